@@ -0,0 +1,102 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CodeFlareStatusName is the name of the CodeFlareStatus singleton reported by the operator.
+const CodeFlareStatusName = "codeflare"
+
+// CodeFlareStatusSpec is empty, as the CodeFlareStatus is only reported by the operator.
+type CodeFlareStatusSpec struct{}
+
+// ComponentVersions are the versions of the operator, of its embedded AppWrapper controller,
+// and of the operators it depends on. They are empty when the operator isn't detected.
+type ComponentVersions struct {
+	Operator   string `json:"operator,omitempty"`
+	AppWrapper string `json:"appWrapper,omitempty"`
+	KubeRay    string `json:"kubeRay,omitempty"`
+	Kueue      string `json:"kueue,omitempty"`
+}
+
+// WorkloadCount is the number of workloads of the kind, e.g., RayCluster or AppWrapper, in the phase.
+type WorkloadCount struct {
+	Kind  string `json:"kind"`
+	Phase string `json:"phase"`
+	Count int32  `json:"count"`
+}
+
+// ReportedError is an error reported by a controller of the operator, for the object it reconciles.
+type ReportedError struct {
+	// Kind is the kind of the object the error was reported for, e.g., RayCluster, or Operator
+	Kind string `json:"kind"`
+	// Object is the namespace/name of the object the error was reported for
+	Object string `json:"object,omitempty"`
+	// Reason is the failure reason of the error, e.g., ImagePullBackOff, if any
+	Reason  string      `json:"reason,omitempty"`
+	Message string      `json:"message"`
+	Time    metav1.Time `json:"time"`
+}
+
+// CodeFlareStatusStatus summarizes the status of the operator.
+type CodeFlareStatusStatus struct {
+	// Versions are the versions of the operator and of the operators it depends on
+	Versions ComponentVersions `json:"versions,omitempty"`
+	// Conditions report the health of the webhooks and the availability of the operators it depends on
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// Workloads are the counts of the workloads managed by the operator, per kind and phase
+	Workloads []WorkloadCount `json:"workloads,omitempty"`
+	// LastErrors are the last errors reported by the controllers of the operator, most recent first
+	LastErrors []ReportedError `json:"lastErrors,omitempty"`
+	// LastUpdateTime is the time the status was last reported
+	LastUpdateTime metav1.Time `json:"lastUpdateTime,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:printcolumn:name="Operator",type=string,JSONPath=`.status.versions.operator`
+// +kubebuilder:printcolumn:name="Available",type=string,JSONPath=`.status.conditions[?(@.type=="Available")].status`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// CodeFlareStatus is the singleton, named codeflare, that summarizes the status of the operator, i.e., the versions
+// of the operators it depends on, the health of its webhooks, the counts of the workloads it manages, and the
+// last errors of its controllers, so that it can be inspected at once, e.g., by support engineers.
+type CodeFlareStatus struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   CodeFlareStatusSpec   `json:"spec,omitempty"`
+	Status CodeFlareStatusStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// CodeFlareStatusList contains a list of CodeFlareStatus.
+type CodeFlareStatusList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []CodeFlareStatus `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&CodeFlareStatus{}, &CodeFlareStatusList{})
+}
@@ -0,0 +1,165 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// The kubectl-codeflare CLI is a kubectl plugin, run as kubectl codeflare, or as a standalone binary,
+// e.g., codeflare clusters list, that gives a non-YAML interface to the CodeFlare workloads.
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	routev1 "github.com/openshift/api/route/v1"
+	awv1beta2 "github.com/project-codeflare/appwrapper/api/v1beta2"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+
+	codeflarev1alpha1 "github.com/project-codeflare/codeflare-operator/api/v1alpha1"
+	"github.com/project-codeflare/codeflare-operator/pkg/cli"
+)
+
+var scheme = runtime.NewScheme()
+
+func init() {
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(rayv1.AddToScheme(scheme))
+	utilruntime.Must(kueue.AddToScheme(scheme))
+	utilruntime.Must(awv1beta2.AddToScheme(scheme))
+	utilruntime.Must(routev1.Install(scheme))
+	utilruntime.Must(codeflarev1alpha1.AddToScheme(scheme))
+}
+
+func main() {
+	name := filepath.Base(os.Args[0])
+	if strings.HasPrefix(name, "kubectl-") {
+		name = "kubectl " + strings.TrimPrefix(name, "kubectl-")
+	}
+	if err := run(name, os.Args[1:], os.Stdout, os.Stderr); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+}
+
+func run(name string, args []string, stdout, stderr io.Writer) error {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		cli.Usage(stdout, name)
+		return nil
+	}
+	command, args := cli.Lookup(args)
+	if command == nil {
+		cli.Usage(stderr, name)
+		return fmt.Errorf("unknown command %q", strings.Join(args, " "))
+	}
+
+	flags := flag.NewFlagSet(name+" "+command.Name, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	overrides := &clientcmd.ConfigOverrides{}
+	flags.StringVar(&loadingRules.ExplicitPath, "kubeconfig", "", "Path to the kubeconfig file")
+	flags.StringVar(&overrides.CurrentContext, "context", "", "The name of the kubeconfig context to use")
+	var namespace, token, jobName string
+	var allNamespaces, insecure, wait bool
+	flags.StringVar(&namespace, "namespace", "", "The namespace, defaults to the namespace of the kubeconfig context")
+	flags.StringVar(&namespace, "n", "", "Shorthand for --namespace")
+	flags.BoolVar(&allNamespaces, "all-namespaces", false, "List the objects across all the namespaces")
+	flags.BoolVar(&allNamespaces, "A", false, "Shorthand for --all-namespaces")
+	flags.StringVar(&token, "token", "", "The bearer token the dashboards are requested with, defaults to the token of the kubeconfig user")
+	flags.BoolVar(&insecure, "insecure-skip-tls-verify", false, "Don't verify the certificates of the dashboards")
+	flags.StringVar(&jobName, "name", "", "The name of the submitted RayJob, defaults to a name generated from the script file name")
+	flags.BoolVar(&wait, "wait", true, "Wait for the submitted RayJob to complete, streaming its logs")
+
+	// The flags can be set before, or after, the positional arguments
+	var positional []string
+	for {
+		if err := flags.Parse(args); errors.Is(err, flag.ErrHelp) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if flags.NArg() == 0 {
+			break
+		}
+		positional = append(positional, flags.Arg(0))
+		args = flags.Args()[1:]
+	}
+	if len(positional) != command.Args {
+		return fmt.Errorf("%s %s expects %d argument(s), got %d", name, command.Name, command.Args, len(positional))
+	}
+
+	kubeConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, overrides)
+	restConfig, err := kubeConfig.ClientConfig()
+	if err != nil {
+		return err
+	}
+	if namespace == "" {
+		if namespace, _, err = kubeConfig.Namespace(); err != nil {
+			return err
+		}
+	}
+	if token == "" {
+		if token, err = bearerToken(restConfig); err != nil {
+			return err
+		}
+	}
+	c, err := client.New(restConfig, client.Options{Scheme: scheme})
+	if err != nil {
+		return err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: insecure}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+	return command.Run(ctx, &cli.Options{
+		Client:        c,
+		HTTPClient:    &http.Client{Transport: transport, Timeout: time.Minute},
+		Token:         token,
+		Namespace:     namespace,
+		AllNamespaces: allNamespaces,
+		JobName:       jobName,
+		Wait:          wait,
+		Out:           stdout,
+	}, positional)
+}
+
+// bearerToken returns the bearer token of the kubeconfig user, if any, e.g., the OpenShift OAuth token.
+func bearerToken(config *rest.Config) (string, error) {
+	if config.BearerToken != "" || config.BearerTokenFile == "" {
+		return config.BearerToken, nil
+	}
+	token, err := os.ReadFile(config.BearerTokenFile)
+	if err != nil {
+		return "", fmt.Errorf("cannot read bearer token: %w", err)
+	}
+	return strings.TrimSpace(string(token)), nil
+}
@@ -21,11 +21,17 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"net"
 	"net/http"
+	"net/netip"
+	"net/url"
 	"os"
+	"path"
 	"strings"
 	"time"
 
+	"github.com/blang/semver/v4"
+	kubeflowv1 "github.com/kubeflow/training-operator/pkg/apis/kubeflow.org/v1"
 	cert "github.com/open-policy-agent/cert-controller/pkg/rotator"
 	dsciv1 "github.com/opendatahub-io/opendatahub-operator/v2/apis/dscinitialization/v1"
 	awv1beta2 "github.com/project-codeflare/appwrapper/api/v1beta2"
@@ -39,10 +45,15 @@ import (
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apiextensionsclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/selection"
 	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/kubernetes"
@@ -50,22 +61,35 @@ import (
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
 	retrywatch "k8s.io/client-go/tools/watch"
 	configv1alpha1 "k8s.io/component-base/config/v1alpha1"
 	"k8s.io/klog/v2"
 	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlcache "sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlconfig "sigs.k8s.io/controller-runtime/pkg/config"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	jobsetv1alpha2 "sigs.k8s.io/jobset/api/jobset/v1alpha2"
 	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
 	"sigs.k8s.io/yaml"
 
 	routev1 "github.com/openshift/api/route/v1"
 	clientset "github.com/openshift/client-go/config/clientset/versioned"
 
+	codeflarev1alpha1 "github.com/project-codeflare/codeflare-operator/api/v1alpha1"
+	"github.com/project-codeflare/codeflare-operator/pkg/audit"
 	"github.com/project-codeflare/codeflare-operator/pkg/config"
 	"github.com/project-codeflare/codeflare-operator/pkg/controllers"
+	"github.com/project-codeflare/codeflare-operator/pkg/cosign"
+	"github.com/project-codeflare/codeflare-operator/pkg/defaults"
+	"github.com/project-codeflare/codeflare-operator/pkg/health"
+	"github.com/project-codeflare/codeflare-operator/pkg/modelregistry"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -80,6 +104,19 @@ var (
 const (
 	workloadAPI   = "workloads.kueue.x-k8s.io"
 	rayclusterAPI = "rayclusters.ray.io"
+	pytorchjobAPI = "pytorchjobs.kubeflow.org"
+	jobsetAPI     = "jobsets.jobset.x-k8s.io"
+)
+
+const (
+	certDir = "/tmp/k8s-webhook-server/serving-certs"
+	// certExpiryThreshold is the remaining validity of the webhook serving certificate below which the operator
+	// isn't ready. The certificate is rotated well before, so it's only reached when the rotation has failed.
+	certExpiryThreshold = 24 * time.Hour
+	apiCheckTimeout     = 500 * time.Millisecond
+
+	mutatingWebhookConfigurationName   = "codeflare-operator-mutating-webhook-configuration"
+	validatingWebhookConfigurationName = "codeflare-operator-validating-webhook-configuration"
 )
 
 func init() {
@@ -94,9 +131,16 @@ func init() {
 	utilruntime.Must(awv1beta2.AddToScheme(scheme))
 	// Kueue
 	utilruntime.Must(kueue.AddToScheme(scheme))
+	// Training Operator
+	utilruntime.Must(kubeflowv1.AddToScheme(scheme))
+	// JobSet
+	utilruntime.Must(jobsetv1alpha2.AddToScheme(scheme))
+	// CodeFlare
+	utilruntime.Must(codeflarev1alpha1.AddToScheme(scheme))
 }
 
 // +kubebuilder:rbac:groups=config.openshift.io,resources=ingresses,verbs=get
+// +kubebuilder:rbac:groups=config.openshift.io,resources=proxies,verbs=get
 
 func main() {
 	var configMapName string
@@ -136,7 +180,14 @@ func main() {
 				ReadinessEndpointName: "readyz",
 				LivenessEndpointName:  "healthz",
 			},
-			LeaderElection: &configv1alpha1.LeaderElectionConfiguration{},
+			LeaderElection: &configv1alpha1.LeaderElectionConfiguration{
+				LeaderElect:   ptr.To(false),
+				ResourceLock:  resourcelock.LeasesResourceLock,
+				ResourceName:  "5a3ca514.codeflare.dev",
+				LeaseDuration: metav1.Duration{Duration: 15 * time.Second},
+				RenewDeadline: metav1.Duration{Duration: 10 * time.Second},
+				RetryPeriod:   metav1.Duration{Duration: 2 * time.Second},
+			},
 		},
 		KubeRay: &config.KubeRayConfiguration{
 			RayDashboardOAuthEnabled: ptr.To(true),
@@ -166,12 +217,28 @@ func main() {
 	kubeConfig.QPS = ptr.Deref(cfg.ClientConnection.QPS, rest.DefaultQPS)
 	setupLog.V(2).Info("REST client", "qps", kubeConfig.QPS, "burst", kubeConfig.Burst)
 
+	exitOnError(validateControllerConcurrency(cfg.Controller), "invalid controller configuration")
+	controllerOptions := ctrlconfig.Controller{}
+	if cfg.Controller != nil {
+		controllerOptions.GroupKindConcurrency = cfg.Controller.GroupKindConcurrency
+	}
+	setupLog.V(2).Info("Controllers", "groupKindConcurrency", controllerOptions.GroupKindConcurrency)
+
+	cacheOptions, err := newCacheOptions(cfg.Cache)
+	exitOnError(err, "invalid cache configuration")
+
+	exitOnError(validatePprofBindAddress(cfg.PprofBindAddress), "invalid pprof configuration")
+
 	mgr, err := ctrl.NewManager(kubeConfig, ctrl.Options{
 		Scheme: scheme,
 		Metrics: metricsserver.Options{
 			BindAddress: cfg.Metrics.BindAddress,
 		},
 		HealthProbeBindAddress:     cfg.Health.BindAddress,
+		PprofBindAddress:           cfg.PprofBindAddress,
+		WebhookServer:              &controllers.InstrumentedWebhookServer{Server: webhook.NewServer(webhook.Options{})},
+		Controller:                 controllerOptions,
+		Cache:                      cacheOptions,
 		LeaderElection:             ptr.Deref(cfg.LeaderElection.LeaderElect, false),
 		LeaderElectionID:           cfg.LeaderElection.ResourceName,
 		LeaderElectionNamespace:    cfg.LeaderElection.ResourceNamespace,
@@ -179,8 +246,16 @@ func main() {
 		LeaseDuration:              &cfg.LeaderElection.LeaseDuration.Duration,
 		RetryPeriod:                &cfg.LeaderElection.RetryPeriod.Duration,
 		RenewDeadline:              &cfg.LeaderElection.RenewDeadline.Duration,
+		// Release the lease when the manager stops, so that another replica takes over without
+		// waiting for it to expire. It's safe as the process exits once the manager has stopped.
+		LeaderElectionReleaseOnCancel: true,
 	})
 	exitOnError(err, "unable to create manager")
+	if ptr.Deref(cfg.LeaderElection.LeaderElect, false) {
+		setupLog.Info("Leader election enabled", "lease", cfg.LeaderElection.ResourceName,
+			"leaseDuration", cfg.LeaderElection.LeaseDuration.Duration, "renewDeadline", cfg.LeaderElection.RenewDeadline.Duration,
+			"retryPeriod", cfg.LeaderElection.RetryPeriod.Duration)
+	}
 
 	certsReady := make(chan struct{})
 	exitOnError(setupCertManagement(mgr, namespace, certsReady), "unable to setup cert-controller")
@@ -192,12 +267,50 @@ func main() {
 		exitOnError(err, cfg.KubeRay.IngressDomain)
 	}
 
+	if cfg.KubeRay.Proxy == nil && isOpenShift(ctx, kubeClient.DiscoveryClient) {
+		configClient, err := clientset.NewForConfig(kubeConfig)
+		exitOnError(err, "unable to create Config Client Set")
+		cfg.KubeRay.Proxy, err = getClusterProxy(ctx, configClient)
+		exitOnError(err, "unable to get cluster-wide Proxy configuration")
+	}
+
+	exitOnError(validateDashboardExposure(cfg.KubeRay, isOpenShift(ctx, kubeClient.DiscoveryClient)), "invalid dashboard exposure configuration")
+	exitOnError(validateTrustedCABundle(cfg.KubeRay, isOpenShift(ctx, kubeClient.DiscoveryClient)), "invalid trusted CA bundle configuration")
+	exitOnError(validateAdmissionPolicyMode(cfg.KubeRay), "invalid admission policy mode configuration")
+	exitOnError(validateRayCompatibility(cfg.KubeRay), "invalid Ray compatibility configuration")
+	exitOnError(validateImageVerification(cfg.KubeRay), "invalid image verification configuration")
+	exitOnError(validateModelRegistry(cfg.ModelRegistry), "invalid model registry configuration")
+	exitOnError(validateScratchVolume(cfg.KubeRay), "invalid scratch volume configuration")
+	exitOnError(validateNotebookNamespaces(cfg.KubeRay), "invalid notebook namespaces configuration")
+	exitOnError(validateSharedClusterNamespaces(cfg.KubeRay), "invalid shared cluster namespaces configuration")
+	exitOnError(validateDashboardHardening(cfg.KubeRay), "invalid dashboard hardening configuration")
+	exitOnError(validateCookieSecretRotation(cfg.KubeRay), "invalid cookie secret rotation configuration")
+	exitOnError(validateObjectStore(cfg.KubeRay), "invalid object store configuration")
+
 	setupLog.Info("setting up health endpoints")
 	exitOnError(setupProbeEndpoints(mgr, cfg, certsReady), "unable to set up health check")
 
+	setupLog.Info("setting up operator status reporter")
+	exitOnError(setupOperatorStatusReporter(mgr, certsReady), "unable to set up operator status reporter")
+
 	setupLog.Info("setting up RayCluster controller")
 	go waitForRayClusterAPIandSetupController(ctx, mgr, cfg, isOpenShift(ctx, kubeClient.DiscoveryClient), certsReady)
 
+	setupLog.Info("setting up Training Operator job webhooks")
+	go waitForTrainingJobAPIandSetupWebhooks(ctx, mgr, cfg, certsReady)
+
+	setupLog.Info("setting up JobSet webhook")
+	go waitForJobSetAPIandSetupWebhook(ctx, mgr, cfg, certsReady)
+
+	setupLog.Info("setting up webhook configuration controller")
+	exitOnError(setupWebhookConfigurationController(mgr, cfg), "unable to setup webhook configuration controller")
+
+	setupLog.Info("setting up admission check retry controller")
+	exitOnError(setupAdmissionCheckRetryController(ctx, mgr, cfg), "unable to setup admission check retry controller")
+
+	setupLog.Info("setting up queue metrics")
+	exitOnError(setupQueueMetrics(ctx, mgr, cfg), "unable to setup queue metrics")
+
 	setupLog.Info("setting up AppWrapper components")
 	exitOnError(setupAppWrapperComponents(ctx, cancel, mgr, cfg, certsReady), "unable to setup AppWrapper")
 
@@ -210,7 +323,12 @@ func setupRayClusterController(mgr ctrl.Manager, cfg *config.CodeFlareOperatorCo
 	<-certsReady
 	setupLog.Info("Certs ready")
 
-	err := controllers.SetupRayClusterWebhookWithManager(mgr, cfg.KubeRay)
+	err := controllers.SetupRayClusterWebhookWithManager(mgr, cfg.KubeRay, cfg.Cache)
+	if err != nil {
+		return err
+	}
+
+	err = controllers.SetupRayJobWebhookWithManager(mgr, cfg.KubeRay, cfg.Cache)
 	if err != nil {
 		return err
 	}
@@ -220,10 +338,62 @@ func setupRayClusterController(mgr ctrl.Manager, cfg *config.CodeFlareOperatorCo
 		Scheme:      mgr.GetScheme(),
 		Config:      cfg.KubeRay,
 		IsOpenShift: isOpenShift,
+		Reader:      mgr.GetAPIReader(),
+		Recorder:    mgr.GetEventRecorderFor("codeflare-operator"),
+	}
+
+	if auditLog := cfg.KubeRay.DashboardAuditLog; auditLog != nil && ptr.Deref(auditLog.Enabled, false) {
+		kubeClient, err := kubernetes.NewForConfig(mgr.GetConfig())
+		if err != nil {
+			return err
+		}
+		sinks := []audit.Sink{&audit.LogSink{Logger: ctrl.Log.WithName("dashboard-audit")}}
+		if auditLog.WebhookURL != "" {
+			sinks = append(sinks, audit.NewWebhookSink(auditLog.WebhookURL))
+		}
+		rayClusterController.Auditor = audit.NewCollector(kubeClient, defaults.OAuthProxyContainerName, sinks...)
+		if err := mgr.Add(rayClusterController.Auditor); err != nil {
+			return err
+		}
+	}
+
+	if err := setupModelRegistrationController(mgr, cfg); err != nil {
+		return err
+	}
+
+	if err := setupCookieSecretRotationController(mgr, cfg, isOpenShift); err != nil {
+		return err
 	}
+
 	return rayClusterController.SetupWithManager(mgr)
 }
 
+func setupModelRegistrationController(mgr ctrl.Manager, cfg *config.CodeFlareOperatorConfiguration) error {
+	if cfg.ModelRegistry == nil || !ptr.Deref(cfg.ModelRegistry.Enabled, false) {
+		setupLog.Info("Model registration controller is disabled by config")
+		return nil
+	}
+	return (&controllers.ModelRegistrationReconciler{
+		Client:   mgr.GetClient(),
+		Registry: modelregistry.NewClient(cfg.ModelRegistry.URL, cfg.ModelRegistry.BearerTokenFile),
+		Recorder: mgr.GetEventRecorderFor("codeflare-operator"),
+	}).SetupWithManager(mgr)
+}
+
+func setupCookieSecretRotationController(mgr ctrl.Manager, cfg *config.CodeFlareOperatorConfiguration, isOpenShift bool) error {
+	// The OAuth proxies, and their Secrets, are only created on OpenShift
+	if !defaults.IsCookieSecretRotationEnabled(cfg.KubeRay) || !ptr.Deref(cfg.KubeRay.RayDashboardOAuthEnabled, true) || !isOpenShift {
+		setupLog.Info("Cookie secret rotation controller is disabled by config")
+		return nil
+	}
+	return (&controllers.CookieSecretRotationReconciler{
+		Client:   mgr.GetClient(),
+		Reader:   mgr.GetAPIReader(),
+		Config:   cfg.KubeRay,
+		Recorder: mgr.GetEventRecorderFor("codeflare-operator"),
+	}).SetupWithManager(mgr)
+}
+
 func waitForRayClusterAPIandSetupController(ctx context.Context, mgr ctrl.Manager, cfg *config.CodeFlareOperatorConfiguration, isOpenShift bool, certsReady chan struct{}) {
 	if isAPIAvailable(ctx, mgr, rayclusterAPI) {
 		exitOnError(setupRayClusterController(mgr, cfg, isOpenShift, certsReady), "unable to setup RayCluster controller")
@@ -234,6 +404,91 @@ func waitForRayClusterAPIandSetupController(ctx context.Context, mgr ctrl.Manage
 	}
 }
 
+func setupTrainingJobWebhooks(mgr ctrl.Manager, cfg *config.CodeFlareOperatorConfiguration, certsReady chan struct{}) error {
+	setupLog.Info("Waiting for certificate generation to complete")
+	<-certsReady
+	setupLog.Info("Setting up Training Operator job webhooks")
+	return controllers.SetupTrainingJobWebhooksWithManager(mgr, defaultQueueName(cfg))
+}
+
+func waitForTrainingJobAPIandSetupWebhooks(ctx context.Context, mgr ctrl.Manager, cfg *config.CodeFlareOperatorConfiguration, certsReady chan struct{}) {
+	if isAPIAvailable(ctx, mgr, pytorchjobAPI) {
+		exitOnError(setupTrainingJobWebhooks(mgr, cfg, certsReady), "unable to setup Training Operator job webhooks")
+	} else {
+		waitForAPI(ctx, mgr, pytorchjobAPI, func() {
+			exitOnError(setupTrainingJobWebhooks(mgr, cfg, certsReady), "unable to setup Training Operator job webhooks")
+		})
+	}
+}
+
+func setupJobSetWebhook(mgr ctrl.Manager, cfg *config.CodeFlareOperatorConfiguration, certsReady chan struct{}) error {
+	setupLog.Info("Waiting for certificate generation to complete")
+	<-certsReady
+	setupLog.Info("Setting up JobSet webhook")
+	return controllers.SetupJobSetWebhookWithManager(mgr, defaultQueueName(cfg))
+}
+
+func waitForJobSetAPIandSetupWebhook(ctx context.Context, mgr ctrl.Manager, cfg *config.CodeFlareOperatorConfiguration, certsReady chan struct{}) {
+	if isAPIAvailable(ctx, mgr, jobsetAPI) {
+		exitOnError(setupJobSetWebhook(mgr, cfg, certsReady), "unable to setup JobSet webhook")
+	} else {
+		waitForAPI(ctx, mgr, jobsetAPI, func() {
+			exitOnError(setupJobSetWebhook(mgr, cfg, certsReady), "unable to setup JobSet webhook")
+		})
+	}
+}
+
+// defaultQueueName returns the Kueue queue of the AppWrappers, that the other workloads are submitted to
+// when they don't specify one, so they get a consistent queue assignment.
+func defaultQueueName(cfg *config.CodeFlareOperatorConfiguration) string {
+	if cfg.AppWrapper != nil && cfg.AppWrapper.Config != nil {
+		return cfg.AppWrapper.Config.DefaultQueueName
+	}
+	return ""
+}
+
+func setupAdmissionCheckRetryController(ctx context.Context, mgr ctrl.Manager, cfg *config.CodeFlareOperatorConfiguration) error {
+	if cfg.AdmissionCheckRetry == nil || !ptr.Deref(cfg.AdmissionCheckRetry.Enabled, false) {
+		setupLog.Info("Admission check retry controller is disabled by config")
+		return nil
+	}
+	if !isAPIAvailable(ctx, mgr, workloadAPI) {
+		setupLog.Info("Workload API not available, admission check retry controller is disabled")
+		return nil
+	}
+	return (&controllers.AdmissionCheckRetryReconciler{
+		Client:   mgr.GetClient(),
+		Reader:   mgr.GetAPIReader(),
+		Config:   cfg.AdmissionCheckRetry,
+		Recorder: mgr.GetEventRecorderFor("codeflare-operator"),
+	}).SetupWithManager(mgr)
+}
+
+func setupQueueMetrics(ctx context.Context, mgr ctrl.Manager, cfg *config.CodeFlareOperatorConfiguration) error {
+	if !ptr.Deref(cfg.Metrics.QueueMetricsEnabled, false) {
+		setupLog.Info("Queue metrics are disabled by config")
+		return nil
+	}
+	if !isAPIAvailable(ctx, mgr, workloadAPI) {
+		setupLog.Info("Workload API not available, queue metrics are disabled")
+		return nil
+	}
+	return metrics.Registry.Register(&controllers.QueueMetricsCollector{Reader: mgr.GetClient()})
+}
+
+func setupWebhookConfigurationController(mgr ctrl.Manager, cfg *config.CodeFlareOperatorConfiguration) error {
+	if cfg.Webhook == nil {
+		setupLog.Info("Webhook configuration controller is disabled by config")
+		return nil
+	}
+	return (&controllers.WebhookConfigurationReconciler{
+		Client:                             mgr.GetClient(),
+		Config:                             cfg.Webhook,
+		MutatingWebhookConfigurationName:   mutatingWebhookConfigurationName,
+		ValidatingWebhookConfigurationName: validatingWebhookConfigurationName,
+	}).SetupWithManager(mgr)
+}
+
 func setupAppWrapperComponents(ctx context.Context, cancel context.CancelFunc, mgr ctrl.Manager,
 	cfg *config.CodeFlareOperatorConfiguration, certsReady chan struct{}) error {
 	if cfg.AppWrapper == nil || !ptr.Deref(cfg.AppWrapper.Enabled, false) {
@@ -285,7 +540,7 @@ func setupCertManagement(mgr ctrl.Manager, namespace string, certsReady chan str
 			Namespace: namespace,
 			Name:      "codeflare-operator-webhook-server-cert",
 		},
-		CertDir:        "/tmp/k8s-webhook-server/serving-certs",
+		CertDir:        certDir,
 		CAName:         "codeflare",
 		CAOrganization: "openshift.ai",
 		DNSName:        fmt.Sprintf("%s.%s.svc", "codeflare-operator-webhook-service", namespace),
@@ -293,11 +548,11 @@ func setupCertManagement(mgr ctrl.Manager, namespace string, certsReady chan str
 		Webhooks: []cert.WebhookInfo{
 			{
 				Type: cert.Validating,
-				Name: "codeflare-operator-validating-webhook-configuration",
+				Name: validatingWebhookConfigurationName,
 			},
 			{
 				Type: cert.Mutating,
-				Name: "codeflare-operator-mutating-webhook-configuration",
+				Name: mutatingWebhookConfigurationName,
 			},
 		},
 		// When the controller is running in the leader election mode,
@@ -312,13 +567,73 @@ func setupProbeEndpoints(mgr ctrl.Manager, cfg *config.CodeFlareOperatorConfigur
 		return err
 	}
 
+	requiredAPIs := []schema.GroupVersionResource{rayv1.GroupVersion.WithResource("rayclusters")}
+	if cfg.AppWrapper != nil && ptr.Deref(cfg.AppWrapper.Enabled, false) {
+		requiredAPIs = append(requiredAPIs, awv1beta2.GroupVersion.WithResource("appwrappers"))
+	}
+	webhooksChecker := newWebhooksChecker(mgr, certsReady)
+	apiChecker, err := newAPIChecker(mgr, requiredAPIs...)
+	if err != nil {
+		return err
+	}
+
 	return mgr.AddReadyzCheck(cfg.Health.ReadinessEndpointName, func(req *http.Request) error {
+		if err := webhooksChecker(req); err != nil {
+			return err
+		}
+		return apiChecker(req)
+	})
+}
+
+// newWebhooksChecker returns a checker that fails until the webhook serving certificate is generated, and when it's
+// invalid, or the webhook server isn't reachable.
+func newWebhooksChecker(mgr ctrl.Manager, certsReady chan struct{}) healthz.Checker {
+	certificateChecker := health.CertificateChecker(certDir, certExpiryThreshold)
+	return func(req *http.Request) error {
 		select {
 		case <-certsReady:
+			if err := certificateChecker(req); err != nil {
+				return err
+			}
 			return mgr.GetWebhookServer().StartedChecker()(req)
 		default:
 			return errors.New("certificates are not ready")
 		}
+	}
+}
+
+// newAPIChecker returns a checker that fails when one of the resources isn't served by the API server.
+func newAPIChecker(mgr ctrl.Manager, resources ...schema.GroupVersionResource) (healthz.Checker, error) {
+	// The discovery requests are bounded to fit within the timeout of the readiness probe
+	discoveryConfig := rest.CopyConfig(mgr.GetConfig())
+	discoveryConfig.Timeout = apiCheckTimeout
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(discoveryConfig)
+	if err != nil {
+		return nil, err
+	}
+	return health.APIChecker(discoveryClient, resources...), nil
+}
+
+func setupOperatorStatusReporter(mgr ctrl.Manager, certsReady chan struct{}) error {
+	kubeRayChecker, err := newAPIChecker(mgr, rayv1.GroupVersion.WithResource("rayclusters"))
+	if err != nil {
+		return err
+	}
+	kueueChecker, err := newAPIChecker(mgr, kueue.GroupVersion.WithResource("workloads"))
+	if err != nil {
+		return err
+	}
+	return mgr.Add(&controllers.OperatorStatusReporter{
+		Client:            mgr.GetClient(),
+		Reader:            mgr.GetAPIReader(),
+		OperatorVersion:   OperatorVersion,
+		AppWrapperVersion: AppWrapperVersion,
+		WebhooksChecker:   newWebhooksChecker(mgr, certsReady),
+		APICheckers: controllers.APICheckers{
+			KubeRay: kubeRayChecker,
+			Kueue:   kueueChecker,
+		},
+		Recorder: mgr.GetEventRecorderFor("codeflare-operator"),
 	})
 }
 
@@ -362,6 +677,10 @@ func createConfigMap(ctx context.Context, client kubernetes.Interface, ns, name
 	}
 
 	_, err = client.CoreV1().ConfigMaps(ns).Create(ctx, configMap, metav1.CreateOptions{})
+	if apierrors.IsAlreadyExists(err) {
+		// Another replica of the operator has created it concurrently
+		return loadIntoOrCreate(ctx, client, ns, name, cfg)
+	}
 	return err
 }
 
@@ -420,8 +739,272 @@ func getClusterDomain(ctx context.Context, configClient *clientset.Clientset) (s
 	return domain, nil
 }
 
+func getClusterProxy(ctx context.Context, configClient *clientset.Clientset) (*config.ProxyConfiguration, error) {
+	proxy, err := configClient.ConfigV1().Proxies().Get(ctx, "cluster", metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get Proxy object: %v", err)
+	}
+
+	if proxy.Status.HTTPProxy == "" && proxy.Status.HTTPSProxy == "" {
+		return nil, nil
+	}
+
+	return &config.ProxyConfiguration{
+		HTTPProxy:  proxy.Status.HTTPProxy,
+		HTTPSProxy: proxy.Status.HTTPSProxy,
+		NoProxy:    proxy.Status.NoProxy,
+	}, nil
+}
+
 // +kubebuilder:rbac:groups="apiextensions.k8s.io",resources=customresourcedefinitions,verbs=get;list;watch
 
+func validateDashboardExposure(cfg *config.KubeRayConfiguration, isOpenShift bool) error {
+	switch cfg.DashboardExposure {
+	case "":
+		return nil
+	case config.IngressDashboardExposure:
+		// The Ingresses route to the Ray dashboard directly, which would bypass the OAuth proxy
+		if isOpenShift && ptr.Deref(cfg.RayDashboardOAuthEnabled, true) {
+			return fmt.Errorf("dashboard exposure %q requires the Ray dashboard OAuth proxy to be disabled on OpenShift", cfg.DashboardExposure)
+		}
+		return nil
+	case config.RouteDashboardExposure:
+		if !isOpenShift {
+			return fmt.Errorf("dashboard exposure %q is only supported on OpenShift", cfg.DashboardExposure)
+		}
+		if !ptr.Deref(cfg.RayDashboardOAuthEnabled, true) {
+			return fmt.Errorf("dashboard exposure %q requires the Ray dashboard OAuth proxy to be enabled", cfg.DashboardExposure)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported dashboard exposure %q, must be one of %q or %q", cfg.DashboardExposure, config.RouteDashboardExposure, config.IngressDashboardExposure)
+	}
+}
+
+func validateControllerConcurrency(cfg *config.ControllerConfiguration) error {
+	if cfg == nil {
+		return nil
+	}
+	for groupKind, concurrency := range cfg.GroupKindConcurrency {
+		if concurrency < 1 {
+			return fmt.Errorf("the concurrency of the %s controller must be at least 1, got %d", groupKind, concurrency)
+		}
+	}
+	return nil
+}
+
+// validatePprofBindAddress checks the pprof profiles are only served on the loopback interface, as they expose
+// the internals of the operator and are expensive to compute.
+func validatePprofBindAddress(bindAddress string) error {
+	if bindAddress == "" || bindAddress == "0" {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(bindAddress)
+	if err != nil {
+		return fmt.Errorf("invalid pprof bind address %q: %w", bindAddress, err)
+	}
+	if host == "localhost" {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip == nil || !ip.IsLoopback() {
+		return fmt.Errorf("pprof bind address %q must be a loopback address, e.g., localhost:8082", bindAddress)
+	}
+	return nil
+}
+
+// newCacheOptions returns the options of the informer caches of the manager. The Pods are only watched by
+// the AppWrapper controller, and are restricted to the ones of the AppWrappers, so that the operator doesn't
+// hold all the Pods of the cluster in memory.
+func newCacheOptions(cfg *config.CacheConfiguration) (ctrlcache.Options, error) {
+	appWrapperPods, err := labels.NewRequirement(controllers.AppWrapperLabel, selection.Exists, nil)
+	if err != nil {
+		return ctrlcache.Options{}, err
+	}
+	options := ctrlcache.Options{
+		ByObject: map[client.Object]ctrlcache.ByObject{
+			&corev1.Pod{}: {Label: labels.NewSelector().Add(*appWrapperPods)},
+		},
+	}
+	if cfg == nil {
+		return options, nil
+	}
+
+	if len(cfg.Namespaces) > 0 {
+		options.DefaultNamespaces = map[string]ctrlcache.Config{}
+		for _, namespace := range cfg.Namespaces {
+			options.DefaultNamespaces[namespace] = ctrlcache.Config{}
+		}
+	}
+
+	if cfg.RayClusterLabelSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(cfg.RayClusterLabelSelector)
+		if err != nil {
+			return ctrlcache.Options{}, fmt.Errorf("invalid RayCluster label selector: %w", err)
+		}
+		options.ByObject[&rayv1.RayCluster{}] = ctrlcache.ByObject{Label: selector}
+	}
+
+	return options, nil
+}
+
+func validateAdmissionPolicyMode(cfg *config.KubeRayConfiguration) error {
+	switch cfg.AdmissionPolicyMode {
+	case "", config.EnforceAdmissionPolicyMode, config.AuditAdmissionPolicyMode:
+		return nil
+	default:
+		return fmt.Errorf("unsupported admission policy mode %q, must be one of %q or %q", cfg.AdmissionPolicyMode, config.EnforceAdmissionPolicyMode, config.AuditAdmissionPolicyMode)
+	}
+}
+
+func validateRayCompatibility(cfg *config.KubeRayConfiguration) error {
+	if cfg.RayCompatibility == nil {
+		return nil
+	}
+	if version := cfg.RayCompatibility.KubeRayVersion; version != "" {
+		if _, err := semver.ParseTolerant(version); err != nil {
+			return fmt.Errorf("invalid KubeRay version %q: %w", version, err)
+		}
+	}
+	for _, incompatibility := range cfg.RayCompatibility.Incompatibilities {
+		if _, err := semver.ParseRange(incompatibility.RayVersions); err != nil {
+			return fmt.Errorf("invalid Ray versions range %q: %w", incompatibility.RayVersions, err)
+		}
+		if incompatibility.KubeRayVersions == "" {
+			continue
+		}
+		if _, err := semver.ParseRange(incompatibility.KubeRayVersions); err != nil {
+			return fmt.Errorf("invalid KubeRay versions range %q: %w", incompatibility.KubeRayVersions, err)
+		}
+	}
+	return nil
+}
+
+func validateImageVerification(cfg *config.KubeRayConfiguration) error {
+	if cfg.ImageVerification == nil || !ptr.Deref(cfg.ImageVerification.Enabled, false) {
+		return nil
+	}
+	_, err := cosign.NewVerifier(cfg.ImageVerification)
+	return err
+}
+
+func validateTrustedCABundle(cfg *config.KubeRayConfiguration, isOpenShift bool) error {
+	if cfg.TrustedCABundle == nil || !ptr.Deref(cfg.TrustedCABundle.Enabled, false) {
+		return nil
+	}
+	if cfg.TrustedCABundle.ConfigMapName == "" && !isOpenShift {
+		return fmt.Errorf("the trusted CA bundle ConfigMap name must be set when not running on OpenShift")
+	}
+	return nil
+}
+
+func validateModelRegistry(cfg *config.ModelRegistryConfiguration) error {
+	if cfg == nil || !ptr.Deref(cfg.Enabled, false) {
+		return nil
+	}
+	registryURL, err := url.Parse(cfg.URL)
+	if err != nil || (registryURL.Scheme != "http" && registryURL.Scheme != "https") || registryURL.Host == "" {
+		return fmt.Errorf("invalid model registry URL %q, must be an absolute HTTP(S) URL", cfg.URL)
+	}
+	return nil
+}
+
+func validateScratchVolume(cfg *config.KubeRayConfiguration) error {
+	if cfg.ScratchVolume == nil || !ptr.Deref(cfg.ScratchVolume.Enabled, false) {
+		return nil
+	}
+	if size := cfg.ScratchVolume.Size; size != "" {
+		if quantity, err := resource.ParseQuantity(size); err != nil || quantity.Sign() <= 0 {
+			return fmt.Errorf("invalid scratch volume size %q, must be a positive quantity", size)
+		}
+	}
+	if mountPath := cfg.ScratchVolume.MountPath; mountPath != "" && !path.IsAbs(mountPath) {
+		return fmt.Errorf("invalid scratch volume mount path %q, must be absolute", mountPath)
+	}
+	return nil
+}
+
+func validateNotebookNamespaces(cfg *config.KubeRayConfiguration) error {
+	if cfg.NotebookNamespaces == nil {
+		return nil
+	}
+	for _, name := range cfg.NotebookNamespaces.Names {
+		if msgs := validation.IsDNS1123Label(name); len(msgs) > 0 {
+			return fmt.Errorf("invalid notebook namespace %q: %s", name, strings.Join(msgs, ", "))
+		}
+	}
+	if selector := cfg.NotebookNamespaces.Selector; selector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(selector); err != nil {
+			return fmt.Errorf("invalid notebook namespace selector: %w", err)
+		}
+	}
+	return nil
+}
+
+func validateSharedClusterNamespaces(cfg *config.KubeRayConfiguration) error {
+	for _, name := range cfg.SharedClusterNamespaces {
+		if msgs := validation.IsDNS1123Label(name); len(msgs) > 0 {
+			return fmt.Errorf("invalid shared cluster namespace %q: %s", name, strings.Join(msgs, ", "))
+		}
+	}
+	return nil
+}
+
+func validateDashboardHardening(cfg *config.KubeRayConfiguration) error {
+	hardening := cfg.DashboardHardening
+	if hardening == nil {
+		return nil
+	}
+	for _, ip := range hardening.IPAllowlist {
+		if _, err := netip.ParsePrefix(ip); err == nil {
+			continue
+		}
+		if _, err := netip.ParseAddr(ip); err != nil {
+			return fmt.Errorf("invalid IP allowlist entry %q, must be an IP address or a CIDR range", ip)
+		}
+	}
+	if rateLimit := hardening.RateLimit; rateLimit != nil {
+		for _, limit := range []*int32{rateLimit.ConcurrentConnections, rateLimit.ConnectionRate, rateLimit.RequestRate} {
+			if limit != nil && *limit <= 0 {
+				return fmt.Errorf("invalid rate limit %d, must be positive", *limit)
+			}
+		}
+	}
+	for name, duration := range map[string]*metav1.Duration{
+		"timeout":        hardening.Timeout,
+		"HSTS max-age":   hardening.HSTSMaxAge,
+		"cookie expire":  hardening.CookieExpire,
+		"cookie refresh": hardening.CookieRefresh,
+	} {
+		if duration != nil && duration.Duration <= 0 {
+			return fmt.Errorf("invalid %s %s, must be positive", name, duration.Duration)
+		}
+	}
+	switch hardening.CookieSameSite {
+	case "", "Strict", "Lax", "None":
+	default:
+		return fmt.Errorf("invalid cookie SameSite attribute %q, must be Strict, Lax or None", hardening.CookieSameSite)
+	}
+	return nil
+}
+
+func validateCookieSecretRotation(cfg *config.KubeRayConfiguration) error {
+	if rotation := cfg.CookieSecretRotation; rotation != nil && rotation.Interval != nil && rotation.Interval.Duration < time.Hour {
+		return fmt.Errorf("invalid cookie secret rotation interval %s, must be at least 1h", rotation.Interval.Duration)
+	}
+	return nil
+}
+
+func validateObjectStore(cfg *config.KubeRayConfiguration) error {
+	if objectStore := cfg.ObjectStore; objectStore != nil && objectStore.MemoryPercentage != nil {
+		if percentage := *objectStore.MemoryPercentage; percentage <= 0 || percentage >= 100 {
+			return fmt.Errorf("invalid object store memory percentage %d, must be between 1 and 99", percentage)
+		}
+	}
+	return nil
+}
+
 func isAPIAvailable(ctx context.Context, mgr ctrl.Manager, apiName string) bool {
 	crdClient, err := apiextensionsclientset.NewForConfig(mgr.GetConfig())
 	exitOnError(err, "unable to create CRD client")
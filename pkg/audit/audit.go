@@ -0,0 +1,139 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package audit collects the requests to the Ray dashboards, logged by the OAuth proxy
+// sidecar containers, as structured audit entries.
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Entry is an audit entry for a request to a Ray dashboard.
+type Entry struct {
+	Timestamp  time.Time            `json:"timestamp"`
+	User       string               `json:"user"`
+	Verb       string               `json:"verb"`
+	Path       string               `json:"path"`
+	Status     int                  `json:"status"`
+	SourceIP   string               `json:"sourceIP"`
+	RayCluster types.NamespacedName `json:"rayCluster"`
+}
+
+// oauthProxyRequestLog matches the request log lines of the OAuth proxy, e.g.:
+// 10.128.2.1:52394 - alice@cluster.local [17/Oct/2024:10:21:33 +0000] ray-dashboard-foo-ns.apps.example.com GET localhost:8265 "/api/jobs/" HTTP/1.1 "Mozilla/5.0" 200 1024 0.012
+var oauthProxyRequestLog = regexp.MustCompile(`^(\S+) - (\S+) \[([^\]]+)\] \S+ (\S+) \S+ "((?:[^"\\]|\\.)*)" \S+ "(?:[^"\\]|\\.)*" (\d{3}) \d+ \S+$`)
+
+const oauthProxyTimeLayout = "02/Jan/2006:15:04:05 -0700"
+
+// ParseOAuthProxyRequestLog parses a request log line of the OAuth proxy into an audit entry.
+// It returns false if the line is not a request log line.
+func ParseOAuthProxyRequestLog(line string) (Entry, bool) {
+	match := oauthProxyRequestLog.FindStringSubmatch(line)
+	if match == nil {
+		return Entry{}, false
+	}
+	timestamp, err := time.Parse(oauthProxyTimeLayout, match[3])
+	if err != nil {
+		return Entry{}, false
+	}
+	path, err := strconv.Unquote(`"` + match[5] + `"`)
+	if err != nil {
+		path = match[5]
+	}
+	status, _ := strconv.Atoi(match[6])
+	return Entry{
+		Timestamp: timestamp.UTC(),
+		User:      match[2],
+		Verb:      match[4],
+		Path:      path,
+		Status:    status,
+		SourceIP:  match[1],
+	}, true
+}
+
+// Sink records audit entries.
+type Sink interface {
+	Write(ctx context.Context, entry Entry) error
+}
+
+// LogSink writes audit entries to a structured logger.
+type LogSink struct {
+	Logger logr.Logger
+}
+
+var _ Sink = &LogSink{}
+
+func (s *LogSink) Write(_ context.Context, entry Entry) error {
+	s.Logger.Info("Ray dashboard access",
+		"timestamp", entry.Timestamp,
+		"user", entry.User,
+		"verb", entry.Verb,
+		"path", entry.Path,
+		"status", entry.Status,
+		"sourceIP", entry.SourceIP,
+		"rayCluster", entry.RayCluster,
+	)
+	return nil
+}
+
+// WebhookSink POSTs audit entries as JSON to an HTTP endpoint.
+type WebhookSink struct {
+	URL    string
+	Client *http.Client
+}
+
+var _ Sink = &WebhookSink{}
+
+// NewWebhookSink returns a sink that POSTs audit entries to the URL.
+func NewWebhookSink(url string) *WebhookSink {
+	return &WebhookSink{
+		URL:    url,
+		Client: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+func (s *WebhookSink) Write(ctx context.Context, entry Entry) error {
+	body, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("audit webhook %s returned status %d", s.URL, resp.StatusCode)
+	}
+	return nil
+}
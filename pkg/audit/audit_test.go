@@ -0,0 +1,92 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/project-codeflare/codeflare-common/support"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestParseOAuthProxyRequestLog(t *testing.T) {
+	test := support.NewTest(t)
+
+	test.T().Run("Expected request log line to be parsed", func(t *testing.T) {
+		entry, ok := ParseOAuthProxyRequestLog(`10.128.2.1:52394 - alice@cluster.local [17/Oct/2024:10:21:33 +0000] ray-dashboard-foo-ns.apps.example.com GET localhost:8265 "/api/jobs/?limit=10" HTTP/1.1 "Mozilla/5.0 (X11; Linux x86_64)" 200 1024 0.012`)
+
+		test.Expect(ok).To(BeTrue())
+		test.Expect(entry).To(Equal(Entry{
+			Timestamp: time.Date(2024, time.October, 17, 10, 21, 33, 0, time.UTC),
+			User:      "alice@cluster.local",
+			Verb:      "GET",
+			Path:      "/api/jobs/?limit=10",
+			Status:    200,
+			SourceIP:  "10.128.2.1:52394",
+		}))
+	})
+
+	test.T().Run("Negative: Expected other log lines to be ignored", func(t *testing.T) {
+		_, ok := ParseOAuthProxyRequestLog(`2024/10/17 10:21:30 oauthproxy.go:203: mapping path "/" => upstream "http://localhost:8265/"`)
+		test.Expect(ok).To(BeFalse())
+	})
+}
+
+func TestWebhookSink(t *testing.T) {
+	test := support.NewTest(t)
+
+	entry := Entry{
+		Timestamp:  time.Date(2024, time.October, 17, 10, 21, 33, 0, time.UTC),
+		User:       "alice@cluster.local",
+		Verb:       "GET",
+		Path:       "/",
+		Status:     200,
+		SourceIP:   "10.128.2.1:52394",
+		RayCluster: types.NamespacedName{Namespace: "ns", Name: "foo"},
+	}
+
+	test.T().Run("Expected audit entry to be POSTed as JSON", func(t *testing.T) {
+		received := make(chan Entry, 1)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var e Entry
+			if r.Method != http.MethodPost || json.NewDecoder(r.Body).Decode(&e) != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			received <- e
+		}))
+		defer server.Close()
+
+		test.Expect(NewWebhookSink(server.URL).Write(test.Ctx(), entry)).To(Succeed())
+		test.Expect(<-received).To(Equal(entry))
+	})
+
+	test.T().Run("Negative: Expected error when the webhook fails", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		test.Expect(NewWebhookSink(server.URL).Write(test.Ctx(), entry)).NotTo(Succeed())
+	})
+}
@@ -0,0 +1,186 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"bufio"
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+const retryInterval = 10 * time.Second
+
+// log is for logging in this package.
+var auditlog = ctrl.Log.WithName("dashboard-audit")
+
+// Collector follows the request logs of the OAuth proxy container of the RayCluster head pods,
+// and writes them as audit entries to the sinks.
+type Collector struct {
+	client    kubernetes.Interface
+	container string
+	sinks     []Sink
+
+	mutex   sync.Mutex
+	ctx     context.Context
+	streams map[types.NamespacedName]context.CancelFunc
+}
+
+var _ manager.Runnable = &Collector{}
+
+// NewCollector returns a collector following the logs of the given container of the RayCluster head pods.
+func NewCollector(client kubernetes.Interface, container string, sinks ...Sink) *Collector {
+	return &Collector{
+		client:    client,
+		container: container,
+		sinks:     sinks,
+		streams:   map[types.NamespacedName]context.CancelFunc{},
+	}
+}
+
+// Start implements manager.Runnable, and starts following the RayClusters watched so far.
+func (c *Collector) Start(ctx context.Context) error {
+	c.mutex.Lock()
+	c.ctx = ctx
+	for cluster := range c.streams {
+		c.streams[cluster] = c.follow(cluster)
+	}
+	c.mutex.Unlock()
+
+	<-ctx.Done()
+	return nil
+}
+
+// Watch starts collecting the audit entries of the RayCluster, unless it is already.
+func (c *Collector) Watch(cluster types.NamespacedName) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if _, ok := c.streams[cluster]; ok {
+		return
+	}
+	if c.ctx == nil {
+		// Deferred until the collector is started
+		c.streams[cluster] = nil
+		return
+	}
+	c.streams[cluster] = c.follow(cluster)
+}
+
+// Forget stops collecting the audit entries of the RayCluster.
+func (c *Collector) Forget(cluster types.NamespacedName) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if cancel := c.streams[cluster]; cancel != nil {
+		cancel()
+	}
+	delete(c.streams, cluster)
+}
+
+func (c *Collector) follow(cluster types.NamespacedName) context.CancelFunc {
+	ctx, cancel := context.WithCancel(c.ctx)
+	go func() {
+		logger := auditlog.WithValues("rayCluster", cluster)
+		since := metav1.Now()
+		for {
+			if err := c.stream(ctx, cluster, &since); err != nil {
+				logger.V(2).Info("Failed to follow OAuth proxy logs", "error", err.Error())
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(retryInterval):
+			}
+		}
+	}()
+	return cancel
+}
+
+// stream follows the logs of the head pod, since the timestamp of the last line read, which is updated as lines are read.
+// The logs are requested with second precision, so the lines at or before that timestamp are read again on reconnection,
+// and dropped so that no audit entry is duplicated.
+func (c *Collector) stream(ctx context.Context, cluster types.NamespacedName, since *metav1.Time) error {
+	pods, err := c.client.CoreV1().Pods(cluster.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: "ray.io/node-type=head,ray.io/cluster=" + cluster.Name,
+	})
+	if err != nil {
+		return err
+	}
+	for _, pod := range pods.Items {
+		if pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+		logs, err := c.client.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
+			Container:  c.container,
+			Follow:     true,
+			SinceTime:  since,
+			Timestamps: true,
+		}).Stream(ctx)
+		if err != nil {
+			return err
+		}
+		defer logs.Close()
+
+		scanner := bufio.NewScanner(logs)
+		for scanner.Scan() {
+			timestamp, line, ok := splitLogTimestamp(scanner.Text())
+			if !ok || !timestamp.After(since.Time) {
+				continue
+			}
+			*since = metav1.NewTime(timestamp)
+			entry, ok := ParseOAuthProxyRequestLog(line)
+			if !ok {
+				continue
+			}
+			entry.RayCluster = cluster
+			c.write(ctx, entry)
+		}
+		return scanner.Err()
+	}
+	return nil
+}
+
+// splitLogTimestamp splits a log line requested with timestamps into the RFC 3339 timestamp prefixed by the kubelet,
+// and the line as written by the container.
+func splitLogTimestamp(line string) (time.Time, string, bool) {
+	prefix, rest, found := strings.Cut(line, " ")
+	if !found {
+		return time.Time{}, "", false
+	}
+	timestamp, err := time.Parse(time.RFC3339Nano, prefix)
+	if err != nil {
+		return time.Time{}, "", false
+	}
+	return timestamp, rest, true
+}
+
+func (c *Collector) write(ctx context.Context, entry Entry) {
+	for _, sink := range c.sinks {
+		if err := sink.Write(ctx, entry); err != nil {
+			auditlog.Error(err, "Failed to write audit entry", "rayCluster", entry.RayCluster)
+		}
+	}
+}
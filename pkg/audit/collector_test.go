@@ -0,0 +1,42 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/project-codeflare/codeflare-common/support"
+)
+
+func TestSplitLogTimestamp(t *testing.T) {
+	test := support.NewTest(t)
+
+	test.T().Run("Expected timestamp to be split from the log line", func(t *testing.T) {
+		timestamp, line, ok := splitLogTimestamp(`2024-10-17T10:21:33.123456789Z 10.128.2.1:52394 - alice@cluster.local [17/Oct/2024:10:21:33 +0000] GET`)
+
+		test.Expect(ok).To(BeTrue())
+		test.Expect(timestamp).To(BeTemporally("==", time.Date(2024, time.October, 17, 10, 21, 33, 123456789, time.UTC)))
+		test.Expect(line).To(Equal(`10.128.2.1:52394 - alice@cluster.local [17/Oct/2024:10:21:33 +0000] GET`))
+	})
+
+	test.T().Run("Negative: Expected log line without timestamp to be rejected", func(t *testing.T) {
+		_, _, ok := splitLogTimestamp(`10.128.2.1:52394 - alice@cluster.local [17/Oct/2024:10:21:33 +0000] GET`)
+		test.Expect(ok).To(BeFalse())
+	})
+}
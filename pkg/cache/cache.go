@@ -0,0 +1,179 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package cache provides a caching layer for the external lookups performed in the admission path.
+// Values are cached for a fixed TTL, concurrent lookups of the same key are coalesced into a single
+// load, and cache hits, misses and loads are exported as metrics, so that admission latency stays
+// bounded when many objects are created at once.
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// sweepThreshold is the number of entries above which expired entries are swept on insertion.
+	sweepThreshold = 1024
+
+	// loadTimeout bounds the loads, that don't run with the context of the lookups, as they're shared by them.
+	loadTimeout = 30 * time.Second
+)
+
+// Loader loads the value for a key, e.g., by calling the API server or a remote registry.
+type Loader[K comparable, V any] func(ctx context.Context, key K) (V, error)
+
+// Cache caches the values loaded for keys for a fixed TTL.
+// Errors are not cached, so a failed load is retried by the next lookup.
+type Cache[K comparable, V any] struct {
+	name        string
+	ttl         time.Duration
+	load        Loader[K, V]
+	loadTimeout time.Duration
+	now         func() time.Time
+
+	mu      sync.Mutex
+	entries map[K]entry[V]
+	calls   map[K]*call[V]
+}
+
+type entry[V any] struct {
+	value   V
+	expires time.Time
+}
+
+// call is a load in flight, that concurrent lookups of the same key wait for.
+// A call is stale when its key is invalidated while it's in flight, and its value is then not cached.
+type call[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+	stale bool
+}
+
+// New returns a cache that loads values with the given loader, and keeps them for the given TTL.
+// The name identifies the cache in the exported metrics.
+func New[K comparable, V any](name string, ttl time.Duration, load Loader[K, V]) *Cache[K, V] {
+	return &Cache[K, V]{
+		name:        name,
+		ttl:         ttl,
+		load:        load,
+		loadTimeout: loadTimeout,
+		now:         time.Now,
+		entries:     map[K]entry[V]{},
+		calls:       map[K]*call[V]{},
+	}
+}
+
+// Get returns the cached value for the key, or loads it if it's missing or has expired.
+// If a load of the key is already in flight, Get waits for its result rather than starting another one.
+// The load runs with a context detached from the cancellation of the lookup that started it, bounded by
+// its own timeout, so that a lookup that's canceled doesn't fail the others waiting for the same load.
+// Each lookup waits for the load until its own context is done.
+func (c *Cache[K, V]) Get(ctx context.Context, key K) (V, error) {
+	c.mu.Lock()
+	if e, ok := c.entries[key]; ok && c.now().Before(e.expires) {
+		c.mu.Unlock()
+		requests.WithLabelValues(c.name, resultHit).Inc()
+		return e.value, nil
+	}
+	cl, ok := c.calls[key]
+	if ok {
+		c.mu.Unlock()
+		requests.WithLabelValues(c.name, resultCoalesced).Inc()
+	} else {
+		cl = &call[V]{done: make(chan struct{})}
+		c.calls[key] = cl
+		c.mu.Unlock()
+		requests.WithLabelValues(c.name, resultMiss).Inc()
+
+		loadCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), c.loadTimeout)
+		go func() {
+			defer cancel()
+			c.doLoad(loadCtx, key, cl)
+		}()
+	}
+
+	select {
+	case <-cl.done:
+		return cl.value, cl.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+// doLoad runs the load of the call, and completes it even if the loader panics,
+// so that the lookups waiting for it are not blocked.
+func (c *Cache[K, V]) doLoad(ctx context.Context, key K, cl *call[V]) {
+	start := time.Now()
+	defer func() {
+		loadDuration.WithLabelValues(c.name).Observe(time.Since(start).Seconds())
+		if r := recover(); r != nil {
+			cl.err = fmt.Errorf("panic loading %s cache: %v", c.name, r)
+		}
+		if cl.err != nil {
+			loadErrors.WithLabelValues(c.name).Inc()
+		}
+
+		c.mu.Lock()
+		if c.calls[key] == cl {
+			delete(c.calls, key)
+		}
+		if cl.err == nil && !cl.stale {
+			if len(c.entries) >= sweepThreshold {
+				c.sweep()
+			}
+			c.entries[key] = entry[V]{value: cl.value, expires: c.now().Add(c.ttl)}
+		}
+		c.mu.Unlock()
+		close(cl.done)
+	}()
+
+	cl.value, cl.err = c.load(ctx, key)
+}
+
+// Invalidate removes the cached value for the key, so that the next lookup loads it again.
+// A load of the key in flight is marked stale, so that the value it loads, that may predate the invalidation,
+// is returned to the lookups already waiting for it, but isn't cached, and the next lookup starts another load.
+func (c *Cache[K, V]) Invalidate(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, key)
+	if cl, ok := c.calls[key]; ok {
+		cl.stale = true
+		delete(c.calls, key)
+	}
+}
+
+// Len returns the number of cached values, including those that have expired but are not swept yet.
+func (c *Cache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+// sweep removes the expired entries. It must be called with the lock held.
+func (c *Cache[K, V]) sweep() {
+	now := c.now()
+	for key, e := range c.entries {
+		if !now.Before(e.expires) {
+			delete(c.entries, key)
+		}
+	}
+}
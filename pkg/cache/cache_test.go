@@ -0,0 +1,233 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/project-codeflare/codeflare-common/support"
+)
+
+func TestCache(t *testing.T) {
+	test := support.NewTest(t)
+
+	test.T().Run("Expected cached values to be returned until they expire", func(t *testing.T) {
+		var loads atomic.Int32
+		c := New("test", time.Minute, func(_ context.Context, key string) (string, error) {
+			loads.Add(1)
+			return "value-" + key, nil
+		})
+		now := time.Now()
+		c.now = func() time.Time { return now }
+
+		for i := 0; i < 3; i++ {
+			value, err := c.Get(test.Ctx(), "a")
+			test.Expect(err).NotTo(HaveOccurred())
+			test.Expect(value).To(Equal("value-a"))
+		}
+		test.Expect(loads.Load()).To(Equal(int32(1)))
+
+		now = now.Add(time.Minute)
+		_, err := c.Get(test.Ctx(), "a")
+		test.Expect(err).NotTo(HaveOccurred())
+		test.Expect(loads.Load()).To(Equal(int32(2)))
+
+		c.Invalidate("a")
+		_, err = c.Get(test.Ctx(), "a")
+		test.Expect(err).NotTo(HaveOccurred())
+		test.Expect(loads.Load()).To(Equal(int32(3)))
+	})
+
+	test.T().Run("Expected errors not to be cached", func(t *testing.T) {
+		var loads atomic.Int32
+		c := New("test", time.Minute, func(_ context.Context, _ string) (string, error) {
+			if loads.Add(1) == 1 {
+				return "", errors.New("unavailable")
+			}
+			return "value", nil
+		})
+
+		_, err := c.Get(test.Ctx(), "a")
+		test.Expect(err).To(HaveOccurred())
+		test.Expect(c.Len()).To(BeZero())
+
+		value, err := c.Get(test.Ctx(), "a")
+		test.Expect(err).NotTo(HaveOccurred())
+		test.Expect(value).To(Equal("value"))
+		test.Expect(loads.Load()).To(Equal(int32(2)))
+	})
+
+	test.T().Run("Expected concurrent lookups of the same key to be coalesced", func(t *testing.T) {
+		var loads atomic.Int32
+		release := make(chan struct{})
+		c := New("test", time.Minute, func(_ context.Context, _ string) (string, error) {
+			loads.Add(1)
+			<-release
+			return "value", nil
+		})
+
+		const lookups = 50
+		var wg sync.WaitGroup
+		values := make([]string, lookups)
+		for i := 0; i < lookups; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				values[i], _ = c.Get(test.Ctx(), "a")
+			}(i)
+		}
+		// Wait for the first lookup to start loading before releasing it
+		test.Eventually(loads.Load).Should(Equal(int32(1)))
+		close(release)
+		wg.Wait()
+
+		test.Expect(loads.Load()).To(Equal(int32(1)))
+		test.Expect(values).To(HaveEach("value"))
+	})
+
+	test.T().Run("Expected the value of a load in flight when the key is invalidated not to be cached", func(t *testing.T) {
+		var loads atomic.Int32
+		release := make(chan struct{})
+		c := New("test", time.Minute, func(_ context.Context, _ string) (string, error) {
+			if loads.Add(1) == 1 {
+				<-release
+				return "stale", nil
+			}
+			return "fresh", nil
+		})
+
+		staleValue := make(chan string)
+		go func() {
+			value, _ := c.Get(test.Ctx(), "a")
+			staleValue <- value
+		}()
+		test.Eventually(loads.Load).Should(Equal(int32(1)))
+
+		c.Invalidate("a")
+		// The lookups after the invalidation don't wait for the stale load
+		ctx, cancel := context.WithTimeout(test.Ctx(), 5*time.Second)
+		defer cancel()
+		value, err := c.Get(ctx, "a")
+		test.Expect(err).NotTo(HaveOccurred())
+		test.Expect(value).To(Equal("fresh"))
+
+		close(release)
+		test.Expect(<-staleValue).To(Equal("stale"))
+
+		value, err = c.Get(test.Ctx(), "a")
+		test.Expect(err).NotTo(HaveOccurred())
+		test.Expect(value).To(Equal("fresh"))
+		test.Expect(loads.Load()).To(Equal(int32(2)))
+	})
+
+	test.T().Run("Expected expired entries to be swept", func(t *testing.T) {
+		c := New("test", time.Minute, func(_ context.Context, key int) (int, error) {
+			return key, nil
+		})
+		now := time.Now()
+		c.now = func() time.Time { return now }
+
+		for i := 0; i < sweepThreshold; i++ {
+			_, err := c.Get(test.Ctx(), i)
+			test.Expect(err).NotTo(HaveOccurred())
+		}
+		test.Expect(c.Len()).To(Equal(sweepThreshold))
+
+		now = now.Add(time.Minute)
+		_, err := c.Get(test.Ctx(), sweepThreshold)
+		test.Expect(err).NotTo(HaveOccurred())
+		test.Expect(c.Len()).To(Equal(1))
+	})
+
+	test.T().Run("Negative: Expected waiting lookups to honor their context", func(t *testing.T) {
+		release := make(chan struct{})
+		defer close(release)
+		started := make(chan struct{})
+		c := New("test", time.Minute, func(_ context.Context, _ string) (string, error) {
+			close(started)
+			<-release
+			return "value", nil
+		})
+
+		go func() {
+			_, _ = c.Get(context.Background(), "a")
+		}()
+		<-started
+
+		ctx, cancel := context.WithTimeout(test.Ctx(), 10*time.Millisecond)
+		defer cancel()
+		_, err := c.Get(ctx, "a")
+		test.Expect(err).To(MatchError(context.DeadlineExceeded))
+	})
+
+	test.T().Run("Expected a canceled lookup not to fail the lookups coalesced into its load", func(t *testing.T) {
+		release := make(chan struct{})
+		started := make(chan struct{})
+		c := New("test", time.Minute, func(ctx context.Context, _ string) (string, error) {
+			close(started)
+			<-release
+			return "value", ctx.Err()
+		})
+
+		ctx, cancel := context.WithCancel(test.Ctx())
+		firstErr := make(chan error)
+		go func() {
+			_, err := c.Get(ctx, "a")
+			firstErr <- err
+		}()
+		<-started
+
+		secondValue := make(chan string)
+		go func() {
+			value, _ := c.Get(test.Ctx(), "a")
+			secondValue <- value
+		}()
+		cancel()
+		test.Expect(<-firstErr).To(MatchError(context.Canceled))
+
+		close(release)
+		test.Expect(<-secondValue).To(Equal("value"))
+	})
+
+	test.T().Run("Negative: Expected loads to be bounded by the load timeout", func(t *testing.T) {
+		c := New("test", time.Minute, func(ctx context.Context, _ string) (string, error) {
+			<-ctx.Done()
+			return "", ctx.Err()
+		})
+		c.loadTimeout = 10 * time.Millisecond
+
+		_, err := c.Get(test.Ctx(), "a")
+		test.Expect(err).To(MatchError(context.DeadlineExceeded))
+	})
+
+	test.T().Run("Negative: Expected a panicking loader not to block waiting lookups", func(t *testing.T) {
+		c := New("test", time.Minute, func(_ context.Context, _ string) (string, error) {
+			panic("boom")
+		})
+
+		_, err := c.Get(test.Ctx(), "a")
+		test.Expect(err).To(MatchError(ContainSubstring("boom")))
+		_, err = c.Get(test.Ctx(), "a")
+		test.Expect(err).To(MatchError(ContainSubstring("boom")))
+	})
+}
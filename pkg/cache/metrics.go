@@ -0,0 +1,57 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	resultHit       = "hit"
+	resultMiss      = "miss"
+	resultCoalesced = "coalesced"
+)
+
+var (
+	requests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "codeflare",
+		Subsystem: "cache",
+		Name:      "requests_total",
+		Help:      "Number of cache lookups, partitioned by cache and result (hit, miss or coalesced).",
+	}, []string{"cache", "result"})
+
+	loadErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "codeflare",
+		Subsystem: "cache",
+		Name:      "load_errors_total",
+		Help:      "Number of cache loads that failed, partitioned by cache.",
+	}, []string{"cache"})
+
+	loadDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "codeflare",
+		Subsystem: "cache",
+		Name:      "load_duration_seconds",
+		Help:      "Duration of the cache loads, partitioned by cache.",
+		Buckets:   []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5},
+	}, []string{"cache"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(requests, loadErrors, loadDuration)
+}
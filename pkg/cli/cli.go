@@ -0,0 +1,118 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package cli implements the commands of the kubectl-codeflare CLI plugin, that gives the data scientists
+// a non-YAML interface to the RayClusters, RayJobs and Kueue queues, and to the dashboards exposed by the operator.
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Options are the clients and the flags the commands run with.
+type Options struct {
+	Client client.Client
+	// HTTPClient requests the dashboards of the RayClusters
+	HTTPClient *http.Client
+	// Token is the bearer token the dashboards are requested with, as they're secured by the OAuth proxy
+	Token         string
+	Namespace     string
+	AllNamespaces bool
+	// JobName is the name of the RayJob submitted, generated from the script file name if empty
+	JobName string
+	// Wait is whether the submit command waits for the RayJob to complete, streaming its logs
+	Wait bool
+	Out  io.Writer
+}
+
+// Command is a command of the CLI, e.g., clusters list.
+type Command struct {
+	Name  string
+	Usage string
+	// Args is the number of the positional arguments of the command
+	Args int
+	Run  func(ctx context.Context, o *Options, args []string) error
+}
+
+// Commands are the commands of the CLI.
+var Commands = []Command{
+	{Name: "clusters list", Usage: "List the RayClusters, with their state, workers, queue and dashboard URL", Run: listClusters},
+	{Name: "queue status", Usage: "Show the pending and admitted workloads of the LocalQueues", Run: queueStatus},
+	{Name: "job logs", Usage: "Print the logs of the RayJob <name>, read from the dashboard of its RayCluster", Args: 1, Run: jobLogs},
+	{Name: "submit", Usage: "Submit the local <script> as a RayJob to the RayCluster <name>, and stream its logs until it completes", Args: 2, Run: submitJob},
+	{Name: "doctor", Usage: "Check the CodeFlare installation, and print the remediation of the problems found", Run: runDoctor},
+}
+
+// Lookup returns the command named by the first arguments, and the remaining arguments, or nil if there's none.
+func Lookup(args []string) (*Command, []string) {
+	for i := range Commands {
+		words := strings.Fields(Commands[i].Name)
+		if len(args) >= len(words) && slices.Equal(args[:len(words)], words) {
+			return &Commands[i], args[len(words):]
+		}
+	}
+	return nil, args
+}
+
+// Usage prints the commands of the CLI.
+func Usage(w io.Writer, name string) {
+	fmt.Fprintf(w, "Usage: %s <command> [flags] [arguments]\n\nCommands:\n", name)
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, command := range Commands {
+		fmt.Fprintf(tw, "  %s\t%s\n", command.Name, command.Usage)
+	}
+	_ = tw.Flush()
+}
+
+// listOptions returns the options of the List requests, i.e., the namespace, unless all the namespaces are listed.
+func (o *Options) listOptions() []client.ListOption {
+	if o.AllNamespaces {
+		return nil
+	}
+	return []client.ListOption{client.InNamespace(o.Namespace)}
+}
+
+// table writes the rows, aligned in columns, with the header, prefixed with the namespace column when all the
+// namespaces are listed.
+type table struct {
+	w             *tabwriter.Writer
+	allNamespaces bool
+}
+
+func (o *Options) newTable(header ...string) *table {
+	t := &table{w: tabwriter.NewWriter(o.Out, 0, 4, 3, ' ', 0), allNamespaces: o.AllNamespaces}
+	t.row("NAMESPACE", header...)
+	return t
+}
+
+func (t *table) row(namespace string, columns ...string) {
+	if t.allNamespaces {
+		columns = append([]string{namespace}, columns...)
+	}
+	fmt.Fprintln(t.w, strings.Join(columns, "\t"))
+}
+
+func (t *table) flush() error {
+	return t.w.Flush()
+}
@@ -0,0 +1,291 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/project-codeflare/codeflare-common/support"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	kueueconstants "sigs.k8s.io/kueue/pkg/controller/constants"
+
+	"github.com/project-codeflare/codeflare-operator/pkg/controllers"
+)
+
+func newOptions(t *testing.T, objects ...client.Object) (*Options, *bytes.Buffer) {
+	test := support.NewTest(t)
+	scheme := runtime.NewScheme()
+	test.Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+	test.Expect(rayv1.AddToScheme(scheme)).To(Succeed())
+	test.Expect(kueue.AddToScheme(scheme)).To(Succeed())
+	out := &bytes.Buffer{}
+	return &Options{
+		Client:     fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build(),
+		HTTPClient: http.DefaultClient,
+		Namespace:  "ns",
+		Out:        out,
+	}, out
+}
+
+func TestLookup(t *testing.T) {
+	test := support.NewTest(t)
+
+	test.T().Run("Expected command to be looked up by name", func(t *testing.T) {
+		command, args := Lookup([]string{"job", "logs", "train", "-n", "ns"})
+		test.Expect(command).NotTo(BeNil())
+		test.Expect(command.Name).To(Equal("job logs"))
+		test.Expect(args).To(Equal([]string{"train", "-n", "ns"}))
+	})
+
+	test.T().Run("Negative: Expected no command for unknown names", func(t *testing.T) {
+		command, _ := Lookup([]string{"clusters", "delete"})
+		test.Expect(command).To(BeNil())
+	})
+}
+
+func TestListClusters(t *testing.T) {
+	test := support.NewTest(t)
+	ctx := context.Background()
+
+	rayCluster := func(namespace, name string) *rayv1.RayCluster {
+		return &rayv1.RayCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec: rayv1.RayClusterSpec{WorkerGroupSpecs: []rayv1.WorkerGroupSpec{
+				{Replicas: ptr.To(int32(2))},
+				{Replicas: ptr.To(int32(1)), NumOfHosts: 2},
+			}},
+		}
+	}
+	ready := rayCluster("ns", "ready")
+	ready.Labels = map[string]string{kueueconstants.QueueLabel: "team-a"}
+	ready.Annotations = map[string]string{controllers.DashboardURLAnnotation: "https://ray-dashboard-ready-ns.apps.example.com"}
+	ready.Status.State = rayv1.Ready
+	ready.Status.AvailableWorkerReplicas = 4
+	suspended := rayCluster("ns", "suspended")
+	suspended.Spec.Suspend = ptr.To(true)
+
+	test.T().Run("Expected RayClusters of the namespace to be listed", func(t *testing.T) {
+		o, out := newOptions(t, ready, suspended, rayCluster("other", "other"))
+
+		test.Expect(listClusters(ctx, o, nil)).To(Succeed())
+		test.Expect(out.String()).To(Equal(`NAME        STATE       WORKERS   QUEUE    DASHBOARD
+ready       ready       4/4       team-a   https://ray-dashboard-ready-ns.apps.example.com
+suspended   suspended   0/4       <none>   <none>
+`))
+	})
+
+	test.T().Run("Expected RayClusters of all the namespaces to be listed with their namespace", func(t *testing.T) {
+		o, out := newOptions(t, ready, rayCluster("other", "other"))
+		o.AllNamespaces = true
+
+		test.Expect(listClusters(ctx, o, nil)).To(Succeed())
+		test.Expect(out.String()).To(And(
+			HavePrefix("NAMESPACE   NAME    STATE"),
+			ContainSubstring("\nother       other   <none>"),
+		))
+	})
+}
+
+func TestQueueStatus(t *testing.T) {
+	test := support.NewTest(t)
+
+	o, out := newOptions(t, &kueue.LocalQueue{
+		ObjectMeta: metav1.ObjectMeta{Name: "team-a", Namespace: "ns"},
+		Spec:       kueue.LocalQueueSpec{ClusterQueue: "cluster-queue"},
+		Status:     kueue.LocalQueueStatus{PendingWorkloads: 3, AdmittedWorkloads: 1},
+	})
+
+	test.Expect(queueStatus(context.Background(), o, nil)).To(Succeed())
+	test.Expect(out.String()).To(Equal(`NAME     CLUSTERQUEUE    PENDING   ADMITTED
+team-a   cluster-queue   3         1
+`))
+}
+
+func TestJobLogs(t *testing.T) {
+	test := support.NewTest(t)
+	ctx := context.Background()
+
+	var authorization string
+	dashboard := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		if r.URL.Path != "/api/jobs/raysubmit_123/logs" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`{"logs": "Epoch 1: loss=0.42\n"}`))
+	}))
+	t.Cleanup(dashboard.Close)
+
+	rayJob := &rayv1.RayJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "train", Namespace: "ns"},
+		Status:     rayv1.RayJobStatus{JobId: "raysubmit_123", RayClusterName: "train-raycluster"},
+	}
+	rayCluster := &rayv1.RayCluster{ObjectMeta: metav1.ObjectMeta{
+		Name:        "train-raycluster",
+		Namespace:   "ns",
+		Annotations: map[string]string{controllers.DashboardURLAnnotation: dashboard.URL},
+	}}
+
+	test.T().Run("Expected logs of the RayJob to be read from the dashboard", func(t *testing.T) {
+		o, out := newOptions(t, rayJob, rayCluster)
+		o.Token = "sha256~token"
+
+		test.Expect(jobLogs(ctx, o, []string{"train"})).To(Succeed())
+		test.Expect(out.String()).To(Equal("Epoch 1: loss=0.42\n"))
+		test.Expect(authorization).To(Equal("Bearer sha256~token"))
+	})
+
+	test.T().Run("Negative: Expected error when the RayJob hasn't been submitted", func(t *testing.T) {
+		pending := rayJob.DeepCopy()
+		pending.Status = rayv1.RayJobStatus{JobDeploymentStatus: rayv1.JobDeploymentStatusInitializing}
+		o, _ := newOptions(t, pending)
+
+		test.Expect(jobLogs(ctx, o, []string{"train"})).To(MatchError("RayJob train has not been submitted yet, its deployment status is Initializing"))
+	})
+
+	test.T().Run("Negative: Expected error when the dashboard isn't exposed", func(t *testing.T) {
+		unexposed := rayCluster.DeepCopy()
+		unexposed.Annotations = nil
+		o, _ := newOptions(t, rayJob, unexposed)
+
+		test.Expect(jobLogs(ctx, o, []string{"train"})).To(MatchError("the dashboard of RayCluster train-raycluster is not exposed yet"))
+	})
+}
+
+func TestSubmitJob(t *testing.T) {
+	test := support.NewTest(t)
+	ctx := context.Background()
+
+	submitPollInterval = time.Millisecond
+	t.Cleanup(func() { submitPollInterval = 2 * time.Second })
+
+	script := filepath.Join(t.TempDir(), "Train_MNIST.py")
+	test.Expect(os.WriteFile(script, []byte("print('training')\n"), 0o600)).To(Succeed())
+
+	// The dashboard returns the whole logs, that grow as the job runs
+	logs := []string{"Epoch 1: loss=0.42\n", "Epoch 1: loss=0.42\nEpoch 2: loss=0.21\n"}
+	requests := 0
+	dashboard := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"logs": "` + strings.ReplaceAll(logs[min(requests, len(logs)-1)], "\n", `\n`) + `"}`))
+		requests++
+	}))
+	t.Cleanup(dashboard.Close)
+
+	rayCluster := &rayv1.RayCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "raycluster",
+			Namespace:   "ns",
+			Annotations: map[string]string{controllers.DashboardURLAnnotation: dashboard.URL},
+		},
+		Spec: rayv1.RayClusterSpec{HeadGroupSpec: rayv1.HeadGroupSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "ray-head", Image: "quay.io/modh/ray:2.35.0-py311-cu121"}},
+		}}}},
+	}
+
+	// progress simulates KubeRay, i.e., the RayJob is running on the first poll, and has the given status on the next ones
+	progress := func(o *Options, status rayv1.JobStatus) {
+		polls := 0
+		o.Client = interceptor.NewClient(o.Client.(client.WithWatch), interceptor.Funcs{
+			Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+				if err := c.Get(ctx, key, obj, opts...); err != nil {
+					return err
+				}
+				if rayJob, ok := obj.(*rayv1.RayJob); ok {
+					rayJob.Status = rayv1.RayJobStatus{JobId: "raysubmit_123", JobStatus: rayv1.JobStatusRunning}
+					if polls > 0 {
+						rayJob.Status.JobStatus = status
+						rayJob.Status.Message = "Job entrypoint command failed with exit code 1"
+					}
+					polls++
+				}
+				return nil
+			},
+		})
+	}
+
+	test.T().Run("Expected script to be submitted as a RayJob, mounted from a ConfigMap owned by the RayJob", func(t *testing.T) {
+		o, out := newOptions(t, rayCluster)
+
+		test.Expect(submitJob(ctx, o, []string{"raycluster", script})).To(Succeed())
+
+		rayJobs := &rayv1.RayJobList{}
+		test.Expect(o.Client.List(ctx, rayJobs)).To(Succeed())
+		test.Expect(rayJobs.Items).To(HaveLen(1))
+		rayJob := rayJobs.Items[0]
+		test.Expect(rayJob.Name).To(MatchRegexp("^train-mnist-[a-z0-9]{5}$"))
+		test.Expect(rayJob.Spec.Entrypoint).To(Equal("python Train_MNIST.py"))
+		test.Expect(rayJob.Spec.RuntimeEnvYAML).To(Equal("working_dir: /home/ray/jobs\n"))
+		test.Expect(rayJob.Spec.ClusterSelector).To(Equal(map[string]string{controllers.RayJobClusterSelectorKey: "raycluster"}))
+		submitter := rayJob.Spec.SubmitterPodTemplate.Spec
+		test.Expect(submitter.Containers[0].Image).To(Equal("quay.io/modh/ray:2.35.0-py311-cu121"))
+		test.Expect(submitter.Volumes[0].ConfigMap.Name).To(Equal(rayJob.Name))
+
+		configMap := &corev1.ConfigMap{}
+		test.Expect(o.Client.Get(ctx, client.ObjectKey{Namespace: "ns", Name: rayJob.Name}, configMap)).To(Succeed())
+		test.Expect(configMap.BinaryData).To(HaveKeyWithValue("Train_MNIST.py", []byte("print('training')\n")))
+		test.Expect(configMap.OwnerReferences).To(ConsistOf(HaveField("Name", rayJob.Name)))
+		test.Expect(out.String()).To(Equal("RayJob " + rayJob.Name + " submitted to RayCluster raycluster\n"))
+	})
+
+	test.T().Run("Expected logs to be streamed until the RayJob succeeds", func(t *testing.T) {
+		requests = 0
+		o, out := newOptions(t, rayCluster)
+		o.JobName = "train"
+		o.Wait = true
+		progress(o, rayv1.JobStatusSucceeded)
+
+		test.Expect(submitJob(ctx, o, []string{"raycluster", script})).To(Succeed())
+		test.Expect(out.String()).To(Equal("RayJob train submitted to RayCluster raycluster\n" +
+			"Epoch 1: loss=0.42\nEpoch 2: loss=0.21\n" +
+			"RayJob train succeeded\n"))
+	})
+
+	test.T().Run("Negative: Expected error when the RayJob fails", func(t *testing.T) {
+		requests = 0
+		o, _ := newOptions(t, rayCluster)
+		o.JobName = "train"
+		o.Wait = true
+		progress(o, rayv1.JobStatusFailed)
+
+		test.Expect(submitJob(ctx, o, []string{"raycluster", script})).To(MatchError("RayJob train failed: Job entrypoint command failed with exit code 1"))
+	})
+
+	test.T().Run("Negative: Expected error when the RayCluster doesn't exist", func(t *testing.T) {
+		o, _ := newOptions(t)
+
+		test.Expect(submitJob(ctx, o, []string{"raycluster", script})).To(MatchError(ContainSubstring("cannot get RayCluster")))
+	})
+}
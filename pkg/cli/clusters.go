@@ -0,0 +1,83 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	"k8s.io/utils/ptr"
+	kueueconstants "sigs.k8s.io/kueue/pkg/controller/constants"
+
+	"github.com/project-codeflare/codeflare-operator/pkg/controllers"
+)
+
+// none is printed for the columns that have no value
+const none = "<none>"
+
+func listClusters(ctx context.Context, o *Options, _ []string) error {
+	rayClusters := &rayv1.RayClusterList{}
+	if err := o.Client.List(ctx, rayClusters, o.listOptions()...); err != nil {
+		return fmt.Errorf("cannot list RayClusters: %w", err)
+	}
+	if len(rayClusters.Items) == 0 {
+		fmt.Fprintln(o.Out, "No RayClusters found")
+		return nil
+	}
+
+	t := o.newTable("NAME", "STATE", "WORKERS", "QUEUE", "DASHBOARD")
+	for _, rayCluster := range rayClusters.Items {
+		t.row(rayCluster.Namespace,
+			rayCluster.Name,
+			rayClusterState(&rayCluster),
+			fmt.Sprintf("%d/%d", rayCluster.Status.AvailableWorkerReplicas, desiredWorkers(&rayCluster)),
+			valueOrNone(rayCluster.Labels[kueueconstants.QueueLabel]),
+			valueOrNone(rayCluster.Annotations[controllers.DashboardURLAnnotation]),
+		)
+	}
+	return t.flush()
+}
+
+// rayClusterState returns the state of the RayCluster, i.e., suspended when it's waiting for admission by Kueue.
+func rayClusterState(rayCluster *rayv1.RayCluster) string {
+	if ptr.Deref(rayCluster.Spec.Suspend, false) {
+		return string(rayv1.Suspended)
+	}
+	return valueOrNone(string(rayCluster.Status.State))
+}
+
+func desiredWorkers(rayCluster *rayv1.RayCluster) int32 {
+	var workers int32
+	for _, group := range rayCluster.Spec.WorkerGroupSpecs {
+		workers += ptr.Deref(group.Replicas, 0) * max(group.NumOfHosts, 1)
+	}
+	return workers
+}
+
+func valueOrNone(value string) string {
+	if value == "" {
+		return none
+	}
+	return value
+}
+
+func itoa(i int32) string {
+	return strconv.Itoa(int(i))
+}
@@ -0,0 +1,345 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"strings"
+	"time"
+
+	routev1 "github.com/openshift/api/route/v1"
+	awv1beta2 "github.com/project-codeflare/appwrapper/api/v1beta2"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	kueueconstants "sigs.k8s.io/kueue/pkg/controller/constants"
+
+	"github.com/project-codeflare/codeflare-operator/api/v1alpha1"
+)
+
+const (
+	// webhookConfigurationPrefix is the prefix of the names of the webhook configurations of the operator
+	webhookConfigurationPrefix = "codeflare-operator-"
+	// certificateExpiryThreshold is the validity below which the CA of the webhooks is reported as expiring
+	certificateExpiryThreshold = 7 * 24 * time.Hour
+	// rayClusterNameLabel is set by the operator on the Routes and Ingresses of the RayClusters
+	rayClusterNameLabel = "ray.io/cluster-name"
+)
+
+type severity string
+
+const (
+	severityOK      severity = "OK"
+	severityWarning severity = "WARN"
+	severityFailure severity = "FAIL"
+)
+
+// doctor runs the checks support engineers do when triaging the CodeFlare installations, and prints the problems it
+// finds with their remediation.
+type doctor struct {
+	o        *Options
+	now      time.Time
+	failures int
+}
+
+// report prints the result of the check, with the remediation of the problems.
+func (d *doctor) report(severity severity, check, message, remediation string) {
+	fmt.Fprintf(d.o.Out, "[%s] %s: %s\n", severity, check, message)
+	if severity != severityOK && remediation != "" {
+		fmt.Fprintf(d.o.Out, "       -> %s\n", remediation)
+	}
+	if severity == severityFailure {
+		d.failures++
+	}
+}
+
+func runDoctor(ctx context.Context, o *Options, _ []string) error {
+	d := &doctor{o: o, now: time.Now()}
+	for _, check := range []struct {
+		name string
+		run  func(context.Context) error
+	}{
+		{"APIs", d.checkAPIs},
+		{"Operator status", d.checkOperatorStatus},
+		{"Webhooks", d.checkWebhooks},
+		{"Queues", d.checkQueues},
+		{"Routes", d.checkRoutes},
+	} {
+		if err := check.run(ctx); apierrors.IsForbidden(err) {
+			d.report(severityWarning, check.name, "not checked: "+err.Error(), "Run the doctor with a user granted to read the cluster-scoped resources")
+		} else if err != nil {
+			return err
+		}
+	}
+	if d.failures > 0 {
+		return fmt.Errorf("%d check(s) failed", d.failures)
+	}
+	return nil
+}
+
+// checkAPIs checks the APIs of the operators CodeFlare depends on are served, i.e., their CRDs are installed.
+func (d *doctor) checkAPIs(_ context.Context) error {
+	for _, api := range []struct {
+		gvk         schema.GroupVersionKind
+		severity    severity
+		remediation string
+	}{
+		{rayv1.GroupVersion.WithKind("RayCluster"), severityFailure, "Install the KubeRay operator, e.g., by enabling the ray component of the DataScienceCluster"},
+		{rayv1.GroupVersion.WithKind("RayJob"), severityFailure, "Install the KubeRay operator, e.g., by enabling the ray component of the DataScienceCluster"},
+		{kueue.GroupVersion.WithKind("Workload"), severityWarning, "Install Kueue, e.g., by enabling the kueue component of the DataScienceCluster, so that the workloads are queued"},
+		{awv1beta2.GroupVersion.WithKind("AppWrapper"), severityWarning, "Install the AppWrapper CRD, from config/crd, to submit AppWrappers"},
+		{v1alpha1.GroupVersion.WithKind("CodeFlareStatus"), severityWarning, "Install the CodeFlareStatus CRD, from config/crd/bases, for the operator to report its status"},
+	} {
+		check := "API " + api.gvk.GroupVersion().String() + " " + api.gvk.Kind
+		if _, err := d.o.Client.RESTMapper().RESTMapping(api.gvk.GroupKind(), api.gvk.Version); meta.IsNoMatchError(err) {
+			d.report(api.severity, check, "not served", api.remediation)
+		} else if err != nil {
+			return err
+		} else {
+			d.report(severityOK, check, "served", "")
+		}
+	}
+	return nil
+}
+
+// checkOperatorStatus checks the conditions of the CodeFlareStatus reported by the operator.
+func (d *doctor) checkOperatorStatus(ctx context.Context) error {
+	status := &v1alpha1.CodeFlareStatus{}
+	err := d.o.Client.Get(ctx, client.ObjectKey{Name: v1alpha1.CodeFlareStatusName}, status)
+	if meta.IsNoMatchError(err) || apierrors.IsNotFound(err) {
+		d.report(severityWarning, "Operator status", "the CodeFlareStatus is not reported",
+			"Check the CodeFlare operator is running, e.g., with kubectl get deployment -A -l app.kubernetes.io/name=codeflare-operator")
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if d.now.Sub(status.Status.LastUpdateTime.Time) > 5*time.Minute {
+		d.report(severityWarning, "Operator status", fmt.Sprintf("the CodeFlareStatus was last updated at %s",
+			status.Status.LastUpdateTime.Format(time.RFC3339)), "Check the leader replica of the CodeFlare operator is running, and its logs")
+	}
+	for _, condition := range status.Status.Conditions {
+		check := "Operator condition " + condition.Type
+		switch condition.Status {
+		case metav1.ConditionTrue:
+			d.report(severityOK, check, condition.Message, "")
+		case metav1.ConditionFalse:
+			d.report(severityFailure, check, condition.Message, "Check the logs of the CodeFlare operator")
+		default:
+			d.report(severityWarning, check, condition.Message, "")
+		}
+	}
+	for _, reported := range status.Status.LastErrors {
+		d.report(severityWarning, "Operator error", fmt.Sprintf("%s %s at %s: %s", reported.Kind, reported.Object,
+			reported.Time.Format(time.RFC3339), reported.Message), "")
+	}
+	return nil
+}
+
+// checkWebhooks checks the webhooks of the operator are backed by a ready Service, and trust a valid CA.
+func (d *doctor) checkWebhooks(ctx context.Context) error {
+	type webhook struct {
+		name         string
+		clientConfig admissionregistrationv1.WebhookClientConfig
+	}
+	var webhooks []webhook
+	mutating := &admissionregistrationv1.MutatingWebhookConfigurationList{}
+	if err := d.o.Client.List(ctx, mutating); err != nil {
+		return fmt.Errorf("cannot list MutatingWebhookConfigurations: %w", err)
+	}
+	for _, configuration := range mutating.Items {
+		if strings.HasPrefix(configuration.Name, webhookConfigurationPrefix) {
+			for _, w := range configuration.Webhooks {
+				webhooks = append(webhooks, webhook{w.Name, w.ClientConfig})
+			}
+		}
+	}
+	validating := &admissionregistrationv1.ValidatingWebhookConfigurationList{}
+	if err := d.o.Client.List(ctx, validating); err != nil {
+		return fmt.Errorf("cannot list ValidatingWebhookConfigurations: %w", err)
+	}
+	for _, configuration := range validating.Items {
+		if strings.HasPrefix(configuration.Name, webhookConfigurationPrefix) {
+			for _, w := range configuration.Webhooks {
+				webhooks = append(webhooks, webhook{w.Name, w.ClientConfig})
+			}
+		}
+	}
+	if len(webhooks) == 0 {
+		d.report(severityFailure, "Webhooks", "no webhook configuration of the CodeFlare operator found",
+			"Check the CodeFlare operator is installed, e.g., with the codeflare component of the DataScienceCluster")
+		return nil
+	}
+
+	for _, w := range webhooks {
+		check := "Webhook " + w.name
+		if w.clientConfig.Service != nil {
+			ready, err := d.serviceReady(ctx, types.NamespacedName{Namespace: w.clientConfig.Service.Namespace, Name: w.clientConfig.Service.Name})
+			if err != nil {
+				return err
+			}
+			if !ready {
+				d.report(severityFailure, check, fmt.Sprintf("Service %s/%s has no ready endpoint, the admission requests fail",
+					w.clientConfig.Service.Namespace, w.clientConfig.Service.Name),
+					"Check the CodeFlare operator pods are running and ready, and their logs")
+				continue
+			}
+		}
+		if severity, message := d.caBundleValidity(w.clientConfig.CABundle); severity != severityOK {
+			d.report(severity, check, message, "Restart the CodeFlare operator, so that its certificates are rotated and the CA bundle injected")
+			continue
+		}
+		d.report(severityOK, check, "reachable, with a valid CA bundle", "")
+	}
+	return nil
+}
+
+// serviceReady returns whether the Service has ready endpoints.
+func (d *doctor) serviceReady(ctx context.Context, service types.NamespacedName) (bool, error) {
+	endpoints := &corev1.Endpoints{}
+	if err := d.o.Client.Get(ctx, service, endpoints); apierrors.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("cannot get Endpoints of Service %s: %w", service, err)
+	}
+	for _, subset := range endpoints.Subsets {
+		if len(subset.Addresses) > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// caBundleValidity checks the CA bundle the API server verifies the webhooks with is valid, and doesn't expire soon.
+func (d *doctor) caBundleValidity(caBundle []byte) (severity, string) {
+	block, _ := pem.Decode(caBundle)
+	if block == nil {
+		return severityFailure, "the CA bundle is missing, the certificates haven't been injected"
+	}
+	certificate, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return severityFailure, fmt.Sprintf("invalid CA bundle: %v", err)
+	}
+	switch {
+	case d.now.After(certificate.NotAfter):
+		return severityFailure, fmt.Sprintf("the CA expired at %s", certificate.NotAfter.Format(time.RFC3339))
+	case certificate.NotAfter.Sub(d.now) < certificateExpiryThreshold:
+		return severityWarning, fmt.Sprintf("the CA expires at %s", certificate.NotAfter.Format(time.RFC3339))
+	}
+	return severityOK, ""
+}
+
+// checkQueues checks the ClusterQueues are active, and the LocalQueues, and the queue names of the RayClusters,
+// reference existing queues.
+func (d *doctor) checkQueues(ctx context.Context) error {
+	clusterQueues := &kueue.ClusterQueueList{}
+	if err := d.o.Client.List(ctx, clusterQueues); meta.IsNoMatchError(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("cannot list ClusterQueues: %w", err)
+	}
+	existing := map[string]bool{}
+	for _, clusterQueue := range clusterQueues.Items {
+		existing[clusterQueue.Name] = true
+		check := "ClusterQueue " + clusterQueue.Name
+		if active := meta.FindStatusCondition(clusterQueue.Status.Conditions, kueue.ClusterQueueActive); active != nil && active.Status != metav1.ConditionTrue {
+			d.report(severityFailure, check, "inactive: "+active.Message,
+				"Create the ResourceFlavors, and AdmissionChecks, the ClusterQueue references, or fix its spec")
+		} else {
+			d.report(severityOK, check, "active", "")
+		}
+	}
+
+	localQueues := &kueue.LocalQueueList{}
+	if err := d.o.Client.List(ctx, localQueues, d.o.listOptions()...); err != nil {
+		return fmt.Errorf("cannot list LocalQueues: %w", err)
+	}
+	queues := map[types.NamespacedName]bool{}
+	for _, localQueue := range localQueues.Items {
+		queues[types.NamespacedName{Namespace: localQueue.Namespace, Name: localQueue.Name}] = true
+		if !existing[string(localQueue.Spec.ClusterQueue)] {
+			d.report(severityFailure, "LocalQueue "+localQueue.Namespace+"/"+localQueue.Name,
+				fmt.Sprintf("ClusterQueue %s doesn't exist, the workloads submitted to it stay pending", localQueue.Spec.ClusterQueue),
+				"Create the ClusterQueue, or fix the spec.clusterQueue field of the LocalQueue")
+		}
+	}
+
+	rayClusters := &rayv1.RayClusterList{}
+	if err := d.o.Client.List(ctx, rayClusters, d.o.listOptions()...); err != nil {
+		return fmt.Errorf("cannot list RayClusters: %w", err)
+	}
+	for _, rayCluster := range rayClusters.Items {
+		queue, ok := rayCluster.Labels[kueueconstants.QueueLabel]
+		if ok && !queues[types.NamespacedName{Namespace: rayCluster.Namespace, Name: queue}] {
+			d.report(severityFailure, "RayCluster "+rayCluster.Namespace+"/"+rayCluster.Name,
+				fmt.Sprintf("LocalQueue %s doesn't exist, the RayCluster is never admitted", queue),
+				fmt.Sprintf("Create the LocalQueue %s in namespace %s, or fix the %s label", queue, rayCluster.Namespace, kueueconstants.QueueLabel))
+		}
+	}
+	return nil
+}
+
+// checkRoutes checks the Routes, and Ingresses, of the RayClusters are owned by existing RayClusters, as they
+// keep exposing their hosts otherwise, e.g., when the owner references have been removed.
+func (d *doctor) checkRoutes(ctx context.Context) error {
+	rayClusters := &rayv1.RayClusterList{}
+	if err := d.o.Client.List(ctx, rayClusters, d.o.listOptions()...); err != nil {
+		return fmt.Errorf("cannot list RayClusters: %w", err)
+	}
+	owners := map[types.UID]bool{}
+	for _, rayCluster := range rayClusters.Items {
+		owners[rayCluster.UID] = true
+	}
+	dangling := func(kind string, object client.Object) {
+		for _, owner := range object.GetOwnerReferences() {
+			if owner.Kind == "RayCluster" && owners[owner.UID] {
+				return
+			}
+		}
+		d.report(severityWarning, kind+" "+object.GetNamespace()+"/"+object.GetName(),
+			fmt.Sprintf("RayCluster %s it exposes doesn't exist", object.GetLabels()[rayClusterNameLabel]),
+			fmt.Sprintf("Delete it, e.g., with kubectl delete %s %s -n %s", strings.ToLower(kind), object.GetName(), object.GetNamespace()))
+	}
+
+	options := append(d.o.listOptions(), client.HasLabels{rayClusterNameLabel})
+	routes := &routev1.RouteList{}
+	if err := d.o.Client.List(ctx, routes, options...); err != nil && !meta.IsNoMatchError(err) {
+		return fmt.Errorf("cannot list Routes: %w", err)
+	}
+	for i := range routes.Items {
+		dangling("Route", &routes.Items[i])
+	}
+	ingresses := &networkingv1.IngressList{}
+	if err := d.o.Client.List(ctx, ingresses, options...); err != nil {
+		return fmt.Errorf("cannot list Ingresses: %w", err)
+	}
+	for i := range ingresses.Items {
+		dangling("Ingress", &ingresses.Items[i])
+	}
+	return nil
+}
@@ -0,0 +1,209 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	routev1 "github.com/openshift/api/route/v1"
+	awv1beta2 "github.com/project-codeflare/appwrapper/api/v1beta2"
+	"github.com/project-codeflare/codeflare-common/support"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	kueueconstants "sigs.k8s.io/kueue/pkg/controller/constants"
+
+	"github.com/project-codeflare/codeflare-operator/api/v1alpha1"
+)
+
+func caBundle(t *testing.T, notAfter time.Time) []byte {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "codeflare-operator-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              notAfter,
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func TestDoctor(t *testing.T) {
+	test := support.NewTest(t)
+	ctx := context.Background()
+
+	scheme := runtime.NewScheme()
+	test.Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+	test.Expect(rayv1.AddToScheme(scheme)).To(Succeed())
+	test.Expect(kueue.AddToScheme(scheme)).To(Succeed())
+	test.Expect(awv1beta2.AddToScheme(scheme)).To(Succeed())
+	test.Expect(routev1.Install(scheme)).To(Succeed())
+	test.Expect(v1alpha1.AddToScheme(scheme)).To(Succeed())
+
+	// The RESTMapper only maps the kinds of the served APIs, i.e., all the registered kinds but the excluded ones
+	restMapper := func(excluded ...schema.GroupVersion) meta.RESTMapper {
+		mapper := meta.NewDefaultRESTMapper(scheme.PrioritizedVersionsAllGroups())
+	kinds:
+		for gvk := range scheme.AllKnownTypes() {
+			for _, gv := range excluded {
+				if gvk.GroupVersion() == gv {
+					continue kinds
+				}
+			}
+			scope := meta.RESTScopeNamespace
+			if gvk.Kind == "ClusterQueue" || gvk.Kind == "CodeFlareStatus" || gvk.Kind == "ResourceFlavor" ||
+				gvk.Kind == "MutatingWebhookConfiguration" || gvk.Kind == "ValidatingWebhookConfiguration" {
+				scope = meta.RESTScopeRoot
+			}
+			mapper.Add(gvk, scope)
+		}
+		return mapper
+	}
+	run := func(mapper meta.RESTMapper, objects ...client.Object) (string, error) {
+		out := &bytes.Buffer{}
+		o := &Options{
+			Client:    fake.NewClientBuilder().WithScheme(scheme).WithRESTMapper(mapper).WithObjects(objects...).Build(),
+			Namespace: "ns",
+			Out:       out,
+		}
+		err := runDoctor(ctx, o, nil)
+		return out.String(), err
+	}
+
+	webhookConfiguration := func(caBundle []byte) *admissionregistrationv1.ValidatingWebhookConfiguration {
+		return &admissionregistrationv1.ValidatingWebhookConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: "codeflare-operator-validating-webhook-configuration"},
+			Webhooks: []admissionregistrationv1.ValidatingWebhook{{
+				Name: "vraycluster.ray.openshift.ai",
+				ClientConfig: admissionregistrationv1.WebhookClientConfig{
+					Service:  &admissionregistrationv1.ServiceReference{Namespace: "opendatahub", Name: "codeflare-operator-webhook-service"},
+					CABundle: caBundle,
+				},
+			}},
+		}
+	}
+	endpoints := &corev1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "opendatahub", Name: "codeflare-operator-webhook-service"},
+		Subsets:    []corev1.EndpointSubset{{Addresses: []corev1.EndpointAddress{{IP: "10.128.0.12"}}}},
+	}
+	status := &v1alpha1.CodeFlareStatus{
+		ObjectMeta: metav1.ObjectMeta{Name: v1alpha1.CodeFlareStatusName},
+		Status: v1alpha1.CodeFlareStatusStatus{
+			Conditions:     []metav1.Condition{{Type: "Available", Status: metav1.ConditionTrue, Message: "The webhooks are ready and KubeRay is installed"}},
+			LastUpdateTime: metav1.Now(),
+		},
+	}
+	clusterQueue := &kueue.ClusterQueue{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster-queue"},
+		Status: kueue.ClusterQueueStatus{Conditions: []metav1.Condition{
+			{Type: kueue.ClusterQueueActive, Status: metav1.ConditionTrue},
+		}},
+	}
+	localQueue := &kueue.LocalQueue{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "team-a"},
+		Spec:       kueue.LocalQueueSpec{ClusterQueue: "cluster-queue"},
+	}
+	rayCluster := &rayv1.RayCluster{ObjectMeta: metav1.ObjectMeta{
+		Namespace: "ns", Name: "raycluster", UID: "raycluster-uid",
+		Labels: map[string]string{kueueconstants.QueueLabel: "team-a"},
+	}}
+	route := func(owner *rayv1.RayCluster) *routev1.Route {
+		return &routev1.Route{ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns", Name: "ray-dashboard-raycluster",
+			Labels:          map[string]string{rayClusterNameLabel: "raycluster"},
+			OwnerReferences: []metav1.OwnerReference{{APIVersion: "ray.io/v1", Kind: "RayCluster", Name: owner.Name, UID: owner.UID}},
+		}}
+	}
+
+	test.T().Run("Expected no problem to be reported for a healthy installation", func(t *testing.T) {
+		out, err := run(restMapper(),
+			webhookConfiguration(caBundle(t, time.Now().AddDate(1, 0, 0))), endpoints, status,
+			clusterQueue, localQueue, rayCluster, route(rayCluster),
+		)
+
+		test.Expect(err).NotTo(HaveOccurred())
+		test.Expect(out).To(And(
+			ContainSubstring("[OK] API ray.io/v1 RayCluster: served"),
+			ContainSubstring("[OK] Operator condition Available: The webhooks are ready and KubeRay is installed"),
+			ContainSubstring("[OK] Webhook vraycluster.ray.openshift.ai: reachable, with a valid CA bundle"),
+			ContainSubstring("[OK] ClusterQueue cluster-queue: active"),
+			Not(ContainSubstring("[WARN]")),
+			Not(ContainSubstring("[FAIL]")),
+		))
+	})
+
+	test.T().Run("Negative: Expected problems to be reported with their remediation", func(t *testing.T) {
+		inactive := clusterQueue.DeepCopy()
+		inactive.Status.Conditions[0].Status = metav1.ConditionFalse
+		inactive.Status.Conditions[0].Message = "Can't admit new workloads: FlavorNotFound"
+		misconfigured := localQueue.DeepCopy()
+		misconfigured.Name = "team-b"
+		misconfigured.Spec.ClusterQueue = "missing"
+		deleted := rayCluster.DeepCopy()
+		deleted.UID = "deleted-uid"
+
+		out, err := run(restMapper(awv1beta2.GroupVersion),
+			webhookConfiguration(caBundle(t, time.Now().Add(time.Hour))), inactive, misconfigured, rayCluster, route(deleted),
+		)
+
+		test.Expect(err).To(MatchError("4 check(s) failed"))
+		test.Expect(out).To(And(
+			ContainSubstring("[WARN] API workload.codeflare.dev/v1beta2 AppWrapper: not served\n       -> Install the AppWrapper CRD"),
+			ContainSubstring("[WARN] Operator status: the CodeFlareStatus is not reported"),
+			ContainSubstring("[FAIL] Webhook vraycluster.ray.openshift.ai: Service opendatahub/codeflare-operator-webhook-service has no ready endpoint"),
+			ContainSubstring("[FAIL] ClusterQueue cluster-queue: inactive: Can't admit new workloads: FlavorNotFound"),
+			ContainSubstring("[FAIL] LocalQueue ns/team-b: ClusterQueue missing doesn't exist"),
+			ContainSubstring("[FAIL] RayCluster ns/raycluster: LocalQueue team-a doesn't exist"),
+			ContainSubstring("[WARN] Route ns/ray-dashboard-raycluster: RayCluster raycluster it exposes doesn't exist\n       -> Delete it, e.g., with kubectl delete route ray-dashboard-raycluster -n ns"),
+		))
+	})
+
+	test.T().Run("Negative: Expected expiring CA bundle to be reported", func(t *testing.T) {
+		out, _ := run(restMapper(), webhookConfiguration(caBundle(t, time.Now().Add(time.Hour))), endpoints)
+
+		test.Expect(out).To(ContainSubstring("[WARN] Webhook vraycluster.ray.openshift.ai: the CA expires at"))
+	})
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/project-codeflare/codeflare-operator/pkg/controllers"
+)
+
+// jobLogs prints the logs of the RayJob, read from the jobs API of the dashboard of its RayCluster,
+// at the URL the operator exposes it at.
+func jobLogs(ctx context.Context, o *Options, args []string) error {
+	rayJob := &rayv1.RayJob{}
+	if err := o.Client.Get(ctx, client.ObjectKey{Namespace: o.Namespace, Name: args[0]}, rayJob); err != nil {
+		return fmt.Errorf("cannot get RayJob: %w", err)
+	}
+	if rayJob.Status.JobId == "" || rayJob.Status.RayClusterName == "" {
+		return fmt.Errorf("RayJob %s has not been submitted yet, its deployment status is %s", rayJob.Name, valueOrNone(string(rayJob.Status.JobDeploymentStatus)))
+	}
+	rayCluster := &rayv1.RayCluster{}
+	if err := o.Client.Get(ctx, client.ObjectKey{Namespace: o.Namespace, Name: rayJob.Status.RayClusterName}, rayCluster); err != nil {
+		return fmt.Errorf("cannot get RayCluster of RayJob %s: %w", rayJob.Name, err)
+	}
+	dashboardURL, ok := rayCluster.Annotations[controllers.DashboardURLAnnotation]
+	if !ok {
+		return fmt.Errorf("the dashboard of RayCluster %s is not exposed yet", rayCluster.Name)
+	}
+
+	logs, err := o.rayJobLogs(ctx, dashboardURL, rayJob.Status.JobId)
+	if err != nil {
+		return fmt.Errorf("cannot get logs of RayJob %s: %w", rayJob.Name, err)
+	}
+	_, err = io.WriteString(o.Out, logs)
+	return err
+}
+
+// rayJobLogs returns the logs of the Ray job with the ID, read from the jobs API of the dashboard at the URL.
+func (o *Options) rayJobLogs(ctx context.Context, dashboardURL, jobID string) (string, error) {
+	logs := struct {
+		Logs string `json:"logs"`
+	}{}
+	if err := o.getDashboard(ctx, dashboardURL, "/api/jobs/"+url.PathEscape(jobID)+"/logs", &logs); err != nil {
+		return "", err
+	}
+	return logs.Logs, nil
+}
+
+// getDashboard requests the path of the dashboard API at the URL, authenticated with the bearer token,
+// and decodes the JSON response into the result.
+func (o *Options) getDashboard(ctx context.Context, dashboardURL, path string, result any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(dashboardURL, "/")+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if o.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+o.Token)
+	}
+	resp, err := o.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("dashboard returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
@@ -0,0 +1,46 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+)
+
+func queueStatus(ctx context.Context, o *Options, _ []string) error {
+	localQueues := &kueue.LocalQueueList{}
+	if err := o.Client.List(ctx, localQueues, o.listOptions()...); err != nil {
+		return fmt.Errorf("cannot list LocalQueues: %w", err)
+	}
+	if len(localQueues.Items) == 0 {
+		fmt.Fprintln(o.Out, "No LocalQueues found")
+		return nil
+	}
+
+	t := o.newTable("NAME", "CLUSTERQUEUE", "PENDING", "ADMITTED")
+	for _, localQueue := range localQueues.Items {
+		t.row(localQueue.Namespace,
+			localQueue.Name,
+			string(localQueue.Spec.ClusterQueue),
+			itoa(localQueue.Status.PendingWorkloads),
+			itoa(localQueue.Status.AdmittedWorkloads),
+		)
+	}
+	return t.flush()
+}
@@ -0,0 +1,204 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/project-codeflare/codeflare-operator/pkg/controllers"
+)
+
+const (
+	// scriptMountPath is where the script is mounted into the submitter pod, and uploaded from,
+	// as the working directory of the job, to the RayCluster
+	scriptMountPath = "/home/ray/jobs"
+	// maxScriptSize is the maximum size of the data of a ConfigMap
+	maxScriptSize = 1024 * 1024
+)
+
+// submitPollInterval is how often the RayJob, and its logs, are polled for until it completes.
+var submitPollInterval = 2 * time.Second
+
+var invalidNameCharacters = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// submitJob packages the local script into a ConfigMap, and submits it as a RayJob to the existing RayCluster,
+// then waits for the RayJob to complete, streaming its logs, unless it's told not to.
+func submitJob(ctx context.Context, o *Options, args []string) error {
+	clusterName, script := args[0], args[1]
+	content, err := os.ReadFile(script)
+	if err != nil {
+		return fmt.Errorf("cannot read script: %w", err)
+	}
+	if len(content) > maxScriptSize {
+		return fmt.Errorf("script %s is larger than the %d bytes a ConfigMap can hold", script, maxScriptSize)
+	}
+
+	rayCluster := &rayv1.RayCluster{}
+	if err := o.Client.Get(ctx, client.ObjectKey{Namespace: o.Namespace, Name: clusterName}, rayCluster); err != nil {
+		return fmt.Errorf("cannot get RayCluster: %w", err)
+	}
+	if len(rayCluster.Spec.HeadGroupSpec.Template.Spec.Containers) == 0 {
+		return fmt.Errorf("RayCluster %s has no head container", rayCluster.Name)
+	}
+
+	rayJob := newRayJob(o.JobName, rayCluster, filepath.Base(script))
+	if err := o.Client.Create(ctx, rayJob); err != nil {
+		return fmt.Errorf("cannot create RayJob: %w", err)
+	}
+	// The ConfigMap is owned by the RayJob, so that it's garbage collected with it
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: rayJob.Namespace,
+			Name:      rayJob.Name,
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: rayv1.GroupVersion.String(),
+				Kind:       "RayJob",
+				Name:       rayJob.Name,
+				UID:        rayJob.UID,
+			}},
+		},
+		BinaryData: map[string][]byte{filepath.Base(script): content},
+		Immutable:  ptr.To(true),
+	}
+	if err := o.Client.Create(ctx, configMap); err != nil {
+		_ = o.Client.Delete(ctx, rayJob)
+		return fmt.Errorf("cannot create ConfigMap of RayJob %s: %w", rayJob.Name, err)
+	}
+	fmt.Fprintf(o.Out, "RayJob %s submitted to RayCluster %s\n", rayJob.Name, rayCluster.Name)
+
+	if !o.Wait {
+		return nil
+	}
+	return o.waitForJob(ctx, rayJob, rayCluster)
+}
+
+// newRayJob returns the RayJob that runs the script, mounted into its submitter pod, on the RayCluster.
+// Its name is generated from the script file name, unless it's given.
+func newRayJob(name string, rayCluster *rayv1.RayCluster, scriptName string) *rayv1.RayJob {
+	// The name is generated client-side, as the ConfigMap, named after the RayJob, is mounted into the submitter pod
+	if name == "" {
+		prefix := strings.Trim(invalidNameCharacters.ReplaceAllString(strings.ToLower(strings.TrimSuffix(scriptName, filepath.Ext(scriptName))), "-"), "-")
+		if prefix == "" {
+			prefix = "rayjob"
+		}
+		name = prefix[:min(len(prefix), 40)] + "-" + utilrand.String(5)
+	}
+	return &rayv1.RayJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: rayCluster.Namespace,
+			Name:      name,
+		},
+		Spec: rayv1.RayJobSpec{
+			Entrypoint:     "python " + scriptName,
+			RuntimeEnvYAML: "working_dir: " + scriptMountPath + "\n",
+			ClusterSelector: map[string]string{
+				controllers.RayJobClusterSelectorKey: rayCluster.Name,
+			},
+			SubmitterPodTemplate: &corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyNever,
+					Containers: []corev1.Container{
+						{
+							Name:  "ray-job-submitter",
+							Image: rayCluster.Spec.HeadGroupSpec.Template.Spec.Containers[0].Image,
+							VolumeMounts: []corev1.VolumeMount{
+								{
+									Name:      "script",
+									MountPath: scriptMountPath,
+								},
+							},
+						},
+					},
+					Volumes: []corev1.Volume{
+						{
+							Name: "script",
+							VolumeSource: corev1.VolumeSource{
+								ConfigMap: &corev1.ConfigMapVolumeSource{
+									LocalObjectReference: corev1.LocalObjectReference{Name: name},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// waitForJob polls the RayJob until it completes, printing its new logs, read from the dashboard of the RayCluster,
+// and returns an error if it doesn't succeed.
+func (o *Options) waitForJob(ctx context.Context, rayJob *rayv1.RayJob, rayCluster *rayv1.RayCluster) error {
+	ticker := time.NewTicker(submitPollInterval)
+	defer ticker.Stop()
+
+	// printed is the length of the logs already printed, as the dashboard returns the whole logs
+	printed := 0
+	for {
+		if err := o.Client.Get(ctx, client.ObjectKeyFromObject(rayJob), rayJob); err != nil {
+			return fmt.Errorf("cannot get RayJob: %w", err)
+		}
+		if err := o.Client.Get(ctx, client.ObjectKeyFromObject(rayCluster), rayCluster); err != nil {
+			return fmt.Errorf("cannot get RayCluster of RayJob %s: %w", rayJob.Name, err)
+		}
+		terminal := rayv1.IsJobTerminal(rayJob.Status.JobStatus)
+		dashboardURL, exposed := rayCluster.Annotations[controllers.DashboardURLAnnotation]
+		if exposed && rayJob.Status.JobId != "" {
+			logs, err := o.rayJobLogs(ctx, dashboardURL, rayJob.Status.JobId)
+			switch {
+			// The job may not be known by the dashboard until it's been submitted by the submitter pod
+			case err != nil && terminal:
+				return fmt.Errorf("cannot get logs of RayJob %s: %w", rayJob.Name, err)
+			case err == nil && len(logs) > printed:
+				if _, err := io.WriteString(o.Out, logs[printed:]); err != nil {
+					return err
+				}
+				printed = len(logs)
+			}
+		}
+
+		switch {
+		case rayJob.Status.JobStatus == rayv1.JobStatusSucceeded:
+			fmt.Fprintf(o.Out, "RayJob %s succeeded\n", rayJob.Name)
+			return nil
+		case terminal:
+			return fmt.Errorf("RayJob %s %s: %s", rayJob.Name, strings.ToLower(string(rayJob.Status.JobStatus)), valueOrNone(rayJob.Status.Message))
+		case rayJob.Status.JobDeploymentStatus == rayv1.JobDeploymentStatusFailed:
+			return fmt.Errorf("RayJob %s failed to be submitted: %s", rayJob.Name, valueOrNone(rayJob.Status.Message))
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
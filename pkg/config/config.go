@@ -19,6 +19,9 @@ package config
 import (
 	awconfig "github.com/project-codeflare/appwrapper/pkg/config"
 
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	configv1alpha1 "k8s.io/component-base/config/v1alpha1"
 )
 
@@ -33,6 +36,85 @@ type CodeFlareOperatorConfiguration struct {
 	KubeRay *KubeRayConfiguration `json:"kuberay,omitempty"`
 
 	AppWrapper *AppWrapperConfiguration `json:"appwrapper,omitempty"`
+
+	// AdmissionCheckRetry configures the automatic re-admission of Workloads
+	// rejected by transient AdmissionCheck failures.
+	// +optional
+	AdmissionCheckRetry *AdmissionCheckRetryConfiguration `json:"admissionCheckRetry,omitempty"`
+
+	// Webhook configures how the API server calls the webhooks of the operator.
+	// +optional
+	Webhook *WebhookConfiguration `json:"webhook,omitempty"`
+
+	// ModelRegistry configures the registration of the models trained by RayJobs in a model registry.
+	// +optional
+	ModelRegistry *ModelRegistryConfiguration `json:"modelRegistry,omitempty"`
+}
+
+// ModelRegistryConfiguration defines the model registry the models trained by the RayJobs annotated with
+// their output path, e.g., an S3 URI, are registered in, once the RayJobs have succeeded.
+type ModelRegistryConfiguration struct {
+	// Enabled controls whether the models trained by the RayJobs are registered.
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// URL is the URL of the REST API of the Kubeflow model registry, e.g., https://modelregistry.example.com:8443.
+	URL string `json:"url"`
+
+	// BearerTokenFile is the path of a file holding the bearer token the requests to the model registry are
+	// authenticated with, e.g., the token of the operator service account. It's read on each request, so that
+	// rotated tokens are picked up.
+	// +optional
+	BearerTokenFile string `json:"bearerTokenFile,omitempty"`
+}
+
+// WebhookConfiguration defines how the API server calls the webhooks of the operator. It's applied by the operator
+// to all the webhooks of its MutatingWebhookConfiguration and ValidatingWebhookConfiguration.
+type WebhookConfiguration struct {
+	// FailurePolicy defines how the API server handles the errors calling the webhooks, i.e., Fail to reject
+	// the requests, or Ignore to admit them without mutating nor validating them. Defaults to Fail.
+	// +optional
+	FailurePolicy *admissionregistrationv1.FailurePolicyType `json:"failurePolicy,omitempty"`
+
+	// ExcludedNamespaces lists the namespaces whose requests aren't sent to the webhooks, e.g., the system namespaces.
+	// +optional
+	ExcludedNamespaces []string `json:"excludedNamespaces,omitempty"`
+
+	// NamespaceSelector restricts the requests sent to the webhooks to the ones for the resources in the namespaces
+	// matching the selector. The excluded namespaces are added to its match expressions.
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+
+	// ObjectSelector restricts the requests sent to the webhooks to the ones for the resources matching the selector.
+	// +optional
+	ObjectSelector *metav1.LabelSelector `json:"objectSelector,omitempty"`
+}
+
+// AdmissionCheckRetryConfiguration defines how Workloads rejected by transient AdmissionCheck failures,
+// e.g., a provisioning timeout, are re-admitted.
+type AdmissionCheckRetryConfiguration struct {
+	// Enabled controls whether Workloads rejected by transient AdmissionCheck failures are re-admitted,
+	// by deleting them so that they are re-created for their suspended owner and evaluated again.
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// AdmissionChecks lists the names of the AdmissionChecks whose rejections are considered transient.
+	// When empty, the rejections of all the AdmissionChecks are considered transient.
+	// +optional
+	AdmissionChecks []string `json:"admissionChecks,omitempty"`
+
+	// MaxRetries is the maximum number of re-admissions of the Workloads of an owner,
+	// until one of them is admitted. Defaults to 5.
+	// +optional
+	MaxRetries *int32 `json:"maxRetries,omitempty"`
+
+	// InitialBackoff is the delay before the first re-admission, that doubles on each retry. Defaults to 30s.
+	// +optional
+	InitialBackoff *metav1.Duration `json:"initialBackoff,omitempty"`
+
+	// MaxBackoff caps the delay between re-admissions. Defaults to 10m.
+	// +optional
+	MaxBackoff *metav1.Duration `json:"maxBackoff,omitempty"`
 }
 
 type AppWrapperConfiguration struct {
@@ -52,6 +134,420 @@ type KubeRayConfiguration struct {
 	MTLSEnabled *bool `json:"mTLSEnabled,omitempty"`
 
 	CertGeneratorImage string `json:"certGeneratorImage"`
+
+	// RejectRayVersionSkew controls whether RayClusters whose head and worker
+	// images reference different Ray versions are rejected at admission.
+	// When unset or false, a warning is returned to the user instead.
+	// +optional
+	RejectRayVersionSkew *bool `json:"rejectRayVersionSkew,omitempty"`
+
+	// AdmissionPolicyMode selects how the admission policies, i.e., RejectRayVersionSkew and the prohibition of the
+	// privileged pod settings, are applied, either Enforce to reject the RayClusters violating them, or Audit to admit
+	// them, and only log the violations, return them as warnings, and count them in the
+	// codeflare_webhook_policy_violations_total metric, so that the policies can be evaluated against live traffic
+	// before they're enforced. Defaults to Enforce.
+	// +optional
+	AdmissionPolicyMode AdmissionPolicyModeType `json:"admissionPolicyMode,omitempty"`
+
+	// PrivilegedNamespaces lists the namespaces whose RayClusters are allowed to use hostPath volumes, the host
+	// network, or PID, namespace, and privileged containers in their Ray pod templates. The RayClusters of the
+	// other namespaces using any of them are rejected.
+	// +optional
+	PrivilegedNamespaces []string `json:"privilegedNamespaces,omitempty"`
+
+	// RayCompatibility configures the validation of the Ray versions of the RayClusters against
+	// the version of the KubeRay operator.
+	// +optional
+	RayCompatibility *RayCompatibilityConfiguration `json:"rayCompatibility,omitempty"`
+
+	// ImageVerification configures the verification of the cosign signatures of the Ray images at admission.
+	// +optional
+	ImageVerification *ImageVerificationConfiguration `json:"imageVerification,omitempty"`
+
+	// RestrictedPodSecurityEnabled controls whether the security context of Ray pods
+	// is defaulted so that they comply with the restricted Pod Security Standard.
+	// +optional
+	RestrictedPodSecurityEnabled *bool `json:"restrictedPodSecurityEnabled,omitempty"`
+
+	// Proxy contains the HTTP proxy settings injected into the Ray head, worker and submitter containers.
+	// When unset on OpenShift, it defaults to the cluster-wide Proxy configuration.
+	// +optional
+	Proxy *ProxyConfiguration `json:"proxy,omitempty"`
+
+	// DashboardExposure selects how the Ray dashboard and client are exposed, either Route or Ingress.
+	// When unset, Routes are used on OpenShift with the dashboard OAuth proxy enabled, and Ingresses
+	// on vanilla Kubernetes with the dashboard OAuth proxy disabled. Ingresses require the dashboard
+	// OAuth proxy to be disabled on OpenShift, as they route to the Ray dashboard directly.
+	// Changing it migrates the exposure of existing RayClusters.
+	// +optional
+	DashboardExposure DashboardExposureType `json:"dashboardExposure,omitempty"`
+
+	// TrustedCABundle configures the mounting of a trusted CA bundle into the Ray containers.
+	// +optional
+	TrustedCABundle *TrustedCABundleConfiguration `json:"trustedCABundle,omitempty"`
+
+	// DashboardAuditLog configures the audit logging of the requests to the Ray dashboard,
+	// that go through the OAuth proxy.
+	// +optional
+	DashboardAuditLog *DashboardAuditLogConfiguration `json:"dashboardAuditLog,omitempty"`
+
+	// DashboardRBACEnabled controls whether access to the Ray dashboard is authorized against the
+	// rayclusters/dashboard virtual subresource, so that it can be granted per RayCluster or namespace.
+	// When unset or false, users that can get pods in the RayCluster namespace are granted access.
+	// +optional
+	DashboardRBACEnabled *bool `json:"dashboardRBACEnabled,omitempty"`
+
+	// DashboardHardening configures the exposure policies enforced on the Routes of the Ray dashboard, and client,
+	// and on the OAuth proxy of the dashboard.
+	// +optional
+	DashboardHardening *DashboardHardeningConfiguration `json:"dashboardHardening,omitempty"`
+
+	// CookieSecretRotation configures the rotation of the cookie secrets of the OAuth proxies of the Ray dashboards.
+	// +optional
+	CookieSecretRotation *CookieSecretRotationConfiguration `json:"cookieSecretRotation,omitempty"`
+
+	// AcceleratorRuntimeEnvs is the catalog of the pip settings appended to the runtime env of the RayJobs
+	// whose RayCluster requests a given accelerator, e.g., the PyTorch ROCm wheels index for AMD GPUs.
+	// The runtime envs are left unchanged when it's unset.
+	// +optional
+	AcceleratorRuntimeEnvs []AcceleratorRuntimeEnvConfiguration `json:"acceleratorRuntimeEnvs,omitempty"`
+
+	// RayImages maps the CPU architectures, as set in the kubernetes.io/arch node label, e.g., amd64 or arm64,
+	// to the Ray image built for them. The Ray container of the head, and worker groups, whose pods are
+	// constrained to an architecture, is set to the image of that architecture, when it has no image, or runs
+	// another image of the map, so that the same RayCluster can, e.g., run its head on amd64 nodes and workers on arm64 GPU nodes.
+	// +optional
+	RayImages map[string]string `json:"rayImages,omitempty"`
+
+	// GangSchedulingVerification configures the verification that all the pods of the RayClusters
+	// admitted by Kueue, or deployed by AppWrappers, get scheduled.
+	// +optional
+	GangSchedulingVerification *GangSchedulingVerificationConfiguration `json:"gangSchedulingVerification,omitempty"`
+
+	// KarpenterHintsEnabled controls whether the Karpenter node selectors, i.e., the capacity type, instance family
+	// and GPU type, set as codeflare.dev/karpenter-* annotations on the namespaces, are added to the worker groups
+	// of the RayClusters created in those namespaces, so that Karpenter provisions the nodes they're meant to run on.
+	// +optional
+	KarpenterHintsEnabled *bool `json:"karpenterHintsEnabled,omitempty"`
+
+	// RayStartParamsFromResourcesEnabled controls whether the num-cpus, num-gpus and memory Ray start parameters
+	// the head and worker groups don't set are defaulted from the resource limits of their Ray container, so that
+	// Ray doesn't schedule more tasks than the cgroup limits of the container allow.
+	// +optional
+	RayStartParamsFromResourcesEnabled *bool `json:"rayStartParamsFromResourcesEnabled,omitempty"`
+
+	// ObjectStore configures the sizing of the Ray object store, and of the shared memory it's backed by,
+	// from the memory limit of the Ray containers.
+	// +optional
+	ObjectStore *ObjectStoreConfiguration `json:"objectStore,omitempty"`
+
+	// ScratchVolume configures the shared scratch volume, i.e., a ReadWriteMany PersistentVolumeClaim that's
+	// provisioned for the RayClusters annotated with codeflare.dev/scratch-volume=true, and mounted in all their pods.
+	// +optional
+	ScratchVolume *ScratchVolumeConfiguration `json:"scratchVolume,omitempty"`
+
+	// NotebookNamespaces declares the namespaces of the notebooks, e.g., the RHOAI workbenches, that connect to
+	// the RayClusters of other namespaces. The NetworkPolicies of the RayClusters allow the Ray client, and dashboard,
+	// traffic from those namespaces, in addition to the namespace of the RayCluster.
+	// +optional
+	NotebookNamespaces *NotebookNamespacesConfiguration `json:"notebookNamespaces,omitempty"`
+
+	// SharedClusterNamespaces are the namespaces of the RayClusters shared by the tenants. RayJobs can only target
+	// the RayClusters of their own namespace, or of these namespaces, whose NetworkPolicies allow the Ray client,
+	// and dashboard, traffic from all the namespaces.
+	// +optional
+	SharedClusterNamespaces []string `json:"sharedClusterNamespaces,omitempty"`
+}
+
+// ObjectStoreConfiguration defines the sizing of the Ray object store, and of the /dev/shm memory-backed volume
+// it's allocated in, so that Ray doesn't fall back to a much slower object store on disk, in /tmp, when /dev/shm
+// is smaller than the object store.
+type ObjectStoreConfiguration struct {
+	// Enabled controls whether the object-store-memory Ray start parameter, that the head and worker groups don't
+	// set, is defaulted from the memory limit of their Ray container, and /dev/shm is sized accordingly.
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// MemoryPercentage is the percentage of the memory limit of the Ray container that's allocated
+	// to the object store. Defaults to 30, as Ray does.
+	// +optional
+	MemoryPercentage *int32 `json:"memoryPercentage,omitempty"`
+}
+
+// NotebookNamespacesConfiguration selects the namespaces of the notebooks, either by name, or by label,
+// e.g., opendatahub.io/dashboard=true for the RHOAI data science projects.
+type NotebookNamespacesConfiguration struct {
+	// Names are the names of the notebook namespaces.
+	// +optional
+	Names []string `json:"names,omitempty"`
+
+	// Selector selects the notebook namespaces by label.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+}
+
+// ScratchVolumeConfiguration defines the shared scratch volume of the RayClusters, that gives the Ray jobs
+// a filesystem shared by all the Ray pods, e.g., to write checkpoints to.
+type ScratchVolumeConfiguration struct {
+	// Enabled controls whether the scratch volume is provisioned for the RayClusters that request it.
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// Size is the storage requested by the scratch volume, unless the RayCluster overrides it
+	// with the codeflare.dev/scratch-volume-size annotation. Defaults to 10Gi.
+	// +optional
+	Size string `json:"size,omitempty"`
+
+	// StorageClassName is the storage class of the scratch volume, that must support the ReadWriteMany access mode,
+	// unless the RayCluster overrides it with the codeflare.dev/scratch-volume-storage-class annotation.
+	// When unset, the default storage class is used.
+	// +optional
+	StorageClassName string `json:"storageClassName,omitempty"`
+
+	// MountPath is the path the scratch volume is mounted at in the Ray containers. Defaults to /home/ray/scratch.
+	// +optional
+	MountPath string `json:"mountPath,omitempty"`
+}
+
+// GangSchedulingVerificationConfiguration defines how the scheduling of the pods of admitted RayClusters is verified.
+// A RayCluster whose pods are not all scheduled within the timeout is flagged with a PartiallyScheduled condition,
+// as it holds resources while it may never be able to run, e.g., when the quota doesn't match the cluster capacity.
+type GangSchedulingVerificationConfiguration struct {
+	// Enabled controls whether the scheduling of the pods of admitted RayClusters is verified.
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// Timeout is the period, from the admission of the RayCluster, within which all its pods
+	// must be scheduled. Defaults to 5m.
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+
+	// ResetAppWrapper controls whether the admission and warmup grace periods of the AppWrapper of a
+	// partially scheduled RayCluster are lowered to the time elapsed since its deployment, so that the
+	// AppWrapper controller resets it, releasing its resources and re-deploying the RayCluster after the
+	// retry pause. The AppWrapper retry limit applies.
+	// +optional
+	ResetAppWrapper *bool `json:"resetAppWrapper,omitempty"`
+}
+
+// AcceleratorRuntimeEnvConfiguration defines the pip settings required by the Ray jobs running on an accelerator.
+type AcceleratorRuntimeEnvConfiguration struct {
+	// ResourceName is the extended resource name of the accelerator, e.g., amd.com/gpu.
+	ResourceName corev1.ResourceName `json:"resourceName"`
+
+	// PipExtraIndexURLs are the URLs of the package indexes, added as extra indexes to the pip install.
+	// +optional
+	PipExtraIndexURLs []string `json:"pipExtraIndexURLs,omitempty"`
+
+	// PipPackages are the pip requirements appended to the runtime env, e.g., torch==2.3.1+rocm6.0.
+	// The requirements of packages the runtime env already lists are not appended.
+	// +optional
+	PipPackages []string `json:"pipPackages,omitempty"`
+}
+
+// TrustedCABundleConfiguration defines the trusted CA bundle mounted into the Ray containers,
+// so that jobs can verify the certificates of internal services, like S3 endpoints or PyPI mirrors.
+type TrustedCABundleConfiguration struct {
+	// Enabled controls whether the trusted CA bundle is mounted into the Ray containers,
+	// with the SSL_CERT_FILE and REQUESTS_CA_BUNDLE environment variables pointing to it.
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// ConfigMapName is the name of the ConfigMap, in the RayCluster namespace, that holds the CA bundle.
+	// The bundle replaces the default trust store, so it must also contain the public CAs that are needed.
+	// The Ray pods don't start until the ConfigMap holds the bundle.
+	// When unset on OpenShift, a ConfigMap is created for each RayCluster, into which the cluster trust bundle is injected.
+	// +optional
+	ConfigMapName string `json:"configMapName,omitempty"`
+
+	// Key is the key of the CA bundle in the ConfigMap. Defaults to ca-bundle.crt.
+	// +optional
+	Key string `json:"key,omitempty"`
+}
+
+// DashboardAuditLogConfiguration defines the audit logging of the requests to the Ray dashboard.
+type DashboardAuditLogConfiguration struct {
+	// Enabled controls whether the requests to the Ray dashboard are logged by the OAuth proxy,
+	// and collected by the operator as structured audit entries.
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// WebhookURL is the URL of an HTTP endpoint the audit entries are POSTed to as JSON,
+	// in addition to being written to the operator logs.
+	// +optional
+	WebhookURL string `json:"webhookURL,omitempty"`
+}
+
+// DashboardHardeningConfiguration defines the exposure policies of the Ray dashboard, and client, Routes.
+type DashboardHardeningConfiguration struct {
+	// Timeout is the server timeout of the dashboard Route, e.g., 30s, after which the router closes the connections
+	// the dashboard doesn't respond to.
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+
+	// IPAllowlist are the IP addresses, and CIDR ranges, the dashboard, and client, Routes only accept connections from.
+	// +optional
+	IPAllowlist []string `json:"ipAllowlist,omitempty"`
+
+	// RateLimit configures the rate limiting, per client IP, of the connections to the dashboard, and client, Routes.
+	// +optional
+	RateLimit *RouteRateLimitConfiguration `json:"rateLimit,omitempty"`
+
+	// HSTSMaxAge is the max-age of the Strict-Transport-Security header of the dashboard responses, including the
+	// subdomains. HSTS isn't enabled when it's not set.
+	// +optional
+	HSTSMaxAge *metav1.Duration `json:"hstsMaxAge,omitempty"`
+
+	// CookieSameSite is the SameSite attribute of the session cookie of the OAuth proxy, i.e., Strict, Lax or None.
+	// The session cookie is always secure, and HTTP only.
+	// +optional
+	CookieSameSite string `json:"cookieSameSite,omitempty"`
+
+	// CookieExpire is the lifetime of the session cookie of the OAuth proxy, that defaults to 168h.
+	// +optional
+	CookieExpire *metav1.Duration `json:"cookieExpire,omitempty"`
+
+	// CookieRefresh is the duration after which the session cookie of the OAuth proxy is refreshed,
+	// and the access of the user re-authorized. It's not refreshed when it's not set.
+	// +optional
+	CookieRefresh *metav1.Duration `json:"cookieRefresh,omitempty"`
+}
+
+// CookieSecretRotationConfiguration defines the rotation of the cookie secrets of the OAuth proxies.
+type CookieSecretRotationConfiguration struct {
+	// Enabled controls whether the cookie secrets are randomly generated, and rotated, by the operator, rather than
+	// derived from the name of the RayClusters, and a salt generated on each start of the operator. The OAuth proxies
+	// are restarted once their cookie secret is rotated, which signs the users out of the dashboards.
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// Interval is the period between the rotations of the cookie secret of a RayCluster. Defaults to 720h.
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+}
+
+// RouteRateLimitConfiguration defines the rate limiting, per client IP, of the connections to the Routes.
+type RouteRateLimitConfiguration struct {
+	// ConcurrentConnections is the number of concurrent TCP connections a client IP can open.
+	// +optional
+	ConcurrentConnections *int32 `json:"concurrentConnections,omitempty"`
+
+	// ConnectionRate is the number of TCP connections a client IP can open within 3 seconds.
+	// +optional
+	ConnectionRate *int32 `json:"connectionRate,omitempty"`
+
+	// RequestRate is the number of HTTP requests a client IP can send to the dashboard within 3 seconds.
+	// +optional
+	RequestRate *int32 `json:"requestRate,omitempty"`
+}
+
+// RayCompatibilityConfiguration defines the validation of the Ray versions of the RayClusters, i.e., the Ray version
+// of their spec against the Ray version of their image tag, and the known-broken combinations of Ray and KubeRay versions.
+type RayCompatibilityConfiguration struct {
+	// Reject controls whether the RayClusters with incompatible versions are rejected at admission,
+	// according to the admission policy mode. When unset or false, a warning is returned to the user instead.
+	// +optional
+	Reject *bool `json:"reject,omitempty"`
+
+	// KubeRayVersion is the version of the KubeRay operator. When unset, it's detected from the image tag
+	// of the KubeRay operator Deployment, labelled with app.kubernetes.io/component=kuberay-operator.
+	// +optional
+	KubeRayVersion string `json:"kubeRayVersion,omitempty"`
+
+	// Incompatibilities lists the known-broken combinations of Ray and KubeRay versions.
+	// +optional
+	Incompatibilities []RayIncompatibility `json:"incompatibilities,omitempty"`
+}
+
+// ImageVerificationConfiguration defines how the cosign signatures of the Ray images, i.e., the images of the Ray
+// containers of the head and worker groups, are verified, so that only signed images run. The RayClusters whose Ray
+// images aren't signed by one of the public keys, or keyless identities, are rejected, according to the admission
+// policy mode.
+type ImageVerificationConfiguration struct {
+	// Enabled controls whether the signatures of the Ray images are verified.
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// PublicKeys are the PEM-encoded public keys, i.e., ECDSA, RSA or Ed25519 keys, the images can be signed with,
+	// e.g., with cosign sign --key.
+	// +optional
+	PublicKeys []string `json:"publicKeys,omitempty"`
+
+	// KeylessIdentities are the identities the images can be signed with keyless, i.e., with a certificate issued
+	// by Fulcio for the identity, whose signature is logged in Rekor.
+	// +optional
+	KeylessIdentities []KeylessIdentity `json:"keylessIdentities,omitempty"`
+
+	// FulcioRoots are the PEM-encoded root certificates of Fulcio the keyless certificates are verified against.
+	// It's required with keyless identities.
+	// +optional
+	FulcioRoots string `json:"fulcioRoots,omitempty"`
+
+	// RekorPublicKey is the PEM-encoded public key of Rekor the transparency log entries of the keyless signatures
+	// are verified against. It's required with keyless identities.
+	// +optional
+	RekorPublicKey string `json:"rekorPublicKey,omitempty"`
+}
+
+// KeylessIdentity is the identity of the certificates issued by Fulcio the images are signed keyless with.
+type KeylessIdentity struct {
+	// Issuer is the OIDC issuer of the identity, e.g., https://token.actions.githubusercontent.com.
+	Issuer string `json:"issuer"`
+
+	// Subject is the email, or URI, of the identity, e.g.,
+	// https://github.com/org/repo/.github/workflows/release.yaml@refs/heads/main.
+	Subject string `json:"subject"`
+}
+
+// RayIncompatibility defines a known-broken combination of Ray and KubeRay versions.
+type RayIncompatibility struct {
+	// RayVersions is the range of the Ray versions, e.g., ">=2.10.0 <2.12.0".
+	RayVersions string `json:"rayVersions"`
+
+	// KubeRayVersions is the range of the KubeRay versions, e.g., "<1.1.0". All the versions when unset.
+	// +optional
+	KubeRayVersions string `json:"kubeRayVersions,omitempty"`
+
+	// Reason explains why the versions are incompatible, and is returned to the user.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+}
+
+// AdmissionPolicyModeType defines how the admission policies are applied to the RayClusters violating them.
+type AdmissionPolicyModeType string
+
+const (
+	// EnforceAdmissionPolicyMode rejects the RayClusters violating the admission policies.
+	EnforceAdmissionPolicyMode AdmissionPolicyModeType = "Enforce"
+
+	// AuditAdmissionPolicyMode admits the RayClusters violating the admission policies, and records the violations.
+	AuditAdmissionPolicyMode AdmissionPolicyModeType = "Audit"
+)
+
+// DashboardExposureType defines how the Ray dashboard and client are exposed outside the cluster.
+type DashboardExposureType string
+
+const (
+	// RouteDashboardExposure exposes the Ray dashboard, through the OAuth proxy, and client with OpenShift Routes.
+	RouteDashboardExposure DashboardExposureType = "Route"
+
+	// IngressDashboardExposure exposes the Ray dashboard, directly, and client with Ingresses.
+	IngressDashboardExposure DashboardExposureType = "Ingress"
+)
+
+// ProxyConfiguration defines the egress proxy configuration.
+type ProxyConfiguration struct {
+	// HTTPProxy is the URL of the proxy for HTTP requests.
+	// +optional
+	HTTPProxy string `json:"httpProxy,omitempty"`
+
+	// HTTPSProxy is the URL of the proxy for HTTPS requests.
+	// +optional
+	HTTPSProxy string `json:"httpsProxy,omitempty"`
+
+	// NoProxy is a comma-separated list of hostnames and/or CIDRs for which the proxy should not be used.
+	// +optional
+	NoProxy string `json:"noProxy,omitempty"`
 }
 
 type ControllerManager struct {
@@ -63,9 +559,50 @@ type ControllerManager struct {
 	// +optional
 	Health HealthConfiguration `json:"health,omitempty"`
 
+	// PprofBindAddress is the TCP address the pprof profiles of the operator are served on, e.g., localhost:8082.
+	// It must be a loopback address, so that they are only reachable from within the pod.
+	// It can be set to "0" or "" to disable serving the profiles.
+	// +optional
+	PprofBindAddress string `json:"pprofBindAddress,omitempty"`
+
 	// LeaderElection is the LeaderElection config to be used when configuring
 	// the manager.Manager leader election
 	LeaderElection *configv1alpha1.LeaderElectionConfiguration `json:"leaderElection,omitempty"`
+
+	// Controller contains the global configuration of the controllers
+	// +optional
+	Controller *ControllerConfiguration `json:"controller,omitempty"`
+
+	// Cache contains the configuration of the informer caches of the controllers
+	// +optional
+	Cache *CacheConfiguration `json:"cache,omitempty"`
+}
+
+// ControllerConfiguration defines the global configuration of the controllers.
+type ControllerConfiguration struct {
+	// GroupKindConcurrency is a map from the kind of the resources the controllers reconcile,
+	// to the number of their concurrent reconciliations. Defaults to 1.
+	//
+	// The key is expected to be consistent in form with GroupKind.String(),
+	// e.g., RayCluster.ray.io, AppWrapper.workload.codeflare.dev, or Workload.kueue.x-k8s.io.
+	// +optional
+	GroupKindConcurrency map[string]int `json:"groupKindConcurrency,omitempty"`
+}
+
+// CacheConfiguration restricts the resources the controllers watch, and hold in their informer caches,
+// to the ones managed by the operator, so that the memory of the operator doesn't grow with the
+// workloads it doesn't manage. The Pods are always restricted to the ones of the AppWrappers.
+type CacheConfiguration struct {
+	// Namespaces restricts the namespaced resources the controllers watch to these namespaces.
+	// The webhooks leave the RayClusters and RayJobs of the other namespaces unchanged.
+	// Defaults to all the namespaces.
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// RayClusterLabelSelector restricts the RayClusters the operator reconciles, and the webhooks
+	// default and validate, to the ones matching the label selector. Defaults to all the RayClusters.
+	// +optional
+	RayClusterLabelSelector *metav1.LabelSelector `json:"rayClusterLabelSelector,omitempty"`
 }
 
 type ClientConnection struct {
@@ -84,6 +621,12 @@ type MetricsConfiguration struct {
 	// It can be set to "0" to disable the metrics serving.
 	// +optional
 	BindAddress string `json:"bindAddress,omitempty"`
+
+	// QueueMetricsEnabled controls whether the number of pending and admitted Workloads of each Kueue ClusterQueue,
+	// the resources they request, and the nominal quota of the ClusterQueue, are exported as metrics.
+	// The Workloads, LocalQueues and ClusterQueues are then watched by the operator.
+	// +optional
+	QueueMetricsEnabled *bool `json:"queueMetricsEnabled,omitempty"`
 }
 
 // HealthConfiguration defines the health configuration.
@@ -0,0 +1,73 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/project-codeflare/codeflare-operator/pkg/config"
+)
+
+const (
+	// rayVersionSkewPolicy rejects the RayClusters whose head and worker images reference different Ray versions
+	rayVersionSkewPolicy = "RayVersionSkew"
+	// privilegedPodPolicy rejects the RayClusters whose Ray pods use hostPath volumes, host namespaces, or privileged
+	// containers, outside the privileged namespaces
+	privilegedPodPolicy = "PrivilegedPod"
+)
+
+var policyViolations = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "codeflare",
+	Subsystem: "webhook",
+	Name:      "policy_violations_total",
+	Help:      "Number of RayClusters violating the admission policies, partitioned by policy and mode (Enforce or Audit).",
+}, []string{"policy", "mode"})
+
+func init() {
+	metrics.Registry.MustRegister(policyViolations)
+}
+
+// appendPolicyViolations rejects the RayCluster with the violations of the admission policy, unless the policies
+// are applied in audit mode, in which case the RayCluster is admitted, and the violations are logged and returned
+// as warnings. The RayClusters violating the policy are counted in both modes.
+func appendPolicyViolations(warnings admission.Warnings, allErrors field.ErrorList, rayCluster *rayv1.RayCluster,
+	cfg *config.KubeRayConfiguration, policy string, violations field.ErrorList) (admission.Warnings, field.ErrorList) {
+	if len(violations) == 0 {
+		return warnings, allErrors
+	}
+
+	mode := cfg.AdmissionPolicyMode
+	if mode == "" {
+		mode = config.EnforceAdmissionPolicyMode
+	}
+	policyViolations.WithLabelValues(policy, string(mode)).Inc()
+
+	if mode == config.EnforceAdmissionPolicyMode {
+		return warnings, append(allErrors, violations...)
+	}
+	rayclusterlog.Info("Admitting RayCluster violating admission policy in audit mode", "namespace", rayCluster.Namespace,
+		"name", rayCluster.Name, "policy", policy, "violations", violations.ToAggregate().Error())
+	for _, violation := range violations {
+		warnings = append(warnings, "Audit: "+violation.Error())
+	}
+	return warnings, allErrors
+}
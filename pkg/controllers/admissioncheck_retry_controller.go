@@ -0,0 +1,221 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+
+	"github.com/project-codeflare/codeflare-operator/pkg/config"
+)
+
+const (
+	admissionCheckRetryControllerName = "codeflare-admissioncheck-retry-controller"
+
+	// AdmissionRetriesAnnotation records, on the owner of a Workload, the number of times its Workloads have been
+	// re-admitted after transient AdmissionCheck failures. It is removed once one of its Workloads is admitted.
+	AdmissionRetriesAnnotation = "codeflare.dev/admission-retries"
+
+	defaultAdmissionCheckMaxRetries     = 5
+	defaultAdmissionCheckInitialBackoff = 30 * time.Second
+	defaultAdmissionCheckMaxBackoff     = 10 * time.Minute
+)
+
+// AdmissionCheckRetryReconciler re-admits the Workloads rejected by transient AdmissionCheck failures.
+// Kueue finishes a Workload as soon as one of its AdmissionChecks is rejected, which leaves its owner,
+// e.g., a RayCluster, suspended until it is re-created. The reconciler deletes the rejected Workload
+// instead, with an exponential backoff, so that Kueue re-creates it for the suspended owner and
+// evaluates it again.
+type AdmissionCheckRetryReconciler struct {
+	client.Client
+	// Reader reads the owners of the Workloads, that can be of any kind, without informing on them
+	Reader   client.Reader
+	Config   *config.AdmissionCheckRetryConfiguration
+	Recorder record.EventRecorder
+
+	now func() time.Time
+}
+
+// +kubebuilder:rbac:groups=kueue.x-k8s.io,resources=workloads,verbs=get;list;watch;delete
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+
+func (r *AdmissionCheckRetryReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := ctrl.LoggerFrom(ctx)
+
+	workload := &kueue.Workload{}
+	if err := r.Get(ctx, req.NamespacedName, workload); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if !workload.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	ownerRef := metav1.GetControllerOf(workload)
+	if ownerRef == nil {
+		return ctrl.Result{}, nil
+	}
+
+	if meta.IsStatusConditionTrue(workload.Status.Conditions, kueue.WorkloadAdmitted) {
+		return ctrl.Result{}, r.resetRetries(ctx, workload.Namespace, ownerRef)
+	}
+
+	finished := meta.FindStatusCondition(workload.Status.Conditions, kueue.WorkloadFinished)
+	if finished == nil || finished.Status != metav1.ConditionTrue || finished.Reason != kueue.WorkloadFinishedReasonAdmissionChecksRejected {
+		return ctrl.Result{}, nil
+	}
+	rejected := rejectedAdmissionChecks(workload)
+	if !r.isTransient(rejected) {
+		return ctrl.Result{}, nil
+	}
+
+	owner, err := r.getOwner(ctx, workload.Namespace, ownerRef)
+	if err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	retries, _ := strconv.Atoi(owner.Annotations[AdmissionRetriesAnnotation])
+	maxRetries := int(ptr.Deref(r.Config.MaxRetries, defaultAdmissionCheckMaxRetries))
+	if retries >= maxRetries {
+		logger.Info("Giving up re-admission after transient admission check failures", "retries", retries, "admissionChecks", rejected)
+		r.Recorder.Eventf(owner, corev1.EventTypeWarning, "AdmissionRetriesExhausted",
+			"Workload %s rejected by admission checks %s after %d retries, delete and re-create %s to retry",
+			workload.Name, strings.Join(rejected, ", "), retries, ownerRef.Name)
+		return ctrl.Result{}, nil
+	}
+
+	retryAt := finished.LastTransitionTime.Add(r.backoff(retries))
+	if wait := retryAt.Sub(r.clock()); wait > 0 {
+		logger.V(2).Info("Waiting before re-admitting Workload", "retryAt", retryAt)
+		return ctrl.Result{RequeueAfter: wait}, nil
+	}
+
+	// Record the retry first, so that the backoff keeps increasing should the Workload deletion fail
+	patch := client.MergeFrom(owner.DeepCopy())
+	metav1.SetMetaDataAnnotation(&owner.ObjectMeta, AdmissionRetriesAnnotation, strconv.Itoa(retries+1))
+	if err := r.Patch(ctx, owner, patch); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	err = r.Delete(ctx, workload, client.Preconditions{UID: &workload.UID})
+	if err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	logger.Info("Re-admitting Workload after transient admission check failures", "retry", retries+1, "admissionChecks", rejected)
+	r.Recorder.Eventf(owner, corev1.EventTypeNormal, "AdmissionRetry",
+		"Workload %s rejected by admission checks %s, retrying admission (%d/%d)",
+		workload.Name, strings.Join(rejected, ", "), retries+1, maxRetries)
+
+	return ctrl.Result{}, nil
+}
+
+// isTransient returns whether all the rejected AdmissionChecks are configured as transient.
+func (r *AdmissionCheckRetryReconciler) isTransient(rejected []string) bool {
+	if len(rejected) == 0 {
+		return false
+	}
+	if len(r.Config.AdmissionChecks) == 0 {
+		return true
+	}
+	for _, check := range rejected {
+		if !slices.Contains(r.Config.AdmissionChecks, check) {
+			return false
+		}
+	}
+	return true
+}
+
+// backoff returns the delay before the next retry, that doubles on each retry up to the configured maximum.
+func (r *AdmissionCheckRetryReconciler) backoff(retries int) time.Duration {
+	initial := defaultAdmissionCheckInitialBackoff
+	if r.Config.InitialBackoff != nil {
+		initial = r.Config.InitialBackoff.Duration
+	}
+	maximum := defaultAdmissionCheckMaxBackoff
+	if r.Config.MaxBackoff != nil {
+		maximum = r.Config.MaxBackoff.Duration
+	}
+	backoff := initial
+	for i := 0; i < retries && backoff < maximum; i++ {
+		backoff *= 2
+	}
+	return min(backoff, maximum)
+}
+
+func (r *AdmissionCheckRetryReconciler) clock() time.Time {
+	if r.now != nil {
+		return r.now()
+	}
+	return time.Now()
+}
+
+// resetRetries removes the retries annotation from the owner of an admitted Workload.
+func (r *AdmissionCheckRetryReconciler) resetRetries(ctx context.Context, namespace string, ownerRef *metav1.OwnerReference) error {
+	owner, err := r.getOwner(ctx, namespace, ownerRef)
+	if err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if _, ok := owner.Annotations[AdmissionRetriesAnnotation]; !ok {
+		return nil
+	}
+	patch := client.MergeFrom(owner.DeepCopy())
+	delete(owner.Annotations, AdmissionRetriesAnnotation)
+	return client.IgnoreNotFound(r.Patch(ctx, owner, patch))
+}
+
+func (r *AdmissionCheckRetryReconciler) getOwner(ctx context.Context, namespace string, ownerRef *metav1.OwnerReference) (*metav1.PartialObjectMetadata, error) {
+	gv, err := schema.ParseGroupVersion(ownerRef.APIVersion)
+	if err != nil {
+		return nil, fmt.Errorf("invalid owner reference %s: %w", ownerRef.Name, err)
+	}
+	owner := &metav1.PartialObjectMetadata{}
+	owner.SetGroupVersionKind(gv.WithKind(ownerRef.Kind))
+	if err := r.Reader.Get(ctx, client.ObjectKey{Namespace: namespace, Name: ownerRef.Name}, owner); err != nil {
+		return nil, err
+	}
+	return owner, nil
+}
+
+func rejectedAdmissionChecks(workload *kueue.Workload) []string {
+	var rejected []string
+	for _, check := range workload.Status.AdmissionChecks {
+		if check.State == kueue.CheckStateRejected {
+			rejected = append(rejected, check.Name)
+		}
+	}
+	return rejected
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *AdmissionCheckRetryReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(admissionCheckRetryControllerName).
+		For(&kueue.Workload{}).
+		Complete(instrument(admissionCheckRetryControllerName, "Workload", r))
+}
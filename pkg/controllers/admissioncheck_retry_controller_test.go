@@ -0,0 +1,170 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/project-codeflare/codeflare-common/support"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+
+	"github.com/project-codeflare/codeflare-operator/pkg/config"
+)
+
+func TestAdmissionCheckRetryReconcile(t *testing.T) {
+	test := support.NewTest(t)
+
+	scheme := runtime.NewScheme()
+	test.Expect(rayv1.AddToScheme(scheme)).To(Succeed())
+	test.Expect(kueue.AddToScheme(scheme)).To(Succeed())
+
+	// Condition timestamps are serialized with a second precision
+	now := time.Now().Truncate(time.Second)
+	finishedAt := now.Add(-time.Minute)
+
+	rayCluster := func(retries string) *rayv1.RayCluster {
+		rc := &rayv1.RayCluster{
+			TypeMeta:   metav1.TypeMeta{APIVersion: rayv1.GroupVersion.String(), Kind: "RayCluster"},
+			ObjectMeta: metav1.ObjectMeta{Name: "raycluster", Namespace: "ns", UID: "raycluster-uid"},
+		}
+		if retries != "" {
+			rc.Annotations = map[string]string{AdmissionRetriesAnnotation: retries}
+		}
+		return rc
+	}
+	rejectedWorkload := func(check string) *kueue.Workload {
+		return &kueue.Workload{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "raycluster-workload",
+				Namespace: "ns",
+				UID:       "workload-uid",
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion: rayv1.GroupVersion.String(),
+					Kind:       "RayCluster",
+					Name:       "raycluster",
+					UID:        "raycluster-uid",
+					Controller: ptr.To(true),
+				}},
+			},
+			Status: kueue.WorkloadStatus{
+				Conditions: []metav1.Condition{{
+					Type:               kueue.WorkloadFinished,
+					Status:             metav1.ConditionTrue,
+					Reason:             kueue.WorkloadFinishedReasonAdmissionChecksRejected,
+					LastTransitionTime: metav1.NewTime(finishedAt),
+				}},
+				AdmissionChecks: []kueue.AdmissionCheckState{{
+					Name:  check,
+					State: kueue.CheckStateRejected,
+				}},
+			},
+		}
+	}
+	reconcile := func(cfg *config.AdmissionCheckRetryConfiguration, objects ...client.Object) (ctrl.Result, client.Client, *record.FakeRecorder) {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+		recorder := record.NewFakeRecorder(10)
+		r := &AdmissionCheckRetryReconciler{Client: c, Reader: c, Config: cfg, Recorder: recorder, now: func() time.Time { return now }}
+		result, err := r.Reconcile(test.Ctx(), ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "ns", Name: "raycluster-workload"}})
+		test.Expect(err).NotTo(HaveOccurred())
+		return result, c, recorder
+	}
+	workloadDeleted := func(c client.Client) bool {
+		err := c.Get(test.Ctx(), client.ObjectKey{Namespace: "ns", Name: "raycluster-workload"}, &kueue.Workload{})
+		return errors.IsNotFound(err)
+	}
+	retries := func(c client.Client) string {
+		rc := &rayv1.RayCluster{}
+		test.Expect(c.Get(test.Ctx(), client.ObjectKey{Namespace: "ns", Name: "raycluster"}, rc)).To(Succeed())
+		return rc.Annotations[AdmissionRetriesAnnotation]
+	}
+
+	test.T().Run("Expected Workload rejected by a transient failure to be deleted after the backoff", func(t *testing.T) {
+		result, c, recorder := reconcile(&config.AdmissionCheckRetryConfiguration{}, rayCluster(""), rejectedWorkload("provisioning"))
+
+		test.Expect(result.RequeueAfter).To(BeZero())
+		test.Expect(workloadDeleted(c)).To(BeTrue())
+		test.Expect(retries(c)).To(Equal("1"))
+		test.Expect(recorder.Events).To(Receive(ContainSubstring("AdmissionRetry")))
+	})
+
+	test.T().Run("Expected Workload re-admission to be delayed until the backoff has elapsed", func(t *testing.T) {
+		result, c, _ := reconcile(&config.AdmissionCheckRetryConfiguration{}, rayCluster("2"), rejectedWorkload("provisioning"))
+
+		// 30s doubled twice, minus the minute elapsed since the Workload finished
+		test.Expect(result.RequeueAfter).To(Equal(time.Minute))
+		test.Expect(workloadDeleted(c)).To(BeFalse())
+		test.Expect(retries(c)).To(Equal("2"))
+	})
+
+	test.T().Run("Expected Workload rejected by a non-transient failure not to be re-admitted", func(t *testing.T) {
+		cfg := &config.AdmissionCheckRetryConfiguration{AdmissionChecks: []string{"provisioning"}}
+		_, c, _ := reconcile(cfg, rayCluster(""), rejectedWorkload("quota-approval"))
+
+		test.Expect(workloadDeleted(c)).To(BeFalse())
+		test.Expect(retries(c)).To(BeEmpty())
+	})
+
+	test.T().Run("Expected Workload not to be re-admitted once the retries are exhausted", func(t *testing.T) {
+		cfg := &config.AdmissionCheckRetryConfiguration{MaxRetries: ptr.To(int32(3))}
+		_, c, recorder := reconcile(cfg, rayCluster("3"), rejectedWorkload("provisioning"))
+
+		test.Expect(workloadDeleted(c)).To(BeFalse())
+		test.Expect(recorder.Events).To(Receive(ContainSubstring("AdmissionRetriesExhausted")))
+	})
+
+	test.T().Run("Expected retries to be reset once a Workload is admitted", func(t *testing.T) {
+		workload := rejectedWorkload("provisioning")
+		workload.Status.Conditions = []metav1.Condition{{
+			Type:   kueue.WorkloadAdmitted,
+			Status: metav1.ConditionTrue,
+			Reason: "Admitted",
+		}}
+		workload.Status.AdmissionChecks[0].State = kueue.CheckStateReady
+		_, c, _ := reconcile(&config.AdmissionCheckRetryConfiguration{}, rayCluster("2"), workload)
+
+		test.Expect(workloadDeleted(c)).To(BeFalse())
+		test.Expect(retries(c)).To(BeEmpty())
+	})
+}
+
+func TestAdmissionCheckRetryBackoff(t *testing.T) {
+	test := support.NewTest(t)
+
+	r := &AdmissionCheckRetryReconciler{Config: &config.AdmissionCheckRetryConfiguration{
+		InitialBackoff: &metav1.Duration{Duration: 10 * time.Second},
+		MaxBackoff:     &metav1.Duration{Duration: time.Minute},
+	}}
+
+	test.Expect(r.backoff(0)).To(Equal(10 * time.Second))
+	test.Expect(r.backoff(1)).To(Equal(20 * time.Second))
+	test.Expect(r.backoff(2)).To(Equal(40 * time.Second))
+	test.Expect(r.backoff(3)).To(Equal(time.Minute))
+	test.Expect(r.backoff(30)).To(Equal(time.Minute))
+}
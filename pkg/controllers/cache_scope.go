@@ -0,0 +1,66 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"slices"
+
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/project-codeflare/codeflare-operator/pkg/config"
+)
+
+// cacheScope is the scope of the informer caches of the operator, i.e., the namespaces and the RayClusters it manages.
+// The webhooks leave the resources outside of it unchanged, as they aren't reconciled by the operator.
+// The zero value includes all the resources.
+type cacheScope struct {
+	namespaces         []string
+	rayClusterSelector labels.Selector
+}
+
+func newCacheScope(cfg *config.CacheConfiguration) (cacheScope, error) {
+	scope := cacheScope{}
+	if cfg == nil {
+		return scope, nil
+	}
+	scope.namespaces = cfg.Namespaces
+	if cfg.RayClusterLabelSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(cfg.RayClusterLabelSelector)
+		if err != nil {
+			return cacheScope{}, fmt.Errorf("invalid RayCluster label selector: %w", err)
+		}
+		scope.rayClusterSelector = selector
+	}
+	return scope, nil
+}
+
+// includesNamespace returns whether the resources of the namespace are managed by the operator.
+func (s cacheScope) includesNamespace(namespace string) bool {
+	return len(s.namespaces) == 0 || slices.Contains(s.namespaces, namespace)
+}
+
+// includesRayCluster returns whether the RayCluster is managed by the operator.
+func (s cacheScope) includesRayCluster(rayCluster *rayv1.RayCluster) bool {
+	if !s.includesNamespace(rayCluster.Namespace) {
+		return false
+	}
+	return s.rayClusterSelector == nil || s.rayClusterSelector.Matches(labels.Set(rayCluster.Labels))
+}
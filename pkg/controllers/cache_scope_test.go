@@ -0,0 +1,75 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/project-codeflare/codeflare-common/support"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kueueconstants "sigs.k8s.io/kueue/pkg/controller/constants"
+
+	"github.com/project-codeflare/codeflare-operator/pkg/config"
+)
+
+func TestCacheScope(t *testing.T) {
+	test := support.NewTest(t)
+
+	rayCluster := func(namespace string, labels map[string]string) *rayv1.RayCluster {
+		return &rayv1.RayCluster{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "raycluster", Labels: labels}}
+	}
+
+	test.T().Run("Expected all the RayClusters to be included without cache configuration", func(t *testing.T) {
+		scope, err := newCacheScope(nil)
+		test.Expect(err).NotTo(HaveOccurred())
+		test.Expect(scope.includesNamespace("default")).To(BeTrue())
+		test.Expect(scope.includesRayCluster(rayCluster("default", nil))).To(BeTrue())
+	})
+
+	test.T().Run("Expected RayClusters in the namespaces and matching the selector to be included", func(t *testing.T) {
+		scope, err := newCacheScope(&config.CacheConfiguration{
+			Namespaces:              []string{"team-a"},
+			RayClusterLabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{kueueconstants.QueueLabel: "queue"}},
+		})
+		test.Expect(err).NotTo(HaveOccurred())
+		test.Expect(scope.includesNamespace("team-a")).To(BeTrue())
+		test.Expect(scope.includesRayCluster(rayCluster("team-a", map[string]string{kueueconstants.QueueLabel: "queue"}))).To(BeTrue())
+	})
+
+	test.T().Run("Negative: RayClusters outside the namespaces, or not matching the selector, are excluded", func(t *testing.T) {
+		scope, err := newCacheScope(&config.CacheConfiguration{
+			Namespaces:              []string{"team-a"},
+			RayClusterLabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{kueueconstants.QueueLabel: "queue"}},
+		})
+		test.Expect(err).NotTo(HaveOccurred())
+		test.Expect(scope.includesNamespace("team-b")).To(BeFalse())
+		test.Expect(scope.includesRayCluster(rayCluster("team-b", map[string]string{kueueconstants.QueueLabel: "queue"}))).To(BeFalse())
+		test.Expect(scope.includesRayCluster(rayCluster("team-a", nil))).To(BeFalse())
+	})
+
+	test.T().Run("Negative: invalid RayCluster label selectors are rejected", func(t *testing.T) {
+		_, err := newCacheScope(&config.CacheConfiguration{
+			RayClusterLabelSelector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: kueueconstants.QueueLabel, Operator: "Unknown"},
+			}},
+		})
+		test.Expect(err).To(MatchError(ContainSubstring("invalid RayCluster label selector")))
+	})
+}
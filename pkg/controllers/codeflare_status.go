@@ -0,0 +1,86 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	awv1beta2 "github.com/project-codeflare/appwrapper/api/v1beta2"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/project-codeflare/codeflare-operator/api/v1alpha1"
+)
+
+const (
+	appWrapperKind = "AppWrapper"
+
+	// unknownPhase is the phase the workloads, whose phase isn't reported yet, are counted in
+	unknownPhase = "Unknown"
+)
+
+// +kubebuilder:rbac:groups=codeflare.dev,resources=codeflarestatuses,verbs=get;create
+// +kubebuilder:rbac:groups=codeflare.dev,resources=codeflarestatuses/status,verbs=get;update;patch
+
+// workloadCounts returns the counts of the RayClusters, per state, and of the AppWrappers, per phase, sorted by kind
+// and phase. The kinds whose API isn't served are skipped.
+func (r *OperatorStatusReporter) workloadCounts(ctx context.Context) ([]v1alpha1.WorkloadCount, error) {
+	counts := map[v1alpha1.WorkloadCount]int32{}
+	count := func(kind, phase string) {
+		if phase == "" {
+			phase = unknownPhase
+		}
+		counts[v1alpha1.WorkloadCount{Kind: kind, Phase: phase}]++
+	}
+
+	rayClusters := &rayv1.RayClusterList{}
+	if err := r.List(ctx, rayClusters); err != nil && !isAPIMissing(err) {
+		return nil, err
+	}
+	for _, rayCluster := range rayClusters.Items {
+		count(rayClusterKind, string(rayCluster.Status.State))
+	}
+	appWrappers := &awv1beta2.AppWrapperList{}
+	if err := r.List(ctx, appWrappers); err != nil && !isAPIMissing(err) {
+		return nil, err
+	}
+	for _, appWrapper := range appWrappers.Items {
+		count(appWrapperKind, string(appWrapper.Status.Phase))
+	}
+
+	workloads := make([]v1alpha1.WorkloadCount, 0, len(counts))
+	for workload, n := range counts {
+		workload.Count = n
+		workloads = append(workloads, workload)
+	}
+	slices.SortFunc(workloads, func(a, b v1alpha1.WorkloadCount) int {
+		if c := strings.Compare(a.Kind, b.Kind); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Phase, b.Phase)
+	})
+	return workloads, nil
+}
+
+// isAPIMissing returns whether the error is returned for a kind whose API isn't served, or isn't registered.
+func isAPIMissing(err error) bool {
+	return meta.IsNoMatchError(err) || runtime.IsNotRegisteredError(err)
+}
@@ -0,0 +1,155 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	awv1beta2 "github.com/project-codeflare/appwrapper/api/v1beta2"
+	"github.com/project-codeflare/codeflare-common/support"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/project-codeflare/codeflare-operator/api/v1alpha1"
+)
+
+func TestReportCodeFlareStatus(t *testing.T) {
+	test := support.NewTest(t)
+	ctx := context.Background()
+
+	scheme := runtime.NewScheme()
+	test.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+	test.Expect(appsv1.AddToScheme(scheme)).To(Succeed())
+	test.Expect(rayv1.AddToScheme(scheme)).To(Succeed())
+	test.Expect(awv1beta2.AddToScheme(scheme)).To(Succeed())
+	test.Expect(v1alpha1.AddToScheme(scheme)).To(Succeed())
+
+	rayCluster := func(name string, state rayv1.ClusterState) *rayv1.RayCluster {
+		return &rayv1.RayCluster{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns"}, Status: rayv1.RayClusterStatus{State: state}}
+	}
+	failing := healthz.Checker(func(_ *http.Request) error { return errors.New("certificates are not ready") })
+	appWrapper := &awv1beta2.AppWrapper{
+		ObjectMeta: metav1.ObjectMeta{Name: "aw", Namespace: "ns"},
+		Status:     awv1beta2.AppWrapperStatus{Phase: awv1beta2.AppWrapperRunning},
+	}
+
+	test.T().Run("Expected CodeFlareStatus to summarize the operator status, workloads and errors", func(t *testing.T) {
+		c := fake.NewClientBuilder().WithScheme(scheme).
+			WithObjects(rayCluster("ready-1", rayv1.Ready), rayCluster("ready-2", rayv1.Ready), rayCluster("new", ""), appWrapper).
+			WithStatusSubresource(&v1alpha1.CodeFlareStatus{}).
+			Build()
+		r := &OperatorStatusReporter{
+			Client:            c,
+			Reader:            c,
+			OperatorVersion:   "v1.9.0",
+			AppWrapperVersion: "v0.27.0",
+			WebhooksChecker:   failing,
+		}
+		test.Expect(r.reportStatus(ctx)).To(Succeed())
+
+		status := &v1alpha1.CodeFlareStatus{}
+		test.Expect(c.Get(ctx, client.ObjectKey{Name: v1alpha1.CodeFlareStatusName}, status)).To(Succeed())
+		test.Expect(status.Status.Versions).To(Equal(v1alpha1.ComponentVersions{Operator: "v1.9.0", AppWrapper: "v0.27.0"}))
+		test.Expect(status.Status.Conditions).To(ContainElement(And(
+			HaveField("Type", WebhooksReadyCondition),
+			HaveField("Status", metav1.ConditionFalse),
+		)))
+		test.Expect(status.Status.Workloads).To(Equal([]v1alpha1.WorkloadCount{
+			{Kind: appWrapperKind, Phase: string(awv1beta2.AppWrapperRunning), Count: 1},
+			{Kind: rayClusterKind, Phase: unknownPhase, Count: 1},
+			{Kind: rayClusterKind, Phase: string(rayv1.Ready), Count: 2},
+		}))
+		test.Expect(status.Status.LastErrors).To(ContainElement(And(
+			HaveField("Kind", operatorKind),
+			HaveField("Object", client.ObjectKey{Name: v1alpha1.CodeFlareStatusName}.String()),
+			HaveField("Reason", string(WebhookCertInvalidReason)),
+		)))
+
+		// The existing CodeFlareStatus is updated
+		test.Expect(c.Delete(ctx, rayCluster("new", ""))).To(Succeed())
+		test.Expect(r.reportStatus(ctx)).To(Succeed())
+		test.Expect(c.Get(ctx, client.ObjectKey{Name: v1alpha1.CodeFlareStatusName}, status)).To(Succeed())
+		test.Expect(status.Status.Workloads).To(HaveLen(2))
+	})
+
+	test.T().Run("Negative: Expected CodeFlareStatus not to be reported when its API isn't registered", func(t *testing.T) {
+		scheme := runtime.NewScheme()
+		test.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		c := fake.NewClientBuilder().WithScheme(scheme).Build()
+		r := &OperatorStatusReporter{Client: c, Reader: c}
+
+		test.Expect(r.reportStatus(ctx)).To(Succeed())
+	})
+}
+
+func TestErrorLog(t *testing.T) {
+	test := support.NewTest(t)
+
+	test.T().Run("Expected last errors to be listed, most recent first", func(t *testing.T) {
+		log := &errorLog{}
+		for i := range maxReportedErrors + 5 {
+			log.add(rayClusterKind, "ns/raycluster", "", fmt.Sprintf("error %d", i))
+		}
+
+		reported := log.list()
+		test.Expect(reported).To(HaveLen(maxReportedErrors))
+		test.Expect(reported[0].Message).To(Equal(fmt.Sprintf("error %d", maxReportedErrors+4)))
+		test.Expect(reported[maxReportedErrors-1].Message).To(Equal("error 5"))
+	})
+
+	test.T().Run("Expected reconcile errors, but conflicts, to be recorded", func(t *testing.T) {
+		previous := reportedErrors
+		reportedErrors = &errorLog{}
+		t.Cleanup(func() { reportedErrors = previous })
+
+		var reconcileErr error
+		r := instrumentedReconciler{kind: rayClusterKind, Reconciler: reconcile.Func(func(context.Context, ctrl.Request) (ctrl.Result, error) {
+			return ctrl.Result{}, reconcileErr
+		})}
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "ns", Name: "raycluster"}}
+
+		reconcileErr = errors.New("cannot create Route")
+		_, err := r.Reconcile(context.Background(), req)
+		test.Expect(err).To(MatchError(reconcileErr))
+
+		reconcileErr = apierrors.NewConflict(rayv1.Resource("rayclusters"), "raycluster", errors.New("the object has been modified"))
+		_, err = r.Reconcile(context.Background(), req)
+		test.Expect(err).To(MatchError(reconcileErr))
+
+		test.Expect(reportedErrors.list()).To(ConsistOf(And(
+			HaveField("Kind", rayClusterKind),
+			HaveField("Object", "ns/raycluster"),
+			HaveField("Message", "cannot create Route"),
+		)))
+	})
+}
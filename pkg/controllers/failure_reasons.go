@@ -0,0 +1,64 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// FailureReason is the reason of the Warning Events the operator emits on the failures it detects, and the label
+// of the codeflare_failures_total metric, so that the failure causes can be aggregated across RayClusters.
+type FailureReason string
+
+const (
+	// QuotaExceededReason is reported when a RayCluster requests more resources than the capacity of its ClusterQueue.
+	QuotaExceededReason FailureReason = "QuotaExceeded"
+	// ImagePullBackOffReason is reported when the image of a container of the Ray pods can't be pulled.
+	ImagePullBackOffReason FailureReason = "ImagePullBackOff"
+	// WebhookCertInvalidReason is reported when the webhooks of the operator aren't served with a valid certificate.
+	WebhookCertInvalidReason FailureReason = "WebhookCertInvalid"
+	// DependencyMissingReason is reported when a resource, or an API, a RayCluster, or the operator, depends on is missing.
+	DependencyMissingReason FailureReason = "DependencyMissing"
+)
+
+var failures = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "codeflare",
+	Name:      "failures_total",
+	Help:      "Number of failures reported in Warning Events, partitioned by kind of the failed object, i.e., RayCluster or Operator, and reason.",
+}, []string{"kind", "reason"})
+
+func init() {
+	metrics.Registry.MustRegister(failures)
+}
+
+// recordFailure emits a Warning Event, with the failure reason, for the object, counts the failure for the kind,
+// and records it in the last errors of the CodeFlareStatus. It must be called when the failure is detected,
+// rather than on each reconciliation, so that failures are counted once.
+func recordFailure(recorder record.EventRecorder, object client.Object, kind string, reason FailureReason, messageFmt string, args ...any) {
+	failures.WithLabelValues(kind, string(reason)).Inc()
+	reportedErrors.add(kind, client.ObjectKeyFromObject(object).String(), reason, fmt.Sprintf(messageFmt, args...))
+	if recorder != nil {
+		recorder.Eventf(object, corev1.EventTypeWarning, string(reason), messageFmt, args...)
+	}
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	jobsetv1alpha2 "sigs.k8s.io/jobset/api/jobset/v1alpha2"
+	kueueconstants "sigs.k8s.io/kueue/pkg/controller/constants"
+
+	"github.com/project-codeflare/codeflare-operator/pkg/cache"
+)
+
+// log is for logging in this package.
+var jobsetlog = logf.Log.WithName("jobset-resource")
+
+// maxPodSets is the maximum number of PodSets of a Kueue Workload, that has a PodSet per replicated job of a JobSet.
+const maxPodSets = 8
+
+// SetupJobSetWebhookWithManager sets up the webhook defaulting the Kueue queue of the JobSets, and validating
+// the JobSets submitted to Kueue can be admitted.
+func SetupJobSetWebhookWithManager(mgr ctrl.Manager, defaultQueueName string) error {
+	jobSetWebhookInstance := &jobSetWebhook{
+		DefaultQueueName: defaultQueueName,
+		QueueCapacities:  newQueueCapacityCache(mgr.GetAPIReader()),
+	}
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&jobsetv1alpha2.JobSet{}).
+		WithDefaulter(jobSetWebhookInstance).
+		WithValidator(jobSetWebhookInstance).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-jobset-x-k8s-io-v1alpha2-jobset,mutating=true,failurePolicy=fail,sideEffects=None,groups=jobset.x-k8s.io,resources=jobsets,verbs=create,versions=v1alpha2,name=mjobset.codeflare.dev,admissionReviewVersions=v1
+// +kubebuilder:webhook:path=/validate-jobset-x-k8s-io-v1alpha2-jobset,mutating=false,failurePolicy=fail,sideEffects=None,groups=jobset.x-k8s.io,resources=jobsets,verbs=create;update,versions=v1alpha2,name=vjobset.codeflare.dev,admissionReviewVersions=v1
+
+type jobSetWebhook struct {
+	// DefaultQueueName is the Kueue queue the JobSets are submitted to when they don't specify one
+	DefaultQueueName string
+	// QueueCapacities caches the capacity of the ClusterQueues of the LocalQueues the JobSets are submitted to
+	QueueCapacities *cache.Cache[types.NamespacedName, *queueCapacity]
+}
+
+var _ webhook.CustomDefaulter = &jobSetWebhook{}
+var _ webhook.CustomValidator = &jobSetWebhook{}
+
+// Default implements webhook.Defaulter so a webhook will be registered for the type
+func (w *jobSetWebhook) Default(_ context.Context, obj runtime.Object) error {
+	jobSet := obj.(*jobsetv1alpha2.JobSet)
+
+	if applyDefaultQueueName(jobSet, w.DefaultQueueName) {
+		jobsetlog.V(2).Info("Applied default queue", "namespace", jobSet.Namespace, "name", jobSet.Name, "queue", w.DefaultQueueName)
+	}
+
+	return nil
+}
+
+func (w *jobSetWebhook) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	jobSet := obj.(*jobsetv1alpha2.JobSet)
+
+	allErrors := validateJobSetPodSets(jobSet)
+	warnings := workloadQuotaWarnings(ctx, w.QueueCapacities, "JobSet", jobSet, func() corev1.ResourceList {
+		return jobSetRequests(jobSet)
+	})
+
+	return warnings, allErrors.ToAggregate()
+}
+
+func (w *jobSetWebhook) ValidateUpdate(_ context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+	jobSet := newObj.(*jobsetv1alpha2.JobSet)
+
+	return nil, validateJobSetPodSets(jobSet).ToAggregate()
+}
+
+func (w *jobSetWebhook) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validateJobSetPodSets rejects the JobSets submitted to Kueue with more replicated jobs than the PodSets
+// a Kueue Workload can have, as Kueue can't create their Workloads, and they'd stay suspended forever.
+func validateJobSetPodSets(jobSet *jobsetv1alpha2.JobSet) field.ErrorList {
+	var allErrors field.ErrorList
+
+	if _, queued := jobSet.Labels[kueueconstants.QueueLabel]; queued && len(jobSet.Spec.ReplicatedJobs) > maxPodSets {
+		allErrors = append(allErrors, field.TooMany(
+			field.NewPath("spec", "replicatedJobs"),
+			len(jobSet.Spec.ReplicatedJobs),
+			maxPodSets))
+	}
+
+	return allErrors
+}
+
+// jobSetRequests returns the aggregate resources requested by the pods of the JobSet, i.e., the pods of the
+// replicas of each of its replicated jobs, as many as their parallelism.
+func jobSetRequests(jobSet *jobsetv1alpha2.JobSet) corev1.ResourceList {
+	requests := corev1.ResourceList{}
+	for i := range jobSet.Spec.ReplicatedJobs {
+		replicatedJob := &jobSet.Spec.ReplicatedJobs[i]
+		count := int64(replicatedJob.Replicas) * int64(ptr.Deref(replicatedJob.Template.Spec.Parallelism, 1))
+		addResources(requests, podRequests(&replicatedJob.Template.Spec.Template.Spec), count)
+	}
+	return requests
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/project-codeflare/codeflare-common/support"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	jobsetv1alpha2 "sigs.k8s.io/jobset/api/jobset/v1alpha2"
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	kueueconstants "sigs.k8s.io/kueue/pkg/controller/constants"
+)
+
+func TestJobSetWebhook(t *testing.T) {
+	test := support.NewTest(t)
+
+	replicatedJob := func(name string, replicas, parallelism int32) jobsetv1alpha2.ReplicatedJob {
+		return jobsetv1alpha2.ReplicatedJob{
+			Name:     name,
+			Replicas: replicas,
+			Template: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{
+				Parallelism: ptr.To(parallelism),
+				Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "trainer", Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+					}}},
+				}},
+			}},
+		}
+	}
+	jobSet := func(labels map[string]string, replicatedJobs ...jobsetv1alpha2.ReplicatedJob) *jobsetv1alpha2.JobSet {
+		return &jobsetv1alpha2.JobSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "jobset", Namespace: namespace, Labels: labels},
+			Spec:       jobsetv1alpha2.JobSetSpec{ReplicatedJobs: replicatedJobs},
+		}
+	}
+
+	test.T().Run("Expected default queue to be set on JobSets without a queue", func(t *testing.T) {
+		w := &jobSetWebhook{DefaultQueueName: "default-queue"}
+
+		unqueued := jobSet(nil, replicatedJob("workers", 1, 1))
+		test.Expect(w.Default(test.Ctx(), unqueued)).To(Succeed())
+		test.Expect(unqueued.Labels).To(HaveKeyWithValue(kueueconstants.QueueLabel, "default-queue"))
+
+		wrapped := jobSet(map[string]string{AppWrapperLabel: "appwrapper"}, replicatedJob("workers", 1, 1))
+		test.Expect(w.Default(test.Ctx(), wrapped)).To(Succeed())
+		test.Expect(wrapped.Labels).NotTo(HaveKey(kueueconstants.QueueLabel))
+	})
+
+	test.T().Run("Expected aggregate requests to include the replicas and parallelism of the replicated jobs", func(t *testing.T) {
+		requests := jobSetRequests(jobSet(nil, replicatedJob("driver", 1, 1), replicatedJob("workers", 2, 4)))
+		test.Expect(requests.Cpu().String()).To(Equal("9"))
+	})
+
+	test.T().Run("Expected error on call to ValidateCreate function due to too many replicated jobs", func(t *testing.T) {
+		w := &jobSetWebhook{}
+
+		var replicatedJobs []jobsetv1alpha2.ReplicatedJob
+		for i := 0; i <= maxPodSets; i++ {
+			replicatedJobs = append(replicatedJobs, replicatedJob(fmt.Sprintf("job-%d", i), 1, 1))
+		}
+		_, err := w.ValidateCreate(test.Ctx(), runtime.Object(jobSet(map[string]string{kueueconstants.QueueLabel: "team-a"}, replicatedJobs...)))
+		test.Expect(err).To(HaveOccurred())
+
+		// JobSets that aren't submitted to Kueue are not limited
+		_, err = w.ValidateCreate(test.Ctx(), runtime.Object(jobSet(nil, replicatedJobs...)))
+		test.Expect(err).NotTo(HaveOccurred())
+	})
+
+	test.T().Run("Expected warning on call to ValidateCreate function when the requests exceed the quota of the ClusterQueue", func(t *testing.T) {
+		scheme := runtime.NewScheme()
+		test.Expect(kueue.AddToScheme(scheme)).To(Succeed())
+		reader := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&kueue.LocalQueue{
+				ObjectMeta: metav1.ObjectMeta{Name: "team-a", Namespace: namespace},
+				Spec:       kueue.LocalQueueSpec{ClusterQueue: "team-a-cq"},
+			},
+			&kueue.ClusterQueue{
+				ObjectMeta: metav1.ObjectMeta{Name: "team-a-cq"},
+				Spec: kueue.ClusterQueueSpec{ResourceGroups: []kueue.ResourceGroup{{
+					CoveredResources: []corev1.ResourceName{corev1.ResourceCPU},
+					Flavors: []kueue.FlavorQuotas{{Name: "default-flavor", Resources: []kueue.ResourceQuota{
+						{Name: corev1.ResourceCPU, NominalQuota: resource.MustParse("8")},
+					}}},
+				}}},
+			},
+		).Build()
+		w := &jobSetWebhook{QueueCapacities: newQueueCapacityCache(reader)}
+
+		warnings, err := w.ValidateCreate(test.Ctx(), runtime.Object(jobSet(map[string]string{kueueconstants.QueueLabel: "team-a"}, replicatedJob("workers", 2, 4), replicatedJob("driver", 1, 1))))
+		test.Expect(err).NotTo(HaveOccurred())
+		test.Expect(warnings).To(ConsistOf("JobSet requests 9 cpu in total, more than the 8 quota of ClusterQueue team-a-cq, and will never be admitted"))
+	})
+}
@@ -0,0 +1,192 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+
+	"github.com/project-codeflare/codeflare-operator/api/v1alpha1"
+)
+
+const (
+	// AvailableCondition reports whether the operator is functional, i.e., its webhooks are ready and KubeRay is installed.
+	AvailableCondition = "Available"
+	// WebhooksReadyCondition reports whether the webhooks of the operator are served with a valid certificate.
+	WebhooksReadyCondition = "WebhooksReady"
+	// KubeRayAvailableCondition and KueueAvailableCondition report whether the APIs of the operators
+	// the operator depends on are served.
+	KubeRayAvailableCondition = "KubeRayAvailable"
+	KueueAvailableCondition   = "KueueAvailable"
+
+	// kueueNameLabel selects the Kueue controller manager Deployment, in the upstream and ODH manifests
+	kueueNameLabel = "app.kubernetes.io/name"
+	kueueName      = "kueue"
+
+	operatorKind = "Operator"
+
+	// operatorStatusInterval is the period at which the status of the operator is reported
+	operatorStatusInterval = time.Minute
+)
+
+// APICheckers are the checkers of the APIs of the operators the operator depends on.
+type APICheckers struct {
+	KubeRay healthz.Checker
+	Kueue   healthz.Checker
+}
+
+// OperatorStatusReporter periodically reports the status of the operator, i.e., the health of its webhooks, the presence
+// of the operators it depends on, and the versions of all of them, with the counts of the workloads and the last errors,
+// in the CodeFlareStatus, so that it can be read by the ODH operator, or any other consumer, e.g., to report the status
+// of the component of the DataScienceCluster. It runs in the leader replica, whose webhooks are reported.
+type OperatorStatusReporter struct {
+	client.Client
+	// Reader reads the CodeFlareStatus and the Deployments of the operators, without informing on them
+	Reader            client.Reader
+	OperatorVersion   string
+	AppWrapperVersion string
+	WebhooksChecker   healthz.Checker
+	APICheckers       APICheckers
+	// Recorder emits the Warning Events of the failures of the operator, on the CodeFlareStatus
+	Recorder record.EventRecorder
+}
+
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=list
+
+// Start implements manager.Runnable, and reports the status of the operator until the context is done.
+func (r *OperatorStatusReporter) Start(ctx context.Context) error {
+	logger := ctrl.Log.WithName("operator-status")
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if err := r.reportStatus(ctx); err != nil {
+			logger.Error(err, "Failed to report the operator status", "codeFlareStatus", v1alpha1.CodeFlareStatusName)
+			reportedErrors.add(operatorKind, client.ObjectKey{Name: v1alpha1.CodeFlareStatusName}.String(), "", err.Error())
+		}
+	}, operatorStatusInterval)
+	return nil
+}
+
+// reportStatus reports the status of the operator, the counts of the workloads it manages, and the last errors of its
+// controllers, in the CodeFlareStatus singleton, which is created if it doesn't exist. It's skipped when the
+// CodeFlareStatus CRD isn't installed.
+func (r *OperatorStatusReporter) reportStatus(ctx context.Context) error {
+	status := &v1alpha1.CodeFlareStatus{}
+	err := r.Reader.Get(ctx, client.ObjectKey{Name: v1alpha1.CodeFlareStatusName}, status)
+	if isAPIMissing(err) {
+		return nil
+	}
+	if apierrors.IsNotFound(err) {
+		status.Name = v1alpha1.CodeFlareStatusName
+		err = r.Create(ctx, status)
+	}
+	if err != nil {
+		return err
+	}
+
+	conditions, failed := r.conditions(status.Status.Conditions)
+	versions := v1alpha1.ComponentVersions{
+		Operator:   r.OperatorVersion,
+		AppWrapper: r.AppWrapperVersion,
+	}
+	if versions.KubeRay, err = deploymentVersion(ctx, r.Reader, client.MatchingLabels{kubeRayOperatorComponentLabel: kubeRayOperatorComponent}); err != nil {
+		return err
+	}
+	if versions.Kueue, err = deploymentVersion(ctx, r.Reader, client.MatchingLabels{kueueNameLabel: kueueName}); err != nil {
+		return err
+	}
+	workloads, err := r.workloadCounts(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, condition := range failed {
+		reason := DependencyMissingReason
+		if condition.Type == WebhooksReadyCondition {
+			reason = WebhookCertInvalidReason
+		}
+		recordFailure(r.Recorder, status, operatorKind, reason, "%s: %s", condition.Type, condition.Message)
+	}
+	status.Status = v1alpha1.CodeFlareStatusStatus{
+		Versions:       versions,
+		Conditions:     conditions,
+		Workloads:      workloads,
+		LastErrors:     reportedErrors.list(),
+		LastUpdateTime: metav1.Now(),
+	}
+	return r.Status().Update(ctx, status)
+}
+
+// conditions returns the conditions of the operator, updated from the previous ones, and the conditions, among the
+// webhooks and KubeRay ones, that have just failed.
+func (r *OperatorStatusReporter) conditions(previous []metav1.Condition) ([]metav1.Condition, []metav1.Condition) {
+	webhooks := checkerCondition(WebhooksReadyCondition, r.WebhooksChecker, "The webhooks are served")
+	kubeRay := checkerCondition(KubeRayAvailableCondition, r.APICheckers.KubeRay,
+		fmt.Sprintf("The %s API is served", rayv1.GroupVersion.WithResource("rayclusters").GroupResource()))
+	kueueAvailable := checkerCondition(KueueAvailableCondition, r.APICheckers.Kueue,
+		fmt.Sprintf("The %s API is served", kueue.GroupVersion.WithResource("workloads").GroupResource()))
+	available := metav1.Condition{
+		Type:    AvailableCondition,
+		Status:  metav1.ConditionTrue,
+		Reason:  "Available",
+		Message: "The webhooks are ready and KubeRay is installed",
+	}
+	for _, condition := range []metav1.Condition{webhooks, kubeRay} {
+		if condition.Status != metav1.ConditionTrue {
+			available.Status = metav1.ConditionFalse
+			available.Reason = condition.Type + "Failed"
+			available.Message = condition.Message
+			break
+		}
+	}
+	var failed []metav1.Condition
+	for _, condition := range []metav1.Condition{webhooks, kubeRay} {
+		if current := meta.FindStatusCondition(previous, condition.Type); condition.Status == metav1.ConditionFalse &&
+			(current == nil || current.Status != metav1.ConditionFalse) {
+			failed = append(failed, condition)
+		}
+	}
+	conditions := append([]metav1.Condition(nil), previous...)
+	for _, condition := range []metav1.Condition{available, webhooks, kubeRay, kueueAvailable} {
+		meta.SetStatusCondition(&conditions, condition)
+	}
+	return conditions, failed
+}
+
+// checkerCondition returns the condition of the given type, that's true, with the message, when the checker succeeds,
+// and reports its error otherwise.
+func checkerCondition(conditionType string, checker healthz.Checker, message string) metav1.Condition {
+	if checker == nil {
+		return metav1.Condition{Type: conditionType, Status: metav1.ConditionUnknown, Reason: "NotChecked", Message: "Not checked"}
+	}
+	if err := checker(nil); err != nil {
+		return metav1.Condition{Type: conditionType, Status: metav1.ConditionFalse, Reason: "CheckFailed", Message: err.Error()}
+	}
+	return metav1.Condition{Type: conditionType, Status: metav1.ConditionTrue, Reason: "CheckSucceeded", Message: message}
+}
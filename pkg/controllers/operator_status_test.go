@@ -0,0 +1,113 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/project-codeflare/codeflare-common/support"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+
+	"github.com/project-codeflare/codeflare-operator/api/v1alpha1"
+)
+
+func TestOperatorStatusReporter(t *testing.T) {
+	test := support.NewTest(t)
+	ctx := context.Background()
+
+	scheme := runtime.NewScheme()
+	test.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+	test.Expect(appsv1.AddToScheme(scheme)).To(Succeed())
+	test.Expect(v1alpha1.AddToScheme(scheme)).To(Succeed())
+
+	deployment := func(name string, labels map[string]string, image string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "opendatahub", Labels: labels},
+			Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "manager", Image: image}},
+			}}},
+		}
+	}
+	succeeding := healthz.Checker(func(_ *http.Request) error { return nil })
+	failing := healthz.Checker(func(_ *http.Request) error { return errors.New("certificates are not ready") })
+
+	newReporter := func(webhooksChecker healthz.Checker, objects ...client.Object) *OperatorStatusReporter {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).WithStatusSubresource(&v1alpha1.CodeFlareStatus{}).Build()
+		return &OperatorStatusReporter{
+			Client:            c,
+			Reader:            c,
+			OperatorVersion:   "v1.9.0",
+			AppWrapperVersion: "v0.27.0",
+			WebhooksChecker:   webhooksChecker,
+			APICheckers:       APICheckers{KubeRay: succeeding, Kueue: succeeding},
+		}
+	}
+	status := func(r *OperatorStatusReporter) v1alpha1.CodeFlareStatusStatus {
+		status := &v1alpha1.CodeFlareStatus{}
+		test.Expect(r.Get(ctx, client.ObjectKey{Name: v1alpha1.CodeFlareStatusName}, status)).To(Succeed())
+		return status.Status
+	}
+
+	test.T().Run("Expected operator status to report the webhooks health and the dependent operators", func(t *testing.T) {
+		r := newReporter(succeeding,
+			deployment("kuberay-operator", map[string]string{kubeRayOperatorComponentLabel: kubeRayOperatorComponent}, "quay.io/kuberay/operator:v1.1.0"),
+			deployment("kueue-controller-manager", map[string]string{kueueNameLabel: kueueName}, "registry.k8s.io/kueue/kueue:v0.7.0"),
+		)
+
+		test.Expect(r.reportStatus(ctx)).To(Succeed())
+
+		status := status(r)
+		test.Expect(status.Versions).To(Equal(v1alpha1.ComponentVersions{
+			Operator:   "v1.9.0",
+			AppWrapper: "v0.27.0",
+			KubeRay:    "v1.1.0",
+			Kueue:      "v0.7.0",
+		}))
+		for _, conditionType := range []string{AvailableCondition, WebhooksReadyCondition, KubeRayAvailableCondition, KueueAvailableCondition} {
+			test.Expect(meta.IsStatusConditionTrue(status.Conditions, conditionType)).To(BeTrue(), conditionType)
+		}
+	})
+
+	test.T().Run("Negative: Expected operator to be reported unavailable when its webhooks are not ready", func(t *testing.T) {
+		r := newReporter(failing)
+		r.APICheckers.Kueue = failing
+
+		test.Expect(r.reportStatus(ctx)).To(Succeed())
+
+		status := status(r)
+		test.Expect(status.Versions.KubeRay).To(BeEmpty())
+		test.Expect(meta.FindStatusCondition(status.Conditions, AvailableCondition)).To(And(
+			HaveField("Status", Equal(metav1.ConditionFalse)),
+			HaveField("Reason", Equal("WebhooksReadyFailed")),
+			HaveField("Message", Equal("certificates are not ready")),
+		))
+		test.Expect(meta.IsStatusConditionFalse(status.Conditions, KueueAvailableCondition)).To(BeTrue())
+		test.Expect(meta.IsStatusConditionTrue(status.Conditions, KubeRayAvailableCondition)).To(BeTrue())
+	})
+}
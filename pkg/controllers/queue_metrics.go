@@ -0,0 +1,180 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+)
+
+// queueMetricsCollectTimeout bounds the reads of the Kueue objects on each scrape.
+const queueMetricsCollectTimeout = 10 * time.Second
+
+var (
+	queueWorkloadsDesc = prometheus.NewDesc(
+		"codeflare_clusterqueue_workloads",
+		"Number of active Workloads, partitioned by ClusterQueue and status (pending or admitted).",
+		[]string{"cluster_queue", "status"}, nil)
+
+	queueRequestedResourcesDesc = prometheus.NewDesc(
+		"codeflare_clusterqueue_requested_resources",
+		"Aggregate resources requested by the active Workloads, partitioned by ClusterQueue, resource and status (pending or admitted). CPU is in cores, and memory in bytes.",
+		[]string{"cluster_queue", "resource", "status"}, nil)
+
+	queueNominalQuotaDesc = prometheus.NewDesc(
+		"codeflare_clusterqueue_nominal_quota",
+		"Nominal quota of the ClusterQueue, summed across its flavors, partitioned by ClusterQueue and resource. CPU is in cores, and memory in bytes.",
+		[]string{"cluster_queue", "resource"}, nil)
+)
+
+const (
+	workloadStatusPending  = "pending"
+	workloadStatusAdmitted = "admitted"
+)
+
+// QueueMetricsCollector exports, on each scrape, the number of pending and admitted Workloads of each ClusterQueue,
+// and the resources they request, alongside the nominal quota of the ClusterQueue, so that the saturation of the
+// queues can be alerted on from the operator metrics alone. It reads the Workloads, LocalQueues and ClusterQueues
+// from the informer caches of the manager.
+type QueueMetricsCollector struct {
+	Reader client.Reader
+}
+
+var _ prometheus.Collector = &QueueMetricsCollector{}
+
+// +kubebuilder:rbac:groups=kueue.x-k8s.io,resources=workloads;localqueues;clusterqueues,verbs=get;list;watch
+
+func (c *QueueMetricsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- queueWorkloadsDesc
+	ch <- queueRequestedResourcesDesc
+	ch <- queueNominalQuotaDesc
+}
+
+func (c *QueueMetricsCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), queueMetricsCollectTimeout)
+	defer cancel()
+	logger := logf.FromContext(ctx).WithName("queue-metrics")
+
+	clusterQueues := &kueue.ClusterQueueList{}
+	if err := c.Reader.List(ctx, clusterQueues); err != nil {
+		logger.Error(err, "Failed to list ClusterQueues")
+		return
+	}
+	localQueues := &kueue.LocalQueueList{}
+	if err := c.Reader.List(ctx, localQueues); err != nil {
+		logger.Error(err, "Failed to list LocalQueues")
+		return
+	}
+	workloads := &kueue.WorkloadList{}
+	if err := c.Reader.List(ctx, workloads); err != nil {
+		logger.Error(err, "Failed to list Workloads")
+		return
+	}
+
+	for _, clusterQueue := range clusterQueues.Items {
+		for name, quota := range clusterQueueNominalQuota(&clusterQueue) {
+			ch <- prometheus.MustNewConstMetric(queueNominalQuotaDesc, prometheus.GaugeValue, quota.AsApproximateFloat64(), clusterQueue.Name, string(name))
+		}
+	}
+
+	for key, usage := range queueUsages(clusterQueues.Items, localQueues.Items, workloads.Items) {
+		ch <- prometheus.MustNewConstMetric(queueWorkloadsDesc, prometheus.GaugeValue, float64(usage.workloads), key.clusterQueue, key.status)
+		for name, quantity := range usage.requests {
+			ch <- prometheus.MustNewConstMetric(queueRequestedResourcesDesc, prometheus.GaugeValue, quantity.AsApproximateFloat64(), key.clusterQueue, string(name), key.status)
+		}
+	}
+}
+
+type queueUsageKey struct {
+	clusterQueue string
+	status       string
+}
+
+type queueUsage struct {
+	workloads int
+	requests  corev1.ResourceList
+}
+
+// queueUsages aggregates the active Workloads, i.e., neither finished nor deactivated, and their requests, by
+// ClusterQueue and status. The pending Workloads are accounted to the ClusterQueue of their LocalQueue, and the
+// admitted ones to the ClusterQueue that admitted them. The pending and admitted counts of each ClusterQueue are
+// reported, even when they're zero, so that the series don't disappear when the queue drains.
+func queueUsages(clusterQueues []kueue.ClusterQueue, localQueues []kueue.LocalQueue, workloads []kueue.Workload) map[queueUsageKey]*queueUsage {
+	usages := map[queueUsageKey]*queueUsage{}
+	usage := func(clusterQueue, status string) *queueUsage {
+		key := queueUsageKey{clusterQueue: clusterQueue, status: status}
+		if usages[key] == nil {
+			usages[key] = &queueUsage{requests: corev1.ResourceList{}}
+		}
+		return usages[key]
+	}
+	for _, clusterQueue := range clusterQueues {
+		usage(clusterQueue.Name, workloadStatusPending)
+		usage(clusterQueue.Name, workloadStatusAdmitted)
+	}
+
+	clusterQueueOf := map[types.NamespacedName]string{}
+	for _, localQueue := range localQueues {
+		clusterQueueOf[types.NamespacedName{Namespace: localQueue.Namespace, Name: localQueue.Name}] = string(localQueue.Spec.ClusterQueue)
+	}
+
+	for i := range workloads {
+		workload := &workloads[i]
+		if !ptr.Deref(workload.Spec.Active, true) || meta.IsStatusConditionTrue(workload.Status.Conditions, kueue.WorkloadFinished) {
+			continue
+		}
+		clusterQueue, status := clusterQueueOf[types.NamespacedName{Namespace: workload.Namespace, Name: workload.Spec.QueueName}], workloadStatusPending
+		if workload.Status.Admission != nil && meta.IsStatusConditionTrue(workload.Status.Conditions, kueue.WorkloadAdmitted) {
+			clusterQueue, status = string(workload.Status.Admission.ClusterQueue), workloadStatusAdmitted
+		}
+		if clusterQueue == "" {
+			continue
+		}
+		u := usage(clusterQueue, status)
+		u.workloads++
+		for j := range workload.Spec.PodSets {
+			podSet := &workload.Spec.PodSets[j]
+			addResources(u.requests, podRequests(&podSet.Template.Spec), int64(podSet.Count))
+		}
+	}
+	return usages
+}
+
+// clusterQueueNominalQuota returns the nominal quota of the ClusterQueue for each resource, summed across its flavors.
+func clusterQueueNominalQuota(clusterQueue *kueue.ClusterQueue) corev1.ResourceList {
+	quotas := corev1.ResourceList{}
+	for _, group := range clusterQueue.Spec.ResourceGroups {
+		for _, flavor := range group.Flavors {
+			for _, quota := range flavor.Resources {
+				total := quotas[quota.Name]
+				total.Add(quota.NominalQuota)
+				quotas[quota.Name] = total
+			}
+		}
+	}
+	return quotas
+}
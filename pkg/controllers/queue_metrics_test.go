@@ -0,0 +1,116 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/project-codeflare/codeflare-common/support"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+)
+
+func TestQueueMetricsCollector(t *testing.T) {
+	test := support.NewTest(t)
+
+	scheme := runtime.NewScheme()
+	test.Expect(kueue.AddToScheme(scheme)).To(Succeed())
+
+	clusterQueue := &kueue.ClusterQueue{
+		ObjectMeta: metav1.ObjectMeta{Name: "team-a"},
+		Spec: kueue.ClusterQueueSpec{ResourceGroups: []kueue.ResourceGroup{{
+			CoveredResources: []corev1.ResourceName{corev1.ResourceCPU},
+			Flavors: []kueue.FlavorQuotas{
+				{Name: "on-demand", Resources: []kueue.ResourceQuota{{Name: corev1.ResourceCPU, NominalQuota: resource.MustParse("8")}}},
+				{Name: "spot", Resources: []kueue.ResourceQuota{{Name: corev1.ResourceCPU, NominalQuota: resource.MustParse("4")}}},
+			},
+		}}},
+	}
+	idleQueue := &kueue.ClusterQueue{ObjectMeta: metav1.ObjectMeta{Name: "team-b"}}
+	localQueue := &kueue.LocalQueue{
+		ObjectMeta: metav1.ObjectMeta{Name: "queue", Namespace: "ns"},
+		Spec:       kueue.LocalQueueSpec{ClusterQueue: "team-a"},
+	}
+	workload := func(name, cpu string, count int32) *kueue.Workload {
+		return &kueue.Workload{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns"},
+			Spec: kueue.WorkloadSpec{
+				QueueName: "queue",
+				PodSets: []kueue.PodSet{{
+					Name:  "workers",
+					Count: count,
+					Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{
+						Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu)}},
+					}}}},
+				}},
+			},
+		}
+	}
+	pending := workload("pending", "2", 3)
+	admitted := workload("admitted", "500m", 2)
+	admitted.Status.Admission = &kueue.Admission{ClusterQueue: "team-a"}
+	admitted.Status.Conditions = []metav1.Condition{{Type: kueue.WorkloadAdmitted, Status: metav1.ConditionTrue}}
+	finished := workload("finished", "1", 1)
+	finished.Status.Conditions = []metav1.Condition{{Type: kueue.WorkloadFinished, Status: metav1.ConditionTrue}}
+	deactivated := workload("deactivated", "1", 1)
+	deactivated.Spec.Active = ptr.To(false)
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects([]client.Object{
+		clusterQueue, idleQueue, localQueue, pending, admitted, finished, deactivated,
+	}...).Build()
+	collector := &QueueMetricsCollector{Reader: c}
+
+	test.T().Run("Expected Workloads, requested resources and nominal quota to be exported per ClusterQueue", func(t *testing.T) {
+		expected := `
+# HELP codeflare_clusterqueue_nominal_quota Nominal quota of the ClusterQueue, summed across its flavors, partitioned by ClusterQueue and resource. CPU is in cores, and memory in bytes.
+# TYPE codeflare_clusterqueue_nominal_quota gauge
+codeflare_clusterqueue_nominal_quota{cluster_queue="team-a",resource="cpu"} 12
+# HELP codeflare_clusterqueue_requested_resources Aggregate resources requested by the active Workloads, partitioned by ClusterQueue, resource and status (pending or admitted). CPU is in cores, and memory in bytes.
+# TYPE codeflare_clusterqueue_requested_resources gauge
+codeflare_clusterqueue_requested_resources{cluster_queue="team-a",resource="cpu",status="admitted"} 1
+codeflare_clusterqueue_requested_resources{cluster_queue="team-a",resource="cpu",status="pending"} 6
+# HELP codeflare_clusterqueue_workloads Number of active Workloads, partitioned by ClusterQueue and status (pending or admitted).
+# TYPE codeflare_clusterqueue_workloads gauge
+codeflare_clusterqueue_workloads{cluster_queue="team-a",status="admitted"} 1
+codeflare_clusterqueue_workloads{cluster_queue="team-a",status="pending"} 1
+codeflare_clusterqueue_workloads{cluster_queue="team-b",status="admitted"} 0
+codeflare_clusterqueue_workloads{cluster_queue="team-b",status="pending"} 0
+`
+		test.Expect(testutil.CollectAndCompare(collector, strings.NewReader(expected))).To(Succeed())
+	})
+
+	test.T().Run("Negative: Expected pending Workloads of unknown LocalQueues not to be exported", func(t *testing.T) {
+		orphan := workload("orphan", "1", 1)
+		orphan.Spec.QueueName = "unknown"
+		usages := queueUsages([]kueue.ClusterQueue{*clusterQueue}, []kueue.LocalQueue{*localQueue}, []kueue.Workload{*orphan})
+
+		test.Expect(usages).To(HaveLen(2))
+		for _, usage := range usages {
+			test.Expect(usage.workloads).To(BeZero())
+		}
+	})
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	kueueconstants "sigs.k8s.io/kueue/pkg/controller/constants"
+)
+
+const (
+	// AdmissionChecksReadyCondition reports whether the AdmissionChecks of the Kueue Workload of the RayCluster,
+	// e.g., the provisioning of its nodes by the cluster autoscaler, are ready, or what the RayCluster waits for.
+	AdmissionChecksReadyCondition = "AdmissionChecksReady"
+
+	// admissionChecksRecheckInterval is the period at which the AdmissionChecks of suspended RayClusters are checked
+	// again, as the reconciler doesn't watch Workloads
+	admissionChecksRecheckInterval = 15 * time.Second
+)
+
+// updateAdmissionChecksCondition records the state of the AdmissionChecks of the Kueue Workload of the RayCluster with
+// the AdmissionChecksReady condition, so that the users know their RayCluster waits for nodes to be provisioned, rather
+// than for quota. The RayClusters that aren't submitted to a LocalQueue, or whose Workloads have no AdmissionChecks,
+// aren't reported. It returns the delay after which the AdmissionChecks must be checked again, if any.
+func (r *RayClusterReconciler) updateAdmissionChecksCondition(ctx context.Context, cluster *rayv1.RayCluster, suspended bool) (time.Duration, error) {
+	if _, queued := cluster.Labels[kueueconstants.QueueLabel]; !queued || r.Reader == nil {
+		return 0, nil
+	}
+
+	conditions, err := rayClusterConditions(cluster)
+	if err != nil {
+		return 0, err
+	}
+	// The AdmissionChecks are only checked again once the RayCluster is suspended, e.g., when it's preempted
+	current := meta.FindStatusCondition(conditions, AdmissionChecksReadyCondition)
+	if !suspended && (current == nil || current.Status == metav1.ConditionTrue) {
+		return 0, nil
+	}
+
+	workload, err := r.workloadForRayCluster(ctx, cluster)
+	if err != nil {
+		return 0, err
+	}
+	if workload == nil || len(workload.Status.AdmissionChecks) == 0 {
+		// The Workload may not have been created, or its ClusterQueue not have been evaluated, yet
+		if suspended {
+			return admissionChecksRecheckInterval, nil
+		}
+		return 0, nil
+	}
+
+	condition := admissionChecksCondition(workload.Status.AdmissionChecks)
+	condition.ObservedGeneration = cluster.Generation
+	if meta.SetStatusCondition(&conditions, condition) {
+		if err := r.patchRayClusterConditions(ctx, cluster, conditions); err != nil {
+			return 0, err
+		}
+	}
+	if condition.Status == metav1.ConditionTrue {
+		return 0, nil
+	}
+	return admissionChecksRecheckInterval, nil
+}
+
+// workloadForRayCluster returns the Kueue Workload owned by the RayCluster, if any.
+func (r *RayClusterReconciler) workloadForRayCluster(ctx context.Context, cluster *rayv1.RayCluster) (*kueue.Workload, error) {
+	workloads := &kueue.WorkloadList{}
+	if err := r.Reader.List(ctx, workloads, client.InNamespace(cluster.Namespace)); err != nil {
+		if meta.IsNoMatchError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	for i := range workloads.Items {
+		for _, owner := range workloads.Items[i].OwnerReferences {
+			if owner.UID == cluster.UID {
+				return &workloads.Items[i], nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// admissionChecksCondition returns the AdmissionChecksReady condition, that's true once all the AdmissionChecks
+// are ready, or reports the state and message of those that aren't.
+func admissionChecksCondition(checks []kueue.AdmissionCheckState) metav1.Condition {
+	var pending []string
+	reason := ""
+	for _, check := range checks {
+		if check.State == kueue.CheckStateReady {
+			continue
+		}
+		if reason == "" {
+			reason = string(check.State)
+		}
+		message := fmt.Sprintf("AdmissionCheck %s is %s", check.Name, check.State)
+		if check.Message != "" {
+			message += ": " + check.Message
+		}
+		pending = append(pending, message)
+	}
+
+	if len(pending) == 0 {
+		return metav1.Condition{
+			Type:    AdmissionChecksReadyCondition,
+			Status:  metav1.ConditionTrue,
+			Reason:  "Ready",
+			Message: fmt.Sprintf("All the %d AdmissionChecks of the RayCluster are ready", len(checks)),
+		}
+	}
+	return metav1.Condition{
+		Type:    AdmissionChecksReadyCondition,
+		Status:  metav1.ConditionFalse,
+		Reason:  reason,
+		Message: strings.Join(pending, "; "),
+	}
+}
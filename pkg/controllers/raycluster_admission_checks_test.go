@@ -0,0 +1,122 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/project-codeflare/codeflare-common/support"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	kueueconstants "sigs.k8s.io/kueue/pkg/controller/constants"
+)
+
+func TestUpdateAdmissionChecksCondition(t *testing.T) {
+	test := support.NewTest(t)
+	ctx := context.Background()
+
+	scheme := runtime.NewScheme()
+	test.Expect(rayv1.AddToScheme(scheme)).To(Succeed())
+	test.Expect(kueue.AddToScheme(scheme)).To(Succeed())
+
+	queuedCluster := func() *rayv1.RayCluster {
+		return &rayv1.RayCluster{ObjectMeta: metav1.ObjectMeta{
+			Name:      "raycluster",
+			Namespace: "ns",
+			UID:       "raycluster-uid",
+			Labels:    map[string]string{kueueconstants.QueueLabel: "queue"},
+		}}
+	}
+	workload := func(checks ...kueue.AdmissionCheckState) *kueue.Workload {
+		return &kueue.Workload{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "raycluster-raycluster-abcde",
+				Namespace:       "ns",
+				OwnerReferences: []metav1.OwnerReference{{Kind: "RayCluster", Name: "raycluster", UID: "raycluster-uid"}},
+			},
+			Status: kueue.WorkloadStatus{AdmissionChecks: checks},
+		}
+	}
+	newReconciler := func(objects ...client.Object) *RayClusterReconciler {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+		return &RayClusterReconciler{Client: c, Reader: c}
+	}
+	condition := func(r *RayClusterReconciler, cluster *rayv1.RayCluster) *metav1.Condition {
+		test.Expect(r.Get(ctx, client.ObjectKeyFromObject(cluster), cluster)).To(Succeed())
+		conditions, err := rayClusterConditions(cluster)
+		test.Expect(err).NotTo(HaveOccurred())
+		return meta.FindStatusCondition(conditions, AdmissionChecksReadyCondition)
+	}
+
+	test.T().Run("Expected AdmissionChecksReady condition to report the pending provisioning of the nodes", func(t *testing.T) {
+		cluster := queuedCluster()
+		r := newReconciler(cluster, workload(
+			kueue.AdmissionCheckState{Name: "dws-prov", State: kueue.CheckStatePending, Message: "Waiting for resources. Currently there are not enough resources available to fulfill the request."},
+		))
+
+		checkAfter, err := r.updateAdmissionChecksCondition(ctx, cluster, true)
+		test.Expect(err).NotTo(HaveOccurred())
+		test.Expect(checkAfter).To(Equal(admissionChecksRecheckInterval))
+		test.Expect(condition(r, cluster)).To(And(
+			HaveField("Status", Equal(metav1.ConditionFalse)),
+			HaveField("Reason", Equal("Pending")),
+			HaveField("Message", Equal("AdmissionCheck dws-prov is Pending: Waiting for resources. Currently there are not enough resources available to fulfill the request.")),
+		))
+	})
+
+	test.T().Run("Expected AdmissionChecksReady condition once the nodes are provisioned", func(t *testing.T) {
+		cluster := queuedCluster()
+		r := newReconciler(cluster, workload(
+			kueue.AdmissionCheckState{Name: "dws-prov", State: kueue.CheckStateReady},
+		))
+
+		checkAfter, err := r.updateAdmissionChecksCondition(ctx, cluster, true)
+		test.Expect(err).NotTo(HaveOccurred())
+		test.Expect(checkAfter).To(BeZero())
+		test.Expect(condition(r, cluster)).To(HaveField("Status", Equal(metav1.ConditionTrue)))
+
+		// The AdmissionChecks aren't checked again while the RayCluster is running
+		r.Reader = nil
+		checkAfter, err = r.updateAdmissionChecksCondition(ctx, cluster, false)
+		test.Expect(err).NotTo(HaveOccurred())
+		test.Expect(checkAfter).To(BeZero())
+	})
+
+	test.T().Run("Negative: Expected no AdmissionChecksReady condition without AdmissionChecks", func(t *testing.T) {
+		cluster := queuedCluster()
+		r := newReconciler(cluster, workload())
+
+		checkAfter, err := r.updateAdmissionChecksCondition(ctx, cluster, true)
+		test.Expect(err).NotTo(HaveOccurred())
+		test.Expect(checkAfter).To(Equal(admissionChecksRecheckInterval))
+		test.Expect(condition(r, cluster)).To(BeNil())
+
+		unqueued := queuedCluster()
+		unqueued.Labels = nil
+		r = newReconciler(unqueued, workload(kueue.AdmissionCheckState{Name: "dws-prov", State: kueue.CheckStatePending}))
+		test.Expect(r.updateAdmissionChecksCondition(ctx, unqueued, true)).To(BeZero())
+		test.Expect(condition(r, unqueued)).To(BeNil())
+	})
+}
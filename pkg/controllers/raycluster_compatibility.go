@@ -0,0 +1,166 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/blang/semver/v4"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/project-codeflare/codeflare-operator/pkg/cache"
+	"github.com/project-codeflare/codeflare-operator/pkg/config"
+)
+
+const (
+	// rayCompatibilityPolicy rejects the RayClusters with incompatible Ray and KubeRay versions
+	rayCompatibilityPolicy = "RayCompatibility"
+
+	// kubeRayOperatorComponentLabel selects the KubeRay operator Deployment, in the upstream and ODH manifests
+	kubeRayOperatorComponentLabel = "app.kubernetes.io/component"
+	kubeRayOperatorComponent      = "kuberay-operator"
+
+	// kubeRayVersionTTL is how long the version of the KubeRay operator is cached for by the webhook,
+	// so that upgrades of the KubeRay operator are eventually detected.
+	kubeRayVersionTTL = 5 * time.Minute
+)
+
+// newKubeRayVersionCache returns a cache of the version of the KubeRay operator, detected from the image tag of its
+// Deployment. The version is empty when the Deployment isn't found, or its image isn't tagged with a version.
+func newKubeRayVersionCache(reader client.Reader) *cache.Cache[string, string] {
+	return cache.New("kuberay-version", kubeRayVersionTTL,
+		func(ctx context.Context, _ string) (string, error) {
+			return deploymentVersion(ctx, reader, client.MatchingLabels{kubeRayOperatorComponentLabel: kubeRayOperatorComponent})
+		})
+}
+
+// deploymentVersion returns the version of the operator deployed by the Deployments with the labels, detected from
+// the image tag of their containers, or an empty string when none is found.
+func deploymentVersion(ctx context.Context, reader client.Reader, labels client.MatchingLabels) (string, error) {
+	deployments := &appsv1.DeploymentList{}
+	if err := reader.List(ctx, deployments, labels); err != nil {
+		return "", err
+	}
+	for _, deployment := range deployments.Items {
+		for _, container := range deployment.Spec.Template.Spec.Containers {
+			if version := versionFromImageTag(container.Image); version != "" {
+				return version, nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// versionFromImageTag returns the semantic version the image is tagged with, e.g., v1.1.0, or an empty string.
+func versionFromImageTag(image string) string {
+	if i := strings.Index(image, "@"); i >= 0 {
+		image = image[:i]
+	}
+	colon := strings.LastIndex(image, ":")
+	if colon <= strings.LastIndex(image, "/") {
+		return ""
+	}
+	if _, err := semver.ParseTolerant(image[colon+1:]); err != nil {
+		return ""
+	}
+	return image[colon+1:]
+}
+
+// validateRayCompatibility returns the errors for the RayCluster whose Ray version, in its spec, doesn't match the Ray
+// version of its head image, or whose Ray version is known to be broken with the version of the KubeRay operator.
+func validateRayCompatibility(rayCluster *rayv1.RayCluster, cfg *config.RayCompatibilityConfiguration, kubeRayVersion string) field.ErrorList {
+	var allErrors field.ErrorList
+
+	imageRayVersion := ""
+	if len(rayCluster.Spec.HeadGroupSpec.Template.Spec.Containers) > 0 {
+		imageRayVersion = rayVersionFromImage(rayCluster.Spec.HeadGroupSpec.Template.Spec.Containers[0].Image)
+	}
+	if rayCluster.Spec.RayVersion != "" && imageRayVersion != "" && rayCluster.Spec.RayVersion != imageRayVersion {
+		allErrors = append(allErrors, field.Invalid(
+			field.NewPath("spec", "rayVersion"),
+			rayCluster.Spec.RayVersion,
+			fmt.Sprintf("Ray version does not match Ray version %s of the head group image", imageRayVersion)))
+	}
+
+	rayVersion := imageRayVersion
+	if rayVersion == "" {
+		rayVersion = rayCluster.Spec.RayVersion
+	}
+	parsedRayVersion, err := semver.ParseTolerant(rayVersion)
+	if err != nil {
+		return allErrors
+	}
+	parsedKubeRayVersion, kubeRayVersionErr := semver.ParseTolerant(kubeRayVersion)
+
+	for _, incompatibility := range cfg.Incompatibilities {
+		rayVersions, err := semver.ParseRange(incompatibility.RayVersions)
+		if err != nil || !rayVersions(parsedRayVersion) {
+			continue
+		}
+		if incompatibility.KubeRayVersions != "" {
+			kubeRayVersions, err := semver.ParseRange(incompatibility.KubeRayVersions)
+			if err != nil || kubeRayVersionErr != nil || !kubeRayVersions(parsedKubeRayVersion) {
+				continue
+			}
+		}
+		message := fmt.Sprintf("Ray version %s is not compatible with KubeRay version %s", rayVersion, kubeRayVersion)
+		if incompatibility.KubeRayVersions == "" {
+			message = fmt.Sprintf("Ray version %s is not supported", rayVersion)
+		}
+		if incompatibility.Reason != "" {
+			message += ": " + incompatibility.Reason
+		}
+		allErrors = append(allErrors, field.Invalid(field.NewPath("spec", "rayVersion"), rayVersion, message))
+	}
+
+	return allErrors
+}
+
+// appendRayCompatibility reports incompatible Ray and KubeRay versions either as errors or warnings, depending on the configuration
+func (w *rayClusterWebhook) appendRayCompatibility(ctx context.Context, warnings admission.Warnings, allErrors field.ErrorList, rayCluster *rayv1.RayCluster) (admission.Warnings, field.ErrorList) {
+	cfg := w.Config.RayCompatibility
+	if cfg == nil {
+		return warnings, allErrors
+	}
+
+	kubeRayVersion := cfg.KubeRayVersion
+	if kubeRayVersion == "" && w.KubeRayVersion != nil {
+		version, err := w.KubeRayVersion.Get(ctx, "")
+		if err != nil {
+			rayclusterlog.V(2).Info("Cannot detect KubeRay version", "error", err.Error())
+		}
+		kubeRayVersion = version
+	}
+
+	incompatibilities := validateRayCompatibility(rayCluster, cfg, kubeRayVersion)
+	if ptr.Deref(cfg.Reject, false) {
+		return appendPolicyViolations(warnings, allErrors, rayCluster, w.Config, rayCompatibilityPolicy, incompatibilities)
+	}
+	for _, err := range incompatibilities {
+		warnings = append(warnings, err.Error())
+	}
+	return warnings, allErrors
+}
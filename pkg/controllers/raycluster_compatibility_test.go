@@ -0,0 +1,153 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/project-codeflare/codeflare-common/support"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/project-codeflare/codeflare-operator/pkg/config"
+)
+
+func TestValidateRayCompatibility(t *testing.T) {
+	test := support.NewTest(t)
+
+	rayCluster := func(rayVersion, image string) *rayv1.RayCluster {
+		return &rayv1.RayCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: rayClusterName, Namespace: namespace},
+			Spec: rayv1.RayClusterSpec{
+				RayVersion: rayVersion,
+				HeadGroupSpec: rayv1.HeadGroupSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "ray-head", Image: image}},
+				}}},
+			},
+		}
+	}
+	cfg := &config.RayCompatibilityConfiguration{
+		Incompatibilities: []config.RayIncompatibility{
+			{RayVersions: ">=2.10.0", KubeRayVersions: "<1.1.0", Reason: "the Ray dashboard agent port is not exposed"},
+			{RayVersions: "<2.5.0", Reason: "the Ray version is end of life"},
+		},
+	}
+
+	t.Run("Expected Ray version and image tag to be consistent", func(t *testing.T) {
+		test.Expect(validateRayCompatibility(rayCluster("2.20.0", "quay.io/project-codeflare/ray:2.20.0-py39-cu118"), cfg, "v1.1.0")).To(BeEmpty())
+		test.Expect(validateRayCompatibility(rayCluster("2.9.0", "quay.io/project-codeflare/ray:2.20.0-py39-cu118"), cfg, "v1.1.0")).To(ConsistOf(
+			HaveField("Detail", "Ray version does not match Ray version 2.20.0 of the head group image"),
+		))
+	})
+
+	t.Run("Expected known-broken Ray and KubeRay versions to be reported", func(t *testing.T) {
+		test.Expect(validateRayCompatibility(rayCluster("", "quay.io/project-codeflare/ray:2.20.0-py39-cu118"), cfg, "v1.0.0")).To(ConsistOf(
+			HaveField("Detail", "Ray version 2.20.0 is not compatible with KubeRay version v1.0.0: the Ray dashboard agent port is not exposed"),
+		))
+		test.Expect(validateRayCompatibility(rayCluster("2.4.0", ""), cfg, "v1.1.0")).To(ConsistOf(
+			HaveField("Detail", "Ray version 2.4.0 is not supported: the Ray version is end of life"),
+		))
+	})
+
+	t.Run("Expected versions to be compatible when the KubeRay version is unknown", func(t *testing.T) {
+		test.Expect(validateRayCompatibility(rayCluster("", "quay.io/project-codeflare/ray:2.20.0-py39-cu118"), cfg, "")).To(BeEmpty())
+	})
+
+	t.Run("Expected warnings, or errors, on call to ValidateCreate function due to incompatible versions", func(t *testing.T) {
+		skewed := rayCluster("2.9.0", "quay.io/project-codeflare/ray:2.20.0-py39-cu118")
+
+		warningWebhook := &rayClusterWebhook{Config: &config.KubeRayConfiguration{
+			RayDashboardOAuthEnabled: support.Ptr(false),
+			RayCompatibility:         &config.RayCompatibilityConfiguration{KubeRayVersion: "v1.1.0"},
+		}}
+		warnings, err := warningWebhook.ValidateCreate(test.Ctx(), runtime.Object(skewed))
+		test.Expect(err).ShouldNot(HaveOccurred())
+		test.Expect(warnings).To(HaveLen(1))
+
+		rejectingWebhook := &rayClusterWebhook{Config: &config.KubeRayConfiguration{
+			RayDashboardOAuthEnabled: support.Ptr(false),
+			RayCompatibility:         &config.RayCompatibilityConfiguration{KubeRayVersion: "v1.1.0", Reject: support.Ptr(true)},
+		}}
+		warnings, err = rejectingWebhook.ValidateCreate(test.Ctx(), runtime.Object(skewed))
+		test.Expect(err).Should(HaveOccurred())
+		test.Expect(warnings).To(BeEmpty())
+	})
+
+	t.Run("Expected RayClusters to remain updatable, after a KubeRay upgrade, until their Ray version changes", func(t *testing.T) {
+		running := rayCluster("", "quay.io/project-codeflare/ray:2.20.0-py39-cu118")
+		upgradedWebhook := &rayClusterWebhook{Config: &config.KubeRayConfiguration{
+			RayDashboardOAuthEnabled: support.Ptr(false),
+			MTLSEnabled:              support.Ptr(false),
+			RayCompatibility: &config.RayCompatibilityConfiguration{
+				KubeRayVersion:    "v1.0.0",
+				Reject:            support.Ptr(true),
+				Incompatibilities: cfg.Incompatibilities,
+			},
+		}}
+
+		suspended := running.DeepCopy()
+		suspended.Spec.Suspend = support.Ptr(true)
+		_, err := upgradedWebhook.ValidateUpdate(test.Ctx(), runtime.Object(running), runtime.Object(suspended))
+		test.Expect(err).ShouldNot(HaveOccurred())
+
+		upgraded := running.DeepCopy()
+		upgraded.Spec.HeadGroupSpec.Template.Spec.Containers[0].Image = "quay.io/project-codeflare/ray:2.21.0-py39-cu118"
+		_, err = upgradedWebhook.ValidateUpdate(test.Ctx(), runtime.Object(running), runtime.Object(upgraded))
+		test.Expect(err).Should(HaveOccurred())
+	})
+}
+
+func TestKubeRayVersionCache(t *testing.T) {
+	test := support.NewTest(t)
+	ctx := context.Background()
+
+	scheme := runtime.NewScheme()
+	test.Expect(appsv1.AddToScheme(scheme)).To(Succeed())
+
+	deployment := func(image string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "kuberay-operator",
+				Namespace: "opendatahub",
+				Labels:    map[string]string{kubeRayOperatorComponentLabel: kubeRayOperatorComponent},
+			},
+			Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "kuberay-operator", Image: image}},
+			}}},
+		}
+	}
+
+	t.Run("Expected KubeRay version to be detected from the image tag of its Deployment", func(t *testing.T) {
+		reader := fake.NewClientBuilder().WithScheme(scheme).WithObjects(deployment("quay.io/opendatahub/kuberay-operator:v1.1.0")).Build()
+		test.Expect(newKubeRayVersionCache(reader).Get(ctx, "")).To(Equal("v1.1.0"))
+	})
+
+	t.Run("Expected KubeRay version to be unknown when its image isn't tagged with a version", func(t *testing.T) {
+		reader := fake.NewClientBuilder().WithScheme(scheme).WithObjects(deployment("quay.io/opendatahub/kuberay-operator@sha256:abc")).Build()
+		test.Expect(newKubeRayVersionCache(reader).Get(ctx, "")).To(BeEmpty())
+
+		reader = fake.NewClientBuilder().WithScheme(scheme).Build()
+		test.Expect(newKubeRayVersionCache(reader).Get(ctx, "")).To(BeEmpty())
+	})
+}
@@ -24,26 +24,32 @@ import (
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/base64"
+	"encoding/json"
 	"encoding/pem"
 	"fmt"
 	"math/big"
 	rand2 "math/rand"
+	"net/http"
 	"time"
 
 	dsciv1 "github.com/opendatahub-io/opendatahub-operator/v2/apis/dscinitialization/v1"
 	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
 
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	corev1ac "k8s.io/client-go/applyconfigurations/core/v1"
 	metav1ac "k8s.io/client-go/applyconfigurations/meta/v1"
 	networkingv1ac "k8s.io/client-go/applyconfigurations/networking/v1"
 	rbacv1ac "k8s.io/client-go/applyconfigurations/rbac/v1"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -53,7 +59,10 @@ import (
 	routev1ac "github.com/openshift/client-go/route/applyconfigurations/route/v1"
 	routev1client "github.com/openshift/client-go/route/clientset/versioned/typed/route/v1"
 
+	"github.com/project-codeflare/codeflare-operator/pkg/audit"
+	"github.com/project-codeflare/codeflare-operator/pkg/cache"
 	"github.com/project-codeflare/codeflare-operator/pkg/config"
+	"github.com/project-codeflare/codeflare-operator/pkg/defaults"
 )
 
 // RayClusterReconciler reconciles a RayCluster object
@@ -65,6 +74,15 @@ type RayClusterReconciler struct {
 	CookieSalt  string
 	Config      *config.KubeRayConfiguration
 	IsOpenShift bool
+	Auditor     *audit.Collector
+	// Reader reads the Kueue Workloads, Secrets and pods of the RayClusters, without informing on all of them
+	Reader client.Reader
+	// Recorder emits the Warning Events of the failures of the RayClusters
+	Recorder record.EventRecorder
+
+	dashboardProbeClient *http.Client
+	queueCapacities      *cache.Cache[types.NamespacedName, *queueCapacity]
+	imagePullFailures    imagePullFailureTracker
 }
 
 const (
@@ -78,6 +96,16 @@ const (
 
 	CAPrivateKeyKey = "ca.key"
 	CACertKey       = "ca.crt"
+
+	// ConditionsAnnotation holds the conditions the operator reports for a RayCluster,
+	// as the RayCluster status is owned by KubeRay and has no conditions.
+	ConditionsAnnotation = "codeflare.dev/conditions"
+	// SuspendedCondition reports whether the RayCluster is suspended, either by Kueue or by the user,
+	// and its dashboard and client endpoints have been torn down.
+	SuspendedCondition = "Suspended"
+	// PartiallyScheduledCondition reports whether some pods of the admitted RayCluster could not be scheduled
+	// within the gang scheduling verification timeout.
+	PartiallyScheduledCondition = "PartiallyScheduled"
 )
 
 var (
@@ -91,6 +119,8 @@ var (
 // +kubebuilder:rbac:groups=route.openshift.io,resources=routes;routes/custom-host,verbs=get;create;update;patch;delete
 // +kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;create;patch;delete;get
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=pods/log,verbs=get
 // +kubebuilder:rbac:groups=core,resources=services,verbs=get;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=serviceaccounts,verbs=get;create;update;patch;delete
 // +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterrolebindings,verbs=get;create;update;patch;delete
@@ -98,6 +128,8 @@ var (
 // +kubebuilder:rbac:groups=authorization.k8s.io,resources=subjectaccessreviews,verbs=create;
 // +kubebuilder:rbac:groups=dscinitialization.opendatahub.io,resources=dscinitializations,verbs=get;list;watch
 // +kubebuilder:rbac:groups=networking.k8s.io,resources=networkpolicies,verbs=get;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;create;patch
+// +kubebuilder:rbac:groups=external-secrets.io,resources=externalsecrets,verbs=get
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -117,6 +149,11 @@ func (r *RayClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	if err := r.Get(ctx, req.NamespacedName, cluster); err != nil {
 		if !errors.IsNotFound(err) {
 			logger.Error(err, "Error getting RayCluster resource")
+		} else {
+			if r.Auditor != nil {
+				r.Auditor.Forget(req.NamespacedName)
+			}
+			r.imagePullFailures.forget(req.NamespacedName)
 		}
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
@@ -132,6 +169,9 @@ func (r *RayClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 			}
 		}
 	} else if controllerutil.ContainsFinalizer(cluster, oAuthFinalizer) {
+		if r.Auditor != nil {
+			r.Auditor.Forget(req.NamespacedName)
+		}
 		err := client.IgnoreNotFound(r.Client.Delete(
 			ctx,
 			&rbacv1.ClusterRoleBinding{
@@ -182,21 +222,63 @@ func (r *RayClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		}
 	}
 
-	if cluster.Status.State != "suspended" && isRayDashboardOAuthEnabled(r.Config) && r.IsOpenShift {
-		logger.Info("Creating OAuth Objects")
-		_, err := r.routeClient.Routes(cluster.Namespace).Apply(ctx, desiredClusterRoute(cluster), metav1.ApplyOptions{FieldManager: controllerName, Force: true})
-		if err != nil {
-			logger.Error(err, "Failed to update OAuth Route")
+	suspended := isRayClusterSuspended(cluster)
+	if suspended {
+		if r.Auditor != nil {
+			r.Auditor.Forget(req.NamespacedName)
+		}
+		if err := r.deleteSuspendedObjects(ctx, cluster); err != nil {
+			logger.Error(err, "Failed to remove the endpoints of the suspended RayCluster", logRequeueing, true)
 			return ctrl.Result{RequeueAfter: requeueTime}, err
 		}
+	}
+	if err := r.updateSuspendedCondition(ctx, cluster, suspended); err != nil {
+		// This log is info level since conflicts are not fatal and are expected
+		logger.Info("WARN: Failed to update RayCluster Suspended condition", "error", err.Error(), logRequeueing, true)
+		return ctrl.Result{RequeueAfter: requeueTime}, err
+	}
 
-		_, err = r.kubeClient.CoreV1().Secrets(cluster.Namespace).Apply(ctx, desiredOAuthSecret(cluster, r.CookieSalt), metav1.ApplyOptions{FieldManager: controllerName, Force: true})
-		if err != nil {
-			logger.Error(err, "Failed to create OAuth Secret")
-			return ctrl.Result{RequeueAfter: requeueTime}, err
+	verifyAfter, err := r.verifyGangScheduling(ctx, cluster, suspended)
+	if err != nil {
+		logger.Error(err, "Failed to verify the scheduling of the RayCluster pods", logRequeueing, true)
+		return ctrl.Result{RequeueAfter: requeueTime}, err
+	}
+
+	checkAfter, err := r.updateAdmissionChecksCondition(ctx, cluster, suspended)
+	if err != nil {
+		// This log is info level since conflicts are not fatal and are expected
+		logger.Info("WARN: Failed to update RayCluster AdmissionChecksReady condition", "error", err.Error(), logRequeueing, true)
+		return ctrl.Result{RequeueAfter: requeueTime}, err
+	}
+
+	secretsAfter, err := r.releaseExternalSecretsGate(ctx, cluster, suspended)
+	if err != nil {
+		logger.Error(err, "Failed to release the Ray pods waiting for external Secrets", logRequeueing, true)
+		return ctrl.Result{RequeueAfter: requeueTime}, err
+	}
+
+	pullAfter, err := r.reportImagePullFailures(ctx, cluster, suspended)
+	if err != nil {
+		logger.Error(err, "Failed to check the Ray pods for image pull failures", logRequeueing, true)
+		return ctrl.Result{RequeueAfter: requeueTime}, err
+	}
+
+	if !suspended && r.Auditor != nil && isRayDashboardOAuthEnabled(r.Config) && r.IsOpenShift {
+		r.Auditor.Watch(req.NamespacedName)
+	}
+
+	if !suspended && isRayDashboardOAuthEnabled(r.Config) && r.IsOpenShift {
+		logger.Info("Creating OAuth Objects")
+		// The rotated cookie secrets are managed by the cookie secret rotation controller
+		if !defaults.IsCookieSecretRotationEnabled(r.Config) {
+			_, err := r.kubeClient.CoreV1().Secrets(cluster.Namespace).Apply(ctx, desiredOAuthSecret(cluster, r.CookieSalt), metav1.ApplyOptions{FieldManager: controllerName, Force: true})
+			if err != nil {
+				logger.Error(err, "Failed to create OAuth Secret")
+				return ctrl.Result{RequeueAfter: requeueTime}, err
+			}
 		}
 
-		_, err = r.kubeClient.CoreV1().Services(cluster.Namespace).Apply(ctx, desiredOAuthService(cluster), metav1.ApplyOptions{FieldManager: controllerName, Force: true})
+		_, err := r.kubeClient.CoreV1().Services(cluster.Namespace).Apply(ctx, desiredOAuthService(cluster), metav1.ApplyOptions{FieldManager: controllerName, Force: true})
 		if err != nil {
 			logger.Error(err, "Failed to update OAuth Service")
 			return ctrl.Result{RequeueAfter: requeueTime}, err
@@ -213,23 +295,76 @@ func (r *RayClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 			logger.Error(err, "Failed to update OAuth ClusterRoleBinding")
 			return ctrl.Result{RequeueAfter: requeueTime}, err
 		}
+	}
+
+	if r.IsOpenShift && isTrustedCABundleInjected(r.Config) {
+		_, err := r.kubeClient.CoreV1().ConfigMaps(cluster.Namespace).Apply(ctx, desiredTrustedCABundleConfigMap(cluster, r.Config), metav1.ApplyOptions{FieldManager: controllerName, Force: true})
+		if err != nil {
+			logger.Error(err, "Failed to update trusted CA bundle ConfigMap")
+			return ctrl.Result{RequeueAfter: requeueTime}, err
+		}
+	}
+
+	if defaults.IsScratchVolumeRequested(r.Config, cluster) {
+		// The claim is only created, as most of its spec is immutable, and it's deleted with the RayCluster
+		_, err := r.kubeClient.CoreV1().PersistentVolumeClaims(cluster.Namespace).Get(ctx, defaults.ScratchVolumeClaimName(cluster), metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			var claim *corev1ac.PersistentVolumeClaimApplyConfiguration
+			if claim, err = desiredScratchVolumeClaim(cluster, r.Config.ScratchVolume); err == nil {
+				_, err = r.kubeClient.CoreV1().PersistentVolumeClaims(cluster.Namespace).Apply(ctx, claim, metav1.ApplyOptions{FieldManager: controllerName, Force: true})
+			}
+		}
+		if err != nil {
+			logger.Error(err, "Failed to create scratch volume PersistentVolumeClaim")
+			return ctrl.Result{RequeueAfter: requeueTime}, err
+		}
+	}
+
+	// The URL of the dashboard, if it's exposed
+	var exposedDashboardURL string
+	// The delay after which the deletion of the stale exposure objects is retried
+	var exposureAfter time.Duration
+	exposure := dashboardExposure(r.Config, r.IsOpenShift)
+	if !suspended && exposure == config.RouteDashboardExposure {
+		logger.Info("Creating Dashboard Route")
+		dashboardRoute, err := r.routeClient.Routes(cluster.Namespace).Apply(ctx, desiredClusterRoute(cluster, r.Config), metav1.ApplyOptions{FieldManager: controllerName, Force: true})
+		if err != nil {
+			logger.Error(err, "Failed to update OAuth Route")
+			return ctrl.Result{RequeueAfter: requeueTime}, err
+		}
 
 		logger.Info("Creating RayClient Route")
-		_, err = r.routeClient.Routes(cluster.Namespace).Apply(ctx, desiredRayClientRoute(cluster), metav1.ApplyOptions{FieldManager: controllerName, Force: true})
+		rayClientRoute, err := r.routeClient.Routes(cluster.Namespace).Apply(ctx, desiredRayClientRoute(cluster, r.Config), metav1.ApplyOptions{FieldManager: controllerName, Force: true})
 		if err != nil {
 			logger.Error(err, "Failed to update RayClient Route")
 			return ctrl.Result{RequeueAfter: requeueTime}, err
 		}
 
-	} else if cluster.Status.State != "suspended" && !isRayDashboardOAuthEnabled(r.Config) && !r.IsOpenShift {
-		logger.Info("We detected being on Vanilla Kubernetes!")
+		// The host is generated by the router when it's not set
+		if dashboardRoute.Spec.Host != "" {
+			exposedDashboardURL = dashboardURL(dashboardRoute.Spec.Host, true)
+		}
+
+		requeue, err := r.deleteStaleExposure(ctx, cluster, exposure, isRouteAdmitted(dashboardRoute) && isRouteAdmitted(rayClientRoute))
+		if err != nil {
+			logger.Error(err, "Failed to delete stale Ingresses")
+			return ctrl.Result{RequeueAfter: requeueTime}, err
+		} else if requeue {
+			logger.Info("Waiting for Routes to be admitted before deleting stale Ingresses", logRequeueing, true)
+			exposureAfter = requeueTime * time.Second
+		}
+
+	} else if !suspended && exposure == config.IngressDashboardExposure {
+		if !r.IsOpenShift {
+			logger.Info("We detected being on Vanilla Kubernetes!")
+		}
 		logger.Info("Creating Dashboard Ingress")
 		dashboardName := dashboardNameFromCluster(cluster)
 		dashboardIngressHost, err := getIngressHost(r.Config, cluster, dashboardName)
 		if err != nil {
 			return ctrl.Result{RequeueAfter: requeueTime}, err
 		}
-		_, err = r.kubeClient.NetworkingV1().Ingresses(cluster.Namespace).Apply(ctx, desiredClusterIngress(cluster, dashboardIngressHost), metav1.ApplyOptions{FieldManager: controllerName, Force: true})
+		dashboardIngress, err := r.kubeClient.NetworkingV1().Ingresses(cluster.Namespace).Apply(ctx, desiredClusterIngress(cluster, dashboardIngressHost), metav1.ApplyOptions{FieldManager: controllerName, Force: true})
 		if err != nil {
 			// This log is info level since errors are not fatal and are expected
 			logger.Info("WARN: Failed to update Dashboard Ingress", "error", err.Error(), logRequeueing, true)
@@ -241,11 +376,35 @@ func (r *RayClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		if err != nil {
 			return ctrl.Result{RequeueAfter: requeueTime}, err
 		}
-		_, err = r.kubeClient.NetworkingV1().Ingresses(cluster.Namespace).Apply(ctx, desiredRayClientIngress(cluster, rayClientIngressHost), metav1.ApplyOptions{FieldManager: controllerName, Force: true})
+		rayClientIngress, err := r.kubeClient.NetworkingV1().Ingresses(cluster.Namespace).Apply(ctx, desiredRayClientIngress(cluster, rayClientIngressHost), metav1.ApplyOptions{FieldManager: controllerName, Force: true})
 		if err != nil {
 			logger.Error(err, "Failed to update RayClient Ingress")
 			return ctrl.Result{RequeueAfter: requeueTime}, err
 		}
+
+		exposedDashboardURL = dashboardURL(dashboardIngressHost, false)
+
+		requeue, err := r.deleteStaleExposure(ctx, cluster, exposure, isIngressAdmitted(dashboardIngress) && isIngressAdmitted(rayClientIngress))
+		if err != nil {
+			logger.Error(err, "Failed to delete stale Routes")
+			return ctrl.Result{RequeueAfter: requeueTime}, err
+		} else if requeue {
+			logger.Info("Waiting for Ingresses to be admitted before deleting stale Routes", logRequeueing, true)
+			exposureAfter = requeueTime * time.Second
+		}
+	}
+
+	probeAfter, err := r.updateDashboardReadyCondition(ctx, cluster, exposedDashboardURL)
+	if err != nil {
+		// This log is info level since conflicts are not fatal and are expected
+		logger.Info("WARN: Failed to update RayCluster DashboardReady condition", "error", err.Error(), logRequeueing, true)
+		return ctrl.Result{RequeueAfter: requeueTime}, err
+	}
+	requeueAfter := verifyAfter
+	for _, after := range []time.Duration{probeAfter, checkAfter, secretsAfter, pullAfter, exposureAfter} {
+		if after > 0 && (requeueAfter == 0 || after < requeueAfter) {
+			requeueAfter = after
+		}
 	}
 
 	// Locate the KubeRay operator deployment:
@@ -253,7 +412,7 @@ func (r *RayClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	// - Or fallback to the well-known defaults
 	var kubeRayNamespaces []string
 	dsci := &dsciv1.DSCInitialization{}
-	err := r.Client.Get(ctx, client.ObjectKey{Name: "default-dsci"}, dsci)
+	err = r.Client.Get(ctx, client.ObjectKey{Name: "default-dsci"}, dsci)
 	if errors.IsNotFound(err) {
 		kubeRayNamespaces = []string{"opendatahub", "redhat-ods-applications"}
 	} else if err != nil {
@@ -272,7 +431,112 @@ func (r *RayClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		logger.Error(err, "Failed to update NetworkPolicy")
 	}
 
-	return ctrl.Result{}, nil
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// isRayClusterSuspended returns whether the RayCluster is suspended, or is still being suspended by KubeRay.
+// A RayCluster that has been resumed is not considered suspended, even if KubeRay has not updated its state yet,
+// so that its endpoints are recreated as soon as possible.
+func isRayClusterSuspended(cluster *rayv1.RayCluster) bool {
+	if cluster.Spec.Suspend != nil {
+		return *cluster.Spec.Suspend
+	}
+	return cluster.Status.State == rayv1.Suspended
+}
+
+// deleteSuspendedObjects deletes the Routes, Ingresses and OAuth objects of a suspended RayCluster.
+// They are recreated by the reconciler once the RayCluster is resumed.
+func (r *RayClusterReconciler) deleteSuspendedObjects(ctx context.Context, cluster *rayv1.RayCluster) error {
+	names := []string{dashboardNameFromCluster(cluster), rayClientNameFromCluster(cluster)}
+	for _, name := range names {
+		err := r.kubeClient.NetworkingV1().Ingresses(cluster.Namespace).Delete(ctx, name, metav1.DeleteOptions{})
+		if client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+	if !r.IsOpenShift {
+		return nil
+	}
+	for _, name := range names {
+		err := r.routeClient.Routes(cluster.Namespace).Delete(ctx, name, metav1.DeleteOptions{})
+		if client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+	if !isRayDashboardOAuthEnabled(r.Config) {
+		return nil
+	}
+	objects := []client.Object{
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: oauthSecretNameFromCluster(cluster), Namespace: cluster.Namespace}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: oauthServiceNameFromCluster(cluster), Namespace: cluster.Namespace}},
+		&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: oauthServiceAccountNameFromCluster(cluster), Namespace: cluster.Namespace}},
+		&rbacv1.ClusterRoleBinding{ObjectMeta: metav1.ObjectMeta{Name: crbNameFromCluster(cluster)}},
+	}
+	for _, object := range objects {
+		if err := client.IgnoreNotFound(r.Client.Delete(ctx, object, &deleteOptions)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateSuspendedCondition records the Suspended condition in the conditions annotation of the RayCluster.
+// The condition is only added once the RayCluster has been suspended, and updated on subsequent transitions.
+func (r *RayClusterReconciler) updateSuspendedCondition(ctx context.Context, cluster *rayv1.RayCluster, suspended bool) error {
+	conditions, err := rayClusterConditions(cluster)
+	if err != nil {
+		return err
+	}
+	current := meta.FindStatusCondition(conditions, SuspendedCondition)
+	if !suspended && current == nil {
+		return nil
+	}
+
+	condition := metav1.Condition{
+		Type:               SuspendedCondition,
+		Status:             metav1.ConditionFalse,
+		Reason:             "Resumed",
+		Message:            "RayCluster has been resumed",
+		ObservedGeneration: cluster.Generation,
+	}
+	if suspended {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "Suspended"
+		condition.Message = "RayCluster is suspended, its dashboard and client endpoints have been removed"
+	}
+	if !meta.SetStatusCondition(&conditions, condition) {
+		return nil
+	}
+	if err := r.patchRayClusterConditions(ctx, cluster, conditions); err != nil {
+		return err
+	}
+	if suspended && (current == nil || current.Status != metav1.ConditionTrue) {
+		// The RayClusters queued with Kueue are suspended until they're admitted
+		r.reportQuotaExceeded(ctx, cluster)
+	}
+	return nil
+}
+
+// patchRayClusterConditions records the conditions in the conditions annotation of the RayCluster.
+func (r *RayClusterReconciler) patchRayClusterConditions(ctx context.Context, cluster *rayv1.RayCluster, conditions []metav1.Condition) error {
+	value, err := json.Marshal(conditions)
+	if err != nil {
+		return err
+	}
+	patch := client.MergeFrom(cluster.DeepCopy())
+	metav1.SetMetaDataAnnotation(&cluster.ObjectMeta, ConditionsAnnotation, string(value))
+	return r.Patch(ctx, cluster, patch)
+}
+
+// rayClusterConditions returns the conditions recorded in the conditions annotation of the RayCluster.
+func rayClusterConditions(cluster *rayv1.RayCluster) ([]metav1.Condition, error) {
+	var conditions []metav1.Condition
+	if value, ok := cluster.Annotations[ConditionsAnnotation]; ok {
+		if err := json.Unmarshal([]byte(value), &conditions); err != nil {
+			return nil, fmt.Errorf("invalid %s annotation: %w", ConditionsAnnotation, err)
+		}
+	}
+	return conditions, nil
 }
 
 // getIngressHost generates the cluster URL string based on the cluster type, RayCluster, and ingress domain.
@@ -286,6 +550,85 @@ func getIngressHost(cfg *config.KubeRayConfiguration, cluster *rayv1.RayCluster,
 	return fmt.Sprintf("%s-%s.%s", ingressNameFromCluster, cluster.Namespace, ingressDomain), nil
 }
 
+// dashboardExposure returns how the Ray dashboard and client are exposed, defaulting to Routes
+// on OpenShift with the OAuth proxy enabled, and to Ingresses on vanilla Kubernetes without it.
+// An empty value means they are not exposed.
+func dashboardExposure(cfg *config.KubeRayConfiguration, isOpenShift bool) config.DashboardExposureType {
+	if cfg != nil && cfg.DashboardExposure != "" {
+		return cfg.DashboardExposure
+	}
+	if isRayDashboardOAuthEnabled(cfg) && isOpenShift {
+		return config.RouteDashboardExposure
+	} else if !isRayDashboardOAuthEnabled(cfg) && !isOpenShift {
+		return config.IngressDashboardExposure
+	}
+	return ""
+}
+
+// deleteStaleExposure deletes the dashboard and client objects left over from a previous exposure type,
+// i.e. the Ingresses when exposed with Routes and conversely. They are only deleted once the objects
+// of the current exposure type are admitted, so that the dashboard remains reachable during the migration.
+// The admission, i.e. the Admitted condition of the Routes or the load balancer status of the Ingresses,
+// stands for the reachability of the new hosts, which are not probed.
+// It returns whether stale objects remain and their deletion must be retried.
+func (r *RayClusterReconciler) deleteStaleExposure(ctx context.Context, cluster *rayv1.RayCluster, exposure config.DashboardExposureType, admitted bool) (bool, error) {
+	if exposure == config.IngressDashboardExposure && !r.IsOpenShift {
+		// The Route API is not available
+		return false, nil
+	}
+	for _, name := range []string{dashboardNameFromCluster(cluster), rayClientNameFromCluster(cluster)} {
+		var stale *metav1.ObjectMeta
+		var deleteStale func(context.Context, string, metav1.DeleteOptions) error
+		switch exposure {
+		case config.RouteDashboardExposure:
+			ingress, err := r.kubeClient.NetworkingV1().Ingresses(cluster.Namespace).Get(ctx, name, metav1.GetOptions{})
+			if errors.IsNotFound(err) {
+				continue
+			} else if err != nil {
+				return false, err
+			}
+			stale, deleteStale = &ingress.ObjectMeta, r.kubeClient.NetworkingV1().Ingresses(cluster.Namespace).Delete
+		case config.IngressDashboardExposure:
+			route, err := r.routeClient.Routes(cluster.Namespace).Get(ctx, name, metav1.GetOptions{})
+			if errors.IsNotFound(err) {
+				continue
+			} else if err != nil {
+				return false, err
+			}
+			stale, deleteStale = &route.ObjectMeta, r.routeClient.Routes(cluster.Namespace).Delete
+		default:
+			return false, nil
+		}
+		// Leave alone objects that are not managed for this RayCluster
+		if stale.Labels["ray.io/cluster-name"] != cluster.Name {
+			continue
+		}
+		if !admitted {
+			return true, nil
+		}
+		err := deleteStale(ctx, name, metav1.DeleteOptions{Preconditions: metav1.NewUIDPreconditions(string(stale.UID))})
+		if err != nil && !errors.IsNotFound(err) {
+			return false, err
+		}
+	}
+	return false, nil
+}
+
+func isRouteAdmitted(route *routev1.Route) bool {
+	for _, ingress := range route.Status.Ingress {
+		for _, condition := range ingress.Conditions {
+			if condition.Type == routev1.RouteAdmitted && condition.Status == corev1.ConditionTrue {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isIngressAdmitted(ingress *networkingv1.Ingress) bool {
+	return len(ingress.Status.LoadBalancer.Ingress) > 0
+}
+
 func isRayDashboardOAuthEnabled(cfg *config.KubeRayConfiguration) bool {
 	return cfg == nil || ptr.Deref(cfg.RayDashboardOAuthEnabled, true)
 }
@@ -341,9 +684,10 @@ func rayClientNameFromCluster(cluster *rayv1.RayCluster) string {
 	return "rayclient-" + cluster.Name
 }
 
-func desiredClusterRoute(cluster *rayv1.RayCluster) *routev1ac.RouteApplyConfiguration {
+func desiredClusterRoute(cluster *rayv1.RayCluster, cfg *config.KubeRayConfiguration) *routev1ac.RouteApplyConfiguration {
 	return routev1ac.Route(dashboardNameFromCluster(cluster), cluster.Namespace).
 		WithLabels(map[string]string{"ray.io/cluster-name": cluster.Name}).
+		WithAnnotations(dashboardRouteAnnotations(cfg)).
 		WithSpec(routev1ac.RouteSpec().
 			WithTo(routev1ac.RouteTargetReference().WithKind("Service").WithName(oauthServiceNameFromCluster(cluster))).
 			WithPort(routev1ac.RoutePort().WithTargetPort(intstr.FromString((oAuthServicePortName)))).
@@ -371,6 +715,8 @@ func desiredOAuthService(cluster *rayv1.RayCluster) *corev1ac.ServiceApplyConfig
 		WithAnnotations(map[string]string{"service.beta.openshift.io/serving-cert-secret-name": oauthServiceTLSSecretName(cluster)}).
 		WithSpec(
 			corev1ac.ServiceSpec().
+				// Allocate a cluster IP of each family on dual-stack clusters, so the Route is served over both
+				WithIPFamilyPolicy(corev1.IPFamilyPolicyPreferDualStack).
 				WithPorts(
 					corev1ac.ServicePort().
 						WithName(oAuthServicePortName).
@@ -404,6 +750,42 @@ func desiredOAuthSecret(cluster *rayv1.RayCluster, cookieSalt string) *corev1ac.
 		)
 }
 
+// isTrustedCABundleInjected returns whether the trusted CA bundle ConfigMap is managed by the operator,
+// and the cluster trust bundle injected into it by OpenShift.
+func isTrustedCABundleInjected(cfg *config.KubeRayConfiguration) bool {
+	return cfg != nil && cfg.TrustedCABundle != nil && ptr.Deref(cfg.TrustedCABundle.Enabled, false) && cfg.TrustedCABundle.ConfigMapName == ""
+}
+
+func desiredTrustedCABundleConfigMap(cluster *rayv1.RayCluster, cfg *config.KubeRayConfiguration) *corev1ac.ConfigMapApplyConfiguration {
+	return corev1ac.ConfigMap(defaults.TrustedCABundleConfigMapName(cfg.TrustedCABundle, cluster), cluster.Namespace).
+		WithLabels(map[string]string{
+			"ray.io/cluster-name":                         cluster.Name,
+			"config.openshift.io/inject-trusted-cabundle": "true",
+		}).
+		WithOwnerReferences(
+			metav1ac.OwnerReference().WithUID(cluster.UID).WithName(cluster.Name).WithKind(cluster.Kind).WithAPIVersion(cluster.APIVersion),
+		)
+}
+
+func desiredScratchVolumeClaim(cluster *rayv1.RayCluster, cfg *config.ScratchVolumeConfiguration) (*corev1ac.PersistentVolumeClaimApplyConfiguration, error) {
+	size, err := defaults.ScratchVolumeSize(cfg, cluster)
+	if err != nil {
+		return nil, err
+	}
+	spec := corev1ac.PersistentVolumeClaimSpec().
+		WithAccessModes(corev1.ReadWriteMany).
+		WithResources(corev1ac.VolumeResourceRequirements().WithRequests(corev1.ResourceList{corev1.ResourceStorage: size}))
+	if storageClassName := defaults.ScratchVolumeStorageClassName(cfg, cluster); storageClassName != "" {
+		spec = spec.WithStorageClassName(storageClassName)
+	}
+	return corev1ac.PersistentVolumeClaim(defaults.ScratchVolumeClaimName(cluster), cluster.Namespace).
+		WithLabels(map[string]string{"ray.io/cluster-name": cluster.Name}).
+		WithSpec(spec).
+		WithOwnerReferences(
+			metav1ac.OwnerReference().WithUID(cluster.UID).WithName(cluster.Name).WithKind(cluster.Kind).WithAPIVersion(cluster.APIVersion),
+		), nil
+}
+
 func caSecretNameFromCluster(cluster *rayv1.RayCluster) string {
 	return "ca-secret-" + cluster.Name
 }
@@ -487,7 +869,7 @@ func desiredHeadNetworkPolicy(cluster *rayv1.RayCluster, cfg *config.KubeRayConf
 	if ptr.Deref(cfg.MTLSEnabled, true) {
 		allSecuredPorts = append(allSecuredPorts, networkingv1ac.NetworkPolicyPort().WithProtocol(corev1.ProtocolTCP).WithPort(intstr.FromInt(10001)))
 	}
-	return networkingv1ac.NetworkPolicy(cluster.Name+"-head", cluster.Namespace).
+	policy := networkingv1ac.NetworkPolicy(cluster.Name+"-head", cluster.Namespace).
 		WithLabels(map[string]string{"ray.io/cluster-name": cluster.Name}).
 		WithSpec(networkingv1ac.NetworkPolicySpec().
 			WithPodSelector(metav1ac.LabelSelector().WithMatchLabels(map[string]string{"ray.io/cluster": cluster.Name, "ray.io/node-type": "head"})).
@@ -536,6 +918,13 @@ func desiredHeadNetworkPolicy(cluster *rayv1.RayCluster, cfg *config.KubeRayConf
 		WithOwnerReferences(
 			metav1ac.OwnerReference().WithUID(cluster.UID).WithName(cluster.Name).WithKind(cluster.Kind).WithAPIVersion(cluster.APIVersion),
 		)
+	if rule := notebookNamespacesIngressRule(cfg); rule != nil {
+		policy.Spec.WithIngress(rule)
+	}
+	if rule := sharedClusterIngressRule(cluster, cfg); rule != nil {
+		policy.Spec.WithIngress(rule)
+	}
+	return policy
 }
 
 // SetupWithManager sets up the controller with the Manager.
@@ -548,8 +937,12 @@ func (r *RayClusterReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		return err
 	}
 	r.CookieSalt = string(b)
+	r.dashboardProbeClient = newDashboardProbeClient()
+	if r.Reader != nil {
+		r.queueCapacities = newQueueCapacityCache(r.Reader)
+	}
 	return ctrl.NewControllerManagedBy(mgr).
 		Named(controllerName).
 		For(&rayv1.RayCluster{}).
-		Complete(r)
+		Complete(instrument(controllerName, rayClusterKind, r))
 }
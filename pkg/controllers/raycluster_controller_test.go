@@ -24,9 +24,12 @@ import (
 	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
 
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
 
 	routev1 "github.com/openshift/api/route/v1"
 )
@@ -115,6 +118,18 @@ var _ = Describe("RayCluster controller", func() {
 			}).WithTimeout(time.Second * 10).ShouldNot(BeNil())
 		})
 
+		It("should request a dual-stack OAuth Service", func(ctx SpecContext) {
+			foundRayCluster, err := rayClient.RayV1().RayClusters(namespaceName).Get(ctx, rayClusterName, metav1.GetOptions{})
+			Expect(err).To(Not(HaveOccurred()))
+
+			// The Service falls back to a single family on single-stack clusters
+			Eventually(func() (*corev1.Service, error) {
+				return k8sClient.CoreV1().Services(namespaceName).Get(ctx, oauthServiceNameFromCluster(foundRayCluster), metav1.GetOptions{})
+			}).WithTimeout(time.Second * 10).Should(WithTransform(func(service *corev1.Service) *corev1.IPFamilyPolicy {
+				return service.Spec.IPFamilyPolicy
+			}, Equal(ptr.To(corev1.IPFamilyPolicyPreferDualStack))))
+		})
+
 		It("should set owner references for all resources", func(ctx SpecContext) {
 			foundRayCluster, err := rayClient.RayV1().RayClusters(namespaceName).Get(ctx, rayClusterName, metav1.GetOptions{})
 			Expect(err).To(Not(HaveOccurred()))
@@ -145,6 +160,111 @@ var _ = Describe("RayCluster controller", func() {
 			}).WithTimeout(time.Second * 10).Should(WithTransform(OwnerReferenceName, Equal(foundRayCluster.Name)))
 		})
 
+		It("should delete stale Ingresses once the Routes are admitted", func(ctx SpecContext) {
+			foundRayCluster, err := rayClient.RayV1().RayClusters(namespaceName).Get(ctx, rayClusterName, metav1.GetOptions{})
+			Expect(err).To(Not(HaveOccurred()))
+
+			By("creating the dashboard Ingress left over from a previous exposure type")
+			ingress := &networkingv1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      dashboardNameFromCluster(foundRayCluster),
+					Namespace: namespaceName,
+					Labels:    map[string]string{"ray.io/cluster-name": foundRayCluster.Name},
+				},
+				Spec: networkingv1.IngressSpec{
+					DefaultBackend: &networkingv1.IngressBackend{
+						Service: &networkingv1.IngressServiceBackend{
+							Name: serviceNameFromCluster(foundRayCluster),
+							Port: networkingv1.ServiceBackendPort{Name: ingressServicePortName},
+						},
+					},
+				},
+			}
+			_, err = k8sClient.NetworkingV1().Ingresses(namespaceName).Create(ctx, ingress, metav1.CreateOptions{})
+			Expect(err).To(Not(HaveOccurred()))
+
+			Consistently(func() error {
+				_, err := k8sClient.NetworkingV1().Ingresses(namespaceName).Get(ctx, ingress.Name, metav1.GetOptions{})
+				return err
+			}).WithTimeout(time.Second * 2).Should(Succeed())
+
+			By("admitting the Routes")
+			for _, name := range []string{dashboardNameFromCluster(foundRayCluster), rayClientNameFromCluster(foundRayCluster)} {
+				Eventually(func() error {
+					route, err := routeClient.RouteV1().Routes(namespaceName).Get(ctx, name, metav1.GetOptions{})
+					if err != nil {
+						return err
+					}
+					route.Status.Ingress = []routev1.RouteIngress{{
+						Host:       route.Name + ".apps.example.com",
+						RouterName: "default",
+						Conditions: []routev1.RouteIngressCondition{{Type: routev1.RouteAdmitted, Status: corev1.ConditionTrue}},
+					}}
+					_, err = routeClient.RouteV1().Routes(namespaceName).UpdateStatus(ctx, route, metav1.UpdateOptions{})
+					return err
+				}).WithTimeout(time.Second * 10).Should(Succeed())
+			}
+
+			Eventually(func() error {
+				_, err := k8sClient.NetworkingV1().Ingresses(namespaceName).Get(ctx, ingress.Name, metav1.GetOptions{})
+				return err
+			}).WithTimeout(time.Second * 30).Should(Satisfy(errors.IsNotFound))
+		})
+
+		It("should remove the endpoints while the RayCluster is suspended", func(ctx SpecContext) {
+			foundRayCluster, err := rayClient.RayV1().RayClusters(namespaceName).Get(ctx, rayClusterName, metav1.GetOptions{})
+			Expect(err).To(Not(HaveOccurred()))
+
+			Eventually(func() (*routev1.Route, error) {
+				return routeClient.RouteV1().Routes(namespaceName).Get(ctx, dashboardNameFromCluster(foundRayCluster), metav1.GetOptions{})
+			}).WithTimeout(time.Second * 10).ShouldNot(BeNil())
+
+			By("suspending the RayCluster")
+			setSuspend := func(suspend bool) {
+				Eventually(func() error {
+					rayCluster, err := rayClient.RayV1().RayClusters(namespaceName).Get(ctx, rayClusterName, metav1.GetOptions{})
+					if err != nil {
+						return err
+					}
+					rayCluster.Spec.Suspend = ptr.To(suspend)
+					_, err = rayClient.RayV1().RayClusters(namespaceName).Update(ctx, rayCluster, metav1.UpdateOptions{})
+					return err
+				}).WithTimeout(time.Second * 10).Should(Succeed())
+			}
+			suspendedCondition := func() (*metav1.Condition, error) {
+				rayCluster, err := rayClient.RayV1().RayClusters(namespaceName).Get(ctx, rayClusterName, metav1.GetOptions{})
+				if err != nil {
+					return nil, err
+				}
+				conditions, err := rayClusterConditions(rayCluster)
+				return meta.FindStatusCondition(conditions, SuspendedCondition), err
+			}
+			setSuspend(true)
+
+			Eventually(func() error {
+				_, err := routeClient.RouteV1().Routes(namespaceName).Get(ctx, dashboardNameFromCluster(foundRayCluster), metav1.GetOptions{})
+				return err
+			}).WithTimeout(time.Second * 10).Should(Satisfy(errors.IsNotFound))
+			Eventually(func() error {
+				_, err := k8sClient.CoreV1().Secrets(namespaceName).Get(ctx, oauthSecretNameFromCluster(foundRayCluster), metav1.GetOptions{})
+				return err
+			}).WithTimeout(time.Second * 10).Should(Satisfy(errors.IsNotFound))
+			Eventually(suspendedCondition).WithTimeout(time.Second * 10).
+				Should(HaveField("Status", Equal(metav1.ConditionTrue)))
+
+			By("resuming the RayCluster")
+			setSuspend(false)
+
+			Eventually(func() (*routev1.Route, error) {
+				return routeClient.RouteV1().Routes(namespaceName).Get(ctx, dashboardNameFromCluster(foundRayCluster), metav1.GetOptions{})
+			}).WithTimeout(time.Second * 10).ShouldNot(BeNil())
+			Eventually(func() (*corev1.Secret, error) {
+				return k8sClient.CoreV1().Secrets(namespaceName).Get(ctx, oauthSecretNameFromCluster(foundRayCluster), metav1.GetOptions{})
+			}).WithTimeout(time.Second * 10).ShouldNot(BeNil())
+			Eventually(suspendedCondition).WithTimeout(time.Second * 10).
+				Should(HaveField("Status", Equal(metav1.ConditionFalse)))
+		})
+
 		It("should remove CRB when the RayCluster is deleted", func(ctx SpecContext) {
 			foundRayCluster, err := rayClient.RayV1().RayClusters(namespaceName).Get(ctx, rayClusterName, metav1.GetOptions{})
 			Expect(err).To(Not(HaveOccurred()))
@@ -0,0 +1,234 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/project-codeflare/codeflare-operator/pkg/config"
+	"github.com/project-codeflare/codeflare-operator/pkg/defaults"
+)
+
+const (
+	cookieSecretRotationControllerName = "codeflare-cookie-secret-rotation-controller"
+
+	// CookieSecretRotatedAtAnnotation records, on the OAuth Secret of a RayCluster, when its cookie secret was rotated.
+	CookieSecretRotatedAtAnnotation = "codeflare.dev/cookie-secret-rotated-at"
+
+	// CookieSecretRotatedCondition reports when the cookie secret of the OAuth proxy of the RayCluster was rotated,
+	// and whether the proxy has been restarted with it.
+	CookieSecretRotatedCondition = "CookieSecretRotated"
+
+	cookieSecretKey                     = "cookie_secret"
+	defaultCookieSecretRotationInterval = 720 * time.Hour
+	// cookieSecretRestartCheckInterval is how often the restart of the OAuth proxy is checked after a rotation,
+	// as the head pods aren't watched.
+	cookieSecretRestartCheckInterval = 30 * time.Second
+)
+
+// CookieSecretRotationReconciler rotates the cookie secrets of the OAuth proxies of the Ray dashboards on a schedule.
+// The rotated secret is written to the OAuth Secret of the RayCluster, whose mounted copy is compared, by the liveness
+// probe of the proxy, with the secret the proxy was started with, so that the kubelet restarts the proxy container
+// only, rather than the Ray head. The rotations, and the restarts of the proxy, are reported with the
+// CookieSecretRotated condition of the RayClusters.
+type CookieSecretRotationReconciler struct {
+	client.Client
+	// Reader reads the OAuth Secrets, and the head pods, of the RayClusters, without informing on all of them
+	Reader   client.Reader
+	Config   *config.KubeRayConfiguration
+	Recorder record.EventRecorder
+
+	now func() time.Time
+}
+
+// +kubebuilder:rbac:groups=ray.io,resources=rayclusters,verbs=get;list;watch;patch
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;create;update
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=list
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+
+func (r *CookieSecretRotationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := ctrl.LoggerFrom(ctx)
+
+	cluster := &rayv1.RayCluster{}
+	if err := r.Get(ctx, req.NamespacedName, cluster); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if !cluster.DeletionTimestamp.IsZero() || isRayClusterSuspended(cluster) {
+		// The OAuth objects are removed with the endpoints of the suspended RayClusters
+		return ctrl.Result{}, nil
+	}
+
+	now := r.clock()
+	interval := r.interval()
+
+	secret := &corev1.Secret{}
+	err := r.Reader.Get(ctx, client.ObjectKey{Namespace: cluster.Namespace, Name: oauthSecretNameFromCluster(cluster)}, secret)
+	if errors.IsNotFound(err) {
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      oauthSecretNameFromCluster(cluster),
+				Namespace: cluster.Namespace,
+				Labels:    map[string]string{"ray.io/cluster-name": cluster.Name},
+			},
+		}
+		if err := controllerutil.SetOwnerReference(cluster, secret, r.Scheme()); err != nil {
+			return ctrl.Result{}, err
+		}
+		if err := rotateCookieSecret(secret, now); err != nil {
+			return ctrl.Result{}, err
+		}
+		if err := r.Create(ctx, secret); err != nil {
+			return ctrl.Result{}, err
+		}
+		logger.Info("Created OAuth Secret with a random cookie secret")
+	} else if err != nil {
+		return ctrl.Result{}, err
+	} else if rotatedAt, ok := cookieSecretRotatedAt(secret); !ok || !now.Before(rotatedAt.Add(interval)) {
+		// The cookie secrets derived from the name of the RayClusters, before the rotation was enabled, are rotated
+		if err := rotateCookieSecret(secret, now); err != nil {
+			return ctrl.Result{}, err
+		}
+		if err := r.Update(ctx, secret); err != nil {
+			return ctrl.Result{}, err
+		}
+		logger.Info("Rotated OAuth proxy cookie secret")
+		r.Recorder.Event(cluster, corev1.EventTypeNormal, "CookieSecretRotated",
+			"Rotated the cookie secret of the OAuth proxy, the users are signed out of the Ray dashboard")
+	}
+	rotatedAt, _ := cookieSecretRotatedAt(secret)
+	nextRotation := rotatedAt.Add(interval)
+
+	startedAt, err := r.oauthProxyStartedAt(ctx, cluster)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	requeueAfter := nextRotation.Sub(now)
+	condition := metav1.Condition{
+		Type:               CookieSecretRotatedCondition,
+		Status:             metav1.ConditionTrue,
+		Reason:             "Rotated",
+		Message:            fmt.Sprintf("Cookie secret rotated at %s, next rotation at %s", rotatedAt.Format(time.RFC3339), nextRotation.Format(time.RFC3339)),
+		ObservedGeneration: cluster.Generation,
+	}
+	if startedAt == nil || startedAt.Before(rotatedAt) {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "RestartPending"
+		condition.Message = fmt.Sprintf("Cookie secret rotated at %s, waiting for the OAuth proxy to be restarted", rotatedAt.Format(time.RFC3339))
+		requeueAfter = min(requeueAfter, cookieSecretRestartCheckInterval)
+	}
+
+	conditions, err := rayClusterConditions(cluster)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if meta.SetStatusCondition(&conditions, condition) {
+		value, err := json.Marshal(conditions)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		// The conditions annotation is also patched by the RayCluster controller
+		patch := client.MergeFromWithOptions(cluster.DeepCopy(), client.MergeFromWithOptimisticLock{})
+		metav1.SetMetaDataAnnotation(&cluster.ObjectMeta, ConditionsAnnotation, string(value))
+		if err := r.Patch(ctx, cluster, patch); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// oauthProxyStartedAt returns when the running OAuth proxy container of the head pod of the RayCluster was started,
+// or nil if it's not running.
+func (r *CookieSecretRotationReconciler) oauthProxyStartedAt(ctx context.Context, cluster *rayv1.RayCluster) (*time.Time, error) {
+	pods := &corev1.PodList{}
+	if err := r.Reader.List(ctx, pods, client.InNamespace(cluster.Namespace),
+		client.MatchingLabels{"ray.io/cluster": cluster.Name, "ray.io/node-type": "head"}); err != nil {
+		return nil, err
+	}
+	for _, pod := range pods.Items {
+		if !pod.DeletionTimestamp.IsZero() {
+			continue
+		}
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.Name == defaults.OAuthProxyContainerName && status.State.Running != nil {
+				return &status.State.Running.StartedAt.Time, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// rotateCookieSecret sets a new random cookie secret, of 32 bytes, so that it's a valid AES-256 key for the OAuth proxy.
+func rotateCookieSecret(secret *corev1.Secret, now time.Time) error {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return err
+	}
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+	secret.Data[cookieSecretKey] = []byte(base64.StdEncoding.EncodeToString(b))
+	metav1.SetMetaDataAnnotation(&secret.ObjectMeta, CookieSecretRotatedAtAnnotation, now.UTC().Format(time.RFC3339))
+	return nil
+}
+
+// cookieSecretRotatedAt returns when the cookie secret was rotated, if it's been rotated by the operator.
+func cookieSecretRotatedAt(secret *corev1.Secret) (time.Time, bool) {
+	if len(secret.Data[cookieSecretKey]) == 0 {
+		return time.Time{}, false
+	}
+	rotatedAt, err := time.Parse(time.RFC3339, secret.Annotations[CookieSecretRotatedAtAnnotation])
+	return rotatedAt, err == nil
+}
+
+func (r *CookieSecretRotationReconciler) interval() time.Duration {
+	if rotation := r.Config.CookieSecretRotation; rotation != nil && rotation.Interval != nil {
+		return rotation.Interval.Duration
+	}
+	return defaultCookieSecretRotationInterval
+}
+
+func (r *CookieSecretRotationReconciler) clock() time.Time {
+	if r.now != nil {
+		return r.now()
+	}
+	return time.Now()
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *CookieSecretRotationReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(cookieSecretRotationControllerName).
+		For(&rayv1.RayCluster{}).
+		Complete(instrument(cookieSecretRotationControllerName, rayClusterKind, r))
+}
@@ -0,0 +1,186 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/project-codeflare/codeflare-common/support"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/project-codeflare/codeflare-operator/pkg/config"
+	"github.com/project-codeflare/codeflare-operator/pkg/defaults"
+)
+
+func TestCookieSecretRotationReconcile(t *testing.T) {
+	test := support.NewTest(t)
+
+	scheme := runtime.NewScheme()
+	test.Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+	test.Expect(rayv1.AddToScheme(scheme)).To(Succeed())
+
+	// The rotation timestamps are serialized with a second precision
+	now := time.Now().UTC().Truncate(time.Second)
+	cfg := &config.KubeRayConfiguration{CookieSecretRotation: &config.CookieSecretRotationConfiguration{
+		Enabled:  ptr.To(true),
+		Interval: &metav1.Duration{Duration: 24 * time.Hour},
+	}}
+
+	rayCluster := &rayv1.RayCluster{
+		TypeMeta:   metav1.TypeMeta{APIVersion: rayv1.GroupVersion.String(), Kind: "RayCluster"},
+		ObjectMeta: metav1.ObjectMeta{Name: "raycluster", Namespace: "ns", UID: "raycluster-uid"},
+	}
+	oauthSecret := func(rotatedAt time.Time) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "raycluster-oauth-config",
+				Namespace:   "ns",
+				Annotations: map[string]string{CookieSecretRotatedAtAnnotation: rotatedAt.Format(time.RFC3339)},
+			},
+			Data: map[string][]byte{"cookie_secret": []byte("secret")},
+		}
+	}
+	headPod := func(startedAt time.Time) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "raycluster-head",
+				Namespace: "ns",
+				Labels:    map[string]string{"ray.io/cluster": "raycluster", "ray.io/node-type": "head"},
+			},
+			Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{
+				Name:  defaults.OAuthProxyContainerName,
+				State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{StartedAt: metav1.NewTime(startedAt)}},
+			}}},
+		}
+	}
+	reconcile := func(objects ...client.Object) (ctrl.Result, client.Client, *record.FakeRecorder) {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+		recorder := record.NewFakeRecorder(10)
+		r := &CookieSecretRotationReconciler{Client: c, Reader: c, Config: cfg, Recorder: recorder, now: func() time.Time { return now }}
+		result, err := r.Reconcile(test.Ctx(), ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "ns", Name: "raycluster"}})
+		test.Expect(err).NotTo(HaveOccurred())
+		return result, c, recorder
+	}
+	getSecret := func(c client.Client) *corev1.Secret {
+		secret := &corev1.Secret{}
+		test.Expect(c.Get(test.Ctx(), client.ObjectKey{Namespace: "ns", Name: "raycluster-oauth-config"}, secret)).To(Succeed())
+		return secret
+	}
+	getCondition := func(c client.Client) *metav1.Condition {
+		rc := &rayv1.RayCluster{}
+		test.Expect(c.Get(test.Ctx(), client.ObjectKey{Namespace: "ns", Name: "raycluster"}, rc)).To(Succeed())
+		conditions, err := rayClusterConditions(rc)
+		test.Expect(err).NotTo(HaveOccurred())
+		return meta.FindStatusCondition(conditions, CookieSecretRotatedCondition)
+	}
+
+	test.T().Run("Expected OAuth Secret to be created with a random cookie secret", func(t *testing.T) {
+		result, c, _ := reconcile(rayCluster.DeepCopy())
+
+		secret := getSecret(c)
+		test.Expect(secret.Data["cookie_secret"]).To(HaveLen(44))
+		test.Expect(secret.Annotations).To(HaveKeyWithValue(CookieSecretRotatedAtAnnotation, now.Format(time.RFC3339)))
+		test.Expect(secret.OwnerReferences).To(ConsistOf(HaveField("UID", Equal(types.UID("raycluster-uid")))))
+		test.Expect(getCondition(c)).To(And(
+			HaveField("Status", Equal(metav1.ConditionFalse)),
+			HaveField("Reason", Equal("RestartPending")),
+		))
+		test.Expect(result.RequeueAfter).To(Equal(cookieSecretRestartCheckInterval))
+	})
+
+	test.T().Run("Expected cookie secret to be rotated once the interval has elapsed", func(t *testing.T) {
+		rotatedAt := now.Add(-25 * time.Hour)
+		_, c, recorder := reconcile(rayCluster.DeepCopy(), oauthSecret(rotatedAt), headPod(rotatedAt.Add(time.Minute)))
+
+		secret := getSecret(c)
+		test.Expect(secret.Data["cookie_secret"]).NotTo(Equal([]byte("secret")))
+		test.Expect(secret.Annotations).To(HaveKeyWithValue(CookieSecretRotatedAtAnnotation, now.Format(time.RFC3339)))
+		test.Expect(recorder.Events).To(Receive(ContainSubstring("CookieSecretRotated")))
+		// The proxy was started before the rotation
+		test.Expect(getCondition(c)).To(And(
+			HaveField("Status", Equal(metav1.ConditionFalse)),
+			HaveField("Reason", Equal("RestartPending")),
+			HaveField("Message", ContainSubstring(now.Format(time.RFC3339))),
+		))
+	})
+
+	test.T().Run("Expected rotation to be reported once the OAuth proxy is restarted", func(t *testing.T) {
+		rotatedAt := now.Add(-time.Hour)
+		result, c, recorder := reconcile(rayCluster.DeepCopy(), oauthSecret(rotatedAt), headPod(rotatedAt.Add(time.Minute)))
+
+		test.Expect(getSecret(c).Data["cookie_secret"]).To(Equal([]byte("secret")))
+		test.Expect(recorder.Events).NotTo(Receive())
+		test.Expect(getCondition(c)).To(And(
+			HaveField("Status", Equal(metav1.ConditionTrue)),
+			HaveField("Reason", Equal("Rotated")),
+			HaveField("Message", Equal("Cookie secret rotated at "+rotatedAt.Format(time.RFC3339)+
+				", next rotation at "+rotatedAt.Add(24*time.Hour).Format(time.RFC3339))),
+		))
+		test.Expect(result.RequeueAfter).To(Equal(23 * time.Hour))
+	})
+
+	test.T().Run("Expected cookie secret derived from the RayCluster name to be rotated", func(t *testing.T) {
+		secret := oauthSecret(now)
+		delete(secret.Annotations, CookieSecretRotatedAtAnnotation)
+		_, c, _ := reconcile(rayCluster.DeepCopy(), secret)
+
+		test.Expect(getSecret(c).Data["cookie_secret"]).NotTo(Equal([]byte("secret")))
+		test.Expect(getSecret(c).Annotations).To(HaveKey(CookieSecretRotatedAtAnnotation))
+	})
+
+	test.T().Run("Negative: Expected no OAuth Secret for suspended RayClusters", func(t *testing.T) {
+		suspended := rayCluster.DeepCopy()
+		suspended.Spec.Suspend = ptr.To(true)
+		_, c, _ := reconcile(suspended)
+
+		err := c.Get(test.Ctx(), client.ObjectKey{Namespace: "ns", Name: "raycluster-oauth-config"}, &corev1.Secret{})
+		test.Expect(err).To(HaveOccurred())
+	})
+}
+
+func TestCookieSecretRotationMetrics(t *testing.T) {
+	test := support.NewTest(t)
+
+	test.T().Run("Expected operator metrics to be gathered with the cookie secret rotation enabled", func(t *testing.T) {
+		// Both the RayCluster controller and the cookie secret rotation controller reconcile RayClusters
+		instrument(controllerName, rayClusterKind, &RayClusterReconciler{})
+		instrument(cookieSecretRotationControllerName, rayClusterKind, &CookieSecretRotationReconciler{})
+		for _, name := range []string{controllerName, cookieSecretRotationControllerName} {
+			queue := workqueue.NewNamed(name)
+			t.Cleanup(queue.ShutDown)
+			queue.Add("ns/raycluster")
+		}
+
+		test.Expect(workqueueDepths(test, metrics.Registry, rayClusterKind)).To(Equal([]float64{2}))
+	})
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// DashboardReadyCondition reports whether the dashboard of the RayCluster serves requests at the URL
+	// of its Route or Ingress, recorded in the dashboard URL annotation.
+	DashboardReadyCondition = "DashboardReady"
+	// DashboardURLAnnotation holds the URL the dashboard of the RayCluster is exposed at.
+	DashboardURLAnnotation = "codeflare.dev/dashboard-url"
+
+	dashboardProbeTimeout = 5 * time.Second
+	// dashboardProbeInterval is the period at which the dashboard is probed until it's ready,
+	// as the availability of the Route or Ingress backends doesn't trigger reconciliations
+	dashboardProbeInterval = 10 * time.Second
+)
+
+// newDashboardProbeClient returns the HTTP client probing the dashboards. The certificates of the Routes
+// aren't verified, as they're commonly signed by CAs the operator doesn't trust, and the probes carry no
+// credentials. The redirects, e.g., to the OAuth login page, aren't followed, as they're served by the dashboard.
+func newDashboardProbeClient() *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	return &http.Client{
+		Transport: transport,
+		Timeout:   dashboardProbeTimeout,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// dashboardURL returns the URL of the dashboard exposed at the host, i.e., over TLS for the Routes,
+// that terminate it, and in plain HTTP for the Ingresses, that have no TLS configuration.
+func dashboardURL(host string, tls bool) string {
+	scheme := "http"
+	if tls {
+		scheme = "https"
+	}
+	return (&url.URL{Scheme: scheme, Host: host}).String()
+}
+
+// probeDashboard requests the dashboard at the URL, and returns an error if it's not served, i.e., the host can't
+// be reached, or the router, or the Ingress controller, responds it has no available backend.
+func probeDashboard(ctx context.Context, httpClient *http.Client, dashboardURL string) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, dashboardURL, nil)
+	if err != nil {
+		return err
+	}
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	response.Body.Close()
+	switch response.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return fmt.Errorf("%s responded %s", dashboardURL, response.Status)
+	}
+	return nil
+}
+
+// updateDashboardReadyCondition probes the dashboard of the RayCluster, at the URL it's exposed at, and records
+// the outcome with the DashboardReady condition, along with the URL, so that clients don't have to resolve the Routes,
+// or the Ingresses, and wait for them to serve requests. An empty URL means the dashboard isn't exposed, e.g., because
+// the RayCluster is suspended, and the condition is removed. It returns the delay after which the dashboard should be
+// probed again, or zero once it's ready.
+func (r *RayClusterReconciler) updateDashboardReadyCondition(ctx context.Context, cluster *rayv1.RayCluster, url string) (time.Duration, error) {
+	conditions, err := rayClusterConditions(cluster)
+	if err != nil {
+		return 0, err
+	}
+
+	var probeAfter time.Duration
+	changed := false
+	if url == "" {
+		changed = meta.RemoveStatusCondition(&conditions, DashboardReadyCondition)
+	} else {
+		condition := metav1.Condition{
+			Type:               DashboardReadyCondition,
+			Status:             metav1.ConditionTrue,
+			Reason:             "DashboardAvailable",
+			Message:            "Ray dashboard is available at " + url,
+			ObservedGeneration: cluster.Generation,
+		}
+		if cluster.Status.State != rayv1.Ready {
+			// The RayCluster is reconciled again once it's ready
+			condition.Status = metav1.ConditionFalse
+			condition.Reason = "RayClusterNotReady"
+			condition.Message = "RayCluster is not ready"
+		} else if err := probeDashboard(ctx, r.dashboardProbeClient, url); err != nil {
+			condition.Status = metav1.ConditionFalse
+			condition.Reason = "DashboardUnavailable"
+			condition.Message = "Ray dashboard is not available: " + err.Error()
+			probeAfter = dashboardProbeInterval
+		}
+		changed = meta.SetStatusCondition(&conditions, condition)
+	}
+
+	if !changed && cluster.Annotations[DashboardURLAnnotation] == url {
+		return probeAfter, nil
+	}
+	value, err := json.Marshal(conditions)
+	if err != nil {
+		return 0, err
+	}
+	patch := client.MergeFrom(cluster.DeepCopy())
+	metav1.SetMetaDataAnnotation(&cluster.ObjectMeta, ConditionsAnnotation, string(value))
+	if url == "" {
+		delete(cluster.Annotations, DashboardURLAnnotation)
+	} else {
+		metav1.SetMetaDataAnnotation(&cluster.ObjectMeta, DashboardURLAnnotation, url)
+	}
+	return probeAfter, r.Patch(ctx, cluster, patch)
+}
@@ -0,0 +1,164 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/project-codeflare/codeflare-common/support"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func dashboardServer(t *testing.T, status int) *httptest.Server {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if status == http.StatusFound {
+			http.Redirect(w, r, "https://oauth.example.com/login", status)
+			return
+		}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestProbeDashboard(t *testing.T) {
+	test := support.NewTest(t)
+	ctx := context.Background()
+	httpClient := newDashboardProbeClient()
+
+	test.T().Run("Expected dashboard to be served when it responds", func(t *testing.T) {
+		// The OAuth proxy redirects to the login page, or denies the requests without credentials
+		for _, status := range []int{http.StatusOK, http.StatusFound, http.StatusForbidden} {
+			test.Expect(probeDashboard(ctx, httpClient, dashboardServer(t, status).URL)).To(Succeed())
+		}
+	})
+
+	test.T().Run("Expected dashboard not to be served when it has no available backend", func(t *testing.T) {
+		for _, status := range []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout} {
+			test.Expect(probeDashboard(ctx, httpClient, dashboardServer(t, status).URL)).To(MatchError(ContainSubstring(http.StatusText(status))))
+		}
+	})
+
+	test.T().Run("Expected dashboard not to be served when it's unreachable", func(t *testing.T) {
+		server := dashboardServer(t, http.StatusOK)
+		server.Close()
+		test.Expect(probeDashboard(ctx, httpClient, server.URL)).NotTo(Succeed())
+	})
+
+	test.T().Run("Expected dashboard URL to match its exposure", func(t *testing.T) {
+		test.Expect(dashboardURL("ray-dashboard-raycluster-ns.apps.example.com", true)).To(Equal("https://ray-dashboard-raycluster-ns.apps.example.com"))
+		test.Expect(dashboardURL("ray-dashboard-raycluster-ns.kind", false)).To(Equal("http://ray-dashboard-raycluster-ns.kind"))
+	})
+}
+
+func TestUpdateDashboardReadyCondition(t *testing.T) {
+	test := support.NewTest(t)
+	ctx := context.Background()
+
+	scheme := runtime.NewScheme()
+	test.Expect(rayv1.AddToScheme(scheme)).To(Succeed())
+
+	newReconciler := func(cluster *rayv1.RayCluster) *RayClusterReconciler {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster).Build()
+		return &RayClusterReconciler{Client: c, dashboardProbeClient: newDashboardProbeClient()}
+	}
+	readyCluster := func() *rayv1.RayCluster {
+		return &rayv1.RayCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "raycluster", Namespace: "ns"},
+			Status:     rayv1.RayClusterStatus{State: rayv1.Ready},
+		}
+	}
+	condition := func(r *RayClusterReconciler, cluster *rayv1.RayCluster) (*metav1.Condition, string) {
+		test.Expect(r.Get(ctx, client.ObjectKeyFromObject(cluster), cluster)).To(Succeed())
+		conditions, err := rayClusterConditions(cluster)
+		test.Expect(err).NotTo(HaveOccurred())
+		return meta.FindStatusCondition(conditions, DashboardReadyCondition), cluster.Annotations[DashboardURLAnnotation]
+	}
+
+	test.T().Run("Expected DashboardReady condition and URL once the dashboard is served", func(t *testing.T) {
+		cluster := readyCluster()
+		r := newReconciler(cluster)
+		url := dashboardServer(t, http.StatusOK).URL
+
+		probeAfter, err := r.updateDashboardReadyCondition(ctx, cluster, url)
+		test.Expect(err).NotTo(HaveOccurred())
+		test.Expect(probeAfter).To(BeZero())
+
+		ready, dashboardURL := condition(r, cluster)
+		test.Expect(ready).To(And(
+			HaveField("Status", Equal(metav1.ConditionTrue)),
+			HaveField("Reason", Equal("DashboardAvailable")),
+		))
+		test.Expect(dashboardURL).To(Equal(url))
+	})
+
+	test.T().Run("Expected dashboard to be probed again until it's served", func(t *testing.T) {
+		cluster := readyCluster()
+		r := newReconciler(cluster)
+
+		probeAfter, err := r.updateDashboardReadyCondition(ctx, cluster, dashboardServer(t, http.StatusServiceUnavailable).URL)
+		test.Expect(err).NotTo(HaveOccurred())
+		test.Expect(probeAfter).To(Equal(dashboardProbeInterval))
+
+		ready, _ := condition(r, cluster)
+		test.Expect(ready).To(And(
+			HaveField("Status", Equal(metav1.ConditionFalse)),
+			HaveField("Reason", Equal("DashboardUnavailable")),
+		))
+	})
+
+	test.T().Run("Expected dashboard not to be probed until the RayCluster is ready", func(t *testing.T) {
+		cluster := readyCluster()
+		cluster.Status.State = ""
+		r := newReconciler(cluster)
+		r.dashboardProbeClient = nil
+
+		probeAfter, err := r.updateDashboardReadyCondition(ctx, cluster, "https://ray-dashboard-raycluster-ns.apps.example.com")
+		test.Expect(err).NotTo(HaveOccurred())
+		test.Expect(probeAfter).To(BeZero())
+
+		ready, _ := condition(r, cluster)
+		test.Expect(ready).To(And(
+			HaveField("Status", Equal(metav1.ConditionFalse)),
+			HaveField("Reason", Equal("RayClusterNotReady")),
+		))
+	})
+
+	test.T().Run("Expected DashboardReady condition and URL to be removed once the dashboard isn't exposed", func(t *testing.T) {
+		cluster := readyCluster()
+		r := newReconciler(cluster)
+		_, err := r.updateDashboardReadyCondition(ctx, cluster, dashboardServer(t, http.StatusOK).URL)
+		test.Expect(err).NotTo(HaveOccurred())
+
+		_, err = r.updateDashboardReadyCondition(ctx, cluster, "")
+		test.Expect(err).NotTo(HaveOccurred())
+
+		ready, dashboardURL := condition(r, cluster)
+		test.Expect(ready).To(BeNil())
+		test.Expect(dashboardURL).To(BeEmpty())
+	})
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/project-codeflare/codeflare-operator/pkg/defaults"
+)
+
+const (
+	// SecretsReadyCondition reports whether the Secrets listed in the codeflare.dev/external-secrets annotation
+	// of the RayCluster exist, or which of them the Ray pods wait for.
+	SecretsReadyCondition = "SecretsReady"
+
+	// externalSecretsRecheckInterval is the period at which the Secrets are checked again, and the Ray pods
+	// ungated, as the reconciler watches neither Secrets nor pods
+	externalSecretsRecheckInterval = 15 * time.Second
+)
+
+var externalSecretGVK = schema.GroupVersionKind{Group: "external-secrets.io", Version: "v1beta1", Kind: "ExternalSecret"}
+
+// releaseExternalSecretsGate removes the scheduling gate of the Ray pods of the RayCluster once the Secrets listed in
+// its codeflare.dev/external-secrets annotation exist, so that the pods don't start before the Secrets they consume are
+// materialized, and records the missing Secrets with the SecretsReady condition. As pods may be created at any time,
+// e.g., by the autoscaler, it returns the delay after which the RayCluster must be checked again, if any.
+func (r *RayClusterReconciler) releaseExternalSecretsGate(ctx context.Context, cluster *rayv1.RayCluster, suspended bool) (time.Duration, error) {
+	names := defaults.ExternalSecretNames(cluster)
+	if len(names) == 0 || suspended || r.Reader == nil {
+		return 0, nil
+	}
+
+	var missing []string
+	for _, name := range names {
+		secret := &metav1.PartialObjectMetadata{}
+		secret.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("Secret"))
+		if err := r.Reader.Get(ctx, client.ObjectKey{Namespace: cluster.Namespace, Name: name}, secret); errors.IsNotFound(err) {
+			missing = append(missing, name)
+		} else if err != nil {
+			return 0, err
+		}
+	}
+
+	conditions, err := rayClusterConditions(cluster)
+	if err != nil {
+		return 0, err
+	}
+	current := meta.FindStatusCondition(conditions, SecretsReadyCondition)
+	condition := metav1.Condition{
+		Type:               SecretsReadyCondition,
+		Status:             metav1.ConditionTrue,
+		Reason:             "SecretsAvailable",
+		Message:            fmt.Sprintf("The Secrets %s are available", strings.Join(names, ", ")),
+		ObservedGeneration: cluster.Generation,
+	}
+	if len(missing) > 0 {
+		messages := []string{fmt.Sprintf("The Ray pods wait for the Secrets %s to be materialized", strings.Join(missing, ", "))}
+		for _, name := range missing {
+			if message := r.externalSecretNotReadyMessage(ctx, cluster.Namespace, name); message != "" {
+				messages = append(messages, message)
+			}
+		}
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "SecretsMissing"
+		condition.Message = strings.Join(messages, "; ")
+	}
+	if meta.SetStatusCondition(&conditions, condition) {
+		if err := r.patchRayClusterConditions(ctx, cluster, conditions); err != nil {
+			return 0, err
+		}
+	}
+	if len(missing) > 0 {
+		if current == nil || current.Status != metav1.ConditionFalse {
+			recordFailure(r.Recorder, cluster, rayClusterKind, DependencyMissingReason, "%s", condition.Message)
+		}
+		return externalSecretsRecheckInterval, nil
+	}
+
+	pods := &corev1.PodList{}
+	if err := r.Reader.List(ctx, pods, client.InNamespace(cluster.Namespace), client.MatchingLabels{"ray.io/cluster": cluster.Name}); err != nil {
+		return 0, err
+	}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		isExternalSecretsGate := func(gate corev1.PodSchedulingGate) bool { return gate.Name == defaults.ExternalSecretsSchedulingGate }
+		if !slices.ContainsFunc(pod.Spec.SchedulingGates, isExternalSecretsGate) {
+			continue
+		}
+		patch := client.MergeFromWithOptions(pod.DeepCopy(), client.MergeFromWithOptimisticLock{})
+		pod.Spec.SchedulingGates = slices.DeleteFunc(pod.Spec.SchedulingGates, isExternalSecretsGate)
+		if err := r.Patch(ctx, pod, patch); err != nil {
+			return 0, err
+		}
+	}
+	return externalSecretsRecheckInterval, nil
+}
+
+// externalSecretNotReadyMessage returns the reason why the ExternalSecret named after the Secret, if any, hasn't
+// materialized it yet, so that, e.g., a missing Vault path or permission is reported on the RayCluster.
+func (r *RayClusterReconciler) externalSecretNotReadyMessage(ctx context.Context, namespace, name string) string {
+	externalSecret := &unstructured.Unstructured{}
+	externalSecret.SetGroupVersionKind(externalSecretGVK)
+	if err := r.Reader.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, externalSecret); err != nil {
+		// The ExternalSecret API may not be installed, or the Secret be materialized otherwise
+		return ""
+	}
+	conditions, _, _ := unstructured.NestedSlice(externalSecret.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]any)
+		if !ok || condition["type"] != "Ready" || condition["status"] == string(metav1.ConditionTrue) {
+			continue
+		}
+		return fmt.Sprintf("ExternalSecret %s is not ready: %v", name, condition["message"])
+	}
+	return ""
+}
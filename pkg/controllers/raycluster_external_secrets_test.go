@@ -0,0 +1,123 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/project-codeflare/codeflare-common/support"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/project-codeflare/codeflare-operator/pkg/defaults"
+)
+
+func TestReleaseExternalSecretsGate(t *testing.T) {
+	test := support.NewTest(t)
+	ctx := context.Background()
+
+	scheme := runtime.NewScheme()
+	test.Expect(rayv1.AddToScheme(scheme)).To(Succeed())
+	test.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+	annotatedCluster := func() *rayv1.RayCluster {
+		return &rayv1.RayCluster{ObjectMeta: metav1.ObjectMeta{
+			Name:        "raycluster",
+			Namespace:   "ns",
+			Annotations: map[string]string{defaults.ExternalSecretsAnnotation: "vault-creds, hf-token"},
+		}}
+	}
+	secret := func(name string) *corev1.Secret {
+		return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns"}}
+	}
+	gatedPod := func(name string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns", Labels: map[string]string{"ray.io/cluster": "raycluster"}},
+			Spec: corev1.PodSpec{SchedulingGates: []corev1.PodSchedulingGate{
+				{Name: defaults.ExternalSecretsSchedulingGate},
+				{Name: "other.io/gate"},
+			}},
+		}
+	}
+	newReconciler := func(objects ...client.Object) *RayClusterReconciler {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+		return &RayClusterReconciler{Client: c, Reader: c}
+	}
+	condition := func(r *RayClusterReconciler, cluster *rayv1.RayCluster) *metav1.Condition {
+		test.Expect(r.Get(ctx, client.ObjectKeyFromObject(cluster), cluster)).To(Succeed())
+		conditions, err := rayClusterConditions(cluster)
+		test.Expect(err).NotTo(HaveOccurred())
+		return meta.FindStatusCondition(conditions, SecretsReadyCondition)
+	}
+	schedulingGates := func(r *RayClusterReconciler, name string) []corev1.PodSchedulingGate {
+		pod := &corev1.Pod{}
+		test.Expect(r.Get(ctx, client.ObjectKey{Namespace: "ns", Name: name}, pod)).To(Succeed())
+		return pod.Spec.SchedulingGates
+	}
+
+	test.T().Run("Expected Ray pods to stay gated while Secrets are missing", func(t *testing.T) {
+		cluster := annotatedCluster()
+		r := newReconciler(cluster, secret("vault-creds"), gatedPod("raycluster-head"))
+
+		checkAfter, err := r.releaseExternalSecretsGate(ctx, cluster, false)
+		test.Expect(err).NotTo(HaveOccurred())
+		test.Expect(checkAfter).To(Equal(externalSecretsRecheckInterval))
+		test.Expect(condition(r, cluster)).To(And(
+			HaveField("Status", Equal(metav1.ConditionFalse)),
+			HaveField("Reason", Equal("SecretsMissing")),
+			HaveField("Message", Equal("The Ray pods wait for the Secrets hf-token to be materialized")),
+		))
+		test.Expect(schedulingGates(r, "raycluster-head")).To(HaveLen(2))
+	})
+
+	test.T().Run("Expected Ray pods to be ungated once all the Secrets exist", func(t *testing.T) {
+		cluster := annotatedCluster()
+		r := newReconciler(cluster, secret("vault-creds"), secret("hf-token"), gatedPod("raycluster-head"), gatedPod("raycluster-worker"))
+
+		_, err := r.releaseExternalSecretsGate(ctx, cluster, false)
+		test.Expect(err).NotTo(HaveOccurred())
+		test.Expect(condition(r, cluster)).To(And(
+			HaveField("Status", Equal(metav1.ConditionTrue)),
+			HaveField("Reason", Equal("SecretsAvailable")),
+		))
+		test.Expect(schedulingGates(r, "raycluster-head")).To(ConsistOf(corev1.PodSchedulingGate{Name: "other.io/gate"}))
+		test.Expect(schedulingGates(r, "raycluster-worker")).To(ConsistOf(corev1.PodSchedulingGate{Name: "other.io/gate"}))
+	})
+
+	test.T().Run("Negative: Expected RayClusters without external Secrets, or suspended, to be skipped", func(t *testing.T) {
+		cluster := annotatedCluster()
+		r := newReconciler(cluster)
+
+		checkAfter, err := r.releaseExternalSecretsGate(ctx, cluster, true)
+		test.Expect(err).NotTo(HaveOccurred())
+		test.Expect(checkAfter).To(BeZero())
+		test.Expect(condition(r, cluster)).To(BeNil())
+
+		plain := &rayv1.RayCluster{ObjectMeta: metav1.ObjectMeta{Name: "plain", Namespace: "ns"}}
+		checkAfter, err = newReconciler(plain).releaseExternalSecretsGate(ctx, plain, false)
+		test.Expect(err).NotTo(HaveOccurred())
+		test.Expect(checkAfter).To(BeZero())
+	})
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	rayClusterKind = "RayCluster"
+
+	// imagePullFailuresRecheckInterval is the period at which the pods of the RayClusters that aren't ready are checked
+	// for image pull failures, as the reconciler doesn't watch pods
+	imagePullFailuresRecheckInterval = 30 * time.Second
+)
+
+// imagePullFailureTracker tracks the containers, of the pods of each RayCluster, whose image pull failures have been
+// reported, so that they're reported once.
+type imagePullFailureTracker struct {
+	mu       sync.Mutex
+	reported map[types.NamespacedName]sets.Set[string]
+}
+
+func (t *imagePullFailureTracker) swap(key types.NamespacedName, failing sets.Set[string]) sets.Set[string] {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.reported == nil {
+		t.reported = map[types.NamespacedName]sets.Set[string]{}
+	}
+	reported := t.reported[key]
+	t.reported[key] = failing
+	return reported
+}
+
+func (t *imagePullFailureTracker) forget(key types.NamespacedName) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.reported, key)
+}
+
+// reportImagePullFailures reports, with an ImagePullBackOff Warning Event, each container of the Ray pods that cannot
+// pull its image, while the RayCluster isn't ready. It returns the delay after which the pods must be checked again, if any.
+func (r *RayClusterReconciler) reportImagePullFailures(ctx context.Context, cluster *rayv1.RayCluster, suspended bool) (time.Duration, error) {
+	key := client.ObjectKeyFromObject(cluster)
+	if suspended || cluster.Status.State == rayv1.Ready || r.Reader == nil {
+		r.imagePullFailures.forget(key)
+		return 0, nil
+	}
+
+	pods := &corev1.PodList{}
+	if err := r.Reader.List(ctx, pods, client.InNamespace(cluster.Namespace), client.MatchingLabels{"ray.io/cluster": cluster.Name}); err != nil {
+		return 0, err
+	}
+	type failure struct {
+		pod    *corev1.Pod
+		status *corev1.ContainerStatus
+	}
+	failing := map[string]failure{}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		statuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+		for j := range statuses {
+			status := &statuses[j]
+			if status.State.Waiting != nil && (status.State.Waiting.Reason == "ImagePullBackOff" || status.State.Waiting.Reason == "ErrImagePull") {
+				failing[string(pod.UID)+"/"+status.Name] = failure{pod: pod, status: status}
+			}
+		}
+	}
+
+	reported := r.imagePullFailures.swap(key, sets.KeySet(failing))
+	for id, f := range failing {
+		if reported.Has(id) {
+			continue
+		}
+		recordFailure(r.Recorder, cluster, rayClusterKind, ImagePullBackOffReason, "Container %s of pod %s cannot pull image %s: %s",
+			f.status.Name, f.pod.Name, f.status.Image, f.status.State.Waiting.Message)
+	}
+	return imagePullFailuresRecheckInterval, nil
+}
+
+// reportQuotaExceeded reports, with a QuotaExceeded Warning Event, each resource the RayCluster requests more of
+// than the capacity of its ClusterQueue, as it'd never be admitted.
+func (r *RayClusterReconciler) reportQuotaExceeded(ctx context.Context, cluster *rayv1.RayCluster) {
+	for _, warning := range quotaWarnings(ctx, r.queueCapacities, cluster) {
+		recordFailure(r.Recorder, cluster, rayClusterKind, QuotaExceededReason, "%s", warning)
+	}
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/project-codeflare/codeflare-common/support"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	kueueconstants "sigs.k8s.io/kueue/pkg/controller/constants"
+)
+
+func TestReportImagePullFailures(t *testing.T) {
+	test := support.NewTest(t)
+	ctx := context.Background()
+
+	scheme := runtime.NewScheme()
+	test.Expect(rayv1.AddToScheme(scheme)).To(Succeed())
+	test.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+	cluster := &rayv1.RayCluster{ObjectMeta: metav1.ObjectMeta{Name: "raycluster", Namespace: "ns"}}
+	pod := func(name, reason string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns", UID: types.UID("uid-" + name), Labels: map[string]string{"ray.io/cluster": "raycluster"}},
+			Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{
+				Name:  "ray-worker",
+				Image: "quay.io/project-codeflare/ray:missing",
+				State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: reason, Message: "manifest unknown"}},
+			}}},
+		}
+	}
+	newReconciler := func(objects ...client.Object) (*RayClusterReconciler, *record.FakeRecorder) {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+		recorder := record.NewFakeRecorder(10)
+		return &RayClusterReconciler{Client: c, Reader: c, Recorder: recorder}, recorder
+	}
+	imagePullFailures := func() float64 {
+		return testutil.ToFloat64(failures.WithLabelValues(rayClusterKind, string(ImagePullBackOffReason)))
+	}
+
+	test.T().Run("Expected image pull failures to be reported once per container", func(t *testing.T) {
+		r, recorder := newReconciler(cluster, pod("worker-1", "ImagePullBackOff"), pod("worker-2", "ErrImagePull"), pod("worker-3", "ContainerCreating"))
+		reported := imagePullFailures()
+
+		checkAfter, err := r.reportImagePullFailures(ctx, cluster, false)
+		test.Expect(err).NotTo(HaveOccurred())
+		test.Expect(checkAfter).To(Equal(imagePullFailuresRecheckInterval))
+		test.Expect(imagePullFailures()).To(Equal(reported + 2))
+		test.Expect(recorder.Events).To(HaveLen(2))
+		test.Expect(<-recorder.Events).To(And(
+			HavePrefix("Warning ImagePullBackOff Container ray-worker of pod worker-"),
+			HaveSuffix("cannot pull image quay.io/project-codeflare/ray:missing: manifest unknown"),
+		))
+
+		// The failures that have been reported aren't reported again
+		_, err = r.reportImagePullFailures(ctx, cluster, false)
+		test.Expect(err).NotTo(HaveOccurred())
+		test.Expect(imagePullFailures()).To(Equal(reported + 2))
+	})
+
+	test.T().Run("Negative: Expected pods of ready, or suspended, RayClusters not to be checked", func(t *testing.T) {
+		ready := cluster.DeepCopy()
+		ready.Status.State = rayv1.Ready
+		r, recorder := newReconciler(ready, pod("worker-1", "ImagePullBackOff"))
+
+		checkAfter, err := r.reportImagePullFailures(ctx, ready, false)
+		test.Expect(err).NotTo(HaveOccurred())
+		test.Expect(checkAfter).To(BeZero())
+
+		checkAfter, err = r.reportImagePullFailures(ctx, cluster, true)
+		test.Expect(err).NotTo(HaveOccurred())
+		test.Expect(checkAfter).To(BeZero())
+		test.Expect(recorder.Events).To(BeEmpty())
+	})
+}
+
+func TestReportQuotaExceeded(t *testing.T) {
+	test := support.NewTest(t)
+	ctx := context.Background()
+
+	scheme := runtime.NewScheme()
+	test.Expect(rayv1.AddToScheme(scheme)).To(Succeed())
+	test.Expect(kueue.AddToScheme(scheme)).To(Succeed())
+
+	cluster := &rayv1.RayCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "raycluster", Namespace: "ns", Labels: map[string]string{kueueconstants.QueueLabel: "queue"}},
+		Spec: rayv1.RayClusterSpec{HeadGroupSpec: rayv1.HeadGroupSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("16")}}}},
+		}}}},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		cluster,
+		&kueue.LocalQueue{ObjectMeta: metav1.ObjectMeta{Name: "queue", Namespace: "ns"}, Spec: kueue.LocalQueueSpec{ClusterQueue: "team-a"}},
+		&kueue.ClusterQueue{
+			ObjectMeta: metav1.ObjectMeta{Name: "team-a"},
+			Spec: kueue.ClusterQueueSpec{ResourceGroups: []kueue.ResourceGroup{{
+				CoveredResources: []corev1.ResourceName{corev1.ResourceCPU},
+				Flavors: []kueue.FlavorQuotas{
+					{Name: "default", Resources: []kueue.ResourceQuota{{Name: corev1.ResourceCPU, NominalQuota: resource.MustParse("8")}}},
+				},
+			}}},
+		},
+	).Build()
+	recorder := record.NewFakeRecorder(10)
+	r := &RayClusterReconciler{Client: c, Reader: c, Recorder: recorder, queueCapacities: newQueueCapacityCache(c)}
+
+	test.T().Run("Expected QuotaExceeded to be reported once the queued RayCluster is suspended", func(t *testing.T) {
+		reported := testutil.ToFloat64(failures.WithLabelValues(rayClusterKind, string(QuotaExceededReason)))
+
+		test.Expect(r.updateSuspendedCondition(ctx, cluster, true)).To(Succeed())
+		test.Expect(recorder.Events).To(Receive(Equal("Warning QuotaExceeded RayCluster requests 16 cpu in total, more than the 8 quota of ClusterQueue team-a, and will never be admitted")))
+
+		// The RayCluster is only reported when it gets suspended
+		test.Expect(r.updateSuspendedCondition(ctx, cluster, true)).To(Succeed())
+		test.Expect(recorder.Events).To(BeEmpty())
+		test.Expect(testutil.ToFloat64(failures.WithLabelValues(rayClusterKind, string(QuotaExceededReason)))).To(Equal(reported + 1))
+	})
+}
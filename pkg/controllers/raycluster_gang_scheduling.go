@@ -0,0 +1,220 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	awv1beta2 "github.com/project-codeflare/appwrapper/api/v1beta2"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	kueueconstants "sigs.k8s.io/kueue/pkg/controller/constants"
+
+	"github.com/project-codeflare/codeflare-operator/pkg/config"
+)
+
+const (
+	defaultGangSchedulingTimeout = 5 * time.Minute
+	// gangSchedulingRecheckInterval is the period at which partially scheduled RayClusters are checked again,
+	// as the reconciler doesn't watch pods
+	gangSchedulingRecheckInterval = 30 * time.Second
+)
+
+// verifyGangScheduling verifies that all the pods of an admitted RayCluster are scheduled within the configured timeout,
+// and records the outcome with the PartiallyScheduled condition. The verification completes once all the pods have been
+// scheduled, so that pods added later, e.g., by the autoscaler, are not considered, and starts over when the RayCluster
+// is resumed. It returns the delay after which the RayCluster must be verified again, if any.
+func (r *RayClusterReconciler) verifyGangScheduling(ctx context.Context, cluster *rayv1.RayCluster, suspended bool) (time.Duration, error) {
+	cfg := r.gangSchedulingVerification()
+	if cfg == nil || !ptr.Deref(cfg.Enabled, false) || !isGangScheduled(cluster) {
+		return 0, nil
+	}
+
+	conditions, err := rayClusterConditions(cluster)
+	if err != nil {
+		return 0, err
+	}
+	if suspended {
+		if meta.RemoveStatusCondition(&conditions, PartiallyScheduledCondition) {
+			return 0, r.patchRayClusterConditions(ctx, cluster, conditions)
+		}
+		return 0, nil
+	}
+	current := meta.FindStatusCondition(conditions, PartiallyScheduledCondition)
+	if current != nil && current.Status == metav1.ConditionFalse {
+		return 0, nil
+	}
+
+	pods, err := r.kubeClient.CoreV1().Pods(cluster.Namespace).List(ctx, metav1.ListOptions{LabelSelector: "ray.io/cluster=" + cluster.Name})
+	if err != nil {
+		return 0, err
+	}
+	expected := expectedPodCount(cluster)
+	scheduled := scheduledPodCount(pods.Items)
+
+	condition := metav1.Condition{
+		Type:               PartiallyScheduledCondition,
+		Status:             metav1.ConditionFalse,
+		Reason:             "AllPodsScheduled",
+		Message:            fmt.Sprintf("All the %d pods of the RayCluster have been scheduled", expected),
+		ObservedGeneration: cluster.Generation,
+	}
+	if scheduled < expected {
+		timeout := defaultGangSchedulingTimeout
+		if cfg.Timeout != nil {
+			timeout = cfg.Timeout.Duration
+		}
+		if wait := time.Until(admissionTime(cluster, conditions).Add(timeout)); wait > 0 {
+			return min(wait, gangSchedulingRecheckInterval), nil
+		}
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "PodsUnscheduled"
+		condition.Message = fmt.Sprintf("Only %d of the %d pods of the RayCluster have been scheduled within %s", scheduled, expected, timeout)
+	}
+	if meta.SetStatusCondition(&conditions, condition) {
+		if err := r.patchRayClusterConditions(ctx, cluster, conditions); err != nil {
+			return 0, err
+		}
+	}
+	if condition.Status == metav1.ConditionFalse {
+		return 0, nil
+	}
+
+	if (current == nil || current.Status != metav1.ConditionTrue) && ptr.Deref(cfg.ResetAppWrapper, false) {
+		if err := r.resetAppWrapper(ctx, cluster); err != nil {
+			return 0, err
+		}
+	}
+	return gangSchedulingRecheckInterval, nil
+}
+
+func (r *RayClusterReconciler) gangSchedulingVerification() *config.GangSchedulingVerificationConfiguration {
+	if r.Config == nil {
+		return nil
+	}
+	return r.Config.GangSchedulingVerification
+}
+
+// resetAppWrapper lowers the admission and warmup grace periods of the AppWrapper that owns the RayCluster, if it's
+// running, to the time elapsed since it deployed the RayCluster, so that the AppWrapper controller finds them expired
+// and resets the AppWrapper, releasing its resources and re-deploying the RayCluster after the retry pause, or fails
+// it once its retry limit is reached. The grace periods, already shorter, are left unchanged.
+func (r *RayClusterReconciler) resetAppWrapper(ctx context.Context, cluster *rayv1.RayCluster) error {
+	logger := ctrl.LoggerFrom(ctx)
+
+	ownerRef := appWrapperOwner(cluster)
+	if ownerRef == nil {
+		return nil
+	}
+	aw := &awv1beta2.AppWrapper{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: cluster.Namespace, Name: ownerRef.Name}, aw); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if aw.UID != ownerRef.UID || aw.Status.Phase != awv1beta2.AppWrapperRunning {
+		return nil
+	}
+	deployed := meta.FindStatusCondition(aw.Status.Conditions, string(awv1beta2.ResourcesDeployed))
+	if deployed == nil || deployed.Status != metav1.ConditionTrue {
+		return nil
+	}
+
+	patch := client.MergeFrom(aw.DeepCopy())
+	if !lowerAppWrapperGracePeriods(aw, time.Since(deployed.LastTransitionTime.Time).Truncate(time.Second)) {
+		return nil
+	}
+	if err := r.Patch(ctx, aw, patch); err != nil {
+		return err
+	}
+	logger.Info("Lowered grace periods of AppWrapper of partially scheduled RayCluster", "appwrapper", aw.Name)
+	return nil
+}
+
+// lowerAppWrapperGracePeriods sets the admission and warmup grace period annotations of the AppWrapper to the given
+// period, unless they are already shorter. It returns whether any annotation has been changed.
+func lowerAppWrapperGracePeriods(aw *awv1beta2.AppWrapper, period time.Duration) bool {
+	changed := false
+	for _, annotation := range []string{awv1beta2.AdmissionGracePeriodDurationAnnotation, awv1beta2.WarmupGracePeriodDurationAnnotation} {
+		if value, ok := aw.Annotations[annotation]; ok {
+			if current, err := time.ParseDuration(value); err == nil && current <= period {
+				continue
+			}
+		}
+		if aw.Annotations == nil {
+			aw.Annotations = map[string]string{}
+		}
+		aw.Annotations[annotation] = period.String()
+		changed = true
+	}
+	return changed
+}
+
+// isGangScheduled returns whether the RayCluster is admitted as a whole, either by Kueue or as part of an AppWrapper.
+func isGangScheduled(cluster *rayv1.RayCluster) bool {
+	_, queued := cluster.Labels[kueueconstants.QueueLabel]
+	return queued || appWrapperOwner(cluster) != nil
+}
+
+func appWrapperOwner(cluster *rayv1.RayCluster) *metav1.OwnerReference {
+	ownerRef := metav1.GetControllerOf(cluster)
+	if ownerRef == nil || ownerRef.APIVersion != awv1beta2.GroupVersion.String() || ownerRef.Kind != "AppWrapper" {
+		return nil
+	}
+	return ownerRef
+}
+
+// admissionTime returns the time the RayCluster was last resumed, or created if it has never been suspended,
+// e.g., when it's deployed by an AppWrapper once admitted.
+func admissionTime(cluster *rayv1.RayCluster, conditions []metav1.Condition) time.Time {
+	if suspended := meta.FindStatusCondition(conditions, SuspendedCondition); suspended != nil && suspended.Status == metav1.ConditionFalse {
+		return suspended.LastTransitionTime.Time
+	}
+	return cluster.CreationTimestamp.Time
+}
+
+// expectedPodCount returns the number of pods of the RayCluster, that is the head pod and the pods of the worker groups.
+func expectedPodCount(cluster *rayv1.RayCluster) int32 {
+	count := int32(1)
+	for _, workerGroup := range cluster.Spec.WorkerGroupSpecs {
+		count += ptr.Deref(workerGroup.Replicas, 0) * max(workerGroup.NumOfHosts, 1)
+	}
+	return count
+}
+
+// scheduledPodCount returns the number of the pods, not being deleted, that have been bound to a node.
+func scheduledPodCount(pods []corev1.Pod) int32 {
+	var count int32
+	for _, pod := range pods {
+		if !pod.DeletionTimestamp.IsZero() {
+			continue
+		}
+		for _, condition := range pod.Status.Conditions {
+			if condition.Type == corev1.PodScheduled && condition.Status == corev1.ConditionTrue {
+				count++
+				break
+			}
+		}
+	}
+	return count
+}
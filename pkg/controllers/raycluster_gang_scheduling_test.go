@@ -0,0 +1,139 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	awv1beta2 "github.com/project-codeflare/appwrapper/api/v1beta2"
+	"github.com/project-codeflare/codeflare-common/support"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	kueueconstants "sigs.k8s.io/kueue/pkg/controller/constants"
+)
+
+func TestGangSchedulingPodCounts(t *testing.T) {
+	test := support.NewTest(t)
+
+	cluster := &rayv1.RayCluster{
+		Spec: rayv1.RayClusterSpec{
+			WorkerGroupSpecs: []rayv1.WorkerGroupSpec{
+				{Replicas: ptr.To(int32(2))},
+				{Replicas: ptr.To(int32(2)), NumOfHosts: 4},
+			},
+		},
+	}
+	scheduledPod := func() corev1.Pod {
+		return corev1.Pod{Status: corev1.PodStatus{Conditions: []corev1.PodCondition{
+			{Type: corev1.PodScheduled, Status: corev1.ConditionTrue},
+		}}}
+	}
+	deletedPod := scheduledPod()
+	deletedPod.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	pendingPod := corev1.Pod{Status: corev1.PodStatus{Conditions: []corev1.PodCondition{
+		{Type: corev1.PodScheduled, Status: corev1.ConditionFalse, Reason: corev1.PodReasonUnschedulable},
+	}}}
+
+	test.T().Run("Expected head pod and worker pods for each host of each replica", func(t *testing.T) {
+		test.Expect(expectedPodCount(cluster)).To(Equal(int32(11)))
+	})
+
+	test.T().Run("Expected only bound pods not being deleted to be counted as scheduled", func(t *testing.T) {
+		pods := []corev1.Pod{scheduledPod(), scheduledPod(), deletedPod, pendingPod, {}}
+		test.Expect(scheduledPodCount(pods)).To(Equal(int32(2)))
+	})
+}
+
+func TestGangSchedulingAdmission(t *testing.T) {
+	test := support.NewTest(t)
+
+	created := metav1.NewTime(time.Now().Add(-time.Hour).Truncate(time.Second))
+	resumed := metav1.NewTime(time.Now().Add(-time.Minute).Truncate(time.Second))
+
+	test.T().Run("Expected RayClusters queued with Kueue or owned by AppWrappers to be gang scheduled", func(t *testing.T) {
+		queued := &rayv1.RayCluster{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{kueueconstants.QueueLabel: "queue"}}}
+		test.Expect(isGangScheduled(queued)).To(BeTrue())
+
+		wrapped := &rayv1.RayCluster{ObjectMeta: metav1.ObjectMeta{OwnerReferences: []metav1.OwnerReference{{
+			APIVersion: awv1beta2.GroupVersion.String(),
+			Kind:       "AppWrapper",
+			Name:       "appwrapper",
+			Controller: ptr.To(true),
+		}}}}
+		test.Expect(isGangScheduled(wrapped)).To(BeTrue())
+		test.Expect(appWrapperOwner(wrapped).Name).To(Equal("appwrapper"))
+	})
+
+	test.T().Run("Negative: RayClusters neither queued nor owned by AppWrappers are not gang scheduled", func(t *testing.T) {
+		owned := &rayv1.RayCluster{ObjectMeta: metav1.ObjectMeta{OwnerReferences: []metav1.OwnerReference{{
+			APIVersion: rayv1.GroupVersion.String(),
+			Kind:       "RayJob",
+			Name:       "rayjob",
+			Controller: ptr.To(true),
+		}}}}
+		test.Expect(isGangScheduled(owned)).To(BeFalse())
+		test.Expect(appWrapperOwner(owned)).To(BeNil())
+	})
+
+	test.T().Run("Expected admission time to be the last resumption time", func(t *testing.T) {
+		cluster := &rayv1.RayCluster{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: created}}
+		conditions := []metav1.Condition{{Type: SuspendedCondition, Status: metav1.ConditionFalse, LastTransitionTime: resumed}}
+		test.Expect(admissionTime(cluster, conditions)).To(Equal(resumed.Time))
+	})
+
+	test.T().Run("Expected admission time to be the creation time of RayClusters never suspended", func(t *testing.T) {
+		cluster := &rayv1.RayCluster{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: created}}
+		test.Expect(admissionTime(cluster, nil)).To(Equal(created.Time))
+	})
+}
+
+func TestLowerAppWrapperGracePeriods(t *testing.T) {
+	test := support.NewTest(t)
+
+	test.T().Run("Expected grace periods to be set to the elapsed period", func(t *testing.T) {
+		aw := &awv1beta2.AppWrapper{}
+		test.Expect(lowerAppWrapperGracePeriods(aw, 5*time.Minute)).To(BeTrue())
+		test.Expect(aw.Annotations).To(Equal(map[string]string{
+			awv1beta2.AdmissionGracePeriodDurationAnnotation: "5m0s",
+			awv1beta2.WarmupGracePeriodDurationAnnotation:    "5m0s",
+		}))
+	})
+
+	test.T().Run("Expected longer or malformed grace periods to be lowered", func(t *testing.T) {
+		aw := &awv1beta2.AppWrapper{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+			awv1beta2.AdmissionGracePeriodDurationAnnotation: "1h",
+			awv1beta2.WarmupGracePeriodDurationAnnotation:    "soon",
+		}}}
+		test.Expect(lowerAppWrapperGracePeriods(aw, 5*time.Minute)).To(BeTrue())
+		test.Expect(aw.Annotations).To(HaveKeyWithValue(awv1beta2.AdmissionGracePeriodDurationAnnotation, "5m0s"))
+		test.Expect(aw.Annotations).To(HaveKeyWithValue(awv1beta2.WarmupGracePeriodDurationAnnotation, "5m0s"))
+	})
+
+	test.T().Run("Negative: shorter grace periods are left unchanged", func(t *testing.T) {
+		aw := &awv1beta2.AppWrapper{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+			awv1beta2.AdmissionGracePeriodDurationAnnotation: "1m",
+			awv1beta2.WarmupGracePeriodDurationAnnotation:    "5m",
+		}}}
+		test.Expect(lowerAppWrapperGracePeriods(aw, 5*time.Minute)).To(BeFalse())
+		test.Expect(aw.Annotations).To(HaveKeyWithValue(awv1beta2.AdmissionGracePeriodDurationAnnotation, "1m"))
+	})
+}
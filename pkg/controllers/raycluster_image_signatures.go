@@ -0,0 +1,207 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/project-codeflare/codeflare-operator/pkg/cache"
+	"github.com/project-codeflare/codeflare-operator/pkg/cosign"
+)
+
+const (
+	// imageSignaturePolicy rejects the RayClusters whose Ray images aren't signed
+	imageSignaturePolicy = "ImageSignature"
+
+	// imageSignatureTTL is how long the verification results of the images are cached for by the webhook,
+	// so that the registries aren't requested on each admission, and re-signed images are eventually admitted.
+	imageSignatureTTL = 10 * time.Minute
+)
+
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get
+
+// imageSignatureKey identifies the verification of an image, with the image pull secrets of a namespace.
+type imageSignatureKey struct {
+	namespace string
+	// pullSecrets are the comma-separated names of the image pull secrets
+	pullSecrets string
+	image       string
+}
+
+// imageSignature is the verification result of an image, i.e., the digest of its verified manifest,
+// or the VerificationError when it's not verified.
+type imageSignature struct {
+	digest string
+	err    *cosign.VerificationError
+}
+
+// newImageSignatureCache returns a cache of the verification results of the images.
+// The errors requesting the registries aren't cached.
+func newImageSignatureCache(reader client.Reader, verifier *cosign.Verifier) *cache.Cache[imageSignatureKey, imageSignature] {
+	return cache.New("image-signatures", imageSignatureTTL,
+		func(ctx context.Context, key imageSignatureKey) (imageSignature, error) {
+			var secrets []corev1.Secret
+			for _, name := range strings.Split(key.pullSecrets, ",") {
+				secret := &corev1.Secret{}
+				if name == "" {
+					continue
+				} else if err := reader.Get(ctx, client.ObjectKey{Namespace: key.namespace, Name: name}, secret); err != nil {
+					if client.IgnoreNotFound(err) != nil {
+						return imageSignature{}, err
+					}
+					continue
+				}
+				secrets = append(secrets, *secret)
+			}
+			digest, err := verifier.Verify(ctx, key.image, cosign.PullSecretKeychain(secrets))
+			var verificationErr *cosign.VerificationError
+			if errors.As(err, &verificationErr) {
+				return imageSignature{err: verificationErr}, nil
+			} else if err != nil {
+				return imageSignature{}, err
+			}
+			return imageSignature{digest: digest.String()}, nil
+		})
+}
+
+// pinImageSignatures references the Ray images of the RayCluster, that are verified, by the digest of their verified
+// manifest, so that the tags of the images cannot be moved to other manifests, once they are verified. The images
+// that cannot be verified are left unchanged, and rejected by the validation.
+func (w *rayClusterWebhook) pinImageSignatures(ctx context.Context, rayCluster *rayv1.RayCluster) {
+	if w.ImageSignatures == nil {
+		return
+	}
+
+	for _, image := range rayImages(rayCluster) {
+		if isPinned(image.container.Image) {
+			continue
+		}
+		signature, err := w.getImageSignature(ctx, rayCluster, image.pullSecrets, image.container.Image)
+		if err != nil || signature.err != nil {
+			continue
+		}
+		image.container.Image = image.container.Image + "@" + signature.digest
+	}
+}
+
+// appendImageSignatures rejects the RayCluster whose Ray images, that aren't already run by the old RayCluster,
+// if any, are not signed, or not referenced by the digest of their verified manifest, according to the admission
+// policy mode.
+func (w *rayClusterWebhook) appendImageSignatures(ctx context.Context, warnings admission.Warnings, allErrors field.ErrorList,
+	rayCluster, oldRayCluster *rayv1.RayCluster) (admission.Warnings, field.ErrorList) {
+	if w.ImageSignatures == nil {
+		return warnings, allErrors
+	}
+
+	var violations field.ErrorList
+	previous := map[string]bool{}
+	if oldRayCluster != nil {
+		for _, image := range rayImages(oldRayCluster) {
+			previous[image.container.Image] = true
+		}
+	}
+	verified := map[string]error{}
+	for _, image := range rayImages(rayCluster) {
+		if previous[image.container.Image] {
+			continue
+		}
+		err, ok := verified[image.container.Image]
+		if !ok {
+			err = w.verifyImageSignature(ctx, rayCluster, image.pullSecrets, image.container.Image)
+			verified[image.container.Image] = err
+		}
+		if err != nil {
+			violations = append(violations, field.Invalid(image.path, image.container.Image, err.Error()))
+		}
+	}
+	return appendPolicyViolations(warnings, allErrors, rayCluster, w.Config, imageSignaturePolicy, violations)
+}
+
+func (w *rayClusterWebhook) verifyImageSignature(ctx context.Context, rayCluster *rayv1.RayCluster, pullSecrets []corev1.LocalObjectReference, image string) error {
+	signature, err := w.getImageSignature(ctx, rayCluster, pullSecrets, image)
+	if err != nil {
+		// The images are rejected when they cannot be verified, e.g., when their registry is unavailable
+		return fmt.Errorf("cannot verify signature: %w", err)
+	}
+	if signature.err != nil {
+		return signature.err
+	}
+	if !isPinned(image) {
+		// The images are pinned on creation, so that the manifests that are run are the ones that are verified
+		return fmt.Errorf("image %s is not referenced by the digest %s of its verified manifest", image, signature.digest)
+	}
+	return nil
+}
+
+func (w *rayClusterWebhook) getImageSignature(ctx context.Context, rayCluster *rayv1.RayCluster, pullSecrets []corev1.LocalObjectReference, image string) (imageSignature, error) {
+	names := make([]string, 0, len(pullSecrets))
+	for _, secret := range pullSecrets {
+		names = append(names, secret.Name)
+	}
+	return w.ImageSignatures.Get(ctx, imageSignatureKey{
+		namespace:   rayCluster.Namespace,
+		pullSecrets: strings.Join(names, ","),
+		image:       image,
+	})
+}
+
+// isPinned returns whether the image is referenced by digest, e.g., quay.io/modh/ray:2.35.0@sha256:<digest>.
+func isPinned(image string) bool {
+	_, err := name.NewDigest(image)
+	return err == nil
+}
+
+type rayImage struct {
+	path        *field.Path
+	container   *corev1.Container
+	pullSecrets []corev1.LocalObjectReference
+}
+
+// rayImages returns the images of the Ray containers, i.e., the first containers, of the head and worker groups.
+func rayImages(rayCluster *rayv1.RayCluster) []rayImage {
+	var images []rayImage
+	if spec := &rayCluster.Spec.HeadGroupSpec.Template.Spec; len(spec.Containers) > 0 {
+		images = append(images, rayImage{
+			path:        field.NewPath("spec", "headGroupSpec", "template", "spec", "containers", strconv.Itoa(0), "image"),
+			container:   &spec.Containers[0],
+			pullSecrets: spec.ImagePullSecrets,
+		})
+	}
+	for i := range rayCluster.Spec.WorkerGroupSpecs {
+		if spec := &rayCluster.Spec.WorkerGroupSpecs[i].Template.Spec; len(spec.Containers) > 0 {
+			images = append(images, rayImage{
+				path:        field.NewPath("spec", "workerGroupSpecs", strconv.Itoa(i), "template", "spec", "containers", strconv.Itoa(0), "image"),
+				container:   &spec.Containers[0],
+				pullSecrets: spec.ImagePullSecrets,
+			})
+		}
+	}
+	return images
+}
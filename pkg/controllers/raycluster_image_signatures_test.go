@@ -0,0 +1,172 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/project-codeflare/codeflare-common/support"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/project-codeflare/codeflare-operator/pkg/cache"
+	"github.com/project-codeflare/codeflare-operator/pkg/config"
+	"github.com/project-codeflare/codeflare-operator/pkg/cosign"
+)
+
+func TestValidateImageSignatures(t *testing.T) {
+	test := support.NewTest(t)
+
+	const (
+		signedImage   = "quay.io/modh/ray:2.35.0-py311-cu121"
+		unsignedImage = "quay.io/someone/ray:2.35.0-py311-cu121"
+		digest        = "sha256:0d2a55e0d6ad7bb9e8ff0a04be4d3d8ce4c77f8b14a3fa4a28d57a8e4f6b8d3c"
+		pinnedImage   = signedImage + "@" + digest
+	)
+
+	rayCluster := &rayv1.RayCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      rayClusterName,
+			Namespace: namespace,
+		},
+		Spec: rayv1.RayClusterSpec{
+			HeadGroupSpec: rayv1.HeadGroupSpec{
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Containers:       []corev1.Container{{Name: "ray-head", Image: signedImage}},
+						ImagePullSecrets: []corev1.LocalObjectReference{{Name: "quay"}},
+					},
+				},
+				RayStartParams: map[string]string{},
+			},
+			WorkerGroupSpecs: []rayv1.WorkerGroupSpec{
+				{
+					GroupName: "worker-group-1",
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{{Name: "ray-worker", Image: signedImage}},
+						},
+					},
+					RayStartParams: map[string]string{},
+				},
+			},
+		},
+	}
+	unsignedRayCluster := rayCluster.DeepCopy()
+	unsignedRayCluster.Spec.HeadGroupSpec.Template.Spec.Containers[0].Image = pinnedImage
+	unsignedRayCluster.Spec.WorkerGroupSpecs[0].Template.Spec.Containers[0].Image = unsignedImage
+
+	// newWebhook returns the webhook whose verifications of the images are stubbed, and the verified keys
+	newWebhook := func(mode config.AdmissionPolicyModeType, registryErr error) (*rayClusterWebhook, *[]imageSignatureKey) {
+		var verified []imageSignatureKey
+		return &rayClusterWebhook{
+			Config: &config.KubeRayConfiguration{
+				RayDashboardOAuthEnabled: support.Ptr(false),
+				MTLSEnabled:              support.Ptr(false),
+				AdmissionPolicyMode:      mode,
+			},
+			ImageSignatures: cache.New("test-image-signatures", imageSignatureTTL,
+				func(_ context.Context, key imageSignatureKey) (imageSignature, error) {
+					verified = append(verified, key)
+					if registryErr != nil {
+						return imageSignature{}, registryErr
+					}
+					if key.image == unsignedImage {
+						return imageSignature{err: &cosign.VerificationError{Image: key.image, Reason: "no signature found"}}, nil
+					}
+					return imageSignature{digest: digest}, nil
+				}),
+		}, &verified
+	}
+
+	t.Run("Expected RayCluster with signed Ray images to be pinned, and admitted, verifying each image once", func(t *testing.T) {
+		webhook, verified := newWebhook("", nil)
+		pinnedRayCluster := rayCluster.DeepCopy()
+
+		test.Expect(webhook.Default(test.Ctx(), runtime.Object(pinnedRayCluster))).To(Succeed())
+		test.Expect(pinnedRayCluster.Spec.HeadGroupSpec.Template.Spec.Containers[0].Image).To(Equal(pinnedImage))
+		test.Expect(pinnedRayCluster.Spec.WorkerGroupSpecs[0].Template.Spec.Containers[0].Image).To(Equal(pinnedImage))
+
+		_, err := webhook.ValidateCreate(test.Ctx(), runtime.Object(pinnedRayCluster))
+		test.Expect(err).ShouldNot(HaveOccurred())
+		test.Expect(*verified).To(ConsistOf(
+			imageSignatureKey{namespace: namespace, pullSecrets: "quay", image: signedImage},
+			imageSignatureKey{namespace: namespace, image: signedImage},
+			imageSignatureKey{namespace: namespace, pullSecrets: "quay", image: pinnedImage},
+		))
+	})
+
+	t.Run("Negative: Expected RayCluster with signed Ray images not referenced by digest to be rejected", func(t *testing.T) {
+		webhook, _ := newWebhook("", nil)
+
+		_, err := webhook.ValidateCreate(test.Ctx(), runtime.Object(rayCluster))
+		test.Expect(err).To(MatchError(ContainSubstring(
+			"image " + signedImage + " is not referenced by the digest " + digest + " of its verified manifest")))
+	})
+
+	t.Run("Expected RayCluster with unsigned Ray images not to be pinned", func(t *testing.T) {
+		webhook, _ := newWebhook("", nil)
+		pinnedRayCluster := unsignedRayCluster.DeepCopy()
+
+		test.Expect(webhook.Default(test.Ctx(), runtime.Object(pinnedRayCluster))).To(Succeed())
+		test.Expect(pinnedRayCluster.Spec.HeadGroupSpec.Template.Spec.Containers[0].Image).To(Equal(pinnedImage))
+		test.Expect(pinnedRayCluster.Spec.WorkerGroupSpecs[0].Template.Spec.Containers[0].Image).To(Equal(unsignedImage))
+	})
+
+	t.Run("Negative: Expected RayCluster with unsigned Ray images to be rejected", func(t *testing.T) {
+		webhook, _ := newWebhook("", nil)
+
+		_, err := webhook.ValidateCreate(test.Ctx(), runtime.Object(unsignedRayCluster))
+		test.Expect(err).To(MatchError(ContainSubstring(
+			"spec.workerGroupSpecs.0.template.spec.containers.0.image: Invalid value: \"" + unsignedImage + "\": image " + unsignedImage + " is not verified: no signature found")))
+	})
+
+	t.Run("Negative: Expected RayCluster to be rejected when its Ray images cannot be verified", func(t *testing.T) {
+		webhook, _ := newWebhook("", errors.New("registry unavailable"))
+
+		_, err := webhook.ValidateCreate(test.Ctx(), runtime.Object(rayCluster))
+		test.Expect(err).To(MatchError(ContainSubstring("cannot verify signature: registry unavailable")))
+	})
+
+	t.Run("Expected RayCluster with unsigned Ray images to be admitted with warnings in audit mode", func(t *testing.T) {
+		webhook, _ := newWebhook(config.AuditAdmissionPolicyMode, nil)
+
+		warnings, err := webhook.ValidateCreate(test.Ctx(), runtime.Object(unsignedRayCluster))
+		test.Expect(err).ShouldNot(HaveOccurred())
+		test.Expect(warnings).To(ConsistOf(ContainSubstring("Audit: ")))
+	})
+
+	t.Run("Expected only the new Ray images to be verified on update", func(t *testing.T) {
+		webhook, verified := newWebhook("", nil)
+
+		_, err := webhook.ValidateUpdate(test.Ctx(), runtime.Object(unsignedRayCluster), runtime.Object(unsignedRayCluster))
+		test.Expect(err).ShouldNot(HaveOccurred())
+		test.Expect(*verified).To(BeEmpty())
+
+		pinnedRayCluster := unsignedRayCluster.DeepCopy()
+		pinnedRayCluster.Spec.WorkerGroupSpecs[0].Template.Spec.Containers[0].Image = pinnedImage
+		_, err = webhook.ValidateUpdate(test.Ctx(), runtime.Object(pinnedRayCluster), runtime.Object(unsignedRayCluster))
+		test.Expect(err).Should(HaveOccurred())
+		test.Expect(*verified).To(ConsistOf(imageSignatureKey{namespace: namespace, image: unsignedImage}))
+	})
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"slices"
+
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	metav1ac "k8s.io/client-go/applyconfigurations/meta/v1"
+	networkingv1ac "k8s.io/client-go/applyconfigurations/networking/v1"
+
+	"github.com/project-codeflare/codeflare-operator/pkg/config"
+)
+
+// notebookNamespacesIngressRule returns the rule of the head NetworkPolicy that allows the Ray client, and dashboard,
+// traffic from the configured notebook namespaces, so that notebooks can connect to the RayClusters of other namespaces,
+// or nil when no notebook namespace is configured.
+func notebookNamespacesIngressRule(cfg *config.KubeRayConfiguration) *networkingv1ac.NetworkPolicyIngressRuleApplyConfiguration {
+	if cfg == nil || cfg.NotebookNamespaces == nil {
+		return nil
+	}
+
+	var peers []*networkingv1ac.NetworkPolicyPeerApplyConfiguration
+	if names := cfg.NotebookNamespaces.Names; len(names) > 0 {
+		peers = append(peers, networkingv1ac.NetworkPolicyPeer().WithNamespaceSelector(metav1ac.LabelSelector().
+			WithMatchExpressions(metav1ac.LabelSelectorRequirement().
+				WithKey(corev1.LabelMetadataName).
+				WithOperator(metav1.LabelSelectorOpIn).
+				WithValues(names...))))
+	}
+	if selector := cfg.NotebookNamespaces.Selector; selector != nil {
+		peers = append(peers, networkingv1ac.NetworkPolicyPeer().WithNamespaceSelector(labelSelectorApplyConfiguration(selector)))
+	}
+	if len(peers) == 0 {
+		return nil
+	}
+
+	return networkingv1ac.NetworkPolicyIngressRule().
+		WithPorts(
+			networkingv1ac.NetworkPolicyPort().WithProtocol(corev1.ProtocolTCP).WithPort(intstr.FromInt(10001)),
+			networkingv1ac.NetworkPolicyPort().WithProtocol(corev1.ProtocolTCP).WithPort(intstr.FromInt(8265)),
+		).
+		WithFrom(peers...)
+}
+
+// sharedClusterIngressRule returns the rule of the head NetworkPolicy that allows the Ray client, and dashboard,
+// traffic from all the namespaces, when the RayCluster is in one of the shared cluster namespaces, or nil otherwise.
+func sharedClusterIngressRule(cluster *rayv1.RayCluster, cfg *config.KubeRayConfiguration) *networkingv1ac.NetworkPolicyIngressRuleApplyConfiguration {
+	if cfg == nil || !slices.Contains(cfg.SharedClusterNamespaces, cluster.Namespace) {
+		return nil
+	}
+
+	return networkingv1ac.NetworkPolicyIngressRule().
+		WithPorts(
+			networkingv1ac.NetworkPolicyPort().WithProtocol(corev1.ProtocolTCP).WithPort(intstr.FromInt(10001)),
+			networkingv1ac.NetworkPolicyPort().WithProtocol(corev1.ProtocolTCP).WithPort(intstr.FromInt(8265)),
+		).
+		WithFrom(networkingv1ac.NetworkPolicyPeer().WithNamespaceSelector(metav1ac.LabelSelector()))
+}
+
+func labelSelectorApplyConfiguration(selector *metav1.LabelSelector) *metav1ac.LabelSelectorApplyConfiguration {
+	ac := metav1ac.LabelSelector()
+	if len(selector.MatchLabels) > 0 {
+		ac.WithMatchLabels(selector.MatchLabels)
+	}
+	for _, requirement := range selector.MatchExpressions {
+		ac.WithMatchExpressions(metav1ac.LabelSelectorRequirement().
+			WithKey(requirement.Key).
+			WithOperator(requirement.Operator).
+			WithValues(requirement.Values...))
+	}
+	return ac
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/project-codeflare/codeflare-common/support"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+
+	"github.com/project-codeflare/codeflare-operator/pkg/config"
+)
+
+func TestNotebookNamespacesIngressRule(t *testing.T) {
+	test := support.NewTest(t)
+
+	cluster := &rayv1.RayCluster{ObjectMeta: metav1.ObjectMeta{Name: "raycluster", Namespace: "ns"}}
+
+	test.T().Run("Expected Ray client and dashboard traffic to be allowed from the notebook namespaces", func(t *testing.T) {
+		cfg := &config.KubeRayConfiguration{NotebookNamespaces: &config.NotebookNamespacesConfiguration{
+			Names: []string{"workbenches"},
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"opendatahub.io/dashboard": "true"},
+				MatchExpressions: []metav1.LabelSelectorRequirement{
+					{Key: "team", Operator: metav1.LabelSelectorOpIn, Values: []string{"ml"}},
+				},
+			},
+		}}
+
+		policy := desiredHeadNetworkPolicy(cluster, cfg, []string{"opendatahub"})
+		rule := policy.Spec.Ingress[len(policy.Spec.Ingress)-1]
+
+		test.Expect(rule.Ports).To(HaveLen(2))
+		test.Expect(*rule.Ports[0].Port).To(Equal(intstr.FromInt(10001)))
+		test.Expect(*rule.Ports[1].Port).To(Equal(intstr.FromInt(8265)))
+		test.Expect(rule.From).To(HaveLen(2))
+		test.Expect(rule.From[0].PodSelector).To(BeNil())
+		test.Expect(rule.From[0].NamespaceSelector.MatchExpressions).To(ConsistOf(And(
+			HaveField("Key", HaveValue(Equal(corev1.LabelMetadataName))),
+			HaveField("Values", ConsistOf("workbenches")),
+		)))
+		test.Expect(rule.From[1].NamespaceSelector.MatchLabels).To(Equal(map[string]string{"opendatahub.io/dashboard": "true"}))
+		test.Expect(rule.From[1].NamespaceSelector.MatchExpressions).To(ConsistOf(And(
+			HaveField("Key", HaveValue(Equal("team"))),
+			HaveField("Operator", HaveValue(Equal(metav1.LabelSelectorOpIn))),
+			HaveField("Values", ConsistOf("ml")),
+		)))
+	})
+
+	test.T().Run("Negative: Expected no rule without notebook namespaces", func(t *testing.T) {
+		cfg := &config.KubeRayConfiguration{MTLSEnabled: ptr.To(false)}
+		test.Expect(notebookNamespacesIngressRule(cfg)).To(BeNil())
+
+		cfg.NotebookNamespaces = &config.NotebookNamespacesConfiguration{}
+		test.Expect(notebookNamespacesIngressRule(cfg)).To(BeNil())
+		test.Expect(desiredHeadNetworkPolicy(cluster, cfg, []string{"opendatahub"}).Spec.Ingress).To(HaveLen(5))
+	})
+}
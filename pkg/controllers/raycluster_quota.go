@@ -0,0 +1,188 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	kueueconstants "sigs.k8s.io/kueue/pkg/controller/constants"
+
+	"github.com/project-codeflare/codeflare-operator/pkg/cache"
+)
+
+// queueCapacityTTL is how long the capacity of the ClusterQueues is cached for by the webhook.
+const queueCapacityTTL = 30 * time.Second
+
+// queueCapacity is the maximum amount of the resources a Workload can be admitted with by the ClusterQueue
+// of a LocalQueue, i.e., the sum of their nominal quotas across the flavors, plus their borrowing limits
+// when the ClusterQueue is in a cohort. The resources that can be borrowed without limit aren't included.
+type queueCapacity struct {
+	clusterQueue string
+	resources    corev1.ResourceList
+}
+
+func newQueueCapacityCache(reader client.Reader) *cache.Cache[types.NamespacedName, *queueCapacity] {
+	return cache.New("queue-capacity", queueCapacityTTL,
+		func(ctx context.Context, key types.NamespacedName) (*queueCapacity, error) {
+			localQueue := &kueue.LocalQueue{}
+			if err := reader.Get(ctx, key, localQueue); err != nil {
+				return nil, err
+			}
+			clusterQueue := &kueue.ClusterQueue{}
+			if err := reader.Get(ctx, client.ObjectKey{Name: string(localQueue.Spec.ClusterQueue)}, clusterQueue); err != nil {
+				return nil, err
+			}
+			return clusterQueueCapacity(clusterQueue), nil
+		})
+}
+
+func clusterQueueCapacity(clusterQueue *kueue.ClusterQueue) *queueCapacity {
+	capacity := &queueCapacity{clusterQueue: clusterQueue.Name, resources: corev1.ResourceList{}}
+	unbounded := map[corev1.ResourceName]bool{}
+	for _, group := range clusterQueue.Spec.ResourceGroups {
+		for _, flavor := range group.Flavors {
+			for _, quota := range flavor.Resources {
+				if clusterQueue.Spec.Cohort != "" && quota.BorrowingLimit == nil {
+					unbounded[quota.Name] = true
+					continue
+				}
+				total := capacity.resources[quota.Name]
+				total.Add(quota.NominalQuota)
+				if clusterQueue.Spec.Cohort != "" {
+					total.Add(*quota.BorrowingLimit)
+				}
+				capacity.resources[quota.Name] = total
+			}
+		}
+	}
+	for name := range unbounded {
+		delete(capacity.resources, name)
+	}
+	return capacity
+}
+
+// podRequests returns the resources requested by a pod, as computed by Kueue, i.e., the maximum of the sum of
+// the requests of its containers and of the requests of each of its init containers, plus its overhead.
+// The limits of the containers are used for the resources they don't request.
+func podRequests(spec *corev1.PodSpec) corev1.ResourceList {
+	containerRequests := func(container *corev1.Container) corev1.ResourceList {
+		requests := container.Resources.Requests.DeepCopy()
+		if requests == nil {
+			requests = corev1.ResourceList{}
+		}
+		for name, limit := range container.Resources.Limits {
+			if _, ok := requests[name]; !ok {
+				requests[name] = limit.DeepCopy()
+			}
+		}
+		return requests
+	}
+
+	requests := corev1.ResourceList{}
+	for i := range spec.Containers {
+		addResources(requests, containerRequests(&spec.Containers[i]), 1)
+	}
+	for i := range spec.InitContainers {
+		for name, quantity := range containerRequests(&spec.InitContainers[i]) {
+			if current, ok := requests[name]; !ok || quantity.Cmp(current) > 0 {
+				requests[name] = quantity
+			}
+		}
+	}
+	addResources(requests, spec.Overhead, 1)
+	return requests
+}
+
+func addResources(total, resources corev1.ResourceList, count int64) {
+	for name, quantity := range resources {
+		sum := total[name]
+		for i := int64(0); i < count; i++ {
+			sum.Add(quantity)
+		}
+		total[name] = sum
+	}
+}
+
+// rayClusterRequests returns the aggregate resources requested by the pods of the RayCluster.
+func rayClusterRequests(rayCluster *rayv1.RayCluster) corev1.ResourceList {
+	requests := podRequests(&rayCluster.Spec.HeadGroupSpec.Template.Spec)
+	for i := range rayCluster.Spec.WorkerGroupSpecs {
+		workerGroup := &rayCluster.Spec.WorkerGroupSpecs[i]
+		count := int64(ptr.Deref(workerGroup.Replicas, ptr.Deref(workerGroup.MinReplicas, 0)))
+		if workerGroup.NumOfHosts > 1 {
+			count *= int64(workerGroup.NumOfHosts)
+		}
+		addResources(requests, podRequests(&workerGroup.Template.Spec), count)
+	}
+	return requests
+}
+
+// quotaWarnings returns a warning for each resource the RayCluster requests more of, in aggregate, than the
+// capacity of the ClusterQueue it's submitted to, as it'd stay pending forever, rather than being admitted
+// by Kueue.
+func quotaWarnings(ctx context.Context, capacities *cache.Cache[types.NamespacedName, *queueCapacity], rayCluster *rayv1.RayCluster) admission.Warnings {
+	return workloadQuotaWarnings(ctx, capacities, "RayCluster", rayCluster, func() corev1.ResourceList {
+		return rayClusterRequests(rayCluster)
+	})
+}
+
+// workloadQuotaWarnings returns a warning for each resource the workload requests more of, in aggregate, than the
+// capacity of the ClusterQueue it's submitted to. The workloads that aren't submitted to a LocalQueue, or whose
+// queues can't be read, e.g., because Kueue isn't installed, aren't checked.
+func workloadQuotaWarnings(ctx context.Context, capacities *cache.Cache[types.NamespacedName, *queueCapacity], kind string,
+	obj client.Object, workloadRequests func() corev1.ResourceList) admission.Warnings {
+	queueName := obj.GetLabels()[kueueconstants.QueueLabel]
+	if capacities == nil || queueName == "" {
+		return nil
+	}
+	capacity, err := capacities.Get(ctx, types.NamespacedName{Namespace: obj.GetNamespace(), Name: queueName})
+	if err != nil {
+		logf.FromContext(ctx).V(2).Info("Cannot read queue capacity", "namespace", obj.GetNamespace(), "queue", queueName, "error", err.Error())
+		return nil
+	}
+
+	requests := workloadRequests()
+	names := make([]string, 0, len(requests))
+	for name := range requests {
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+
+	var warnings admission.Warnings
+	for _, name := range names {
+		requested := requests[corev1.ResourceName(name)]
+		quota, ok := capacity.resources[corev1.ResourceName(name)]
+		if !ok || requested.Cmp(quota) <= 0 {
+			continue
+		}
+		warnings = append(warnings, fmt.Sprintf("%s requests %s %s in total, more than the %s quota of ClusterQueue %s, and will never be admitted",
+			kind, requested.String(), name, quota.String(), capacity.clusterQueue))
+	}
+	return warnings
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/project-codeflare/codeflare-common/support"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	kueueconstants "sigs.k8s.io/kueue/pkg/controller/constants"
+)
+
+func TestQuotaWarnings(t *testing.T) {
+	test := support.NewTest(t)
+	ctx := context.Background()
+
+	scheme := runtime.NewScheme()
+	test.Expect(kueue.AddToScheme(scheme)).To(Succeed())
+
+	localQueue := &kueue.LocalQueue{
+		ObjectMeta: metav1.ObjectMeta{Name: "team-a", Namespace: "ns"},
+		Spec:       kueue.LocalQueueSpec{ClusterQueue: "team-a-cq"},
+	}
+	clusterQueue := func(cohort string, cpuBorrowingLimit, memoryBorrowingLimit *resource.Quantity) *kueue.ClusterQueue {
+		return &kueue.ClusterQueue{
+			ObjectMeta: metav1.ObjectMeta{Name: "team-a-cq"},
+			Spec: kueue.ClusterQueueSpec{
+				Cohort: cohort,
+				ResourceGroups: []kueue.ResourceGroup{{
+					CoveredResources: []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory},
+					Flavors: []kueue.FlavorQuotas{
+						{Name: "on-demand", Resources: []kueue.ResourceQuota{
+							{Name: corev1.ResourceCPU, NominalQuota: resource.MustParse("4"), BorrowingLimit: cpuBorrowingLimit},
+							{Name: corev1.ResourceMemory, NominalQuota: resource.MustParse("16Gi"), BorrowingLimit: memoryBorrowingLimit},
+						}},
+						{Name: "spot", Resources: []kueue.ResourceQuota{
+							{Name: corev1.ResourceCPU, NominalQuota: resource.MustParse("4"), BorrowingLimit: cpuBorrowingLimit},
+							{Name: corev1.ResourceMemory, NominalQuota: resource.MustParse("16Gi"), BorrowingLimit: memoryBorrowingLimit},
+						}},
+					},
+				}},
+			},
+		}
+	}
+	rayCluster := func(workers int32) *rayv1.RayCluster {
+		resources := corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+			Limits:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2"), corev1.ResourceMemory: resource.MustParse("4Gi")},
+		}
+		return &rayv1.RayCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "raycluster", Namespace: "ns", Labels: map[string]string{kueueconstants.QueueLabel: "team-a"}},
+			Spec: rayv1.RayClusterSpec{
+				HeadGroupSpec: rayv1.HeadGroupSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "ray-head", Resources: resources}},
+				}}},
+				WorkerGroupSpecs: []rayv1.WorkerGroupSpec{{
+					GroupName: "workers",
+					Replicas:  ptr.To(workers),
+					Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{Name: "ray-worker", Resources: resources}},
+					}},
+				}},
+			},
+		}
+	}
+	warnings := func(cluster *rayv1.RayCluster, objects ...client.Object) []string {
+		reader := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+		return quotaWarnings(ctx, newQueueCapacityCache(reader), cluster)
+	}
+
+	test.T().Run("Expected aggregate requests to include the limits of the resources that aren't requested", func(t *testing.T) {
+		requests := rayClusterRequests(rayCluster(3))
+		test.Expect(requests.Cpu().String()).To(Equal("4"))
+		test.Expect(requests.Memory().String()).To(Equal("16Gi"))
+	})
+
+	test.T().Run("Expected warning when the aggregate requests exceed the quota of the ClusterQueue", func(t *testing.T) {
+		test.Expect(warnings(rayCluster(8), localQueue, clusterQueue("", nil, nil))).To(ConsistOf(
+			"RayCluster requests 36Gi memory in total, more than the 32Gi quota of ClusterQueue team-a-cq, and will never be admitted",
+			"RayCluster requests 9 cpu in total, more than the 8 quota of ClusterQueue team-a-cq, and will never be admitted",
+		))
+	})
+
+	test.T().Run("Expected no warning when the aggregate requests fit in the quota of the ClusterQueue", func(t *testing.T) {
+		test.Expect(warnings(rayCluster(7), localQueue, clusterQueue("", nil, nil))).To(BeEmpty())
+	})
+
+	test.T().Run("Expected borrowing limits to be added to the quota of ClusterQueues in a cohort", func(t *testing.T) {
+		test.Expect(warnings(rayCluster(8), localQueue, clusterQueue("cohort", ptr.To(resource.MustParse("1")), ptr.To(resource.MustParse("1Gi"))))).To(ConsistOf(
+			"RayCluster requests 36Gi memory in total, more than the 34Gi quota of ClusterQueue team-a-cq, and will never be admitted",
+		))
+		// The resources can be borrowed without limit
+		test.Expect(warnings(rayCluster(8), localQueue, clusterQueue("cohort", nil, nil))).To(BeEmpty())
+	})
+
+	test.T().Run("Negative: Expected no warning when the queue cannot be read", func(t *testing.T) {
+		test.Expect(warnings(rayCluster(8))).To(BeEmpty())
+
+		unqueued := rayCluster(8)
+		unqueued.Labels = nil
+		test.Expect(warnings(unqueued, localQueue, clusterQueue("", nil, nil))).To(BeEmpty())
+	})
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/project-codeflare/codeflare-operator/pkg/config"
+)
+
+const (
+	routeTimeoutAnnotation                 = "haproxy.router.openshift.io/timeout"
+	routeIPAllowlistAnnotation             = "haproxy.router.openshift.io/ip_allowlist"
+	routeIPWhitelistAnnotation             = "haproxy.router.openshift.io/ip_whitelist"
+	routeRateLimitAnnotation               = "haproxy.router.openshift.io/rate-limit-connections"
+	routeConcurrentConnectionsAnnotation   = "haproxy.router.openshift.io/rate-limit-connections.concurrent-tcp"
+	routeConnectionRateAnnotation          = "haproxy.router.openshift.io/rate-limit-connections.rate-tcp"
+	routeRequestRateAnnotation             = "haproxy.router.openshift.io/rate-limit-connections.rate-http"
+	routeStrictTransportSecurityAnnotation = "haproxy.router.openshift.io/hsts_header"
+)
+
+// dashboardRouteAnnotations returns the router annotations of the dashboard Route, that enforce the dashboard
+// hardening configuration, if any.
+func dashboardRouteAnnotations(cfg *config.KubeRayConfiguration) map[string]string {
+	annotations := routeAnnotations(cfg, true)
+	if cfg == nil || cfg.DashboardHardening == nil {
+		return annotations
+	}
+	hardening := cfg.DashboardHardening
+	if hardening.Timeout != nil {
+		annotations[routeTimeoutAnnotation] = fmt.Sprintf("%dms", hardening.Timeout.Milliseconds())
+	}
+	if hardening.HSTSMaxAge != nil {
+		annotations[routeStrictTransportSecurityAnnotation] = fmt.Sprintf("max-age=%d;includeSubDomains", int64(hardening.HSTSMaxAge.Seconds()))
+	}
+	return annotations
+}
+
+// rayClientRouteAnnotations returns the router annotations of the Ray client Route. The Ray client connections
+// are passed through to the head, so neither the timeout, nor the HTTP settings, apply.
+func rayClientRouteAnnotations(cfg *config.KubeRayConfiguration) map[string]string {
+	return routeAnnotations(cfg, false)
+}
+
+func routeAnnotations(cfg *config.KubeRayConfiguration, http bool) map[string]string {
+	annotations := map[string]string{}
+	if cfg == nil || cfg.DashboardHardening == nil {
+		return annotations
+	}
+	hardening := cfg.DashboardHardening
+	if len(hardening.IPAllowlist) > 0 {
+		allowlist := strings.Join(hardening.IPAllowlist, " ")
+		annotations[routeIPAllowlistAnnotation] = allowlist
+		// The annotation the routers of OpenShift before 4.14 support
+		annotations[routeIPWhitelistAnnotation] = allowlist
+	}
+	if rateLimit := hardening.RateLimit; rateLimit != nil {
+		set := func(annotation string, limit *int32) {
+			if limit != nil {
+				annotations[routeRateLimitAnnotation] = "true"
+				annotations[annotation] = strconv.Itoa(int(*limit))
+			}
+		}
+		set(routeConcurrentConnectionsAnnotation, rateLimit.ConcurrentConnections)
+		set(routeConnectionRateAnnotation, rateLimit.ConnectionRate)
+		if http {
+			set(routeRequestRateAnnotation, rateLimit.RequestRate)
+		}
+	}
+	return annotations
+}
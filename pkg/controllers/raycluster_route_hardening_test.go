@@ -0,0 +1,72 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/project-codeflare/codeflare-common/support"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	"github.com/project-codeflare/codeflare-operator/pkg/config"
+)
+
+func TestRouteHardening(t *testing.T) {
+	test := support.NewTest(t)
+
+	cluster := &rayv1.RayCluster{ObjectMeta: metav1.ObjectMeta{Name: "raycluster", Namespace: "ns"}}
+
+	test.T().Run("Expected dashboard and Ray client Routes to be annotated with the hardening configuration", func(t *testing.T) {
+		cfg := &config.KubeRayConfiguration{DashboardHardening: &config.DashboardHardeningConfiguration{
+			Timeout:     &metav1.Duration{Duration: 90 * time.Second},
+			IPAllowlist: []string{"10.0.0.0/8", "192.168.1.10"},
+			RateLimit: &config.RouteRateLimitConfiguration{
+				ConcurrentConnections: ptr.To[int32](20),
+				RequestRate:           ptr.To[int32](100),
+			},
+			HSTSMaxAge: &metav1.Duration{Duration: 365 * 24 * time.Hour},
+		}}
+
+		test.Expect(desiredClusterRoute(cluster, cfg).Annotations).To(Equal(map[string]string{
+			"haproxy.router.openshift.io/timeout":                               "90000ms",
+			"haproxy.router.openshift.io/ip_allowlist":                          "10.0.0.0/8 192.168.1.10",
+			"haproxy.router.openshift.io/ip_whitelist":                          "10.0.0.0/8 192.168.1.10",
+			"haproxy.router.openshift.io/rate-limit-connections":                "true",
+			"haproxy.router.openshift.io/rate-limit-connections.concurrent-tcp": "20",
+			"haproxy.router.openshift.io/rate-limit-connections.rate-http":      "100",
+			"haproxy.router.openshift.io/hsts_header":                           "max-age=31536000;includeSubDomains",
+		}))
+		test.Expect(desiredRayClientRoute(cluster, cfg).Annotations).To(Equal(map[string]string{
+			"haproxy.router.openshift.io/ip_allowlist":                          "10.0.0.0/8 192.168.1.10",
+			"haproxy.router.openshift.io/ip_whitelist":                          "10.0.0.0/8 192.168.1.10",
+			"haproxy.router.openshift.io/rate-limit-connections":                "true",
+			"haproxy.router.openshift.io/rate-limit-connections.concurrent-tcp": "20",
+		}))
+	})
+
+	test.T().Run("Negative: Expected no annotations without hardening configuration", func(t *testing.T) {
+		cfg := &config.KubeRayConfiguration{}
+
+		test.Expect(desiredClusterRoute(cluster, cfg).Annotations).To(BeEmpty())
+		test.Expect(desiredRayClientRoute(cluster, cfg).Annotations).To(BeEmpty())
+	})
+}
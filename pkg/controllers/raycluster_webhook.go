@@ -18,12 +18,18 @@ package controllers
 
 import (
 	"context"
+	"fmt"
+	"regexp"
+	"slices"
 	"strconv"
+	"strings"
 
 	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -31,21 +37,33 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
+	"github.com/project-codeflare/codeflare-operator/pkg/cache"
 	"github.com/project-codeflare/codeflare-operator/pkg/config"
-)
-
-const (
-	oauthProxyContainerName = "oauth-proxy"
-	oauthProxyVolumeName    = "proxy-tls-secret"
-	initContainerName       = "create-cert"
+	"github.com/project-codeflare/codeflare-operator/pkg/cosign"
+	"github.com/project-codeflare/codeflare-operator/pkg/defaults"
 )
 
 // log is for logging in this package.
 var rayclusterlog = logf.Log.WithName("raycluster-resource")
 
-func SetupRayClusterWebhookWithManager(mgr ctrl.Manager, cfg *config.KubeRayConfiguration) error {
+func SetupRayClusterWebhookWithManager(mgr ctrl.Manager, cfg *config.KubeRayConfiguration, cacheCfg *config.CacheConfiguration) error {
+	scope, err := newCacheScope(cacheCfg)
+	if err != nil {
+		return err
+	}
 	rayClusterWebhookInstance := &rayClusterWebhook{
-		Config: cfg,
+		Config:               cfg,
+		Scope:                scope,
+		NamespaceAnnotations: newNamespaceAnnotationsCache(mgr.GetAPIReader()),
+		QueueCapacities:      newQueueCapacityCache(mgr.GetAPIReader()),
+		KubeRayVersion:       newKubeRayVersionCache(mgr.GetAPIReader()),
+	}
+	if cfg.ImageVerification != nil && ptr.Deref(cfg.ImageVerification.Enabled, false) {
+		verifier, err := cosign.NewVerifier(cfg.ImageVerification)
+		if err != nil {
+			return err
+		}
+		rayClusterWebhookInstance.ImageSignatures = newImageSignatureCache(mgr.GetAPIReader(), verifier)
 	}
 	return ctrl.NewWebhookManagedBy(mgr).
 		For(&rayv1.RayCluster{}).
@@ -57,8 +75,23 @@ func SetupRayClusterWebhookWithManager(mgr ctrl.Manager, cfg *config.KubeRayConf
 // +kubebuilder:webhook:path=/mutate-ray-io-v1-raycluster,mutating=true,failurePolicy=fail,sideEffects=None,groups=ray.io,resources=rayclusters,verbs=create,versions=v1,name=mraycluster.ray.openshift.ai,admissionReviewVersions=v1
 // +kubebuilder:webhook:path=/validate-ray-io-v1-raycluster,mutating=false,failurePolicy=fail,sideEffects=None,groups=ray.io,resources=rayclusters,verbs=create;update,versions=v1,name=vraycluster.ray.openshift.ai,admissionReviewVersions=v1
 
+// +kubebuilder:rbac:groups=kueue.x-k8s.io,resources=localqueues,verbs=get
+// +kubebuilder:rbac:groups=kueue.x-k8s.io,resources=clusterqueues,verbs=get
+
 type rayClusterWebhook struct {
 	Config *config.KubeRayConfiguration
+	// Scope restricts the RayClusters the webhook defaults and validates to the ones the operator manages
+	Scope cacheScope
+	// NamespaceAnnotations caches the annotations of the RayCluster namespaces,
+	// without informing on all the namespaces
+	NamespaceAnnotations *cache.Cache[string, map[string]string]
+	// QueueCapacities caches the capacity of the ClusterQueues of the LocalQueues the RayClusters are submitted to,
+	// without informing on all the queues
+	QueueCapacities *cache.Cache[types.NamespacedName, *queueCapacity]
+	// KubeRayVersion caches the version of the KubeRay operator
+	KubeRayVersion *cache.Cache[string, string]
+	// ImageSignatures caches the verification results of the signatures of the Ray images, when it's enabled
+	ImageSignatures *cache.Cache[imageSignatureKey, imageSignature]
 }
 
 var _ webhook.CustomDefaulter = &rayClusterWebhook{}
@@ -67,84 +100,66 @@ var _ webhook.CustomValidator = &rayClusterWebhook{}
 // Default implements webhook.Defaulter so a webhook will be registered for the type
 func (w *rayClusterWebhook) Default(ctx context.Context, obj runtime.Object) error {
 	rayCluster := obj.(*rayv1.RayCluster)
-
-	if ptr.Deref(w.Config.RayDashboardOAuthEnabled, true) {
-		rayclusterlog.V(2).Info("Adding OAuth sidecar container")
-		rayCluster.Spec.HeadGroupSpec.Template.Spec.Containers = upsert(rayCluster.Spec.HeadGroupSpec.Template.Spec.Containers, oauthProxyContainer(rayCluster), withContainerName(oauthProxyContainerName))
-
-		rayCluster.Spec.HeadGroupSpec.Template.Spec.Volumes = upsert(rayCluster.Spec.HeadGroupSpec.Template.Spec.Volumes, oauthProxyTLSSecretVolume(rayCluster), withVolumeName(oauthProxyVolumeName))
-
-		rayCluster.Spec.HeadGroupSpec.Template.Spec.ServiceAccountName = rayCluster.Name + "-oauth-proxy"
+	if !w.Scope.includesRayCluster(rayCluster) {
+		return nil
 	}
 
-	if ptr.Deref(w.Config.MTLSEnabled, true) {
-		rayclusterlog.V(2).Info("Adding create-cert Init Containers")
-		// HeadGroupSpec
+	rayclusterlog.V(2).Info("Applying RayCluster defaults", "namespace", rayCluster.Namespace, "name", rayCluster.Name)
+	defaults.ApplyRayClusterDefaults(w.Config, rayCluster)
 
-		// Append the list of environment variables for the ray-head container
-		for _, envVar := range envVarList() {
-			rayCluster.Spec.HeadGroupSpec.Template.Spec.Containers[0].Env = upsert(rayCluster.Spec.HeadGroupSpec.Template.Spec.Containers[0].Env, envVar, withEnvVarName(envVar.Name))
+	if w.NamespaceAnnotations != nil {
+		annotations, err := w.NamespaceAnnotations.Get(ctx, rayCluster.Namespace)
+		if err != nil {
+			return err
 		}
-
-		// Append the create-cert Init Container
-		rayCluster.Spec.HeadGroupSpec.Template.Spec.InitContainers = upsert(rayCluster.Spec.HeadGroupSpec.Template.Spec.InitContainers, rayHeadInitContainer(rayCluster, w.Config), withContainerName(initContainerName))
-
-		// Append the CA volumes
-		for _, caVol := range caVolumes(rayCluster) {
-			rayCluster.Spec.HeadGroupSpec.Template.Spec.Volumes = upsert(rayCluster.Spec.HeadGroupSpec.Template.Spec.Volumes, caVol, withVolumeName(caVol.Name))
+		// The invalid namespace annotations are ignored, rather than rejecting the RayClusters of the namespace
+		// until they are fixed
+		if err := defaults.ApplyTopologyNamespaceDefaults(annotations, &rayCluster.Spec); err != nil {
+			rayclusterlog.Error(err, "Ignoring invalid topology defaults", "namespace", rayCluster.Namespace, "name", rayCluster.Name)
 		}
-
-		// Append the certificate volume mounts
-		for _, mount := range certVolumeMounts() {
-			rayCluster.Spec.HeadGroupSpec.Template.Spec.Containers[0].VolumeMounts = upsert(rayCluster.Spec.HeadGroupSpec.Template.Spec.Containers[0].VolumeMounts, mount, byVolumeMountName)
-		}
-
-		// WorkerGroupSpec
-		for i := range rayCluster.Spec.WorkerGroupSpecs {
-			workerSpec := &rayCluster.Spec.WorkerGroupSpecs[i]
-
-			// Append the list of environment variables for the worker container
-			for _, envVar := range envVarList() {
-				workerSpec.Template.Spec.Containers[0].Env = upsert(workerSpec.Template.Spec.Containers[0].Env, envVar, withEnvVarName(envVar.Name))
-			}
-
-			// Append the CA volumes
-			for _, caVol := range caVolumes(rayCluster) {
-				workerSpec.Template.Spec.Volumes = upsert(workerSpec.Template.Spec.Volumes, caVol, withVolumeName(caVol.Name))
-			}
-
-			// Append the certificate volume mounts
-			for _, mount := range certVolumeMounts() {
-				workerSpec.Template.Spec.Containers[0].VolumeMounts = upsert(workerSpec.Template.Spec.Containers[0].VolumeMounts, mount, byVolumeMountName)
-			}
-
-			// Append the create-cert Init Container
-			workerSpec.Template.Spec.InitContainers = upsert(workerSpec.Template.Spec.InitContainers, rayWorkerInitContainer(w.Config), withContainerName(initContainerName))
+		if err := defaults.ApplyKarpenterNamespaceDefaults(w.Config, annotations, &rayCluster.Spec); err != nil {
+			rayclusterlog.Error(err, "Ignoring invalid Karpenter defaults", "namespace", rayCluster.Namespace, "name", rayCluster.Name)
 		}
 	}
 
+	w.pinImageSignatures(ctx, rayCluster)
+
 	return nil
 }
 
 func (w *rayClusterWebhook) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
 	rayCluster := obj.(*rayv1.RayCluster)
+	if !w.Scope.includesRayCluster(rayCluster) {
+		return nil, nil
+	}
 
 	var warnings admission.Warnings
 	var allErrors field.ErrorList
 
 	allErrors = append(allErrors, validateIngress(rayCluster)...)
+	allErrors = append(allErrors, validateS3Secret(rayCluster)...)
+	allErrors = append(allErrors, validateExternalSecrets(rayCluster)...)
+	allErrors = append(allErrors, validateScratchVolume(rayCluster, w.Config)...)
 
 	if ptr.Deref(w.Config.RayDashboardOAuthEnabled, true) {
-		allErrors = append(allErrors, validateOAuthProxyContainer(rayCluster)...)
+		allErrors = append(allErrors, validateOAuthProxyContainer(rayCluster, nil, w.Config)...)
 		allErrors = append(allErrors, validateOAuthProxyVolume(rayCluster)...)
 		allErrors = append(allErrors, validateHeadGroupServiceAccountName(rayCluster)...)
 	}
 
+	warnings, allErrors = appendRayVersionSkew(warnings, allErrors, rayCluster, w.Config)
+	warnings, allErrors = appendPolicyViolations(warnings, allErrors, rayCluster, w.Config, privilegedPodPolicy,
+		validatePrivilegedPod(rayCluster, w.Config))
+	warnings, allErrors = w.appendRayCompatibility(ctx, warnings, allErrors, rayCluster)
+	warnings, allErrors = w.appendImageSignatures(ctx, warnings, allErrors, rayCluster, nil)
+	warnings = append(warnings, quotaWarnings(ctx, w.QueueCapacities, rayCluster)...)
+
 	return warnings, allErrors.ToAggregate()
 }
 
 func (w *rayClusterWebhook) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
 	rayCluster := newObj.(*rayv1.RayCluster)
+	oldRayCluster := oldObj.(*rayv1.RayCluster)
 
 	var warnings admission.Warnings
 	var allErrors field.ErrorList
@@ -153,23 +168,43 @@ func (w *rayClusterWebhook) ValidateUpdate(ctx context.Context, oldObj, newObj r
 		// Object is being deleted, skip validations
 		return nil, nil
 	}
+	if !w.Scope.includesRayCluster(rayCluster) {
+		return nil, nil
+	}
 
 	allErrors = append(allErrors, validateIngress(rayCluster)...)
+	allErrors = append(allErrors, validateS3Secret(rayCluster)...)
+	allErrors = append(allErrors, validateExternalSecrets(rayCluster)...)
+	allErrors = append(allErrors, validateScratchVolume(rayCluster, w.Config)...)
 
 	if ptr.Deref(w.Config.RayDashboardOAuthEnabled, true) {
-		allErrors = append(allErrors, validateOAuthProxyContainer(rayCluster)...)
+		allErrors = append(allErrors, validateOAuthProxyContainer(rayCluster, oldRayCluster, w.Config)...)
 		allErrors = append(allErrors, validateOAuthProxyVolume(rayCluster)...)
 		allErrors = append(allErrors, validateHeadGroupServiceAccountName(rayCluster)...)
 	}
 
 	// Init Container related errors
 	if ptr.Deref(w.Config.MTLSEnabled, true) {
-		allErrors = append(allErrors, validateHeadInitContainer(rayCluster, w.Config)...)
-		allErrors = append(allErrors, validateWorkerInitContainer(rayCluster, w.Config)...)
+		allErrors = append(allErrors, validateHeadInitContainer(rayCluster, oldRayCluster, w.Config)...)
+		allErrors = append(allErrors, validateWorkerInitContainer(rayCluster, oldRayCluster, w.Config)...)
 		allErrors = append(allErrors, validateHeadEnvVars(rayCluster)...)
 		allErrors = append(allErrors, validateWorkerEnvVars(rayCluster)...)
 		allErrors = append(allErrors, validateCaVolumes(rayCluster)...)
 	}
+
+	// The RayClusters created with privileged pod settings, before they were prohibited, remain updatable
+	if len(validatePrivilegedPod(oldRayCluster, w.Config)) == 0 {
+		warnings, allErrors = appendPolicyViolations(warnings, allErrors, rayCluster, w.Config, privilegedPodPolicy,
+			validatePrivilegedPod(rayCluster, w.Config))
+	}
+	// The Ray versions of the RayClusters are only validated when they change, so that the RayClusters admitted before
+	// the policies were tightened, or the KubeRay operator was upgraded, remain updatable, e.g., to be suspended, or resumed
+	if rayVersionChanged(rayCluster, oldRayCluster) {
+		warnings, allErrors = appendRayVersionSkew(warnings, allErrors, rayCluster, w.Config)
+		warnings, allErrors = w.appendRayCompatibility(ctx, warnings, allErrors, rayCluster)
+	}
+	warnings, allErrors = w.appendImageSignatures(ctx, warnings, allErrors, rayCluster, oldRayCluster)
+
 	return warnings, allErrors.ToAggregate()
 }
 
@@ -178,10 +213,17 @@ func (w *rayClusterWebhook) ValidateDelete(ctx context.Context, obj runtime.Obje
 	return nil, nil
 }
 
-func validateOAuthProxyContainer(rayCluster *rayv1.RayCluster) field.ErrorList {
+// validateOAuthProxyContainer checks the OAuth proxy container of the head group is the one the operator defaults,
+// or, on update, that it's left unchanged, as it depends on the version, and configuration, of the operator, that may
+// have changed since the RayCluster was created.
+func validateOAuthProxyContainer(rayCluster, oldRayCluster *rayv1.RayCluster, cfg *config.KubeRayConfiguration) field.ErrorList {
 	var allErrors field.ErrorList
 
-	if err := contains(rayCluster.Spec.HeadGroupSpec.Template.Spec.Containers, oauthProxyContainer(rayCluster), byContainerName,
+	var oldContainers []corev1.Container
+	if oldRayCluster != nil {
+		oldContainers = oldRayCluster.Spec.HeadGroupSpec.Template.Spec.Containers
+	}
+	if err := containsOrUnchanged(rayCluster.Spec.HeadGroupSpec.Template.Spec.Containers, oldContainers, defaults.OAuthProxyContainer(cfg, rayCluster), byContainerName,
 		field.NewPath("spec", "headGroupSpec", "template", "spec", "containers"),
 		"OAuth Proxy container is immutable"); err != nil {
 		allErrors = append(allErrors, err)
@@ -193,7 +235,7 @@ func validateOAuthProxyContainer(rayCluster *rayv1.RayCluster) field.ErrorList {
 func validateOAuthProxyVolume(rayCluster *rayv1.RayCluster) field.ErrorList {
 	var allErrors field.ErrorList
 
-	if err := contains(rayCluster.Spec.HeadGroupSpec.Template.Spec.Volumes, oauthProxyTLSSecretVolume(rayCluster), byVolumeName,
+	if err := contains(rayCluster.Spec.HeadGroupSpec.Template.Spec.Volumes, defaults.OAuthProxyTLSSecretVolume(rayCluster), byVolumeName,
 		field.NewPath("spec", "headGroupSpec", "template", "spec", "volumes"),
 		"OAuth Proxy TLS Secret volume is immutable"); err != nil {
 		allErrors = append(allErrors, err)
@@ -215,169 +257,208 @@ func validateIngress(rayCluster *rayv1.RayCluster) field.ErrorList {
 	return allErrors
 }
 
-func validateHeadGroupServiceAccountName(rayCluster *rayv1.RayCluster) field.ErrorList {
+func validateS3Secret(rayCluster *rayv1.RayCluster) field.ErrorList {
 	var allErrors field.ErrorList
 
-	if rayCluster.Spec.HeadGroupSpec.Template.Spec.ServiceAccountName != rayCluster.Name+"-oauth-proxy" {
+	if secretName, ok := rayCluster.Annotations[defaults.S3SecretAnnotation]; ok {
+		for _, msg := range validation.IsDNS1123Subdomain(secretName) {
+			allErrors = append(allErrors, field.Invalid(
+				field.NewPath("metadata", "annotations").Key(defaults.S3SecretAnnotation),
+				secretName,
+				msg))
+		}
+	}
+
+	return allErrors
+}
+
+func validateExternalSecrets(rayCluster *rayv1.RayCluster) field.ErrorList {
+	var allErrors field.ErrorList
+
+	for _, secretName := range defaults.ExternalSecretNames(rayCluster) {
+		for _, msg := range validation.IsDNS1123Subdomain(secretName) {
+			allErrors = append(allErrors, field.Invalid(
+				field.NewPath("metadata", "annotations").Key(defaults.ExternalSecretsAnnotation),
+				secretName,
+				msg))
+		}
+	}
+
+	return allErrors
+}
+
+func validateScratchVolume(rayCluster *rayv1.RayCluster, cfg *config.KubeRayConfiguration) field.ErrorList {
+	var allErrors field.ErrorList
+
+	if !defaults.IsScratchVolumeRequested(cfg, rayCluster) {
+		return allErrors
+	}
+	if _, err := defaults.ScratchVolumeSize(cfg.ScratchVolume, rayCluster); err != nil {
 		allErrors = append(allErrors, field.Invalid(
-			field.NewPath("spec", "headGroupSpec", "template", "spec", "serviceAccountName"),
-			rayCluster.Spec.HeadGroupSpec.Template.Spec.ServiceAccountName,
-			"RayCluster head group service account is immutable"))
+			field.NewPath("metadata", "annotations").Key(defaults.ScratchVolumeSizeAnnotation),
+			rayCluster.Annotations[defaults.ScratchVolumeSizeAnnotation],
+			err.Error()))
+	}
+	if storageClassName, ok := rayCluster.Annotations[defaults.ScratchVolumeStorageClassAnnotation]; ok {
+		for _, msg := range validation.IsDNS1123Subdomain(storageClassName) {
+			allErrors = append(allErrors, field.Invalid(
+				field.NewPath("metadata", "annotations").Key(defaults.ScratchVolumeStorageClassAnnotation),
+				storageClassName,
+				msg))
+		}
 	}
 
 	return allErrors
 }
 
-func oauthProxyContainer(rayCluster *rayv1.RayCluster) corev1.Container {
-	return corev1.Container{
-		Name:  oauthProxyContainerName,
-		Image: "registry.redhat.io/openshift4/ose-oauth-proxy@sha256:1ea6a01bf3e63cdcf125c6064cbd4a4a270deaf0f157b3eabb78f60556840366",
-		Ports: []corev1.ContainerPort{
-			{ContainerPort: 8443, Name: "oauth-proxy"},
-		},
-		Env: []corev1.EnvVar{
-			{
-				Name: "COOKIE_SECRET",
-				ValueFrom: &corev1.EnvVarSource{
-					SecretKeyRef: &corev1.SecretKeySelector{
-						LocalObjectReference: corev1.LocalObjectReference{
-							Name: rayCluster.Name + "-oauth-config",
-						},
-						Key: "cookie_secret",
-					},
-				},
-			},
-		},
-		Args: []string{
-			"--https-address=:8443",
-			"--provider=openshift",
-			"--openshift-service-account=" + rayCluster.Name + "-oauth-proxy",
-			"--upstream=http://localhost:8265",
-			"--tls-cert=/etc/tls/private/tls.crt",
-			"--tls-key=/etc/tls/private/tls.key",
-			"--cookie-secret=$(COOKIE_SECRET)",
-			"--openshift-delegate-urls={\"/\":{\"resource\":\"pods\",\"namespace\":\"" + rayCluster.Namespace + "\",\"verb\":\"get\"}}",
-		},
-		VolumeMounts: []corev1.VolumeMount{
-			{
-				Name:      oauthProxyVolumeName,
-				MountPath: "/etc/tls/private",
-				ReadOnly:  true,
-			},
-		},
+// rayVersionFromImageTag matches the Ray version prefix of an image tag, e.g. 2.20.0 in 2.20.0-py39-cu118
+var rayVersionFromImageTag = regexp.MustCompile(`^\d+\.\d+\.\d+`)
+
+// rayVersionFromImage returns the Ray version referenced by the image tag, or an empty string
+// if it cannot be determined, e.g. for digest-only references or tags like latest.
+func rayVersionFromImage(image string) string {
+	if i := strings.Index(image, "@"); i >= 0 {
+		image = image[:i]
+	}
+	colon := strings.LastIndex(image, ":")
+	if colon <= strings.LastIndex(image, "/") {
+		return ""
 	}
+	return rayVersionFromImageTag.FindString(image[colon+1:])
 }
 
-func oauthProxyTLSSecretVolume(rayCluster *rayv1.RayCluster) corev1.Volume {
-	return corev1.Volume{
-		Name: oauthProxyVolumeName,
-		VolumeSource: corev1.VolumeSource{
-			Secret: &corev1.SecretVolumeSource{
-				SecretName: rayCluster.Name + "-proxy-tls-secret",
-			},
-		},
+func validateRayVersionSkew(rayCluster *rayv1.RayCluster) field.ErrorList {
+	var allErrors field.ErrorList
+
+	if len(rayCluster.Spec.HeadGroupSpec.Template.Spec.Containers) == 0 {
+		return allErrors
 	}
+	headVersion := rayVersionFromImage(rayCluster.Spec.HeadGroupSpec.Template.Spec.Containers[0].Image)
+	if headVersion == "" {
+		return allErrors
+	}
+
+	for i := range rayCluster.Spec.WorkerGroupSpecs {
+		workerSpec := &rayCluster.Spec.WorkerGroupSpecs[i]
+		if len(workerSpec.Template.Spec.Containers) == 0 {
+			continue
+		}
+		image := workerSpec.Template.Spec.Containers[0].Image
+		if workerVersion := rayVersionFromImage(image); workerVersion != "" && workerVersion != headVersion {
+			allErrors = append(allErrors, field.Invalid(
+				field.NewPath("spec", "workerGroupSpecs", strconv.Itoa(i), "template", "spec", "containers", strconv.Itoa(0), "image"),
+				image,
+				fmt.Sprintf("Ray version %s of worker group %s does not match Ray version %s of the head group", workerVersion, workerSpec.GroupName, headVersion)))
+		}
+	}
+
+	return allErrors
 }
 
-func certVolumeMounts() []corev1.VolumeMount {
-	return []corev1.VolumeMount{
-		{
-			Name:      "ca-vol",
-			MountPath: "/home/ray/workspace/ca",
-			ReadOnly:  true,
-		},
-		{
-			Name:      "server-cert",
-			MountPath: "/home/ray/workspace/tls",
-			ReadOnly:  false,
-		},
+// appendRayVersionSkew reports Ray version skew either as errors or warnings, depending on the configuration
+func appendRayVersionSkew(warnings admission.Warnings, allErrors field.ErrorList, rayCluster *rayv1.RayCluster, cfg *config.KubeRayConfiguration) (admission.Warnings, field.ErrorList) {
+	skew := validateRayVersionSkew(rayCluster)
+	if ptr.Deref(cfg.RejectRayVersionSkew, false) {
+		return appendPolicyViolations(warnings, allErrors, rayCluster, cfg, rayVersionSkewPolicy, skew)
 	}
+	for _, err := range skew {
+		warnings = append(warnings, err.Error())
+	}
+	return warnings, allErrors
+}
+
+// rayVersionChanged returns whether the Ray version, or the image of the Ray container of the head group, or of any
+// of the worker groups, of the RayCluster differs from the old RayCluster.
+func rayVersionChanged(rayCluster, oldRayCluster *rayv1.RayCluster) bool {
+	if rayCluster.Spec.RayVersion != oldRayCluster.Spec.RayVersion ||
+		rayContainerImage(&rayCluster.Spec.HeadGroupSpec.Template.Spec) != rayContainerImage(&oldRayCluster.Spec.HeadGroupSpec.Template.Spec) {
+		return true
+	}
+	for i := range rayCluster.Spec.WorkerGroupSpecs {
+		workerSpec := &rayCluster.Spec.WorkerGroupSpecs[i]
+		oldWorkerSpec := workerGroupSpec(oldRayCluster, workerSpec.GroupName)
+		if oldWorkerSpec == nil || rayContainerImage(&workerSpec.Template.Spec) != rayContainerImage(&oldWorkerSpec.Template.Spec) {
+			return true
+		}
+	}
+	return false
 }
 
-func envVarList() []corev1.EnvVar {
-	return []corev1.EnvVar{
-		{
-			Name: "MY_POD_IP",
-			ValueFrom: &corev1.EnvVarSource{
-				FieldRef: &corev1.ObjectFieldSelector{
-					FieldPath: "status.podIP",
-				},
-			},
-		},
-		{
-			Name:  "RAY_USE_TLS",
-			Value: "1",
-		},
-		{
-			Name:  "RAY_TLS_SERVER_CERT",
-			Value: "/home/ray/workspace/tls/server.crt",
-		},
-		{
-			Name:  "RAY_TLS_SERVER_KEY",
-			Value: "/home/ray/workspace/tls/server.key",
-		},
-		{
-			Name:  "RAY_TLS_CA_CERT",
-			Value: "/home/ray/workspace/tls/ca.crt",
-		},
+func rayContainerImage(podSpec *corev1.PodSpec) string {
+	if len(podSpec.Containers) == 0 {
+		return ""
 	}
+	return podSpec.Containers[0].Image
 }
 
-func caVolumes(rayCluster *rayv1.RayCluster) []corev1.Volume {
-	return []corev1.Volume{
-		{
-			Name: "ca-vol",
-			VolumeSource: corev1.VolumeSource{
-				Secret: &corev1.SecretVolumeSource{
-					SecretName: `ca-secret-` + rayCluster.Name,
-				},
-			},
-		},
-		{
-			Name: "server-cert",
-			VolumeSource: corev1.VolumeSource{
-				EmptyDir: &corev1.EmptyDirVolumeSource{},
-			},
-		},
+// validatePrivilegedPod prohibits the hostPath volumes, the host network and PID namespaces, and the privileged
+// containers, in the pod templates of the head and worker groups, unless the namespace is a privileged namespace.
+func validatePrivilegedPod(rayCluster *rayv1.RayCluster, cfg *config.KubeRayConfiguration) field.ErrorList {
+	var allErrors field.ErrorList
+
+	if slices.Contains(cfg.PrivilegedNamespaces, rayCluster.Namespace) {
+		return allErrors
 	}
+	allErrors = append(allErrors, validatePrivilegedPodSpec(&rayCluster.Spec.HeadGroupSpec.Template.Spec,
+		field.NewPath("spec", "headGroupSpec", "template", "spec"))...)
+	for i := range rayCluster.Spec.WorkerGroupSpecs {
+		allErrors = append(allErrors, validatePrivilegedPodSpec(&rayCluster.Spec.WorkerGroupSpecs[i].Template.Spec,
+			field.NewPath("spec", "workerGroupSpecs", strconv.Itoa(i), "template", "spec"))...)
+	}
+
+	return allErrors
 }
 
-func rayHeadInitContainer(rayCluster *rayv1.RayCluster, config *config.KubeRayConfiguration) corev1.Container {
-	rayClientRoute := "rayclient-" + rayCluster.Name + "-" + rayCluster.Namespace + "." + config.IngressDomain
-	// Service name for basic interactive
-	svcDomain := rayCluster.Name + "-head-svc." + rayCluster.Namespace + ".svc"
-
-	initContainerHead := corev1.Container{
-		Name:  "create-cert",
-		Image: config.CertGeneratorImage,
-		Command: []string{
-			"sh",
-			"-c",
-			`cd /home/ray/workspace/tls && openssl req -nodes -newkey rsa:2048 -keyout server.key -out server.csr -subj '/CN=ray-head' && printf "authorityKeyIdentifier=keyid,issuer\nbasicConstraints=CA:FALSE\nsubjectAltName = @alt_names\n[alt_names]\nDNS.1 = 127.0.0.1\nDNS.2 = localhost\nDNS.3 = ${FQ_RAY_IP}\nDNS.4 = $(awk 'END{print $1}' /etc/hosts)\nDNS.5 = ` + rayClientRoute + `\nDNS.6 = ` + svcDomain + `">./domain.ext && cp /home/ray/workspace/ca/* . && openssl x509 -req -CA ca.crt -CAkey ca.key -in server.csr -out server.crt -days 365 -CAcreateserial -extfile domain.ext`,
-		},
-		VolumeMounts: certVolumeMounts(),
-	}
-	return initContainerHead
+func validatePrivilegedPodSpec(spec *corev1.PodSpec, path *field.Path) field.ErrorList {
+	var allErrors field.ErrorList
+
+	if spec.HostNetwork {
+		allErrors = append(allErrors, field.Forbidden(path.Child("hostNetwork"), "the host network is not allowed"))
+	}
+	if spec.HostPID {
+		allErrors = append(allErrors, field.Forbidden(path.Child("hostPID"), "the host PID namespace is not allowed"))
+	}
+	for i, volume := range spec.Volumes {
+		if volume.HostPath != nil {
+			allErrors = append(allErrors, field.Forbidden(path.Child("volumes", strconv.Itoa(i), "hostPath"),
+				fmt.Sprintf("hostPath volume %s is not allowed", volume.Name)))
+		}
+	}
+	for _, containers := range []struct {
+		name       string
+		containers []corev1.Container
+	}{{"initContainers", spec.InitContainers}, {"containers", spec.Containers}} {
+		for i, container := range containers.containers {
+			if container.SecurityContext != nil && ptr.Deref(container.SecurityContext.Privileged, false) {
+				allErrors = append(allErrors, field.Forbidden(path.Child(containers.name, strconv.Itoa(i), "securityContext", "privileged"),
+					fmt.Sprintf("privileged container %s is not allowed", container.Name)))
+			}
+		}
+	}
+
+	return allErrors
 }
 
-func rayWorkerInitContainer(config *config.KubeRayConfiguration) corev1.Container {
-	initContainerWorker := corev1.Container{
-		Name:  "create-cert",
-		Image: config.CertGeneratorImage,
-		Command: []string{
-			"sh",
-			"-c",
-			`cd /home/ray/workspace/tls && openssl req -nodes -newkey rsa:2048 -keyout server.key -out server.csr -subj '/CN=ray-head' && printf "authorityKeyIdentifier=keyid,issuer\nbasicConstraints=CA:FALSE\nsubjectAltName = @alt_names\n[alt_names]\nDNS.1 = 127.0.0.1\nDNS.2 = localhost\nDNS.3 = ${FQ_RAY_IP}\nDNS.4 = $(awk 'END{print $1}' /etc/hosts)">./domain.ext && cp /home/ray/workspace/ca/* . && openssl x509 -req -CA ca.crt -CAkey ca.key -in server.csr -out server.crt -days 365 -CAcreateserial -extfile domain.ext`,
-		},
-		VolumeMounts: certVolumeMounts(),
-	}
-	return initContainerWorker
+func validateHeadGroupServiceAccountName(rayCluster *rayv1.RayCluster) field.ErrorList {
+	var allErrors field.ErrorList
+
+	if rayCluster.Spec.HeadGroupSpec.Template.Spec.ServiceAccountName != rayCluster.Name+"-oauth-proxy" {
+		allErrors = append(allErrors, field.Invalid(
+			field.NewPath("spec", "headGroupSpec", "template", "spec", "serviceAccountName"),
+			rayCluster.Spec.HeadGroupSpec.Template.Spec.ServiceAccountName,
+			"RayCluster head group service account is immutable"))
+	}
+
+	return allErrors
 }
 
-func validateHeadInitContainer(rayCluster *rayv1.RayCluster, config *config.KubeRayConfiguration) field.ErrorList {
+// validateHeadInitContainer checks the create-cert init container of the head group is the one the operator defaults,
+// or, for the RayClusters created by a previous version of the operator, that it's left unchanged.
+func validateHeadInitContainer(rayCluster, oldRayCluster *rayv1.RayCluster, config *config.KubeRayConfiguration) field.ErrorList {
 	var allErrors field.ErrorList
 
-	if err := contains(rayCluster.Spec.HeadGroupSpec.Template.Spec.InitContainers, rayHeadInitContainer(rayCluster, config), byContainerName,
+	if err := containsOrUnchanged(rayCluster.Spec.HeadGroupSpec.Template.Spec.InitContainers, oldRayCluster.Spec.HeadGroupSpec.Template.Spec.InitContainers,
+		defaults.RayHeadInitContainer(rayCluster, config), byContainerName,
 		field.NewPath("spec", "headGroupSpec", "template", "spec", "initContainers"),
 		"create-cert Init Container is immutable"); err != nil {
 		allErrors = append(allErrors, err)
@@ -386,12 +467,16 @@ func validateHeadInitContainer(rayCluster *rayv1.RayCluster, config *config.Kube
 	return allErrors
 }
 
-func validateWorkerInitContainer(rayCluster *rayv1.RayCluster, config *config.KubeRayConfiguration) field.ErrorList {
+func validateWorkerInitContainer(rayCluster, oldRayCluster *rayv1.RayCluster, config *config.KubeRayConfiguration) field.ErrorList {
 	var allErrors field.ErrorList
 
 	for i := range rayCluster.Spec.WorkerGroupSpecs {
 		workerSpec := &rayCluster.Spec.WorkerGroupSpecs[i]
-		if err := contains(workerSpec.Template.Spec.InitContainers, rayWorkerInitContainer(config), byContainerName,
+		var oldInitContainers []corev1.Container
+		if oldWorkerSpec := workerGroupSpec(oldRayCluster, workerSpec.GroupName); oldWorkerSpec != nil {
+			oldInitContainers = oldWorkerSpec.Template.Spec.InitContainers
+		}
+		if err := containsOrUnchanged(workerSpec.Template.Spec.InitContainers, oldInitContainers, defaults.RayWorkerInitContainer(config), byContainerName,
 			field.NewPath("spec", "workerGroupSpecs", strconv.Itoa(i), "template", "spec", "initContainers"),
 			"create-cert Init Container is immutable"); err != nil {
 			allErrors = append(allErrors, err)
@@ -401,10 +486,20 @@ func validateWorkerInitContainer(rayCluster *rayv1.RayCluster, config *config.Ku
 	return allErrors
 }
 
+// workerGroupSpec returns the worker group of the RayCluster with the name, or nil if there's none.
+func workerGroupSpec(rayCluster *rayv1.RayCluster, groupName string) *rayv1.WorkerGroupSpec {
+	for i := range rayCluster.Spec.WorkerGroupSpecs {
+		if rayCluster.Spec.WorkerGroupSpecs[i].GroupName == groupName {
+			return &rayCluster.Spec.WorkerGroupSpecs[i]
+		}
+	}
+	return nil
+}
+
 func validateCaVolumes(rayCluster *rayv1.RayCluster) field.ErrorList {
 	var allErrors field.ErrorList
 
-	for _, caVol := range caVolumes(rayCluster) {
+	for _, caVol := range defaults.CAVolumes(rayCluster) {
 		if err := contains(rayCluster.Spec.HeadGroupSpec.Template.Spec.Volumes, caVol, byVolumeName,
 			field.NewPath("spec", "headGroupSpec", "template", "spec", "volumes"),
 			"ca-vol and server-cert Secret volumes are immutable"); err != nil {
@@ -426,7 +521,7 @@ func validateCaVolumes(rayCluster *rayv1.RayCluster) field.ErrorList {
 func validateHeadEnvVars(rayCluster *rayv1.RayCluster) field.ErrorList {
 	var allErrors field.ErrorList
 
-	for _, envVar := range envVarList() {
+	for _, envVar := range defaults.TLSEnvVars() {
 		if err := contains(rayCluster.Spec.HeadGroupSpec.Template.Spec.Containers[0].Env, envVar, byEnvVarName,
 			field.NewPath("spec", "headGroupSpec", "template", "spec", "containers", strconv.Itoa(0), "env"),
 			"RAY_TLS related environment variables are immutable"); err != nil {
@@ -442,7 +537,7 @@ func validateWorkerEnvVars(rayCluster *rayv1.RayCluster) field.ErrorList {
 
 	for i := range rayCluster.Spec.WorkerGroupSpecs {
 		workerSpec := &rayCluster.Spec.WorkerGroupSpecs[i]
-		for _, envVar := range envVarList() {
+		for _, envVar := range defaults.TLSEnvVars() {
 			if err := contains(workerSpec.Template.Spec.Containers[0].Env, envVar, byEnvVarName,
 				field.NewPath("spec", "workerGroupSpecs", strconv.Itoa(i), "template", "spec", "containers", strconv.Itoa(0), "env"),
 				"RAY_TLS related environment variables are immutable"); err != nil {
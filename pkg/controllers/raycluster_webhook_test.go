@@ -21,13 +21,16 @@ import (
 
 	. "github.com/onsi/gomega"
 	"github.com/project-codeflare/codeflare-common/support"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
 
 	"github.com/project-codeflare/codeflare-operator/pkg/config"
+	"github.com/project-codeflare/codeflare-operator/pkg/defaults"
 )
 
 var (
@@ -103,7 +106,7 @@ func TestRayClusterWebhookDefault(t *testing.T) {
 			To(
 				And(
 					HaveLen(1),
-					ContainElement(WithTransform(support.ResourceName, Equal(oauthProxyContainerName))),
+					ContainElement(WithTransform(support.ResourceName, Equal(defaults.OAuthProxyContainerName))),
 				),
 				"Expected the OAuth proxy container to be present in the head group",
 			)
@@ -114,7 +117,7 @@ func TestRayClusterWebhookDefault(t *testing.T) {
 			To(
 				And(
 					HaveLen(3),
-					ContainElement(WithTransform(support.ResourceName, Equal(oauthProxyVolumeName))),
+					ContainElement(WithTransform(support.ResourceName, Equal(defaults.OAuthProxyVolumeName))),
 					ContainElement(WithTransform(support.ResourceName, Equal("ca-vol"))),
 					ContainElement(WithTransform(support.ResourceName, Equal("server-cert"))),
 				),
@@ -149,7 +152,7 @@ func TestRayClusterWebhookDefault(t *testing.T) {
 			To(
 				And(
 					HaveLen(1),
-					ContainElement(WithTransform(support.ResourceName, Equal(initContainerName))),
+					ContainElement(WithTransform(support.ResourceName, Equal(defaults.CreateCertInitContainerName))),
 				),
 				"Expected the create-cert init container to be present in the head group",
 			)
@@ -160,7 +163,7 @@ func TestRayClusterWebhookDefault(t *testing.T) {
 			To(
 				And(
 					HaveLen(3),
-					ContainElement(WithTransform(support.ResourceName, Equal(oauthProxyVolumeName))),
+					ContainElement(WithTransform(support.ResourceName, Equal(defaults.OAuthProxyVolumeName))),
 					ContainElement(WithTransform(support.ResourceName, Equal("ca-vol"))),
 					ContainElement(WithTransform(support.ResourceName, Equal("server-cert"))),
 				),
@@ -219,13 +222,28 @@ func TestRayClusterWebhookDefault(t *testing.T) {
 				To(
 					And(
 						HaveLen(1),
-						ContainElement(WithTransform(support.ResourceName, Equal(initContainerName))),
+						ContainElement(WithTransform(support.ResourceName, Equal(defaults.CreateCertInitContainerName))),
 					),
 					"Expected the required init container to be present in each worker group",
 				)
 		}
 	})
 
+	t.Run("Negative: RayClusters outside the cache scope are not defaulted", func(t *testing.T) {
+		scope, err := newCacheScope(&config.CacheConfiguration{Namespaces: []string{"other-namespace"}})
+		test.Expect(err).NotTo(HaveOccurred())
+		scopedWebhook := &rayClusterWebhook{Config: &config.KubeRayConfiguration{}, Scope: scope}
+
+		rayCluster := &rayv1.RayCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: rayClusterName, Namespace: namespace},
+			Spec: rayv1.RayClusterSpec{
+				HeadGroupSpec: rayv1.HeadGroupSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{}}}},
+			},
+		}
+		test.Expect(scopedWebhook.Default(test.Ctx(), rayCluster)).To(Succeed())
+		test.Expect(rayCluster.Spec.HeadGroupSpec.Template.Spec.Containers).To(BeEmpty())
+	})
+
 }
 
 func TestValidateCreate(t *testing.T) {
@@ -242,7 +260,7 @@ func TestValidateCreate(t *testing.T) {
 					Spec: corev1.PodSpec{
 						Containers: []corev1.Container{
 							{
-								Name:  oauthProxyContainerName,
+								Name:  defaults.OAuthProxyContainerName,
 								Image: "registry.redhat.io/openshift4/ose-oauth-proxy@sha256:1ea6a01bf3e63cdcf125c6064cbd4a4a270deaf0f157b3eabb78f60556840366",
 								Ports: []corev1.ContainerPort{
 									{ContainerPort: 8443, Name: "oauth-proxy"},
@@ -272,7 +290,7 @@ func TestValidateCreate(t *testing.T) {
 								},
 								VolumeMounts: []corev1.VolumeMount{
 									{
-										Name:      oauthProxyVolumeName,
+										Name:      defaults.OAuthProxyVolumeName,
 										MountPath: "/etc/tls/private",
 										ReadOnly:  true,
 									},
@@ -281,7 +299,7 @@ func TestValidateCreate(t *testing.T) {
 						},
 						Volumes: []corev1.Volume{
 							{
-								Name: oauthProxyVolumeName,
+								Name: defaults.OAuthProxyVolumeName,
 								VolumeSource: corev1.VolumeSource{
 									Secret: &corev1.SecretVolumeSource{
 										SecretName: rayClusterName + "-proxy-tls-secret",
@@ -320,7 +338,7 @@ func TestValidateCreate(t *testing.T) {
 
 	t.Run("Negative: Expected errors on call to ValidateCreate function due to manipulated OAuth Proxy Container", func(t *testing.T) {
 		for i, headContainer := range invalidRayCluster.Spec.HeadGroupSpec.Template.Spec.Containers {
-			if headContainer.Name == oauthProxyContainerName {
+			if headContainer.Name == defaults.OAuthProxyContainerName {
 				invalidRayCluster.Spec.HeadGroupSpec.Template.Spec.Containers[i].Args = []string{"--invalid-arg"}
 				break
 			}
@@ -331,7 +349,7 @@ func TestValidateCreate(t *testing.T) {
 
 	t.Run("Negative: Expected errors on call to ValidateCreate function due to manipulated OAuth Proxy Volume", func(t *testing.T) {
 		for i, headVolume := range invalidRayCluster.Spec.HeadGroupSpec.Template.Spec.Volumes {
-			if headVolume.Name == oauthProxyVolumeName {
+			if headVolume.Name == defaults.OAuthProxyVolumeName {
 				invalidRayCluster.Spec.HeadGroupSpec.Template.Spec.Volumes[i].Secret.SecretName = "invalid-secret-name"
 				break
 			}
@@ -346,6 +364,25 @@ func TestValidateCreate(t *testing.T) {
 		test.Expect(err).Should(HaveOccurred(), "Expected errors on call to ValidateCreate function due to manipulated head group service account name")
 	})
 
+	t.Run("Negative: Expected errors on call to ValidateCreate function due to invalid S3 Secret name", func(t *testing.T) {
+		invalidS3Secret := validRayCluster.DeepCopy()
+		invalidS3Secret.Annotations = map[string]string{defaults.S3SecretAnnotation: "Data_Connection"}
+		_, err := rcWebhook.ValidateCreate(test.Ctx(), runtime.Object(invalidS3Secret))
+		test.Expect(err).Should(MatchError(ContainSubstring(defaults.S3SecretAnnotation)), "Expected errors on call to ValidateCreate function due to invalid S3 Secret name")
+	})
+
+	t.Run("Negative: Expected errors on call to ValidateCreate function due to invalid scratch volume size", func(t *testing.T) {
+		scratchVolumeWebhook := &rayClusterWebhook{
+			Config: &config.KubeRayConfiguration{ScratchVolume: &config.ScratchVolumeConfiguration{Enabled: ptr.To(true)}},
+		}
+		invalidScratchVolume := validRayCluster.DeepCopy()
+		invalidScratchVolume.Annotations = map[string]string{
+			defaults.ScratchVolumeAnnotation:     "true",
+			defaults.ScratchVolumeSizeAnnotation: "lots",
+		}
+		_, err := scratchVolumeWebhook.ValidateCreate(test.Ctx(), runtime.Object(invalidScratchVolume))
+		test.Expect(err).Should(MatchError(ContainSubstring(defaults.ScratchVolumeSizeAnnotation)), "Expected errors on call to ValidateCreate function due to invalid scratch volume size")
+	})
 }
 
 func TestValidateUpdate(t *testing.T) {
@@ -362,7 +399,7 @@ func TestValidateUpdate(t *testing.T) {
 					Spec: corev1.PodSpec{
 						Containers: []corev1.Container{
 							{
-								Name:  oauthProxyContainerName,
+								Name:  defaults.OAuthProxyContainerName,
 								Image: "registry.redhat.io/openshift4/ose-oauth-proxy@sha256:1ea6a01bf3e63cdcf125c6064cbd4a4a270deaf0f157b3eabb78f60556840366",
 								Ports: []corev1.ContainerPort{
 									{ContainerPort: 8443, Name: "oauth-proxy"},
@@ -404,7 +441,7 @@ func TestValidateUpdate(t *testing.T) {
 								},
 								VolumeMounts: []corev1.VolumeMount{
 									{
-										Name:      oauthProxyVolumeName,
+										Name:      defaults.OAuthProxyVolumeName,
 										MountPath: "/etc/tls/private",
 										ReadOnly:  true,
 									},
@@ -418,7 +455,17 @@ func TestValidateUpdate(t *testing.T) {
 								Command: []string{
 									"sh",
 									"-c",
-									`cd /home/ray/workspace/tls && openssl req -nodes -newkey rsa:2048 -keyout server.key -out server.csr -subj '/CN=ray-head' && printf "authorityKeyIdentifier=keyid,issuer\nbasicConstraints=CA:FALSE\nsubjectAltName = @alt_names\n[alt_names]\nDNS.1 = 127.0.0.1\nDNS.2 = localhost\nDNS.3 = ${FQ_RAY_IP}\nDNS.4 = $(awk 'END{print $1}' /etc/hosts)\nDNS.5 = rayclient-` + rayClusterName + `-` + namespace + `.\nDNS.6 = ` + rayClusterName + `-head-svc.` + namespace + `.svc` + `">./domain.ext && cp /home/ray/workspace/ca/* . && openssl x509 -req -CA ca.crt -CAkey ca.key -in server.csr -out server.crt -days 365 -CAcreateserial -extfile domain.ext`,
+									`cd /home/ray/workspace/tls && openssl req -nodes -newkey rsa:2048 -keyout server.key -out server.csr -subj '/CN=ray-head' && printf "authorityKeyIdentifier=keyid,issuer\nbasicConstraints=CA:FALSE\nsubjectAltName = @alt_names\n[alt_names]\nDNS.1 = localhost\nDNS.2 = ${FQ_RAY_IP}\nDNS.3 = rayclient-` + rayClusterName + `-` + namespace + `.\nDNS.4 = ` + rayClusterName + `-head-svc.` + namespace + `.svc` + `\nIP.1 = 127.0.0.1\nIP.2 = ::1\n">./domain.ext && i=3 && for ip in $(echo "${MY_POD_IPS}" | tr ',' ' '); do echo "IP.${i} = ${ip}">>./domain.ext; i=$((i+1)); done && cp /home/ray/workspace/ca/* . && openssl x509 -req -CA ca.crt -CAkey ca.key -in server.csr -out server.crt -days 365 -CAcreateserial -extfile domain.ext`,
+								},
+								Env: []corev1.EnvVar{
+									{
+										Name: "MY_POD_IPS",
+										ValueFrom: &corev1.EnvVarSource{
+											FieldRef: &corev1.ObjectFieldSelector{
+												FieldPath: "status.podIPs",
+											},
+										},
+									},
 								},
 								VolumeMounts: []corev1.VolumeMount{
 									{
@@ -436,7 +483,7 @@ func TestValidateUpdate(t *testing.T) {
 						},
 						Volumes: []corev1.Volume{
 							{
-								Name: oauthProxyVolumeName,
+								Name: defaults.OAuthProxyVolumeName,
 								VolumeSource: corev1.VolumeSource{
 									Secret: &corev1.SecretVolumeSource{
 										SecretName: rayClusterName + "-proxy-tls-secret",
@@ -494,9 +541,19 @@ func TestValidateUpdate(t *testing.T) {
 									Command: []string{
 										"sh",
 										"-c",
-										`cd /home/ray/workspace/tls && openssl req -nodes -newkey rsa:2048 -keyout server.key -out server.csr -subj '/CN=ray-head' && printf "authorityKeyIdentifier=keyid,issuer\nbasicConstraints=CA:FALSE\nsubjectAltName = @alt_names\n[alt_names]\nDNS.1 = 127.0.0.1\nDNS.2 = localhost\nDNS.3 = ${FQ_RAY_IP}\nDNS.4 = $(awk 'END{print $1}' /etc/hosts)">./domain.ext && cp /home/ray/workspace/ca/* . && openssl x509 -req -CA ca.crt -CAkey ca.key -in server.csr -out server.crt -days 365 -CAcreateserial -extfile domain.ext`,
+										`cd /home/ray/workspace/tls && openssl req -nodes -newkey rsa:2048 -keyout server.key -out server.csr -subj '/CN=ray-head' && printf "authorityKeyIdentifier=keyid,issuer\nbasicConstraints=CA:FALSE\nsubjectAltName = @alt_names\n[alt_names]\nDNS.1 = localhost\nDNS.2 = ${FQ_RAY_IP}\nIP.1 = 127.0.0.1\nIP.2 = ::1\n">./domain.ext && i=3 && for ip in $(echo "${MY_POD_IPS}" | tr ',' ' '); do echo "IP.${i} = ${ip}">>./domain.ext; i=$((i+1)); done && cp /home/ray/workspace/ca/* . && openssl x509 -req -CA ca.crt -CAkey ca.key -in server.csr -out server.crt -days 365 -CAcreateserial -extfile domain.ext`,
+									},
+									Env: []corev1.EnvVar{
+										{
+											Name: "MY_POD_IPS",
+											ValueFrom: &corev1.EnvVarSource{
+												FieldRef: &corev1.ObjectFieldSelector{
+													FieldPath: "status.podIPs",
+												},
+											},
+										},
 									},
-									VolumeMounts: certVolumeMounts(),
+									VolumeMounts: defaults.CertVolumeMounts(),
 								},
 							},
 							Volumes: []corev1.Volume{
@@ -535,6 +592,54 @@ func TestValidateUpdate(t *testing.T) {
 		test.Expect(err).ShouldNot(HaveOccurred(), "Expected no errors on call to ValidateUpdate function")
 	})
 
+	t.Run("Expected RayCluster with the init containers of a previous operator version to remain updatable", func(t *testing.T) {
+		legacyRayCluster := validRayCluster.DeepCopy()
+		legacyCommand := []string{
+			"sh",
+			"-c",
+			`cd /home/ray/workspace/tls && openssl req -nodes -newkey rsa:2048 -keyout server.key -out server.csr -subj '/CN=ray-head' && printf "authorityKeyIdentifier=keyid,issuer\nbasicConstraints=CA:FALSE\nsubjectAltName = @alt_names\n[alt_names]\nDNS.1 = 127.0.0.1\nDNS.2 = localhost\nDNS.3 = ${FQ_RAY_IP}\nDNS.4 = $(awk 'END{print $1}' /etc/hosts)">./domain.ext && cp /home/ray/workspace/ca/* . && openssl x509 -req -CA ca.crt -CAkey ca.key -in server.csr -out server.crt -days 365 -CAcreateserial -extfile domain.ext`,
+		}
+		for _, podSpec := range []*corev1.PodSpec{&legacyRayCluster.Spec.HeadGroupSpec.Template.Spec, &legacyRayCluster.Spec.WorkerGroupSpecs[0].Template.Spec} {
+			podSpec.InitContainers[0].Command = legacyCommand
+			podSpec.InitContainers[0].Env = nil
+		}
+
+		suspended := legacyRayCluster.DeepCopy()
+		suspended.Spec.Suspend = ptr.To(true)
+		_, err := rcWebhook.ValidateUpdate(test.Ctx(), runtime.Object(legacyRayCluster), runtime.Object(suspended))
+		test.Expect(err).ShouldNot(HaveOccurred())
+
+		// The legacy init containers can only be migrated to the current ones
+		manipulated := legacyRayCluster.DeepCopy()
+		manipulated.Spec.WorkerGroupSpecs[0].Template.Spec.InitContainers[0].Command = []string{"manipulated command"}
+		_, err = rcWebhook.ValidateUpdate(test.Ctx(), runtime.Object(legacyRayCluster), runtime.Object(manipulated))
+		test.Expect(err).Should(HaveOccurred())
+		_, err = rcWebhook.ValidateUpdate(test.Ctx(), runtime.Object(legacyRayCluster), runtime.Object(validRayCluster))
+		test.Expect(err).ShouldNot(HaveOccurred())
+	})
+
+	t.Run("Expected RayCluster to remain updatable once the OAuth proxy configuration has changed", func(t *testing.T) {
+		reconfiguredWebhook := &rayClusterWebhook{
+			Config: &config.KubeRayConfiguration{
+				DashboardHardening: &config.DashboardHardeningConfiguration{CookieSameSite: "Strict"},
+			},
+		}
+
+		suspended := validRayCluster.DeepCopy()
+		suspended.Spec.Suspend = ptr.To(true)
+		_, err := reconfiguredWebhook.ValidateUpdate(test.Ctx(), runtime.Object(validRayCluster), runtime.Object(suspended))
+		test.Expect(err).ShouldNot(HaveOccurred())
+
+		manipulated := validRayCluster.DeepCopy()
+		for i, headContainer := range manipulated.Spec.HeadGroupSpec.Template.Spec.Containers {
+			if headContainer.Name == defaults.OAuthProxyContainerName {
+				manipulated.Spec.HeadGroupSpec.Template.Spec.Containers[i].Args = []string{"--invalid-arg"}
+			}
+		}
+		_, err = reconfiguredWebhook.ValidateUpdate(test.Ctx(), runtime.Object(validRayCluster), runtime.Object(manipulated))
+		test.Expect(err).Should(HaveOccurred())
+	})
+
 	// Negative Test Cases
 	trueBool := true
 	invalidRayCluster := validRayCluster.DeepCopy()
@@ -547,7 +652,7 @@ func TestValidateUpdate(t *testing.T) {
 
 	t.Run("Negative: Expected errors on call to ValidateUpdate function due to manipulated OAuth Proxy Container", func(t *testing.T) {
 		for i, headContainer := range invalidRayCluster.Spec.HeadGroupSpec.Template.Spec.Containers {
-			if headContainer.Name == oauthProxyContainerName {
+			if headContainer.Name == defaults.OAuthProxyContainerName {
 				invalidRayCluster.Spec.HeadGroupSpec.Template.Spec.Containers[i].Args = []string{"--invalid-arg"}
 				break
 			}
@@ -558,7 +663,7 @@ func TestValidateUpdate(t *testing.T) {
 
 	t.Run("Negative: Expected errors on call to ValidateUpdate function due to manipulated OAuth Proxy Volume", func(t *testing.T) {
 		for i, headVolume := range invalidRayCluster.Spec.HeadGroupSpec.Template.Spec.Volumes {
-			if headVolume.Name == oauthProxyVolumeName {
+			if headVolume.Name == defaults.OAuthProxyVolumeName {
 				invalidRayCluster.Spec.HeadGroupSpec.Template.Spec.Volumes[i].Secret.SecretName = "invalid-secret-name"
 				break
 			}
@@ -645,3 +750,310 @@ func TestValidateUpdate(t *testing.T) {
 		test.Expect(err).Should(HaveOccurred(), "Expected errors on call to ValidateUpdate function due to manipulated env vars in the worker group")
 	})
 }
+
+func TestValidateRayVersionSkew(t *testing.T) {
+	test := support.NewTest(t)
+
+	rayCluster := &rayv1.RayCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      rayClusterName,
+			Namespace: namespace,
+		},
+		Spec: rayv1.RayClusterSpec{
+			HeadGroupSpec: rayv1.HeadGroupSpec{
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{
+							{
+								Name:  "ray-head",
+								Image: "quay.io/project-codeflare/ray:2.20.0-py39-cu118",
+							},
+						},
+					},
+				},
+				RayStartParams: map[string]string{},
+			},
+			WorkerGroupSpecs: []rayv1.WorkerGroupSpec{
+				{
+					GroupName: "worker-group-1",
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name:  "ray-worker",
+									Image: "quay.io/project-codeflare/ray:2.20.0-py39-cu118",
+								},
+							},
+						},
+					},
+					RayStartParams: map[string]string{},
+				},
+			},
+		},
+	}
+
+	t.Run("Expected Ray version to be parsed from image tags", func(t *testing.T) {
+		test.Expect(rayVersionFromImage("quay.io/project-codeflare/ray:2.20.0-py39-cu118")).To(Equal("2.20.0"))
+		test.Expect(rayVersionFromImage("rayproject/ray:2.9.3")).To(Equal("2.9.3"))
+		test.Expect(rayVersionFromImage("localhost:5000/ray:2.9.3@sha256:abc")).To(Equal("2.9.3"))
+		test.Expect(rayVersionFromImage("localhost:5000/ray")).To(BeEmpty())
+		test.Expect(rayVersionFromImage("rayproject/ray:latest")).To(BeEmpty())
+		test.Expect(rayVersionFromImage("rayproject/ray@sha256:abc")).To(BeEmpty())
+	})
+
+	t.Run("Expected no errors for consistent Ray versions", func(t *testing.T) {
+		test.Expect(validateRayVersionSkew(rayCluster)).To(BeEmpty())
+	})
+
+	t.Run("Expected no errors when the Ray version cannot be determined", func(t *testing.T) {
+		unknownVersion := rayCluster.DeepCopy()
+		unknownVersion.Spec.WorkerGroupSpecs[0].Template.Spec.Containers[0].Image = "quay.io/project-codeflare/ray:latest"
+		test.Expect(validateRayVersionSkew(unknownVersion)).To(BeEmpty())
+	})
+
+	skewedRayCluster := rayCluster.DeepCopy()
+	skewedRayCluster.Spec.WorkerGroupSpecs[0].Template.Spec.Containers[0].Image = "quay.io/project-codeflare/ray:2.9.0-py39-cu118"
+
+	t.Run("Expected warnings on call to ValidateCreate function due to Ray version skew", func(t *testing.T) {
+		warningWebhook := &rayClusterWebhook{
+			Config: &config.KubeRayConfiguration{
+				RayDashboardOAuthEnabled: support.Ptr(false),
+			},
+		}
+		warnings, err := warningWebhook.ValidateCreate(test.Ctx(), runtime.Object(skewedRayCluster))
+		test.Expect(warnings).To(HaveLen(1))
+		test.Expect(err).ShouldNot(HaveOccurred())
+	})
+
+	t.Run("Negative: Expected errors on call to ValidateCreate function due to Ray version skew", func(t *testing.T) {
+		rejectingWebhook := &rayClusterWebhook{
+			Config: &config.KubeRayConfiguration{
+				RayDashboardOAuthEnabled: support.Ptr(false),
+				RejectRayVersionSkew:     support.Ptr(true),
+			},
+		}
+		warnings, err := rejectingWebhook.ValidateCreate(test.Ctx(), runtime.Object(skewedRayCluster))
+		test.Expect(warnings).To(BeEmpty())
+		test.Expect(err).Should(HaveOccurred())
+	})
+
+	t.Run("Expected RayClusters with Ray version skew to remain updatable until their images change", func(t *testing.T) {
+		rejectingWebhook := &rayClusterWebhook{
+			Config: &config.KubeRayConfiguration{
+				RayDashboardOAuthEnabled: support.Ptr(false),
+				MTLSEnabled:              support.Ptr(false),
+				RejectRayVersionSkew:     support.Ptr(true),
+			},
+		}
+		suspended := skewedRayCluster.DeepCopy()
+		suspended.Spec.Suspend = support.Ptr(true)
+		_, err := rejectingWebhook.ValidateUpdate(test.Ctx(), runtime.Object(skewedRayCluster), runtime.Object(suspended))
+		test.Expect(err).ShouldNot(HaveOccurred())
+
+		_, err = rejectingWebhook.ValidateUpdate(test.Ctx(), runtime.Object(rayCluster), runtime.Object(skewedRayCluster))
+		test.Expect(err).Should(HaveOccurred())
+	})
+
+	t.Run("Expected Ray version skew to be admitted and recorded in audit mode", func(t *testing.T) {
+		auditingWebhook := &rayClusterWebhook{
+			Config: &config.KubeRayConfiguration{
+				RayDashboardOAuthEnabled: support.Ptr(false),
+				RejectRayVersionSkew:     support.Ptr(true),
+				AdmissionPolicyMode:      config.AuditAdmissionPolicyMode,
+			},
+		}
+		violations := testutil.ToFloat64(policyViolations.WithLabelValues(rayVersionSkewPolicy, string(config.AuditAdmissionPolicyMode)))
+		warnings, err := auditingWebhook.ValidateCreate(test.Ctx(), runtime.Object(skewedRayCluster))
+		test.Expect(err).ShouldNot(HaveOccurred())
+		test.Expect(warnings).To(ConsistOf(HavePrefix("Audit: ")))
+		test.Expect(testutil.ToFloat64(policyViolations.WithLabelValues(rayVersionSkewPolicy, string(config.AuditAdmissionPolicyMode)))).To(Equal(violations + 1))
+	})
+}
+
+func TestValidatePrivilegedPod(t *testing.T) {
+	test := support.NewTest(t)
+
+	rayCluster := &rayv1.RayCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      rayClusterName,
+			Namespace: namespace,
+		},
+		Spec: rayv1.RayClusterSpec{
+			HeadGroupSpec: rayv1.HeadGroupSpec{
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{Name: "ray-head"}},
+					},
+				},
+				RayStartParams: map[string]string{},
+			},
+			WorkerGroupSpecs: []rayv1.WorkerGroupSpec{
+				{
+					GroupName: "worker-group-1",
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{{Name: "ray-worker"}},
+						},
+					},
+					RayStartParams: map[string]string{},
+				},
+			},
+		},
+	}
+
+	privilegedRayCluster := rayCluster.DeepCopy()
+	privilegedRayCluster.Spec.HeadGroupSpec.Template.Spec.HostNetwork = true
+	privilegedRayCluster.Spec.HeadGroupSpec.Template.Spec.Volumes = []corev1.Volume{{
+		Name:         "docker-socket",
+		VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: "/var/run/docker.sock"}},
+	}}
+	privilegedRayCluster.Spec.WorkerGroupSpecs[0].Template.Spec.HostPID = true
+	privilegedRayCluster.Spec.WorkerGroupSpecs[0].Template.Spec.InitContainers = []corev1.Container{{
+		Name:            "sysctl",
+		SecurityContext: &corev1.SecurityContext{Privileged: ptr.To(true)},
+	}}
+
+	webhook := &rayClusterWebhook{
+		Config: &config.KubeRayConfiguration{
+			RayDashboardOAuthEnabled: support.Ptr(false),
+			MTLSEnabled:              support.Ptr(false),
+		},
+	}
+
+	t.Run("Expected no errors for unprivileged pod templates", func(t *testing.T) {
+		test.Expect(validatePrivilegedPod(rayCluster, webhook.Config)).To(BeEmpty())
+	})
+
+	t.Run("Expected errors for the privileged settings of the head and worker pod templates", func(t *testing.T) {
+		test.Expect(validatePrivilegedPod(privilegedRayCluster, webhook.Config).ToAggregate().Errors()).To(ConsistOf(
+			MatchError(`spec.headGroupSpec.template.spec.hostNetwork: Forbidden: the host network is not allowed`),
+			MatchError(`spec.headGroupSpec.template.spec.volumes.0.hostPath: Forbidden: hostPath volume docker-socket is not allowed`),
+			MatchError(`spec.workerGroupSpecs.0.template.spec.hostPID: Forbidden: the host PID namespace is not allowed`),
+			MatchError(`spec.workerGroupSpecs.0.template.spec.initContainers.0.securityContext.privileged: Forbidden: privileged container sysctl is not allowed`),
+		))
+	})
+
+	t.Run("Negative: Expected errors on call to ValidateCreate function due to privileged pod settings", func(t *testing.T) {
+		_, err := webhook.ValidateCreate(test.Ctx(), runtime.Object(privilegedRayCluster))
+		test.Expect(err).Should(HaveOccurred())
+	})
+
+	t.Run("Expected privileged pod settings to be admitted in the privileged namespaces", func(t *testing.T) {
+		allowingWebhook := &rayClusterWebhook{
+			Config: &config.KubeRayConfiguration{
+				RayDashboardOAuthEnabled: support.Ptr(false),
+				PrivilegedNamespaces:     []string{namespace},
+			},
+		}
+		_, err := allowingWebhook.ValidateCreate(test.Ctx(), runtime.Object(privilegedRayCluster))
+		test.Expect(err).ShouldNot(HaveOccurred())
+	})
+
+	t.Run("Expected RayClusters created with privileged pod settings to remain updatable", func(t *testing.T) {
+		_, err := webhook.ValidateUpdate(test.Ctx(), runtime.Object(privilegedRayCluster), runtime.Object(privilegedRayCluster))
+		test.Expect(err).ShouldNot(HaveOccurred())
+	})
+
+	t.Run("Negative: Expected errors on call to ValidateUpdate function adding privileged pod settings", func(t *testing.T) {
+		_, err := webhook.ValidateUpdate(test.Ctx(), runtime.Object(rayCluster), runtime.Object(privilegedRayCluster))
+		test.Expect(err).Should(HaveOccurred())
+	})
+
+	t.Run("Expected privileged pod settings to be admitted and recorded in audit mode", func(t *testing.T) {
+		auditingWebhook := &rayClusterWebhook{
+			Config: &config.KubeRayConfiguration{
+				RayDashboardOAuthEnabled: support.Ptr(false),
+				AdmissionPolicyMode:      config.AuditAdmissionPolicyMode,
+			},
+		}
+		violations := testutil.ToFloat64(policyViolations.WithLabelValues(privilegedPodPolicy, string(config.AuditAdmissionPolicyMode)))
+		warnings, err := auditingWebhook.ValidateCreate(test.Ctx(), runtime.Object(privilegedRayCluster))
+		test.Expect(err).ShouldNot(HaveOccurred())
+		test.Expect(warnings).To(HaveLen(4))
+		test.Expect(testutil.ToFloat64(policyViolations.WithLabelValues(privilegedPodPolicy, string(config.AuditAdmissionPolicyMode)))).To(Equal(violations + 1))
+	})
+}
+
+func TestRayClusterWebhookDefaultRestrictedPodSecurity(t *testing.T) {
+	test := support.NewTest(t)
+
+	restrictedWebhook := &rayClusterWebhook{
+		Config: &config.KubeRayConfiguration{
+			RestrictedPodSecurityEnabled: support.Ptr(true),
+		},
+	}
+
+	rayCluster := &rayv1.RayCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      rayClusterName,
+			Namespace: namespace,
+		},
+		Spec: rayv1.RayClusterSpec{
+			HeadGroupSpec: rayv1.HeadGroupSpec{
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{
+							{
+								Name: "ray-head",
+								SecurityContext: &corev1.SecurityContext{
+									Capabilities: &corev1.Capabilities{
+										Drop: []corev1.Capability{"NET_RAW"},
+									},
+								},
+							},
+						},
+					},
+				},
+				RayStartParams: map[string]string{},
+			},
+			WorkerGroupSpecs: []rayv1.WorkerGroupSpec{
+				{
+					GroupName: "worker-group-1",
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "ray-worker",
+								},
+							},
+						},
+					},
+					RayStartParams: map[string]string{},
+				},
+			},
+		},
+	}
+
+	err := restrictedWebhook.Default(test.Ctx(), runtime.Object(rayCluster))
+	t.Run("Expected no errors on call to Default function", func(t *testing.T) {
+		test.Expect(err).ShouldNot(HaveOccurred(), "Expected no errors on call to Default function")
+	})
+
+	t.Run("Expected restricted pod security context for the head and worker groups", func(t *testing.T) {
+		podSpecs := []corev1.PodSpec{rayCluster.Spec.HeadGroupSpec.Template.Spec}
+		for _, workerGroup := range rayCluster.Spec.WorkerGroupSpecs {
+			podSpecs = append(podSpecs, workerGroup.Template.Spec)
+		}
+		for _, podSpec := range podSpecs {
+			test.Expect(podSpec.SecurityContext.RunAsNonRoot).To(Equal(support.Ptr(true)))
+			test.Expect(podSpec.SecurityContext.SeccompProfile.Type).To(Equal(corev1.SeccompProfileTypeRuntimeDefault))
+		}
+	})
+
+	t.Run("Expected restricted security context for all containers", func(t *testing.T) {
+		containers := append(rayCluster.Spec.HeadGroupSpec.Template.Spec.Containers, rayCluster.Spec.HeadGroupSpec.Template.Spec.InitContainers...)
+		for _, workerGroup := range rayCluster.Spec.WorkerGroupSpecs {
+			containers = append(containers, workerGroup.Template.Spec.Containers...)
+			containers = append(containers, workerGroup.Template.Spec.InitContainers...)
+		}
+		for _, container := range containers {
+			test.Expect(container.SecurityContext.AllowPrivilegeEscalation).To(Equal(support.Ptr(false)), container.Name)
+			test.Expect(container.SecurityContext.Capabilities.Drop).To(ContainElement(corev1.Capability("ALL")), container.Name)
+		}
+	})
+
+	t.Run("Expected user provided security context fields to be preserved, with ALL capabilities dropped", func(t *testing.T) {
+		test.Expect(rayCluster.Spec.HeadGroupSpec.Template.Spec.Containers[0].SecurityContext.Capabilities.Drop).
+			To(Equal([]corev1.Capability{"NET_RAW", "ALL"}))
+	})
+}
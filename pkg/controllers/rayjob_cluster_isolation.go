@@ -0,0 +1,148 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+	"golang.org/x/net/publicsuffix"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/project-codeflare/codeflare-operator/pkg/config"
+)
+
+// rayAddressEnvVars are the environment variables that set the address of the RayCluster the Ray CLI submits jobs to
+var rayAddressEnvVars = []string{"RAY_ADDRESS", "RAY_DASHBOARD_ADDRESS", "RAY_API_SERVER_ADDRESS"}
+
+// specialUseDomains are the reserved top-level domains, that aren't ICANN TLDs, and resolve outside the cluster
+var specialUseDomains = []string{"example", "internal", "invalid", "local", "localhost", "test"}
+
+// rayAddressFlag matches the address flag of the Ray CLI, e.g., ray job submit --address http://raycluster-head-svc:8265
+var rayAddressFlag = regexp.MustCompile(`--address(?:=|\s+)["']?([^\s"']+)`)
+
+// validateClusterSelector rejects the RayJobs that target a RayCluster of another namespace, other than the shared
+// cluster namespaces, either by selecting it, or by setting its address in the submitter pod.
+// The check of the addresses is best-effort: only the Ray address environment variables set by value, and the
+// --address flag of the submitter command, are checked, for hosts that are Service DNS names. IP addresses,
+// environment variables set from ConfigMaps or Secrets, and addresses set in the entrypoint, e.g., with
+// ray.init(address=...), are not covered, and must be restricted with NetworkPolicies.
+func validateClusterSelector(rayJob *rayv1.RayJob, cfg *config.KubeRayConfiguration) field.ErrorList {
+	var allErrors field.ErrorList
+
+	// KubeRay resolves the selected RayCluster by name, in the namespace of the RayJob
+	if len(rayJob.Spec.ClusterSelector) > 0 {
+		path := field.NewPath("spec", "clusterSelector")
+		if name, ok := rayJob.Spec.ClusterSelector[RayJobClusterSelectorKey]; !ok {
+			allErrors = append(allErrors, field.Required(path.Key(RayJobClusterSelectorKey),
+				"the RayCluster of the RayJob namespace must be selected by name"))
+		} else if msgs := validation.IsDNS1123Subdomain(name); len(msgs) > 0 {
+			allErrors = append(allErrors, field.Invalid(path.Key(RayJobClusterSelectorKey), name, strings.Join(msgs, ", ")))
+		}
+	}
+
+	if rayJob.Spec.SubmitterPodTemplate == nil {
+		return allErrors
+	}
+	for i, container := range rayJob.Spec.SubmitterPodTemplate.Spec.Containers {
+		path := field.NewPath("spec", "submitterPodTemplate", "spec", "containers", strconv.Itoa(i))
+		allErrors = append(allErrors, validateRayAddresses(rayJob, cfg, container, path)...)
+	}
+
+	return allErrors
+}
+
+func validateRayAddresses(rayJob *rayv1.RayJob, cfg *config.KubeRayConfiguration, container corev1.Container, path *field.Path) field.ErrorList {
+	var allErrors field.ErrorList
+
+	validateAddress := func(path *field.Path, address string) {
+		if namespace, ok := serviceNamespace(address); ok && namespace != rayJob.Namespace &&
+			!slices.Contains(cfg.SharedClusterNamespaces, namespace) {
+			allErrors = append(allErrors, field.Forbidden(path,
+				fmt.Sprintf("RayJob cannot target RayCluster %s of namespace %s", address, namespace)))
+		}
+	}
+
+	for i, env := range container.Env {
+		if slices.Contains(rayAddressEnvVars, env.Name) {
+			validateAddress(path.Child("env", strconv.Itoa(i), "value"), env.Value)
+		}
+	}
+	type argument struct {
+		path  *field.Path
+		value string
+	}
+	var args []argument
+	for i, value := range container.Command {
+		args = append(args, argument{path.Child("command", strconv.Itoa(i)), value})
+	}
+	for i, value := range container.Args {
+		args = append(args, argument{path.Child("args", strconv.Itoa(i)), value})
+	}
+	for i, arg := range args {
+		for _, match := range rayAddressFlag.FindAllStringSubmatch(arg.value, -1) {
+			validateAddress(arg.path, match[1])
+		}
+		// The flag and its value are separate arguments when the Ray CLI isn't run by a shell
+		if arg.value == "--address" && i+1 < len(args) {
+			validateAddress(args[i+1].path, args[i+1].value)
+		}
+	}
+
+	return allErrors
+}
+
+// serviceNamespace returns the namespace of the Service the address resolves to, when its host is the DNS name of
+// a Service of another namespace, i.e., <service>.<namespace>.svc[.<cluster-domain>], or <service>.<namespace>,
+// unless <namespace> isn't a valid namespace name, or is a top-level domain, e.g., myray.io.
+func serviceNamespace(address string) (string, bool) {
+	if _, rest, ok := strings.Cut(address, "://"); ok {
+		address = rest
+	}
+	host, _, _ := strings.Cut(address, "/")
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if net.ParseIP(host) != nil {
+		return "", false
+	}
+	labels := strings.Split(host, ".")
+	switch {
+	case len(labels) > 2 && labels[2] == "svc":
+		return labels[1], true
+	case len(labels) == 2 && len(validation.IsDNS1123Label(labels[1])) == 0 && !isTopLevelDomain(labels[1]):
+		return labels[1], true
+	}
+	return "", false
+}
+
+func isTopLevelDomain(domain string) bool {
+	if slices.Contains(specialUseDomains, domain) {
+		return true
+	}
+	suffix, icann := publicsuffix.PublicSuffix(domain)
+	return icann && suffix == domain
+}
@@ -0,0 +1,190 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/project-codeflare/codeflare-common/support"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/project-codeflare/codeflare-operator/pkg/config"
+)
+
+func TestValidateClusterSelector(t *testing.T) {
+	test := support.NewTest(t)
+
+	rjWebhook := &rayJobWebhook{
+		Config: &config.KubeRayConfiguration{SharedClusterNamespaces: []string{"shared"}},
+	}
+
+	newRayJob := func(clusterSelector map[string]string, container corev1.Container) *rayv1.RayJob {
+		return &rayv1.RayJob{
+			ObjectMeta: metav1.ObjectMeta{Name: "rayjob", Namespace: "tenant-a"},
+			Spec: rayv1.RayJobSpec{
+				ClusterSelector: clusterSelector,
+				SubmitterPodTemplate: &corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{Containers: []corev1.Container{container}},
+				},
+			},
+		}
+	}
+
+	t.Run("Expected RayJobs targeting RayClusters of their namespace, or of the shared namespaces, to be admitted", func(t *testing.T) {
+		rayJob := newRayJob(map[string]string{RayJobClusterSelectorKey: "raycluster"}, corev1.Container{
+			Name:    "ray-job-submitter",
+			Command: []string{"ray", "job", "submit", "--address", "http://raycluster-head-svc.tenant-a.svc.cluster.local:8265"},
+			Env: []corev1.EnvVar{
+				{Name: "RAY_DASHBOARD_ADDRESS", Value: "http://raycluster-head-svc:8265"},
+				{Name: "RAY_ADDRESS", Value: "ray://raycluster-head-svc.shared.svc:10001"},
+				{Name: "RAY_API_SERVER_ADDRESS", Value: "https://ray.example.com/api"},
+			},
+		})
+
+		_, err := rjWebhook.ValidateCreate(test.Ctx(), runtime.Object(rayJob))
+		test.Expect(err).ShouldNot(HaveOccurred())
+
+		_, err = rjWebhook.ValidateUpdate(test.Ctx(), runtime.Object(rayJob), runtime.Object(rayJob))
+		test.Expect(err).ShouldNot(HaveOccurred())
+	})
+
+	t.Run("Negative: Expected RayJobs whose ClusterSelector doesn't select a RayCluster by name to be rejected", func(t *testing.T) {
+		rayJob := newRayJob(map[string]string{"team": "tenant-b"}, corev1.Container{Name: "ray-job-submitter"})
+
+		_, err := rjWebhook.ValidateCreate(test.Ctx(), runtime.Object(rayJob))
+		test.Expect(err).To(MatchError(ContainSubstring("spec.clusterSelector[ray.io/cluster]: Required value")))
+
+		rayJob = newRayJob(map[string]string{RayJobClusterSelectorKey: "tenant-b/raycluster"}, corev1.Container{Name: "ray-job-submitter"})
+
+		_, err = rjWebhook.ValidateCreate(test.Ctx(), runtime.Object(rayJob))
+		test.Expect(err).To(MatchError(ContainSubstring("spec.clusterSelector[ray.io/cluster]: Invalid value: \"tenant-b/raycluster\"")))
+	})
+
+	t.Run("Negative: Expected RayJobs whose submitter targets a RayCluster of another namespace to be rejected", func(t *testing.T) {
+		rayJob := newRayJob(map[string]string{RayJobClusterSelectorKey: "raycluster"}, corev1.Container{
+			Name:    "ray-job-submitter",
+			Command: []string{"bash", "-c"},
+			Args: []string{
+				"ray job submit --address=http://raycluster-head-svc.tenant-b.svc.cluster.local:8265 -- python job.py",
+				"--address", "raycluster-head-svc.tenant-c:8265",
+			},
+			Env: []corev1.EnvVar{{Name: "RAY_DASHBOARD_ADDRESS", Value: "http://raycluster-head-svc.tenant-d.svc:8265"}},
+		})
+
+		_, err := rjWebhook.ValidateCreate(test.Ctx(), runtime.Object(rayJob))
+		test.Expect(err).To(MatchError(And(
+			ContainSubstring("spec.submitterPodTemplate.spec.containers.0.args.0: Forbidden: RayJob cannot target RayCluster http://raycluster-head-svc.tenant-b.svc.cluster.local:8265 of namespace tenant-b"),
+			ContainSubstring("spec.submitterPodTemplate.spec.containers.0.args.2: Forbidden: RayJob cannot target RayCluster raycluster-head-svc.tenant-c:8265 of namespace tenant-c"),
+			ContainSubstring("spec.submitterPodTemplate.spec.containers.0.env.0.value: Forbidden: RayJob cannot target RayCluster http://raycluster-head-svc.tenant-d.svc:8265 of namespace tenant-d"),
+		)))
+	})
+}
+
+func TestSharedClusterIngressRule(t *testing.T) {
+	test := support.NewTest(t)
+
+	cfg := &config.KubeRayConfiguration{SharedClusterNamespaces: []string{"shared"}}
+
+	test.T().Run("Expected Ray client and dashboard traffic to be allowed from all the namespaces to shared RayClusters", func(t *testing.T) {
+		cluster := &rayv1.RayCluster{ObjectMeta: metav1.ObjectMeta{Name: "raycluster", Namespace: "shared"}}
+
+		policy := desiredHeadNetworkPolicy(cluster, cfg, []string{"opendatahub"})
+		rule := policy.Spec.Ingress[len(policy.Spec.Ingress)-1]
+
+		test.Expect(rule.Ports).To(HaveLen(2))
+		test.Expect(*rule.Ports[0].Port).To(Equal(intstr.FromInt(10001)))
+		test.Expect(*rule.Ports[1].Port).To(Equal(intstr.FromInt(8265)))
+		test.Expect(rule.From).To(HaveLen(1))
+		test.Expect(rule.From[0].PodSelector).To(BeNil())
+		test.Expect(rule.From[0].NamespaceSelector.MatchLabels).To(BeEmpty())
+		test.Expect(rule.From[0].NamespaceSelector.MatchExpressions).To(BeEmpty())
+	})
+
+	test.T().Run("Negative: Expected no rule for the RayClusters of other namespaces", func(t *testing.T) {
+		cluster := &rayv1.RayCluster{ObjectMeta: metav1.ObjectMeta{Name: "raycluster", Namespace: "tenant-a"}}
+
+		test.Expect(sharedClusterIngressRule(cluster, cfg)).To(BeNil())
+	})
+}
+
+func TestServiceNamespace(t *testing.T) {
+	test := support.NewTest(t)
+
+	tests := []struct {
+		name              string
+		address           string
+		expectedNamespace string
+		expectedService   bool
+	}{
+		{
+			name:              "Expected namespace of the fully qualified Service DNS name to be returned",
+			address:           "http://raycluster-head-svc.tenant-b.svc.cluster.local:8265",
+			expectedNamespace: "tenant-b",
+			expectedService:   true,
+		},
+		{
+			name:              "Expected namespace of the Service DNS name without cluster domain to be returned",
+			address:           "ray://raycluster-head-svc.tenant-b.svc:10001",
+			expectedNamespace: "tenant-b",
+			expectedService:   true,
+		},
+		{
+			name:              "Expected namespace of the <service>.<namespace> host to be returned",
+			address:           "raycluster-head-svc.tenant-b:8265",
+			expectedNamespace: "tenant-b",
+			expectedService:   true,
+		},
+		{
+			name:    "Negative: Expected Service DNS name of the local namespace to have no namespace",
+			address: "http://raycluster-head-svc:8265",
+		},
+		{
+			name:    "Negative: Expected host of a top-level domain not to be a Service",
+			address: "https://myray.io/api",
+		},
+		{
+			name:    "Negative: Expected host of a special-use domain not to be a Service",
+			address: "http://ray.example:8265",
+		},
+		{
+			name:    "Negative: Expected fully qualified external host not to be a Service",
+			address: "https://ray.example.com/api",
+		},
+		{
+			name:    "Negative: Expected host of an invalid namespace name not to be a Service",
+			address: "http://raycluster-head-svc.tenant_b:8265",
+		},
+		{
+			name:    "Negative: Expected IP address not to be a Service",
+			address: "http://10.0.0.1:8265",
+		},
+	}
+
+	for _, tc := range tests {
+		test.T().Run(tc.name, func(t *testing.T) {
+			namespace, ok := serviceNamespace(tc.address)
+			test.Expect(ok).To(Equal(tc.expectedService))
+			test.Expect(namespace).To(Equal(tc.expectedNamespace))
+		})
+	}
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"github.com/project-codeflare/codeflare-operator/pkg/modelregistry"
+)
+
+const (
+	modelRegistrationControllerName = "codeflare-model-registration-controller"
+
+	// ModelOutputPathAnnotation is the URI, e.g., s3://bucket/path, the RayJob writes the model it trains to.
+	// The model is registered in the model registry once the RayJob has succeeded.
+	ModelOutputPathAnnotation = "codeflare.dev/model-output-path"
+	// ModelNameAnnotation and ModelVersionAnnotation are the name, and version, the model is registered with.
+	// They default to the name of the RayJob. The name is prefixed with the namespace of the RayJob, i.e.,
+	// <namespace>/<name>, so that the RayJobs of a namespace can't register versions of the models of another.
+	ModelNameAnnotation    = "codeflare.dev/model-name"
+	ModelVersionAnnotation = "codeflare.dev/model-version"
+
+	// ModelRegisteredCondition reports whether the model trained by the RayJob is registered in the model registry.
+	ModelRegisteredCondition = "ModelRegistered"
+)
+
+// ModelRegistry registers models in a model registry.
+type ModelRegistry interface {
+	RegisterModel(ctx context.Context, model modelregistry.Model) (string, error)
+}
+
+// ModelRegistrationReconciler registers, in the model registry, the models trained by the RayJobs annotated with
+// their output path, once the RayJobs have succeeded, so that the trained models can be served from the registry.
+// The registration is recorded with the ModelRegistered condition of the RayJobs, and retried with backoff on failures.
+type ModelRegistrationReconciler struct {
+	client.Client
+	Registry ModelRegistry
+	Recorder record.EventRecorder
+}
+
+// +kubebuilder:rbac:groups=ray.io,resources=rayjobs,verbs=get;list;watch;patch
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+
+func (r *ModelRegistrationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := ctrl.LoggerFrom(ctx)
+
+	rayJob := &rayv1.RayJob{}
+	if err := r.Get(ctx, req.NamespacedName, rayJob); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	outputPath, ok := rayJob.Annotations[ModelOutputPathAnnotation]
+	if !ok || rayJob.Status.JobStatus != rayv1.JobStatusSucceeded || !rayJob.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	conditions, err := rayJobConditions(rayJob)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if meta.IsStatusConditionTrue(conditions, ModelRegisteredCondition) {
+		return ctrl.Result{}, nil
+	}
+
+	model := modelregistry.Model{
+		Name:        rayJob.Namespace + "/" + rayJob.Name,
+		Version:     rayJob.Name,
+		URI:         outputPath,
+		Description: fmt.Sprintf("Trained by RayJob %s/%s", rayJob.Namespace, rayJob.Name),
+		Source:      rayJob.Namespace + "/" + rayJob.Name,
+	}
+	if name := rayJob.Annotations[ModelNameAnnotation]; name != "" {
+		model.Name = rayJob.Namespace + "/" + name
+	}
+	if version := rayJob.Annotations[ModelVersionAnnotation]; version != "" {
+		model.Version = version
+	}
+
+	condition := metav1.Condition{
+		Type:               ModelRegisteredCondition,
+		Status:             metav1.ConditionTrue,
+		Reason:             "Registered",
+		ObservedGeneration: rayJob.Generation,
+	}
+	versionID, registerErr := r.Registry.RegisterModel(ctx, model)
+	if registerErr != nil {
+		logger.Error(registerErr, "Failed to register model", "model", model.Name, "version", model.Version, "uri", model.URI)
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "RegistrationFailed"
+		condition.Message = registerErr.Error()
+	} else {
+		logger.Info("Registered model", "model", model.Name, "version", model.Version, "uri", model.URI, "versionID", versionID)
+		condition.Message = fmt.Sprintf("Version %s of model %s is registered with ID %s", model.Version, model.Name, versionID)
+		r.Recorder.Eventf(rayJob, corev1.EventTypeNormal, "ModelRegistered",
+			"Registered version %s of model %s, from %s, in the model registry", model.Version, model.Name, model.URI)
+	}
+
+	if meta.SetStatusCondition(&conditions, condition) {
+		value, err := json.Marshal(conditions)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		patch := client.MergeFrom(rayJob.DeepCopy())
+		metav1.SetMetaDataAnnotation(&rayJob.ObjectMeta, ConditionsAnnotation, string(value))
+		if err := r.Patch(ctx, rayJob, patch); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	// The registration is retried with the backoff of the controller
+	return ctrl.Result{}, registerErr
+}
+
+// rayJobConditions returns the conditions recorded in the conditions annotation of the RayJob.
+func rayJobConditions(rayJob *rayv1.RayJob) ([]metav1.Condition, error) {
+	var conditions []metav1.Condition
+	if value, ok := rayJob.Annotations[ConditionsAnnotation]; ok {
+		if err := json.Unmarshal([]byte(value), &conditions); err != nil {
+			return nil, fmt.Errorf("invalid %s annotation: %w", ConditionsAnnotation, err)
+		}
+	}
+	return conditions, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ModelRegistrationReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	hasModelOutputPath := predicate.NewPredicateFuncs(func(object client.Object) bool {
+		_, ok := object.GetAnnotations()[ModelOutputPathAnnotation]
+		return ok
+	})
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(modelRegistrationControllerName).
+		For(&rayv1.RayJob{}, builder.WithPredicates(hasModelOutputPath)).
+		Complete(instrument(modelRegistrationControllerName, "RayJob", r))
+}
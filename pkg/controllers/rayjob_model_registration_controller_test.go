@@ -0,0 +1,137 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/project-codeflare/codeflare-common/support"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/project-codeflare/codeflare-operator/pkg/modelregistry"
+)
+
+type fakeModelRegistry struct {
+	models []modelregistry.Model
+	err    error
+}
+
+func (f *fakeModelRegistry) RegisterModel(_ context.Context, model modelregistry.Model) (string, error) {
+	if f.err != nil {
+		return "", f.err
+	}
+	f.models = append(f.models, model)
+	return "42", nil
+}
+
+func TestModelRegistrationReconcile(t *testing.T) {
+	test := support.NewTest(t)
+
+	scheme := runtime.NewScheme()
+	test.Expect(rayv1.AddToScheme(scheme)).To(Succeed())
+
+	rayJob := func(status rayv1.JobStatus, annotations map[string]string) *rayv1.RayJob {
+		return &rayv1.RayJob{
+			ObjectMeta: metav1.ObjectMeta{Name: "train-llama", Namespace: "ns", Annotations: annotations},
+			Status:     rayv1.RayJobStatus{JobStatus: status},
+		}
+	}
+	reconcile := func(registry *fakeModelRegistry, job *rayv1.RayJob) (*rayv1.RayJob, *record.FakeRecorder, error) {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(job).Build()
+		recorder := record.NewFakeRecorder(10)
+		r := &ModelRegistrationReconciler{Client: c, Registry: registry, Recorder: recorder}
+		_, err := r.Reconcile(test.Ctx(), ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "ns", Name: "train-llama"}})
+		updated := &rayv1.RayJob{}
+		test.Expect(c.Get(test.Ctx(), client.ObjectKeyFromObject(job), updated)).To(Succeed())
+		return updated, recorder, err
+	}
+	condition := func(job *rayv1.RayJob) *metav1.Condition {
+		conditions, err := rayJobConditions(job)
+		test.Expect(err).NotTo(HaveOccurred())
+		return meta.FindStatusCondition(conditions, ModelRegisteredCondition)
+	}
+
+	test.T().Run("Expected model of the succeeded RayJob to be registered", func(t *testing.T) {
+		registry := &fakeModelRegistry{}
+		job, recorder, err := reconcile(registry, rayJob(rayv1.JobStatusSucceeded, map[string]string{
+			ModelOutputPathAnnotation: "s3://models/llama/train-llama",
+			ModelNameAnnotation:       "llama-finetuned",
+		}))
+
+		test.Expect(err).NotTo(HaveOccurred())
+		test.Expect(registry.models).To(ConsistOf(modelregistry.Model{
+			Name:        "ns/llama-finetuned",
+			Version:     "train-llama",
+			URI:         "s3://models/llama/train-llama",
+			Description: "Trained by RayJob ns/train-llama",
+			Source:      "ns/train-llama",
+		}))
+		test.Expect(condition(job)).To(And(
+			HaveField("Status", Equal(metav1.ConditionTrue)),
+			HaveField("Message", Equal("Version train-llama of model ns/llama-finetuned is registered with ID 42")),
+		))
+		test.Expect(recorder.Events).To(Receive(ContainSubstring("ModelRegistered")))
+
+		// The model isn't registered again
+		registry.models = nil
+		r := &ModelRegistrationReconciler{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(job).Build(), Registry: registry, Recorder: recorder}
+		_, err = r.Reconcile(test.Ctx(), ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "ns", Name: "train-llama"}})
+		test.Expect(err).NotTo(HaveOccurred())
+		test.Expect(registry.models).To(BeEmpty())
+	})
+
+	test.T().Run("Expected model to be registered with the name of the RayJob, in its namespace, by default", func(t *testing.T) {
+		registry := &fakeModelRegistry{}
+		_, _, err := reconcile(registry, rayJob(rayv1.JobStatusSucceeded, map[string]string{ModelOutputPathAnnotation: "s3://models/llama"}))
+
+		test.Expect(err).NotTo(HaveOccurred())
+		test.Expect(registry.models).To(ConsistOf(HaveField("Name", Equal("ns/train-llama"))))
+	})
+
+	test.T().Run("Expected registration failure to be reported and retried", func(t *testing.T) {
+		registry := &fakeModelRegistry{err: errors.New("model registry returned status 503")}
+		job, _, err := reconcile(registry, rayJob(rayv1.JobStatusSucceeded, map[string]string{ModelOutputPathAnnotation: "s3://models/llama"}))
+
+		test.Expect(err).To(HaveOccurred())
+		test.Expect(condition(job)).To(And(
+			HaveField("Status", Equal(metav1.ConditionFalse)),
+			HaveField("Reason", Equal("RegistrationFailed")),
+			HaveField("Message", Equal("model registry returned status 503")),
+		))
+	})
+
+	test.T().Run("Negative: Expected no registration for RayJobs that haven't succeeded", func(t *testing.T) {
+		registry := &fakeModelRegistry{}
+		job, _, err := reconcile(registry, rayJob(rayv1.JobStatusFailed, map[string]string{ModelOutputPathAnnotation: "s3://models/llama"}))
+
+		test.Expect(err).NotTo(HaveOccurred())
+		test.Expect(registry.models).To(BeEmpty())
+		test.Expect(condition(job)).To(BeNil())
+	})
+}
@@ -0,0 +1,176 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/project-codeflare/codeflare-operator/pkg/cache"
+	"github.com/project-codeflare/codeflare-operator/pkg/config"
+	"github.com/project-codeflare/codeflare-operator/pkg/defaults"
+)
+
+// log is for logging in this package.
+var rayjoblog = logf.Log.WithName("rayjob-resource")
+
+// RayJobClusterSelectorKey is the cluster selector key of the RayJobs submitted to an existing RayCluster by name.
+const RayJobClusterSelectorKey = "ray.io/cluster"
+
+// namespaceAnnotationsTTL is how long the namespace annotations are cached for by the webhook.
+const namespaceAnnotationsTTL = 30 * time.Second
+
+func SetupRayJobWebhookWithManager(mgr ctrl.Manager, cfg *config.KubeRayConfiguration, cacheCfg *config.CacheConfiguration) error {
+	scope, err := newCacheScope(cacheCfg)
+	if err != nil {
+		return err
+	}
+	rayJobWebhookInstance := &rayJobWebhook{
+		Config:               cfg,
+		Scope:                scope,
+		Client:               mgr.GetClient(),
+		NamespaceAnnotations: newNamespaceAnnotationsCache(mgr.GetAPIReader()),
+	}
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&rayv1.RayJob{}).
+		WithDefaulter(rayJobWebhookInstance).
+		WithValidator(rayJobWebhookInstance).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-ray-io-v1-rayjob,mutating=true,failurePolicy=fail,sideEffects=None,groups=ray.io,resources=rayjobs,verbs=create,versions=v1,name=mrayjob.ray.openshift.ai,admissionReviewVersions=v1
+// +kubebuilder:webhook:path=/validate-ray-io-v1-rayjob,mutating=false,failurePolicy=fail,sideEffects=None,groups=ray.io,resources=rayjobs,verbs=create;update,versions=v1,name=vrayjob.ray.openshift.ai,admissionReviewVersions=v1
+// +kubebuilder:rbac:groups=core,resources=namespaces,verbs=get
+
+type rayJobWebhook struct {
+	Config *config.KubeRayConfiguration
+	// Scope restricts the RayJobs the webhook defaults and validates to the ones in the namespaces the operator manages
+	Scope cacheScope
+	// Client reads the existing RayClusters RayJobs are submitted to from the informer cache,
+	// as they are watched by the RayCluster controller
+	Client client.Reader
+	// NamespaceAnnotations caches the annotations of the RayJob namespaces,
+	// without informing on all the namespaces
+	NamespaceAnnotations *cache.Cache[string, map[string]string]
+}
+
+func newNamespaceAnnotationsCache(reader client.Reader) *cache.Cache[string, map[string]string] {
+	return cache.New("namespace-annotations", namespaceAnnotationsTTL,
+		func(ctx context.Context, name string) (map[string]string, error) {
+			namespace := &corev1.Namespace{}
+			if err := reader.Get(ctx, client.ObjectKey{Name: name}, namespace); err != nil {
+				return nil, err
+			}
+			return namespace.Annotations, nil
+		})
+}
+
+var _ webhook.CustomDefaulter = &rayJobWebhook{}
+var _ webhook.CustomValidator = &rayJobWebhook{}
+
+// Default implements webhook.Defaulter so a webhook will be registered for the type
+func (w *rayJobWebhook) Default(ctx context.Context, obj runtime.Object) error {
+	rayJob := obj.(*rayv1.RayJob)
+	if !w.Scope.includesNamespace(rayJob.Namespace) {
+		return nil
+	}
+
+	rayjoblog.V(2).Info("Applying RayJob defaults", "namespace", rayJob.Namespace, "name", rayJob.Name)
+	defaults.ApplyRayJobDefaults(w.Config, rayJob)
+
+	if rayJob.Spec.RayClusterSpec != nil {
+		annotations, err := w.NamespaceAnnotations.Get(ctx, rayJob.Namespace)
+		if err != nil {
+			return err
+		}
+		// The invalid namespace annotations are ignored, rather than rejecting the RayJobs of the namespace
+		// until they are fixed
+		if err := defaults.ApplyRayJobNamespaceDefaults(annotations, rayJob); err != nil {
+			rayjoblog.Error(err, "Ignoring invalid RayJob defaults", "namespace", rayJob.Namespace, "name", rayJob.Name)
+		}
+		// The Workload, and RayCluster, of the RayJob are created from the RayCluster spec of the RayJob
+		if err := defaults.ApplyTopologyNamespaceDefaults(annotations, rayJob.Spec.RayClusterSpec); err != nil {
+			rayjoblog.Error(err, "Ignoring invalid topology defaults", "namespace", rayJob.Namespace, "name", rayJob.Name)
+		}
+		if err := defaults.ApplyKarpenterNamespaceDefaults(w.Config, annotations, rayJob.Spec.RayClusterSpec); err != nil {
+			rayjoblog.Error(err, "Ignoring invalid Karpenter defaults", "namespace", rayJob.Namespace, "name", rayJob.Name)
+		}
+	}
+
+	if w.Config == nil || len(w.Config.AcceleratorRuntimeEnvs) == 0 {
+		return nil
+	}
+	clusterSpec, err := w.targetRayClusterSpec(ctx, rayJob)
+	if err != nil {
+		return err
+	}
+	return defaults.ApplyAcceleratorRuntimeEnv(w.Config.AcceleratorRuntimeEnvs, rayJob, clusterSpec)
+}
+
+func (w *rayJobWebhook) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	rayJob := obj.(*rayv1.RayJob)
+	if !w.Scope.includesNamespace(rayJob.Namespace) {
+		return nil, nil
+	}
+
+	return nil, validateClusterSelector(rayJob, w.Config).ToAggregate()
+}
+
+func (w *rayJobWebhook) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	rayJob := newObj.(*rayv1.RayJob)
+
+	if !rayJob.DeletionTimestamp.IsZero() {
+		// Object is being deleted, skip validations
+		return nil, nil
+	}
+	if !w.Scope.includesNamespace(rayJob.Namespace) {
+		return nil, nil
+	}
+
+	return nil, validateClusterSelector(rayJob, w.Config).ToAggregate()
+}
+
+func (w *rayJobWebhook) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// targetRayClusterSpec returns the spec of the RayCluster the RayJob runs on, either the one it creates,
+// or the existing one it selects, if any.
+func (w *rayJobWebhook) targetRayClusterSpec(ctx context.Context, rayJob *rayv1.RayJob) (*rayv1.RayClusterSpec, error) {
+	if rayJob.Spec.RayClusterSpec != nil {
+		return rayJob.Spec.RayClusterSpec, nil
+	}
+	name, ok := rayJob.Spec.ClusterSelector[RayJobClusterSelectorKey]
+	if !ok || rayJob.Spec.RuntimeEnvYAML == "" {
+		return nil, nil
+	}
+	rayCluster := &rayv1.RayCluster{}
+	if err := w.Client.Get(ctx, client.ObjectKey{Namespace: rayJob.Namespace, Name: name}, rayCluster); err != nil {
+		return nil, client.IgnoreNotFound(err)
+	}
+	return &rayCluster.Spec, nil
+}
@@ -0,0 +1,234 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/project-codeflare/codeflare-common/support"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/project-codeflare/codeflare-operator/pkg/config"
+	"github.com/project-codeflare/codeflare-operator/pkg/defaults"
+)
+
+func TestRayJobWebhookDefault(t *testing.T) {
+	test := support.NewTest(t)
+
+	rjWebhook := &rayJobWebhook{
+		Config: &config.KubeRayConfiguration{
+			Proxy: &config.ProxyConfiguration{
+				HTTPProxy:  "http://proxy.example.com:3128",
+				HTTPSProxy: "http://proxy.example.com:3128",
+				NoProxy:    ".cluster.local,.svc",
+			},
+		},
+	}
+
+	rayJob := &rayv1.RayJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-rayjob",
+			Namespace: namespace,
+		},
+		Spec: rayv1.RayJobSpec{
+			SubmitterPodTemplate: &corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name: "rayjob-submitter-pod",
+							Env: []corev1.EnvVar{
+								{Name: "NO_PROXY", Value: "example.org"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	err := rjWebhook.Default(test.Ctx(), runtime.Object(rayJob))
+	t.Run("Expected no errors on call to Default function", func(t *testing.T) {
+		test.Expect(err).ShouldNot(HaveOccurred(), "Expected no errors on call to Default function")
+	})
+
+	t.Run("Expected proxy environment variables for the submitter container", func(t *testing.T) {
+		test.Expect(rayJob.Spec.SubmitterPodTemplate.Spec.Containers[0].Env).
+			To(
+				And(
+					HaveLen(6),
+					ContainElement(corev1.EnvVar{Name: "HTTP_PROXY", Value: "http://proxy.example.com:3128"}),
+					ContainElement(corev1.EnvVar{Name: "http_proxy", Value: "http://proxy.example.com:3128"}),
+					ContainElement(corev1.EnvVar{Name: "HTTPS_PROXY", Value: "http://proxy.example.com:3128"}),
+					ContainElement(corev1.EnvVar{Name: "https_proxy", Value: "http://proxy.example.com:3128"}),
+					ContainElement(corev1.EnvVar{Name: "no_proxy", Value: ".cluster.local,.svc"}),
+				),
+				"Expected the proxy environment variables to be present in the submitter container",
+			)
+	})
+
+	t.Run("Expected user provided proxy environment variables to be preserved", func(t *testing.T) {
+		test.Expect(rayJob.Spec.SubmitterPodTemplate.Spec.Containers[0].Env).
+			To(ContainElement(corev1.EnvVar{Name: "NO_PROXY", Value: "example.org"}))
+	})
+
+	t.Run("Expected no errors for RayJobs without submitter pod template", func(t *testing.T) {
+		test.Expect(rjWebhook.Default(test.Ctx(), runtime.Object(&rayv1.RayJob{}))).To(Succeed())
+	})
+}
+
+func TestRayJobWebhookNamespaceDefaults(t *testing.T) {
+	test := support.NewTest(t)
+
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-ns",
+			Annotations: map[string]string{
+				defaults.ShutdownAfterJobFinishesAnnotation: "true",
+				defaults.TTLSecondsAfterFinishedAnnotation:  "300",
+			},
+		},
+	}
+	var gets atomic.Int32
+	reader := fake.NewClientBuilder().
+		WithObjects(namespace).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+				gets.Add(1)
+				return c.Get(ctx, key, obj, opts...)
+			},
+		}).
+		Build()
+	rjWebhook := &rayJobWebhook{NamespaceAnnotations: newNamespaceAnnotationsCache(reader)}
+
+	for i := 0; i < 3; i++ {
+		rayJob := &rayv1.RayJob{
+			ObjectMeta: metav1.ObjectMeta{Name: "rayjob", Namespace: namespace.Name},
+			Spec:       rayv1.RayJobSpec{RayClusterSpec: &rayv1.RayClusterSpec{}},
+		}
+		test.Expect(rjWebhook.Default(test.Ctx(), runtime.Object(rayJob))).To(Succeed())
+
+		test.Expect(rayJob.Spec.ShutdownAfterJobFinishes).To(BeTrue())
+		test.Expect(rayJob.Spec.TTLSecondsAfterFinished).To(Equal(int32(300)))
+	}
+
+	t.Run("Expected the namespace to be read once for consecutive RayJobs", func(t *testing.T) {
+		test.Expect(gets.Load()).To(Equal(int32(1)))
+	})
+
+	t.Run("Negative: Expected an error for a missing namespace", func(t *testing.T) {
+		rayJob := &rayv1.RayJob{
+			ObjectMeta: metav1.ObjectMeta{Name: "rayjob", Namespace: "missing"},
+			Spec:       rayv1.RayJobSpec{RayClusterSpec: &rayv1.RayClusterSpec{}},
+		}
+		test.Expect(rjWebhook.Default(test.Ctx(), runtime.Object(rayJob))).NotTo(Succeed())
+	})
+	t.Run("Expected invalid namespace annotations to be ignored", func(t *testing.T) {
+		invalid := &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "invalid-ns",
+				Annotations: map[string]string{
+					defaults.ShutdownAfterJobFinishesAnnotation: "yes please",
+					defaults.RequiredTopologyAnnotation:         "not a label",
+				},
+			},
+		}
+		invalidWebhook := &rayJobWebhook{NamespaceAnnotations: newNamespaceAnnotationsCache(fake.NewClientBuilder().WithObjects(invalid).Build())}
+		rayJob := &rayv1.RayJob{
+			ObjectMeta: metav1.ObjectMeta{Name: "rayjob", Namespace: invalid.Name},
+			Spec:       rayv1.RayJobSpec{RayClusterSpec: &rayv1.RayClusterSpec{}},
+		}
+		test.Expect(invalidWebhook.Default(test.Ctx(), runtime.Object(rayJob))).To(Succeed())
+		test.Expect(rayJob.Spec.ShutdownAfterJobFinishes).To(BeFalse())
+	})
+}
+
+func TestRayJobWebhookAcceleratorRuntimeEnv(t *testing.T) {
+	test := support.NewTest(t)
+
+	scheme := runtime.NewScheme()
+	test.Expect(rayv1.AddToScheme(scheme)).To(Succeed())
+
+	rayCluster := &rayv1.RayCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "raycluster", Namespace: "test-ns"},
+		Spec: rayv1.RayClusterSpec{
+			WorkerGroupSpecs: []rayv1.WorkerGroupSpec{{
+				Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{
+					Name: "ray-worker",
+					Resources: corev1.ResourceRequirements{
+						Limits: corev1.ResourceList{"amd.com/gpu": resource.MustParse("1")},
+					},
+				}}}},
+			}},
+		},
+	}
+	rjWebhook := &rayJobWebhook{
+		Config: &config.KubeRayConfiguration{
+			AcceleratorRuntimeEnvs: []config.AcceleratorRuntimeEnvConfiguration{{
+				ResourceName:      "amd.com/gpu",
+				PipExtraIndexURLs: []string{"https://download.pytorch.org/whl/rocm6.0"},
+			}},
+		},
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(rayCluster).Build(),
+	}
+
+	t.Run("Expected accelerator pip settings for RayJobs submitted to an existing RayCluster", func(t *testing.T) {
+		rayJob := &rayv1.RayJob{
+			ObjectMeta: metav1.ObjectMeta{Name: "rayjob", Namespace: "test-ns"},
+			Spec: rayv1.RayJobSpec{
+				ClusterSelector: map[string]string{RayJobClusterSelectorKey: rayCluster.Name},
+				RuntimeEnvYAML:  "pip:\n  - torch\n",
+			},
+		}
+		test.Expect(rjWebhook.Default(test.Ctx(), runtime.Object(rayJob))).To(Succeed())
+		test.Expect(rayJob.Spec.RuntimeEnvYAML).To(ContainSubstring("--extra-index-url https://download.pytorch.org/whl/rocm6.0"))
+	})
+
+	t.Run("Expected no errors for RayJobs selecting a missing RayCluster", func(t *testing.T) {
+		rayJob := &rayv1.RayJob{
+			ObjectMeta: metav1.ObjectMeta{Name: "rayjob", Namespace: "test-ns"},
+			Spec: rayv1.RayJobSpec{
+				ClusterSelector: map[string]string{RayJobClusterSelectorKey: "missing"},
+				RuntimeEnvYAML:  "pip:\n  - torch\n",
+			},
+		}
+		test.Expect(rjWebhook.Default(test.Ctx(), runtime.Object(rayJob))).To(Succeed())
+		test.Expect(rayJob.Spec.RuntimeEnvYAML).To(Equal("pip:\n  - torch\n"))
+	})
+	t.Run("Negative: runtime envs are left untouched without accelerator catalog", func(t *testing.T) {
+		rayJob := &rayv1.RayJob{
+			ObjectMeta: metav1.ObjectMeta{Name: "rayjob", Namespace: "test-ns"},
+			Spec: rayv1.RayJobSpec{
+				ClusterSelector: map[string]string{RayJobClusterSelectorKey: rayCluster.Name},
+				RuntimeEnvYAML:  "pip:\n  - torch\n",
+			},
+		}
+		unconfiguredWebhook := &rayJobWebhook{Config: &config.KubeRayConfiguration{}, Client: rjWebhook.Client}
+		test.Expect(unconfiguredWebhook.Default(test.Ctx(), runtime.Object(rayJob))).To(Succeed())
+		test.Expect(rayJob.Spec.RuntimeEnvYAML).To(Equal("pip:\n  - torch\n"))
+	})
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	requeueReasonError        = "error"
+	requeueReasonRequeue      = "requeue"
+	requeueReasonRequeueAfter = "requeue_after"
+)
+
+var (
+	reconcileDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "codeflare",
+		Name:      "reconcile_duration_seconds",
+		Help:      "Duration of the reconciliations, partitioned by kind of the reconciled objects.",
+		Buckets:   prometheus.ExponentialBuckets(0.005, 2, 14),
+	}, []string{"kind"})
+
+	reconcileRequeues = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "codeflare",
+		Name:      "reconcile_requeues_total",
+		Help:      "Number of reconciliations that requeued the object, partitioned by kind of the reconciled objects and reason (error, requeue or requeue_after).",
+	}, []string{"kind", "reason"})
+
+	workqueueDepthDesc = prometheus.NewDesc(
+		"codeflare_workqueue_depth",
+		"Number of objects waiting to be reconciled, partitioned by kind of the reconciled objects.",
+		[]string{"kind"}, nil)
+)
+
+var workqueueDepth = &workqueueDepthCollector{depth: controllerRuntimeWorkqueueDepth()}
+
+func init() {
+	metrics.Registry.MustRegister(reconcileDuration, reconcileRequeues, workqueueDepth)
+}
+
+// controllerKinds maps the names of the instrumented controllers to the kind of the objects they reconcile.
+var controllerKinds sync.Map
+
+// instrumentedReconciler records the duration, and the requeues, of the reconciliations of the objects of the kind,
+// as well as the errors they return in the last errors of the CodeFlareStatus. The conflicts aren't recorded there,
+// as they're expected and retried straight away.
+type instrumentedReconciler struct {
+	kind string
+	reconcile.Reconciler
+}
+
+// instrument returns the reconciler of the named controller instrumented for the objects of the kind.
+func instrument(controllerName, kind string, reconciler reconcile.Reconciler) reconcile.Reconciler {
+	controllerKinds.Store(controllerName, kind)
+	return instrumentedReconciler{kind: kind, Reconciler: reconciler}
+}
+
+func (r instrumentedReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	start := time.Now()
+	result, err := r.Reconciler.Reconcile(ctx, req)
+	reconcileDuration.WithLabelValues(r.kind).Observe(time.Since(start).Seconds())
+
+	switch {
+	case err != nil:
+		reconcileRequeues.WithLabelValues(r.kind, requeueReasonError).Inc()
+		if !apierrors.IsConflict(err) {
+			reportedErrors.add(r.kind, req.String(), "", err.Error())
+		}
+	case result.RequeueAfter > 0:
+		reconcileRequeues.WithLabelValues(r.kind, requeueReasonRequeueAfter).Inc()
+	case result.Requeue:
+		reconcileRequeues.WithLabelValues(r.kind, requeueReasonRequeue).Inc()
+	}
+	return result, err
+}
+
+// controllerRuntimeWorkqueueDepth returns the depth gauge of the workqueues of the controllers, registered
+// by controller-runtime, that isn't exported otherwise. It's retrieved by registering an identical gauge.
+func controllerRuntimeWorkqueueDepth() prometheus.Collector {
+	depth := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: metrics.WorkQueueSubsystem,
+		Name:      metrics.DepthKey,
+		Help:      "Current depth of workqueue",
+	}, []string{"name"})
+	are := prometheus.AlreadyRegisteredError{}
+	if err := metrics.Registry.Register(depth); errors.As(err, &are) {
+		return are.ExistingCollector
+	}
+	return depth
+}
+
+// workqueueDepthCollector exports the depth of the workqueues of the instrumented controllers, by kind of the objects
+// they reconcile. The depths of the controllers reconciling the same kind are summed, so that a single sample is
+// exported per kind.
+type workqueueDepthCollector struct {
+	depth prometheus.Collector
+}
+
+var _ prometheus.Collector = &workqueueDepthCollector{}
+
+func (c *workqueueDepthCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- workqueueDepthDesc
+}
+
+func (c *workqueueDepthCollector) Collect(ch chan<- prometheus.Metric) {
+	gauges := make(chan prometheus.Metric)
+	go func() {
+		c.depth.Collect(gauges)
+		close(gauges)
+	}()
+	depths := map[string]float64{}
+	for gauge := range gauges {
+		metric := &dto.Metric{}
+		if err := gauge.Write(metric); err != nil {
+			continue
+		}
+		for _, label := range metric.GetLabel() {
+			if label.GetName() != "name" {
+				continue
+			}
+			if kind, ok := controllerKinds.Load(label.GetValue()); ok {
+				depths[kind.(string)] += metric.GetGauge().GetValue()
+			}
+		}
+	}
+	for kind, depth := range depths {
+		ch <- prometheus.MustNewConstMetric(workqueueDepthDesc, prometheus.GaugeValue, depth, kind)
+	}
+}
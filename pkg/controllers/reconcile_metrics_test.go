@@ -0,0 +1,128 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/project-codeflare/codeflare-common/support"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestInstrumentedReconciler(t *testing.T) {
+	test := support.NewTest(t)
+
+	test.T().Run("Expected reconciliations to be timed, and their requeues counted by reason", func(t *testing.T) {
+		kind := "InstrumentedKind"
+		results := []struct {
+			result ctrl.Result
+			err    error
+		}{
+			{ctrl.Result{}, nil},
+			{ctrl.Result{Requeue: true}, nil},
+			{ctrl.Result{RequeueAfter: time.Minute}, nil},
+			{ctrl.Result{RequeueAfter: time.Minute}, nil},
+			{ctrl.Result{}, errors.New("cannot create Route")},
+		}
+		i := 0
+		r := instrument("codeflare-instrumented-controller", kind, reconcile.Func(func(context.Context, ctrl.Request) (ctrl.Result, error) {
+			defer func() { i++ }()
+			return results[i].result, results[i].err
+		}))
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "ns", Name: "object"}}
+
+		for range results {
+			_, _ = r.Reconcile(context.Background(), req)
+		}
+
+		duration := &dto.Metric{}
+		test.Expect(reconcileDuration.WithLabelValues(kind).(prometheus.Histogram).Write(duration)).To(Succeed())
+		test.Expect(duration.GetHistogram().GetSampleCount()).To(Equal(uint64(len(results))))
+		test.Expect(testutil.ToFloat64(reconcileRequeues.WithLabelValues(kind, requeueReasonRequeue))).To(Equal(float64(1)))
+		test.Expect(testutil.ToFloat64(reconcileRequeues.WithLabelValues(kind, requeueReasonRequeueAfter))).To(Equal(float64(2)))
+		test.Expect(testutil.ToFloat64(reconcileRequeues.WithLabelValues(kind, requeueReasonError))).To(Equal(float64(1)))
+	})
+}
+
+func TestWorkqueueDepthCollector(t *testing.T) {
+	test := support.NewTest(t)
+
+	test.T().Run("Expected depth of the workqueues of the instrumented controllers to be exported by kind", func(t *testing.T) {
+		instrument("codeflare-depth-controller", "DepthKind", nil)
+		queue := workqueue.NewNamed("codeflare-depth-controller")
+		t.Cleanup(queue.ShutDown)
+		queue.Add("ns/object-1")
+		queue.Add("ns/object-2")
+		// The workqueues of the controllers that aren't instrumented aren't exported
+		other := workqueue.NewNamed("appwrapper")
+		t.Cleanup(other.ShutDown)
+		other.Add("ns/appwrapper")
+
+		registry := prometheus.NewPedanticRegistry()
+		test.Expect(registry.Register(workqueueDepth)).To(Succeed())
+		test.Expect(workqueueDepths(test, registry, "DepthKind")).To(Equal([]float64{2}))
+		test.Expect(workqueueDepths(test, registry, "appwrapper")).To(BeEmpty())
+	})
+	test.T().Run("Expected depth of the workqueues of the controllers reconciling the same kind to be summed", func(t *testing.T) {
+		instrument("codeflare-shared-depth-controller", "SharedDepthKind", nil)
+		instrument("codeflare-other-shared-depth-controller", "SharedDepthKind", nil)
+		queue := workqueue.NewNamed("codeflare-shared-depth-controller")
+		t.Cleanup(queue.ShutDown)
+		queue.Add("ns/object-1")
+		other := workqueue.NewNamed("codeflare-other-shared-depth-controller")
+		t.Cleanup(other.ShutDown)
+		other.Add("ns/object-1")
+		other.Add("ns/object-2")
+
+		registry := prometheus.NewPedanticRegistry()
+		test.Expect(registry.Register(workqueueDepth)).To(Succeed())
+		test.Expect(workqueueDepths(test, registry, "SharedDepthKind")).To(Equal([]float64{3}))
+	})
+}
+
+// workqueueDepths gathers the metrics of the registry, and returns the samples of the workqueue depth of the kind.
+func workqueueDepths(test support.Test, gatherer prometheus.Gatherer, kind string) []float64 {
+	test.T().Helper()
+	families, err := gatherer.Gather()
+	test.Expect(err).NotTo(HaveOccurred())
+
+	var depths []float64
+	for _, family := range families {
+		if family.GetName() != "codeflare_workqueue_depth" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "kind" && label.GetValue() == kind {
+					depths = append(depths, metric.GetGauge().GetValue())
+				}
+			}
+		}
+	}
+	return depths
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"slices"
+	"sync"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/project-codeflare/codeflare-operator/api/v1alpha1"
+)
+
+// maxReportedErrors is the number of the last errors reported in the CodeFlareStatus
+const maxReportedErrors = 10
+
+// reportedErrors are the last errors of the controllers of the operator replica, reported in the CodeFlareStatus.
+// The controllers only run in the leader replica, which also reports the CodeFlareStatus.
+var reportedErrors = &errorLog{}
+
+type errorLog struct {
+	mu     sync.Mutex
+	errors []v1alpha1.ReportedError
+}
+
+// add records the error, evicting the oldest one when maxReportedErrors are recorded already.
+func (l *errorLog) add(kind, object string, reason FailureReason, message string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.errors = append([]v1alpha1.ReportedError{{
+		Kind:    kind,
+		Object:  object,
+		Reason:  string(reason),
+		Message: message,
+		Time:    metav1.Now(),
+	}}, l.errors[:min(len(l.errors), maxReportedErrors-1)]...)
+}
+
+// list returns the recorded errors, most recent first.
+func (l *errorLog) list() []v1alpha1.ReportedError {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return slices.Clone(l.errors)
+}
@@ -12,15 +12,18 @@ import (
 	networkingv1ac "k8s.io/client-go/applyconfigurations/networking/v1"
 
 	routeapply "github.com/openshift/client-go/route/applyconfigurations/route/v1"
+
+	"github.com/project-codeflare/codeflare-operator/pkg/config"
 )
 
 func serviceNameFromCluster(cluster *rayv1.RayCluster) string {
 	return cluster.Name + "-head-svc"
 }
 
-func desiredRayClientRoute(cluster *rayv1.RayCluster) *routeapply.RouteApplyConfiguration {
+func desiredRayClientRoute(cluster *rayv1.RayCluster, cfg *config.KubeRayConfiguration) *routeapply.RouteApplyConfiguration {
 	return routeapply.Route(rayClientNameFromCluster(cluster), cluster.Namespace).
 		WithLabels(map[string]string{"ray.io/cluster-name": cluster.Name}).
+		WithAnnotations(rayClientRouteAnnotations(cfg)).
 		WithSpec(routeapply.RouteSpec().
 			WithTo(routeapply.RouteTargetReference().WithKind("Service").WithName(serviceNameFromCluster(cluster)).WithWeight(100)).
 			WithPort(routeapply.RoutePort().WithTargetPort(intstr.FromString("client"))).
@@ -97,16 +100,6 @@ func desiredClusterIngress(cluster *rayv1.RayCluster, ingressHost string) *netwo
 
 type compare[T any] func(T, T) bool
 
-func upsert[T any](items []T, item T, predicate compare[T]) []T {
-	for i, t := range items {
-		if predicate(t, item) {
-			items[i] = item
-			return items
-		}
-	}
-	return append(items, item)
-}
-
 func contains[T any](items []T, item T, predicate compare[T], path *field.Path, msg string) *field.Error {
 	for _, t := range items {
 		if predicate(t, item) {
@@ -119,40 +112,32 @@ func contains[T any](items []T, item T, predicate compare[T], path *field.Path,
 	return field.Required(path, msg)
 }
 
+// containsOrUnchanged is contains, that also accepts the item as it is in the old items, so that the objects defaulted
+// by a previous version, or configuration, of the operator remain updatable, as long as the item is left unchanged.
+func containsOrUnchanged[T any](items, oldItems []T, item T, predicate compare[T], path *field.Path, msg string) *field.Error {
+	err := contains(items, item, predicate, path, msg)
+	if err == nil {
+		return nil
+	}
+	for _, oldItem := range oldItems {
+		if predicate(oldItem, item) && contains(items, oldItem, predicate, path, msg) == nil {
+			return nil
+		}
+	}
+	return err
+}
+
 var byContainerName = compare[corev1.Container](
 	func(c1, c2 corev1.Container) bool {
 		return c1.Name == c2.Name
 	})
 
-func withContainerName(name string) compare[corev1.Container] {
-	return func(c1, c2 corev1.Container) bool {
-		return c1.Name == name
-	}
-}
-
 var byVolumeName = compare[corev1.Volume](
 	func(v1, v2 corev1.Volume) bool {
 		return v1.Name == v2.Name
 	})
 
-func withVolumeName(name string) compare[corev1.Volume] {
-	return func(v1, v2 corev1.Volume) bool {
-		return v1.Name == name
-	}
-}
-
-var byVolumeMountName = compare[corev1.VolumeMount](
-	func(v1, v2 corev1.VolumeMount) bool {
-		return v1.Name == v2.Name
-	})
-
 var byEnvVarName = compare[corev1.EnvVar](
 	func(e1, e2 corev1.EnvVar) bool {
 		return e1.Name == e2.Name
 	})
-
-func withEnvVarName(name string) compare[corev1.EnvVar] {
-	return func(e1, e2 corev1.EnvVar) bool {
-		return e1.Name == name
-	}
-}
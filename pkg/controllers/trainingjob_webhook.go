@@ -0,0 +1,98 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	kubeflowv1 "github.com/kubeflow/training-operator/pkg/apis/kubeflow.org/v1"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	kueueconstants "sigs.k8s.io/kueue/pkg/controller/constants"
+)
+
+// log is for logging in this package.
+var trainingjoblog = logf.Log.WithName("trainingjob-resource")
+
+// AppWrapperLabel is the label the AppWrapper controller sets on the resources it creates for the AppWrappers,
+// including their Pods
+const AppWrapperLabel = "workload.codeflare.dev/appwrapper"
+
+// SetupTrainingJobWebhooksWithManager sets up the webhooks defaulting the Kueue queue of the Training Operator jobs
+// to the default queue of the AppWrappers, so all the workload types get a consistent queue assignment.
+func SetupTrainingJobWebhooksWithManager(mgr ctrl.Manager, defaultQueueName string) error {
+	trainingJobWebhookInstance := &trainingJobWebhook{
+		DefaultQueueName: defaultQueueName,
+	}
+	for _, obj := range []client.Object{&kubeflowv1.PyTorchJob{}, &kubeflowv1.TFJob{}} {
+		if err := ctrl.NewWebhookManagedBy(mgr).
+			For(obj).
+			WithDefaulter(trainingJobWebhookInstance).
+			Complete(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// +kubebuilder:webhook:path=/mutate-kubeflow-org-v1-pytorchjob,mutating=true,failurePolicy=fail,sideEffects=None,groups=kubeflow.org,resources=pytorchjobs,verbs=create,versions=v1,name=mpytorchjob.codeflare.dev,admissionReviewVersions=v1
+// +kubebuilder:webhook:path=/mutate-kubeflow-org-v1-tfjob,mutating=true,failurePolicy=fail,sideEffects=None,groups=kubeflow.org,resources=tfjobs,verbs=create,versions=v1,name=mtfjob.codeflare.dev,admissionReviewVersions=v1
+
+type trainingJobWebhook struct {
+	// DefaultQueueName is the Kueue queue the jobs are submitted to when they don't specify one
+	DefaultQueueName string
+}
+
+var _ webhook.CustomDefaulter = &trainingJobWebhook{}
+
+// Default implements webhook.Defaulter so a webhook will be registered for the type
+func (w *trainingJobWebhook) Default(_ context.Context, obj runtime.Object) error {
+	job, ok := obj.(client.Object)
+	if !ok {
+		return fmt.Errorf("expected a Kubernetes object but got %T", obj)
+	}
+
+	if applyDefaultQueueName(job, w.DefaultQueueName) {
+		trainingjoblog.V(2).Info("Applied default queue", "kind", obj.GetObjectKind().GroupVersionKind().Kind,
+			"namespace", job.GetNamespace(), "name", job.GetName(), "queue", w.DefaultQueueName)
+	}
+
+	return nil
+}
+
+// applyDefaultQueueName submits the workload to the default queue, unless it specifies a queue already,
+// or it's wrapped in an AppWrapper, that's admitted by Kueue as a whole. It returns whether the queue is defaulted.
+func applyDefaultQueueName(obj client.Object, defaultQueueName string) bool {
+	labels := obj.GetLabels()
+	if _, wrapped := labels[AppWrapperLabel]; wrapped || defaultQueueName == "" {
+		return false
+	}
+	if _, queued := labels[kueueconstants.QueueLabel]; queued {
+		return false
+	}
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[kueueconstants.QueueLabel] = defaultQueueName
+	obj.SetLabels(labels)
+	return true
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	kubeflowv1 "github.com/kubeflow/training-operator/pkg/apis/kubeflow.org/v1"
+	. "github.com/onsi/gomega"
+	"github.com/project-codeflare/codeflare-common/support"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kueueconstants "sigs.k8s.io/kueue/pkg/controller/constants"
+)
+
+func TestTrainingJobDefaultQueue(t *testing.T) {
+	test := support.NewTest(t)
+
+	w := &trainingJobWebhook{DefaultQueueName: "default-queue"}
+
+	test.T().Run("Expected default queue to be set on PyTorchJobs and TFJobs without a queue", func(t *testing.T) {
+		pytorchJob := &kubeflowv1.PyTorchJob{ObjectMeta: metav1.ObjectMeta{Name: "pytorchjob", Namespace: namespace}}
+		test.Expect(w.Default(test.Ctx(), pytorchJob)).To(Succeed())
+		test.Expect(pytorchJob.Labels).To(HaveKeyWithValue(kueueconstants.QueueLabel, "default-queue"))
+
+		tfJob := &kubeflowv1.TFJob{ObjectMeta: metav1.ObjectMeta{Name: "tfjob", Namespace: namespace, Labels: map[string]string{"app": "tf"}}}
+		test.Expect(w.Default(test.Ctx(), tfJob)).To(Succeed())
+		test.Expect(tfJob.Labels).To(Equal(map[string]string{"app": "tf", kueueconstants.QueueLabel: "default-queue"}))
+	})
+
+	test.T().Run("Expected queue of the job to be kept", func(t *testing.T) {
+		pytorchJob := &kubeflowv1.PyTorchJob{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{kueueconstants.QueueLabel: "team-a"}}}
+		test.Expect(w.Default(test.Ctx(), pytorchJob)).To(Succeed())
+		test.Expect(pytorchJob.Labels).To(HaveKeyWithValue(kueueconstants.QueueLabel, "team-a"))
+	})
+
+	test.T().Run("Expected no queue to be set on jobs wrapped in AppWrappers", func(t *testing.T) {
+		pytorchJob := &kubeflowv1.PyTorchJob{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{AppWrapperLabel: "appwrapper"}}}
+		test.Expect(w.Default(test.Ctx(), pytorchJob)).To(Succeed())
+		test.Expect(pytorchJob.Labels).NotTo(HaveKey(kueueconstants.QueueLabel))
+	})
+
+	test.T().Run("Expected no queue to be set when there is no default queue", func(t *testing.T) {
+		pytorchJob := &kubeflowv1.PyTorchJob{}
+		test.Expect((&trainingJobWebhook{}).Default(test.Ctx(), pytorchJob)).To(Succeed())
+		test.Expect(pytorchJob.Labels).To(BeEmpty())
+	})
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/project-codeflare/codeflare-operator/pkg/config"
+)
+
+const webhookConfigurationControllerName = "codeflare-webhook-configuration-controller"
+
+// WebhookConfigurationReconciler applies the failure policy, and the namespace and object selectors, of the
+// webhook configuration of the operator, to the webhooks of its MutatingWebhookConfiguration and
+// ValidatingWebhookConfiguration. The CA bundles of the webhooks are managed by the certificate rotator,
+// and the updates are rejected on conflicts with it, so that they're retried on the latest version.
+type WebhookConfigurationReconciler struct {
+	client.Client
+	Config                             *config.WebhookConfiguration
+	MutatingWebhookConfigurationName   string
+	ValidatingWebhookConfigurationName string
+}
+
+// +kubebuilder:rbac:groups="admissionregistration.k8s.io",resources=mutatingwebhookconfigurations,verbs=get;list;watch;update
+// +kubebuilder:rbac:groups="admissionregistration.k8s.io",resources=validatingwebhookconfigurations,verbs=get;list;watch;update
+
+func (r *WebhookConfigurationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := ctrl.LoggerFrom(ctx)
+
+	failurePolicy := ptr.Deref(r.Config.FailurePolicy, admissionregistrationv1.Fail)
+	namespaceSelector := webhookNamespaceSelector(r.Config)
+
+	switch req.Name {
+	case r.MutatingWebhookConfigurationName:
+		webhookConfiguration := &admissionregistrationv1.MutatingWebhookConfiguration{}
+		if err := r.Get(ctx, req.NamespacedName, webhookConfiguration); err != nil {
+			return ctrl.Result{}, client.IgnoreNotFound(err)
+		}
+		changed := false
+		for i := range webhookConfiguration.Webhooks {
+			webhook := &webhookConfiguration.Webhooks[i]
+			changed = applyWebhookConfiguration(&webhook.FailurePolicy, &webhook.NamespaceSelector, &webhook.ObjectSelector,
+				failurePolicy, namespaceSelector, r.Config.ObjectSelector) || changed
+		}
+		if changed {
+			logger.Info("Updating webhooks", "failurePolicy", failurePolicy)
+			return ctrl.Result{}, r.Update(ctx, webhookConfiguration)
+		}
+
+	case r.ValidatingWebhookConfigurationName:
+		webhookConfiguration := &admissionregistrationv1.ValidatingWebhookConfiguration{}
+		if err := r.Get(ctx, req.NamespacedName, webhookConfiguration); err != nil {
+			return ctrl.Result{}, client.IgnoreNotFound(err)
+		}
+		changed := false
+		for i := range webhookConfiguration.Webhooks {
+			webhook := &webhookConfiguration.Webhooks[i]
+			changed = applyWebhookConfiguration(&webhook.FailurePolicy, &webhook.NamespaceSelector, &webhook.ObjectSelector,
+				failurePolicy, namespaceSelector, r.Config.ObjectSelector) || changed
+		}
+		if changed {
+			logger.Info("Updating webhooks", "failurePolicy", failurePolicy)
+			return ctrl.Result{}, r.Update(ctx, webhookConfiguration)
+		}
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// webhookNamespaceSelector returns the namespace selector of the webhooks, i.e., the configured selector,
+// that also excludes the excluded namespaces, or nil to match all the namespaces.
+func webhookNamespaceSelector(cfg *config.WebhookConfiguration) *metav1.LabelSelector {
+	if cfg.NamespaceSelector == nil && len(cfg.ExcludedNamespaces) == 0 {
+		return nil
+	}
+	selector := &metav1.LabelSelector{}
+	if cfg.NamespaceSelector != nil {
+		selector = cfg.NamespaceSelector.DeepCopy()
+	}
+	if len(cfg.ExcludedNamespaces) > 0 {
+		selector.MatchExpressions = append(selector.MatchExpressions, metav1.LabelSelectorRequirement{
+			Key:      corev1.LabelMetadataName,
+			Operator: metav1.LabelSelectorOpNotIn,
+			Values:   cfg.ExcludedNamespaces,
+		})
+	}
+	return selector
+}
+
+// applyWebhookConfiguration sets the failure policy and the selectors of a webhook, and returns whether they've changed.
+// The API server defaults the selectors to the empty selector, that matches everything.
+func applyWebhookConfiguration(failurePolicy **admissionregistrationv1.FailurePolicyType, namespaceSelector, objectSelector **metav1.LabelSelector,
+	desiredFailurePolicy admissionregistrationv1.FailurePolicyType, desiredNamespaceSelector, desiredObjectSelector *metav1.LabelSelector) bool {
+	if desiredNamespaceSelector == nil {
+		desiredNamespaceSelector = &metav1.LabelSelector{}
+	}
+	if desiredObjectSelector == nil {
+		desiredObjectSelector = &metav1.LabelSelector{}
+	}
+
+	changed := false
+	if ptr.Deref(*failurePolicy, admissionregistrationv1.Fail) != desiredFailurePolicy {
+		*failurePolicy = ptr.To(desiredFailurePolicy)
+		changed = true
+	}
+	if !equality.Semantic.DeepEqual(ptr.Deref(*namespaceSelector, metav1.LabelSelector{}), *desiredNamespaceSelector) {
+		*namespaceSelector = desiredNamespaceSelector.DeepCopy()
+		changed = true
+	}
+	if !equality.Semantic.DeepEqual(ptr.Deref(*objectSelector, metav1.LabelSelector{}), *desiredObjectSelector) {
+		*objectSelector = desiredObjectSelector.DeepCopy()
+		changed = true
+	}
+	return changed
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *WebhookConfigurationReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	isOperatorWebhookConfiguration := predicate.NewPredicateFuncs(func(object client.Object) bool {
+		return object.GetName() == r.MutatingWebhookConfigurationName || object.GetName() == r.ValidatingWebhookConfigurationName
+	})
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(webhookConfigurationControllerName).
+		For(&admissionregistrationv1.MutatingWebhookConfiguration{}, builder.WithPredicates(isOperatorWebhookConfiguration)).
+		Watches(&admissionregistrationv1.ValidatingWebhookConfiguration{},
+			handler.EnqueueRequestsFromMapFunc(func(_ context.Context, object client.Object) []reconcile.Request {
+				return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: object.GetName()}}}
+			}),
+			builder.WithPredicates(isOperatorWebhookConfiguration)).
+		Complete(instrument(webhookConfigurationControllerName, "WebhookConfiguration", r))
+}
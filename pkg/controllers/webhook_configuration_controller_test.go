@@ -0,0 +1,138 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/project-codeflare/codeflare-common/support"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/project-codeflare/codeflare-operator/pkg/config"
+)
+
+func TestWebhookConfigurationReconcile(t *testing.T) {
+	test := support.NewTest(t)
+	ctx := context.Background()
+
+	scheme := runtime.NewScheme()
+	test.Expect(admissionregistrationv1.AddToScheme(scheme)).To(Succeed())
+
+	caBundle := []byte("ca-bundle")
+	mutatingWebhookConfiguration := func() *admissionregistrationv1.MutatingWebhookConfiguration {
+		return &admissionregistrationv1.MutatingWebhookConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: "codeflare-operator-mutating-webhook-configuration"},
+			Webhooks: []admissionregistrationv1.MutatingWebhook{
+				{Name: "mraycluster.ray.openshift.ai", FailurePolicy: ptr.To(admissionregistrationv1.Fail), ClientConfig: admissionregistrationv1.WebhookClientConfig{CABundle: caBundle}},
+				{Name: "mrayjob.ray.openshift.ai", FailurePolicy: ptr.To(admissionregistrationv1.Fail), ClientConfig: admissionregistrationv1.WebhookClientConfig{CABundle: caBundle}},
+			},
+		}
+	}
+	validatingWebhookConfiguration := func() *admissionregistrationv1.ValidatingWebhookConfiguration {
+		return &admissionregistrationv1.ValidatingWebhookConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: "codeflare-operator-validating-webhook-configuration"},
+			Webhooks: []admissionregistrationv1.ValidatingWebhook{
+				{Name: "vraycluster.ray.openshift.ai", FailurePolicy: ptr.To(admissionregistrationv1.Fail), ClientConfig: admissionregistrationv1.WebhookClientConfig{CABundle: caBundle}},
+			},
+		}
+	}
+	reconcile := func(cfg *config.WebhookConfiguration, objects ...client.Object) client.Client {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+		r := &WebhookConfigurationReconciler{
+			Client:                             c,
+			Config:                             cfg,
+			MutatingWebhookConfigurationName:   "codeflare-operator-mutating-webhook-configuration",
+			ValidatingWebhookConfigurationName: "codeflare-operator-validating-webhook-configuration",
+		}
+		for _, object := range objects {
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: object.GetName()}})
+			test.Expect(err).NotTo(HaveOccurred())
+		}
+		return c
+	}
+
+	test.T().Run("Expected failure policy and selectors to be applied to all the webhooks", func(t *testing.T) {
+		cfg := &config.WebhookConfiguration{
+			FailurePolicy:      ptr.To(admissionregistrationv1.Ignore),
+			ExcludedNamespaces: []string{"kube-system", "openshift-monitoring"},
+			NamespaceSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"opendatahub.io/dashboard": "true"},
+			},
+			ObjectSelector: &metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "codeflare.dev/skip-webhooks", Operator: metav1.LabelSelectorOpDoesNotExist}},
+			},
+		}
+		c := reconcile(cfg, mutatingWebhookConfiguration(), validatingWebhookConfiguration())
+
+		expectedNamespaceSelector := &metav1.LabelSelector{
+			MatchLabels: map[string]string{"opendatahub.io/dashboard": "true"},
+			MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: corev1.LabelMetadataName, Operator: metav1.LabelSelectorOpNotIn, Values: []string{"kube-system", "openshift-monitoring"}},
+			},
+		}
+
+		mutating := &admissionregistrationv1.MutatingWebhookConfiguration{}
+		test.Expect(c.Get(ctx, types.NamespacedName{Name: "codeflare-operator-mutating-webhook-configuration"}, mutating)).To(Succeed())
+		test.Expect(mutating.Webhooks).To(HaveLen(2))
+		for _, webhook := range mutating.Webhooks {
+			test.Expect(webhook.FailurePolicy).To(Equal(ptr.To(admissionregistrationv1.Ignore)))
+			test.Expect(webhook.NamespaceSelector).To(Equal(expectedNamespaceSelector))
+			test.Expect(webhook.ObjectSelector).To(Equal(cfg.ObjectSelector))
+			test.Expect(webhook.ClientConfig.CABundle).To(Equal(caBundle))
+		}
+
+		validating := &admissionregistrationv1.ValidatingWebhookConfiguration{}
+		test.Expect(c.Get(ctx, types.NamespacedName{Name: "codeflare-operator-validating-webhook-configuration"}, validating)).To(Succeed())
+		test.Expect(validating.Webhooks).To(HaveLen(1))
+		test.Expect(validating.Webhooks[0].FailurePolicy).To(Equal(ptr.To(admissionregistrationv1.Ignore)))
+		test.Expect(validating.Webhooks[0].NamespaceSelector).To(Equal(expectedNamespaceSelector))
+		test.Expect(validating.Webhooks[0].ObjectSelector).To(Equal(cfg.ObjectSelector))
+	})
+
+	test.T().Run("Expected webhooks to be reverted to their defaults", func(t *testing.T) {
+		mutating := mutatingWebhookConfiguration()
+		mutating.Webhooks[0].FailurePolicy = ptr.To(admissionregistrationv1.Ignore)
+		mutating.Webhooks[0].NamespaceSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"team": "a"}}
+		c := reconcile(&config.WebhookConfiguration{}, mutating)
+
+		test.Expect(c.Get(ctx, client.ObjectKeyFromObject(mutating), mutating)).To(Succeed())
+		test.Expect(mutating.Webhooks[0].FailurePolicy).To(Equal(ptr.To(admissionregistrationv1.Fail)))
+		test.Expect(mutating.Webhooks[0].NamespaceSelector).To(Equal(&metav1.LabelSelector{}))
+	})
+
+	test.T().Run("Negative: Expected webhooks matching the configuration not to be updated", func(t *testing.T) {
+		validating := validatingWebhookConfiguration()
+		validating.Webhooks[0].NamespaceSelector = &metav1.LabelSelector{}
+		validating.Webhooks[0].ObjectSelector = &metav1.LabelSelector{}
+		c := reconcile(&config.WebhookConfiguration{}, validating)
+
+		updated := &admissionregistrationv1.ValidatingWebhookConfiguration{}
+		test.Expect(c.Get(ctx, client.ObjectKeyFromObject(validating), updated)).To(Succeed())
+		test.Expect(updated.ResourceVersion).To(Equal("999"))
+	})
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	admissionResultAllowed = "allowed"
+	admissionResultDenied  = "denied"
+	admissionResultWarned  = "warned"
+)
+
+var (
+	admissionDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "codeflare",
+		Name:      "webhook_admission_duration_seconds",
+		Help:      "Duration of the handling of the admission requests, partitioned by webhook path.",
+		Buckets:   []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+	}, []string{"webhook"})
+
+	admissionRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "codeflare",
+		Name:      "webhook_admission_requests_total",
+		Help:      "Number of admission requests, partitioned by webhook path, result (allowed, denied or warned) and, for the denied requests, reason, e.g., Invalid or Forbidden.",
+	}, []string{"webhook", "result", "reason"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(admissionDuration, admissionRequests)
+}
+
+// InstrumentedWebhookServer is a webhook.Server, that records the duration, and the result, of the admission requests
+// handled by the webhooks registered with it, including those of the embedded AppWrapper controller.
+type InstrumentedWebhookServer struct {
+	webhook.Server
+}
+
+// Register registers the webhook, whose admission handler is instrumented, at the path.
+func (s *InstrumentedWebhookServer) Register(path string, hook http.Handler) {
+	if admissionWebhook, ok := hook.(*admission.Webhook); ok {
+		admissionWebhook.Handler = instrumentedAdmissionHandler{webhook: path, handler: admissionWebhook.Handler}
+	}
+	s.Server.Register(path, hook)
+}
+
+type instrumentedAdmissionHandler struct {
+	webhook string
+	handler admission.Handler
+}
+
+func (h instrumentedAdmissionHandler) Handle(ctx context.Context, req admission.Request) admission.Response {
+	start := time.Now()
+	resp := h.handler.Handle(ctx, req)
+	admissionDuration.WithLabelValues(h.webhook).Observe(time.Since(start).Seconds())
+
+	result, reason := admissionResultAllowed, ""
+	switch {
+	case !resp.Allowed:
+		result, reason = admissionResultDenied, admissionDeniedReason(resp)
+	case len(resp.Warnings) > 0:
+		result = admissionResultWarned
+	}
+	admissionRequests.WithLabelValues(h.webhook, result, reason).Inc()
+	return resp
+}
+
+// admissionDeniedReason returns the reason of the denied admission response, e.g., Invalid for validation errors,
+// or the text of its status code, e.g., InternalServerError, when it has no reason.
+func admissionDeniedReason(resp admission.Response) string {
+	if resp.Result == nil {
+		return ""
+	}
+	if resp.Result.Reason != "" {
+		return string(resp.Result.Reason)
+	}
+	return strings.ReplaceAll(http.StatusText(int(resp.Result.Code)), " ", "")
+}
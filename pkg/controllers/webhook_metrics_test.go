@@ -0,0 +1,77 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/project-codeflare/codeflare-common/support"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func TestInstrumentedWebhookServer(t *testing.T) {
+	test := support.NewTest(t)
+
+	path := "/validate-test"
+	var resp admission.Response
+	hook := &admission.Webhook{Handler: admission.HandlerFunc(func(context.Context, admission.Request) admission.Response {
+		return resp
+	})}
+	server := &InstrumentedWebhookServer{Server: webhook.NewServer(webhook.Options{})}
+	server.Register(path, hook)
+	requests := func(result, reason string) float64 {
+		return testutil.ToFloat64(admissionRequests.WithLabelValues(path, result, reason))
+	}
+
+	test.T().Run("Expected admission requests to be timed, and counted by result and reason", func(t *testing.T) {
+		invalid := apierrors.NewInvalid(schema.GroupKind{Group: "ray.io", Kind: "RayCluster"}, "raycluster",
+			field.ErrorList{field.Forbidden(field.NewPath("spec", "headGroupSpec"), "host networking is prohibited")})
+		for _, r := range []admission.Response{
+			admission.Allowed(""),
+			admission.Allowed("").WithWarnings("the dashboard is not secured"),
+			admission.Denied("denied"),
+			admission.Errored(http.StatusInternalServerError, errors.New("cannot read LocalQueue")),
+		} {
+			resp = r
+			hook.Handle(context.Background(), admission.Request{})
+		}
+		resp = admission.Denied("")
+		resp.Result = &invalid.ErrStatus
+		hook.Handle(context.Background(), admission.Request{})
+
+		duration := &dto.Metric{}
+		test.Expect(admissionDuration.WithLabelValues(path).(prometheus.Histogram).Write(duration)).To(Succeed())
+		test.Expect(duration.GetHistogram().GetSampleCount()).To(Equal(uint64(5)))
+		test.Expect(requests(admissionResultAllowed, "")).To(Equal(float64(1)))
+		test.Expect(requests(admissionResultWarned, "")).To(Equal(float64(1)))
+		test.Expect(requests(admissionResultDenied, "Forbidden")).To(Equal(float64(1)))
+		test.Expect(requests(admissionResultDenied, "InternalServerError")).To(Equal(float64(1)))
+		test.Expect(requests(admissionResultDenied, "Invalid")).To(Equal(float64(1)))
+	})
+}
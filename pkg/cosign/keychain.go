@@ -0,0 +1,79 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cosign
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// dockerConfig is the content of the dockerconfigjson, and legacy dockercfg, pull secrets
+type dockerConfig struct {
+	Auths map[string]authn.AuthConfig `json:"auths"`
+}
+
+type pullSecretKeychain map[string]authn.AuthConfig
+
+// PullSecretKeychain returns the keychain of the credentials of the image pull secrets, or anonymous access
+// to the registries they have no credentials for.
+func PullSecretKeychain(secrets []corev1.Secret) authn.Keychain {
+	keychain := pullSecretKeychain{}
+	for _, secret := range secrets {
+		config := dockerConfig{}
+		switch secret.Type {
+		case corev1.SecretTypeDockerConfigJson:
+			if err := json.Unmarshal(secret.Data[corev1.DockerConfigJsonKey], &config); err != nil {
+				continue
+			}
+		case corev1.SecretTypeDockercfg:
+			if err := json.Unmarshal(secret.Data[corev1.DockerConfigKey], &config.Auths); err != nil {
+				continue
+			}
+		default:
+			continue
+		}
+		for registry, auth := range config.Auths {
+			registry = registryHost(registry)
+			if _, ok := keychain[registry]; !ok {
+				keychain[registry] = auth
+			}
+		}
+	}
+	return keychain
+}
+
+// registryHost returns the host of the registry key of the docker config, e.g., https://index.docker.io/v1/.
+func registryHost(registry string) string {
+	registry = strings.TrimPrefix(strings.TrimPrefix(registry, "https://"), "http://")
+	registry, _, _ = strings.Cut(registry, "/")
+	if registry == "docker.io" {
+		return name.DefaultRegistry
+	}
+	return registry
+}
+
+func (k pullSecretKeychain) Resolve(resource authn.Resource) (authn.Authenticator, error) {
+	if auth, ok := k[resource.RegistryStr()]; ok {
+		return authn.FromConfig(auth), nil
+	}
+	return authn.Anonymous, nil
+}
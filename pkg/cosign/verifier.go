@@ -0,0 +1,184 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package cosign verifies the cosign signatures of container images, i.e., the signatures pushed by cosign sign
+// to the registry of the images, as the sha256-<digest>.sig tag, either against public keys, or keyless, against
+// the identities of the certificates issued by Fulcio, whose signatures are logged in Rekor. The signatures are
+// verified by the verification packages of cosign.
+package cosign
+
+import (
+	"context"
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	sigstorecosign "github.com/sigstore/cosign/v2/pkg/cosign"
+	ociremote "github.com/sigstore/cosign/v2/pkg/oci/remote"
+	"github.com/sigstore/sigstore/pkg/signature"
+	"github.com/sigstore/sigstore/pkg/tuf"
+
+	"k8s.io/utils/ptr"
+
+	"github.com/project-codeflare/codeflare-operator/pkg/config"
+)
+
+// VerificationError is returned when the image isn't signed, or none of its signatures is verified,
+// as opposed to the errors requesting its registry, that may be transient.
+type VerificationError struct {
+	Image  string
+	Reason string
+}
+
+func (e *VerificationError) Error() string {
+	return fmt.Sprintf("image %s is not verified: %s", e.Image, e.Reason)
+}
+
+// Verifier verifies the images are signed by one of its public keys, or keyless identities.
+type Verifier struct {
+	// checkOpts are the cosign verification options of each public key, and of the keyless identities
+	checkOpts []*sigstorecosign.CheckOpts
+	// transport requests the registries
+	transport http.RoundTripper
+}
+
+// NewVerifier returns the verifier of the configuration, or an error if its keys, or certificates, are invalid.
+func NewVerifier(cfg *config.ImageVerificationConfiguration) (*Verifier, error) {
+	if cfg == nil || !ptr.Deref(cfg.Enabled, false) {
+		return nil, errors.New("image verification is not enabled")
+	}
+	if len(cfg.PublicKeys) == 0 && len(cfg.KeylessIdentities) == 0 {
+		return nil, errors.New("at least one public key, or keyless identity, is required")
+	}
+
+	v := &Verifier{transport: remote.DefaultTransport}
+	for i, key := range cfg.PublicKeys {
+		publicKey, err := parsePublicKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid public key %d: %w", i, err)
+		}
+		verifier, err := signature.LoadVerifier(publicKey, crypto.SHA256)
+		if err != nil {
+			return nil, fmt.Errorf("invalid public key %d: %w", i, err)
+		}
+		v.checkOpts = append(v.checkOpts, &sigstorecosign.CheckOpts{
+			SigVerifier:   verifier,
+			ClaimVerifier: sigstorecosign.SimpleClaimVerifier,
+			// The signatures with keys aren't required to be logged in Rekor
+			IgnoreTlog: true,
+		})
+	}
+	if len(cfg.KeylessIdentities) == 0 {
+		return v, nil
+	}
+
+	var identities []sigstorecosign.Identity
+	for _, identity := range cfg.KeylessIdentities {
+		if identity.Issuer == "" || identity.Subject == "" {
+			return nil, errors.New("the keyless identities require an issuer and a subject")
+		}
+		identities = append(identities, sigstorecosign.Identity{Issuer: identity.Issuer, Subject: identity.Subject})
+	}
+	fulcioRoots := x509.NewCertPool()
+	if !fulcioRoots.AppendCertsFromPEM([]byte(cfg.FulcioRoots)) {
+		return nil, errors.New("the keyless identities require the PEM-encoded Fulcio root certificates")
+	}
+	if _, err := parsePublicKey(cfg.RekorPublicKey); err != nil {
+		return nil, fmt.Errorf("invalid Rekor public key: %w", err)
+	}
+	rekorPublicKeys := sigstorecosign.NewTrustedTransparencyLogPubKeys()
+	if err := rekorPublicKeys.AddTransparencyLogPubKey([]byte(cfg.RekorPublicKey), tuf.Active); err != nil {
+		return nil, fmt.Errorf("invalid Rekor public key: %w", err)
+	}
+	v.checkOpts = append(v.checkOpts, &sigstorecosign.CheckOpts{
+		RootCerts:     fulcioRoots,
+		Identities:    identities,
+		ClaimVerifier: sigstorecosign.SimpleClaimVerifier,
+		RekorPubKeys:  &rekorPublicKeys,
+		// The signatures are verified against the Rekor bundles, i.e., the entries of the transparency log
+		// signed by Rekor, attached to the signatures, so that Rekor isn't requested on admission, and the
+		// Fulcio certificates, that have expired, against the time the signatures were logged.
+		Offline: true,
+		// The certificate transparency log of Fulcio isn't configured
+		IgnoreSCT: true,
+	})
+	return v, nil
+}
+
+func parsePublicKey(key string) (crypto.PublicKey, error) {
+	block, _ := pem.Decode([]byte(key))
+	if block == nil {
+		return nil, errors.New("no PEM-encoded public key found")
+	}
+	return x509.ParsePKIXPublicKey(block.Bytes)
+}
+
+// Verify verifies the image, authenticating to its registry with the keychain, is signed by one of the public keys,
+// or keyless identities, of the verifier, and returns the digest of its manifest, the image can be pinned to, so that
+// the manifest that's run is the one that's verified. It returns a VerificationError when it's not.
+func (v *Verifier) Verify(ctx context.Context, image string, keychain authn.Keychain) (v1.Hash, error) {
+	ref, err := name.ParseReference(image)
+	if err != nil {
+		return v1.Hash{}, &VerificationError{Image: image, Reason: err.Error()}
+	}
+	options := []ociremote.Option{
+		ociremote.WithRemoteOptions(remote.WithContext(ctx), remote.WithAuthFromKeychain(keychain), remote.WithTransport(v.transport)),
+	}
+
+	digest, err := ociremote.ResolveDigest(ref, options...)
+	if err != nil {
+		return v1.Hash{}, fmt.Errorf("cannot get manifest of image %s: %w", image, err)
+	}
+	hash, err := v1.NewHash(digest.DigestStr())
+	if err != nil {
+		return v1.Hash{}, fmt.Errorf("cannot get manifest of image %s: %w", image, err)
+	}
+	signatureTag, err := ociremote.SignatureTag(digest, options...)
+	if err != nil {
+		return v1.Hash{}, fmt.Errorf("cannot get signatures of image %s: %w", image, err)
+	}
+	signatures, err := ociremote.Signatures(signatureTag, options...)
+	if err != nil {
+		return v1.Hash{}, fmt.Errorf("cannot get signatures of image %s: %w", image, err)
+	}
+	list, err := signatures.Get()
+	if err != nil {
+		return v1.Hash{}, fmt.Errorf("cannot get signatures of image %s: %w", image, err)
+	}
+	if len(list) == 0 {
+		return v1.Hash{}, &VerificationError{Image: image, Reason: "no signature found"}
+	}
+
+	var reasons []string
+	for _, sig := range list {
+		for _, checkOpts := range v.checkOpts {
+			if _, err := sigstorecosign.VerifyImageSignature(ctx, sig, hash, checkOpts); err != nil {
+				reasons = append(reasons, err.Error())
+				continue
+			}
+			return hash, nil
+		}
+	}
+	return v1.Hash{}, &VerificationError{Image: image, Reason: strings.Join(reasons, ", ")}
+}
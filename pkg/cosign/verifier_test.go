@@ -0,0 +1,347 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cosign
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cyberphone/json-canonicalization/go/src/webpki.org/jsoncanonicalizer"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	ggcrstatic "github.com/google/go-containerregistry/pkg/v1/static"
+	. "github.com/onsi/gomega"
+	"github.com/project-codeflare/codeflare-common/support"
+	"github.com/sigstore/cosign/v2/pkg/cosign/bundle"
+	"github.com/sigstore/cosign/v2/pkg/oci/static"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+
+	"github.com/project-codeflare/codeflare-operator/pkg/config"
+)
+
+// issuerExtension is the extension of the Fulcio certificates holding the OIDC issuer of the identity
+var issuerExtension = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 1}
+
+func generateKey(t *testing.T) (*ecdsa.PrivateKey, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+func sign(t *testing.T, key *ecdsa.PrivateKey, message []byte) []byte {
+	t.Helper()
+	hash := sha256.Sum256(message)
+	signature, err := ecdsa.SignASN1(rand.Reader, key, hash[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signature
+}
+
+// pushImage pushes a random image to the registry, with a unique tag, and returns its reference, its digest, and the
+// signed payload referencing its digest.
+func pushImage(t *testing.T, host string) (string, v1.Hash, []byte) {
+	t.Helper()
+	image, err := random.Image(1024, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := name.ParseReference(fmt.Sprintf("%s/ray:2.35.0-%d", host, time.Now().UnixNano()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.Write(ref, image); err != nil {
+		t.Fatal(err)
+	}
+	digest, err := image.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload := fmt.Sprintf(`{"critical":{"identity":{"docker-reference":"%s"},"image":{"docker-manifest-digest":"%s"},"type":"cosign container image signature"},"optional":null}`,
+		ref.Context().Name(), digest)
+	return ref.String(), digest, []byte(payload)
+}
+
+// pushSignature pushes the signature of the image, with the annotations of the signed payload layer, as cosign does.
+func pushSignature(t *testing.T, image string, payload []byte, annotations map[string]string) {
+	t.Helper()
+	ref, err := name.ParseReference(image)
+	if err != nil {
+		t.Fatal(err)
+	}
+	descriptor, err := remote.Head(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signatures, err := mutate.Append(empty.Image, mutate.Addendum{
+		Layer:       ggcrstatic.NewLayer(payload, "application/vnd.dev.cosign.simplesigning.v1+json"),
+		Annotations: annotations,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.Write(ref.Context().Tag(fmt.Sprintf("sha256-%s.sig", descriptor.Digest.Hex)), signatures); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	test := support.NewTest(t)
+	ctx := context.Background()
+
+	server := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	t.Cleanup(server.Close)
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	key, publicKey := generateKey(t)
+	_, otherPublicKey := generateKey(t)
+
+	newVerifier := func(cfg *config.ImageVerificationConfiguration) *Verifier {
+		cfg.Enabled = ptr.To(true)
+		verifier, err := NewVerifier(cfg)
+		test.Expect(err).NotTo(HaveOccurred())
+		return verifier
+	}
+
+	test.T().Run("Expected image signed with a public key to be verified", func(t *testing.T) {
+		image, digest, payload := pushImage(t, host)
+		pushSignature(t, image, payload, map[string]string{
+			static.SignatureAnnotationKey: base64.StdEncoding.EncodeToString(sign(t, key, payload)),
+		})
+
+		verifier := newVerifier(&config.ImageVerificationConfiguration{PublicKeys: []string{otherPublicKey, publicKey}})
+		test.Expect(verifier.Verify(ctx, image, authn.DefaultKeychain)).To(Equal(digest))
+	})
+
+	test.T().Run("Negative: Expected unsigned image not to be verified", func(t *testing.T) {
+		image, _, _ := pushImage(t, host)
+
+		verifier := newVerifier(&config.ImageVerificationConfiguration{PublicKeys: []string{publicKey}})
+		_, err := verifier.Verify(ctx, image, authn.DefaultKeychain)
+		test.Expect(err).To(BeAssignableToTypeOf(&VerificationError{}))
+		test.Expect(err).To(MatchError(ContainSubstring("no signature found")))
+	})
+
+	test.T().Run("Negative: Expected image signed with another key not to be verified", func(t *testing.T) {
+		image, _, payload := pushImage(t, host)
+		pushSignature(t, image, payload, map[string]string{
+			static.SignatureAnnotationKey: base64.StdEncoding.EncodeToString(sign(t, key, payload)),
+		})
+
+		verifier := newVerifier(&config.ImageVerificationConfiguration{PublicKeys: []string{otherPublicKey}})
+		_, err := verifier.Verify(ctx, image, authn.DefaultKeychain)
+		test.Expect(err).To(BeAssignableToTypeOf(&VerificationError{}))
+		test.Expect(err).To(MatchError(ContainSubstring("invalid signature")))
+	})
+
+	test.T().Run("Negative: Expected signature of another image not to be verified", func(t *testing.T) {
+		image, _, _ := pushImage(t, host)
+		_, _, otherPayload := pushImage(t, host)
+		pushSignature(t, image, otherPayload, map[string]string{
+			static.SignatureAnnotationKey: base64.StdEncoding.EncodeToString(sign(t, key, otherPayload)),
+		})
+
+		verifier := newVerifier(&config.ImageVerificationConfiguration{PublicKeys: []string{publicKey}})
+		_, err := verifier.Verify(ctx, image, authn.DefaultKeychain)
+		test.Expect(err).To(BeAssignableToTypeOf(&VerificationError{}))
+		test.Expect(err).To(MatchError(ContainSubstring("invalid or missing digest in claim")))
+	})
+
+	// Fulcio issues short-lived certificates, that have expired when the images are verified,
+	// but were valid when the signatures were logged in Rekor
+	fulcioKey, _ := generateKey(t)
+	fulcioTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "sigstore"},
+		NotBefore:             time.Now().Add(-24 * time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	fulcioDER, err := x509.CreateCertificate(rand.Reader, fulcioTemplate, fulcioTemplate, &fulcioKey.PublicKey, fulcioKey)
+	test.Expect(err).NotTo(HaveOccurred())
+	fulcioRoot, err := x509.ParseCertificate(fulcioDER)
+	test.Expect(err).NotTo(HaveOccurred())
+	fulcioRoots := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: fulcioDER}))
+	rekorKey, rekorPublicKey := generateKey(t)
+
+	signKeyless := func(t *testing.T, image string, payload []byte, email string, signedAt time.Time) {
+		signingKey, _ := generateKey(t)
+		der, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+			SerialNumber:    big.NewInt(2),
+			NotBefore:       signedAt.Add(-time.Minute),
+			NotAfter:        signedAt.Add(10 * time.Minute),
+			KeyUsage:        x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:     []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+			EmailAddresses:  []string{email},
+			ExtraExtensions: []pkix.Extension{{Id: issuerExtension, Value: []byte("https://github.com/login/oauth")}},
+		}, fulcioRoot, &signingKey.PublicKey, fulcioKey)
+		test.Expect(err).NotTo(HaveOccurred())
+		certificate := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+		signature := sign(t, signingKey, payload)
+
+		hash := sha256.Sum256(payload)
+		body, err := json.Marshal(map[string]any{
+			"apiVersion": "0.0.1",
+			"kind":       "hashedrekord",
+			"spec": map[string]any{
+				"data":      map[string]any{"hash": map[string]string{"algorithm": "sha256", "value": hex.EncodeToString(hash[:])}},
+				"signature": map[string]any{"content": signature, "publicKey": map[string]any{"content": certificate}},
+			},
+		})
+		test.Expect(err).NotTo(HaveOccurred())
+		rekorDER, err := x509.MarshalPKIXPublicKey(&rekorKey.PublicKey)
+		test.Expect(err).NotTo(HaveOccurred())
+		logID := sha256.Sum256(rekorDER)
+		entry := bundle.RekorPayload{
+			Body:           base64.StdEncoding.EncodeToString(body),
+			IntegratedTime: signedAt.Unix(),
+			LogID:          hex.EncodeToString(logID[:]),
+			LogIndex:       42,
+		}
+		// Rekor signs the canonical JSON of the entry
+		contents, err := json.Marshal(entry)
+		test.Expect(err).NotTo(HaveOccurred())
+		canonical, err := jsoncanonicalizer.Transform(contents)
+		test.Expect(err).NotTo(HaveOccurred())
+		rekorBundle, err := json.Marshal(bundle.RekorBundle{SignedEntryTimestamp: sign(t, rekorKey, canonical), Payload: entry})
+		test.Expect(err).NotTo(HaveOccurred())
+
+		pushSignature(t, image, payload, map[string]string{
+			static.SignatureAnnotationKey:   base64.StdEncoding.EncodeToString(signature),
+			static.CertificateAnnotationKey: string(certificate),
+			static.BundleAnnotationKey:      string(rekorBundle),
+		})
+	}
+	identity := config.KeylessIdentity{Issuer: "https://github.com/login/oauth", Subject: "release@example.com"}
+
+	test.T().Run("Expected image signed keyless by the identity to be verified", func(t *testing.T) {
+		image, digest, payload := pushImage(t, host)
+		signKeyless(t, image, payload, "release@example.com", time.Now().Add(-time.Hour))
+
+		verifier := newVerifier(&config.ImageVerificationConfiguration{
+			KeylessIdentities: []config.KeylessIdentity{identity},
+			FulcioRoots:       fulcioRoots,
+			RekorPublicKey:    rekorPublicKey,
+		})
+		test.Expect(verifier.Verify(ctx, image, authn.DefaultKeychain)).To(Equal(digest))
+	})
+
+	test.T().Run("Negative: Expected image signed keyless by another identity not to be verified", func(t *testing.T) {
+		image, _, payload := pushImage(t, host)
+		signKeyless(t, image, payload, "someone@example.com", time.Now().Add(-time.Hour))
+
+		verifier := newVerifier(&config.ImageVerificationConfiguration{
+			KeylessIdentities: []config.KeylessIdentity{identity},
+			FulcioRoots:       fulcioRoots,
+			RekorPublicKey:    rekorPublicKey,
+		})
+		_, err := verifier.Verify(ctx, image, authn.DefaultKeychain)
+		test.Expect(err).To(BeAssignableToTypeOf(&VerificationError{}))
+		test.Expect(err).To(MatchError(ContainSubstring("none of the expected identities matched")))
+	})
+
+	test.T().Run("Negative: Expected keyless signature not logged in the trusted Rekor not to be verified", func(t *testing.T) {
+		image, _, payload := pushImage(t, host)
+		signKeyless(t, image, payload, "release@example.com", time.Now().Add(-time.Hour))
+		_, otherRekorPublicKey := generateKey(t)
+
+		verifier := newVerifier(&config.ImageVerificationConfiguration{
+			KeylessIdentities: []config.KeylessIdentity{identity},
+			FulcioRoots:       fulcioRoots,
+			RekorPublicKey:    otherRekorPublicKey,
+		})
+		_, err := verifier.Verify(ctx, image, authn.DefaultKeychain)
+		test.Expect(err).To(BeAssignableToTypeOf(&VerificationError{}))
+		test.Expect(err).To(MatchError(ContainSubstring("rekor log public key not found")))
+	})
+
+	test.T().Run("Negative: Expected invalid configurations to be rejected", func(t *testing.T) {
+		_, err := NewVerifier(&config.ImageVerificationConfiguration{Enabled: ptr.To(true)})
+		test.Expect(err).To(MatchError("at least one public key, or keyless identity, is required"))
+
+		_, err = NewVerifier(&config.ImageVerificationConfiguration{Enabled: ptr.To(true), PublicKeys: []string{"invalid"}})
+		test.Expect(err).To(MatchError("invalid public key 0: no PEM-encoded public key found"))
+
+		_, err = NewVerifier(&config.ImageVerificationConfiguration{Enabled: ptr.To(true), KeylessIdentities: []config.KeylessIdentity{identity}})
+		test.Expect(err).To(MatchError("the keyless identities require the PEM-encoded Fulcio root certificates"))
+	})
+}
+
+func TestPullSecretKeychain(t *testing.T) {
+	test := support.NewTest(t)
+
+	keychain := PullSecretKeychain([]corev1.Secret{
+		{
+			Type: corev1.SecretTypeDockerConfigJson,
+			Data: map[string][]byte{corev1.DockerConfigJsonKey: []byte(`{"auths": {"https://index.docker.io/v1/": {"username": "user", "password": "secret"}}}`)},
+		},
+		{
+			Type: corev1.SecretTypeDockercfg,
+			Data: map[string][]byte{corev1.DockerConfigKey: []byte(`{"quay.io": {"auth": "dXNlcjpzZWNyZXQ="}}`)},
+		},
+	})
+
+	for _, image := range []string{"rayproject/ray:2.35.0", "quay.io/modh/ray:2.35.0-py311-cu121"} {
+		ref, err := name.ParseReference(image)
+		test.Expect(err).NotTo(HaveOccurred())
+		authenticator, err := keychain.Resolve(ref.Context())
+		test.Expect(err).NotTo(HaveOccurred())
+		test.Expect(authn.Authorization(context.Background(), authenticator)).To(Or(
+			HaveField("Username", "user"),
+			HaveField("Auth", "dXNlcjpzZWNyZXQ="),
+		))
+	}
+
+	ref, err := name.ParseReference("registry.example.com/ray:2.35.0")
+	test.Expect(err).NotTo(HaveOccurred())
+	test.Expect(keychain.Resolve(ref.Context())).To(Equal(authn.Anonymous))
+}
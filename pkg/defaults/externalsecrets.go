@@ -0,0 +1,51 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package defaults
+
+import (
+	"strings"
+
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// ExternalSecretsAnnotation is the comma-separated list of the Secrets, in the namespace of the RayCluster,
+	// or RayJob, it is set on, that are materialized by an external secrets manager, e.g., External Secrets Operator
+	// ExternalSecrets synced from Vault, and consumed by the Ray pods. The pods are not scheduled until they all exist.
+	ExternalSecretsAnnotation = "codeflare.dev/external-secrets"
+	// ExternalSecretsSchedulingGate is the scheduling gate of the Ray pods, that the operator removes
+	// once the Secrets of the ExternalSecretsAnnotation exist.
+	ExternalSecretsSchedulingGate = "codeflare.dev/external-secrets"
+)
+
+// ExternalSecretNames returns the names of the Secrets listed in the ExternalSecretsAnnotation of the RayCluster.
+func ExternalSecretNames(rayCluster *rayv1.RayCluster) []string {
+	var names []string
+	for _, name := range strings.Split(rayCluster.Annotations[ExternalSecretsAnnotation], ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// applyExternalSecretsSchedulingGate adds the ExternalSecretsSchedulingGate to the pod.
+func applyExternalSecretsSchedulingGate(podSpec *corev1.PodSpec) {
+	podSpec.SchedulingGates = upsert(podSpec.SchedulingGates, corev1.PodSchedulingGate{Name: ExternalSecretsSchedulingGate}, withSchedulingGateName(ExternalSecretsSchedulingGate))
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package defaults
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+// podArchitecture returns the CPU architecture the pods are constrained to run on, with the kubernetes.io/arch
+// node selector, or the required node affinity, or an empty string if they may run on nodes of any architecture.
+func podArchitecture(podSpec *corev1.PodSpec) string {
+	if arch, ok := podSpec.NodeSelector[corev1.LabelArchStable]; ok {
+		return arch
+	}
+	if podSpec.Affinity == nil || podSpec.Affinity.NodeAffinity == nil || podSpec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return ""
+	}
+	// The terms are ORed, so the pods are only constrained to an architecture when all the terms require the same one
+	var arch string
+	for _, term := range podSpec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+		termArch := ""
+		for _, expression := range term.MatchExpressions {
+			if expression.Key == corev1.LabelArchStable && expression.Operator == corev1.NodeSelectorOpIn && len(expression.Values) == 1 {
+				termArch = expression.Values[0]
+			}
+		}
+		if termArch == "" || (arch != "" && arch != termArch) {
+			return ""
+		}
+		arch = termArch
+	}
+	return arch
+}
+
+// applyRayImage sets the image of the Ray container to the image of the architecture the pods are constrained to,
+// when it has no image, or runs the image of another architecture.
+func applyRayImage(images map[string]string, podSpec *corev1.PodSpec) {
+	image, ok := images[podArchitecture(podSpec)]
+	if !ok || len(podSpec.Containers) == 0 {
+		return
+	}
+	container := &podSpec.Containers[0]
+	if container.Image == "" || isRayImage(images, container.Image) {
+		container.Image = image
+	}
+}
+
+func isRayImage(images map[string]string, image string) bool {
+	for _, i := range images {
+		if i == image {
+			return true
+		}
+	}
+	return false
+}
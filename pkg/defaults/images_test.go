@@ -0,0 +1,118 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package defaults
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/project-codeflare/codeflare-common/support"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+
+	"github.com/project-codeflare/codeflare-operator/pkg/config"
+)
+
+const (
+	amd64RayImage = "quay.io/project-codeflare/ray:2.20.0-py39-cu118"
+	arm64RayImage = "quay.io/project-codeflare/ray:2.20.0-py39-aarch64"
+)
+
+func archNodeAffinity(archs ...string) *corev1.Affinity {
+	affinity := &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{},
+	}}
+	for _, arch := range archs {
+		affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms = append(
+			affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms,
+			corev1.NodeSelectorTerm{MatchExpressions: []corev1.NodeSelectorRequirement{
+				{Key: corev1.LabelArchStable, Operator: corev1.NodeSelectorOpIn, Values: []string{arch}},
+			}},
+		)
+	}
+	return affinity
+}
+
+func TestPodArchitecture(t *testing.T) {
+	test := support.NewTest(t)
+
+	test.Expect(podArchitecture(&corev1.PodSpec{})).To(BeEmpty())
+	test.Expect(podArchitecture(&corev1.PodSpec{NodeSelector: map[string]string{corev1.LabelArchStable: "arm64"}})).To(Equal("arm64"))
+	test.Expect(podArchitecture(&corev1.PodSpec{Affinity: archNodeAffinity("arm64")})).To(Equal("arm64"))
+	test.Expect(podArchitecture(&corev1.PodSpec{Affinity: archNodeAffinity("arm64", "arm64")})).To(Equal("arm64"))
+	// The pods may run on either architecture
+	test.Expect(podArchitecture(&corev1.PodSpec{Affinity: archNodeAffinity("amd64", "arm64")})).To(BeEmpty())
+	test.Expect(podArchitecture(&corev1.PodSpec{Affinity: &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{
+			{MatchExpressions: []corev1.NodeSelectorRequirement{
+				{Key: corev1.LabelArchStable, Operator: corev1.NodeSelectorOpIn, Values: []string{"amd64", "arm64"}},
+			}},
+		}},
+	}}})).To(BeEmpty())
+}
+
+func TestApplyRayClusterDefaultsRayImages(t *testing.T) {
+	test := support.NewTest(t)
+
+	cfg := &config.KubeRayConfiguration{
+		RayDashboardOAuthEnabled: ptr.To(false),
+		MTLSEnabled:              ptr.To(false),
+		RayImages: map[string]string{
+			"amd64": amd64RayImage,
+			"arm64": arm64RayImage,
+		},
+	}
+
+	test.T().Run("Expected the Ray image of the architecture the pods are constrained to", func(t *testing.T) {
+		rayCluster := testRayCluster()
+		rayCluster.Spec.HeadGroupSpec.Template.Spec.Containers[0].Image = amd64RayImage
+		rayCluster.Spec.WorkerGroupSpecs[0].Template.Spec.Containers[0].Image = amd64RayImage
+		rayCluster.Spec.WorkerGroupSpecs[0].Template.Spec.NodeSelector = map[string]string{corev1.LabelArchStable: "arm64"}
+		ApplyRayClusterDefaults(cfg, rayCluster)
+
+		test.Expect(rayCluster.Spec.HeadGroupSpec.Template.Spec.Containers[0].Image).To(Equal(amd64RayImage))
+		test.Expect(rayCluster.Spec.WorkerGroupSpecs[0].Template.Spec.Containers[0].Image).To(Equal(arm64RayImage))
+	})
+
+	test.T().Run("Expected the Ray image to be set when the Ray container has no image", func(t *testing.T) {
+		rayCluster := testRayCluster()
+		rayCluster.Spec.WorkerGroupSpecs[0].Template.Spec.Affinity = archNodeAffinity("arm64")
+		ApplyRayClusterDefaults(cfg, rayCluster)
+
+		test.Expect(rayCluster.Spec.HeadGroupSpec.Template.Spec.Containers[0].Image).To(BeEmpty())
+		test.Expect(rayCluster.Spec.WorkerGroupSpecs[0].Template.Spec.Containers[0].Image).To(Equal(arm64RayImage))
+	})
+
+	test.T().Run("Expected user provided images to be honored", func(t *testing.T) {
+		rayCluster := testRayCluster()
+		rayCluster.Spec.WorkerGroupSpecs[0].Template.Spec.Containers[0].Image = "quay.io/user/ray:custom"
+		rayCluster.Spec.WorkerGroupSpecs[0].Template.Spec.NodeSelector = map[string]string{corev1.LabelArchStable: "arm64"}
+		ApplyRayClusterDefaults(cfg, rayCluster)
+
+		test.Expect(rayCluster.Spec.WorkerGroupSpecs[0].Template.Spec.Containers[0].Image).To(Equal("quay.io/user/ray:custom"))
+	})
+
+	test.T().Run("Expected no changes for architectures without Ray image", func(t *testing.T) {
+		rayCluster := testRayCluster()
+		rayCluster.Spec.WorkerGroupSpecs[0].Template.Spec.Containers[0].Image = amd64RayImage
+		rayCluster.Spec.WorkerGroupSpecs[0].Template.Spec.NodeSelector = map[string]string{corev1.LabelArchStable: "s390x"}
+		ApplyRayClusterDefaults(cfg, rayCluster)
+
+		test.Expect(rayCluster.Spec.WorkerGroupSpecs[0].Template.Spec.Containers[0].Image).To(Equal(amd64RayImage))
+	})
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package defaults
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/utils/ptr"
+
+	"github.com/project-codeflare/codeflare-operator/pkg/config"
+)
+
+const (
+	// KarpenterCapacityTypeAnnotation is the capacity type, i.e., on-demand, spot or reserved, of the nodes
+	// Karpenter provisions for the workers of the RayClusters created in the namespace it is set on.
+	KarpenterCapacityTypeAnnotation = "codeflare.dev/karpenter-capacity-type"
+
+	// KarpenterInstanceFamilyAnnotation is the instance family, e.g., m5, of the nodes Karpenter provisions
+	// for the workers of the RayClusters created in the namespace it is set on.
+	KarpenterInstanceFamilyAnnotation = "codeflare.dev/karpenter-instance-family"
+
+	// KarpenterGPUTypeAnnotation is the GPU name, e.g., a100, of the nodes Karpenter provisions for the workers
+	// requesting GPUs of the RayClusters created in the namespace it is set on.
+	KarpenterGPUTypeAnnotation = "codeflare.dev/karpenter-gpu-type"
+
+	// KarpenterCapacityTypeLabel, KarpenterInstanceFamilyLabel and KarpenterInstanceGPUNameLabel are the well-known
+	// labels of the nodes provisioned by Karpenter, that the node selectors of the pods are matched against.
+	KarpenterCapacityTypeLabel    = "karpenter.sh/capacity-type"
+	KarpenterInstanceFamilyLabel  = "karpenter.k8s.aws/instance-family"
+	KarpenterInstanceGPUNameLabel = "karpenter.k8s.aws/instance-gpu-name"
+)
+
+var karpenterCapacityTypes = []string{"on-demand", "spot", "reserved"}
+
+// ApplyKarpenterNamespaceDefaults adds the Karpenter node selectors from the annotations of the namespace to the pod
+// templates of the worker groups of the RayCluster, when the Karpenter hints are enabled, so that Karpenter provisions
+// nodes of the capacity type, instance family and, for the worker groups requesting GPUs, GPU type of the namespace policy.
+// The node labels the worker groups already select, or require with their node affinity, are left unchanged.
+// The invalid annotations of the namespace are ignored, and returned as an error, so that the valid ones still apply.
+func ApplyKarpenterNamespaceDefaults(cfg *config.KubeRayConfiguration, namespaceAnnotations map[string]string, spec *rayv1.RayClusterSpec) error {
+	if cfg == nil || !ptr.Deref(cfg.KarpenterHintsEnabled, false) {
+		return nil
+	}
+
+	var errs []error
+	selectors := map[string]string{}
+	gpuSelectors := map[string]string{}
+	if capacityType, ok := namespaceAnnotations[KarpenterCapacityTypeAnnotation]; ok {
+		if !slices.Contains(karpenterCapacityTypes, capacityType) {
+			errs = append(errs, fmt.Errorf("invalid namespace annotation %s=%q, must be one of %s", KarpenterCapacityTypeAnnotation, capacityType, strings.Join(karpenterCapacityTypes, ", ")))
+		} else {
+			selectors[KarpenterCapacityTypeLabel] = capacityType
+		}
+	}
+	if family, ok := namespaceAnnotations[KarpenterInstanceFamilyAnnotation]; ok {
+		if err := validateKarpenterLabelValue(KarpenterInstanceFamilyAnnotation, family); err != nil {
+			errs = append(errs, err)
+		} else {
+			selectors[KarpenterInstanceFamilyLabel] = family
+		}
+	}
+	if gpuType, ok := namespaceAnnotations[KarpenterGPUTypeAnnotation]; ok {
+		if err := validateKarpenterLabelValue(KarpenterGPUTypeAnnotation, gpuType); err != nil {
+			errs = append(errs, err)
+		} else {
+			gpuSelectors[KarpenterInstanceGPUNameLabel] = gpuType
+		}
+	}
+
+	for i := range spec.WorkerGroupSpecs {
+		podSpec := &spec.WorkerGroupSpecs[i].Template.Spec
+		applyNodeSelectors(podSpec, selectors)
+		if requestsGPUs(podSpec) {
+			applyNodeSelectors(podSpec, gpuSelectors)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func validateKarpenterLabelValue(key, value string) error {
+	if errs := validation.IsValidLabelValue(value); len(errs) > 0 || value == "" {
+		return fmt.Errorf("invalid namespace annotation %s=%q, must be a non-empty node label value: %s", key, value, strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// applyNodeSelectors adds the node selectors to the pods, except for the node labels they already select,
+// or require with their node affinity.
+func applyNodeSelectors(podSpec *corev1.PodSpec, selectors map[string]string) {
+	for label, value := range selectors {
+		if _, ok := podSpec.NodeSelector[label]; ok || requiresNodeLabel(podSpec, label) {
+			continue
+		}
+		if podSpec.NodeSelector == nil {
+			podSpec.NodeSelector = map[string]string{}
+		}
+		podSpec.NodeSelector[label] = value
+	}
+}
+
+// requiresNodeLabel returns whether the required node affinity of the pods has an expression on the node label.
+func requiresNodeLabel(podSpec *corev1.PodSpec, label string) bool {
+	if podSpec.Affinity == nil || podSpec.Affinity.NodeAffinity == nil || podSpec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return false
+	}
+	for _, term := range podSpec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+		for _, expression := range term.MatchExpressions {
+			if expression.Key == label {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// requestsGPUs returns whether the containers of the pods request, or limit, GPUs, e.g., nvidia.com/gpu or amd.com/gpu.
+func requestsGPUs(podSpec *corev1.PodSpec) bool {
+	for _, container := range podSpec.Containers {
+		for _, resources := range []corev1.ResourceList{container.Resources.Requests, container.Resources.Limits} {
+			for name, quantity := range resources {
+				if strings.HasSuffix(string(name), "/gpu") && !quantity.IsZero() {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
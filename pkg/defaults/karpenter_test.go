@@ -0,0 +1,135 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package defaults
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/project-codeflare/codeflare-common/support"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/utils/ptr"
+
+	"github.com/project-codeflare/codeflare-operator/pkg/config"
+)
+
+func TestApplyKarpenterNamespaceDefaults(t *testing.T) {
+	test := support.NewTest(t)
+
+	enabled := &config.KubeRayConfiguration{KarpenterHintsEnabled: ptr.To(true)}
+
+	cpuWorkers := func() rayv1.WorkerGroupSpec {
+		return rayv1.WorkerGroupSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "ray-worker"}},
+		}}}
+	}
+	gpuWorkers := func() rayv1.WorkerGroupSpec {
+		workers := cpuWorkers()
+		workers.Template.Spec.Containers[0].Resources.Limits = corev1.ResourceList{"nvidia.com/gpu": resource.MustParse("1")}
+		return workers
+	}
+	spotAffinityWorkers := func() rayv1.WorkerGroupSpec {
+		workers := cpuWorkers()
+		workers.Template.Spec.Affinity = &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+				MatchExpressions: []corev1.NodeSelectorRequirement{{Key: KarpenterCapacityTypeLabel, Operator: corev1.NodeSelectorOpIn, Values: []string{"spot", "on-demand"}}},
+			}}},
+		}}
+		return workers
+	}
+
+	tests := []struct {
+		name                 string
+		config               *config.KubeRayConfiguration
+		namespaceAnnotations map[string]string
+		workerGroups         []rayv1.WorkerGroupSpec
+		expectedSelectors    []map[string]string
+		expectedError        bool
+	}{
+		{
+			name:   "Expected Karpenter node selectors of the namespace to be set on the worker groups",
+			config: enabled,
+			namespaceAnnotations: map[string]string{
+				KarpenterCapacityTypeAnnotation:   "spot",
+				KarpenterInstanceFamilyAnnotation: "p4d",
+				KarpenterGPUTypeAnnotation:        "a100",
+			},
+			workerGroups: []rayv1.WorkerGroupSpec{cpuWorkers(), gpuWorkers()},
+			expectedSelectors: []map[string]string{
+				{KarpenterCapacityTypeLabel: "spot", KarpenterInstanceFamilyLabel: "p4d"},
+				{KarpenterCapacityTypeLabel: "spot", KarpenterInstanceFamilyLabel: "p4d", KarpenterInstanceGPUNameLabel: "a100"},
+			},
+		},
+		{
+			name:                 "Expected node constraints of the worker groups to take precedence over namespace default",
+			config:               enabled,
+			namespaceAnnotations: map[string]string{KarpenterCapacityTypeAnnotation: "on-demand", KarpenterGPUTypeAnnotation: "a100"},
+			workerGroups: func() []rayv1.WorkerGroupSpec {
+				workers := gpuWorkers()
+				workers.Template.Spec.NodeSelector = map[string]string{KarpenterInstanceGPUNameLabel: "h100"}
+				return []rayv1.WorkerGroupSpec{workers, spotAffinityWorkers()}
+			}(),
+			expectedSelectors: []map[string]string{
+				{KarpenterCapacityTypeLabel: "on-demand", KarpenterInstanceGPUNameLabel: "h100"},
+				nil,
+			},
+		},
+		{
+			name:                 "Expected no Karpenter node selectors with the Karpenter hints disabled",
+			config:               &config.KubeRayConfiguration{},
+			namespaceAnnotations: map[string]string{KarpenterCapacityTypeAnnotation: "spot"},
+			workerGroups:         []rayv1.WorkerGroupSpec{cpuWorkers()},
+			expectedSelectors:    []map[string]string{nil},
+		},
+		{
+			name:                 "Expected error for invalid capacity type",
+			config:               enabled,
+			namespaceAnnotations: map[string]string{KarpenterCapacityTypeAnnotation: "preemptible"},
+			workerGroups:         []rayv1.WorkerGroupSpec{cpuWorkers()},
+			expectedError:        true,
+		},
+		{
+			name:   "Expected error for invalid instance family, and the valid annotations to still apply",
+			config: enabled,
+			namespaceAnnotations: map[string]string{
+				KarpenterCapacityTypeAnnotation:   "spot",
+				KarpenterInstanceFamilyAnnotation: "not a label value",
+			},
+			workerGroups:      []rayv1.WorkerGroupSpec{cpuWorkers()},
+			expectedSelectors: []map[string]string{{KarpenterCapacityTypeLabel: "spot"}},
+			expectedError:     true,
+		},
+	}
+
+	for _, tc := range tests {
+		test.T().Run(tc.name, func(t *testing.T) {
+			spec := &rayv1.RayClusterSpec{WorkerGroupSpecs: tc.workerGroups}
+			err := ApplyKarpenterNamespaceDefaults(tc.config, tc.namespaceAnnotations, spec)
+			if tc.expectedError {
+				test.Expect(err).To(HaveOccurred())
+			} else {
+				test.Expect(err).NotTo(HaveOccurred())
+			}
+			for i, expected := range tc.expectedSelectors {
+				test.Expect(spec.WorkerGroupSpecs[i].Template.Spec.NodeSelector).To(Equal(expected))
+			}
+		})
+	}
+}
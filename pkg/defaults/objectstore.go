@@ -0,0 +1,90 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package defaults
+
+import (
+	"slices"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/utils/ptr"
+
+	"github.com/project-codeflare/codeflare-operator/pkg/config"
+)
+
+const (
+	// RayStartParamObjectStoreMemory is the Ray start parameter of the size, in bytes, of the object store.
+	RayStartParamObjectStoreMemory = "object-store-memory"
+
+	// SharedMemoryVolumeName is the name of the memory-backed volume mounted at /dev/shm, that's also the name
+	// of the volume KubeRay adds, sized to the whole memory limit, when none is mounted at /dev/shm.
+	SharedMemoryVolumeName = "shared-mem"
+
+	sharedMemoryMountPath              = "/dev/shm"
+	defaultObjectStoreMemoryPercentage = 30
+	// sharedMemoryHeadroomPercentage is how much larger than the object store /dev/shm is, as Ray requires
+	// the object store to fit in 95% of the available shared memory.
+	sharedMemoryHeadroomPercentage = 10
+)
+
+// IsObjectStoreSizingEnabled returns whether the object store, and /dev/shm, are sized by the operator.
+func IsObjectStoreSizingEnabled(cfg *config.KubeRayConfiguration) bool {
+	return cfg != nil && cfg.ObjectStore != nil && ptr.Deref(cfg.ObjectStore.Enabled, false)
+}
+
+// applyObjectStoreSizing sets the object-store-memory Ray start parameter, if missing, to the configured percentage
+// of the memory limit of the Ray container, and mounts a memory-backed volume at /dev/shm, that's larger than the
+// object store, as Ray falls back to an object store in /tmp when /dev/shm can't hold it. The pods that already
+// mount a volume at /dev/shm, or whose Ray container has no memory limit, are left unchanged.
+func applyObjectStoreSizing(cfg *config.ObjectStoreConfiguration, rayStartParams *map[string]string, podSpec *corev1.PodSpec) {
+	container := &podSpec.Containers[0]
+	if slices.ContainsFunc(container.VolumeMounts, func(mount corev1.VolumeMount) bool {
+		return mount.MountPath == sharedMemoryMountPath && mount.Name != SharedMemoryVolumeName
+	}) {
+		return
+	}
+
+	objectStoreMemory, err := strconv.ParseInt((*rayStartParams)[RayStartParamObjectStoreMemory], 10, 64)
+	if err != nil || objectStoreMemory <= 0 {
+		memory, ok := container.Resources.Limits[corev1.ResourceMemory]
+		if !ok || memory.IsZero() {
+			return
+		}
+		percentage := int64(ptr.Deref(cfg.MemoryPercentage, defaultObjectStoreMemoryPercentage))
+		objectStoreMemory = memory.Value() * percentage / 100
+		if *rayStartParams == nil {
+			*rayStartParams = map[string]string{}
+		}
+		(*rayStartParams)[RayStartParamObjectStoreMemory] = strconv.FormatInt(objectStoreMemory, 10)
+	}
+
+	sizeLimit := resource.NewQuantity(objectStoreMemory*(100+sharedMemoryHeadroomPercentage)/100, resource.BinarySI)
+	podSpec.Volumes = upsert(podSpec.Volumes, corev1.Volume{
+		Name: SharedMemoryVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			EmptyDir: &corev1.EmptyDirVolumeSource{
+				Medium:    corev1.StorageMediumMemory,
+				SizeLimit: sizeLimit,
+			},
+		},
+	}, withVolumeName(SharedMemoryVolumeName))
+	container.VolumeMounts = upsert(container.VolumeMounts, corev1.VolumeMount{
+		Name:      SharedMemoryVolumeName,
+		MountPath: sharedMemoryMountPath,
+	}, byVolumeMountName)
+}
@@ -0,0 +1,554 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package defaults implements the defaults the CodeFlare operator applies to Ray resources at admission.
+// The functions have no side effects other than mutating their input, so that clients, like the CodeFlare SDK,
+// can preview or pre-apply the same defaults as the operator webhooks.
+package defaults
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+
+	"github.com/project-codeflare/codeflare-operator/pkg/config"
+)
+
+const (
+	OAuthProxyContainerName     = "oauth-proxy"
+	OAuthProxyVolumeName        = "proxy-tls-secret"
+	OAuthConfigVolumeName       = "oauth-config"
+	CreateCertInitContainerName = "create-cert"
+	TrustedCABundleVolumeName   = "trusted-ca-bundle"
+
+	trustedCABundleMountPath  = "/home/ray/workspace/trusted-ca"
+	trustedCABundleFileName   = "ca-bundle.crt"
+	defaultTrustedCABundleKey = "ca-bundle.crt"
+
+	oauthConfigMountPath = "/etc/oauth/config"
+)
+
+// ApplyRayClusterDefaults mutates the RayCluster with the defaults derived from the operator configuration.
+// It is idempotent, so it can safely be applied to a RayCluster that has already been defaulted.
+func ApplyRayClusterDefaults(cfg *config.KubeRayConfiguration, rayCluster *rayv1.RayCluster) {
+	if cfg == nil {
+		cfg = &config.KubeRayConfiguration{}
+	}
+
+	if len(cfg.RayImages) > 0 {
+		applyRayImage(cfg.RayImages, &rayCluster.Spec.HeadGroupSpec.Template.Spec)
+		for i := range rayCluster.Spec.WorkerGroupSpecs {
+			applyRayImage(cfg.RayImages, &rayCluster.Spec.WorkerGroupSpecs[i].Template.Spec)
+		}
+	}
+
+	if ptr.Deref(cfg.RayDashboardOAuthEnabled, true) {
+		// Add the OAuth sidecar container
+		rayCluster.Spec.HeadGroupSpec.Template.Spec.Containers = upsert(rayCluster.Spec.HeadGroupSpec.Template.Spec.Containers, OAuthProxyContainer(cfg, rayCluster), withContainerName(OAuthProxyContainerName))
+
+		rayCluster.Spec.HeadGroupSpec.Template.Spec.Volumes = upsert(rayCluster.Spec.HeadGroupSpec.Template.Spec.Volumes, OAuthProxyTLSSecretVolume(rayCluster), withVolumeName(OAuthProxyVolumeName))
+
+		if IsCookieSecretRotationEnabled(cfg) {
+			rayCluster.Spec.HeadGroupSpec.Template.Spec.Volumes = upsert(rayCluster.Spec.HeadGroupSpec.Template.Spec.Volumes, OAuthConfigVolume(rayCluster), withVolumeName(OAuthConfigVolumeName))
+		}
+
+		rayCluster.Spec.HeadGroupSpec.Template.Spec.ServiceAccountName = rayCluster.Name + "-oauth-proxy"
+	}
+
+	if ptr.Deref(cfg.MTLSEnabled, true) {
+		// HeadGroupSpec
+
+		// Append the list of environment variables for the ray-head container
+		for _, envVar := range TLSEnvVars() {
+			rayCluster.Spec.HeadGroupSpec.Template.Spec.Containers[0].Env = upsert(rayCluster.Spec.HeadGroupSpec.Template.Spec.Containers[0].Env, envVar, withEnvVarName(envVar.Name))
+		}
+
+		// Append the create-cert Init Container
+		rayCluster.Spec.HeadGroupSpec.Template.Spec.InitContainers = upsert(rayCluster.Spec.HeadGroupSpec.Template.Spec.InitContainers, RayHeadInitContainer(rayCluster, cfg), withContainerName(CreateCertInitContainerName))
+
+		// Append the CA volumes
+		for _, caVol := range CAVolumes(rayCluster) {
+			rayCluster.Spec.HeadGroupSpec.Template.Spec.Volumes = upsert(rayCluster.Spec.HeadGroupSpec.Template.Spec.Volumes, caVol, withVolumeName(caVol.Name))
+		}
+
+		// Append the certificate volume mounts
+		for _, mount := range CertVolumeMounts() {
+			rayCluster.Spec.HeadGroupSpec.Template.Spec.Containers[0].VolumeMounts = upsert(rayCluster.Spec.HeadGroupSpec.Template.Spec.Containers[0].VolumeMounts, mount, byVolumeMountName)
+		}
+
+		// WorkerGroupSpec
+		for i := range rayCluster.Spec.WorkerGroupSpecs {
+			workerSpec := &rayCluster.Spec.WorkerGroupSpecs[i]
+
+			// Append the list of environment variables for the worker container
+			for _, envVar := range TLSEnvVars() {
+				workerSpec.Template.Spec.Containers[0].Env = upsert(workerSpec.Template.Spec.Containers[0].Env, envVar, withEnvVarName(envVar.Name))
+			}
+
+			// Append the CA volumes
+			for _, caVol := range CAVolumes(rayCluster) {
+				workerSpec.Template.Spec.Volumes = upsert(workerSpec.Template.Spec.Volumes, caVol, withVolumeName(caVol.Name))
+			}
+
+			// Append the certificate volume mounts
+			for _, mount := range CertVolumeMounts() {
+				workerSpec.Template.Spec.Containers[0].VolumeMounts = upsert(workerSpec.Template.Spec.Containers[0].VolumeMounts, mount, byVolumeMountName)
+			}
+
+			// Append the create-cert Init Container
+			workerSpec.Template.Spec.InitContainers = upsert(workerSpec.Template.Spec.InitContainers, RayWorkerInitContainer(cfg), withContainerName(CreateCertInitContainerName))
+		}
+	}
+
+	if proxyEnvVars := ProxyEnvVars(cfg.Proxy); len(proxyEnvVars) > 0 {
+		for _, envVar := range proxyEnvVars {
+			rayCluster.Spec.HeadGroupSpec.Template.Spec.Containers[0].Env = insertIfAbsent(rayCluster.Spec.HeadGroupSpec.Template.Spec.Containers[0].Env, envVar, byEnvVarName)
+		}
+		for i := range rayCluster.Spec.WorkerGroupSpecs {
+			workerSpec := &rayCluster.Spec.WorkerGroupSpecs[i]
+			for _, envVar := range proxyEnvVars {
+				workerSpec.Template.Spec.Containers[0].Env = insertIfAbsent(workerSpec.Template.Spec.Containers[0].Env, envVar, byEnvVarName)
+			}
+		}
+	}
+
+	if secretName := rayCluster.Annotations[S3SecretAnnotation]; secretName != "" {
+		applyS3Secret(secretName, &rayCluster.Spec.HeadGroupSpec.Template.Spec.Containers[0])
+		for i := range rayCluster.Spec.WorkerGroupSpecs {
+			applyS3Secret(secretName, &rayCluster.Spec.WorkerGroupSpecs[i].Template.Spec.Containers[0])
+		}
+	}
+
+	if len(ExternalSecretNames(rayCluster)) > 0 {
+		applyExternalSecretsSchedulingGate(&rayCluster.Spec.HeadGroupSpec.Template.Spec)
+		for i := range rayCluster.Spec.WorkerGroupSpecs {
+			applyExternalSecretsSchedulingGate(&rayCluster.Spec.WorkerGroupSpecs[i].Template.Spec)
+		}
+	}
+
+	if IsScratchVolumeRequested(cfg, rayCluster) {
+		applyScratchVolume(cfg.ScratchVolume, rayCluster, &rayCluster.Spec.HeadGroupSpec.Template.Spec)
+		for i := range rayCluster.Spec.WorkerGroupSpecs {
+			applyScratchVolume(cfg.ScratchVolume, rayCluster, &rayCluster.Spec.WorkerGroupSpecs[i].Template.Spec)
+		}
+	}
+
+	if cfg.TrustedCABundle != nil && ptr.Deref(cfg.TrustedCABundle.Enabled, false) {
+		rayCluster.Spec.HeadGroupSpec.Template.Spec.Volumes = upsert(rayCluster.Spec.HeadGroupSpec.Template.Spec.Volumes, TrustedCABundleVolume(cfg.TrustedCABundle, rayCluster), withVolumeName(TrustedCABundleVolumeName))
+		mountTrustedCABundle(&rayCluster.Spec.HeadGroupSpec.Template.Spec.Containers[0])
+		for i := range rayCluster.Spec.WorkerGroupSpecs {
+			workerSpec := &rayCluster.Spec.WorkerGroupSpecs[i]
+			workerSpec.Template.Spec.Volumes = upsert(workerSpec.Template.Spec.Volumes, TrustedCABundleVolume(cfg.TrustedCABundle, rayCluster), withVolumeName(TrustedCABundleVolumeName))
+			mountTrustedCABundle(&workerSpec.Template.Spec.Containers[0])
+		}
+	}
+
+	// The object store is sized before the memory is defaulted, as the memory excludes the object store
+	if IsObjectStoreSizingEnabled(cfg) {
+		applyObjectStoreSizing(cfg.ObjectStore, &rayCluster.Spec.HeadGroupSpec.RayStartParams, &rayCluster.Spec.HeadGroupSpec.Template.Spec)
+		for i := range rayCluster.Spec.WorkerGroupSpecs {
+			workerSpec := &rayCluster.Spec.WorkerGroupSpecs[i]
+			applyObjectStoreSizing(cfg.ObjectStore, &workerSpec.RayStartParams, &workerSpec.Template.Spec)
+		}
+	}
+
+	if ptr.Deref(cfg.RayStartParamsFromResourcesEnabled, false) {
+		applyRayStartParamsFromResources(&rayCluster.Spec.HeadGroupSpec.RayStartParams, &rayCluster.Spec.HeadGroupSpec.Template.Spec.Containers[0])
+		for i := range rayCluster.Spec.WorkerGroupSpecs {
+			workerSpec := &rayCluster.Spec.WorkerGroupSpecs[i]
+			applyRayStartParamsFromResources(&workerSpec.RayStartParams, &workerSpec.Template.Spec.Containers[0])
+		}
+	}
+
+	if ptr.Deref(cfg.RestrictedPodSecurityEnabled, false) {
+		defaultRestrictedSecurityContext(&rayCluster.Spec.HeadGroupSpec.Template.Spec)
+		for i := range rayCluster.Spec.WorkerGroupSpecs {
+			defaultRestrictedSecurityContext(&rayCluster.Spec.WorkerGroupSpecs[i].Template.Spec)
+		}
+	}
+}
+
+// OAuthProxyContainer returns the OAuth proxy sidecar container that secures the Ray dashboard.
+func OAuthProxyContainer(cfg *config.KubeRayConfiguration, rayCluster *rayv1.RayCluster) corev1.Container {
+	container := corev1.Container{
+		Name:  OAuthProxyContainerName,
+		Image: "registry.redhat.io/openshift4/ose-oauth-proxy@sha256:1ea6a01bf3e63cdcf125c6064cbd4a4a270deaf0f157b3eabb78f60556840366",
+		Ports: []corev1.ContainerPort{
+			{ContainerPort: 8443, Name: "oauth-proxy"},
+		},
+		Env: []corev1.EnvVar{
+			{
+				Name: "COOKIE_SECRET",
+				ValueFrom: &corev1.EnvVarSource{
+					SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{
+							Name: rayCluster.Name + "-oauth-config",
+						},
+						Key: "cookie_secret",
+					},
+				},
+			},
+		},
+		Args: []string{
+			"--https-address=:8443",
+			"--provider=openshift",
+			"--openshift-service-account=" + rayCluster.Name + "-oauth-proxy",
+			"--upstream=http://localhost:8265",
+			"--tls-cert=/etc/tls/private/tls.crt",
+			"--tls-key=/etc/tls/private/tls.key",
+			"--cookie-secret=$(COOKIE_SECRET)",
+		},
+		VolumeMounts: []corev1.VolumeMount{
+			{
+				Name:      OAuthProxyVolumeName,
+				MountPath: "/etc/tls/private",
+				ReadOnly:  true,
+			},
+		},
+	}
+
+	if cfg != nil && ptr.Deref(cfg.DashboardRBACEnabled, false) {
+		// Authorize both bearer token and browser sessions against the rayclusters/dashboard virtual subresource
+		container.Args = append(container.Args,
+			"--openshift-delegate-urls={\"/\":"+dashboardResourceAttributes(rayCluster)+"}",
+			"--openshift-sar="+dashboardResourceAttributes(rayCluster),
+		)
+	} else {
+		container.Args = append(container.Args,
+			"--openshift-delegate-urls={\"/\":{\"resource\":\"pods\",\"namespace\":\""+rayCluster.Namespace+"\",\"verb\":\"get\"}}",
+		)
+	}
+
+	if cfg != nil && cfg.DashboardAuditLog != nil && ptr.Deref(cfg.DashboardAuditLog.Enabled, false) {
+		// Log the requests to stdout, for the operator to collect them as audit entries
+		container.Args = append(container.Args, "--request-logging=true")
+	}
+
+	if cfg != nil && cfg.DashboardHardening != nil {
+		hardening := cfg.DashboardHardening
+		// Enforce the cookie security settings, rather than relying on the defaults of the OAuth proxy image
+		container.Args = append(container.Args, "--cookie-secure=true", "--cookie-httponly=true")
+		if hardening.CookieSameSite != "" {
+			container.Args = append(container.Args, "--cookie-samesite="+strings.ToLower(hardening.CookieSameSite))
+		}
+		if hardening.CookieExpire != nil {
+			container.Args = append(container.Args, "--cookie-expire="+hardening.CookieExpire.Duration.String())
+		}
+		if hardening.CookieRefresh != nil {
+			container.Args = append(container.Args, "--cookie-refresh="+hardening.CookieRefresh.Duration.String())
+		}
+	}
+
+	if IsCookieSecretRotationEnabled(cfg) {
+		// The mounted Secret is updated once the cookie secret is rotated, while the environment variable keeps
+		// the value the proxy was started with, so that the kubelet restarts the proxy with the rotated secret,
+		// without restarting the Ray head.
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+			Name:      OAuthConfigVolumeName,
+			MountPath: oauthConfigMountPath,
+			ReadOnly:  true,
+		})
+		container.LivenessProbe = &corev1.Probe{
+			ProbeHandler: corev1.ProbeHandler{
+				Exec: &corev1.ExecAction{
+					Command: []string{"/bin/sh", "-c", `[ "${COOKIE_SECRET}" = "$(cat ` + oauthConfigMountPath + `/cookie_secret)" ]`},
+				},
+			},
+			PeriodSeconds:    30,
+			FailureThreshold: 1,
+		}
+	}
+
+	return container
+}
+
+// IsCookieSecretRotationEnabled returns whether the cookie secrets of the OAuth proxies are rotated by the operator.
+func IsCookieSecretRotationEnabled(cfg *config.KubeRayConfiguration) bool {
+	return cfg != nil && cfg.CookieSecretRotation != nil && ptr.Deref(cfg.CookieSecretRotation.Enabled, false)
+}
+
+// OAuthConfigVolume returns the volume of the Secret holding the cookie secret of the OAuth proxy.
+func OAuthConfigVolume(rayCluster *rayv1.RayCluster) corev1.Volume {
+	return corev1.Volume{
+		Name: OAuthConfigVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName: rayCluster.Name + "-oauth-config",
+			},
+		},
+	}
+}
+
+// dashboardResourceAttributes returns the SubjectAccessReview resource attributes, that authorize
+// access to the dashboard of the RayCluster, in the JSON format expected by the OAuth proxy.
+func dashboardResourceAttributes(rayCluster *rayv1.RayCluster) string {
+	return `{"group":"ray.io","resource":"rayclusters","subresource":"dashboard","namespace":"` + rayCluster.Namespace +
+		`","name":"` + rayCluster.Name + `","verb":"get"}`
+}
+
+// OAuthProxyTLSSecretVolume returns the volume holding the OAuth proxy serving certificate.
+func OAuthProxyTLSSecretVolume(rayCluster *rayv1.RayCluster) corev1.Volume {
+	return corev1.Volume{
+		Name: OAuthProxyVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName: rayCluster.Name + "-proxy-tls-secret",
+			},
+		},
+	}
+}
+
+// CertVolumeMounts returns the mounts of the CA and generated certificate volumes.
+func CertVolumeMounts() []corev1.VolumeMount {
+	return []corev1.VolumeMount{
+		{
+			Name:      "ca-vol",
+			MountPath: "/home/ray/workspace/ca",
+			ReadOnly:  true,
+		},
+		{
+			Name:      "server-cert",
+			MountPath: "/home/ray/workspace/tls",
+			ReadOnly:  false,
+		},
+	}
+}
+
+// TLSEnvVars returns the environment variables enabling mTLS between Ray nodes.
+func TLSEnvVars() []corev1.EnvVar {
+	return []corev1.EnvVar{
+		{
+			Name: "MY_POD_IP",
+			ValueFrom: &corev1.EnvVarSource{
+				FieldRef: &corev1.ObjectFieldSelector{
+					FieldPath: "status.podIP",
+				},
+			},
+		},
+		{
+			Name:  "RAY_USE_TLS",
+			Value: "1",
+		},
+		{
+			Name:  "RAY_TLS_SERVER_CERT",
+			Value: "/home/ray/workspace/tls/server.crt",
+		},
+		{
+			Name:  "RAY_TLS_SERVER_KEY",
+			Value: "/home/ray/workspace/tls/server.key",
+		},
+		{
+			Name:  "RAY_TLS_CA_CERT",
+			Value: "/home/ray/workspace/tls/ca.crt",
+		},
+	}
+}
+
+// ProxyEnvVars returns the proxy environment variables, in both upper and lower case
+// as not all tools, e.g. pip or curl, honor the same variants.
+func ProxyEnvVars(proxy *config.ProxyConfiguration) []corev1.EnvVar {
+	var envVars []corev1.EnvVar
+	if proxy == nil {
+		return envVars
+	}
+	for _, envVar := range []corev1.EnvVar{
+		{Name: "HTTP_PROXY", Value: proxy.HTTPProxy},
+		{Name: "HTTPS_PROXY", Value: proxy.HTTPSProxy},
+		{Name: "NO_PROXY", Value: proxy.NoProxy},
+	} {
+		if envVar.Value != "" {
+			envVars = append(envVars, envVar, corev1.EnvVar{Name: strings.ToLower(envVar.Name), Value: envVar.Value})
+		}
+	}
+	return envVars
+}
+
+// TrustedCABundleConfigMapName returns the name of the ConfigMap holding the trusted CA bundle
+// mounted into the containers of the RayCluster.
+func TrustedCABundleConfigMapName(cfg *config.TrustedCABundleConfiguration, rayCluster *rayv1.RayCluster) string {
+	if cfg != nil && cfg.ConfigMapName != "" {
+		return cfg.ConfigMapName
+	}
+	return rayCluster.Name + "-trusted-ca-bundle"
+}
+
+// TrustedCABundleVolume returns the volume projecting the trusted CA bundle from its ConfigMap.
+// It is required, so that the pods don't start, with the default trust store, until the bundle is injected.
+func TrustedCABundleVolume(cfg *config.TrustedCABundleConfiguration, rayCluster *rayv1.RayCluster) corev1.Volume {
+	key := defaultTrustedCABundleKey
+	if cfg != nil && cfg.Key != "" {
+		key = cfg.Key
+	}
+	return corev1.Volume{
+		Name: TrustedCABundleVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{
+					Name: TrustedCABundleConfigMapName(cfg, rayCluster),
+				},
+				Items: []corev1.KeyToPath{
+					{Key: key, Path: trustedCABundleFileName},
+				},
+			},
+		},
+	}
+}
+
+// mountTrustedCABundle mounts the trusted CA bundle into the container, and points the OpenSSL
+// and Python requests trust stores to it, unless already set by the user.
+func mountTrustedCABundle(container *corev1.Container) {
+	container.VolumeMounts = upsert(container.VolumeMounts, corev1.VolumeMount{
+		Name:      TrustedCABundleVolumeName,
+		MountPath: trustedCABundleMountPath,
+		ReadOnly:  true,
+	}, byVolumeMountName)
+
+	caBundleFile := trustedCABundleMountPath + "/" + trustedCABundleFileName
+	for _, name := range []string{"SSL_CERT_FILE", "REQUESTS_CA_BUNDLE"} {
+		container.Env = insertIfAbsent(container.Env, corev1.EnvVar{Name: name, Value: caBundleFile}, byEnvVarName)
+	}
+}
+
+// CAVolumes returns the volumes holding the RayCluster CA and generated certificate.
+func CAVolumes(rayCluster *rayv1.RayCluster) []corev1.Volume {
+	return []corev1.Volume{
+		{
+			Name: "ca-vol",
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: `ca-secret-` + rayCluster.Name,
+				},
+			},
+		},
+		{
+			Name: "server-cert",
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{},
+			},
+		},
+	}
+}
+
+// RayHeadInitContainer returns the init container generating the certificate of the head node.
+func RayHeadInitContainer(rayCluster *rayv1.RayCluster, cfg *config.KubeRayConfiguration) corev1.Container {
+	rayClientRoute := "rayclient-" + rayCluster.Name + "-" + rayCluster.Namespace + "." + cfg.IngressDomain
+	// Service name for basic interactive
+	svcDomain := rayCluster.Name + "-head-svc." + rayCluster.Namespace + ".svc"
+
+	initContainerHead := corev1.Container{
+		Name:  CreateCertInitContainerName,
+		Image: cfg.CertGeneratorImage,
+		Command: []string{
+			"sh",
+			"-c",
+			certGenerationCommand("localhost", "${FQ_RAY_IP}", rayClientRoute, svcDomain),
+		},
+		Env:          []corev1.EnvVar{podIPsEnvVar()},
+		VolumeMounts: CertVolumeMounts(),
+	}
+	return initContainerHead
+}
+
+// RayWorkerInitContainer returns the init container generating the certificate of worker nodes.
+func RayWorkerInitContainer(cfg *config.KubeRayConfiguration) corev1.Container {
+	initContainerWorker := corev1.Container{
+		Name:  CreateCertInitContainerName,
+		Image: cfg.CertGeneratorImage,
+		Command: []string{
+			"sh",
+			"-c",
+			certGenerationCommand("localhost", "${FQ_RAY_IP}"),
+		},
+		Env:          []corev1.EnvVar{podIPsEnvVar()},
+		VolumeMounts: CertVolumeMounts(),
+	}
+	return initContainerWorker
+}
+
+// certGenerationCommand returns the shell command generating the server certificate, signed by the CA of the
+// RayCluster, for the DNS names. The loopback addresses, and the pod IPs, are added as IP SANs, rather than DNS SANs,
+// so that the certificate is valid for both the IPv4 and IPv6 addresses of the pod, on single-stack, and dual-stack,
+// clusters.
+func certGenerationCommand(dnsNames ...string) string {
+	var altNames strings.Builder
+	for i, name := range dnsNames {
+		fmt.Fprintf(&altNames, `\nDNS.%d = %s`, i+1, name)
+	}
+	for i, ip := range loopbackIPs {
+		fmt.Fprintf(&altNames, `\nIP.%d = %s`, i+1, ip)
+	}
+	return `cd /home/ray/workspace/tls && openssl req -nodes -newkey rsa:2048 -keyout server.key -out server.csr -subj '/CN=ray-head' && printf "authorityKeyIdentifier=keyid,issuer\nbasicConstraints=CA:FALSE\nsubjectAltName = @alt_names\n[alt_names]` + altNames.String() + `\n">./domain.ext && ` +
+		fmt.Sprintf(`i=%d && for ip in $(echo "${MY_POD_IPS}" | tr ',' ' '); do echo "IP.${i} = ${ip}">>./domain.ext; i=$((i+1)); done && `, len(loopbackIPs)+1) +
+		`cp /home/ray/workspace/ca/* . && openssl x509 -req -CA ca.crt -CAkey ca.key -in server.csr -out server.crt -days 365 -CAcreateserial -extfile domain.ext`
+}
+
+// loopbackIPs are the IPv4 and IPv6 loopback addresses the Ray nodes may be reached at from the same pod.
+var loopbackIPs = []string{"127.0.0.1", "::1"}
+
+// podIPsEnvVar returns the environment variable of the comma-separated IPs of the pod, i.e., a single IPv4 or IPv6
+// address on single-stack clusters, and one address of each family on dual-stack clusters.
+func podIPsEnvVar() corev1.EnvVar {
+	return corev1.EnvVar{
+		Name: "MY_POD_IPS",
+		ValueFrom: &corev1.EnvVarSource{
+			FieldRef: &corev1.ObjectFieldSelector{
+				FieldPath: "status.podIPs",
+			},
+		},
+	}
+}
+
+// defaultRestrictedSecurityContext sets the security context fields required by the restricted
+// Pod Security Standard, and OpenShift restricted-v2 SCC, that are not already set in the pod spec.
+func defaultRestrictedSecurityContext(podSpec *corev1.PodSpec) {
+	if podSpec.SecurityContext == nil {
+		podSpec.SecurityContext = &corev1.PodSecurityContext{}
+	}
+	if podSpec.SecurityContext.RunAsNonRoot == nil {
+		podSpec.SecurityContext.RunAsNonRoot = ptr.To(true)
+	}
+	if podSpec.SecurityContext.SeccompProfile == nil {
+		podSpec.SecurityContext.SeccompProfile = &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault}
+	}
+
+	for i := range podSpec.InitContainers {
+		defaultRestrictedContainerSecurityContext(&podSpec.InitContainers[i])
+	}
+	for i := range podSpec.Containers {
+		defaultRestrictedContainerSecurityContext(&podSpec.Containers[i])
+	}
+}
+
+func defaultRestrictedContainerSecurityContext(container *corev1.Container) {
+	if container.SecurityContext == nil {
+		container.SecurityContext = &corev1.SecurityContext{}
+	}
+	if container.SecurityContext.AllowPrivilegeEscalation == nil {
+		container.SecurityContext.AllowPrivilegeEscalation = ptr.To(false)
+	}
+	if container.SecurityContext.Capabilities == nil {
+		container.SecurityContext.Capabilities = &corev1.Capabilities{}
+	}
+	// The restricted Pod Security Standard requires ALL to be dropped, whatever the other dropped capabilities
+	if !slices.Contains(container.SecurityContext.Capabilities.Drop, "ALL") {
+		container.SecurityContext.Capabilities.Drop = append(container.SecurityContext.Capabilities.Drop, "ALL")
+	}
+}
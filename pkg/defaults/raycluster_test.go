@@ -0,0 +1,604 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package defaults
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/project-codeflare/codeflare-common/support"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	"github.com/project-codeflare/codeflare-operator/pkg/config"
+)
+
+func testRayCluster() *rayv1.RayCluster {
+	return &rayv1.RayCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-raycluster",
+			Namespace: "test-namespace",
+		},
+		Spec: rayv1.RayClusterSpec{
+			HeadGroupSpec: rayv1.HeadGroupSpec{
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{Name: "ray-head"}},
+					},
+				},
+			},
+			WorkerGroupSpecs: []rayv1.WorkerGroupSpec{
+				{
+					GroupName: "worker-group-1",
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{{Name: "ray-worker"}},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestApplyRayClusterDefaults(t *testing.T) {
+	test := support.NewTest(t)
+
+	cfg := &config.KubeRayConfiguration{
+		RayDashboardOAuthEnabled: ptr.To(true),
+		MTLSEnabled:              ptr.To(true),
+		IngressDomain:            "apps.example.com",
+		CertGeneratorImage:       "quay.io/project-codeflare/ray:latest-py39-cu118",
+		Proxy: &config.ProxyConfiguration{
+			HTTPSProxy: "http://proxy.example.com:3128",
+		},
+	}
+
+	test.T().Run("Expected OAuth proxy, mTLS and proxy defaults to be applied", func(t *testing.T) {
+		rayCluster := testRayCluster()
+		ApplyRayClusterDefaults(cfg, rayCluster)
+
+		headSpec := rayCluster.Spec.HeadGroupSpec.Template.Spec
+		test.Expect(headSpec.Containers).To(ContainElement(WithTransform(support.ResourceName, Equal(OAuthProxyContainerName))))
+		test.Expect(headSpec.Volumes).To(ContainElement(WithTransform(support.ResourceName, Equal(OAuthProxyVolumeName))))
+		test.Expect(headSpec.ServiceAccountName).To(Equal(rayCluster.Name + "-oauth-proxy"))
+		test.Expect(headSpec.InitContainers).To(ContainElement(RayHeadInitContainer(rayCluster, cfg)))
+		test.Expect(headSpec.Containers[0].Env).To(ContainElements(TLSEnvVars()))
+		test.Expect(headSpec.Containers[0].Env).To(ContainElement(corev1.EnvVar{Name: "https_proxy", Value: "http://proxy.example.com:3128"}))
+
+		workerSpec := rayCluster.Spec.WorkerGroupSpecs[0].Template.Spec
+		test.Expect(workerSpec.InitContainers).To(ContainElement(RayWorkerInitContainer(cfg)))
+		test.Expect(workerSpec.Volumes).To(ContainElements(CAVolumes(rayCluster)))
+		test.Expect(workerSpec.Containers[0].VolumeMounts).To(ContainElements(CertVolumeMounts()))
+		test.Expect(workerSpec.Containers[0].Env).To(ContainElement(corev1.EnvVar{Name: "HTTPS_PROXY", Value: "http://proxy.example.com:3128"}))
+	})
+
+	test.T().Run("Expected defaults to be idempotent", func(t *testing.T) {
+		rayCluster := testRayCluster()
+		ApplyRayClusterDefaults(cfg, rayCluster)
+		defaulted := rayCluster.DeepCopy()
+
+		ApplyRayClusterDefaults(cfg, rayCluster)
+		test.Expect(rayCluster).To(Equal(defaulted))
+	})
+
+	test.T().Run("Expected the certificates to be valid for the IPv4 and IPv6 addresses of the pods", func(t *testing.T) {
+		rayCluster := testRayCluster()
+		ApplyRayClusterDefaults(cfg, rayCluster)
+
+		for _, podSpec := range []corev1.PodSpec{rayCluster.Spec.HeadGroupSpec.Template.Spec, rayCluster.Spec.WorkerGroupSpecs[0].Template.Spec} {
+			test.Expect(podSpec.InitContainers).To(HaveLen(1))
+			initContainer := podSpec.InitContainers[0]
+			// The IPs of the pod, one of each family on dual-stack clusters
+			test.Expect(initContainer.Env).To(ContainElement(corev1.EnvVar{
+				Name:      "MY_POD_IPS",
+				ValueFrom: &corev1.EnvVarSource{FieldRef: &corev1.ObjectFieldSelector{FieldPath: "status.podIPs"}},
+			}))
+			// The addresses are IP SANs, as IPv6 addresses aren't valid DNS names
+			test.Expect(initContainer.Command[2]).To(And(
+				ContainSubstring(`\nIP.1 = 127.0.0.1\nIP.2 = ::1\n`),
+				ContainSubstring(`for ip in $(echo "${MY_POD_IPS}" | tr ',' ' '); do echo "IP.${i} = ${ip}">>./domain.ext`),
+				Not(MatchRegexp(`DNS\.\d+ = (127\.0\.0\.1|\$\(awk)`)),
+			))
+		}
+		test.Expect(rayCluster.Spec.HeadGroupSpec.Template.Spec.InitContainers[0].Command[2]).To(
+			ContainSubstring(`\nDNS.3 = rayclient-test-raycluster-test-namespace.apps.example.com\nDNS.4 = test-raycluster-head-svc.test-namespace.svc\n`))
+	})
+
+	test.T().Run("Expected OAuth proxy request logging when dashboard audit log is enabled", func(t *testing.T) {
+		auditCfg := &config.KubeRayConfiguration{
+			RayDashboardOAuthEnabled: ptr.To(true),
+			MTLSEnabled:              ptr.To(false),
+			DashboardAuditLog:        &config.DashboardAuditLogConfiguration{Enabled: ptr.To(true)},
+		}
+
+		rayCluster := testRayCluster()
+		ApplyRayClusterDefaults(auditCfg, rayCluster)
+
+		test.Expect(rayCluster.Spec.HeadGroupSpec.Template.Spec.Containers).To(ContainElement(And(
+			WithTransform(support.ResourceName, Equal(OAuthProxyContainerName)),
+			WithTransform(func(c corev1.Container) []string { return c.Args }, ContainElement("--request-logging=true")),
+		)))
+	})
+
+	test.T().Run("Expected dashboard access to be authorized against the dashboard subresource", func(t *testing.T) {
+		rbacCfg := &config.KubeRayConfiguration{
+			RayDashboardOAuthEnabled: ptr.To(true),
+			MTLSEnabled:              ptr.To(false),
+			DashboardRBACEnabled:     ptr.To(true),
+		}
+
+		rayCluster := testRayCluster()
+		ApplyRayClusterDefaults(rbacCfg, rayCluster)
+
+		attributes := `{"group":"ray.io","resource":"rayclusters","subresource":"dashboard","namespace":"test-namespace","name":"test-raycluster","verb":"get"}`
+		test.Expect(OAuthProxyContainer(rbacCfg, rayCluster).Args).To(ContainElements(
+			`--openshift-delegate-urls={"/":`+attributes+`}`,
+			`--openshift-sar=`+attributes,
+		))
+		test.Expect(rayCluster.Spec.HeadGroupSpec.Template.Spec.Containers).To(ContainElement(OAuthProxyContainer(rbacCfg, rayCluster)))
+	})
+
+	test.T().Run("Expected OAuth proxy session cookie to be hardened", func(t *testing.T) {
+		hardeningCfg := &config.KubeRayConfiguration{
+			RayDashboardOAuthEnabled: ptr.To(true),
+			MTLSEnabled:              ptr.To(false),
+			DashboardHardening: &config.DashboardHardeningConfiguration{
+				CookieSameSite: "Strict",
+				CookieExpire:   &metav1.Duration{Duration: 8 * time.Hour},
+				CookieRefresh:  &metav1.Duration{Duration: time.Hour},
+			},
+		}
+
+		rayCluster := testRayCluster()
+		ApplyRayClusterDefaults(hardeningCfg, rayCluster)
+
+		test.Expect(OAuthProxyContainer(hardeningCfg, rayCluster).Args).To(ContainElements(
+			"--cookie-secure=true",
+			"--cookie-httponly=true",
+			"--cookie-samesite=strict",
+			"--cookie-expire=8h0m0s",
+			"--cookie-refresh=1h0m0s",
+		))
+		test.Expect(rayCluster.Spec.HeadGroupSpec.Template.Spec.Containers).To(ContainElement(OAuthProxyContainer(hardeningCfg, rayCluster)))
+	})
+
+	test.T().Run("Expected OAuth proxy to be restarted once its cookie secret is rotated", func(t *testing.T) {
+		rotationCfg := &config.KubeRayConfiguration{
+			RayDashboardOAuthEnabled: ptr.To(true),
+			MTLSEnabled:              ptr.To(false),
+			CookieSecretRotation:     &config.CookieSecretRotationConfiguration{Enabled: ptr.To(true)},
+		}
+
+		rayCluster := testRayCluster()
+		ApplyRayClusterDefaults(rotationCfg, rayCluster)
+
+		container := OAuthProxyContainer(rotationCfg, rayCluster)
+		test.Expect(container.VolumeMounts).To(ContainElement(corev1.VolumeMount{Name: OAuthConfigVolumeName, MountPath: "/etc/oauth/config", ReadOnly: true}))
+		test.Expect(container.LivenessProbe.Exec.Command).To(ContainElement(`[ "${COOKIE_SECRET}" = "$(cat /etc/oauth/config/cookie_secret)" ]`))
+		test.Expect(rayCluster.Spec.HeadGroupSpec.Template.Spec.Containers).To(ContainElement(container))
+		test.Expect(rayCluster.Spec.HeadGroupSpec.Template.Spec.Volumes).To(ContainElement(OAuthConfigVolume(rayCluster)))
+		test.Expect(OAuthConfigVolume(rayCluster).Secret.SecretName).To(Equal("test-raycluster-oauth-config"))
+	})
+
+	test.T().Run("Expected no changes when all defaults are disabled", func(t *testing.T) {
+		rayCluster := testRayCluster()
+		ApplyRayClusterDefaults(&config.KubeRayConfiguration{
+			RayDashboardOAuthEnabled: ptr.To(false),
+			MTLSEnabled:              ptr.To(false),
+		}, rayCluster)
+
+		test.Expect(rayCluster).To(Equal(testRayCluster()))
+	})
+}
+
+func TestApplyRayClusterDefaultsTrustedCABundle(t *testing.T) {
+	test := support.NewTest(t)
+
+	cfg := &config.KubeRayConfiguration{
+		RayDashboardOAuthEnabled: ptr.To(false),
+		MTLSEnabled:              ptr.To(false),
+		TrustedCABundle: &config.TrustedCABundleConfiguration{
+			Enabled: ptr.To(true),
+		},
+	}
+
+	test.T().Run("Expected trusted CA bundle to be mounted into the Ray containers", func(t *testing.T) {
+		rayCluster := testRayCluster()
+		ApplyRayClusterDefaults(cfg, rayCluster)
+
+		for _, podSpec := range []corev1.PodSpec{rayCluster.Spec.HeadGroupSpec.Template.Spec, rayCluster.Spec.WorkerGroupSpecs[0].Template.Spec} {
+			test.Expect(podSpec.Volumes).To(ContainElement(TrustedCABundleVolume(cfg.TrustedCABundle, rayCluster)))
+			test.Expect(podSpec.Volumes).To(ContainElement(WithTransform(func(v corev1.Volume) string {
+				return v.ConfigMap.Name
+			}, Equal("test-raycluster-trusted-ca-bundle"))))
+			test.Expect(podSpec.Containers[0].VolumeMounts).To(ContainElement(WithTransform(support.ResourceName, Equal(TrustedCABundleVolumeName))))
+			test.Expect(podSpec.Containers[0].Env).To(ContainElements(
+				corev1.EnvVar{Name: "SSL_CERT_FILE", Value: "/home/ray/workspace/trusted-ca/ca-bundle.crt"},
+				corev1.EnvVar{Name: "REQUESTS_CA_BUNDLE", Value: "/home/ray/workspace/trusted-ca/ca-bundle.crt"},
+			))
+		}
+	})
+
+	test.T().Run("Expected user provided ConfigMap and environment variables to be honored", func(t *testing.T) {
+		userCfg := &config.KubeRayConfiguration{
+			RayDashboardOAuthEnabled: ptr.To(false),
+			MTLSEnabled:              ptr.To(false),
+			TrustedCABundle: &config.TrustedCABundleConfiguration{
+				Enabled:       ptr.To(true),
+				ConfigMapName: "odh-trusted-ca-bundle",
+				Key:           "odh-ca-bundle.crt",
+			},
+		}
+		rayCluster := testRayCluster()
+		rayCluster.Spec.HeadGroupSpec.Template.Spec.Containers[0].Env = []corev1.EnvVar{
+			{Name: "SSL_CERT_FILE", Value: "/etc/ssl/certs/custom.crt"},
+		}
+		ApplyRayClusterDefaults(userCfg, rayCluster)
+
+		volume := TrustedCABundleVolume(userCfg.TrustedCABundle, rayCluster)
+		test.Expect(volume.ConfigMap.Name).To(Equal("odh-trusted-ca-bundle"))
+		test.Expect(volume.ConfigMap.Items).To(ConsistOf(corev1.KeyToPath{Key: "odh-ca-bundle.crt", Path: "ca-bundle.crt"}))
+		test.Expect(ptr.Deref(volume.ConfigMap.Optional, false)).To(BeFalse())
+		test.Expect(rayCluster.Spec.HeadGroupSpec.Template.Spec.Volumes).To(ContainElement(volume))
+		test.Expect(rayCluster.Spec.HeadGroupSpec.Template.Spec.Containers[0].Env).To(ContainElement(
+			corev1.EnvVar{Name: "SSL_CERT_FILE", Value: "/etc/ssl/certs/custom.crt"},
+		))
+		test.Expect(rayCluster.Spec.HeadGroupSpec.Template.Spec.Containers[0].Env).NotTo(ContainElement(
+			corev1.EnvVar{Name: "SSL_CERT_FILE", Value: "/home/ray/workspace/trusted-ca/ca-bundle.crt"},
+		))
+	})
+}
+
+func TestApplyRayClusterDefaultsS3Secret(t *testing.T) {
+	test := support.NewTest(t)
+
+	cfg := &config.KubeRayConfiguration{
+		RayDashboardOAuthEnabled: ptr.To(false),
+		MTLSEnabled:              ptr.To(false),
+	}
+	secretKeyRef := func(key string) *corev1.EnvVarSource {
+		return &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "data-connection"},
+			Key:                  key,
+			Optional:             ptr.To(true),
+		}}
+	}
+
+	test.T().Run("Expected S3 credentials of the Secret to be set in the Ray containers", func(t *testing.T) {
+		rayCluster := testRayCluster()
+		rayCluster.Annotations = map[string]string{S3SecretAnnotation: "data-connection"}
+		rayCluster.Spec.WorkerGroupSpecs[0].Template.Spec.Containers[0].Env = []corev1.EnvVar{
+			{Name: "AWS_DEFAULT_REGION", Value: "eu-west-1"},
+		}
+		ApplyRayClusterDefaults(cfg, rayCluster)
+
+		test.Expect(rayCluster.Spec.HeadGroupSpec.Template.Spec.Containers[0].Env).To(ConsistOf(S3SecretEnvVars("data-connection")))
+		test.Expect(rayCluster.Spec.HeadGroupSpec.Template.Spec.Containers[0].Env).To(ContainElements(
+			corev1.EnvVar{Name: "AWS_ACCESS_KEY_ID", ValueFrom: secretKeyRef("AWS_ACCESS_KEY_ID")},
+			corev1.EnvVar{Name: "AWS_ENDPOINT_URL", ValueFrom: secretKeyRef("AWS_S3_ENDPOINT")},
+		))
+		// The environment variables of the user take precedence
+		workerEnv := rayCluster.Spec.WorkerGroupSpecs[0].Template.Spec.Containers[0].Env
+		test.Expect(workerEnv).To(ContainElement(corev1.EnvVar{Name: "AWS_DEFAULT_REGION", Value: "eu-west-1"}))
+		test.Expect(workerEnv).To(ContainElement(corev1.EnvVar{Name: "AWS_SECRET_ACCESS_KEY", ValueFrom: secretKeyRef("AWS_SECRET_ACCESS_KEY")}))
+		test.Expect(workerEnv).To(HaveLen(len(S3SecretEnvVars("data-connection"))))
+
+		// Defaulting is idempotent
+		defaulted := rayCluster.DeepCopy()
+		ApplyRayClusterDefaults(cfg, rayCluster)
+		test.Expect(rayCluster).To(Equal(defaulted))
+	})
+
+	test.T().Run("Negative: Expected no S3 credentials without the annotation", func(t *testing.T) {
+		rayCluster := testRayCluster()
+		ApplyRayClusterDefaults(cfg, rayCluster)
+
+		test.Expect(rayCluster.Spec.HeadGroupSpec.Template.Spec.Containers[0].Env).To(BeEmpty())
+	})
+}
+
+func TestApplyRayClusterDefaultsScratchVolume(t *testing.T) {
+	test := support.NewTest(t)
+
+	cfg := &config.KubeRayConfiguration{
+		RayDashboardOAuthEnabled: ptr.To(false),
+		MTLSEnabled:              ptr.To(false),
+		ScratchVolume: &config.ScratchVolumeConfiguration{
+			Enabled:          ptr.To(true),
+			StorageClassName: "nfs",
+		},
+	}
+	scratchVolume := corev1.Volume{
+		Name: ScratchVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "test-raycluster-scratch"},
+		},
+	}
+
+	test.T().Run("Expected scratch volume to be mounted in the Ray containers", func(t *testing.T) {
+		rayCluster := testRayCluster()
+		rayCluster.Annotations = map[string]string{ScratchVolumeAnnotation: "true"}
+		ApplyRayClusterDefaults(cfg, rayCluster)
+
+		for _, podSpec := range []corev1.PodSpec{rayCluster.Spec.HeadGroupSpec.Template.Spec, rayCluster.Spec.WorkerGroupSpecs[0].Template.Spec} {
+			test.Expect(podSpec.Volumes).To(ConsistOf(scratchVolume))
+			test.Expect(podSpec.Containers[0].VolumeMounts).To(ConsistOf(corev1.VolumeMount{Name: ScratchVolumeName, MountPath: "/home/ray/scratch"}))
+		}
+
+		// Defaulting is idempotent
+		defaulted := rayCluster.DeepCopy()
+		ApplyRayClusterDefaults(cfg, rayCluster)
+		test.Expect(rayCluster).To(Equal(defaulted))
+	})
+
+	test.T().Run("Expected size and storage class to be overridden by the annotations", func(t *testing.T) {
+		rayCluster := testRayCluster()
+		rayCluster.Annotations = map[string]string{
+			ScratchVolumeAnnotation:             "true",
+			ScratchVolumeSizeAnnotation:         "100Gi",
+			ScratchVolumeStorageClassAnnotation: "cephfs",
+		}
+
+		size, err := ScratchVolumeSize(cfg.ScratchVolume, rayCluster)
+		test.Expect(err).NotTo(HaveOccurred())
+		test.Expect(size.String()).To(Equal("100Gi"))
+		test.Expect(ScratchVolumeStorageClassName(cfg.ScratchVolume, rayCluster)).To(Equal("cephfs"))
+
+		size, err = ScratchVolumeSize(cfg.ScratchVolume, testRayCluster())
+		test.Expect(err).NotTo(HaveOccurred())
+		test.Expect(size.String()).To(Equal("10Gi"))
+		test.Expect(ScratchVolumeStorageClassName(cfg.ScratchVolume, testRayCluster())).To(Equal("nfs"))
+	})
+
+	test.T().Run("Negative: Expected error for invalid scratch volume size", func(t *testing.T) {
+		rayCluster := testRayCluster()
+		rayCluster.Annotations = map[string]string{ScratchVolumeSizeAnnotation: "-1Gi"}
+
+		_, err := ScratchVolumeSize(cfg.ScratchVolume, rayCluster)
+		test.Expect(err).To(MatchError(ContainSubstring("must be positive")))
+	})
+
+	test.T().Run("Negative: Expected no scratch volume when it's not requested, or disabled", func(t *testing.T) {
+		rayCluster := testRayCluster()
+		ApplyRayClusterDefaults(cfg, rayCluster)
+		test.Expect(rayCluster.Spec.HeadGroupSpec.Template.Spec.Volumes).To(BeEmpty())
+
+		rayCluster.Annotations = map[string]string{ScratchVolumeAnnotation: "true"}
+		ApplyRayClusterDefaults(&config.KubeRayConfiguration{RayDashboardOAuthEnabled: ptr.To(false), MTLSEnabled: ptr.To(false)}, rayCluster)
+		test.Expect(rayCluster.Spec.HeadGroupSpec.Template.Spec.Volumes).To(BeEmpty())
+	})
+}
+
+func TestApplyRayClusterDefaultsExternalSecrets(t *testing.T) {
+	test := support.NewTest(t)
+
+	cfg := &config.KubeRayConfiguration{
+		RayDashboardOAuthEnabled: ptr.To(false),
+		MTLSEnabled:              ptr.To(false),
+	}
+
+	test.T().Run("Expected Ray pods to be gated until the external Secrets exist", func(t *testing.T) {
+		rayCluster := testRayCluster()
+		rayCluster.Annotations = map[string]string{ExternalSecretsAnnotation: "vault-creds,hf-token"}
+		ApplyRayClusterDefaults(cfg, rayCluster)
+
+		test.Expect(ExternalSecretNames(rayCluster)).To(Equal([]string{"vault-creds", "hf-token"}))
+		gate := corev1.PodSchedulingGate{Name: ExternalSecretsSchedulingGate}
+		test.Expect(rayCluster.Spec.HeadGroupSpec.Template.Spec.SchedulingGates).To(ConsistOf(gate))
+		test.Expect(rayCluster.Spec.WorkerGroupSpecs[0].Template.Spec.SchedulingGates).To(ConsistOf(gate))
+
+		// Defaulting is idempotent
+		defaulted := rayCluster.DeepCopy()
+		ApplyRayClusterDefaults(cfg, rayCluster)
+		test.Expect(rayCluster).To(Equal(defaulted))
+	})
+
+	test.T().Run("Negative: Expected no scheduling gate without external Secrets", func(t *testing.T) {
+		rayCluster := testRayCluster()
+		rayCluster.Annotations = map[string]string{ExternalSecretsAnnotation: " , "}
+		ApplyRayClusterDefaults(cfg, rayCluster)
+
+		test.Expect(rayCluster.Spec.HeadGroupSpec.Template.Spec.SchedulingGates).To(BeEmpty())
+	})
+}
+
+func TestApplyRayClusterDefaultsRayStartParams(t *testing.T) {
+	test := support.NewTest(t)
+
+	cfg := &config.KubeRayConfiguration{
+		RayDashboardOAuthEnabled:           ptr.To(false),
+		MTLSEnabled:                        ptr.To(false),
+		RayStartParamsFromResourcesEnabled: ptr.To(true),
+	}
+	withLimits := func(rayCluster *rayv1.RayCluster) *rayv1.RayCluster {
+		rayCluster.Spec.HeadGroupSpec.Template.Spec.Containers[0].Resources.Limits = corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("1500m"),
+			corev1.ResourceMemory: resource.MustParse("4Gi"),
+		}
+		rayCluster.Spec.WorkerGroupSpecs[0].Template.Spec.Containers[0].Resources.Limits = corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("500m"),
+			corev1.ResourceMemory: resource.MustParse("8G"),
+			"nvidia.com/gpu":      resource.MustParse("2"),
+		}
+		return rayCluster
+	}
+
+	test.T().Run("Expected Ray start parameters to be defaulted from the resource limits", func(t *testing.T) {
+		rayCluster := withLimits(testRayCluster())
+		ApplyRayClusterDefaults(cfg, rayCluster)
+
+		test.Expect(rayCluster.Spec.HeadGroupSpec.RayStartParams).To(Equal(map[string]string{
+			RayStartParamNumCPUs: "1",
+		}))
+		test.Expect(rayCluster.Spec.WorkerGroupSpecs[0].RayStartParams).To(Equal(map[string]string{
+			RayStartParamNumCPUs: "1",
+			RayStartParamNumGPUs: "2",
+		}))
+
+		// Defaulting is idempotent
+		defaulted := rayCluster.DeepCopy()
+		ApplyRayClusterDefaults(cfg, rayCluster)
+		test.Expect(rayCluster).To(Equal(defaulted))
+	})
+
+	test.T().Run("Expected Ray start parameters set by the user to be preserved", func(t *testing.T) {
+		rayCluster := withLimits(testRayCluster())
+		rayCluster.Spec.HeadGroupSpec.RayStartParams = map[string]string{RayStartParamNumCPUs: "0"}
+		ApplyRayClusterDefaults(cfg, rayCluster)
+
+		test.Expect(rayCluster.Spec.HeadGroupSpec.RayStartParams).To(Equal(map[string]string{
+			RayStartParamNumCPUs: "0",
+		}))
+	})
+
+	test.T().Run("Negative: Expected no memory without object store size, as Ray sizes the object store from it", func(t *testing.T) {
+		rayCluster := testRayCluster()
+		rayCluster.Spec.HeadGroupSpec.Template.Spec.Containers[0].Resources.Limits = corev1.ResourceList{
+			corev1.ResourceMemory: resource.MustParse("4Gi"),
+		}
+		ApplyRayClusterDefaults(cfg, rayCluster)
+
+		test.Expect(rayCluster.Spec.HeadGroupSpec.RayStartParams).NotTo(HaveKey(RayStartParamMemory))
+	})
+
+	test.T().Run("Expected the object store, and its headroom, to be subtracted from the memory", func(t *testing.T) {
+		rayCluster := withLimits(testRayCluster())
+		objectStoreCfg := *cfg
+		objectStoreCfg.ObjectStore = &config.ObjectStoreConfiguration{Enabled: ptr.To(true)}
+		ApplyRayClusterDefaults(&objectStoreCfg, rayCluster)
+
+		// 30% of the memory limit for the object store, and 110% of the object store for /dev/shm
+		test.Expect(rayCluster.Spec.HeadGroupSpec.RayStartParams).To(Equal(map[string]string{
+			RayStartParamNumCPUs:           "1",
+			RayStartParamObjectStoreMemory: "1288490188",
+			RayStartParamMemory:            "2877628090",
+		}))
+		test.Expect(rayCluster.Spec.WorkerGroupSpecs[0].RayStartParams).To(Equal(map[string]string{
+			RayStartParamNumCPUs:           "1",
+			RayStartParamNumGPUs:           "2",
+			RayStartParamObjectStoreMemory: "2400000000",
+			RayStartParamMemory:            "5360000000",
+		}))
+
+		// Defaulting is idempotent
+		defaulted := rayCluster.DeepCopy()
+		ApplyRayClusterDefaults(&objectStoreCfg, rayCluster)
+		test.Expect(rayCluster).To(Equal(defaulted))
+	})
+
+	test.T().Run("Expected the object store set by the user to be subtracted from the memory", func(t *testing.T) {
+		rayCluster := withLimits(testRayCluster())
+		rayCluster.Spec.HeadGroupSpec.RayStartParams = map[string]string{RayStartParamObjectStoreMemory: "1000000000"}
+		ApplyRayClusterDefaults(cfg, rayCluster)
+
+		test.Expect(rayCluster.Spec.HeadGroupSpec.RayStartParams).To(HaveKeyWithValue(RayStartParamMemory, "3194967296"))
+	})
+
+	test.T().Run("Negative: Expected no Ray start parameters without limits, or when disabled", func(t *testing.T) {
+		rayCluster := testRayCluster()
+		ApplyRayClusterDefaults(cfg, rayCluster)
+		test.Expect(rayCluster.Spec.HeadGroupSpec.RayStartParams).To(BeEmpty())
+
+		rayCluster = withLimits(testRayCluster())
+		ApplyRayClusterDefaults(&config.KubeRayConfiguration{RayDashboardOAuthEnabled: ptr.To(false), MTLSEnabled: ptr.To(false)}, rayCluster)
+		test.Expect(rayCluster.Spec.HeadGroupSpec.RayStartParams).To(BeEmpty())
+		test.Expect(rayCluster.Spec.WorkerGroupSpecs[0].RayStartParams).To(BeEmpty())
+	})
+}
+
+func TestApplyRayClusterDefaultsObjectStore(t *testing.T) {
+	test := support.NewTest(t)
+
+	cfg := &config.KubeRayConfiguration{
+		RayDashboardOAuthEnabled: ptr.To(false),
+		MTLSEnabled:              ptr.To(false),
+		ObjectStore:              &config.ObjectStoreConfiguration{Enabled: ptr.To(true)},
+	}
+	sharedMemoryVolume := func(sizeLimit int64) corev1.Volume {
+		return corev1.Volume{
+			Name: SharedMemoryVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{Medium: corev1.StorageMediumMemory, SizeLimit: resource.NewQuantity(sizeLimit, resource.BinarySI)},
+			},
+		}
+	}
+	sharedMemoryMount := corev1.VolumeMount{Name: SharedMemoryVolumeName, MountPath: "/dev/shm"}
+
+	test.T().Run("Expected object store and /dev/shm to be sized from the memory limit", func(t *testing.T) {
+		rayCluster := testRayCluster()
+		rayCluster.Spec.HeadGroupSpec.Template.Spec.Containers[0].Resources.Limits = corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("10Gi")}
+		rayCluster.Spec.WorkerGroupSpecs[0].Template.Spec.Containers[0].Resources.Limits = corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("20Gi")}
+		ApplyRayClusterDefaults(cfg, rayCluster)
+
+		test.Expect(rayCluster.Spec.HeadGroupSpec.RayStartParams).To(HaveKeyWithValue(RayStartParamObjectStoreMemory, "3221225472"))
+		test.Expect(rayCluster.Spec.HeadGroupSpec.Template.Spec.Volumes).To(ConsistOf(sharedMemoryVolume(3543348019)))
+		test.Expect(rayCluster.Spec.HeadGroupSpec.Template.Spec.Containers[0].VolumeMounts).To(ConsistOf(sharedMemoryMount))
+		test.Expect(rayCluster.Spec.WorkerGroupSpecs[0].RayStartParams).To(HaveKeyWithValue(RayStartParamObjectStoreMemory, "6442450944"))
+		test.Expect(rayCluster.Spec.WorkerGroupSpecs[0].Template.Spec.Volumes).To(ConsistOf(sharedMemoryVolume(7086696038)))
+		test.Expect(rayCluster.Spec.WorkerGroupSpecs[0].Template.Spec.Containers[0].VolumeMounts).To(ConsistOf(sharedMemoryMount))
+
+		// Defaulting is idempotent
+		defaulted := rayCluster.DeepCopy()
+		ApplyRayClusterDefaults(cfg, rayCluster)
+		test.Expect(rayCluster).To(Equal(defaulted))
+	})
+
+	test.T().Run("Expected /dev/shm to be sized from the object store memory set by the user", func(t *testing.T) {
+		rayCluster := testRayCluster()
+		rayCluster.Spec.HeadGroupSpec.RayStartParams = map[string]string{RayStartParamObjectStoreMemory: "1000000000"}
+		ApplyRayClusterDefaults(cfg, rayCluster)
+
+		test.Expect(rayCluster.Spec.HeadGroupSpec.RayStartParams).To(HaveKeyWithValue(RayStartParamObjectStoreMemory, "1000000000"))
+		test.Expect(rayCluster.Spec.HeadGroupSpec.Template.Spec.Volumes).To(ConsistOf(sharedMemoryVolume(1100000000)))
+	})
+
+	test.T().Run("Expected object store memory percentage to be configurable", func(t *testing.T) {
+		rayCluster := testRayCluster()
+		rayCluster.Spec.HeadGroupSpec.Template.Spec.Containers[0].Resources.Limits = corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("10G")}
+		ApplyRayClusterDefaults(&config.KubeRayConfiguration{
+			RayDashboardOAuthEnabled: ptr.To(false),
+			MTLSEnabled:              ptr.To(false),
+			ObjectStore:              &config.ObjectStoreConfiguration{Enabled: ptr.To(true), MemoryPercentage: ptr.To[int32](50)},
+		}, rayCluster)
+
+		test.Expect(rayCluster.Spec.HeadGroupSpec.RayStartParams).To(HaveKeyWithValue(RayStartParamObjectStoreMemory, "5000000000"))
+	})
+
+	test.T().Run("Negative: Expected /dev/shm mounted by the user to be left unchanged", func(t *testing.T) {
+		rayCluster := testRayCluster()
+		rayCluster.Spec.HeadGroupSpec.Template.Spec.Containers[0].Resources.Limits = corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("10Gi")}
+		rayCluster.Spec.HeadGroupSpec.Template.Spec.Containers[0].VolumeMounts = []corev1.VolumeMount{{Name: "dshm", MountPath: "/dev/shm"}}
+		ApplyRayClusterDefaults(cfg, rayCluster)
+
+		test.Expect(rayCluster.Spec.HeadGroupSpec.RayStartParams).To(BeEmpty())
+		test.Expect(rayCluster.Spec.HeadGroupSpec.Template.Spec.Volumes).To(BeEmpty())
+	})
+
+	test.T().Run("Negative: Expected no object store sizing without memory limit", func(t *testing.T) {
+		rayCluster := testRayCluster()
+		ApplyRayClusterDefaults(cfg, rayCluster)
+
+		test.Expect(rayCluster.Spec.HeadGroupSpec.RayStartParams).To(BeEmpty())
+		test.Expect(rayCluster.Spec.HeadGroupSpec.Template.Spec.Volumes).To(BeEmpty())
+	})
+}
@@ -0,0 +1,179 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package defaults
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/project-codeflare/codeflare-operator/pkg/config"
+)
+
+const (
+	// ShutdownAfterJobFinishesAnnotation defaults the shutdownAfterJobFinishes field of the RayJobs created
+	// in the namespace it is set on. Set on a RayJob, it overrides the field of that RayJob.
+	ShutdownAfterJobFinishesAnnotation = "codeflare.dev/shutdown-after-job-finishes"
+
+	// TTLSecondsAfterFinishedAnnotation defaults the ttlSecondsAfterFinished field of the RayJobs created
+	// in the namespace it is set on. Set on a RayJob, it overrides the field of that RayJob.
+	TTLSecondsAfterFinishedAnnotation = "codeflare.dev/ttl-seconds-after-finished"
+)
+
+// ApplyRayJobNamespaceDefaults mutates the RayJob with the defaults set as annotations on its namespace.
+// The values are taken, in order of precedence, from:
+//   - the annotations on the RayJob
+//   - the RayJob spec, i.e. when shutdownAfterJobFinishes is true or ttlSecondsAfterFinished is not zero
+//   - the annotations on the namespace
+//
+// They only apply to RayJobs that create their own RayCluster, and the TTL only applies to RayJobs
+// that are shut down once finished. The invalid annotation values are ignored, as if they were unset,
+// and returned as an error, so that the valid ones still apply.
+func ApplyRayJobNamespaceDefaults(namespaceAnnotations map[string]string, rayJob *rayv1.RayJob) error {
+	if rayJob.Spec.RayClusterSpec == nil {
+		return nil
+	}
+
+	var errs []error
+	if shutdown, ok, err := boolAnnotation(rayJob.Annotations, ShutdownAfterJobFinishesAnnotation, "RayJob"); ok {
+		rayJob.Spec.ShutdownAfterJobFinishes = shutdown
+	} else {
+		errs = append(errs, err)
+		if !rayJob.Spec.ShutdownAfterJobFinishes {
+			shutdown, ok, err := boolAnnotation(namespaceAnnotations, ShutdownAfterJobFinishesAnnotation, "namespace")
+			if ok {
+				rayJob.Spec.ShutdownAfterJobFinishes = shutdown
+			}
+			errs = append(errs, err)
+		}
+	}
+
+	if !rayJob.Spec.ShutdownAfterJobFinishes {
+		return errors.Join(errs...)
+	}
+
+	if ttl, ok, err := int32Annotation(rayJob.Annotations, TTLSecondsAfterFinishedAnnotation, "RayJob"); ok {
+		rayJob.Spec.TTLSecondsAfterFinished = ttl
+	} else {
+		errs = append(errs, err)
+		if rayJob.Spec.TTLSecondsAfterFinished == 0 {
+			ttl, ok, err := int32Annotation(namespaceAnnotations, TTLSecondsAfterFinishedAnnotation, "namespace")
+			if ok {
+				rayJob.Spec.TTLSecondsAfterFinished = ttl
+			}
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func boolAnnotation(annotations map[string]string, key, owner string) (bool, bool, error) {
+	value, ok := annotations[key]
+	if !ok {
+		return false, false, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, false, fmt.Errorf("invalid %s annotation %s=%q, must be a boolean", owner, key, value)
+	}
+	return b, true, nil
+}
+
+func int32Annotation(annotations map[string]string, key, owner string) (int32, bool, error) {
+	value, ok := annotations[key]
+	if !ok {
+		return 0, false, nil
+	}
+	i, err := strconv.ParseInt(value, 10, 32)
+	if err != nil || i < 0 {
+		return 0, false, fmt.Errorf("invalid %s annotation %s=%q, must be a non-negative integer", owner, key, value)
+	}
+	return int32(i), true, nil
+}
+
+// ApplyRayJobDefaults mutates the RayJob with the defaults derived from the operator configuration.
+// The RayCluster created from the RayJob cluster spec, that inherits the annotations of the RayJob, is defaulted
+// on its own, with ApplyRayClusterDefaults, so only the submitter pod template is defaulted here.
+//
+// KubeRay creates the submitter pod from its default template when the RayJob has none, so that template is set
+// on the RayJob, for the proxy environment variables, and the S3 secret, to be injected into the submitter container.
+// It isn't set for the RayJobs submitted over HTTP, that have no submitter pod, nor for the RayJobs that select an
+// existing RayCluster, whose head image, the default submitter container runs, isn't known at admission.
+func ApplyRayJobDefaults(cfg *config.KubeRayConfiguration, rayJob *rayv1.RayJob) {
+	if cfg == nil {
+		return
+	}
+
+	proxyEnvVars := ProxyEnvVars(cfg.Proxy)
+	secretName := rayJob.Annotations[S3SecretAnnotation]
+	if len(proxyEnvVars) == 0 && secretName == "" {
+		return
+	}
+	if rayJob.Spec.SubmitterPodTemplate == nil {
+		rayJob.Spec.SubmitterPodTemplate = defaultSubmitterPodTemplate(rayJob)
+		if rayJob.Spec.SubmitterPodTemplate == nil {
+			return
+		}
+	}
+
+	for i := range rayJob.Spec.SubmitterPodTemplate.Spec.Containers {
+		container := &rayJob.Spec.SubmitterPodTemplate.Spec.Containers[i]
+		for _, envVar := range proxyEnvVars {
+			container.Env = insertIfAbsent(container.Env, envVar, byEnvVarName)
+		}
+		if secretName != "" {
+			applyS3Secret(secretName, container)
+		}
+	}
+}
+
+// defaultSubmitterPodTemplate returns the submitter pod template KubeRay creates for the RayJob when it has none,
+// or nil when the RayJob has no submitter pod, or its head image isn't known.
+func defaultSubmitterPodTemplate(rayJob *rayv1.RayJob) *corev1.PodTemplateSpec {
+	if rayJob.Spec.SubmissionMode == rayv1.HTTPMode || rayJob.Spec.RayClusterSpec == nil ||
+		len(rayJob.Spec.RayClusterSpec.HeadGroupSpec.Template.Spec.Containers) == 0 {
+		return nil
+	}
+	return &corev1.PodTemplateSpec{
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name: "ray-job-submitter",
+					// The image of the Ray head, as KubeRay does, to be defensive against version mismatch issues
+					Image: rayJob.Spec.RayClusterSpec.HeadGroupSpec.Template.Spec.Containers[0].Image,
+					Resources: corev1.ResourceRequirements{
+						Limits: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("1"),
+							corev1.ResourceMemory: resource.MustParse("1Gi"),
+						},
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("500m"),
+							corev1.ResourceMemory: resource.MustParse("200Mi"),
+						},
+					},
+				},
+			},
+			RestartPolicy: corev1.RestartPolicyNever,
+		},
+	}
+}
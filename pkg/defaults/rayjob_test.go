@@ -0,0 +1,191 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package defaults
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/project-codeflare/codeflare-common/support"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/project-codeflare/codeflare-operator/pkg/config"
+)
+
+func TestApplyRayJobNamespaceDefaults(t *testing.T) {
+	test := support.NewTest(t)
+
+	namespaceAnnotations := map[string]string{
+		ShutdownAfterJobFinishesAnnotation: "true",
+		TTLSecondsAfterFinishedAnnotation:  "600",
+	}
+
+	tests := []struct {
+		name                 string
+		annotations          map[string]string
+		spec                 rayv1.RayJobSpec
+		namespaceAnnotations map[string]string
+		expectedShutdown     bool
+		expectedTTL          int32
+	}{
+		{
+			name:                 "Expected namespace defaults to apply to RayJobs without values",
+			spec:                 rayv1.RayJobSpec{RayClusterSpec: &rayv1.RayClusterSpec{}},
+			namespaceAnnotations: namespaceAnnotations,
+			expectedShutdown:     true,
+			expectedTTL:          600,
+		},
+		{
+			name:                 "Expected RayJob spec TTL to take precedence over namespace default",
+			spec:                 rayv1.RayJobSpec{RayClusterSpec: &rayv1.RayClusterSpec{}, TTLSecondsAfterFinished: 60},
+			namespaceAnnotations: namespaceAnnotations,
+			expectedShutdown:     true,
+			expectedTTL:          60,
+		},
+		{
+			name: "Expected RayJob annotations to take precedence over namespace defaults",
+			annotations: map[string]string{
+				ShutdownAfterJobFinishesAnnotation: "false",
+			},
+			spec:                 rayv1.RayJobSpec{RayClusterSpec: &rayv1.RayClusterSpec{}},
+			namespaceAnnotations: namespaceAnnotations,
+			expectedShutdown:     false,
+			expectedTTL:          0,
+		},
+		{
+			name: "Expected RayJob annotations to take precedence over RayJob spec",
+			annotations: map[string]string{
+				TTLSecondsAfterFinishedAnnotation: "30",
+			},
+			spec:                 rayv1.RayJobSpec{RayClusterSpec: &rayv1.RayClusterSpec{}, ShutdownAfterJobFinishes: true, TTLSecondsAfterFinished: 60},
+			namespaceAnnotations: namespaceAnnotations,
+			expectedShutdown:     true,
+			expectedTTL:          30,
+		},
+		{
+			name:                 "Expected no TTL default for RayJobs that are not shut down",
+			spec:                 rayv1.RayJobSpec{RayClusterSpec: &rayv1.RayClusterSpec{}},
+			namespaceAnnotations: map[string]string{TTLSecondsAfterFinishedAnnotation: "600"},
+			expectedShutdown:     false,
+			expectedTTL:          0,
+		},
+		{
+			name:                 "Expected no defaults for RayJobs using an existing RayCluster",
+			spec:                 rayv1.RayJobSpec{ClusterSelector: map[string]string{"ray.io/cluster": "raycluster"}},
+			namespaceAnnotations: namespaceAnnotations,
+			expectedShutdown:     false,
+			expectedTTL:          0,
+		},
+	}
+
+	for _, tc := range tests {
+		test.T().Run(tc.name, func(t *testing.T) {
+			rayJob := &rayv1.RayJob{Spec: tc.spec}
+			rayJob.Annotations = tc.annotations
+
+			test.Expect(ApplyRayJobNamespaceDefaults(tc.namespaceAnnotations, rayJob)).To(Succeed())
+			test.Expect(rayJob.Spec.ShutdownAfterJobFinishes).To(Equal(tc.expectedShutdown))
+			test.Expect(rayJob.Spec.TTLSecondsAfterFinished).To(Equal(tc.expectedTTL))
+		})
+	}
+
+	test.T().Run("Negative: Expected invalid annotations to be ignored and returned as an error", func(t *testing.T) {
+		rayJob := &rayv1.RayJob{Spec: rayv1.RayJobSpec{RayClusterSpec: &rayv1.RayClusterSpec{}}}
+		err := ApplyRayJobNamespaceDefaults(map[string]string{ShutdownAfterJobFinishesAnnotation: "yes please"}, rayJob)
+		test.Expect(err).To(HaveOccurred())
+		test.Expect(rayJob.Spec.ShutdownAfterJobFinishes).To(BeFalse())
+
+		rayJob = &rayv1.RayJob{Spec: rayv1.RayJobSpec{RayClusterSpec: &rayv1.RayClusterSpec{}, ShutdownAfterJobFinishes: true}}
+		err = ApplyRayJobNamespaceDefaults(map[string]string{TTLSecondsAfterFinishedAnnotation: "-1"}, rayJob)
+		test.Expect(err).To(HaveOccurred())
+		test.Expect(rayJob.Spec.TTLSecondsAfterFinished).To(Equal(int32(0)))
+
+		rayJob = &rayv1.RayJob{Spec: rayv1.RayJobSpec{RayClusterSpec: &rayv1.RayClusterSpec{}}}
+		rayJob.Annotations = map[string]string{ShutdownAfterJobFinishesAnnotation: "yes please"}
+		err = ApplyRayJobNamespaceDefaults(map[string]string{
+			ShutdownAfterJobFinishesAnnotation: "true",
+			TTLSecondsAfterFinishedAnnotation:  "60",
+		}, rayJob)
+		test.Expect(err).To(HaveOccurred())
+		test.Expect(rayJob.Spec.ShutdownAfterJobFinishes).To(BeTrue())
+		test.Expect(rayJob.Spec.TTLSecondsAfterFinished).To(Equal(int32(60)))
+	})
+}
+
+func TestApplyRayJobDefaultsS3Secret(t *testing.T) {
+	test := support.NewTest(t)
+
+	rayJob := &rayv1.RayJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "rayjob", Annotations: map[string]string{S3SecretAnnotation: "data-connection"}},
+		Spec: rayv1.RayJobSpec{
+			SubmitterPodTemplate: &corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "ray-job-submitter"}},
+			}},
+		},
+	}
+	ApplyRayJobDefaults(&config.KubeRayConfiguration{}, rayJob)
+
+	test.Expect(rayJob.Spec.SubmitterPodTemplate.Spec.Containers[0].Env).To(ConsistOf(S3SecretEnvVars("data-connection")))
+}
+
+func TestApplyRayJobDefaultsSubmitterPodTemplate(t *testing.T) {
+	test := support.NewTest(t)
+
+	cfg := &config.KubeRayConfiguration{Proxy: &config.ProxyConfiguration{HTTPSProxy: "http://proxy.example.com:3128"}}
+	rayClusterSpec := &rayv1.RayClusterSpec{
+		HeadGroupSpec: rayv1.HeadGroupSpec{
+			Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "ray-head", Image: "quay.io/modh/ray:2.35.0-py311-cu121"}},
+			}},
+		},
+	}
+
+	test.T().Run("Expected the default submitter pod template to be set, with the proxy environment variables", func(t *testing.T) {
+		rayJob := &rayv1.RayJob{Spec: rayv1.RayJobSpec{RayClusterSpec: rayClusterSpec.DeepCopy()}}
+		ApplyRayJobDefaults(cfg, rayJob)
+
+		test.Expect(rayJob.Spec.SubmitterPodTemplate).NotTo(BeNil())
+		test.Expect(rayJob.Spec.SubmitterPodTemplate.Spec.RestartPolicy).To(Equal(corev1.RestartPolicyNever))
+		test.Expect(rayJob.Spec.SubmitterPodTemplate.Spec.Containers).To(ConsistOf(And(
+			HaveField("Name", "ray-job-submitter"),
+			HaveField("Image", "quay.io/modh/ray:2.35.0-py311-cu121"),
+			HaveField("Env", ConsistOf(ProxyEnvVars(cfg.Proxy))),
+		)))
+	})
+
+	test.T().Run("Expected no submitter pod template to be set without proxy, nor S3 secret", func(t *testing.T) {
+		rayJob := &rayv1.RayJob{Spec: rayv1.RayJobSpec{RayClusterSpec: rayClusterSpec.DeepCopy()}}
+		ApplyRayJobDefaults(&config.KubeRayConfiguration{}, rayJob)
+
+		test.Expect(rayJob.Spec.SubmitterPodTemplate).To(BeNil())
+	})
+
+	test.T().Run("Expected no submitter pod template to be set for RayJobs without submitter pod, or head image", func(t *testing.T) {
+		// The RayJobs submitted over HTTP have no submitter pod
+		rayJob := &rayv1.RayJob{Spec: rayv1.RayJobSpec{RayClusterSpec: rayClusterSpec.DeepCopy(), SubmissionMode: rayv1.HTTPMode}}
+		ApplyRayJobDefaults(cfg, rayJob)
+		test.Expect(rayJob.Spec.SubmitterPodTemplate).To(BeNil())
+
+		// The head image of the RayClusters selected by the RayJobs isn't known at admission
+		rayJob = &rayv1.RayJob{Spec: rayv1.RayJobSpec{ClusterSelector: map[string]string{"ray.io/cluster": "raycluster"}}}
+		ApplyRayJobDefaults(cfg, rayJob)
+		test.Expect(rayJob.Spec.SubmitterPodTemplate).To(BeNil())
+	})
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package defaults
+
+import (
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// The Ray start parameters of the resources of the Ray nodes.
+const (
+	RayStartParamNumCPUs = "num-cpus"
+	RayStartParamNumGPUs = "num-gpus"
+	RayStartParamMemory  = "memory"
+)
+
+// applyRayStartParamsFromResources sets the num-cpus, num-gpus and memory Ray start parameters that are missing
+// from the resource limits of the Ray container. The CPU limit is rounded down, as opposed to KubeRay that rounds
+// it up, so that Ray doesn't run more tasks concurrently than the CPU quota of the container allows, but at least
+// one CPU is declared for a non-zero limit, so that the node can still run tasks. The memory excludes the object
+// store, so the object-store-memory Ray start parameter must be set beforehand. It's left unset when the size of the
+// object store isn't known, so that Ray subtracts the object store it sizes itself from the memory it detects.
+func applyRayStartParamsFromResources(rayStartParams *map[string]string, container *corev1.Container) {
+	limits := container.Resources.Limits
+	params := map[string]string{}
+
+	if cpu, ok := limits[corev1.ResourceCPU]; ok && !cpu.IsZero() {
+		params[RayStartParamNumCPUs] = strconv.FormatInt(max(cpu.MilliValue()/1000, 1), 10)
+	}
+	var gpus int64
+	for name, quantity := range limits {
+		if strings.HasSuffix(string(name), "/gpu") {
+			gpus += quantity.Value()
+		}
+	}
+	if gpus > 0 {
+		params[RayStartParamNumGPUs] = strconv.FormatInt(gpus, 10)
+	}
+	// The object store is allocated in /dev/shm, that's accounted for in the memory limit of the container,
+	// so it's subtracted, along with the headroom of /dev/shm, from the memory available to the tasks
+	objectStoreMemory, err := strconv.ParseInt((*rayStartParams)[RayStartParamObjectStoreMemory], 10, 64)
+	if memory, ok := limits[corev1.ResourceMemory]; ok && !memory.IsZero() && err == nil && objectStoreMemory > 0 {
+		if heapMemory := memory.Value() - objectStoreMemory*(100+sharedMemoryHeadroomPercentage)/100; heapMemory > 0 {
+			params[RayStartParamMemory] = strconv.FormatInt(heapMemory, 10)
+		}
+	}
+
+	for key, value := range params {
+		if _, ok := (*rayStartParams)[key]; ok {
+			continue
+		}
+		if *rayStartParams == nil {
+			*rayStartParams = map[string]string{}
+		}
+		(*rayStartParams)[key] = value
+	}
+}
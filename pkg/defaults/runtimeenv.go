@@ -0,0 +1,142 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package defaults
+
+import (
+	"slices"
+	"strings"
+
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+
+	"github.com/project-codeflare/codeflare-operator/pkg/config"
+)
+
+const pipExtraIndexURLOption = "--extra-index-url"
+
+// ApplyAcceleratorRuntimeEnv appends the pip settings of the accelerators the RayCluster requests, according
+// to the catalog, to the runtime env of the RayJob. Only runtime envs that install pip packages, listed inline,
+// are extended, and the requirements of packages the runtime env already lists are left untouched. The runtime env
+// YAML is left as is when nothing is appended, including when it's invalid, so that it's validated by KubeRay.
+func ApplyAcceleratorRuntimeEnv(catalog []config.AcceleratorRuntimeEnvConfiguration, rayJob *rayv1.RayJob, clusterSpec *rayv1.RayClusterSpec) error {
+	if clusterSpec == nil || strings.TrimSpace(rayJob.Spec.RuntimeEnvYAML) == "" {
+		return nil
+	}
+
+	requested := requestedResources(clusterSpec)
+	var accelerators []config.AcceleratorRuntimeEnvConfiguration
+	for _, accelerator := range catalog {
+		if slices.Contains(requested, accelerator.ResourceName) {
+			accelerators = append(accelerators, accelerator)
+		}
+	}
+	if len(accelerators) == 0 {
+		return nil
+	}
+
+	runtimeEnv := map[string]any{}
+	if err := yaml.Unmarshal([]byte(rayJob.Spec.RuntimeEnvYAML), &runtimeEnv); err != nil {
+		return nil
+	}
+
+	// The pip field is either a list of requirements, or a dictionary with the requirements as packages,
+	// otherwise it's the path of a requirements file, that cannot be extended.
+	var requirements []any
+	pip, isDict := runtimeEnv["pip"].(map[string]any)
+	if isDict {
+		requirements, _ = pip["packages"].([]any)
+	} else if list, ok := runtimeEnv["pip"].([]any); ok {
+		requirements = list
+	} else {
+		return nil
+	}
+
+	updated := requirements
+	for _, accelerator := range accelerators {
+		for _, url := range accelerator.PipExtraIndexURLs {
+			option := pipExtraIndexURLOption + " " + url
+			if !slices.Contains(updated, any(option)) {
+				updated = append(updated, option)
+			}
+		}
+		for _, requirement := range accelerator.PipPackages {
+			if !containsPipPackage(updated, pipPackageName(requirement)) {
+				updated = append(updated, requirement)
+			}
+		}
+	}
+	if len(updated) == len(requirements) {
+		return nil
+	}
+
+	if isDict {
+		pip["packages"] = updated
+	} else {
+		runtimeEnv["pip"] = updated
+	}
+	data, err := yaml.Marshal(runtimeEnv)
+	if err != nil {
+		return err
+	}
+	rayJob.Spec.RuntimeEnvYAML = string(data)
+
+	return nil
+}
+
+// requestedResources returns the names of the resources requested, or limited, by the containers of the RayCluster.
+func requestedResources(clusterSpec *rayv1.RayClusterSpec) []corev1.ResourceName {
+	var names []corev1.ResourceName
+	addContainers := func(containers []corev1.Container) {
+		for _, container := range containers {
+			for _, resources := range []corev1.ResourceList{container.Resources.Requests, container.Resources.Limits} {
+				for name, quantity := range resources {
+					if !quantity.IsZero() && !slices.Contains(names, name) {
+						names = append(names, name)
+					}
+				}
+			}
+		}
+	}
+	addContainers(clusterSpec.HeadGroupSpec.Template.Spec.Containers)
+	for _, workerGroup := range clusterSpec.WorkerGroupSpecs {
+		addContainers(workerGroup.Template.Spec.Containers)
+	}
+	return names
+}
+
+func containsPipPackage(requirements []any, name string) bool {
+	for _, requirement := range requirements {
+		if s, ok := requirement.(string); ok && pipPackageName(s) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// pipPackageName returns the normalized name of the package of a pip requirement, or an empty string for options.
+func pipPackageName(requirement string) string {
+	requirement = strings.TrimSpace(requirement)
+	if strings.HasPrefix(requirement, "-") {
+		return ""
+	}
+	if i := strings.IndexAny(requirement, "=<>!~[;@ "); i >= 0 {
+		requirement = requirement[:i]
+	}
+	return strings.ReplaceAll(strings.ToLower(requirement), "_", "-")
+}
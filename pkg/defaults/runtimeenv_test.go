@@ -0,0 +1,124 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package defaults
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/project-codeflare/codeflare-common/support"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/yaml"
+
+	"github.com/project-codeflare/codeflare-operator/pkg/config"
+)
+
+func TestApplyAcceleratorRuntimeEnv(t *testing.T) {
+	test := support.NewTest(t)
+
+	catalog := []config.AcceleratorRuntimeEnvConfiguration{
+		{
+			ResourceName:      "amd.com/gpu",
+			PipExtraIndexURLs: []string{"https://download.pytorch.org/whl/rocm6.0"},
+			PipPackages:       []string{"torch==2.3.1+rocm6.0", "pytorch-triton-rocm"},
+		},
+	}
+	clusterSpec := func(accelerator corev1.ResourceName) *rayv1.RayClusterSpec {
+		return &rayv1.RayClusterSpec{
+			HeadGroupSpec: rayv1.HeadGroupSpec{
+				Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "ray-head"}}}},
+			},
+			WorkerGroupSpecs: []rayv1.WorkerGroupSpec{{
+				Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{
+					Name: "ray-worker",
+					Resources: corev1.ResourceRequirements{
+						Limits: corev1.ResourceList{accelerator: resource.MustParse("1")},
+					},
+				}}}},
+			}},
+		}
+	}
+	pip := func(rayJob *rayv1.RayJob) any {
+		runtimeEnv := map[string]any{}
+		test.Expect(yaml.Unmarshal([]byte(rayJob.Spec.RuntimeEnvYAML), &runtimeEnv)).To(Succeed())
+		return runtimeEnv["pip"]
+	}
+
+	test.T().Run("Expected accelerator pip settings to be appended to the pip list", func(t *testing.T) {
+		rayJob := &rayv1.RayJob{Spec: rayv1.RayJobSpec{RuntimeEnvYAML: `
+pip:
+  - pytorch_lightning==1.5.10
+env_vars:
+  FOO: bar
+`}}
+		test.Expect(ApplyAcceleratorRuntimeEnv(catalog, rayJob, clusterSpec("amd.com/gpu"))).To(Succeed())
+
+		test.Expect(pip(rayJob)).To(Equal([]any{
+			"pytorch_lightning==1.5.10",
+			"--extra-index-url https://download.pytorch.org/whl/rocm6.0",
+			"torch==2.3.1+rocm6.0",
+			"pytorch-triton-rocm",
+		}))
+		test.Expect(rayJob.Spec.RuntimeEnvYAML).To(ContainSubstring("FOO: bar"))
+	})
+
+	test.T().Run("Expected user provided package requirements to be preserved", func(t *testing.T) {
+		rayJob := &rayv1.RayJob{Spec: rayv1.RayJobSpec{RuntimeEnvYAML: `
+pip:
+  packages:
+    - Torch>=2.1
+  pip_check: false
+`}}
+		test.Expect(ApplyAcceleratorRuntimeEnv(catalog, rayJob, clusterSpec("amd.com/gpu"))).To(Succeed())
+
+		test.Expect(pip(rayJob)).To(Equal(map[string]any{
+			"packages": []any{
+				"Torch>=2.1",
+				"--extra-index-url https://download.pytorch.org/whl/rocm6.0",
+				"pytorch-triton-rocm",
+			},
+			"pip_check": false,
+		}))
+	})
+
+	test.T().Run("Expected the runtime env to be left untouched for other accelerators", func(t *testing.T) {
+		runtimeEnvYAML := "pip:\n  - torch\n"
+		rayJob := &rayv1.RayJob{Spec: rayv1.RayJobSpec{RuntimeEnvYAML: runtimeEnvYAML}}
+		test.Expect(ApplyAcceleratorRuntimeEnv(catalog, rayJob, clusterSpec("nvidia.com/gpu"))).To(Succeed())
+
+		test.Expect(rayJob.Spec.RuntimeEnvYAML).To(Equal(runtimeEnvYAML))
+	})
+
+	test.T().Run("Expected the runtime env to be left untouched when it has no inline pip requirements", func(t *testing.T) {
+		for _, runtimeEnvYAML := range []string{"", "pip: requirements.txt\n", "working_dir: /home/ray/jobs\n"} {
+			rayJob := &rayv1.RayJob{Spec: rayv1.RayJobSpec{RuntimeEnvYAML: runtimeEnvYAML}}
+			test.Expect(ApplyAcceleratorRuntimeEnv(catalog, rayJob, clusterSpec("amd.com/gpu"))).To(Succeed())
+
+			test.Expect(rayJob.Spec.RuntimeEnvYAML).To(Equal(runtimeEnvYAML))
+		}
+	})
+
+	test.T().Run("Negative: invalid runtime envs are left untouched", func(t *testing.T) {
+		rayJob := &rayv1.RayJob{Spec: rayv1.RayJobSpec{RuntimeEnvYAML: "pip: [torch"}}
+		test.Expect(ApplyAcceleratorRuntimeEnv(catalog, rayJob, clusterSpec("amd.com/gpu"))).To(Succeed())
+
+		test.Expect(rayJob.Spec.RuntimeEnvYAML).To(Equal("pip: [torch"))
+	})
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package defaults
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+)
+
+// S3SecretAnnotation is the name of the Secret, in the namespace of the RayCluster, or RayJob, it is set on,
+// whose S3 credentials are exposed as AWS_* environment variables to the Ray and submitter containers.
+// The Secret keys follow the ODH data connection convention, e.g., AWS_ACCESS_KEY_ID and AWS_S3_ENDPOINT.
+const S3SecretAnnotation = "codeflare.dev/s3-secret"
+
+// s3SecretKeys maps the environment variables read by the S3 clients, e.g., boto3 or pyarrow, to the Secret keys
+// they're set from. AWS_ENDPOINT_URL is set from the AWS_S3_ENDPOINT key of the ODH data connections.
+var s3SecretKeys = []struct{ envVar, key string }{
+	{"AWS_ACCESS_KEY_ID", "AWS_ACCESS_KEY_ID"},
+	{"AWS_SECRET_ACCESS_KEY", "AWS_SECRET_ACCESS_KEY"},
+	{"AWS_SESSION_TOKEN", "AWS_SESSION_TOKEN"},
+	{"AWS_DEFAULT_REGION", "AWS_DEFAULT_REGION"},
+	{"AWS_S3_ENDPOINT", "AWS_S3_ENDPOINT"},
+	{"AWS_ENDPOINT_URL", "AWS_S3_ENDPOINT"},
+	{"AWS_S3_BUCKET", "AWS_S3_BUCKET"},
+}
+
+// S3SecretEnvVars returns the AWS_* environment variables set from the keys of the Secret. They are optional,
+// so that the Secret only needs the keys that are required to access the storage.
+func S3SecretEnvVars(secretName string) []corev1.EnvVar {
+	envVars := make([]corev1.EnvVar, 0, len(s3SecretKeys))
+	for _, secretKey := range s3SecretKeys {
+		envVars = append(envVars, corev1.EnvVar{
+			Name: secretKey.envVar,
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: secretName},
+					Key:                  secretKey.key,
+					Optional:             ptr.To(true),
+				},
+			},
+		})
+	}
+	return envVars
+}
+
+// applyS3Secret adds the AWS_* environment variables of the S3 Secret to the container,
+// except for the variables it sets already.
+func applyS3Secret(secretName string, container *corev1.Container) {
+	for _, envVar := range S3SecretEnvVars(secretName) {
+		container.Env = insertIfAbsent(container.Env, envVar, byEnvVarName)
+	}
+}
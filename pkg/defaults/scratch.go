@@ -0,0 +1,117 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package defaults
+
+import (
+	"fmt"
+	"strconv"
+
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/utils/ptr"
+
+	"github.com/project-codeflare/codeflare-operator/pkg/config"
+)
+
+const (
+	// ScratchVolumeAnnotation requests, when set to true on a RayCluster, or RayJob, a shared scratch volume,
+	// i.e., a ReadWriteMany PersistentVolumeClaim provisioned by the operator, that's mounted in all the Ray pods.
+	ScratchVolumeAnnotation = "codeflare.dev/scratch-volume"
+	// ScratchVolumeSizeAnnotation and ScratchVolumeStorageClassAnnotation override the size, and storage class,
+	// of the scratch volume configured for the operator.
+	ScratchVolumeSizeAnnotation         = "codeflare.dev/scratch-volume-size"
+	ScratchVolumeStorageClassAnnotation = "codeflare.dev/scratch-volume-storage-class"
+
+	ScratchVolumeName = "scratch"
+
+	defaultScratchVolumeSize      = "10Gi"
+	defaultScratchVolumeMountPath = "/home/ray/scratch"
+)
+
+// IsScratchVolumeRequested returns whether the scratch volume is enabled in the operator configuration,
+// and requested by the RayCluster.
+func IsScratchVolumeRequested(cfg *config.KubeRayConfiguration, rayCluster *rayv1.RayCluster) bool {
+	if cfg == nil || cfg.ScratchVolume == nil || !ptr.Deref(cfg.ScratchVolume.Enabled, false) {
+		return false
+	}
+	requested, _ := strconv.ParseBool(rayCluster.Annotations[ScratchVolumeAnnotation])
+	return requested
+}
+
+// ScratchVolumeClaimName returns the name of the scratch volume PersistentVolumeClaim of the RayCluster.
+func ScratchVolumeClaimName(rayCluster *rayv1.RayCluster) string {
+	return rayCluster.Name + "-scratch"
+}
+
+// ScratchVolumeSize returns the size of the scratch volume of the RayCluster, from its annotation,
+// or the operator configuration.
+func ScratchVolumeSize(cfg *config.ScratchVolumeConfiguration, rayCluster *rayv1.RayCluster) (resource.Quantity, error) {
+	size := defaultScratchVolumeSize
+	if cfg != nil && cfg.Size != "" {
+		size = cfg.Size
+	}
+	if value, ok := rayCluster.Annotations[ScratchVolumeSizeAnnotation]; ok {
+		size = value
+	}
+	quantity, err := resource.ParseQuantity(size)
+	if err != nil {
+		return resource.Quantity{}, fmt.Errorf("invalid scratch volume size %q: %w", size, err)
+	}
+	if quantity.Sign() <= 0 {
+		return resource.Quantity{}, fmt.Errorf("invalid scratch volume size %q: must be positive", size)
+	}
+	return quantity, nil
+}
+
+// ScratchVolumeStorageClassName returns the storage class of the scratch volume of the RayCluster, from its
+// annotation, or the operator configuration. It's empty when the default storage class is used.
+func ScratchVolumeStorageClassName(cfg *config.ScratchVolumeConfiguration, rayCluster *rayv1.RayCluster) string {
+	if value, ok := rayCluster.Annotations[ScratchVolumeStorageClassAnnotation]; ok {
+		return value
+	}
+	if cfg != nil {
+		return cfg.StorageClassName
+	}
+	return ""
+}
+
+// ScratchVolume returns the volume of the scratch volume PersistentVolumeClaim of the RayCluster.
+func ScratchVolume(rayCluster *rayv1.RayCluster) corev1.Volume {
+	return corev1.Volume{
+		Name: ScratchVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+				ClaimName: ScratchVolumeClaimName(rayCluster),
+			},
+		},
+	}
+}
+
+// applyScratchVolume adds the scratch volume to the pod, and mounts it in its Ray container.
+func applyScratchVolume(cfg *config.ScratchVolumeConfiguration, rayCluster *rayv1.RayCluster, podSpec *corev1.PodSpec) {
+	mountPath := defaultScratchVolumeMountPath
+	if cfg.MountPath != "" {
+		mountPath = cfg.MountPath
+	}
+	podSpec.Volumes = upsert(podSpec.Volumes, ScratchVolume(rayCluster), withVolumeName(ScratchVolumeName))
+	podSpec.Containers[0].VolumeMounts = upsert(podSpec.Containers[0].VolumeMounts, corev1.VolumeMount{
+		Name:      ScratchVolumeName,
+		MountPath: mountPath,
+	}, byVolumeMountName)
+}
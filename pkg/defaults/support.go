@@ -0,0 +1,62 @@
+package defaults
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+type compare[T any] func(T, T) bool
+
+func upsert[T any](items []T, item T, predicate compare[T]) []T {
+	for i, t := range items {
+		if predicate(t, item) {
+			items[i] = item
+			return items
+		}
+	}
+	return append(items, item)
+}
+
+// insertIfAbsent appends the item unless an item matching the predicate already exists,
+// so that values provided by users take precedence over the defaults.
+func insertIfAbsent[T any](items []T, item T, predicate compare[T]) []T {
+	for _, t := range items {
+		if predicate(t, item) {
+			return items
+		}
+	}
+	return append(items, item)
+}
+
+func withContainerName(name string) compare[corev1.Container] {
+	return func(c1, c2 corev1.Container) bool {
+		return c1.Name == name
+	}
+}
+
+func withVolumeName(name string) compare[corev1.Volume] {
+	return func(v1, v2 corev1.Volume) bool {
+		return v1.Name == name
+	}
+}
+
+var byVolumeMountName = compare[corev1.VolumeMount](
+	func(v1, v2 corev1.VolumeMount) bool {
+		return v1.Name == v2.Name
+	})
+
+var byEnvVarName = compare[corev1.EnvVar](
+	func(e1, e2 corev1.EnvVar) bool {
+		return e1.Name == e2.Name
+	})
+
+func withEnvVarName(name string) compare[corev1.EnvVar] {
+	return func(e1, e2 corev1.EnvVar) bool {
+		return e1.Name == name
+	}
+}
+
+func withSchedulingGateName(name string) compare[corev1.PodSchedulingGate] {
+	return func(g1, g2 corev1.PodSchedulingGate) bool {
+		return g1.Name == name
+	}
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package defaults
+
+import (
+	"fmt"
+	"strings"
+
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	// RequiredTopologyAnnotation is the topology level, e.g., a rack or block node label, the workers of the
+	// RayClusters created in the namespace it is set on must be placed within a single domain of, by Kueue
+	// Topology-Aware Scheduling.
+	RequiredTopologyAnnotation = "codeflare.dev/required-topology"
+
+	// PreferredTopologyAnnotation is the topology level the workers of the RayClusters created in the namespace
+	// it is set on are preferably placed within a single domain of, by Kueue Topology-Aware Scheduling.
+	PreferredTopologyAnnotation = "codeflare.dev/preferred-topology"
+
+	// KueuePodSetRequiredTopologyAnnotation and KueuePodSetPreferredTopologyAnnotation are the annotations
+	// of the pod templates Kueue reads the topology level of the PodSets from.
+	KueuePodSetRequiredTopologyAnnotation  = "kueue.x-k8s.io/podset-required-topology"
+	KueuePodSetPreferredTopologyAnnotation = "kueue.x-k8s.io/podset-preferred-topology"
+)
+
+// ApplyTopologyNamespaceDefaults sets the topology level from the annotations of the namespace on the pod templates
+// of the worker groups of the RayCluster, so that the workers of each group are placed close to each other by Kueue.
+// The worker groups that request a topology level already are left unchanged. An error is returned, and the RayCluster
+// is left unchanged, if the annotations of the namespace are invalid.
+func ApplyTopologyNamespaceDefaults(namespaceAnnotations map[string]string, spec *rayv1.RayClusterSpec) error {
+	annotation, level, err := topologyPolicy(namespaceAnnotations)
+	if err != nil || annotation == "" {
+		return err
+	}
+
+	for i := range spec.WorkerGroupSpecs {
+		template := &spec.WorkerGroupSpecs[i].Template
+		if _, ok := template.Annotations[KueuePodSetRequiredTopologyAnnotation]; ok {
+			continue
+		}
+		if _, ok := template.Annotations[KueuePodSetPreferredTopologyAnnotation]; ok {
+			continue
+		}
+		if template.Annotations == nil {
+			template.Annotations = map[string]string{}
+		}
+		template.Annotations[annotation] = level
+	}
+
+	return nil
+}
+
+// topologyPolicy returns the annotation of the pod templates, and the topology level, of the topology policy set on
+// the namespace, if any.
+func topologyPolicy(namespaceAnnotations map[string]string) (string, string, error) {
+	required, hasRequired := namespaceAnnotations[RequiredTopologyAnnotation]
+	preferred, hasPreferred := namespaceAnnotations[PreferredTopologyAnnotation]
+	switch {
+	case hasRequired && hasPreferred:
+		return "", "", fmt.Errorf("invalid namespace annotations, %s and %s are mutually exclusive", RequiredTopologyAnnotation, PreferredTopologyAnnotation)
+	case hasRequired:
+		return KueuePodSetRequiredTopologyAnnotation, required, validateTopologyLevel(RequiredTopologyAnnotation, required)
+	case hasPreferred:
+		return KueuePodSetPreferredTopologyAnnotation, preferred, validateTopologyLevel(PreferredTopologyAnnotation, preferred)
+	}
+	return "", "", nil
+}
+
+func validateTopologyLevel(key, level string) error {
+	if errs := validation.IsQualifiedName(level); len(errs) > 0 {
+		return fmt.Errorf("invalid namespace annotation %s=%q, must be a node label key: %s", key, level, strings.Join(errs, ", "))
+	}
+	return nil
+}
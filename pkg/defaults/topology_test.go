@@ -0,0 +1,100 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package defaults
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/project-codeflare/codeflare-common/support"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestApplyTopologyNamespaceDefaults(t *testing.T) {
+	test := support.NewTest(t)
+
+	const rackLabel = "cloud.provider.com/topology-rack"
+
+	workerGroup := func(annotations map[string]string) rayv1.WorkerGroupSpec {
+		return rayv1.WorkerGroupSpec{Template: corev1.PodTemplateSpec{ObjectMeta: metav1.ObjectMeta{Annotations: annotations}}}
+	}
+
+	tests := []struct {
+		name                 string
+		namespaceAnnotations map[string]string
+		workerGroups         []rayv1.WorkerGroupSpec
+		expectedAnnotations  []map[string]string
+		expectedError        bool
+	}{
+		{
+			name:                 "Expected required topology of the namespace to be set on the worker groups",
+			namespaceAnnotations: map[string]string{RequiredTopologyAnnotation: rackLabel},
+			workerGroups:         []rayv1.WorkerGroupSpec{workerGroup(nil), workerGroup(map[string]string{"app": "ray"})},
+			expectedAnnotations: []map[string]string{
+				{KueuePodSetRequiredTopologyAnnotation: rackLabel},
+				{"app": "ray", KueuePodSetRequiredTopologyAnnotation: rackLabel},
+			},
+		},
+		{
+			name:                 "Expected preferred topology of the namespace to be set on the worker groups",
+			namespaceAnnotations: map[string]string{PreferredTopologyAnnotation: rackLabel},
+			workerGroups:         []rayv1.WorkerGroupSpec{workerGroup(nil)},
+			expectedAnnotations:  []map[string]string{{KueuePodSetPreferredTopologyAnnotation: rackLabel}},
+		},
+		{
+			name:                 "Expected topology of the worker groups to take precedence over namespace default",
+			namespaceAnnotations: map[string]string{RequiredTopologyAnnotation: rackLabel},
+			workerGroups:         []rayv1.WorkerGroupSpec{workerGroup(map[string]string{KueuePodSetPreferredTopologyAnnotation: "kubernetes.io/hostname"})},
+			expectedAnnotations:  []map[string]string{{KueuePodSetPreferredTopologyAnnotation: "kubernetes.io/hostname"}},
+		},
+		{
+			name:                "Expected no topology without namespace default",
+			workerGroups:        []rayv1.WorkerGroupSpec{workerGroup(nil)},
+			expectedAnnotations: []map[string]string{nil},
+		},
+		{
+			name:                 "Expected error for mutually exclusive namespace annotations",
+			namespaceAnnotations: map[string]string{RequiredTopologyAnnotation: rackLabel, PreferredTopologyAnnotation: rackLabel},
+			workerGroups:         []rayv1.WorkerGroupSpec{workerGroup(nil)},
+			expectedError:        true,
+		},
+		{
+			name:                 "Expected error for invalid namespace annotation",
+			namespaceAnnotations: map[string]string{RequiredTopologyAnnotation: "not a label"},
+			workerGroups:         []rayv1.WorkerGroupSpec{workerGroup(nil)},
+			expectedError:        true,
+		},
+	}
+
+	for _, tc := range tests {
+		test.T().Run(tc.name, func(t *testing.T) {
+			spec := &rayv1.RayClusterSpec{WorkerGroupSpecs: tc.workerGroups}
+			err := ApplyTopologyNamespaceDefaults(tc.namespaceAnnotations, spec)
+			if tc.expectedError {
+				test.Expect(err).To(HaveOccurred())
+				return
+			}
+			test.Expect(err).NotTo(HaveOccurred())
+			for i, expected := range tc.expectedAnnotations {
+				test.Expect(spec.WorkerGroupSpecs[i].Template.Annotations).To(Equal(expected))
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package health provides the checks of the readiness endpoint of the operator, so that it's taken out
+// of the endpoints of the webhook Service, and the failure is surfaced, rather than admission requests
+// failing silently, when the webhooks can't serve them after changes to the cluster.
+package health
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+)
+
+// log is for logging in this package.
+var healthlog = ctrl.Log.WithName("health")
+
+// CertificateChecker returns a checker that fails when the webhook serving certificate in the directory
+// can't be read, isn't valid yet, or expires within the threshold, e.g., because its rotation has failed.
+func CertificateChecker(certDir string, threshold time.Duration) healthz.Checker {
+	return func(_ *http.Request) error {
+		return checkCertificate(filepath.Join(certDir, "tls.crt"), threshold, time.Now())
+	}
+}
+
+func checkCertificate(path string, threshold time.Duration, now time.Time) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("cannot read the webhook serving certificate: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return fmt.Errorf("no certificate found in %s", path)
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return fmt.Errorf("invalid webhook serving certificate: %w", err)
+	}
+	if now.Before(cert.NotBefore) {
+		return fmt.Errorf("the webhook serving certificate is not valid before %s", cert.NotBefore.Format(time.RFC3339))
+	}
+	if now.Add(threshold).After(cert.NotAfter) {
+		return fmt.Errorf("the webhook serving certificate expires at %s", cert.NotAfter.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// APIChecker returns a checker that fails when one of the resources isn't served by the API server,
+// e.g., because its CRD has been deleted. Other discovery errors, e.g., when the requests are throttled,
+// don't fail the check, so that the operator isn't taken out of the webhook endpoints by API server pressure.
+func APIChecker(client discovery.DiscoveryInterface, resources ...schema.GroupVersionResource) healthz.Checker {
+	return func(_ *http.Request) error {
+		for _, resource := range resources {
+			list, err := client.ServerResourcesForGroupVersion(resource.GroupVersion().String())
+			if apierrors.IsNotFound(err) {
+				return fmt.Errorf("%s API is not available", resource.GroupResource())
+			} else if err != nil {
+				healthlog.Info("WARN: cannot discover API", "api", resource.GroupResource().String(), "error", err.Error())
+				continue
+			}
+			found := false
+			for _, r := range list.APIResources {
+				if r.Name == resource.Resource {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return fmt.Errorf("%s API is not available", resource.GroupResource())
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package health
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/project-codeflare/codeflare-common/support"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func writeCertificate(t *testing.T, notBefore, notAfter time.Time) string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "codeflare-operator-webhook-service.openshift-operators.svc"},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "tls.crt")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestCheckCertificate(t *testing.T) {
+	test := support.NewTest(t)
+
+	now := time.Date(2024, time.October, 17, 10, 0, 0, 0, time.UTC)
+
+	test.T().Run("Expected valid certificate to pass the check", func(t *testing.T) {
+		path := writeCertificate(t, now.Add(-time.Hour), now.AddDate(1, 0, 0))
+		test.Expect(checkCertificate(path, 24*time.Hour, now)).To(Succeed())
+	})
+
+	test.T().Run("Expected certificate expiring within the threshold to fail the check", func(t *testing.T) {
+		path := writeCertificate(t, now.Add(-time.Hour), now.Add(time.Hour))
+		test.Expect(checkCertificate(path, 24*time.Hour, now)).To(MatchError(ContainSubstring("expires at")))
+	})
+
+	test.T().Run("Expected certificate not valid yet to fail the check", func(t *testing.T) {
+		path := writeCertificate(t, now.Add(time.Hour), now.AddDate(1, 0, 0))
+		test.Expect(checkCertificate(path, 24*time.Hour, now)).To(MatchError(ContainSubstring("not valid before")))
+	})
+
+	test.T().Run("Expected missing or invalid certificate to fail the check", func(t *testing.T) {
+		test.Expect(checkCertificate(filepath.Join(t.TempDir(), "tls.crt"), 24*time.Hour, now)).NotTo(Succeed())
+
+		path := filepath.Join(t.TempDir(), "tls.crt")
+		test.Expect(os.WriteFile(path, []byte("not a certificate"), 0o600)).To(Succeed())
+		test.Expect(checkCertificate(path, 24*time.Hour, now)).To(MatchError(ContainSubstring("no certificate found")))
+	})
+}
+
+// failingDiscovery fails the discovery requests, as the fake discovery doesn't return the errors of its reactors.
+type failingDiscovery struct {
+	*fakediscovery.FakeDiscovery
+	err error
+}
+
+func (d *failingDiscovery) ServerResourcesForGroupVersion(string) (*metav1.APIResourceList, error) {
+	return nil, d.err
+}
+
+func TestAPIChecker(t *testing.T) {
+	test := support.NewTest(t)
+
+	rayClusters := schema.GroupVersionResource{Group: "ray.io", Version: "v1", Resource: "rayclusters"}
+	appWrappers := schema.GroupVersionResource{Group: "workload.codeflare.dev", Version: "v1beta2", Resource: "appwrappers"}
+
+	newDiscovery := func(resources ...*metav1.APIResourceList) *fakediscovery.FakeDiscovery {
+		return &fakediscovery.FakeDiscovery{Fake: &clienttesting.Fake{Resources: resources}}
+	}
+
+	test.T().Run("Expected check to pass when all the APIs are served", func(t *testing.T) {
+		client := newDiscovery(
+			&metav1.APIResourceList{GroupVersion: "ray.io/v1", APIResources: []metav1.APIResource{{Name: "rayclusters"}, {Name: "rayjobs"}}},
+			&metav1.APIResourceList{GroupVersion: "workload.codeflare.dev/v1beta2", APIResources: []metav1.APIResource{{Name: "appwrappers"}}},
+		)
+		test.Expect(APIChecker(client, rayClusters, appWrappers)(nil)).To(Succeed())
+	})
+
+	test.T().Run("Expected check to fail when an API group version is not served", func(t *testing.T) {
+		client := newDiscovery(
+			&metav1.APIResourceList{GroupVersion: "ray.io/v1", APIResources: []metav1.APIResource{{Name: "rayclusters"}}},
+		)
+		test.Expect(APIChecker(client, rayClusters, appWrappers)(nil)).To(MatchError(ContainSubstring("appwrappers.workload.codeflare.dev")))
+	})
+
+	test.T().Run("Expected check to fail when a resource is not served", func(t *testing.T) {
+		client := newDiscovery(
+			&metav1.APIResourceList{GroupVersion: "ray.io/v1", APIResources: []metav1.APIResource{{Name: "rayjobs"}}},
+		)
+		test.Expect(APIChecker(client, rayClusters)(nil)).To(MatchError(ContainSubstring("rayclusters.ray.io")))
+	})
+
+	test.T().Run("Negative: Expected check to pass when the API server cannot be reached", func(t *testing.T) {
+		client := &failingDiscovery{FakeDiscovery: newDiscovery(), err: errors.New("too many requests")}
+		test.Expect(APIChecker(client, rayClusters)(nil)).To(Succeed())
+	})
+}
@@ -0,0 +1,173 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package modelregistry provides a client of the REST API of the Kubeflow model registry, that registers
+// the models trained by the workloads, so that they can be served from the registry.
+package modelregistry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const apiPath = "/api/model_registry/v1alpha3"
+
+// Model is a version of a model, whose artifact is stored at the URI, e.g., an S3 URI.
+type Model struct {
+	Name        string
+	Version     string
+	URI         string
+	Description string
+	// Source identifies the workload that trained the model, e.g., namespace/name of the RayJob
+	Source string
+}
+
+// Client registers models in a model registry.
+type Client struct {
+	URL             string
+	BearerTokenFile string
+	HTTPClient      *http.Client
+}
+
+// NewClient returns a client of the model registry at the URL.
+func NewClient(url, bearerTokenFile string) *Client {
+	return &Client{
+		URL:             strings.TrimSuffix(url, "/"),
+		BearerTokenFile: bearerTokenFile,
+		HTTPClient:      &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+var errNotFound = errors.New("not found")
+
+type registeredModel struct {
+	ID          string `json:"id,omitempty"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+type modelVersion struct {
+	ID                string         `json:"id,omitempty"`
+	Name              string         `json:"name"`
+	RegisteredModelID string         `json:"registeredModelId"`
+	Description       string         `json:"description,omitempty"`
+	CustomProperties  map[string]any `json:"customProperties,omitempty"`
+}
+
+type modelArtifact struct {
+	ID           string `json:"id,omitempty"`
+	ArtifactType string `json:"artifactType"`
+	Name         string `json:"name"`
+	URI          string `json:"uri"`
+}
+
+type modelArtifactList struct {
+	Items []modelArtifact `json:"items"`
+}
+
+// RegisterModel registers the version of the model, and its artifact, creating the registered model if it doesn't
+// exist yet, and returns the ID of the model version. It's idempotent, so that it can be retried after failures.
+func (c *Client) RegisterModel(ctx context.Context, model Model) (string, error) {
+	registered := &registeredModel{}
+	err := c.do(ctx, http.MethodGet, "/registered_model?"+url.Values{"name": {model.Name}}.Encode(), nil, registered)
+	if errors.Is(err, errNotFound) {
+		err = c.do(ctx, http.MethodPost, "/registered_models", &registeredModel{Name: model.Name}, registered)
+	}
+	if err != nil {
+		return "", fmt.Errorf("cannot register model %s: %w", model.Name, err)
+	}
+
+	version := &modelVersion{}
+	err = c.do(ctx, http.MethodGet, "/model_version?"+url.Values{"name": {model.Version}, "parentResourceId": {registered.ID}}.Encode(), nil, version)
+	if errors.Is(err, errNotFound) {
+		version = &modelVersion{Name: model.Version, RegisteredModelID: registered.ID, Description: model.Description}
+		if model.Source != "" {
+			version.CustomProperties = map[string]any{
+				"source": map[string]any{"metadataType": "MetadataStringValue", "string_value": model.Source},
+			}
+		}
+		err = c.do(ctx, http.MethodPost, "/model_versions", version, version)
+	}
+	if err != nil {
+		return "", fmt.Errorf("cannot register version %s of model %s: %w", model.Version, model.Name, err)
+	}
+
+	artifacts := &modelArtifactList{}
+	if err := c.do(ctx, http.MethodGet, "/model_versions/"+url.PathEscape(version.ID)+"/artifacts", nil, artifacts); err != nil {
+		return "", fmt.Errorf("cannot get artifacts of version %s of model %s: %w", model.Version, model.Name, err)
+	}
+	for _, artifact := range artifacts.Items {
+		if artifact.URI == model.URI {
+			return version.ID, nil
+		}
+	}
+	artifact := &modelArtifact{ArtifactType: "model-artifact", Name: model.Name, URI: model.URI}
+	if err := c.do(ctx, http.MethodPost, "/model_versions/"+url.PathEscape(version.ID)+"/artifacts", artifact, artifact); err != nil {
+		return "", fmt.Errorf("cannot register artifact of version %s of model %s: %w", model.Version, model.Name, err)
+	}
+	return version.ID, nil
+}
+
+// do sends the request, with the JSON body if any, to the path of the model registry API, and decodes the JSON
+// response into the result. It returns errNotFound when the resource doesn't exist.
+func (c *Client) do(ctx context.Context, method, path string, body, result any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.URL+apiPath+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.BearerTokenFile != "" {
+		token, err := os.ReadFile(c.BearerTokenFile)
+		if err != nil {
+			return fmt.Errorf("cannot read model registry bearer token: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return errNotFound
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("model registry %s %s returned status %d: %s", method, path, resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
@@ -0,0 +1,174 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package modelregistry
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/project-codeflare/codeflare-common/support"
+)
+
+// fakeRegistry is an in-memory model registry, that serves the subset of the REST API used by the client.
+type fakeRegistry struct {
+	mu        sync.Mutex
+	models    []registeredModel
+	versions  []modelVersion
+	artifacts map[string][]modelArtifact
+	tokens    []string
+}
+
+func (f *fakeRegistry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.tokens = append(f.tokens, r.Header.Get("Authorization"))
+
+	path := strings.TrimPrefix(r.URL.Path, apiPath)
+	query := r.URL.Query()
+	reply := func(result any) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(result)
+	}
+	switch {
+	case r.Method == http.MethodGet && path == "/registered_model":
+		for _, model := range f.models {
+			if model.Name == query.Get("name") {
+				reply(model)
+				return
+			}
+		}
+		http.NotFound(w, r)
+	case r.Method == http.MethodPost && path == "/registered_models":
+		model := registeredModel{}
+		_ = json.NewDecoder(r.Body).Decode(&model)
+		model.ID = strconv.Itoa(len(f.models) + 1)
+		f.models = append(f.models, model)
+		reply(model)
+	case r.Method == http.MethodGet && path == "/model_version":
+		for _, version := range f.versions {
+			if version.Name == query.Get("name") && version.RegisteredModelID == query.Get("parentResourceId") {
+				reply(version)
+				return
+			}
+		}
+		http.NotFound(w, r)
+	case r.Method == http.MethodPost && path == "/model_versions":
+		version := modelVersion{}
+		_ = json.NewDecoder(r.Body).Decode(&version)
+		version.ID = strconv.Itoa(len(f.versions) + 1)
+		f.versions = append(f.versions, version)
+		reply(version)
+	case strings.HasPrefix(path, "/model_versions/") && strings.HasSuffix(path, "/artifacts"):
+		id := strings.TrimSuffix(strings.TrimPrefix(path, "/model_versions/"), "/artifacts")
+		if r.Method == http.MethodGet {
+			reply(modelArtifactList{Items: f.artifacts[id]})
+			return
+		}
+		artifact := modelArtifact{}
+		_ = json.NewDecoder(r.Body).Decode(&artifact)
+		artifact.ID = id + "-" + strconv.Itoa(len(f.artifacts[id])+1)
+		f.artifacts[id] = append(f.artifacts[id], artifact)
+		reply(artifact)
+	default:
+		http.Error(w, "unexpected request", http.StatusBadRequest)
+	}
+}
+
+func TestRegisterModel(t *testing.T) {
+	test := support.NewTest(t)
+	ctx := context.Background()
+
+	newRegistry := func(t *testing.T) (*fakeRegistry, *Client) {
+		registry := &fakeRegistry{artifacts: map[string][]modelArtifact{}}
+		server := httptest.NewServer(registry)
+		t.Cleanup(server.Close)
+		return registry, NewClient(server.URL+"/", "")
+	}
+	model := Model{Name: "llama-finetuned", Version: "train-llama", URI: "s3://models/llama/train-llama", Source: "ns/train-llama"}
+
+	test.T().Run("Expected model, version and artifact to be registered", func(t *testing.T) {
+		registry, client := newRegistry(t)
+
+		id, err := client.RegisterModel(ctx, model)
+
+		test.Expect(err).NotTo(HaveOccurred())
+		test.Expect(id).To(Equal("1"))
+		test.Expect(registry.models).To(ConsistOf(HaveField("Name", "llama-finetuned")))
+		test.Expect(registry.versions).To(ConsistOf(And(
+			HaveField("Name", "train-llama"),
+			HaveField("RegisteredModelID", "1"),
+			HaveField("CustomProperties", HaveKey("source")),
+		)))
+		test.Expect(registry.artifacts["1"]).To(ConsistOf(And(
+			HaveField("ArtifactType", "model-artifact"),
+			HaveField("URI", "s3://models/llama/train-llama"),
+		)))
+	})
+
+	test.T().Run("Expected registration to be idempotent", func(t *testing.T) {
+		registry, client := newRegistry(t)
+
+		_, err := client.RegisterModel(ctx, model)
+		test.Expect(err).NotTo(HaveOccurred())
+		_, err = client.RegisterModel(ctx, model)
+		test.Expect(err).NotTo(HaveOccurred())
+
+		// A new version of the same model is added to the registered model
+		version := model
+		version.Version = "train-llama-2"
+		version.URI = "s3://models/llama/train-llama-2"
+		id, err := client.RegisterModel(ctx, version)
+		test.Expect(err).NotTo(HaveOccurred())
+		test.Expect(id).To(Equal("2"))
+
+		test.Expect(registry.models).To(HaveLen(1))
+		test.Expect(registry.versions).To(HaveLen(2))
+		test.Expect(registry.artifacts["1"]).To(HaveLen(1))
+		test.Expect(registry.artifacts["2"]).To(HaveLen(1))
+	})
+
+	test.T().Run("Expected requests to be authenticated with the bearer token", func(t *testing.T) {
+		registry, client := newRegistry(t)
+		client.BearerTokenFile = filepath.Join(t.TempDir(), "token")
+		test.Expect(os.WriteFile(client.BearerTokenFile, []byte("secret-token\n"), 0o600)).To(Succeed())
+
+		_, err := client.RegisterModel(ctx, model)
+
+		test.Expect(err).NotTo(HaveOccurred())
+		test.Expect(registry.tokens).To(HaveEach("Bearer secret-token"))
+	})
+
+	test.T().Run("Negative: Expected error when the model registry fails", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			http.Error(w, "database unavailable", http.StatusServiceUnavailable)
+		}))
+		t.Cleanup(server.Close)
+
+		_, err := NewClient(server.URL, "").RegisterModel(ctx, model)
+
+		test.Expect(err).To(MatchError(ContainSubstring("returned status 503: database unavailable")))
+	})
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// The apiserver-proxy command is a reverse proxy to the Kubernetes API server, that injects latency and
+// throttles requests with 429 responses. It runs as a sidecar of the operator during tests, so that the
+// behavior of the operator under API server pressure can be asserted.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+)
+
+var log = ctrl.Log.WithName("apiserver-proxy")
+
+type options struct {
+	latency       time.Duration
+	latencyJitter time.Duration
+	throttleRatio float64
+	retryAfter    time.Duration
+}
+
+// throttlingProxy delays the requests, and responds to a ratio of them with 429 Too Many Requests,
+// the way the API server does when its priority and fairness queues are full.
+type throttlingProxy struct {
+	options
+	proxy *httputil.ReverseProxy
+
+	proxied   atomic.Int64
+	throttled atomic.Int64
+}
+
+func (p *throttlingProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	delay := p.latency
+	if p.latencyJitter > 0 {
+		delay += time.Duration(rand.Int63n(int64(p.latencyJitter)))
+	}
+	select {
+	case <-time.After(delay):
+	case <-r.Context().Done():
+		return
+	}
+
+	if p.throttleRatio > 0 && rand.Float64() < p.throttleRatio {
+		p.throttled.Add(1)
+		writeTooManyRequests(w, p.retryAfter)
+		return
+	}
+
+	p.proxied.Add(1)
+	p.proxy.ServeHTTP(w, r)
+}
+
+func writeTooManyRequests(w http.ResponseWriter, retryAfter time.Duration) {
+	seconds := max(int32(retryAfter.Seconds()), 1)
+	status := metav1.Status{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Status"},
+		Status:   metav1.StatusFailure,
+		Message:  "Too many requests, please try again later.",
+		Reason:   metav1.StatusReasonTooManyRequests,
+		Details:  &metav1.StatusDetails{RetryAfterSeconds: seconds},
+		Code:     http.StatusTooManyRequests,
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Retry-After", strconv.Itoa(int(seconds)))
+	w.WriteHeader(http.StatusTooManyRequests)
+	_ = json.NewEncoder(w).Encode(status)
+}
+
+func main() {
+	var listenAddress string
+	var o options
+	flag.StringVar(&listenAddress, "listen-address", "127.0.0.1:8001", "The address the proxy listens to, with plain HTTP.")
+	flag.DurationVar(&o.latency, "latency", 0, "The latency added to every request.")
+	flag.DurationVar(&o.latencyJitter, "latency-jitter", 0, "The maximum random latency added to every request, on top of the fixed latency.")
+	flag.Float64Var(&o.throttleRatio, "throttle-ratio", 0, "The ratio, between 0 and 1, of the requests responded to with 429 Too Many Requests.")
+	flag.DurationVar(&o.retryAfter, "retry-after", time.Second, "The delay after which throttled requests can be retried.")
+	zapOptions := zap.Options{Development: true}
+	zapOptions.BindFlags(flag.CommandLine)
+	flag.Parse()
+	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&zapOptions)))
+
+	if o.throttleRatio < 0 || o.throttleRatio > 1 {
+		exitOnError(fmt.Errorf("invalid throttle ratio %v", o.throttleRatio), "invalid flags")
+	}
+
+	// The proxy authenticates to the API server with the credentials of the pod service account,
+	// that's shared with the operator, so that it keeps its permissions
+	cfg, err := ctrl.GetConfig()
+	exitOnError(err, "unable to get API server configuration")
+	target, err := url.Parse(cfg.Host)
+	exitOnError(err, "invalid API server host")
+	transport, err := rest.TransportFor(cfg)
+	exitOnError(err, "unable to create API server transport")
+
+	proxy := httputil.NewSingleHostReverseProxy(target)
+	proxy.Transport = transport
+	// Flush immediately, so that watch events are streamed to the client
+	proxy.FlushInterval = -1
+
+	handler := &throttlingProxy{options: o, proxy: proxy}
+	go func() {
+		for range time.Tick(30 * time.Second) {
+			log.Info("Requests summary", "proxied", handler.proxied.Load(), "throttled", handler.throttled.Load())
+		}
+	}()
+
+	log.Info("Proxying API server", "host", cfg.Host, "address", listenAddress,
+		"latency", o.latency, "latencyJitter", o.latencyJitter, "throttleRatio", o.throttleRatio)
+	exitOnError(http.ListenAndServe(listenAddress, handler), "unable to serve")
+}
+
+func exitOnError(err error, msg string) {
+	if err != nil {
+		log.Error(err, msg)
+		os.Exit(1)
+	}
+}
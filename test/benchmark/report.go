@@ -0,0 +1,173 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package benchmark measures the turnaround of a canonical workload, from its submission to its cleanup,
+// across deployment shapes and operator configurations, so that the overhead of each layer of the stack
+// can be compared.
+package benchmark
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Phase is a step of the turnaround of a workload.
+type Phase string
+
+const (
+	// PhaseSubmitted is the duration of the creation request, that goes through the admission webhooks.
+	PhaseSubmitted Phase = "submitted"
+	// PhaseAdmitted is the duration from the submission to the admission by Kueue.
+	PhaseAdmitted Phase = "admitted"
+	// PhaseReady is the duration from the admission to the RayCluster being ready.
+	PhaseReady Phase = "ready"
+	// PhaseCompleted is the duration from the RayCluster being ready to the job completion.
+	PhaseCompleted Phase = "completed"
+	// PhaseCleanedUp is the duration from the deletion of the workload to the deletion of all its pods.
+	PhaseCleanedUp Phase = "cleanedUp"
+)
+
+// Phases lists the phases in the order they happen.
+var Phases = []Phase{PhaseSubmitted, PhaseAdmitted, PhaseReady, PhaseCompleted, PhaseCleanedUp}
+
+// Sample is the turnaround of a single run of the workload.
+type Sample struct {
+	Configuration string                  `json:"configuration"`
+	Workload      string                  `json:"workload"`
+	Phases        map[Phase]time.Duration `json:"phases"`
+}
+
+// Total returns the duration of the whole turnaround.
+func (s Sample) Total() time.Duration {
+	var total time.Duration
+	for _, duration := range s.Phases {
+		total += duration
+	}
+	return total
+}
+
+// Summary aggregates the samples of a workload run with a configuration.
+type Summary struct {
+	Configuration string                  `json:"configuration"`
+	Workload      string                  `json:"workload"`
+	Samples       int                     `json:"samples"`
+	Median        map[Phase]time.Duration `json:"median"`
+	P90           map[Phase]time.Duration `json:"p90"`
+	MedianTotal   time.Duration           `json:"medianTotal"`
+}
+
+// Report collects the samples of the benchmark runs.
+type Report struct {
+	Samples []Sample `json:"samples"`
+}
+
+// Add records the sample.
+func (r *Report) Add(sample Sample) {
+	r.Samples = append(r.Samples, sample)
+}
+
+// Summaries returns the summaries of the samples, grouped by configuration and workload, in the order they were run.
+func (r *Report) Summaries() []Summary {
+	type key struct{ configuration, workload string }
+	var keys []key
+	samples := map[key][]Sample{}
+	for _, sample := range r.Samples {
+		k := key{sample.Configuration, sample.Workload}
+		if _, ok := samples[k]; !ok {
+			keys = append(keys, k)
+		}
+		samples[k] = append(samples[k], sample)
+	}
+
+	var summaries []Summary
+	for _, k := range keys {
+		summary := Summary{
+			Configuration: k.configuration,
+			Workload:      k.workload,
+			Samples:       len(samples[k]),
+			Median:        map[Phase]time.Duration{},
+			P90:           map[Phase]time.Duration{},
+		}
+		for _, phase := range Phases {
+			var durations []time.Duration
+			for _, sample := range samples[k] {
+				durations = append(durations, sample.Phases[phase])
+			}
+			summary.Median[phase] = percentile(durations, 50)
+			summary.P90[phase] = percentile(durations, 90)
+		}
+		var totals []time.Duration
+		for _, sample := range samples[k] {
+			totals = append(totals, sample.Total())
+		}
+		summary.MedianTotal = percentile(totals, 50)
+		summaries = append(summaries, summary)
+	}
+	return summaries
+}
+
+// Markdown renders the summaries as a table, with the median duration of each phase.
+func (r *Report) Markdown() string {
+	var b strings.Builder
+	b.WriteString("| Configuration | Workload | Samples |")
+	for _, phase := range Phases {
+		fmt.Fprintf(&b, " %s |", phase)
+	}
+	b.WriteString(" total |\n|---|---|---|")
+	for range Phases {
+		b.WriteString("---|")
+	}
+	b.WriteString("---|\n")
+	for _, summary := range r.Summaries() {
+		fmt.Fprintf(&b, "| %s | %s | %d |", summary.Configuration, summary.Workload, summary.Samples)
+		for _, phase := range Phases {
+			fmt.Fprintf(&b, " %s |", summary.Median[phase].Round(100*time.Millisecond))
+		}
+		fmt.Fprintf(&b, " %s |\n", summary.MedianTotal.Round(100*time.Millisecond))
+	}
+	return b.String()
+}
+
+// Write writes the samples and their summaries as JSON, and the Markdown table, into the directory.
+func (r *Report) Write(dir string) error {
+	data, err := json.MarshalIndent(struct {
+		Samples   []Sample  `json:"samples"`
+		Summaries []Summary `json:"summaries"`
+	}{r.Samples, r.Summaries()}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "turnaround.json"), data, 0o644); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, "turnaround.md"), []byte(r.Markdown()), 0o644)
+}
+
+// percentile returns the nearest-rank percentile of the durations.
+func percentile(durations []time.Duration, p int) time.Duration {
+	if len(durations) == 0 {
+		return 0
+	}
+	sorted := slices.Clone(durations)
+	slices.Sort(sorted)
+	rank := (p*len(sorted) + 99) / 100
+	return sorted[max(rank, 1)-1]
+}
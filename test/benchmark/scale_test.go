@@ -0,0 +1,265 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package benchmark
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	. "github.com/project-codeflare/codeflare-common/support"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/utils/ptr"
+	kueuev1beta1 "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+
+	. "github.com/project-codeflare/codeflare-operator/test/support"
+)
+
+const (
+	defaultScaleRayClusters = 100
+	defaultScaleNamespaces  = 10
+	// scaleConcurrency bounds the number of RayClusters being created at once
+	scaleConcurrency = 10
+	// scaleRunLabel labels the RayClusters of a run of the scale test, so that they're listed at once
+	scaleRunLabel = "codeflare.dev/scale-run"
+
+	operatorMemoryMetric        = "process_resident_memory_bytes"
+	webhookLatencyMetric        = "controller_runtime_webhook_latency_seconds"
+	rayClusterMutatingWebhook   = "/mutate-ray-io-v1-raycluster"
+	rayClusterValidatingWebhook = "/validate-ray-io-v1-raycluster"
+)
+
+// scaleThresholds are the maximum values of the measurements, past which the scale test fails.
+type scaleThresholds struct {
+	AdmissionP99   time.Duration     `json:"admissionP99"`
+	WebhookP99     time.Duration     `json:"webhookP99"`
+	OperatorMemory resource.Quantity `json:"operatorMemory"`
+}
+
+var defaultScaleThresholds = scaleThresholds{
+	AdmissionP99:   30 * time.Second,
+	WebhookP99:     time.Second,
+	OperatorMemory: resource.MustParse("512Mi"),
+}
+
+// ScaleReport is the outcome of a run of the scale test.
+type ScaleReport struct {
+	RayClusters    int                      `json:"rayClusters"`
+	Namespaces     int                      `json:"namespaces"`
+	AdmissionP50   time.Duration            `json:"admissionP50"`
+	AdmissionP99   time.Duration            `json:"admissionP99"`
+	WebhookP99     map[string]time.Duration `json:"webhookP99"`
+	OperatorMemory resource.Quantity        `json:"operatorMemory"`
+	Thresholds     scaleThresholds          `json:"thresholds"`
+}
+
+// Creates hundreds of small RayClusters across namespaces, and asserts the latency of their admission by Kueue,
+// the latency of the RayCluster webhooks of the operator, and the peak memory usage of the operator, are below
+// thresholds, to catch the regressions that only show at scale.
+// The number of RayClusters and namespaces, and the thresholds, can be set with the CODEFLARE_BENCHMARK_SCALE_*
+// environment variables. The head pods are kept pending, with a node selector matching no node, so that the scale
+// is bounded by the control plane, rather than by the capacity of the nodes.
+func TestScale(t *testing.T) {
+	test := With(t)
+	Tags(test, Scale)
+
+	rayClusters := getScaleInt(test, "CODEFLARE_BENCHMARK_SCALE_RAYCLUSTERS", defaultScaleRayClusters)
+	namespaceCount := getScaleInt(test, "CODEFLARE_BENCHMARK_SCALE_NAMESPACES", defaultScaleNamespaces)
+	thresholds := getScaleThresholds(test)
+
+	// A dedicated ClusterQueue, with enough quota to admit all the RayClusters at once
+	resourceFlavor := SharedKueueResourceFlavor(test)
+	clusterQueue := CreateTestKueueClusterQueue(test, kueuev1beta1.ClusterQueueSpec{
+		NamespaceSelector: &metav1.LabelSelector{},
+		ResourceGroups: []kueuev1beta1.ResourceGroup{
+			{
+				CoveredResources: []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory},
+				Flavors: []kueuev1beta1.FlavorQuotas{
+					{
+						Name: kueuev1beta1.ResourceFlavorReference(resourceFlavor.Name),
+						Resources: []kueuev1beta1.ResourceQuota{
+							{Name: corev1.ResourceCPU, NominalQuota: resource.MustParse("1000")},
+							{Name: corev1.ResourceMemory, NominalQuota: resource.MustParse("1000Gi")},
+						},
+					},
+				},
+			},
+		},
+	})
+
+	// The namespaces aren't bounded by the pool of the other tests, as the scale test has the cluster to itself
+	pool := NewNamespacePool(0)
+	var namespaces []*corev1.Namespace
+	localQueues := map[string]string{}
+	for i := 0; i < namespaceCount; i++ {
+		namespace := pool.Lease(test)
+		CollectDiagnosticsOnFailure(test, namespace.Name)
+		localQueues[namespace.Name] = CreateKueueLocalQueue(test, namespace.Name, clusterQueue.Name).Name
+		namespaces = append(namespaces, namespace)
+	}
+
+	operatorMetrics := OperatorMetrics(test)
+	before := operatorMetrics(test)
+	peakMemory := before.Value(operatorMemoryMetric, nil)
+
+	run := rand.String(5)
+	created := map[string]time.Time{}
+	var mutex sync.Mutex
+	var errs []error
+	var wg sync.WaitGroup
+	indices := make(chan int)
+	for w := 0; w < scaleConcurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// Gomega assertions can't fail the test from other goroutines than the test one
+			for i := range indices {
+				namespace := namespaces[i%len(namespaces)].Name
+				rayCluster := newScaleRayCluster(namespace, fmt.Sprintf("scale-%d", i), localQueues[namespace], run)
+				_, err := test.Client().Ray().RayV1().RayClusters(namespace).Create(test.Ctx(), rayCluster, metav1.CreateOptions{})
+				mutex.Lock()
+				if err != nil {
+					errs = append(errs, err)
+				} else {
+					created[namespace+"/"+rayCluster.Name] = time.Now()
+				}
+				mutex.Unlock()
+			}
+		}()
+	}
+	for i := 0; i < rayClusters; i++ {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+	test.Expect(errs).To(BeEmpty())
+	test.T().Logf("Created %d RayClusters across %d namespaces", rayClusters, namespaceCount)
+
+	// The admission is the unsuspension of the RayClusters by Kueue
+	admitted := map[string]time.Duration{}
+	test.Eventually(func(g Gomega) int {
+		list, err := test.Client().Ray().RayV1().RayClusters(metav1.NamespaceAll).List(test.Ctx(), metav1.ListOptions{
+			LabelSelector: scaleRunLabel + "=" + run,
+		})
+		g.Expect(err).NotTo(HaveOccurred())
+		for _, rayCluster := range list.Items {
+			key := rayCluster.Namespace + "/" + rayCluster.Name
+			if _, ok := admitted[key]; !ok && !ptr.Deref(rayCluster.Spec.Suspend, false) {
+				admitted[key] = time.Since(created[key])
+			}
+		}
+		return len(admitted)
+	}, TestTimeoutLong).WithPolling(pollingInterval).Should(Equal(rayClusters))
+
+	// The operator reconciles the RayClusters once they're admitted, so its memory usage is sampled until it has settled
+	test.Consistently(func(g Gomega) {
+		peakMemory = max(peakMemory, operatorMetrics(g).Value(operatorMemoryMetric, nil))
+	}, TestTimeoutShort).WithPolling(time.Second).Should(Succeed())
+	after := operatorMetrics(test)
+
+	var admissions []time.Duration
+	for _, duration := range admitted {
+		admissions = append(admissions, duration)
+	}
+	report := ScaleReport{
+		RayClusters:    rayClusters,
+		Namespaces:     namespaceCount,
+		AdmissionP50:   percentile(admissions, 50),
+		AdmissionP99:   percentile(admissions, 99),
+		WebhookP99:     map[string]time.Duration{},
+		OperatorMemory: *resource.NewQuantity(int64(peakMemory), resource.BinarySI),
+		Thresholds:     thresholds,
+	}
+	for _, webhook := range []string{rayClusterMutatingWebhook, rayClusterValidatingWebhook} {
+		labels := map[string]string{"webhook": webhook}
+		histogram := after.Histogram(webhookLatencyMetric, labels).Sub(before.Histogram(webhookLatencyMetric, labels))
+		test.Expect(histogram.Count).To(BeNumerically(">", 0), "no latency observed for webhook %s", webhook)
+		report.WebhookP99[webhook] = time.Duration(histogram.Quantile(0.99) * float64(time.Second))
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	test.Expect(err).NotTo(HaveOccurred())
+	test.Expect(os.WriteFile(filepath.Join(test.OutputDir(), "scale.json"), data, 0o644)).To(Succeed())
+	test.T().Logf("Scale report:\n%s", data)
+
+	test.Expect(report.AdmissionP99).To(BeNumerically("<=", thresholds.AdmissionP99), "admission p99 exceeds the threshold")
+	for webhook, p99 := range report.WebhookP99 {
+		test.Expect(p99).To(BeNumerically("<=", thresholds.WebhookP99), "p99 of webhook %s exceeds the threshold", webhook)
+	}
+	test.Expect(report.OperatorMemory.Cmp(thresholds.OperatorMemory)).To(BeNumerically("<=", 0),
+		"operator memory %s exceeds the threshold %s", report.OperatorMemory.String(), thresholds.OperatorMemory.String())
+}
+
+// newScaleRayCluster returns a RayCluster with a small head and no worker, whose pod is never scheduled.
+func newScaleRayCluster(namespace, name, localQueue, run string) *rayv1.RayCluster {
+	rayCluster := NewRayClusterBuilder(namespace, name).
+		WithLabels(map[string]string{
+			"kueue.x-k8s.io/queue-name": localQueue,
+			scaleRunLabel:               run,
+		}).
+		WithHeadResources(corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("10m"),
+				corev1.ResourceMemory: resource.MustParse("64Mi"),
+			},
+		}).
+		Build()
+	rayCluster.Spec.HeadGroupSpec.Template.Spec.NodeSelector = map[string]string{scaleRunLabel: "unschedulable"}
+	return rayCluster
+}
+
+func getScaleInt(test Test, name string, defaultValue int) int {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return defaultValue
+	}
+	parsed, err := strconv.Atoi(value)
+	test.Expect(err).NotTo(HaveOccurred())
+	test.Expect(parsed).To(BeNumerically(">", 0))
+	return parsed
+}
+
+func getScaleThresholds(test Test) scaleThresholds {
+	thresholds := defaultScaleThresholds
+	if value, ok := os.LookupEnv("CODEFLARE_BENCHMARK_SCALE_MAX_ADMISSION_P99"); ok {
+		duration, err := time.ParseDuration(value)
+		test.Expect(err).NotTo(HaveOccurred())
+		thresholds.AdmissionP99 = duration
+	}
+	if value, ok := os.LookupEnv("CODEFLARE_BENCHMARK_SCALE_MAX_WEBHOOK_P99"); ok {
+		duration, err := time.ParseDuration(value)
+		test.Expect(err).NotTo(HaveOccurred())
+		thresholds.WebhookP99 = duration
+	}
+	if value, ok := os.LookupEnv("CODEFLARE_BENCHMARK_SCALE_MAX_OPERATOR_MEMORY"); ok {
+		quantity, err := resource.ParseQuantity(value)
+		test.Expect(err).NotTo(HaveOccurred())
+		thresholds.OperatorMemory = quantity
+	}
+	return thresholds
+}
@@ -0,0 +1,207 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package benchmark
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	. "github.com/project-codeflare/codeflare-common/support"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	. "github.com/project-codeflare/codeflare-operator/test/support"
+)
+
+const (
+	defaultSoakDuration = 4 * time.Hour
+	// soakWarmupIterations are the first iterations, after which the memory of the operator is expected to be stable
+	soakWarmupIterations = 3
+)
+
+var defaultSoakMaxMemoryGrowth = resource.MustParse("128Mi")
+
+// soakResources are the namespaced resources the operator creates for the RayClusters, that must be deleted along
+// with them. The Routes are only tracked on OpenShift.
+var soakResources = []schema.GroupVersionResource{
+	corev1.SchemeGroupVersion.WithResource("services"),
+	corev1.SchemeGroupVersion.WithResource("secrets"),
+	corev1.SchemeGroupVersion.WithResource("configmaps"),
+	corev1.SchemeGroupVersion.WithResource("serviceaccounts"),
+	networkingv1.SchemeGroupVersion.WithResource("networkpolicies"),
+	networkingv1.SchemeGroupVersion.WithResource("ingresses"),
+}
+
+var routesResource = schema.GroupVersionResource{Group: "route.openshift.io", Version: "v1", Resource: "routes"}
+
+// SoakSample is the outcome of an iteration of the soak test.
+type SoakSample struct {
+	Iteration      int                     `json:"iteration"`
+	Workload       string                  `json:"workload"`
+	Phases         map[Phase]time.Duration `json:"phases"`
+	OperatorMemory resource.Quantity       `json:"operatorMemory"`
+	// Leftovers are the resources remaining, per kind, once the workload has been deleted, compared to the baseline
+	Leftovers map[string]int `json:"leftovers,omitempty"`
+}
+
+// Continuously submits and deletes RayJobs, managed by Kueue directly or wrapped in AppWrappers, for several hours,
+// and asserts the resources the operator creates for them, like the Routes, Secrets, and Services, don't leak,
+// and the memory usage of the operator doesn't grow, once warmed up, past a threshold.
+// The duration, and the threshold, can be set with the CODEFLARE_BENCHMARK_SOAK_DURATION
+// and CODEFLARE_BENCHMARK_SOAK_MAX_MEMORY_GROWTH environment variables.
+func TestSoak(t *testing.T) {
+	test := With(t)
+	Tags(test, Soak)
+
+	duration := defaultSoakDuration
+	if value, ok := os.LookupEnv("CODEFLARE_BENCHMARK_SOAK_DURATION"); ok {
+		parsed, err := time.ParseDuration(value)
+		test.Expect(err).NotTo(HaveOccurred())
+		duration = parsed
+	}
+	maxMemoryGrowth := defaultSoakMaxMemoryGrowth
+	if value, ok := os.LookupEnv("CODEFLARE_BENCHMARK_SOAK_MAX_MEMORY_GROWTH"); ok {
+		parsed, err := resource.ParseQuantity(value)
+		test.Expect(err).NotTo(HaveOccurred())
+		maxMemoryGrowth = parsed
+	}
+
+	// Create a namespace and localqueue in that namespace
+	namespace := LeaseTestNamespace(test)
+	DumpEventsOnFailure(test, namespace.Name)
+	CollectDiagnosticsOnFailure(test, namespace.Name)
+	localQueue := CreateKueueLocalQueue(test, namespace.Name, "e2e-cluster-queue")
+
+	resources := soakResources
+	if IsOpenShift(test) {
+		resources = append(resources, routesResource)
+	}
+	baseline := countSoakResources(test, namespace.Name, resources)
+	restarts := GetContainerRestarts(test, GetOperatorNamespace(), operatorPodsSelector)
+	operatorMetrics := OperatorMetrics(test)
+
+	var samples []SoakSample
+	defer func() {
+		data, err := json.MarshalIndent(samples, "", "  ")
+		test.Expect(err).NotTo(HaveOccurred())
+		test.Expect(os.WriteFile(filepath.Join(test.OutputDir(), "soak.json"), data, 0o644)).To(Succeed())
+	}()
+
+	test.T().Logf("Soaking the operator for %s", duration)
+	for start, i := time.Now(), 0; time.Since(start) < duration; i++ {
+		w := workloads[i%len(workloads)]
+		sample := SoakSample{Iteration: i, Workload: w.name, Phases: w.run(test, namespace, localQueue)}
+		sample.Leftovers = awaitSoakResources(test, namespace.Name, resources, baseline)
+
+		memory := operatorMetrics(test).Value(operatorMemoryMetric, nil)
+		sample.OperatorMemory = *resource.NewQuantity(int64(memory), resource.BinarySI)
+		samples = append(samples, sample)
+		test.T().Logf("Iteration %d, workload %s, after %s: %v, operator memory %s", i, w.name,
+			time.Since(start).Round(time.Second), sample.Phases, sample.OperatorMemory.String())
+	}
+
+	// The leftovers of an iteration may only be lagging behind, whereas leaked resources accumulate across iterations
+	test.Eventually(func() map[string]int {
+		return diffCounts(countSoakResources(test, namespace.Name, resources), baseline)
+	}, TestTimeoutMedium).WithPolling(pollingInterval).Should(BeEmpty(), "resources have leaked")
+
+	test.Expect(GetContainerRestarts(test, GetOperatorNamespace(), operatorPodsSelector)).To(Equal(restarts),
+		"the operator has restarted during the soak test")
+
+	test.Expect(len(samples)).To(BeNumerically(">", soakWarmupIterations),
+		"the soak test is too short to assess the memory growth of the operator")
+	warm, last := samples[soakWarmupIterations-1].OperatorMemory, samples[len(samples)-1].OperatorMemory
+	growth := last.DeepCopy()
+	growth.Sub(warm)
+	test.T().Logf("Operator memory grew by %s, from %s after warm-up, to %s", growth.String(), warm.String(), last.String())
+	test.Expect(growth.Cmp(maxMemoryGrowth)).To(BeNumerically("<=", 0),
+		"operator memory grew by %s, more than %s", growth.String(), maxMemoryGrowth.String())
+}
+
+// awaitSoakResources waits for the resources to be back to the baseline, once the workload has been deleted, as they
+// are garbage collected in the background, and returns the leftovers, per kind, if they aren't in time.
+func awaitSoakResources(test Test, namespace string, resources []schema.GroupVersionResource, baseline map[string]int) map[string]int {
+	deadline := time.Now().Add(TestTimeoutShort)
+	for {
+		leftovers := diffCounts(countSoakResources(test, namespace, resources), baseline)
+		if len(leftovers) == 0 {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			test.T().Logf("Resources left over in namespace %s: %v", namespace, leftovers)
+			return leftovers
+		}
+		time.Sleep(pollingInterval)
+	}
+}
+
+// countSoakResources returns the number of resources of each kind in the namespace, as well as the number of
+// OAuth ClusterRoleBindings the operator created for the RayClusters of the namespace.
+func countSoakResources(test Test, namespace string, resources []schema.GroupVersionResource) map[string]int {
+	counts := map[string]int{}
+	for _, gvr := range resources {
+		list, err := test.Client().Dynamic().Resource(gvr).Namespace(namespace).List(test.Ctx(), metav1.ListOptions{})
+		test.Expect(err).NotTo(HaveOccurred())
+		counts[gvr.Resource] = len(list.Items)
+	}
+
+	clusterRoleBindings, err := test.Client().Core().RbacV1().ClusterRoleBindings().List(test.Ctx(), metav1.ListOptions{
+		LabelSelector: "ray.io/cluster-name",
+	})
+	test.Expect(err).NotTo(HaveOccurred())
+	counts["clusterrolebindings"] = 0
+	for _, clusterRoleBinding := range clusterRoleBindings.Items {
+		if clusterRoleBindingOfNamespace(clusterRoleBinding, namespace) {
+			counts["clusterrolebindings"]++
+		}
+	}
+
+	return counts
+}
+
+func clusterRoleBindingOfNamespace(clusterRoleBinding rbacv1.ClusterRoleBinding, namespace string) bool {
+	if !strings.HasSuffix(clusterRoleBinding.Name, "-"+namespace+"-auth") {
+		return false
+	}
+	for _, subject := range clusterRoleBinding.Subjects {
+		if subject.Namespace == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// diffCounts returns the kinds whose count differs from the baseline, and by how much.
+func diffCounts(counts, baseline map[string]int) map[string]int {
+	diff := map[string]int{}
+	for kind, count := range counts {
+		if delta := count - baseline[kind]; delta != 0 {
+			diff[kind] = delta
+		}
+	}
+	return diff
+}
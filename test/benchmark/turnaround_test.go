@@ -0,0 +1,372 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package benchmark
+
+import (
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	awv1beta2 "github.com/project-codeflare/appwrapper/api/v1beta2"
+	. "github.com/project-codeflare/codeflare-common/support"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kueuev1beta1 "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	"sigs.k8s.io/yaml"
+
+	. "github.com/project-codeflare/codeflare-operator/test/support"
+)
+
+const (
+	defaultIterations     = 3
+	operatorConfigMapName = "codeflare-operator-config"
+	operatorPodsSelector  = "app.kubernetes.io/name=codeflare-operator"
+	pollingInterval       = 250 * time.Millisecond
+)
+
+// configuration is an operator configuration the workloads are benchmarked with.
+type configuration struct {
+	name string
+	// kubeRay overrides fields of the kuberay section of the operator configuration
+	kubeRay       map[string]any
+	openShiftOnly bool
+}
+
+var configurations = []configuration{
+	{name: "default"},
+	{name: "mtls-disabled", kubeRay: map[string]any{"mTLSEnabled": false}},
+	{name: "restricted-pod-security", kubeRay: map[string]any{"restrictedPodSecurityEnabled": true}},
+	{name: "ingress-exposure", kubeRay: map[string]any{"dashboardExposure": "Ingress", "rayDashboardOAuthEnabled": false}},
+	{name: "route-exposure", kubeRay: map[string]any{"dashboardExposure": "Route", "rayDashboardOAuthEnabled": true}, openShiftOnly: true},
+}
+
+// workload is a deployment shape of the canonical workload, that runs it and returns the duration of each phase.
+type workload struct {
+	name string
+	run  func(test Test, namespace *corev1.Namespace, localQueue *kueuev1beta1.LocalQueue) map[Phase]time.Duration
+}
+
+var workloads = []workload{
+	{name: "rayjob", run: runRayJob},
+	{name: "appwrapper-rayjob", run: runAppWrapperRayJob},
+}
+
+// Measures the turnaround of a RayJob, either managed by Kueue directly or wrapped in an AppWrapper,
+// for each operator configuration, and writes the report into the test output directory.
+// The number of iterations and the configurations can be set with the CODEFLARE_BENCHMARK_ITERATIONS
+// and CODEFLARE_BENCHMARK_CONFIGURATIONS environment variables.
+func TestTurnaround(t *testing.T) {
+	test := With(t)
+	Tags(test, LongRunning)
+
+	iterations := getIterations(test)
+	report := &Report{}
+	defer func() {
+		test.Expect(report.Write(test.OutputDir())).To(Succeed())
+		test.T().Logf("Turnaround report:\n%s", report.Markdown())
+	}()
+
+	for _, cfg := range selectedConfigurations() {
+		if cfg.openShiftOnly && !IsOpenShift(test) {
+			test.T().Logf("Skipping configuration %s, only supported on OpenShift", cfg.name)
+			continue
+		}
+		test.T().Run(cfg.name, func(t *testing.T) {
+			test := With(t)
+
+			configureOperator(test, cfg.kubeRay)
+
+			// Create a namespace and localqueue in that namespace
+			namespace := test.NewTestNamespace()
+			DumpEventsOnFailure(test, namespace.Name)
+			CollectDiagnosticsOnFailure(test, namespace.Name)
+			localQueue := CreateKueueLocalQueue(test, namespace.Name, "e2e-cluster-queue")
+
+			for _, w := range workloads {
+				for i := 0; i < iterations; i++ {
+					phases := w.run(test, namespace, localQueue)
+					report.Add(Sample{Configuration: cfg.name, Workload: w.name, Phases: phases})
+					test.T().Logf("Workload %s run %d/%d with configuration %s: %v", w.name, i+1, iterations, cfg.name, phases)
+				}
+			}
+		})
+	}
+}
+
+func runRayJob(test Test, namespace *corev1.Namespace, localQueue *kueuev1beta1.LocalQueue) map[Phase]time.Duration {
+	phases := map[Phase]time.Duration{}
+
+	rayJob := newRayJob(namespace.Name)
+	rayJob.Labels = map[string]string{"kueue.x-k8s.io/queue-name": localQueue.Name}
+
+	mark := time.Now()
+	rayJob, err := test.Client().Ray().RayV1().RayJobs(namespace.Name).Create(test.Ctx(), rayJob, metav1.CreateOptions{})
+	test.Expect(err).NotTo(HaveOccurred())
+	phases[PhaseSubmitted], mark = time.Since(mark), time.Now()
+
+	test.Eventually(RayJob(test, rayJob.Namespace, rayJob.Name), TestTimeoutMedium).WithPolling(pollingInterval).
+		Should(WithTransform(rayJobSuspended, BeFalse()))
+	phases[PhaseAdmitted], mark = time.Since(mark), time.Now()
+
+	awaitRayJobCompletion(test, rayJob, phases, mark)
+
+	mark = time.Now()
+	err = test.Client().Ray().RayV1().RayJobs(rayJob.Namespace).Delete(test.Ctx(), rayJob.Name, metav1.DeleteOptions{})
+	test.Expect(err).NotTo(HaveOccurred())
+	test.Eventually(func() bool {
+		_, err := test.Client().Ray().RayV1().RayJobs(rayJob.Namespace).Get(test.Ctx(), rayJob.Name, metav1.GetOptions{})
+		return errors.IsNotFound(err)
+	}, TestTimeoutMedium).WithPolling(pollingInterval).Should(BeTrue())
+	awaitNoPods(test, namespace.Name)
+	phases[PhaseCleanedUp] = time.Since(mark)
+
+	return phases
+}
+
+func runAppWrapperRayJob(test Test, namespace *corev1.Namespace, localQueue *kueuev1beta1.LocalQueue) map[Phase]time.Duration {
+	phases := map[Phase]time.Duration{}
+
+	rayJob := newRayJob(namespace.Name)
+	aw := &awv1beta2.AppWrapper{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: awv1beta2.GroupVersion.String(),
+			Kind:       "AppWrapper",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      rayJob.Name,
+			Namespace: namespace.Name,
+			Labels:    map[string]string{"kueue.x-k8s.io/queue-name": localQueue.Name},
+		},
+		Spec: awv1beta2.AppWrapperSpec{
+			Components: []awv1beta2.AppWrapperComponent{
+				{
+					Template: Raw(test, rayJob),
+				},
+			},
+		},
+	}
+	appWrappers := AppWrapperV1beta2(test).AppWrappers(namespace.Name)
+
+	mark := time.Now()
+	_, err := appWrappers.Create(test.Ctx(), aw, metav1.CreateOptions{})
+	test.Expect(err).NotTo(HaveOccurred())
+	phases[PhaseSubmitted], mark = time.Since(mark), time.Now()
+
+	test.Eventually(AppWrapper(test, namespace, aw.Name), TestTimeoutMedium).WithPolling(pollingInterval).
+		Should(HaveCondition(string(awv1beta2.QuotaReserved), metav1.ConditionTrue))
+	phases[PhaseAdmitted], mark = time.Since(mark), time.Now()
+
+	// The RayJob is created by the AppWrapper controller once the AppWrapper is admitted
+	awaitRayJobCompletion(test, rayJob, phases, mark)
+
+	mark = time.Now()
+	err = appWrappers.Delete(test.Ctx(), aw.Name, metav1.DeleteOptions{})
+	test.Expect(err).NotTo(HaveOccurred())
+	test.Eventually(AppWrappers(test, namespace), TestTimeoutMedium).WithPolling(pollingInterval).Should(BeEmpty())
+	awaitNoPods(test, namespace.Name)
+	phases[PhaseCleanedUp] = time.Since(mark)
+
+	return phases
+}
+
+// awaitRayJobCompletion records the ready and completed phases of the RayJob, starting from the mark,
+// and asserts the job succeeded.
+func awaitRayJobCompletion(test Test, rayJob *rayv1.RayJob, phases map[Phase]time.Duration, mark time.Time) {
+	test.Eventually(RayJob(test, rayJob.Namespace, rayJob.Name), TestTimeoutLong).WithPolling(pollingInterval).
+		Should(Or(
+			WithTransform(rayJobClusterState, Equal(rayv1.Ready)),
+			WithTransform(RayJobStatus, Satisfy(rayv1.IsJobTerminal)),
+		))
+	phases[PhaseReady], mark = time.Since(mark), time.Now()
+
+	test.Eventually(RayJob(test, rayJob.Namespace, rayJob.Name), TestTimeoutLong).WithPolling(pollingInterval).
+		Should(WithTransform(RayJobStatus, Satisfy(rayv1.IsJobTerminal)))
+	phases[PhaseCompleted] = time.Since(mark)
+
+	test.Expect(GetRayJob(test, rayJob.Namespace, rayJob.Name)).
+		To(WithTransform(RayJobStatus, Equal(rayv1.JobStatusSucceeded)))
+}
+
+func awaitNoPods(test Test, namespace string) {
+	test.Eventually(func(g Gomega) []corev1.Pod {
+		pods, err := test.Client().Core().CoreV1().Pods(namespace).List(test.Ctx(), metav1.ListOptions{})
+		g.Expect(err).NotTo(HaveOccurred())
+		return pods.Items
+	}, TestTimeoutMedium).WithPolling(pollingInterval).Should(BeEmpty())
+}
+
+// newRayJob returns the canonical workload, a RayJob running a trivial Ray program on a small RayCluster,
+// so that the turnaround is dominated by the stack rather than by the job itself.
+func newRayJob(namespace string) *rayv1.RayJob {
+	rayCluster := NewRayClusterBuilder(namespace, "turnaround").
+		WithWorkerGroup("small-group", 1, corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("250m"),
+				corev1.ResourceMemory: resource.MustParse("256Mi"),
+			},
+			Limits: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("1"),
+				corev1.ResourceMemory: resource.MustParse("1G"),
+			},
+		}, nil).
+		Build()
+
+	return &rayv1.RayJob{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: rayv1.GroupVersion.String(),
+			Kind:       "RayJob",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "turnaround",
+			Namespace: namespace,
+		},
+		Spec: rayv1.RayJobSpec{
+			Entrypoint:     `python -c "import ray; ray.init(); print(ray.cluster_resources())"`,
+			RayClusterSpec: &rayCluster.Spec,
+			// Keep the RayCluster until the RayJob is deleted, so that its teardown is part of the cleanup phase
+			ShutdownAfterJobFinishes: false,
+			SubmitterPodTemplate: &corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyNever,
+					Containers: []corev1.Container{
+						{
+							Image: GetRayImage(),
+							Name:  "rayjob-submitter-pod",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func rayJobSuspended(rayJob *rayv1.RayJob) bool {
+	return rayJob.Spec.Suspend
+}
+
+func rayJobClusterState(rayJob *rayv1.RayJob) rayv1.ClusterState {
+	return rayJob.Status.RayClusterStatus.State
+}
+
+// configureOperator overrides fields of the kuberay section of the operator configuration, restarts the operator
+// so that they are taken into account, and restores the original configuration when the test completes.
+func configureOperator(test Test, kubeRay map[string]any) {
+	test.T().Helper()
+	if len(kubeRay) == 0 {
+		return
+	}
+
+	configMaps := test.Client().Core().CoreV1().ConfigMaps(GetOperatorNamespace())
+	configMap, err := configMaps.Get(test.Ctx(), operatorConfigMapName, metav1.GetOptions{})
+	test.Expect(err).NotTo(HaveOccurred())
+	original := configMap.Data["config.yaml"]
+
+	// Unmarshal into a generic map, so that the other fields are preserved as is
+	cfg := map[string]any{}
+	test.Expect(yaml.Unmarshal([]byte(original), &cfg)).To(Succeed())
+	section, _ := cfg["kuberay"].(map[string]any)
+	if section == nil {
+		section = map[string]any{}
+	}
+	for field, value := range kubeRay {
+		section[field] = value
+	}
+	cfg["kuberay"] = section
+	data, err := yaml.Marshal(cfg)
+	test.Expect(err).NotTo(HaveOccurred())
+
+	updateOperatorConfig(test, string(data))
+	test.T().Cleanup(func() {
+		updateOperatorConfig(test, original)
+	})
+}
+
+func updateOperatorConfig(test Test, data string) {
+	test.T().Helper()
+
+	configMaps := test.Client().Core().CoreV1().ConfigMaps(GetOperatorNamespace())
+	RetryOnConflictOrTransient(test, func() error {
+		configMap, err := configMaps.Get(test.Ctx(), operatorConfigMapName, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		configMap.Data["config.yaml"] = data
+		_, err = configMaps.Update(test.Ctx(), configMap, metav1.UpdateOptions{})
+		return err
+	})
+
+	// The configuration is only loaded on start
+	restart := time.Now().Truncate(time.Second)
+	err := test.Client().Core().CoreV1().Pods(GetOperatorNamespace()).DeleteCollection(test.Ctx(), metav1.DeleteOptions{},
+		metav1.ListOptions{LabelSelector: operatorPodsSelector})
+	test.Expect(err).NotTo(HaveOccurred())
+
+	test.Eventually(func(g Gomega) bool {
+		pods, err := test.Client().Core().CoreV1().Pods(GetOperatorNamespace()).List(test.Ctx(), metav1.ListOptions{LabelSelector: operatorPodsSelector})
+		g.Expect(err).NotTo(HaveOccurred())
+		for _, pod := range pods.Items {
+			if pod.CreationTimestamp.Time.Before(restart) || !podReady(&pod) {
+				return false
+			}
+		}
+		return len(pods.Items) > 0
+	}, TestTimeoutMedium).Should(BeTrue())
+	test.T().Logf("Restarted operator in namespace %s", GetOperatorNamespace())
+}
+
+func podReady(pod *corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func getIterations(test Test) int {
+	value, ok := os.LookupEnv("CODEFLARE_BENCHMARK_ITERATIONS")
+	if !ok {
+		return defaultIterations
+	}
+	iterations, err := strconv.Atoi(value)
+	test.Expect(err).NotTo(HaveOccurred())
+	test.Expect(iterations).To(BeNumerically(">", 0))
+	return iterations
+}
+
+func selectedConfigurations() []configuration {
+	value, ok := os.LookupEnv("CODEFLARE_BENCHMARK_CONFIGURATIONS")
+	if !ok {
+		return configurations
+	}
+	names := strings.Split(value, ",")
+	var selected []configuration
+	for _, cfg := range configurations {
+		if slices.Contains(names, cfg.name) {
+			selected = append(selected, cfg)
+		}
+	}
+	return selected
+}
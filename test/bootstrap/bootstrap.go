@@ -0,0 +1,210 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package bootstrap provisions a KinD cluster, with the CodeFlare stack and the operator built from the local sources,
+// for the test suites to run against, so that they can be run with go test from a clean workstation. It drives the
+// same scripts and make targets as the CI, with the kind, kubectl, make, and container tool CLIs, that must be installed.
+package bootstrap
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const (
+	// EnvVar is the environment variable that enables the bootstrap of the cluster, when set to kind.
+	EnvVar = "CODEFLARE_TEST_BOOTSTRAP"
+	// KeepClusterEnvVar is the environment variable that keeps the cluster once the tests complete, when set to true,
+	// so that it can be investigated, or reused by the next run.
+	KeepClusterEnvVar = "CODEFLARE_TEST_BOOTSTRAP_KEEP_CLUSTER"
+
+	defaultClusterName   = "codeflare-e2e"
+	defaultImage         = "localhost/codeflare-operator:e2e"
+	defaultContainerTool = "podman"
+	operatorNamespace    = "openshift-operators"
+	operatorDeployment   = "codeflare-operator-manager"
+)
+
+// Options are the options of the bootstrap, that default to the KIND_CLUSTER_NAME, IMG, and CONTAINER_TOOL environment variables.
+type Options struct {
+	// ClusterName is the name of the KinD cluster, that's reused if it already exists
+	ClusterName string
+	// Image is the image the operator is built into, and loaded into the KinD cluster from
+	Image string
+	// ContainerTool is the CLI the operator image is built with, i.e., podman or docker
+	ContainerTool string
+	// KeepCluster keeps the cluster once the tests complete
+	KeepCluster bool
+}
+
+// DefaultOptions returns the options set with the environment variables, or their default values.
+func DefaultOptions() Options {
+	return Options{
+		ClusterName:   getEnv("KIND_CLUSTER_NAME", defaultClusterName),
+		Image:         getEnv("IMG", defaultImage),
+		ContainerTool: getEnv("CONTAINER_TOOL", defaultContainerTool),
+		KeepCluster:   os.Getenv(KeepClusterEnvVar) == "true",
+	}
+}
+
+// Main bootstraps the cluster, if enabled with the CODEFLARE_TEST_BOOTSTRAP environment variable, runs the tests,
+// and tears the cluster down, unless it's kept. It's meant to be called from the TestMain function of the suites,
+// with their testing.M.
+func Main(m interface{ Run() int }) {
+	if os.Getenv(EnvVar) != "kind" {
+		os.Exit(m.Run())
+	}
+
+	options := DefaultOptions()
+	if err := Setup(options); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to bootstrap KinD cluster %s: %v\n", options.ClusterName, err)
+		os.Exit(1)
+	}
+
+	code := m.Run()
+
+	if !options.KeepCluster {
+		if err := Teardown(options); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to delete KinD cluster %s: %v\n", options.ClusterName, err)
+		}
+	}
+	os.Exit(code)
+}
+
+// Setup creates the KinD cluster, unless it already exists, deploys the KubeRay, Kubeflow Training, and Kueue
+// operators, and deploys the CodeFlare operator, that embeds the AppWrapper controller, from the local sources.
+func Setup(options Options) error {
+	root, err := repositoryRoot()
+	if err != nil {
+		return err
+	}
+	environment := append(os.Environ(), "KIND_CLUSTER_NAME="+options.ClusterName)
+
+	clusters, err := output(root, environment, "kind", "get", "clusters")
+	if err != nil {
+		return err
+	}
+	if slices.Contains(strings.Fields(clusters), options.ClusterName) {
+		logf("Reusing KinD cluster %s", options.ClusterName)
+		if err := run(root, environment, "kind", "export", "kubeconfig", "--name", options.ClusterName); err != nil {
+			return err
+		}
+	} else {
+		logf("Creating KinD cluster %s", options.ClusterName)
+		if err := run(root, environment, "test/e2e/kind.sh"); err != nil {
+			return err
+		}
+	}
+
+	logf("Deploying the CodeFlare stack")
+	if err := run(root, environment, "make", "setup-e2e"); err != nil {
+		return err
+	}
+
+	logf("Building the CodeFlare operator image %s", options.Image)
+	if err := run(root, environment, options.ContainerTool, "build", "-t", options.Image, "."); err != nil {
+		return err
+	}
+	archive, err := os.CreateTemp("", "codeflare-operator-*.tar")
+	if err != nil {
+		return err
+	}
+	archive.Close()
+	defer os.Remove(archive.Name())
+	// Loading an archive works with both podman and docker
+	if err := run(root, environment, options.ContainerTool, "save", "-o", archive.Name(), options.Image); err != nil {
+		return err
+	}
+	if err := run(root, environment, "kind", "load", "image-archive", archive.Name(), "--name", options.ClusterName); err != nil {
+		return err
+	}
+
+	logf("Deploying the CodeFlare operator")
+	if err := run(root, environment, "make", "deploy", "-e", "IMG="+options.Image, "-e", "ENV=e2e"); err != nil {
+		return err
+	}
+	// The image is only available from the nodes of the KinD cluster
+	if err := run(root, environment, "kubectl", "patch", "deployment", "-n", operatorNamespace, operatorDeployment, "--type=json",
+		`--patch=[{"op":"replace","path":"/spec/template/spec/containers/0/imagePullPolicy","value":"IfNotPresent"}]`); err != nil {
+		return err
+	}
+	return run(root, environment, "kubectl", "wait", "--timeout=300s", "--for=condition=Available=true",
+		"deployment", "-n", operatorNamespace, operatorDeployment)
+}
+
+// Teardown deletes the KinD cluster.
+func Teardown(options Options) error {
+	logf("Deleting KinD cluster %s", options.ClusterName)
+	return run("", os.Environ(), "kind", "delete", "cluster", "--name", options.ClusterName)
+}
+
+// repositoryRoot returns the root directory of the repository, where the scripts and the Makefile are,
+// from the working directory of the tests, i.e., the directory of the suite package.
+func repositoryRoot() (string, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return dir, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("no go.mod found in the parent directories of the working directory")
+		}
+		dir = parent
+	}
+}
+
+func run(dir string, environment []string, name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	cmd.Dir = dir
+	cmd.Env = environment
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s %s: %w", name, strings.Join(args, " "), err)
+	}
+	return nil
+}
+
+func output(dir string, environment []string, name string, args ...string) (string, error) {
+	cmd := exec.Command(name, args...)
+	cmd.Dir = dir
+	cmd.Env = environment
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("%s %s: %w", name, strings.Join(args, " "), err)
+	}
+	return string(out), nil
+}
+
+func getEnv(key, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok && value != "" {
+		return value
+	}
+	return fallback
+}
+
+func logf(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+}
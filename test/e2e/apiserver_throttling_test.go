@@ -0,0 +1,98 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	. "github.com/project-codeflare/codeflare-common/support"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	. "github.com/project-codeflare/codeflare-operator/test/support"
+)
+
+const throttledRayClustersCount = 3
+
+// Creates RayClusters while the operator reaches the API server through the proxy injecting latency and 429s,
+// and asserts the admission stays within the webhook timeout, the RayClusters are eventually reconciled,
+// and the operator doesn't restart.
+func TestRayClusterUnderAPIServerThrottling(t *testing.T) {
+	test := With(t)
+	if !IsAPIServerThrottlingEnabled() {
+		test.T().Skipf("Skipping test as %s is not set", APIServerThrottlingEnvVar)
+	}
+
+	operatorPods := "app.kubernetes.io/name=codeflare-operator"
+	restarts := GetContainerRestarts(test, GetOperatorNamespace(), operatorPods)
+	webhookTimeout := GetWebhookTimeout(test, "mraycluster.ray.openshift.ai")
+
+	// Create a namespace and localqueue in that namespace
+	namespace := test.NewTestNamespace()
+	DumpEventsOnFailure(test, namespace.Name)
+	CollectDiagnosticsOnFailure(test, namespace.Name)
+	localQueue := CreateKueueLocalQueue(test, namespace.Name, "e2e-cluster-queue")
+
+	var rayClusters []*rayv1.RayCluster
+	for i := 0; i < throttledRayClustersCount; i++ {
+		rayCluster := NewRayClusterBuilder(namespace.Name, fmt.Sprintf("raycluster-throttled-%d", i)).
+			WithLabels(map[string]string{"kueue.x-k8s.io/queue-name": localQueue.Name}).
+			WithWorkerGroup("small-group", 1, corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("100m"),
+					corev1.ResourceMemory: resource.MustParse("256Mi"),
+				},
+				Limits: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("1"),
+					corev1.ResourceMemory: resource.MustParse("1G"),
+				},
+			}, nil).
+			Build()
+
+		start := time.Now()
+		rayCluster, err := test.Client().Ray().RayV1().RayClusters(namespace.Name).Create(test.Ctx(), rayCluster, metav1.CreateOptions{})
+		latency := time.Since(start)
+		test.Expect(err).NotTo(HaveOccurred())
+		test.T().Logf("Created RayCluster %s/%s in %s", rayCluster.Namespace, rayCluster.Name, latency)
+		test.Expect(latency).To(BeNumerically("<", webhookTimeout),
+			"RayCluster admission took longer than the webhook timeout")
+
+		rayClusters = append(rayClusters, rayCluster)
+	}
+
+	for _, rayCluster := range rayClusters {
+		test.T().Logf("Waiting for RayCluster %s/%s to be running", rayCluster.Namespace, rayCluster.Name)
+		test.Eventually(RayCluster(test, rayCluster.Namespace, rayCluster.Name), TestTimeoutLong).
+			Should(WithTransform(RayClusterState, Equal(rayv1.Ready)))
+
+		// The NetworkPolicies are the last objects created by the operator when reconciling the RayCluster
+		test.Eventually(func() error {
+			_, err := test.Client().Core().NetworkingV1().NetworkPolicies(rayCluster.Namespace).
+				Get(test.Ctx(), rayCluster.Name+"-workers", metav1.GetOptions{})
+			return err
+		}, TestTimeoutMedium).Should(Succeed())
+	}
+
+	test.Expect(GetContainerRestarts(test, GetOperatorNamespace(), operatorPods)).To(Equal(restarts),
+		"The operator restarted while the API server was throttled")
+}
@@ -0,0 +1,223 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	. "github.com/project-codeflare/codeflare-common/support"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	kueuev1beta1 "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+
+	. "github.com/project-codeflare/codeflare-operator/test/support"
+)
+
+// Submits a plain batch Job, with the queue label, and asserts Kueue keeps it suspended, without pods, until its
+// Workload is admitted, then that all its pods run to completion, and its quota is released.
+func TestBatchJobQueuedByKueue(t *testing.T) {
+	test := With(t)
+	Tags(test, Smoke)
+	test.T().Parallel()
+
+	// Use a dedicated ClusterQueue, that holds the admission of the workloads until it's released
+	clusterQueue := CreateTestKueueClusterQueue(test, kueuev1beta1.ClusterQueueSpec{
+		NamespaceSelector: &metav1.LabelSelector{},
+		StopPolicy:        ptr.To(kueuev1beta1.Hold),
+		ResourceGroups: []kueuev1beta1.ResourceGroup{
+			{
+				CoveredResources: []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory},
+				Flavors: []kueuev1beta1.FlavorQuotas{
+					{
+						Name: "default-flavor",
+						Resources: []kueuev1beta1.ResourceQuota{
+							{Name: corev1.ResourceCPU, NominalQuota: resource.MustParse("1")},
+							{Name: corev1.ResourceMemory, NominalQuota: resource.MustParse("1Gi")},
+						},
+					},
+				},
+			},
+		},
+	})
+
+	// Create a namespace and localqueue in that namespace
+	namespace := LeaseTestNamespace(test)
+	DumpEventsOnFailure(test, namespace.Name)
+	CollectDiagnosticsOnFailure(test, namespace.Name)
+	localQueue := CreateKueueLocalQueue(test, namespace.Name, clusterQueue.Name)
+
+	job := newBatchJob(namespace.Name, "queued", 2)
+	AssignToLocalQueue(job, localQueue)
+	job, err := test.Client().Core().BatchV1().Jobs(namespace.Name).Create(test.Ctx(), job, metav1.CreateOptions{})
+	test.Expect(err).NotTo(HaveOccurred())
+	test.T().Logf("Created Job %s/%s successfully", job.Namespace, job.Name)
+
+	// The Job is suspended on creation, and stays so while its Workload is pending
+	test.Eventually(WorkloadForJob(test, job), TestTimeoutShort).
+		Should(WithTransform(WorkloadAdmitted, BeFalse()))
+	test.T().Logf("Checking Job %s/%s is gated by Kueue", job.Namespace, job.Name)
+	test.Consistently(func(g Gomega) {
+		g.Expect(Job(test, job.Namespace, job.Name)(g)).To(WithTransform(JobSuspended, BeTrue()))
+		g.Expect(namespacePods(test, namespace.Name)(g)).To(BeEmpty())
+	}, TestTimeoutShort).Should(Succeed())
+
+	setClusterQueueStopPolicy(test, clusterQueue.Name, kueuev1beta1.None)
+
+	test.T().Logf("Waiting for Job %s/%s to be admitted", job.Namespace, job.Name)
+	test.Eventually(WorkloadForJob(test, job), TestTimeoutMedium).
+		Should(WithTransform(WorkloadAdmitted, BeTrue()))
+	test.Eventually(Job(test, job.Namespace, job.Name), TestTimeoutShort).
+		Should(WithTransform(JobSuspended, BeFalse()))
+
+	test.T().Logf("Waiting for Job %s/%s to complete", job.Namespace, job.Name)
+	test.Eventually(Job(test, job.Namespace, job.Name), TestTimeoutMedium).
+		Should(Or(
+			WithTransform(JobComplete, BeTrue()),
+			WithTransform(JobFailed, BeTrue()),
+		))
+	test.Expect(GetJob(test, job.Namespace, job.Name)).To(And(
+		WithTransform(JobComplete, BeTrue()),
+		WithTransform(func(job *batchv1.Job) int32 { return job.Status.Succeeded }, Equal(int32(2))),
+	))
+
+	test.T().Logf("Waiting for the quota of Job %s/%s to be released", job.Namespace, job.Name)
+	test.Eventually(WorkloadForJob(test, job), TestTimeoutShort).
+		Should(WithTransform(WorkloadFinished, BeTrue()))
+	test.Eventually(KueueClusterQueue(test, clusterQueue.Name), TestTimeoutShort).
+		Should(WithTransform(KueueClusterQueueUsage, And(
+			HaveFlavorUsage("default-flavor", corev1.ResourceCPU, "0"),
+			HaveFlavorUsage("default-flavor", corev1.ResourceMemory, "0"),
+		)))
+}
+
+// Submits two batch Jobs that each need the whole quota of the ClusterQueue, and asserts the second one is only
+// admitted, and runs, once the first one has completed.
+func TestBatchJobsShareQuota(t *testing.T) {
+	test := With(t)
+	test.T().Parallel()
+
+	// Use a dedicated ClusterQueue, that only fits a single Job at a time
+	clusterQueue := CreateTestKueueClusterQueue(test, kueuev1beta1.ClusterQueueSpec{
+		NamespaceSelector: &metav1.LabelSelector{},
+		ResourceGroups: []kueuev1beta1.ResourceGroup{
+			{
+				CoveredResources: []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory},
+				Flavors: []kueuev1beta1.FlavorQuotas{
+					{
+						Name: "default-flavor",
+						Resources: []kueuev1beta1.ResourceQuota{
+							{Name: corev1.ResourceCPU, NominalQuota: resource.MustParse("200m")},
+							{Name: corev1.ResourceMemory, NominalQuota: resource.MustParse("256Mi")},
+						},
+					},
+				},
+			},
+		},
+	})
+
+	// Create a namespace and localqueue in that namespace
+	namespace := LeaseTestNamespace(test)
+	DumpEventsOnFailure(test, namespace.Name)
+	CollectDiagnosticsOnFailure(test, namespace.Name)
+	localQueue := CreateKueueLocalQueue(test, namespace.Name, clusterQueue.Name)
+
+	var jobs []*batchv1.Job
+	for _, name := range []string{"first", "second"} {
+		job := newBatchJob(namespace.Name, name, 2)
+		AssignToLocalQueue(job, localQueue)
+		job, err := test.Client().Core().BatchV1().Jobs(namespace.Name).Create(test.Ctx(), job, metav1.CreateOptions{})
+		test.Expect(err).NotTo(HaveOccurred())
+		test.T().Logf("Created Job %s/%s successfully", job.Namespace, job.Name)
+		jobs = append(jobs, job)
+	}
+	first, second := jobs[0], jobs[1]
+
+	test.T().Logf("Waiting for Job %s/%s to be admitted", first.Namespace, first.Name)
+	test.Eventually(WorkloadForJob(test, first), TestTimeoutMedium).
+		Should(WithTransform(WorkloadAdmitted, BeTrue()))
+
+	// The second Job stays suspended until the first one has completed, and released the quota. The second Job is
+	// got first, so that it can't be unsuspended after the first Job is got, and before it has completed.
+	test.T().Logf("Waiting for Job %s/%s to complete", first.Namespace, first.Name)
+	test.Eventually(func(g Gomega) bool {
+		suspended := JobSuspended(Job(test, second.Namespace, second.Name)(g))
+		job := Job(test, first.Namespace, first.Name)(g)
+		if JobFailed(job) {
+			StopTrying("Job " + first.Name + " has failed").Now()
+		}
+		if !suspended && !JobComplete(job) {
+			StopTrying("Job " + second.Name + " has been unsuspended before Job " + first.Name + " has completed").Now()
+		}
+		return JobComplete(job)
+	}, TestTimeoutMedium).Should(BeTrue())
+
+	test.T().Logf("Waiting for Job %s/%s to complete", second.Namespace, second.Name)
+	test.Eventually(WorkloadForJob(test, second), TestTimeoutMedium).
+		Should(WithTransform(WorkloadAdmitted, BeTrue()))
+	test.Eventually(Job(test, second.Namespace, second.Name), TestTimeoutMedium).
+		Should(Or(
+			WithTransform(JobComplete, BeTrue()),
+			WithTransform(JobFailed, BeTrue()),
+		))
+	test.Expect(GetJob(test, second.Namespace, second.Name)).To(WithTransform(JobComplete, BeTrue()))
+}
+
+// newBatchJob returns a Job running the pods in parallel, each requesting 100m CPU and 128Mi memory.
+func newBatchJob(namespace, name string, parallelism int32) *batchv1.Job {
+	return &batchv1.Job{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: batchv1.SchemeGroupVersion.String(),
+			Kind:       "Job",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: batchv1.JobSpec{
+			Parallelism:  Ptr(parallelism),
+			Completions:  Ptr(parallelism),
+			BackoffLimit: Ptr(int32(0)),
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyNever,
+					Containers: []corev1.Container{
+						{
+							Name:    "job",
+							Image:   GetRayImage(),
+							Command: []string{"/bin/sh", "-c", "echo Hello from $(hostname) && sleep 10"},
+							Resources: corev1.ResourceRequirements{
+								Requests: corev1.ResourceList{
+									corev1.ResourceCPU:    resource.MustParse("100m"),
+									corev1.ResourceMemory: resource.MustParse("128Mi"),
+								},
+								Limits: corev1.ResourceList{
+									corev1.ResourceCPU:    resource.MustParse("100m"),
+									corev1.ResourceMemory: resource.MustParse("128Mi"),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
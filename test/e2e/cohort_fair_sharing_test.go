@@ -0,0 +1,146 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	. "github.com/project-codeflare/codeflare-common/support"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kueuev1beta1 "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+
+	. "github.com/project-codeflare/codeflare-operator/test/support"
+)
+
+// Creates two ClusterQueues in a cohort, and asserts a RayCluster from the first queue
+// borrows the idle quota of the second queue, and is preempted to give it back once a
+// RayCluster is submitted to the second queue. The borrowing RayCluster is re-admitted
+// once the second queue quota is idle again.
+func TestRayClusterCohortBorrowingAndReclaim(t *testing.T) {
+	test := With(t)
+	test.T().Parallel()
+
+	namespace := LeaseTestNamespace(test)
+	DumpEventsOnFailure(test, namespace.Name)
+	CollectDiagnosticsOnFailure(test, namespace.Name)
+	cohort := "cohort-" + namespace.Name
+
+	// The borrowing queue cannot fit the borrowing RayCluster within its nominal quota
+	borrowingQueue := createCohortClusterQueue(test, cohort, "1", kueuev1beta1.PreemptionPolicyNever)
+	// The lending queue reclaims its quota from any workload in the cohort
+	lendingQueue := createCohortClusterQueue(test, cohort, "2", kueuev1beta1.PreemptionPolicyAny)
+
+	borrowingLocalQueue := CreateKueueLocalQueue(test, namespace.Name, borrowingQueue.Name)
+	lendingLocalQueue := CreateKueueLocalQueue(test, namespace.Name, lendingQueue.Name)
+
+	// The RayClusters request 1750m CPU each, so only one fits in the cohort at a time
+	borrower := NewRayClusterBuilder(namespace.Name, "borrower").
+		WithLabels(map[string]string{"kueue.x-k8s.io/queue-name": borrowingLocalQueue.Name}).
+		WithWorkerGroup("workers", 1, cohortTestWorkerResources(), nil).
+		Build()
+	borrower, err := test.Client().Ray().RayV1().RayClusters(namespace.Name).Create(test.Ctx(), borrower, metav1.CreateOptions{})
+	test.Expect(err).NotTo(HaveOccurred())
+	test.T().Logf("Created RayCluster %s/%s successfully", borrower.Namespace, borrower.Name)
+
+	test.T().Logf("Waiting for RayCluster %s/%s to be running", borrower.Namespace, borrower.Name)
+	test.Eventually(RayCluster(test, namespace.Name, borrower.Name), TestTimeoutMedium).
+		Should(WithTransform(RayClusterState, Equal(rayv1.Ready)))
+
+	test.T().Logf("Checking ClusterQueue %s borrows quota from cohort %s", borrowingQueue.Name, cohort)
+	test.Eventually(KueueClusterQueue(test, borrowingQueue.Name), TestTimeoutShort).
+		Should(WithTransform(KueueClusterQueueUsage, And(
+			HaveFlavorUsage("default-flavor", corev1.ResourceCPU, "1750m"),
+			HaveFlavorBorrowed("default-flavor", corev1.ResourceCPU, "750m"),
+		)))
+
+	// Submit a RayCluster to the lending queue, that needs the borrowed quota back
+	lender := NewRayClusterBuilder(namespace.Name, "lender").
+		WithLabels(map[string]string{"kueue.x-k8s.io/queue-name": lendingLocalQueue.Name}).
+		WithWorkerGroup("workers", 1, cohortTestWorkerResources(), nil).
+		Build()
+	lender, err = test.Client().Ray().RayV1().RayClusters(namespace.Name).Create(test.Ctx(), lender, metav1.CreateOptions{})
+	test.Expect(err).NotTo(HaveOccurred())
+	test.T().Logf("Created RayCluster %s/%s successfully", lender.Namespace, lender.Name)
+
+	test.T().Logf("Waiting for the Kueue Workload of RayCluster %s/%s to be preempted", borrower.Namespace, borrower.Name)
+	test.Eventually(WorkloadForRayCluster(test, borrower), TestTimeoutMedium).
+		Should(WithTransform(WorkloadPreempted, BeTrue()))
+	expectRayClusterSuspended(test, borrower)
+
+	test.T().Logf("Waiting for RayCluster %s/%s to be running", lender.Namespace, lender.Name)
+	test.Eventually(RayCluster(test, namespace.Name, lender.Name), TestTimeoutMedium).
+		Should(WithTransform(RayClusterState, Equal(rayv1.Ready)))
+
+	// The borrower stays pending while the lending queue uses its quota
+	test.Consistently(RayCluster(test, namespace.Name, borrower.Name), TestTimeoutShort).
+		Should(WithTransform(RayClusterState, Equal(rayv1.Suspended)))
+
+	err = test.Client().Ray().RayV1().RayClusters(namespace.Name).Delete(test.Ctx(), lender.Name, metav1.DeleteOptions{})
+	test.Expect(err).NotTo(HaveOccurred())
+	test.T().Logf("Deleted RayCluster %s/%s successfully", lender.Namespace, lender.Name)
+
+	test.T().Logf("Waiting for RayCluster %s/%s to be re-admitted", borrower.Namespace, borrower.Name)
+	expectRayClusterResumed(test, borrower)
+}
+
+// createCohortClusterQueue creates a ClusterQueue in the cohort with the given nominal CPU quota,
+// that can borrow the idle quota of the cohort, and reclaims its own quota with the given policy.
+func createCohortClusterQueue(test Test, cohort, cpu string, reclaim kueuev1beta1.PreemptionPolicy) *kueuev1beta1.ClusterQueue {
+	test.T().Helper()
+
+	clusterQueue := CreateTestKueueClusterQueue(test, kueuev1beta1.ClusterQueueSpec{
+		Cohort:            cohort,
+		NamespaceSelector: &metav1.LabelSelector{},
+		ResourceGroups: []kueuev1beta1.ResourceGroup{
+			{
+				CoveredResources: []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory},
+				Flavors: []kueuev1beta1.FlavorQuotas{
+					{
+						Name: "default-flavor",
+						Resources: []kueuev1beta1.ResourceQuota{
+							{Name: corev1.ResourceCPU, NominalQuota: resource.MustParse(cpu)},
+							{Name: corev1.ResourceMemory, NominalQuota: resource.MustParse("4G")},
+						},
+					},
+				},
+			},
+		},
+		Preemption: &kueuev1beta1.ClusterQueuePreemption{
+			ReclaimWithinCohort: reclaim,
+		},
+	})
+
+	return clusterQueue
+}
+
+func cohortTestWorkerResources() corev1.ResourceRequirements {
+	return corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("1500m"),
+			corev1.ResourceMemory: resource.MustParse("1G"),
+		},
+		Limits: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("1500m"),
+			corev1.ResourceMemory: resource.MustParse("1G"),
+		},
+	}
+}
@@ -0,0 +1,159 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	. "github.com/project-codeflare/codeflare-common/support"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	"github.com/project-codeflare/codeflare-operator/pkg/config"
+	. "github.com/project-codeflare/codeflare-operator/test/support"
+)
+
+const operatorConfigMapName = "codeflare-operator-config"
+
+// Flips the dashboard exposure type of the operator from Route to Ingress and back,
+// and asserts the dashboard and client of an existing RayCluster are migrated accordingly.
+// This test is not run in parallel, as it restarts the operator.
+func TestDashboardExposureMigration(t *testing.T) {
+	test := With(t)
+	Tags(test, OpenShiftOnly)
+
+	// Create a namespace and localqueue in that namespace
+	namespace := test.NewTestNamespace()
+	DumpEventsOnFailure(test, namespace.Name)
+	CollectDiagnosticsOnFailure(test, namespace.Name)
+	localQueue := CreateKueueLocalQueue(test, namespace.Name, "e2e-cluster-queue")
+
+	mnist := constructMNISTConfigMap(test, namespace)
+	mnist, err := test.Client().Core().CoreV1().ConfigMaps(namespace.Name).Create(test.Ctx(), mnist, metav1.CreateOptions{})
+	test.Expect(err).NotTo(HaveOccurred())
+	test.T().Logf("Created ConfigMap %s/%s successfully", mnist.Namespace, mnist.Name)
+
+	// Start from Route exposure, and restore the original configuration once done
+	original := updateOperatorConfig(test, config.RouteDashboardExposure)
+	test.T().Cleanup(func() {
+		restoreOperatorConfig(test, original)
+	})
+
+	rayCluster := constructRayCluster(test, namespace, mnist)
+	AssignToLocalQueue(rayCluster, localQueue)
+	rayCluster, err = test.Client().Ray().RayV1().RayClusters(namespace.Name).Create(test.Ctx(), rayCluster, metav1.CreateOptions{})
+	test.Expect(err).NotTo(HaveOccurred())
+	test.T().Logf("Created RayCluster %s/%s successfully", rayCluster.Namespace, rayCluster.Name)
+
+	test.T().Logf("Waiting for RayCluster %s/%s to be running", rayCluster.Namespace, rayCluster.Name)
+	test.Eventually(RayCluster(test, namespace.Name, rayCluster.Name), TestTimeoutMedium).
+		Should(WithTransform(RayClusterState, Equal(rayv1.Ready)))
+
+	dashboardName := "ray-dashboard-" + rayCluster.Name
+	rayClientName := "rayclient-" + rayCluster.Name
+
+	test.T().Logf("Waiting for RayCluster %s/%s to be exposed with Routes", rayCluster.Namespace, rayCluster.Name)
+	test.Eventually(Route(test, namespace.Name, dashboardName), TestTimeoutShort).Should(Not(BeNil()))
+	test.Eventually(Route(test, namespace.Name, rayClientName), TestTimeoutShort).Should(Not(BeNil()))
+
+	// Switch to Ingress exposure
+	updateOperatorConfig(test, config.IngressDashboardExposure)
+
+	test.T().Logf("Waiting for RayCluster %s/%s to be migrated to Ingresses", rayCluster.Namespace, rayCluster.Name)
+	test.Eventually(Ingress(test, namespace.Name, dashboardName), TestTimeoutMedium).
+		Should(WithTransform(LoadBalancerIngresses, Not(BeEmpty())))
+	test.Eventually(Ingress(test, namespace.Name, rayClientName), TestTimeoutMedium).
+		Should(WithTransform(LoadBalancerIngresses, Not(BeEmpty())))
+	test.Eventually(routeNotFound(test, namespace.Name, dashboardName), TestTimeoutShort).Should(BeTrue())
+	test.Eventually(routeNotFound(test, namespace.Name, rayClientName), TestTimeoutShort).Should(BeTrue())
+
+	// Switch back to Route exposure
+	updateOperatorConfig(test, config.RouteDashboardExposure)
+
+	test.T().Logf("Waiting for RayCluster %s/%s to be migrated back to Routes", rayCluster.Namespace, rayCluster.Name)
+	test.Eventually(Route(test, namespace.Name, dashboardName), TestTimeoutMedium).Should(Not(BeNil()))
+	test.Eventually(Route(test, namespace.Name, rayClientName), TestTimeoutMedium).Should(Not(BeNil()))
+	test.Eventually(ingressNotFound(test, namespace.Name, dashboardName), TestTimeoutShort).Should(BeTrue())
+	test.Eventually(ingressNotFound(test, namespace.Name, rayClientName), TestTimeoutShort).Should(BeTrue())
+}
+
+// updateOperatorConfig sets the dashboard exposure type in the operator configuration, restarts
+// the operator so that it's taken into account, and returns the previous configuration.
+func updateOperatorConfig(test Test, exposure config.DashboardExposureType) string {
+	test.T().Helper()
+
+	configMap, err := test.Client().Core().CoreV1().ConfigMaps(GetOperatorNamespace()).Get(test.Ctx(), operatorConfigMapName, metav1.GetOptions{})
+	test.Expect(err).NotTo(HaveOccurred())
+	original := configMap.Data["config.yaml"]
+
+	// Unmarshal into a generic map, so that the other fields are preserved as is
+	cfg := map[string]any{}
+	test.Expect(yaml.Unmarshal([]byte(original), &cfg)).To(Succeed())
+	kuberay, _ := cfg["kuberay"].(map[string]any)
+	if kuberay == nil {
+		kuberay = map[string]any{}
+	}
+	kuberay["dashboardExposure"] = exposure
+	// The Ingresses bypass the OAuth proxy, so it must be disabled with them
+	kuberay["rayDashboardOAuthEnabled"] = exposure == config.RouteDashboardExposure
+	cfg["kuberay"] = kuberay
+	data, err := yaml.Marshal(cfg)
+	test.Expect(err).NotTo(HaveOccurred())
+
+	restoreOperatorConfig(test, string(data))
+	test.T().Logf("Updated operator dashboard exposure to %s", exposure)
+
+	return original
+}
+
+func restoreOperatorConfig(test Test, data string) {
+	test.T().Helper()
+
+	RetryOnConflictOrTransient(test, func() error {
+		configMap, err := test.Client().Core().CoreV1().ConfigMaps(GetOperatorNamespace()).Get(test.Ctx(), operatorConfigMapName, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		configMap.Data["config.yaml"] = data
+		_, err = test.Client().Core().CoreV1().ConfigMaps(GetOperatorNamespace()).Update(test.Ctx(), configMap, metav1.UpdateOptions{})
+		return err
+	})
+
+	// The configuration is only loaded on start
+	err := test.Client().Core().CoreV1().Pods(GetOperatorNamespace()).DeleteCollection(test.Ctx(), metav1.DeleteOptions{},
+		metav1.ListOptions{LabelSelector: "app.kubernetes.io/name=codeflare-operator"})
+	test.Expect(err).NotTo(HaveOccurred())
+	test.T().Logf("Restarted operator in namespace %s", GetOperatorNamespace())
+}
+
+func routeNotFound(test Test, namespace, name string) func(g Gomega) bool {
+	return func(g Gomega) bool {
+		_, err := test.Client().Route().RouteV1().Routes(namespace).Get(test.Ctx(), name, metav1.GetOptions{})
+		return errors.IsNotFound(err)
+	}
+}
+
+func ingressNotFound(test Test, namespace, name string) func(g Gomega) bool {
+	return func(g Gomega) bool {
+		_, err := test.Client().Core().NetworkingV1().Ingresses(namespace).Get(test.Ctx(), name, metav1.GetOptions{})
+		return errors.IsNotFound(err)
+	}
+}
@@ -0,0 +1,69 @@
+# Copyright 2024 IBM, Red Hat
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#      http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+# Trains a tiny model with DistributedDataParallel across all the ranks of the job, over NCCL, or RCCL on ROCm,
+# and fails unless every rank has taken part in the collectives, and the replicas of the model are in sync.
+
+import os
+
+import torch
+import torch.distributed as dist
+from torch import nn
+from torch.nn.parallel import DistributedDataParallel
+
+STEPS = 10
+
+
+def main():
+    # ROCm builds of PyTorch implement the nccl backend with RCCL
+    dist.init_process_group(backend="nccl")
+    rank, world_size = dist.get_rank(), dist.get_world_size()
+    local_rank = int(os.environ.get("LOCAL_RANK", "0"))
+    torch.cuda.set_device(local_rank)
+    device = torch.device("cuda", local_rank)
+    print(f"rank {rank} joined the process group of world size {world_size} "
+          f"on {torch.cuda.get_device_name(device)}", flush=True)
+
+    # Each rank contributes its rank, so that the sum proves all the ranks have taken part in the all-reduce
+    ranks = torch.tensor([float(rank)], device=device)
+    dist.all_reduce(ranks)
+    expected = world_size * (world_size - 1) // 2
+    if int(ranks.item()) != expected:
+        raise RuntimeError(f"all-reduce of the ranks is {int(ranks.item())}, expected {expected}")
+    print(f"rank {rank} all-reduced the ranks of {world_size} ranks", flush=True)
+
+    torch.manual_seed(rank)
+    model = DistributedDataParallel(nn.Linear(16, 1).to(device), device_ids=[local_rank])
+    optimizer = torch.optim.SGD(model.parameters(), lr=0.01)
+    for step in range(STEPS):
+        optimizer.zero_grad()
+        loss = model(torch.randn(32, 16, device=device)).pow(2).mean()
+        loss.backward()
+        optimizer.step()
+        print(f"rank {rank} step {step} loss {loss.item():.4f}", flush=True)
+
+    # DDP broadcasts the parameters of rank 0, and averages the gradients, so the replicas must be identical
+    parameters = torch.cat([p.detach().flatten() for p in model.parameters()])
+    gathered = [torch.zeros_like(parameters) for _ in range(world_size)]
+    dist.all_gather(gathered, parameters)
+    if not all(torch.equal(gathered[0], p) for p in gathered):
+        raise RuntimeError("the replicas of the model are out of sync")
+    print(f"rank {rank} trained in sync with {world_size} ranks", flush=True)
+
+    dist.barrier()
+    dist.destroy_process_group()
+
+
+if __name__ == "__main__":
+    main()
@@ -0,0 +1,224 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	"fmt"
+	"testing"
+
+	kubeflowv1 "github.com/kubeflow/training-operator/pkg/apis/kubeflow.org/v1"
+	. "github.com/onsi/gomega"
+	. "github.com/project-codeflare/codeflare-common/support"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kueuev1beta1 "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+
+	. "github.com/project-codeflare/codeflare-operator/test/support"
+)
+
+// ddpWorldSize is the number of ranks, each with its own GPU, and on its own pod, the model is trained across.
+const ddpWorldSize = 2
+
+// TestDDPPyTorchJob trains a model with DistributedDataParallel across the GPUs of the master and the workers of a
+// PyTorchJob, over NCCL, or RCCL on AMD GPUs, and asserts all the ranks have joined the process group, and trained
+// in sync, so that the regressions of the networking between the pods, or of the scheduling of the pods onto the
+// tainted GPU nodes, that the single worker training doesn't exercise, are caught.
+// On AMD GPUs, CODEFLARE_TEST_PYTORCH_IMAGE must be set to a ROCm build of PyTorch.
+func TestDDPPyTorchJob(t *testing.T) {
+	test := With(t)
+	Tags(test, GPU, LongRunning)
+	test.T().Parallel()
+
+	if !IsPyTorchJobAvailable(test) {
+		test.T().Skip("The Kubeflow Training Operator isn't installed")
+	}
+	// Intel Gaudi accelerators don't support NCCL
+	accelerators := DetectAccelerators(test)
+	accelerator, ok := NvidiaGPU, accelerators.Available(NvidiaGPU, ddpWorldSize)
+	if !ok {
+		accelerator, ok = AMDGPU, accelerators.Available(AMDGPU, ddpWorldSize)
+	}
+	if !ok {
+		test.T().Skipf("The test requires %d NVIDIA or AMD GPUs, detected accelerators: %s", ddpWorldSize, accelerators)
+	}
+	test.T().Logf("Training across %d %s(s)", ddpWorldSize, accelerator.Name)
+
+	// Pull the image once for all the tests, before the timeouts start
+	PrePullImages(test, GetPyTorchImage())
+
+	// Use a dedicated ClusterQueue, as the one of the e2e tests doesn't cover accelerators
+	clusterQueue := CreateTestKueueClusterQueue(test, kueuev1beta1.ClusterQueueSpec{
+		NamespaceSelector: &metav1.LabelSelector{},
+		ResourceGroups: []kueuev1beta1.ResourceGroup{
+			{
+				CoveredResources: []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory, accelerator.ResourceName},
+				Flavors: []kueuev1beta1.FlavorQuotas{
+					{
+						Name: "default-flavor",
+						Resources: []kueuev1beta1.ResourceQuota{
+							{Name: corev1.ResourceCPU, NominalQuota: resource.MustParse(fmt.Sprint(ddpWorldSize))},
+							{Name: corev1.ResourceMemory, NominalQuota: resource.MustParse(fmt.Sprintf("%dGi", 4*ddpWorldSize))},
+							{Name: accelerator.ResourceName, NominalQuota: *resource.NewQuantity(ddpWorldSize, resource.DecimalSI)},
+						},
+					},
+				},
+			},
+		},
+	})
+
+	// Create a namespace and localqueue in that namespace
+	namespace := LeaseTestNamespace(test)
+	DumpEventsOnFailure(test, namespace.Name)
+	CollectDiagnosticsOnFailure(test, namespace.Name)
+	localQueue := CreateKueueLocalQueue(test, namespace.Name, clusterQueue.Name)
+
+	// Test configuration
+	config := &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.String(),
+			Kind:       "ConfigMap",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ddp-pytorchjob",
+			Namespace: namespace.Name,
+		},
+		BinaryData: map[string][]byte{
+			// DDP training script
+			"ddp.py": ReadFile(test, "ddp.py"),
+		},
+		Immutable: Ptr(true),
+	}
+	config, err := test.Client().Core().CoreV1().ConfigMaps(namespace.Name).Create(test.Ctx(), config, metav1.CreateOptions{})
+	test.Expect(err).NotTo(HaveOccurred())
+	test.T().Logf("Created ConfigMap %s/%s successfully", config.Namespace, config.Name)
+
+	job := &kubeflowv1.PyTorchJob{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: kubeflowv1.GroupVersion.String(),
+			Kind:       kubeflowv1.PyTorchJobKind,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ddp",
+			Namespace: namespace.Name,
+			Labels:    map[string]string{"kueue.x-k8s.io/queue-name": localQueue.Name},
+		},
+		Spec: kubeflowv1.PyTorchJobSpec{
+			NprocPerNode: Ptr("1"),
+			PyTorchReplicaSpecs: map[kubeflowv1.ReplicaType]*kubeflowv1.ReplicaSpec{
+				kubeflowv1.PyTorchJobReplicaTypeMaster: ddpReplicaSpec(config, accelerator, 1),
+				kubeflowv1.PyTorchJobReplicaTypeWorker: ddpReplicaSpec(config, accelerator, ddpWorldSize-1),
+			},
+		},
+	}
+	job, err = Kubeflow(test).PyTorchJobs(namespace.Name).Create(test.Ctx(), job, metav1.CreateOptions{})
+	test.Expect(err).NotTo(HaveOccurred())
+	test.T().Logf("Created PyTorchJob %s/%s successfully", job.Namespace, job.Name)
+
+	test.T().Logf("Waiting for the Kueue Workload of PyTorchJob %s/%s to be admitted", job.Namespace, job.Name)
+	test.Eventually(Workloads(test, namespace.Name), TestTimeoutMedium).
+		Should(ContainElement(WithTransform(WorkloadAdmitted, BeTrue())))
+
+	test.T().Logf("Waiting for PyTorchJob %s/%s to complete", job.Namespace, job.Name)
+	test.Eventually(PyTorchJob(test, namespace.Name, job.Name), TestTimeoutLong).
+		Should(Or(
+			WithTransform(PyTorchJobSucceeded, BeTrue()),
+			WithTransform(PyTorchJobFailed, BeTrue()),
+		))
+
+	// Each rank runs on its own pod, that must have joined the process group, and trained in sync with the others
+	pods := GetPods(test, namespace.Name, metav1.ListOptions{LabelSelector: kubeflowv1.JobNameLabel + "=" + job.Name})
+	test.Expect(pods).To(HaveLen(ddpWorldSize))
+	for i := range pods {
+		logs := string(GetPodLogs(test, &pods[i], corev1.PodLogOptions{Container: kubeflowv1.PyTorchJobDefaultContainerName}))
+		test.T().Logf("Logs of pod %s/%s:\n%s", pods[i].Namespace, pods[i].Name, logs)
+		test.Expect(logs).To(And(
+			ContainSubstring("joined the process group of world size %d", ddpWorldSize),
+			ContainSubstring("all-reduced the ranks of %d ranks", ddpWorldSize),
+			ContainSubstring("trained in sync with %d ranks", ddpWorldSize),
+		), "pod %s/%s hasn't trained with all the ranks", pods[i].Namespace, pods[i].Name)
+	}
+
+	test.Expect(GetPyTorchJob(test, namespace.Name, job.Name)).
+		To(WithTransform(PyTorchJobSucceeded, BeTrue()))
+}
+
+func ddpReplicaSpec(config *corev1.ConfigMap, accelerator Accelerator, replicas int32) *kubeflowv1.ReplicaSpec {
+	return &kubeflowv1.ReplicaSpec{
+		Replicas:      Ptr(replicas),
+		RestartPolicy: kubeflowv1.RestartPolicyNever,
+		Template: corev1.PodTemplateSpec{
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{
+						Name:  kubeflowv1.PyTorchJobDefaultContainerName,
+						Image: GetPyTorchImage(),
+						Env: []corev1.EnvVar{
+							// Reports the transport NCCL selects, and why it fails to connect the ranks
+							{Name: "NCCL_DEBUG", Value: "INFO"},
+						},
+						// torchrun reads the rendezvous configuration from the PET_ environment variables set by the Training Operator
+						Command: []string{"torchrun", "/test/ddp.py"},
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{
+								corev1.ResourceCPU:       resource.MustParse("500m"),
+								corev1.ResourceMemory:    resource.MustParse("2Gi"),
+								accelerator.ResourceName: resource.MustParse("1"),
+							},
+							Limits: corev1.ResourceList{
+								corev1.ResourceCPU:       resource.MustParse("1"),
+								corev1.ResourceMemory:    resource.MustParse("4Gi"),
+								accelerator.ResourceName: resource.MustParse("1"),
+							},
+						},
+						VolumeMounts: []corev1.VolumeMount{
+							{
+								Name:      "test",
+								MountPath: "/test",
+							},
+							{
+								// NCCL exchanges data between the processes through shared memory
+								Name:      "shm",
+								MountPath: "/dev/shm",
+							},
+						},
+					},
+				},
+				Tolerations: []corev1.Toleration{accelerator.Toleration()},
+				Volumes: []corev1.Volume{
+					{
+						Name: "test",
+						VolumeSource: corev1.VolumeSource{
+							ConfigMap: &corev1.ConfigMapVolumeSource{
+								LocalObjectReference: corev1.LocalObjectReference{
+									Name: config.Name,
+								},
+							},
+						},
+					},
+					{
+						Name: "shm",
+						VolumeSource: corev1.VolumeSource{
+							EmptyDir: &corev1.EmptyDirVolumeSource{Medium: corev1.StorageMediumMemory},
+						},
+					},
+				},
+			},
+		},
+	}
+}
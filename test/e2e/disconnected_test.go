@@ -0,0 +1,46 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	. "github.com/project-codeflare/codeflare-common/support"
+
+	. "github.com/project-codeflare/codeflare-operator/test/support"
+)
+
+// Asserts the public internet isn't reachable from the pods of the cluster, when the disconnected profile is enabled,
+// so that the other tests succeeding proves they only depend on the mirrors of the images, packages, and datasets.
+func TestDisconnectedEgress(t *testing.T) {
+	test := With(t)
+	Tags(test, Smoke)
+	test.T().Parallel()
+
+	if !IsDisconnected() {
+		test.T().Skipf("The disconnected profile isn't enabled with %s", DisconnectedEnvVar)
+	}
+
+	namespace := LeaseTestNamespace(test)
+
+	for _, host := range []string{"pypi.org", "github.com", "quay.io"} {
+		exitCode, output := RunProbePod(test, namespace.Name, GetRayImage(), tcpProbeCommand(host, 443))
+		test.Expect(exitCode).NotTo(BeZero(), "Expected %s not to be reachable from the cluster: %s", host, output)
+		test.T().Logf("%s isn't reachable from the cluster: %s", host, output)
+	}
+}
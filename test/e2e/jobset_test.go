@@ -0,0 +1,153 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	. "github.com/project-codeflare/codeflare-common/support"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	jobsetv1alpha2 "sigs.k8s.io/jobset/api/jobset/v1alpha2"
+	kueuev1beta1 "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+
+	. "github.com/project-codeflare/codeflare-operator/test/support"
+)
+
+// Submits a JobSet, with a driver and workers replicated jobs, to a LocalQueue, and asserts it's admitted by Kueue
+// as a single Workload, runs to completion, and its quota is released.
+func TestJobSetQueuedByKueue(t *testing.T) {
+	test := With(t)
+	test.T().Parallel()
+
+	if !IsJobSetAvailable(test) {
+		test.T().Skip("JobSet isn't installed")
+	}
+
+	// Use a dedicated ClusterQueue, that only fits the JobSet
+	clusterQueue := CreateTestKueueClusterQueue(test, kueuev1beta1.ClusterQueueSpec{
+		NamespaceSelector: &metav1.LabelSelector{},
+		ResourceGroups: []kueuev1beta1.ResourceGroup{
+			{
+				CoveredResources: []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory},
+				Flavors: []kueuev1beta1.FlavorQuotas{
+					{
+						Name: "default-flavor",
+						Resources: []kueuev1beta1.ResourceQuota{
+							{Name: corev1.ResourceCPU, NominalQuota: resource.MustParse("300m")},
+							{Name: corev1.ResourceMemory, NominalQuota: resource.MustParse("384Mi")},
+						},
+					},
+				},
+			},
+		},
+	})
+
+	// Create a namespace and localqueue in that namespace
+	namespace := LeaseTestNamespace(test)
+	DumpEventsOnFailure(test, namespace.Name)
+	CollectDiagnosticsOnFailure(test, namespace.Name)
+	localQueue := CreateKueueLocalQueue(test, namespace.Name, clusterQueue.Name)
+
+	jobSet := newJobSet(namespace.Name, "jobset",
+		newReplicatedJob("driver", 1, 1),
+		newReplicatedJob("workers", 1, 2),
+	)
+	AssignToLocalQueue(jobSet, localQueue)
+	jobSet, err := JobSets(test, namespace.Name).Create(test.Ctx(), jobSet, metav1.CreateOptions{})
+	test.Expect(err).NotTo(HaveOccurred())
+	test.T().Logf("Created JobSet %s/%s successfully", jobSet.Namespace, jobSet.Name)
+
+	test.T().Logf("Waiting for JobSet %s/%s to be admitted", jobSet.Namespace, jobSet.Name)
+	test.Eventually(WorkloadForJobSet(test, jobSet), TestTimeoutMedium).
+		Should(And(
+			WithTransform(WorkloadAdmitted, BeTrue()),
+			WithTransform(func(workload *kueuev1beta1.Workload) int { return len(workload.Spec.PodSets) }, Equal(2)),
+		))
+
+	test.T().Logf("Waiting for JobSet %s/%s to complete", jobSet.Namespace, jobSet.Name)
+	test.Eventually(JobSet(test, jobSet.Namespace, jobSet.Name), TestTimeoutMedium).
+		Should(Or(
+			WithTransform(JobSetCompleted, BeTrue()),
+			WithTransform(JobSetFailed, BeTrue()),
+		))
+	test.Expect(JobSet(test, jobSet.Namespace, jobSet.Name)(test)).To(WithTransform(JobSetCompleted, BeTrue()))
+
+	test.T().Logf("Waiting for the quota of JobSet %s/%s to be released", jobSet.Namespace, jobSet.Name)
+	test.Eventually(WorkloadForJobSet(test, jobSet), TestTimeoutShort).
+		Should(WithTransform(WorkloadFinished, BeTrue()))
+	test.Eventually(KueueClusterQueue(test, clusterQueue.Name), TestTimeoutShort).
+		Should(WithTransform(KueueClusterQueueUsage, And(
+			HaveFlavorUsage("default-flavor", corev1.ResourceCPU, "0"),
+			HaveFlavorUsage("default-flavor", corev1.ResourceMemory, "0"),
+		)))
+}
+
+// Submits a JobSet with more replicated jobs than the PodSets of a Kueue Workload, and asserts it's rejected
+// by the webhook, rather than staying suspended forever.
+func TestJobSetWithTooManyReplicatedJobsRejected(t *testing.T) {
+	test := With(t)
+	test.T().Parallel()
+
+	if !IsJobSetAvailable(test) {
+		test.T().Skip("JobSet isn't installed")
+	}
+
+	namespace := LeaseTestNamespace(test)
+
+	var replicatedJobs []jobsetv1alpha2.ReplicatedJob
+	for i := 0; i < 9; i++ {
+		replicatedJobs = append(replicatedJobs, newReplicatedJob(fmt.Sprintf("job-%d", i), 1, 1))
+	}
+	jobSet := newJobSet(namespace.Name, "too-many", replicatedJobs...)
+	jobSet.Labels = map[string]string{"kueue.x-k8s.io/queue-name": "any"}
+
+	_, err := JobSets(test, namespace.Name).Create(test.Ctx(), jobSet, metav1.CreateOptions{})
+	test.Expect(err).To(HaveOccurred())
+	test.Expect(err.Error()).To(ContainSubstring("spec.replicatedJobs: Too many"))
+}
+
+func newJobSet(namespace, name string, replicatedJobs ...jobsetv1alpha2.ReplicatedJob) *jobsetv1alpha2.JobSet {
+	return &jobsetv1alpha2.JobSet{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: jobsetv1alpha2.GroupVersion.String(),
+			Kind:       "JobSet",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: jobsetv1alpha2.JobSetSpec{
+			ReplicatedJobs: replicatedJobs,
+		},
+	}
+}
+
+func newReplicatedJob(name string, replicas, parallelism int32) jobsetv1alpha2.ReplicatedJob {
+	return jobsetv1alpha2.ReplicatedJob{
+		Name:     name,
+		Replicas: replicas,
+		Template: batchv1.JobTemplateSpec{
+			Spec: newBatchJob("", name, parallelism).Spec,
+		},
+	}
+}
@@ -0,0 +1,32 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	"testing"
+
+	"github.com/project-codeflare/codeflare-operator/test/bootstrap"
+	. "github.com/project-codeflare/codeflare-operator/test/support"
+)
+
+// TestMain provisions a KinD cluster for the suite, when enabled with the CODEFLARE_TEST_BOOTSTRAP environment variable,
+// routes the external references through mirrors, when the disconnected profile is enabled, reports the images
+// not built for the architecture the tests are constrained to, deletes the resources
+// left over by the aborted runs, and shares the fixtures, like the pre-pulled images, across the tests of the suite.
+func TestMain(m *testing.M) {
+	bootstrap.Main(WithArchitectureProfile(WithDisconnectedProfile(WithReaper(WithFixtures(m)))))
+}
@@ -26,17 +26,23 @@ import (
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
-	"k8s.io/apimachinery/pkg/runtime"
+
+	. "github.com/project-codeflare/codeflare-operator/test/support"
 )
 
 // Trains the MNIST dataset as a batch Job in an AppWrapper, and asserts successful completion of the training job.
 func TestMNISTPyTorchAppWrapper(t *testing.T) {
 	test := With(t)
+	Tags(test, LongRunning)
 	test.T().Parallel()
 
+	// Pull the image once for all the tests, before the timeouts start
+	PrePullImages(test, GetPyTorchImage())
+
 	// Create a namespace and localqueue in that namespace
-	namespace := test.NewTestNamespace()
+	namespace := LeaseTestNamespace(test)
+	DumpEventsOnFailure(test, namespace.Name)
+	CollectDiagnosticsOnFailure(test, namespace.Name)
 	localQueue := CreateKueueLocalQueue(test, namespace.Name, "e2e-cluster-queue")
 
 	// Test configuration
@@ -82,7 +88,7 @@ func TestMNISTPyTorchAppWrapper(t *testing.T) {
 							Image: GetPyTorchImage(),
 							Env: []corev1.EnvVar{
 								{Name: "PYTHONUSERBASE", Value: "/workdir"},
-								{Name: "MNIST_DATASET_URL", Value: GetMnistDatasetURL()},
+								{Name: "MNIST_DATASET_URL", Value: GetDatasetURL(test, "mnist")},
 								{Name: "PIP_INDEX_URL", Value: GetPipIndexURL()},
 								{Name: "PIP_TRUSTED_HOST", Value: GetPipTrustedHost()},
 							},
@@ -144,22 +150,15 @@ func TestMNISTPyTorchAppWrapper(t *testing.T) {
 		},
 	}
 
-	appWrapperResource := mcadv1beta2.GroupVersion.WithResource("appwrappers")
-	awMap, err := runtime.DefaultUnstructuredConverter.ToUnstructured(aw)
-	test.Expect(err).NotTo(HaveOccurred())
-	unstruct := unstructured.Unstructured{Object: awMap}
-	_, err = test.Client().Dynamic().Resource(appWrapperResource).Namespace(namespace.Name).Create(test.Ctx(), &unstruct, metav1.CreateOptions{})
-	test.Expect(err).NotTo(HaveOccurred())
-	test.T().Logf("Created AppWrapper %s/%s successfully", aw.Namespace, aw.Name)
+	aw = CreateAppWrapper(test, aw)
 
 	test.T().Logf("Waiting for AppWrapper %s/%s to be running", aw.Namespace, aw.Name)
 	test.Eventually(AppWrapper(test, namespace, aw.Name), TestTimeoutMedium).
 		Should(WithTransform(AppWrapperPhase, Equal(mcadv1beta2.AppWrapperRunning)))
+	test.Expect(GetAppWrapper(test, namespace, aw.Name)).
+		To(WithTransform(AppWrapperComponentPhase(0), Equal(ComponentDeployed)))
 
 	test.T().Logf("Waiting for AppWrapper %s/%s to complete", job.Namespace, job.Name)
-	test.Eventually(AppWrapper(test, namespace, aw.Name), TestTimeoutLong).Should(
-		Or(
-			WithTransform(AppWrapperPhase, Equal(mcadv1beta2.AppWrapperSucceeded)),
-			WithTransform(AppWrapperPhase, Equal(mcadv1beta2.AppWrapperFailed)),
-		))
+	test.Eventually(AppWrapper(test, namespace, aw.Name), TestTimeoutLong).
+		Should(HaveAppWrapperPhase(mcadv1beta2.AppWrapperSucceeded, mcadv1beta2.AppWrapperFailed))
 }
@@ -0,0 +1,186 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	"testing"
+
+	kubeflowv1 "github.com/kubeflow/training-operator/pkg/apis/kubeflow.org/v1"
+	. "github.com/onsi/gomega"
+	. "github.com/project-codeflare/codeflare-common/support"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	. "github.com/project-codeflare/codeflare-operator/test/support"
+)
+
+// TestMNISTPyTorchJob trains the MNIST model with DDP across the master and a worker of a PyTorchJob,
+// that's queued to, and admitted as a whole by, Kueue.
+func TestMNISTPyTorchJob(t *testing.T) {
+	test := With(t)
+	Tags(test, LongRunning)
+	test.T().Parallel()
+
+	if !IsPyTorchJobAvailable(test) {
+		test.T().Skip("The Kubeflow Training Operator isn't installed")
+	}
+
+	// Pull the image once for all the tests, before the timeouts start
+	PrePullImages(test, GetPyTorchImage())
+
+	// Create a namespace and localqueue in that namespace
+	namespace := LeaseTestNamespace(test)
+	DumpEventsOnFailure(test, namespace.Name)
+	CollectDiagnosticsOnFailure(test, namespace.Name)
+	localQueue := CreateKueueLocalQueue(test, namespace.Name, "e2e-cluster-queue")
+
+	// Test configuration
+	config := &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.String(),
+			Kind:       "ConfigMap",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "mnist-pytorchjob",
+			Namespace: namespace.Name,
+		},
+		BinaryData: map[string][]byte{
+			// pip requirements
+			"requirements.txt": ReadFile(test, "mnist_pip_requirements.txt"),
+			// MNIST training script
+			"mnist.py": ReadFile(test, "mnist.py"),
+		},
+		Immutable: Ptr(true),
+	}
+	config, err := test.Client().Core().CoreV1().ConfigMaps(namespace.Name).Create(test.Ctx(), config, metav1.CreateOptions{})
+	test.Expect(err).NotTo(HaveOccurred())
+	test.T().Logf("Created ConfigMap %s/%s successfully", config.Namespace, config.Name)
+
+	datasetURL := GetDatasetURL(test, "mnist")
+	job := &kubeflowv1.PyTorchJob{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: kubeflowv1.GroupVersion.String(),
+			Kind:       kubeflowv1.PyTorchJobKind,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "mnist",
+			Namespace: namespace.Name,
+			Labels:    map[string]string{"kueue.x-k8s.io/queue-name": localQueue.Name},
+		},
+		Spec: kubeflowv1.PyTorchJobSpec{
+			NprocPerNode: Ptr("1"),
+			PyTorchReplicaSpecs: map[kubeflowv1.ReplicaType]*kubeflowv1.ReplicaSpec{
+				kubeflowv1.PyTorchJobReplicaTypeMaster: pytorchJobReplicaSpec(config, datasetURL),
+				kubeflowv1.PyTorchJobReplicaTypeWorker: pytorchJobReplicaSpec(config, datasetURL),
+			},
+		},
+	}
+	job, err = Kubeflow(test).PyTorchJobs(namespace.Name).Create(test.Ctx(), job, metav1.CreateOptions{})
+	test.Expect(err).NotTo(HaveOccurred())
+	test.T().Logf("Created PyTorchJob %s/%s successfully", job.Namespace, job.Name)
+
+	test.T().Logf("Waiting for the Kueue Workload of PyTorchJob %s/%s to be admitted", job.Namespace, job.Name)
+	test.Eventually(Workloads(test, namespace.Name), TestTimeoutMedium).
+		Should(ContainElement(WithTransform(WorkloadAdmitted, BeTrue())))
+
+	test.T().Logf("Waiting for PyTorchJob %s/%s to be running", job.Namespace, job.Name)
+	test.Eventually(PyTorchJob(test, namespace.Name, job.Name), TestTimeoutMedium).
+		Should(WithTransform(PyTorchJobRunning, BeTrue()))
+	LogResourceUsage(test, namespace.Name)
+
+	// Report the training progress while waiting for the job to complete
+	masters := GetPods(test, namespace.Name, metav1.ListOptions{
+		LabelSelector: kubeflowv1.JobNameLabel + "=" + job.Name + "," + kubeflowv1.ReplicaTypeLabel + "=master",
+	})
+	test.Expect(masters).To(HaveLen(1))
+	StreamPodLogs(test, &masters[0], kubeflowv1.PyTorchJobDefaultContainerName)
+
+	test.T().Logf("Waiting for PyTorchJob %s/%s to complete", job.Namespace, job.Name)
+	test.Eventually(PyTorchJob(test, namespace.Name, job.Name), TestTimeoutLong).
+		Should(Or(
+			WithTransform(PyTorchJobSucceeded, BeTrue()),
+			WithTransform(PyTorchJobFailed, BeTrue()),
+		))
+	test.Expect(GetPyTorchJob(test, namespace.Name, job.Name)).
+		To(WithTransform(PyTorchJobSucceeded, BeTrue()))
+}
+
+func pytorchJobReplicaSpec(config *corev1.ConfigMap, datasetURL string) *kubeflowv1.ReplicaSpec {
+	return &kubeflowv1.ReplicaSpec{
+		Replicas:      Ptr(int32(1)),
+		RestartPolicy: kubeflowv1.RestartPolicyNever,
+		Template: corev1.PodTemplateSpec{
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{
+						Name:  kubeflowv1.PyTorchJobDefaultContainerName,
+						Image: GetPyTorchImage(),
+						Env: []corev1.EnvVar{
+							{Name: "PYTHONUSERBASE", Value: "/workdir"},
+							{Name: "MNIST_DATASET_URL", Value: datasetURL},
+							{Name: "PIP_INDEX_URL", Value: GetPipIndexURL()},
+							{Name: "PIP_TRUSTED_HOST", Value: GetPipTrustedHost()},
+						},
+						// torchrun reads the rendezvous configuration from the PET_ environment variables set by the Training Operator
+						Command: []string{"/bin/sh", "-c", "pip install -r /test/requirements.txt && torchrun /test/mnist.py"},
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{
+								corev1.ResourceCPU:    resource.MustParse("250m"),
+								corev1.ResourceMemory: resource.MustParse("1Gi"),
+							},
+							Limits: corev1.ResourceList{
+								corev1.ResourceCPU:    resource.MustParse("1"),
+								corev1.ResourceMemory: resource.MustParse("1500Mi"),
+							},
+						},
+						VolumeMounts: []corev1.VolumeMount{
+							{
+								Name:      "test",
+								MountPath: "/test",
+							},
+							{
+								Name:      "workdir",
+								MountPath: "/workdir",
+							},
+						},
+						WorkingDir: "/workdir",
+					},
+				},
+				Volumes: []corev1.Volume{
+					{
+						Name: "test",
+						VolumeSource: corev1.VolumeSource{
+							ConfigMap: &corev1.ConfigMapVolumeSource{
+								LocalObjectReference: corev1.LocalObjectReference{
+									Name: config.Name,
+								},
+							},
+						},
+					},
+					{
+						Name: "workdir",
+						VolumeSource: corev1.VolumeSource{
+							EmptyDir: &corev1.EmptyDirVolumeSource{},
+						},
+					},
+				},
+			},
+		},
+	}
+}
@@ -17,9 +17,6 @@ limitations under the License.
 package e2e
 
 import (
-	"crypto/tls"
-	"net/http"
-	"net/url"
 	"testing"
 
 	. "github.com/onsi/gomega"
@@ -30,18 +27,24 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
-	"k8s.io/apimachinery/pkg/runtime"
+
+	. "github.com/project-codeflare/codeflare-operator/test/support"
 )
 
 // Trains the MNIST dataset as a RayJob, executed by a Ray cluster
 // directly managed by Kueue, and asserts successful completion of the training job.
 func TestMNISTRayJobRayCluster(t *testing.T) {
 	test := With(t)
+	Tags(test, LongRunning)
 	test.T().Parallel()
 
+	// Pull the image once for all the tests, before the timeouts start
+	PrePullImages(test, GetRayImage())
+
 	// Create a namespace and localqueue in that namespace
-	namespace := test.NewTestNamespace()
+	namespace := LeaseTestNamespace(test)
+	DumpEventsOnFailure(test, namespace.Name)
+	CollectDiagnosticsOnFailure(test, namespace.Name)
 	localQueue := CreateKueueLocalQueue(test, namespace.Name, "e2e-cluster-queue")
 
 	// Create MNIST training script
@@ -67,10 +70,16 @@ func TestMNISTRayJobRayCluster(t *testing.T) {
 	test.Expect(err).NotTo(HaveOccurred())
 	test.T().Logf("Created RayJob %s/%s successfully", rayJob.Namespace, rayJob.Name)
 
-	rayDashboardURL := getRayDashboardURL(test, rayCluster.Namespace, rayCluster.Name)
+	// The submitter logs cover the failures that occur before the job is registered with the Ray dashboard
+	test.T().Cleanup(func() {
+		if test.T().Failed() {
+			test.T().Logf("Logs of the submitter of RayJob %s/%s:\n%s", rayJob.Namespace, rayJob.Name,
+				GetRayJobSubmitterLogs(test, rayJob.Namespace, rayJob.Name))
+		}
+	})
 
-	test.T().Logf("Connecting to Ray cluster at: %s", rayDashboardURL.String())
-	rayClient := NewRayClusterClient(rayDashboardURL)
+	rayClient := GetRayDashboardClient(test, rayCluster.Namespace, rayCluster.Name)
+	test.T().Logf("Connecting to Ray cluster at: %s", rayClient.Endpoint().String())
 
 	// Wait for Ray job id to be available, this value is needed for writing logs in defer
 	test.Eventually(RayJob(test, rayJob.Namespace, rayJob.Name), TestTimeoutShort).
@@ -91,10 +100,16 @@ func TestMNISTRayJobRayCluster(t *testing.T) {
 // Same as TestMNISTRayJobRayCluster, except the RayCluster is wrapped in an AppWrapper
 func TestMNISTRayJobRayClusterAppWrapper(t *testing.T) {
 	test := With(t)
+	Tags(test, LongRunning)
 	test.T().Parallel()
 
+	// Pull the image once for all the tests, before the timeouts start
+	PrePullImages(test, GetRayImage())
+
 	// Create a namespace and localqueue in that namespace
-	namespace := test.NewTestNamespace()
+	namespace := LeaseTestNamespace(test)
+	DumpEventsOnFailure(test, namespace.Name)
+	CollectDiagnosticsOnFailure(test, namespace.Name)
 	localQueue := CreateKueueLocalQueue(test, namespace.Name, "e2e-cluster-queue")
 
 	// Create MNIST training script
@@ -123,13 +138,7 @@ func TestMNISTRayJobRayClusterAppWrapper(t *testing.T) {
 			},
 		},
 	}
-	appWrapperResource := mcadv1beta2.GroupVersion.WithResource("appwrappers")
-	awMap, err := runtime.DefaultUnstructuredConverter.ToUnstructured(aw)
-	test.Expect(err).NotTo(HaveOccurred())
-	unstruct := unstructured.Unstructured{Object: awMap}
-	_, err = test.Client().Dynamic().Resource(appWrapperResource).Namespace(namespace.Name).Create(test.Ctx(), &unstruct, metav1.CreateOptions{})
-	test.Expect(err).NotTo(HaveOccurred())
-	test.T().Logf("Created AppWrapper %s/%s successfully", aw.Namespace, aw.Name)
+	aw = CreateAppWrapper(test, aw)
 
 	test.T().Logf("Waiting for AppWrapper %s/%s to be running", aw.Namespace, aw.Name)
 	test.Eventually(AppWrapper(test, namespace, aw.Name), TestTimeoutMedium).
@@ -145,10 +154,16 @@ func TestMNISTRayJobRayClusterAppWrapper(t *testing.T) {
 	test.Expect(err).NotTo(HaveOccurred())
 	test.T().Logf("Created RayJob %s/%s successfully", rayJob.Namespace, rayJob.Name)
 
-	rayDashboardURL := getRayDashboardURL(test, rayCluster.Namespace, rayCluster.Name)
+	// The submitter logs cover the failures that occur before the job is registered with the Ray dashboard
+	test.T().Cleanup(func() {
+		if test.T().Failed() {
+			test.T().Logf("Logs of the submitter of RayJob %s/%s:\n%s", rayJob.Namespace, rayJob.Name,
+				GetRayJobSubmitterLogs(test, rayJob.Namespace, rayJob.Name))
+		}
+	})
 
-	test.T().Logf("Connecting to Ray cluster at: %s", rayDashboardURL.String())
-	rayClient := NewRayClusterClient(rayDashboardURL)
+	rayClient := GetRayDashboardClient(test, rayCluster.Namespace, rayCluster.Name)
+	test.T().Logf("Connecting to Ray cluster at: %s", rayClient.Endpoint().String())
 
 	// Wait for Ray job id to be available, this value is needed for writing logs in defer
 	test.Eventually(RayJob(test, rayJob.Namespace, rayJob.Name), TestTimeoutShort).
@@ -299,7 +314,7 @@ func constructRayCluster(_ Test, namespace *corev1.Namespace, mnist *corev1.Conf
 	}
 }
 
-func constructRayJob(_ Test, namespace *corev1.Namespace, rayCluster *rayv1.RayCluster) *rayv1.RayJob {
+func constructRayJob(test Test, namespace *corev1.Namespace, rayCluster *rayv1.RayCluster) *rayv1.RayJob {
 	return &rayv1.RayJob{
 		TypeMeta: metav1.TypeMeta{
 			APIVersion: rayv1.GroupVersion.String(),
@@ -317,7 +332,7 @@ func constructRayJob(_ Test, namespace *corev1.Namespace, rayCluster *rayv1.RayC
     - torchmetrics==0.9.1
     - torchvision==0.12.0
   env_vars:
-    MNIST_DATASET_URL: "` + GetMnistDatasetURL() + `"
+    MNIST_DATASET_URL: "` + GetDatasetURL(test, "mnist") + `"
     PIP_INDEX_URL: "` + GetPipIndexURL() + `"
     PIP_TRUSTED_HOST: "` + GetPipTrustedHost() + `"
 `,
@@ -339,43 +354,3 @@ func constructRayJob(_ Test, namespace *corev1.Namespace, rayCluster *rayv1.RayC
 		},
 	}
 }
-
-func getRayDashboardURL(test Test, namespace, rayClusterName string) url.URL {
-	dashboardName := "ray-dashboard-" + rayClusterName
-
-	if IsOpenShift(test) {
-		route := GetRoute(test, namespace, dashboardName)
-		hostname := route.Status.Ingress[0].Host
-
-		// Wait for expected HTTP code
-		test.T().Logf("Waiting for Route %s/%s to be available", route.Namespace, route.Name)
-		tr := &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-		}
-		client := &http.Client{Transport: tr}
-
-		test.Eventually(func() (int, error) {
-			resp, err := client.Get("https://" + hostname)
-			if err != nil {
-				return -1, err
-			}
-			return resp.StatusCode, nil
-		}, TestTimeoutShort).Should(Not(Equal(503)))
-
-		return url.URL{
-			Scheme: "https",
-			Host:   hostname,
-		}
-	}
-
-	ingress := GetIngress(test, namespace, dashboardName)
-
-	test.T().Logf("Waiting for Ingress %s/%s to be admitted", ingress.Namespace, ingress.Name)
-	test.Eventually(Ingress(test, ingress.Namespace, ingress.Name), TestTimeoutShort).
-		Should(WithTransform(LoadBalancerIngresses, HaveLen(1)))
-
-	return url.URL{
-		Scheme: "http",
-		Host:   ingress.Spec.Rules[0].Host,
-	}
-}
@@ -0,0 +1,165 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	. "github.com/project-codeflare/codeflare-common/support"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kueuev1beta1 "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+
+	. "github.com/project-codeflare/codeflare-operator/test/support"
+)
+
+// Runs a low priority RayCluster in a ClusterQueue that can only fit a single RayCluster,
+// then submits a high priority RayJob to the same queue, and asserts the RayCluster is
+// preempted, the RayJob runs to completion, and the RayCluster is re-admitted once the
+// RayJob cluster is shut down and its quota is released.
+func TestRayJobPreemptsRayCluster(t *testing.T) {
+	test := With(t)
+	test.T().Parallel()
+
+	// Use a dedicated ClusterQueue, so that the quota isn't shared with the other tests
+	clusterQueue := CreateTestKueueClusterQueue(test, kueuev1beta1.ClusterQueueSpec{
+		NamespaceSelector: &metav1.LabelSelector{},
+		ResourceGroups: []kueuev1beta1.ResourceGroup{
+			{
+				CoveredResources: []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory},
+				Flavors: []kueuev1beta1.FlavorQuotas{
+					{
+						Name: "default-flavor",
+						Resources: []kueuev1beta1.ResourceQuota{
+							{Name: corev1.ResourceCPU, NominalQuota: resource.MustParse("2")},
+							{Name: corev1.ResourceMemory, NominalQuota: resource.MustParse("4G")},
+						},
+					},
+				},
+			},
+		},
+		Preemption: &kueuev1beta1.ClusterQueuePreemption{
+			WithinClusterQueue: kueuev1beta1.PreemptionPolicyLowerPriority,
+		},
+	})
+	lowPriority := CreateKueueWorkloadPriorityClass(test, 100)
+	highPriority := CreateKueueWorkloadPriorityClass(test, 1000)
+
+	// Create a namespace and localqueue in that namespace
+	namespace := LeaseTestNamespace(test)
+	DumpEventsOnFailure(test, namespace.Name)
+	CollectDiagnosticsOnFailure(test, namespace.Name)
+	localQueue := CreateKueueLocalQueue(test, namespace.Name, clusterQueue.Name)
+
+	// Create the low priority RayCluster, that's the preemption victim
+	victim := NewRayClusterBuilder(namespace.Name, "victim").
+		WithLabels(map[string]string{
+			"kueue.x-k8s.io/queue-name":     localQueue.Name,
+			"kueue.x-k8s.io/priority-class": lowPriority.Name,
+		}).
+		WithWorkerGroup("workers", 1, preemptionTestWorkerResources(), nil).
+		Build()
+	victim, err := test.Client().Ray().RayV1().RayClusters(namespace.Name).Create(test.Ctx(), victim, metav1.CreateOptions{})
+	test.Expect(err).NotTo(HaveOccurred())
+	test.T().Logf("Created RayCluster %s/%s successfully", victim.Namespace, victim.Name)
+
+	test.T().Logf("Waiting for RayCluster %s/%s to be running", victim.Namespace, victim.Name)
+	test.Eventually(RayCluster(test, namespace.Name, victim.Name), TestTimeoutMedium).
+		Should(WithTransform(RayClusterState, Equal(rayv1.Ready)))
+
+	// Submit the high priority RayJob, that doesn't fit in the ClusterQueue alongside the victim
+	preemptor := &rayv1.RayJob{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: rayv1.GroupVersion.String(),
+			Kind:       "RayJob",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "preemptor",
+			Namespace: namespace.Name,
+			Labels: map[string]string{
+				"kueue.x-k8s.io/queue-name":     localQueue.Name,
+				"kueue.x-k8s.io/priority-class": highPriority.Name,
+			},
+		},
+		Spec: rayv1.RayJobSpec{
+			Entrypoint: `python -c "import ray; ray.init(); print(ray.cluster_resources())"`,
+			RayClusterSpec: func() *rayv1.RayClusterSpec {
+				spec := NewRayClusterBuilder(namespace.Name, "preemptor").
+					WithWorkerGroup("workers", 1, preemptionTestWorkerResources(), nil).
+					Build().Spec
+				return &spec
+			}(),
+			ShutdownAfterJobFinishes: true,
+			SubmitterPodTemplate: &corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyNever,
+					Containers: []corev1.Container{
+						{
+							Image: GetRayImage(),
+							Name:  "rayjob-submitter-pod",
+							Resources: corev1.ResourceRequirements{
+								Requests: corev1.ResourceList{
+									corev1.ResourceCPU:    resource.MustParse("200m"),
+									corev1.ResourceMemory: resource.MustParse("256Mi"),
+								},
+								Limits: corev1.ResourceList{
+									corev1.ResourceCPU:    resource.MustParse("200m"),
+									corev1.ResourceMemory: resource.MustParse("256Mi"),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	preemptor, err = test.Client().Ray().RayV1().RayJobs(namespace.Name).Create(test.Ctx(), preemptor, metav1.CreateOptions{})
+	test.Expect(err).NotTo(HaveOccurred())
+	test.T().Logf("Created RayJob %s/%s successfully", preemptor.Namespace, preemptor.Name)
+
+	test.T().Logf("Waiting for the Kueue Workload of RayCluster %s/%s to be preempted", victim.Namespace, victim.Name)
+	test.Eventually(WorkloadForRayCluster(test, victim), TestTimeoutMedium).
+		Should(WithTransform(WorkloadPreempted, BeTrue()))
+	expectRayClusterSuspended(test, victim)
+
+	test.T().Logf("Waiting for RayJob %s/%s to complete", preemptor.Namespace, preemptor.Name)
+	test.Eventually(RayJob(test, preemptor.Namespace, preemptor.Name), TestTimeoutLong).
+		Should(WithTransform(RayJobStatus, Satisfy(rayv1.IsJobTerminal)))
+	test.Expect(GetRayJob(test, preemptor.Namespace, preemptor.Name)).
+		To(WithTransform(RayJobStatus, Equal(rayv1.JobStatusSucceeded)))
+
+	// The RayJob cluster is shut down once the job has finished, which releases its quota
+	test.T().Logf("Waiting for RayCluster %s/%s to be re-admitted", victim.Namespace, victim.Name)
+	expectRayClusterResumed(test, victim)
+}
+
+func preemptionTestWorkerResources() corev1.ResourceRequirements {
+	return corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("1"),
+			corev1.ResourceMemory: resource.MustParse("1G"),
+		},
+		Limits: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("1"),
+			corev1.ResourceMemory: resource.MustParse("1G"),
+		},
+	}
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	. "github.com/project-codeflare/codeflare-common/support"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kueuev1beta1 "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+
+	. "github.com/project-codeflare/codeflare-operator/test/support"
+)
+
+// Creates RayClusters from two namespaces into a ClusterQueue that can only fit a single RayCluster at a time,
+// and asserts they're admitted in priority order, then in creation order for the same priority, regardless of their
+// namespace, as the admitted RayClusters are deleted. The RayClusters losing the contention stay suspended, and
+// are requeued until their turn comes, rather than being evicted or admitted out of order.
+func TestRayClusterQuotaContention(t *testing.T) {
+	test := With(t)
+	test.T().Parallel()
+
+	// Use a dedicated ClusterQueue, ordering the workloads strictly by priority, then by creation time
+	clusterQueue := CreateTestKueueClusterQueue(test, kueuev1beta1.ClusterQueueSpec{
+		NamespaceSelector: &metav1.LabelSelector{},
+		QueueingStrategy:  kueuev1beta1.StrictFIFO,
+		ResourceGroups: []kueuev1beta1.ResourceGroup{
+			{
+				CoveredResources: []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory},
+				Flavors: []kueuev1beta1.FlavorQuotas{
+					{
+						Name: "default-flavor",
+						Resources: []kueuev1beta1.ResourceQuota{
+							{Name: corev1.ResourceCPU, NominalQuota: resource.MustParse("2")},
+							{Name: corev1.ResourceMemory, NominalQuota: resource.MustParse("4G")},
+						},
+					},
+				},
+			},
+		},
+	})
+	lowPriority := CreateKueueWorkloadPriorityClass(test, 100)
+	highPriority := CreateKueueWorkloadPriorityClass(test, 1000)
+
+	// Create two namespaces, with a localqueue of the ClusterQueue in each
+	namespaceA := LeaseTestNamespace(test)
+	DumpEventsOnFailure(test, namespaceA.Name)
+	CollectDiagnosticsOnFailure(test, namespaceA.Name)
+	localQueueA := CreateKueueLocalQueue(test, namespaceA.Name, clusterQueue.Name)
+	namespaceB := LeaseTestNamespace(test)
+	DumpEventsOnFailure(test, namespaceB.Name)
+	CollectDiagnosticsOnFailure(test, namespaceB.Name)
+	localQueueB := CreateKueueLocalQueue(test, namespaceB.Name, clusterQueue.Name)
+
+	first := createContendingRayCluster(test, namespaceA.Name, "first", localQueueA, lowPriority)
+	test.T().Logf("Waiting for RayCluster %s/%s to be running", first.Namespace, first.Name)
+	test.Eventually(RayCluster(test, first.Namespace, first.Name), TestTimeoutMedium).
+		Should(WithTransform(RayClusterState, Equal(rayv1.Ready)))
+
+	// The next RayClusters are queued behind the first one, the high priority one being created last
+	second := createContendingRayCluster(test, namespaceB.Name, "second", localQueueB, lowPriority)
+	third := createContendingRayCluster(test, namespaceA.Name, "third", localQueueA, lowPriority)
+	urgent := createContendingRayCluster(test, namespaceB.Name, "urgent", localQueueB, highPriority)
+	expectRayClustersPending(test, second, third, urgent)
+
+	// Each admitted RayCluster is deleted in turn, to release the quota to the next one in the queue
+	running := first
+	queue := []*rayv1.RayCluster{urgent, second, third}
+	for i, next := range queue {
+		err := test.Client().Ray().RayV1().RayClusters(running.Namespace).Delete(test.Ctx(), running.Name, metav1.DeleteOptions{})
+		test.Expect(err).NotTo(HaveOccurred())
+		test.T().Logf("Deleted RayCluster %s/%s successfully", running.Namespace, running.Name)
+
+		test.T().Logf("Waiting for RayCluster %s/%s to be admitted", next.Namespace, next.Name)
+		test.Eventually(WorkloadForRayCluster(test, next), TestTimeoutMedium).
+			Should(WithTransform(WorkloadAdmitted, BeTrue()))
+		test.Eventually(RayCluster(test, next.Namespace, next.Name), TestTimeoutMedium).
+			Should(WithTransform(RayClusterState, Equal(rayv1.Ready)))
+		ExpectAllOrNothingScheduled(test, next)
+
+		if pending := queue[i+1:]; len(pending) > 0 {
+			expectRayClustersPending(test, pending...)
+		}
+		running = next
+	}
+
+	// The RayClusters that have waited for their turn have only been requeued, and never evicted
+	for _, rayCluster := range []*rayv1.RayCluster{second, third} {
+		test.Expect(GetWorkloadForRayCluster(test, rayCluster)).
+			To(WithTransform(WorkloadPreempted, BeFalse()))
+	}
+}
+
+// createContendingRayCluster creates a RayCluster requesting 1250m CPU, so that only one fits in the ClusterQueue.
+func createContendingRayCluster(test Test, namespace, name string, localQueue *kueuev1beta1.LocalQueue, priorityClass *kueuev1beta1.WorkloadPriorityClass) *rayv1.RayCluster {
+	test.T().Helper()
+
+	rayCluster := NewRayClusterBuilder(namespace, name).
+		WithLabels(map[string]string{
+			"kueue.x-k8s.io/queue-name":     localQueue.Name,
+			"kueue.x-k8s.io/priority-class": priorityClass.Name,
+		}).
+		WithWorkerGroup("workers", 1, preemptionTestWorkerResources(), nil).
+		Build()
+	rayCluster, err := test.Client().Ray().RayV1().RayClusters(namespace).Create(test.Ctx(), rayCluster, metav1.CreateOptions{})
+	test.Expect(err).NotTo(HaveOccurred())
+	test.T().Logf("Created RayCluster %s/%s with priority %d successfully", rayCluster.Namespace, rayCluster.Name, priorityClass.Value)
+
+	return rayCluster
+}
+
+// expectRayClustersPending asserts the RayClusters stay suspended, with their Kueue Workloads waiting for quota.
+func expectRayClustersPending(test Test, rayClusters ...*rayv1.RayCluster) {
+	test.T().Helper()
+
+	for _, rayCluster := range rayClusters {
+		test.Eventually(WorkloadForRayCluster(test, rayCluster), TestTimeoutShort).
+			Should(WithTransform(WorkloadAdmitted, BeFalse()))
+	}
+	test.T().Logf("Checking %d RayClusters stay pending", len(rayClusters))
+	test.Consistently(func(g Gomega) {
+		for _, rayCluster := range rayClusters {
+			g.Expect(RayCluster(test, rayCluster.Namespace, rayCluster.Name)(g)).
+				To(WithTransform(RayClusterState, Equal(rayv1.Suspended)))
+			g.Expect(WorkloadForRayCluster(test, rayCluster)(g)).
+				To(WithTransform(WorkloadAdmitted, BeFalse()))
+		}
+	}, TestTimeoutShort).Should(Succeed())
+}
@@ -0,0 +1,171 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	awv1beta2 "github.com/project-codeflare/appwrapper/api/v1beta2"
+	. "github.com/project-codeflare/codeflare-common/support"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kueuev1beta1 "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+
+	. "github.com/project-codeflare/codeflare-operator/test/support"
+)
+
+const (
+	autoscalingMinReplicas = 1
+	autoscalingMaxReplicas = 2
+	autoscalingIdleTimeout = 30
+)
+
+// The job runs as many concurrent tasks as the maximum number of workers, each requiring the single CPU of a worker
+const autoscalingJobEntrypoint = `python -c "import time, ray; ray.init(); ` +
+	`ray.get([ray.remote(num_cpus=1)(lambda: time.sleep(30)).remote() for _ in range(2)]); print('Scaled up')"`
+
+// Enables the in-tree autoscaling of a RayCluster, wrapped in an AppWrapper, as Kueue rejects the autoscaling
+// RayClusters it manages directly, and asserts the workers are scaled up, within the quota reserved by Kueue,
+// for a job requiring more CPUs than the current workers provide, then scaled down to the minimum replicas once idle.
+// Kueue reserves the quota of the maximum replicas, that the RayCluster is created with.
+func TestRayClusterAutoscaling(t *testing.T) {
+	test := With(t)
+	Tags(test, LongRunning)
+	test.T().Parallel()
+
+	// Create a namespace, and a ClusterQueue with exactly the quota of the RayCluster at its maximum size
+	namespace := LeaseTestNamespace(test)
+	DumpEventsOnFailure(test, namespace.Name)
+	CollectDiagnosticsOnFailure(test, namespace.Name)
+	clusterQueue := CreateTestKueueClusterQueue(test, kueuev1beta1.ClusterQueueSpec{
+		NamespaceSelector: &metav1.LabelSelector{},
+		ResourceGroups: []kueuev1beta1.ResourceGroup{
+			{
+				CoveredResources: []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory},
+				Flavors: []kueuev1beta1.FlavorQuotas{
+					{
+						Name: "default-flavor",
+						Resources: []kueuev1beta1.ResourceQuota{
+							{Name: corev1.ResourceCPU, NominalQuota: resource.MustParse("750m")},
+							{Name: corev1.ResourceMemory, NominalQuota: resource.MustParse("1536Mi")},
+						},
+					},
+				},
+			},
+		},
+	})
+	localQueue := CreateKueueLocalQueue(test, namespace.Name, clusterQueue.Name)
+
+	rayCluster := NewRayClusterBuilder(namespace.Name, "raycluster-autoscaling").
+		WithWorkerGroup("workers", autoscalingMaxReplicas, corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("250m"),
+				corev1.ResourceMemory: resource.MustParse("512Mi"),
+			},
+			Limits: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("1"),
+				corev1.ResourceMemory: resource.MustParse("2G"),
+			},
+		}, nil).
+		WithInTreeAutoscaling(autoscalingMinReplicas, autoscalingIdleTimeout).
+		Build()
+	// The head has no CPU for the tasks to be scheduled on, so that they require the workers
+	rayCluster.Spec.HeadGroupSpec.RayStartParams["num-cpus"] = "0"
+
+	aw := &awv1beta2.AppWrapper{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: awv1beta2.GroupVersion.String(),
+			Kind:       "AppWrapper",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      rayCluster.Name,
+			Namespace: namespace.Name,
+			Labels:    map[string]string{"kueue.x-k8s.io/queue-name": localQueue.Name},
+			Annotations: map[string]string{
+				// The AppWrapper expects the pods of the maximum replicas, that are missing while the workers are scaled down
+				awv1beta2.AdmissionGracePeriodDurationAnnotation: "1h",
+			},
+		},
+		Spec: awv1beta2.AppWrapperSpec{
+			Components: []awv1beta2.AppWrapperComponent{
+				{
+					Template: Raw(test, rayCluster),
+				},
+			},
+		},
+	}
+	aw = CreateAppWrapper(test, aw)
+
+	test.T().Logf("Waiting for AppWrapper %s/%s to be running", aw.Namespace, aw.Name)
+	test.Eventually(AppWrapper(test, namespace, aw.Name), TestTimeoutMedium).
+		Should(WithTransform(AppWrapperPhase, Equal(awv1beta2.AppWrapperRunning)))
+	test.Eventually(RayCluster(test, namespace.Name, rayCluster.Name), TestTimeoutMedium).
+		Should(WithTransform(RayClusterState, Equal(rayv1.Ready)))
+
+	// The workers the RayCluster is created with are idle
+	test.T().Logf("Waiting for RayCluster %s/%s to be scaled down to %d workers", rayCluster.Namespace, rayCluster.Name, autoscalingMinReplicas)
+	expectRayClusterWorkers(test, namespace.Name, rayCluster.Name, autoscalingMinReplicas)
+
+	rayClient := GetRayDashboardClient(test, rayCluster.Namespace, rayCluster.Name)
+	job, err := rayClient.SubmitJob(&RayJobSubmission{Entrypoint: autoscalingJobEntrypoint})
+	test.Expect(err).NotTo(HaveOccurred())
+	test.T().Logf("Submitted Ray job %s", job.JobID)
+
+	test.T().Logf("Waiting for RayCluster %s/%s to be scaled up to %d workers", rayCluster.Namespace, rayCluster.Name, autoscalingMaxReplicas)
+	expectRayClusterWorkers(test, namespace.Name, rayCluster.Name, autoscalingMaxReplicas)
+
+	// The scale-up fits into the quota reserved for the AppWrapper, that's the whole quota of the ClusterQueue
+	test.Expect(GetClusterQueueUsage(test, clusterQueue.Name)).To(And(
+		HaveFlavorUsage("default-flavor", corev1.ResourceCPU, "750m"),
+		HaveFlavorUsage("default-flavor", corev1.ResourceMemory, "1536Mi"),
+	))
+
+	test.T().Logf("Waiting for Ray job %s to complete", job.JobID)
+	test.Eventually(RayDashboardJob(test, rayClient, job.JobID), TestTimeoutLong).
+		Should(WithTransform(RayDashboardJobStatus, BeElementOf(RayJobStatusSucceeded, RayJobStatusFailed, RayJobStatusStopped)))
+	logs, err := rayClient.GetJobLogs(job.JobID)
+	test.Expect(err).NotTo(HaveOccurred())
+	test.T().Logf("Logs of Ray job %s:\n%s", job.JobID, logs)
+	test.Expect(RayDashboardJob(test, rayClient, job.JobID)(test)).
+		To(WithTransform(RayDashboardJobStatus, Equal(RayJobStatusSucceeded)))
+
+	test.T().Logf("Waiting for RayCluster %s/%s to be scaled down to %d workers", rayCluster.Namespace, rayCluster.Name, autoscalingMinReplicas)
+	expectRayClusterWorkers(test, namespace.Name, rayCluster.Name, autoscalingMinReplicas)
+
+	// The AppWrapper has tolerated the autoscaling
+	test.Expect(AppWrapper(test, namespace, aw.Name)(test)).To(And(
+		WithTransform(AppWrapperPhase, Equal(awv1beta2.AppWrapperRunning)),
+		HaveAppWrapperRetries(0),
+	))
+}
+
+// expectRayClusterWorkers waits for the RayCluster to have the number of desired, and running, workers.
+func expectRayClusterWorkers(test Test, namespace, name string, workers int32) {
+	test.T().Helper()
+
+	test.Eventually(RayCluster(test, namespace, name), TestTimeoutMedium).
+		Should(And(
+			WithTransform(RayClusterDesiredWorkerReplicas, Equal(workers)),
+			WithTransform(RayClusterAvailableWorkerReplicas, Equal(workers)),
+		))
+	test.Eventually(RayClusterPods(test, namespace, name), TestTimeoutMedium).
+		Should(WithTransform(ScheduledPods, Equal(1+workers)))
+}
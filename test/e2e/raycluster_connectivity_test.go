@@ -0,0 +1,78 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	. "github.com/project-codeflare/codeflare-common/support"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	. "github.com/project-codeflare/codeflare-operator/test/support"
+)
+
+// Probes a RayCluster head from inside its namespace, and asserts the head Service resolves,
+// the dashboard and client ports are reachable, and the GCS port is restricted to the
+// RayCluster pods by the head NetworkPolicy.
+func TestRayClusterInClusterConnectivity(t *testing.T) {
+	test := With(t)
+	Tags(test, Smoke)
+	test.T().Parallel()
+
+	// Create a namespace and localqueue in that namespace
+	namespace := LeaseTestNamespace(test)
+	DumpEventsOnFailure(test, namespace.Name)
+	CollectDiagnosticsOnFailure(test, namespace.Name)
+	localQueue := CreateKueueLocalQueue(test, namespace.Name, "e2e-cluster-queue")
+
+	rayCluster := NewRayClusterBuilder(namespace.Name, "raycluster-connectivity").
+		WithLabels(map[string]string{"kueue.x-k8s.io/queue-name": localQueue.Name}).
+		Build()
+	rayCluster, err := test.Client().Ray().RayV1().RayClusters(namespace.Name).Create(test.Ctx(), rayCluster, metav1.CreateOptions{})
+	test.Expect(err).NotTo(HaveOccurred())
+	test.T().Logf("Created RayCluster %s/%s successfully", rayCluster.Namespace, rayCluster.Name)
+
+	test.T().Logf("Waiting for RayCluster %s/%s to be running", rayCluster.Namespace, rayCluster.Name)
+	test.Eventually(RayCluster(test, namespace.Name, rayCluster.Name), TestTimeoutMedium).
+		Should(WithTransform(RayClusterState, Equal(rayv1.Ready)))
+
+	headService := fmt.Sprintf("%s-head-svc.%s.svc.cluster.local", rayCluster.Name, rayCluster.Namespace)
+
+	exitCode, output := RunProbePod(test, namespace.Name, GetRayImage(),
+		[]string{"python", "-c", fmt.Sprintf("import socket; print(socket.gethostbyname(%q))", headService)})
+	test.Expect(exitCode).To(BeZero(), output)
+	test.T().Logf("Head Service %s resolves to %s", headService, output)
+
+	for _, port := range []int{8265, 10001} {
+		exitCode, output := RunProbePod(test, namespace.Name, GetRayImage(), tcpProbeCommand(headService, port))
+		test.Expect(exitCode).To(BeZero(), "Expected port %d of the head to be reachable: %s", port, output)
+	}
+
+	// NetworkPolicies are not enforced by the default KinD CNI
+	if IsOpenShift(test) {
+		exitCode, _ := RunProbePod(test, namespace.Name, GetRayImage(), tcpProbeCommand(headService, 6379))
+		test.Expect(exitCode).NotTo(BeZero(), "Expected the GCS port of the head not to be reachable")
+	}
+}
+
+func tcpProbeCommand(host string, port int) []string {
+	return []string{"python", "-c", fmt.Sprintf("import socket; socket.create_connection((%q, %d), timeout=10)", host, port)}
+}
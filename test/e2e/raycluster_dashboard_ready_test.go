@@ -0,0 +1,79 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	. "github.com/project-codeflare/codeflare-common/support"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/project-codeflare/codeflare-operator/pkg/controllers"
+	. "github.com/project-codeflare/codeflare-operator/test/support"
+)
+
+// Creates a RayCluster, and asserts the operator reports the dashboard is ready, with the DashboardReady condition,
+// at the URL of its Route, once the Route serves the dashboard, so that clients don't have to poll the Route.
+func TestRayClusterDashboardReady(t *testing.T) {
+	test := With(t)
+	Tags(test, OpenShiftOnly)
+	test.T().Parallel()
+
+	// Create a namespace and localqueue in that namespace
+	namespace := LeaseTestNamespace(test)
+	DumpEventsOnFailure(test, namespace.Name)
+	CollectDiagnosticsOnFailure(test, namespace.Name)
+	localQueue := CreateKueueLocalQueue(test, namespace.Name, "e2e-cluster-queue")
+
+	rayCluster := NewRayClusterBuilder(namespace.Name, "dashboard-ready").
+		WithLabels(map[string]string{"kueue.x-k8s.io/queue-name": localQueue.Name}).
+		WithWorkerGroup("workers", 1, corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("100m"),
+				corev1.ResourceMemory: resource.MustParse("512Mi"),
+			},
+			Limits: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("1"),
+				corev1.ResourceMemory: resource.MustParse("1G"),
+			},
+		}, nil).
+		Build()
+	rayCluster, err := test.Client().Ray().RayV1().RayClusters(namespace.Name).Create(test.Ctx(), rayCluster, metav1.CreateOptions{})
+	test.Expect(err).NotTo(HaveOccurred())
+	test.T().Logf("Created RayCluster %s/%s successfully", rayCluster.Namespace, rayCluster.Name)
+
+	test.T().Logf("Waiting for RayCluster %s/%s to be running", rayCluster.Namespace, rayCluster.Name)
+	test.Eventually(RayCluster(test, namespace.Name, rayCluster.Name), TestTimeoutMedium).
+		Should(WithTransform(RayClusterState, Equal(rayv1.Ready)))
+
+	test.T().Logf("Waiting for the dashboard of RayCluster %s/%s to be ready", rayCluster.Namespace, rayCluster.Name)
+	test.Eventually(RayCluster(test, namespace.Name, rayCluster.Name), TestTimeoutShort).
+		Should(HaveCondition(controllers.DashboardReadyCondition, metav1.ConditionTrue))
+
+	route := GetRoute(test, namespace.Name, "ray-dashboard-"+rayCluster.Name)
+	test.Expect(GetRayCluster(test, namespace.Name, rayCluster.Name)).
+		To(WithTransform(RayClusterDashboardURL, Equal("https://"+route.Spec.Host)))
+
+	// The dashboard serves requests at the reported URL
+	rayClient := GetRayDashboardClient(test, rayCluster.Namespace, rayCluster.Name)
+	EventuallyRayNodes(test, rayClient, rayCluster)
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	. "github.com/project-codeflare/codeflare-common/support"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	. "github.com/project-codeflare/codeflare-operator/test/support"
+)
+
+// The job driver waits for the head to be killed, then runs a task, that can only complete once the worker
+// has reconnected to the GCS of the recreated head.
+const headFailureJobEntrypoint = `python -c "import time, ray; ray.init(); time.sleep(60); ` +
+	`print(ray.get(ray.remote(num_cpus=0)(lambda: 'Resumed after head failure').remote()))"`
+
+// Kills the head of a RayCluster with GCS fault tolerance enabled, while a Ray job is running, and asserts
+// the head is recreated, its dashboard is exposed again, and the job resumes and completes.
+func TestRayClusterHeadFailureWithGCSFaultTolerance(t *testing.T) {
+	test := With(t)
+	Tags(test, LongRunning)
+	test.T().Parallel()
+
+	// Create a namespace and localqueue in that namespace
+	namespace := LeaseTestNamespace(test)
+	DumpEventsOnFailure(test, namespace.Name)
+	CollectDiagnosticsOnFailure(test, namespace.Name)
+	localQueue := CreateKueueLocalQueue(test, namespace.Name, "e2e-cluster-queue")
+
+	redisAddress := DeployRedis(test, namespace.Name)
+
+	rayCluster := NewRayClusterBuilder(namespace.Name, "raycluster-head-failure").
+		WithLabels(map[string]string{"kueue.x-k8s.io/queue-name": localQueue.Name}).
+		WithGCSFaultTolerance(redisAddress).
+		WithWorkerGroup("workers", 1, corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("250m"),
+				corev1.ResourceMemory: resource.MustParse("1G"),
+			},
+			Limits: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("1"),
+				corev1.ResourceMemory: resource.MustParse("2G"),
+			},
+		}, nil).
+		Build()
+	// The head has no CPU for the job driver to be scheduled on, so that it runs on the worker, and survives the head failure
+	rayCluster.Spec.HeadGroupSpec.RayStartParams["num-cpus"] = "0"
+	rayCluster, err := test.Client().Ray().RayV1().RayClusters(namespace.Name).Create(test.Ctx(), rayCluster, metav1.CreateOptions{})
+	test.Expect(err).NotTo(HaveOccurred())
+	test.T().Logf("Created RayCluster %s/%s successfully", rayCluster.Namespace, rayCluster.Name)
+
+	test.T().Logf("Waiting for RayCluster %s/%s to be running", rayCluster.Namespace, rayCluster.Name)
+	test.Eventually(RayCluster(test, rayCluster.Namespace, rayCluster.Name), TestTimeoutMedium).
+		Should(WithTransform(RayClusterState, Equal(rayv1.Ready)))
+
+	rayClient := GetRayDashboardClient(test, rayCluster.Namespace, rayCluster.Name)
+	EventuallyRayNodes(test, rayClient, rayCluster)
+
+	job, err := rayClient.SubmitJob(&RayJobSubmission{
+		Entrypoint:        headFailureJobEntrypoint,
+		EntrypointNumCPUs: 1,
+	})
+	test.Expect(err).NotTo(HaveOccurred())
+	test.T().Logf("Submitted Ray job %s", job.JobID)
+
+	test.Eventually(RayDashboardJob(test, rayClient, job.JobID), TestTimeoutMedium).
+		Should(WithTransform(RayDashboardJobStatus, Equal(RayJobStatusRunning)))
+
+	// Kill the head
+	head := GetRayClusterHeadPod(test, rayCluster.Namespace, rayCluster.Name)
+	err = test.Client().Core().CoreV1().Pods(head.Namespace).Delete(test.Ctx(), head.Name, metav1.DeleteOptions{
+		GracePeriodSeconds: Ptr(int64(0)),
+	})
+	test.Expect(err).NotTo(HaveOccurred())
+	test.T().Logf("Killed head pod %s/%s while Ray job %s is running", head.Namespace, head.Name, job.JobID)
+
+	test.T().Logf("Waiting for the head of RayCluster %s/%s to be recreated", rayCluster.Namespace, rayCluster.Name)
+	test.Eventually(RayClusterHeadPod(test, rayCluster.Namespace, rayCluster.Name), TestTimeoutMedium).
+		Should(WithTransform(func(pod *corev1.Pod) types.UID { return pod.UID }, Not(Equal(head.UID))))
+	test.Eventually(RayCluster(test, rayCluster.Namespace, rayCluster.Name), TestTimeoutMedium).
+		Should(WithTransform(RayClusterState, Equal(rayv1.Ready)))
+
+	// The dashboard of the recreated head is served through a new port-forwarding, when not on OpenShift
+	rayClient = GetRayDashboardClient(test, rayCluster.Namespace, rayCluster.Name)
+	EventuallyRayNodes(test, rayClient, rayCluster)
+
+	test.T().Logf("Waiting for Ray job %s to complete", job.JobID)
+	test.Eventually(RayDashboardJob(test, rayClient, job.JobID), TestTimeoutLong).
+		Should(WithTransform(RayDashboardJobStatus, BeElementOf(RayJobStatusSucceeded, RayJobStatusFailed, RayJobStatusStopped)))
+
+	logs, err := rayClient.GetJobLogs(job.JobID)
+	test.Expect(err).NotTo(HaveOccurred())
+	test.T().Logf("Logs of Ray job %s:\n%s", job.JobID, logs)
+	test.Expect(RayDashboardJob(test, rayClient, job.JobID)(test)).
+		To(WithTransform(RayDashboardJobStatus, Equal(RayJobStatusSucceeded)))
+	test.Expect(logs).To(ContainSubstring("Resumed after head failure"))
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	. "github.com/project-codeflare/codeflare-common/support"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	. "github.com/project-codeflare/codeflare-operator/test/support"
+)
+
+// Creates a RayCluster, with mTLS enabled, and asserts the Ray nodes have joined the Ray cluster over the IPs of their
+// pods, whatever the IP families of the cluster, so that the IPv6-only, and dual-stack, clusters, are covered when the
+// tests run on them. The Ray nodes only join once the certificates, generated for the IPs of the pods, are verified.
+func TestRayClusterIPFamilies(t *testing.T) {
+	test := With(t)
+	test.T().Parallel()
+
+	// Create a namespace and localqueue in that namespace
+	namespace := LeaseTestNamespace(test)
+	DumpEventsOnFailure(test, namespace.Name)
+	CollectDiagnosticsOnFailure(test, namespace.Name)
+	localQueue := CreateKueueLocalQueue(test, namespace.Name, "e2e-cluster-queue")
+
+	rayCluster := NewRayClusterBuilder(namespace.Name, "ip-families").
+		WithLabels(map[string]string{"kueue.x-k8s.io/queue-name": localQueue.Name}).
+		WithWorkerGroup("workers", 1, corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("100m"),
+				corev1.ResourceMemory: resource.MustParse("512Mi"),
+			},
+			Limits: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("1"),
+				corev1.ResourceMemory: resource.MustParse("1G"),
+			},
+		}, nil).
+		Build()
+	rayCluster, err := test.Client().Ray().RayV1().RayClusters(namespace.Name).Create(test.Ctx(), rayCluster, metav1.CreateOptions{})
+	test.Expect(err).NotTo(HaveOccurred())
+	test.T().Logf("Created RayCluster %s/%s successfully", rayCluster.Namespace, rayCluster.Name)
+
+	test.T().Logf("Waiting for RayCluster %s/%s to be running", rayCluster.Namespace, rayCluster.Name)
+	test.Eventually(RayCluster(test, namespace.Name, rayCluster.Name), TestTimeoutMedium).
+		Should(WithTransform(RayClusterState, Equal(rayv1.Ready)))
+
+	pods := GetPods(test, namespace.Name, metav1.ListOptions{LabelSelector: "ray.io/cluster=" + rayCluster.Name})
+	test.Expect(pods).To(HaveLen(2))
+	var podIPs []string
+	for i := range pods {
+		test.T().Logf("Pod %s/%s has IPs %v", pods[i].Namespace, pods[i].Name, PodIPs(&pods[i]))
+		test.Expect(PodIPFamilies(&pods[i])).NotTo(ContainElement(BeEmpty()))
+		podIPs = append(podIPs, PodIPs(&pods[i])...)
+	}
+
+	// The head Service is reachable over the families of the head pod IPs
+	service, err := test.Client().Core().CoreV1().Services(namespace.Name).Get(test.Ctx(), rayCluster.Name+"-head-svc", metav1.GetOptions{})
+	test.Expect(err).NotTo(HaveOccurred())
+	test.T().Logf("Service %s/%s has IP families %v", service.Namespace, service.Name, service.Spec.IPFamilies)
+	for i := range pods {
+		if pods[i].Labels["ray.io/node-type"] == string(rayv1.HeadNode) {
+			test.Expect(PodIPFamilies(&pods[i])).To(ContainElements(service.Spec.IPFamilies))
+		}
+	}
+
+	// The dashboard URL is bracketed when it's reached over an IPv6 address
+	rayClient := GetRayDashboardClient(test, rayCluster.Namespace, rayCluster.Name)
+	test.T().Logf("RayCluster %s/%s dashboard is available at: %s", rayCluster.Namespace, rayCluster.Name, rayClient.Endpoint().String())
+	EventuallyRayNodes(test, rayClient, rayCluster)
+
+	// The Ray nodes are addressed with the IP of their pods, i.e., the MY_POD_IP environment variable
+	test.Expect(RayNodesAlive(RayNodes(test, rayClient)(test))).To(HaveEach(
+		WithTransform(func(node RayNode) string { return node.NodeIP }, BeElementOf(podIPs)),
+	))
+}
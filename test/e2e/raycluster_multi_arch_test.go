@@ -0,0 +1,86 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	. "github.com/project-codeflare/codeflare-common/support"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	. "github.com/project-codeflare/codeflare-operator/test/support"
+)
+
+// Creates a RayCluster whose head runs on amd64 nodes, and workers on arm64 nodes, and asserts the Ray nodes of both
+// architectures have joined the Ray cluster. The Ray image must either be multi-arch, or be mapped to an arm64 image
+// with the kuberay.rayImages configuration of the operator.
+func TestRayClusterMultiArchitecture(t *testing.T) {
+	test := With(t)
+	test.T().Parallel()
+
+	architectures := DetectArchitectures(test)
+	if len(architectures["amd64"]) == 0 || len(architectures["arm64"]) == 0 {
+		test.T().Skipf("The test requires amd64 and arm64 nodes, detected architectures: %v", architectures)
+	}
+
+	// Create a namespace and localqueue in that namespace
+	namespace := LeaseTestNamespace(test)
+	DumpEventsOnFailure(test, namespace.Name)
+	CollectDiagnosticsOnFailure(test, namespace.Name)
+	localQueue := CreateKueueLocalQueue(test, namespace.Name, "e2e-cluster-queue")
+
+	rayCluster := NewRayClusterBuilder(namespace.Name, "multi-arch").
+		WithLabels(map[string]string{"kueue.x-k8s.io/queue-name": localQueue.Name}).
+		WithWorkerGroup("arm64", 1, corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("100m"),
+				corev1.ResourceMemory: resource.MustParse("512Mi"),
+			},
+			Limits: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("1"),
+				corev1.ResourceMemory: resource.MustParse("1G"),
+			},
+		}, nil).
+		Build()
+	rayCluster.Spec.HeadGroupSpec.Template.Spec.NodeSelector = map[string]string{corev1.LabelArchStable: "amd64"}
+	rayCluster.Spec.WorkerGroupSpecs[0].Template.Spec.NodeSelector = map[string]string{corev1.LabelArchStable: "arm64"}
+	rayCluster, err := test.Client().Ray().RayV1().RayClusters(namespace.Name).Create(test.Ctx(), rayCluster, metav1.CreateOptions{})
+	test.Expect(err).NotTo(HaveOccurred())
+	test.T().Logf("Created RayCluster %s/%s successfully", rayCluster.Namespace, rayCluster.Name)
+	test.T().Logf("RayCluster %s/%s runs image %s on amd64 nodes, and image %s on arm64 nodes", rayCluster.Namespace, rayCluster.Name,
+		rayCluster.Spec.HeadGroupSpec.Template.Spec.Containers[0].Image, rayCluster.Spec.WorkerGroupSpecs[0].Template.Spec.Containers[0].Image)
+
+	test.T().Logf("Waiting for RayCluster %s/%s to be running", rayCluster.Namespace, rayCluster.Name)
+	test.Eventually(RayCluster(test, namespace.Name, rayCluster.Name), TestTimeoutMedium).
+		Should(WithTransform(RayClusterState, Equal(rayv1.Ready)))
+
+	// Each Ray node runs on a node of the architecture of its group
+	for _, pod := range GetPods(test, namespace.Name, metav1.ListOptions{LabelSelector: "ray.io/cluster=" + rayCluster.Name}) {
+		node, err := test.Client().Core().CoreV1().Nodes().Get(test.Ctx(), pod.Spec.NodeName, metav1.GetOptions{})
+		test.Expect(err).NotTo(HaveOccurred())
+		test.Expect(node.Labels).To(HaveKeyWithValue(corev1.LabelArchStable, pod.Spec.NodeSelector[corev1.LabelArchStable]),
+			"pod %s/%s runs on node %s", pod.Namespace, pod.Name, node.Name)
+	}
+
+	rayClient := GetRayDashboardClient(test, rayCluster.Namespace, rayCluster.Name)
+	EventuallyRayNodes(test, rayClient, rayCluster)
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	. "github.com/project-codeflare/codeflare-common/support"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kueuev1beta1 "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+
+	"github.com/project-codeflare/codeflare-operator/pkg/controllers"
+	. "github.com/project-codeflare/codeflare-operator/test/support"
+)
+
+// Submits a RayCluster to a ClusterQueue with a ProvisioningRequest AdmissionCheck, and asserts it stays suspended,
+// without pods, and reports it waits for its nodes to be provisioned, until the ProvisioningRequest is provisioned,
+// then that it's admitted and running. The ProvisioningRequest is provisioned by the test, rather than by the cluster
+// autoscaler, so that the test doesn't depend on the cluster being scaled.
+func TestRayClusterWaitsForNodeProvisioning(t *testing.T) {
+	test := With(t)
+	test.T().Parallel()
+
+	if !IsProvisioningRequestAvailable(test) {
+		test.T().Skip("The ProvisioningRequest API of the cluster autoscaler isn't installed")
+	}
+
+	admissionCheck := CreateTestKueueProvisioningAdmissionCheck(test, "check-capacity.autoscaling.x-k8s.io")
+	clusterQueue := CreateTestKueueClusterQueue(test, kueuev1beta1.ClusterQueueSpec{
+		NamespaceSelector: &metav1.LabelSelector{},
+		AdmissionChecks:   []string{admissionCheck.Name},
+		ResourceGroups: []kueuev1beta1.ResourceGroup{
+			{
+				CoveredResources: []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory},
+				Flavors: []kueuev1beta1.FlavorQuotas{
+					{
+						Name: "default-flavor",
+						Resources: []kueuev1beta1.ResourceQuota{
+							{Name: corev1.ResourceCPU, NominalQuota: resource.MustParse("4")},
+							{Name: corev1.ResourceMemory, NominalQuota: resource.MustParse("8Gi")},
+						},
+					},
+				},
+			},
+		},
+	})
+
+	// Create a namespace and localqueue in that namespace
+	namespace := LeaseTestNamespace(test)
+	DumpEventsOnFailure(test, namespace.Name)
+	CollectDiagnosticsOnFailure(test, namespace.Name)
+	localQueue := CreateKueueLocalQueue(test, namespace.Name, clusterQueue.Name)
+
+	rayCluster := NewRayClusterBuilder(namespace.Name, "raycluster-provisioning").
+		WithLabels(map[string]string{"kueue.x-k8s.io/queue-name": localQueue.Name}).
+		WithWorkerGroup("workers", 1, corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("100m"),
+				corev1.ResourceMemory: resource.MustParse("256Mi"),
+			},
+			Limits: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("1"),
+				corev1.ResourceMemory: resource.MustParse("1G"),
+			},
+		}, nil).
+		Build()
+	rayCluster, err := test.Client().Ray().RayV1().RayClusters(namespace.Name).Create(test.Ctx(), rayCluster, metav1.CreateOptions{})
+	test.Expect(err).NotTo(HaveOccurred())
+	test.T().Logf("Created RayCluster %s/%s successfully", rayCluster.Namespace, rayCluster.Name)
+
+	test.T().Logf("Waiting for the ProvisioningRequest of RayCluster %s/%s to be created", rayCluster.Namespace, rayCluster.Name)
+	test.Eventually(ProvisioningRequests(test, namespace.Name), TestTimeoutMedium).Should(HaveLen(1))
+
+	test.T().Logf("Checking RayCluster %s/%s waits for its nodes to be provisioned", rayCluster.Namespace, rayCluster.Name)
+	test.Eventually(RayCluster(test, namespace.Name, rayCluster.Name), TestTimeoutShort).
+		Should(HaveCondition(controllers.AdmissionChecksReadyCondition, metav1.ConditionFalse))
+	test.Consistently(func(g Gomega) {
+		g.Expect(RayCluster(test, namespace.Name, rayCluster.Name)(g).Spec.Suspend).To(HaveValue(BeTrue()))
+		g.Expect(namespacePods(test, namespace.Name)(g)).To(BeEmpty())
+	}, TestTimeoutShort).Should(Succeed())
+
+	provisioningRequests := ProvisioningRequests(test, namespace.Name)(test)
+	SetProvisioningRequestProvisioned(test, &provisioningRequests[0])
+
+	test.T().Logf("Waiting for RayCluster %s/%s to be admitted", rayCluster.Namespace, rayCluster.Name)
+	test.Eventually(WorkloadForRayCluster(test, rayCluster), TestTimeoutMedium).
+		Should(WithTransform(WorkloadAdmitted, BeTrue()))
+	test.Eventually(RayCluster(test, namespace.Name, rayCluster.Name), TestTimeoutMedium).
+		Should(HaveCondition(controllers.AdmissionChecksReadyCondition, metav1.ConditionTrue))
+
+	test.T().Logf("Waiting for RayCluster %s/%s to be running", rayCluster.Namespace, rayCluster.Name)
+	test.Eventually(RayCluster(test, namespace.Name, rayCluster.Name), TestTimeoutLong).
+		Should(WithTransform(RayClusterState, Equal(rayv1.Ready)))
+}
@@ -0,0 +1,185 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	. "github.com/project-codeflare/codeflare-common/support"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	routev1 "github.com/openshift/api/route/v1"
+
+	"github.com/project-codeflare/codeflare-operator/pkg/controllers"
+	. "github.com/project-codeflare/codeflare-operator/test/support"
+)
+
+const suspendResumeCycles = 2
+
+// Suspends and resumes a RayCluster a few times through its spec, and asserts
+// the dashboard and client endpoints are removed while it's suspended,
+// and recreated once it's resumed.
+func TestRayClusterSuspendResume(t *testing.T) {
+	test := With(t)
+	Tags(test, Smoke)
+	test.T().Parallel()
+
+	namespace := LeaseTestNamespace(test)
+	DumpEventsOnFailure(test, namespace.Name)
+	CollectDiagnosticsOnFailure(test, namespace.Name)
+
+	rayCluster := NewRayClusterBuilder(namespace.Name, "raycluster-suspend").
+		WithWorkerGroup("workers", 1, suspendTestResources(), nil).
+		Build()
+	rayCluster, err := test.Client().Ray().RayV1().RayClusters(namespace.Name).Create(test.Ctx(), rayCluster, metav1.CreateOptions{})
+	test.Expect(err).NotTo(HaveOccurred())
+	test.T().Logf("Created RayCluster %s/%s successfully", rayCluster.Namespace, rayCluster.Name)
+
+	expectRayClusterResumed(test, rayCluster)
+
+	for i := 0; i < suspendResumeCycles; i++ {
+		setRayClusterSuspend(test, rayCluster, true)
+		expectRayClusterSuspended(test, rayCluster)
+
+		setRayClusterSuspend(test, rayCluster, false)
+		expectRayClusterResumed(test, rayCluster)
+	}
+}
+
+// Evicts the Kueue Workload of a RayCluster by deactivating it, and asserts the RayCluster
+// is suspended by Kueue, and resumed once the Workload is activated and re-admitted.
+func TestRayClusterKueueEviction(t *testing.T) {
+	test := With(t)
+	test.T().Parallel()
+
+	// Create a namespace and localqueue in that namespace
+	namespace := LeaseTestNamespace(test)
+	DumpEventsOnFailure(test, namespace.Name)
+	CollectDiagnosticsOnFailure(test, namespace.Name)
+	localQueue := CreateKueueLocalQueue(test, namespace.Name, "e2e-cluster-queue")
+
+	rayCluster := NewRayClusterBuilder(namespace.Name, "raycluster-eviction").
+		WithLabels(map[string]string{"kueue.x-k8s.io/queue-name": localQueue.Name}).
+		WithWorkerGroup("workers", 1, suspendTestResources(), nil).
+		Build()
+	rayCluster, err := test.Client().Ray().RayV1().RayClusters(namespace.Name).Create(test.Ctx(), rayCluster, metav1.CreateOptions{})
+	test.Expect(err).NotTo(HaveOccurred())
+	test.T().Logf("Created RayCluster %s/%s successfully", rayCluster.Namespace, rayCluster.Name)
+
+	expectRayClusterResumed(test, rayCluster)
+
+	workloadName := GetWorkloadForRayCluster(test, rayCluster).Name
+
+	for i := 0; i < suspendResumeCycles; i++ {
+		setKueueWorkloadActive(test, namespace.Name, workloadName, false)
+		expectRayClusterSuspended(test, rayCluster)
+
+		setKueueWorkloadActive(test, namespace.Name, workloadName, true)
+		expectRayClusterResumed(test, rayCluster)
+	}
+}
+
+func suspendTestResources() corev1.ResourceRequirements {
+	return corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("250m"),
+			corev1.ResourceMemory: resource.MustParse("256Mi"),
+		},
+		Limits: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("1"),
+			corev1.ResourceMemory: resource.MustParse("1G"),
+		},
+	}
+}
+
+func setRayClusterSuspend(test Test, rayCluster *rayv1.RayCluster, suspend bool) {
+	test.T().Helper()
+
+	patch := []byte(fmt.Sprintf(`{"spec":{"suspend":%t}}`, suspend))
+	RetryOnConflictOrTransient(test, func() error {
+		_, err := test.Client().Ray().RayV1().RayClusters(rayCluster.Namespace).Patch(test.Ctx(), rayCluster.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+		return err
+	})
+	test.T().Logf("Set RayCluster %s/%s suspend to %t", rayCluster.Namespace, rayCluster.Name, suspend)
+}
+
+func setKueueWorkloadActive(test Test, namespace, name string, active bool) {
+	test.T().Helper()
+
+	patch := []byte(fmt.Sprintf(`{"spec":{"active":%t}}`, active))
+	RetryOnConflictOrTransient(test, func() error {
+		_, err := test.Client().Kueue().KueueV1beta1().Workloads(namespace).Patch(test.Ctx(), name, types.MergePatchType, patch, metav1.PatchOptions{})
+		return err
+	})
+	test.T().Logf("Set Kueue Workload %s/%s active to %t", namespace, name, active)
+}
+
+func expectRayClusterSuspended(test Test, rayCluster *rayv1.RayCluster) {
+	test.T().Helper()
+
+	test.T().Logf("Waiting for RayCluster %s/%s to be suspended", rayCluster.Namespace, rayCluster.Name)
+	test.Eventually(RayCluster(test, rayCluster.Namespace, rayCluster.Name), TestTimeoutMedium).
+		Should(And(
+			WithTransform(RayClusterState, Equal(rayv1.Suspended)),
+			HaveCondition(controllers.SuspendedCondition, metav1.ConditionTrue),
+		))
+	ExpectAllOrNothingScheduled(test, rayCluster)
+
+	dashboardName := "ray-dashboard-" + rayCluster.Name
+	rayClientName := "rayclient-" + rayCluster.Name
+	if IsOpenShift(test) {
+		test.Eventually(routeNotFound(test, rayCluster.Namespace, dashboardName), TestTimeoutShort).Should(BeTrue())
+		test.Eventually(routeNotFound(test, rayCluster.Namespace, rayClientName), TestTimeoutShort).Should(BeTrue())
+	}
+	test.Eventually(ingressNotFound(test, rayCluster.Namespace, dashboardName), TestTimeoutShort).Should(BeTrue())
+	test.Eventually(ingressNotFound(test, rayCluster.Namespace, rayClientName), TestTimeoutShort).Should(BeTrue())
+}
+
+func expectRayClusterResumed(test Test, rayCluster *rayv1.RayCluster) {
+	test.T().Helper()
+
+	test.T().Logf("Waiting for RayCluster %s/%s to be running", rayCluster.Namespace, rayCluster.Name)
+	test.Eventually(RayCluster(test, rayCluster.Namespace, rayCluster.Name), TestTimeoutMedium).
+		Should(And(
+			WithTransform(RayClusterState, Equal(rayv1.Ready)),
+			Not(HaveCondition(controllers.SuspendedCondition, metav1.ConditionTrue)),
+		))
+	ExpectAllOrNothingScheduled(test, rayCluster)
+
+	// The endpoints are recreated asynchronously once the RayCluster is resumed
+	dashboardName := "ray-dashboard-" + rayCluster.Name
+	if IsOpenShift(test) {
+		test.Eventually(Route(test, rayCluster.Namespace, dashboardName), TestTimeoutShort).
+			Should(WithTransform(routeIngresses, Not(BeEmpty())))
+	} else {
+		test.Eventually(ingressNotFound(test, rayCluster.Namespace, dashboardName), TestTimeoutShort).Should(BeFalse())
+	}
+
+	rayDashboardURL := GetRayDashboardURL(test, rayCluster.Namespace, rayCluster.Name)
+	test.T().Logf("RayCluster %s/%s dashboard is available at: %s", rayCluster.Namespace, rayCluster.Name, rayDashboardURL.String())
+}
+
+func routeIngresses(route *routev1.Route) []routev1.RouteIngress {
+	return route.Status.Ingress
+}
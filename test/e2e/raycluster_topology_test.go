@@ -0,0 +1,178 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	"slices"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	. "github.com/project-codeflare/codeflare-common/support"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	kueuev1beta1 "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+
+	"github.com/project-codeflare/codeflare-operator/pkg/defaults"
+	. "github.com/project-codeflare/codeflare-operator/test/support"
+)
+
+var (
+	kueueTopologyResource       = schema.GroupVersionResource{Group: "kueue.x-k8s.io", Version: "v1alpha1", Resource: "topologies"}
+	kueueResourceFlavorResource = schema.GroupVersionResource{Group: "kueue.x-k8s.io", Version: "v1beta1", Resource: "resourceflavors"}
+)
+
+// Creates a RayCluster in a namespace whose topology policy requires the workers to be placed on a single node,
+// and asserts the webhook stamps the topology on the worker pod template, and Kueue Topology-Aware Scheduling
+// places all the workers within the requested topology domain.
+func TestRayClusterTopologyAwareScheduling(t *testing.T) {
+	test := With(t)
+	test.T().Parallel()
+
+	if !isKueueTopologyAvailable(test) {
+		test.T().Skip("Kueue Topology-Aware Scheduling isn't enabled")
+	}
+
+	// Use a dedicated topology and flavor, with a single hostname level, so that any cluster has a domain per node
+	topologyName := createKueueTopology(test, corev1.LabelHostname)
+	flavorName := createKueueTopologyResourceFlavor(test, topologyName)
+	clusterQueue := CreateTestKueueClusterQueue(test, kueuev1beta1.ClusterQueueSpec{
+		NamespaceSelector: &metav1.LabelSelector{},
+		ResourceGroups: []kueuev1beta1.ResourceGroup{
+			{
+				CoveredResources: []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory},
+				Flavors: []kueuev1beta1.FlavorQuotas{
+					{
+						Name: kueuev1beta1.ResourceFlavorReference(flavorName),
+						Resources: []kueuev1beta1.ResourceQuota{
+							{Name: corev1.ResourceCPU, NominalQuota: resource.MustParse("4")},
+							{Name: corev1.ResourceMemory, NominalQuota: resource.MustParse("8Gi")},
+						},
+					},
+				},
+			},
+		},
+	})
+
+	// Create a namespace, with the topology policy, and localqueue in that namespace
+	namespace := LeaseTestNamespace(test)
+	DumpEventsOnFailure(test, namespace.Name)
+	CollectDiagnosticsOnFailure(test, namespace.Name)
+	namespace.Annotations = map[string]string{defaults.RequiredTopologyAnnotation: corev1.LabelHostname}
+	namespace, err := test.Client().Core().CoreV1().Namespaces().Update(test.Ctx(), namespace, metav1.UpdateOptions{})
+	test.Expect(err).NotTo(HaveOccurred())
+	localQueue := CreateKueueLocalQueue(test, namespace.Name, clusterQueue.Name)
+
+	rayCluster := NewRayClusterBuilder(namespace.Name, "raycluster-topology").
+		WithLabels(map[string]string{"kueue.x-k8s.io/queue-name": localQueue.Name}).
+		WithWorkerGroup("workers", 2, corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("100m"),
+				corev1.ResourceMemory: resource.MustParse("256Mi"),
+			},
+			Limits: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("1"),
+				corev1.ResourceMemory: resource.MustParse("1G"),
+			},
+		}, nil).
+		Build()
+	rayCluster, err = test.Client().Ray().RayV1().RayClusters(namespace.Name).Create(test.Ctx(), rayCluster, metav1.CreateOptions{})
+	test.Expect(err).NotTo(HaveOccurred())
+	test.T().Logf("Created RayCluster %s/%s successfully", rayCluster.Namespace, rayCluster.Name)
+
+	test.Expect(rayCluster.Spec.WorkerGroupSpecs[0].Template.Annotations).
+		To(HaveKeyWithValue(defaults.KueuePodSetRequiredTopologyAnnotation, corev1.LabelHostname))
+
+	test.T().Logf("Waiting for RayCluster %s/%s to be running", rayCluster.Namespace, rayCluster.Name)
+	test.Eventually(RayCluster(test, namespace.Name, rayCluster.Name), TestTimeoutLong).
+		Should(WithTransform(RayClusterState, Equal(rayv1.Ready)))
+
+	// All the workers are placed within a single node
+	workers := GetPods(test, namespace.Name, metav1.ListOptions{
+		LabelSelector: "ray.io/cluster=" + rayCluster.Name + ",ray.io/node-type=" + string(rayv1.WorkerNode),
+	})
+	test.Expect(workers).To(HaveLen(2))
+	test.Expect(workers[1].Spec.NodeName).To(Equal(workers[0].Spec.NodeName))
+}
+
+// isKueueTopologyAvailable returns whether the Topology API of Kueue is served, i.e., Topology-Aware Scheduling is enabled.
+func isKueueTopologyAvailable(test Test) bool {
+	test.T().Helper()
+
+	resources, err := test.Client().Core().Discovery().ServerResourcesForGroupVersion(kueueTopologyResource.GroupVersion().String())
+	if apierrors.IsNotFound(err) {
+		return false
+	}
+	test.Expect(err).NotTo(HaveOccurred())
+	return slices.ContainsFunc(resources.APIResources, func(resource metav1.APIResource) bool {
+		return resource.Name == kueueTopologyResource.Resource
+	})
+}
+
+// createKueueTopology creates a Kueue Topology with the levels, that's deleted once the test completes.
+// It's unstructured, as the Kueue API the operator depends on predates Topology-Aware Scheduling.
+func createKueueTopology(test Test, levels ...string) string {
+	test.T().Helper()
+
+	var nodeLabels []any
+	for _, level := range levels {
+		nodeLabels = append(nodeLabels, map[string]any{"nodeLabel": level})
+	}
+	topology := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": kueueTopologyResource.GroupVersion().String(),
+		"kind":       "Topology",
+		"metadata":   map[string]any{"generateName": "topology-"},
+		"spec":       map[string]any{"levels": nodeLabels},
+	}}
+	topology, err := test.Client().Dynamic().Resource(kueueTopologyResource).Create(test.Ctx(), topology, metav1.CreateOptions{})
+	test.Expect(err).NotTo(HaveOccurred())
+	test.T().Cleanup(func() {
+		test.Expect(test.Client().Dynamic().Resource(kueueTopologyResource).Delete(test.Ctx(), topology.GetName(), metav1.DeleteOptions{})).To(Succeed())
+	})
+	test.T().Logf("Created Kueue Topology %s successfully", topology.GetName())
+
+	return topology.GetName()
+}
+
+// createKueueTopologyResourceFlavor creates a ResourceFlavor of all the Linux nodes, with the topology,
+// that's deleted once the test completes.
+func createKueueTopologyResourceFlavor(test Test, topologyName string) string {
+	test.T().Helper()
+
+	flavor := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": kueueResourceFlavorResource.GroupVersion().String(),
+		"kind":       "ResourceFlavor",
+		"metadata":   map[string]any{"generateName": "rf-topology-"},
+		"spec": map[string]any{
+			"nodeLabels":   map[string]any{corev1.LabelOSStable: "linux"},
+			"topologyName": topologyName,
+		},
+	}}
+	flavor, err := test.Client().Dynamic().Resource(kueueResourceFlavorResource).Create(test.Ctx(), flavor, metav1.CreateOptions{})
+	test.Expect(err).NotTo(HaveOccurred())
+	test.T().Cleanup(func() {
+		test.Expect(test.Client().Dynamic().Resource(kueueResourceFlavorResource).Delete(test.Ctx(), flavor.GetName(), metav1.DeleteOptions{})).To(Succeed())
+	})
+	test.T().Logf("Created Kueue ResourceFlavor %s successfully", flavor.GetName())
+
+	return flavor.GetName()
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	. "github.com/project-codeflare/codeflare-common/support"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	. "github.com/project-codeflare/codeflare-operator/test/support"
+)
+
+const (
+	workerGroupsCount = 12
+	// The maximum duration of the RayCluster creation request, which goes through the admission webhooks
+	maxAdmissionLatency = 5 * time.Second
+)
+
+// Creates a RayCluster with many heterogeneous worker groups, and asserts it's admitted
+// by the webhooks, the Kueue Workload has a PodSet per group, the dashboard is exposed,
+// and the status aggregates the replicas of all the worker groups.
+func TestRayClusterManyWorkerGroups(t *testing.T) {
+	test := With(t)
+	test.T().Parallel()
+
+	// Create a namespace and localqueue in that namespace
+	namespace := LeaseTestNamespace(test)
+	DumpEventsOnFailure(test, namespace.Name)
+	CollectDiagnosticsOnFailure(test, namespace.Name)
+	localQueue := CreateKueueLocalQueue(test, namespace.Name, "e2e-cluster-queue")
+
+	builder := NewRayClusterBuilder(namespace.Name, "raycluster-many-groups").
+		WithLabels(map[string]string{"kueue.x-k8s.io/queue-name": localQueue.Name})
+	accelerators := []string{"none", "nvidia-tesla-t4", "nvidia-a100"}
+	for i := 0; i < workerGroupsCount; i++ {
+		// Alternate sizes, kept small so that all the groups fit in the e2e ClusterQueue quota
+		cpu := resource.MustParse("100m")
+		if i%2 == 1 {
+			cpu = resource.MustParse("150m")
+		}
+		builder.WithWorkerGroup(fmt.Sprintf("group-%d", i), 1,
+			corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    cpu,
+					corev1.ResourceMemory: resource.MustParse("200Mi"),
+				},
+				Limits: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("1"),
+					corev1.ResourceMemory: resource.MustParse("1G"),
+				},
+			},
+			map[string]string{"codeflare.dev/accelerator": accelerators[i%len(accelerators)]},
+		)
+	}
+
+	start := time.Now()
+	rayCluster, err := test.Client().Ray().RayV1().RayClusters(namespace.Name).Create(test.Ctx(), builder.Build(), metav1.CreateOptions{})
+	latency := time.Since(start)
+	test.Expect(err).NotTo(HaveOccurred())
+	test.T().Logf("Created RayCluster %s/%s with %d worker groups successfully in %s", rayCluster.Namespace, rayCluster.Name, workerGroupsCount, latency)
+	test.Expect(latency).To(BeNumerically("<", maxAdmissionLatency))
+
+	// All the worker groups have been defaulted by the webhook
+	test.Expect(rayCluster.Spec.WorkerGroupSpecs).To(HaveLen(workerGroupsCount))
+
+	test.T().Logf("Waiting for the Kueue Workload of RayCluster %s/%s to be admitted", rayCluster.Namespace, rayCluster.Name)
+	test.Eventually(WorkloadForRayCluster(test, rayCluster), TestTimeoutMedium).
+		Should(WithTransform(WorkloadAdmitted, BeTrue()))
+	// One PodSet for the head, and one for each worker group
+	test.Expect(GetWorkloadForRayCluster(test, rayCluster).Spec.PodSets).To(HaveLen(workerGroupsCount + 1))
+
+	test.T().Logf("Waiting for RayCluster %s/%s to be running", rayCluster.Namespace, rayCluster.Name)
+	test.Eventually(RayCluster(test, namespace.Name, rayCluster.Name), TestTimeoutLong).
+		Should(WithTransform(RayClusterState, Equal(rayv1.Ready)))
+
+	test.Expect(GetRayCluster(test, namespace.Name, rayCluster.Name)).
+		To(WithTransform(RayClusterAvailableWorkerReplicas, Equal(RayClusterDesiredWorkerReplicas(rayCluster))))
+
+	// The workers must also have joined the Ray cluster, not only be ready
+	rayClient := GetRayDashboardClient(test, rayCluster.Namespace, rayCluster.Name)
+	test.T().Logf("RayCluster %s/%s dashboard is available at: %s", rayCluster.Namespace, rayCluster.Name, rayClient.Endpoint().String())
+	EventuallyRayNodes(test, rayClient, rayCluster)
+}
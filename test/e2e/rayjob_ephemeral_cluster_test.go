@@ -0,0 +1,197 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	. "github.com/project-codeflare/codeflare-common/support"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	kueuev1beta1 "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+
+	. "github.com/project-codeflare/codeflare-operator/test/support"
+)
+
+// Submits a RayJob with an inline RayCluster spec, that KubeRay creates for the job, and tears down once it's
+// finished, and asserts Kueue gates the whole job, i.e., neither the RayCluster nor the submitter are created until
+// the Workload of the RayJob is admitted, then that the RayCluster, its pods, and the resources the operator created
+// for it, are deleted once the job has succeeded, and its quota released.
+func TestRayJobEphemeralCluster(t *testing.T) {
+	test := With(t)
+	test.T().Parallel()
+
+	// Use a dedicated ClusterQueue, that holds the admission of the workloads until it's released
+	clusterQueue := CreateTestKueueClusterQueue(test, kueuev1beta1.ClusterQueueSpec{
+		NamespaceSelector: &metav1.LabelSelector{},
+		StopPolicy:        ptr.To(kueuev1beta1.Hold),
+		ResourceGroups: []kueuev1beta1.ResourceGroup{
+			{
+				CoveredResources: []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory},
+				Flavors: []kueuev1beta1.FlavorQuotas{
+					{
+						Name: "default-flavor",
+						Resources: []kueuev1beta1.ResourceQuota{
+							{Name: corev1.ResourceCPU, NominalQuota: resource.MustParse("2")},
+							{Name: corev1.ResourceMemory, NominalQuota: resource.MustParse("4G")},
+						},
+					},
+				},
+			},
+		},
+	})
+
+	// Create a namespace and localqueue in that namespace
+	namespace := LeaseTestNamespace(test)
+	DumpEventsOnFailure(test, namespace.Name)
+	CollectDiagnosticsOnFailure(test, namespace.Name)
+	localQueue := CreateKueueLocalQueue(test, namespace.Name, clusterQueue.Name)
+
+	rayJob := &rayv1.RayJob{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: rayv1.GroupVersion.String(),
+			Kind:       "RayJob",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ephemeral",
+			Namespace: namespace.Name,
+			Labels:    map[string]string{"kueue.x-k8s.io/queue-name": localQueue.Name},
+		},
+		Spec: rayv1.RayJobSpec{
+			Entrypoint: `python -c "import ray; ray.init(); print(ray.cluster_resources())"`,
+			RayClusterSpec: func() *rayv1.RayClusterSpec {
+				spec := NewRayClusterBuilder(namespace.Name, "ephemeral").
+					WithWorkerGroup("workers", 1, suspendTestResources(), nil).
+					Build().Spec
+				return &spec
+			}(),
+			ShutdownAfterJobFinishes: true,
+			SubmitterPodTemplate: &corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyNever,
+					Containers: []corev1.Container{
+						{
+							Image: GetRayImage(),
+							Name:  "rayjob-submitter-pod",
+							Resources: corev1.ResourceRequirements{
+								Requests: corev1.ResourceList{
+									corev1.ResourceCPU:    resource.MustParse("200m"),
+									corev1.ResourceMemory: resource.MustParse("256Mi"),
+								},
+								Limits: corev1.ResourceList{
+									corev1.ResourceCPU:    resource.MustParse("200m"),
+									corev1.ResourceMemory: resource.MustParse("256Mi"),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	rayJob, err := test.Client().Ray().RayV1().RayJobs(namespace.Name).Create(test.Ctx(), rayJob, metav1.CreateOptions{})
+	test.Expect(err).NotTo(HaveOccurred())
+	test.T().Logf("Created RayJob %s/%s successfully", rayJob.Namespace, rayJob.Name)
+
+	// Nothing is created for the RayJob while its Workload is pending
+	test.Eventually(WorkloadForRayJob(test, rayJob), TestTimeoutShort).
+		Should(WithTransform(WorkloadAdmitted, BeFalse()))
+	test.T().Logf("Checking RayJob %s/%s is gated by Kueue", rayJob.Namespace, rayJob.Name)
+	test.Consistently(func(g Gomega) {
+		g.Expect(RayJob(test, rayJob.Namespace, rayJob.Name)(g).Spec.Suspend).To(BeTrue())
+		g.Expect(RayClusters(test, namespace.Name)(g)).To(BeEmpty())
+		g.Expect(namespacePods(test, namespace.Name)(g)).To(BeEmpty())
+	}, TestTimeoutShort).Should(Succeed())
+
+	setClusterQueueStopPolicy(test, clusterQueue.Name, kueuev1beta1.None)
+
+	test.T().Logf("Waiting for RayJob %s/%s to be admitted", rayJob.Namespace, rayJob.Name)
+	test.Eventually(WorkloadForRayJob(test, rayJob), TestTimeoutMedium).
+		Should(WithTransform(WorkloadAdmitted, BeTrue()))
+	test.Eventually(RayJob(test, rayJob.Namespace, rayJob.Name), TestTimeoutMedium).
+		Should(WithTransform(func(job *rayv1.RayJob) string { return job.Status.RayClusterName }, Not(BeEmpty())))
+	rayClusterName := GetRayJob(test, rayJob.Namespace, rayJob.Name).Status.RayClusterName
+
+	// The whole RayCluster is admitted at once, along with the RayJob
+	test.T().Logf("Waiting for RayCluster %s/%s to be running", namespace.Name, rayClusterName)
+	test.Eventually(RayCluster(test, namespace.Name, rayClusterName), TestTimeoutMedium).
+		Should(WithTransform(RayClusterState, Equal(rayv1.Ready)))
+	ExpectAllOrNothingScheduled(test, GetRayCluster(test, namespace.Name, rayClusterName))
+
+	test.T().Logf("Waiting for RayJob %s/%s to complete", rayJob.Namespace, rayJob.Name)
+	test.Eventually(RayJob(test, rayJob.Namespace, rayJob.Name), TestTimeoutLong).
+		Should(WithTransform(RayJobStatus, Satisfy(rayv1.IsJobTerminal)))
+	if job := GetRayJob(test, rayJob.Namespace, rayJob.Name); job.Status.JobStatus != rayv1.JobStatusSucceeded {
+		test.T().Logf("Submitter logs of RayJob %s/%s:\n%s", rayJob.Namespace, rayJob.Name,
+			GetRayJobSubmitterLogs(test, rayJob.Namespace, rayJob.Name))
+	}
+	test.Expect(GetRayJob(test, rayJob.Namespace, rayJob.Name)).
+		To(WithTransform(RayJobStatus, Equal(rayv1.JobStatusSucceeded)))
+
+	// The RayCluster is torn down once the job has finished, along with the resources the operator created for it
+	test.T().Logf("Waiting for RayCluster %s/%s to be deleted", namespace.Name, rayClusterName)
+	test.Eventually(rayClusterNotFound(test, namespace.Name, rayClusterName), TestTimeoutMedium).Should(BeTrue())
+	test.Eventually(RayClusterPods(test, namespace.Name, rayClusterName), TestTimeoutMedium).Should(BeEmpty())
+	dashboardName := "ray-dashboard-" + rayClusterName
+	if IsOpenShift(test) {
+		test.Eventually(routeNotFound(test, namespace.Name, dashboardName), TestTimeoutShort).Should(BeTrue())
+	}
+	test.Eventually(ingressNotFound(test, namespace.Name, dashboardName), TestTimeoutShort).Should(BeTrue())
+
+	test.T().Logf("Waiting for the quota of RayJob %s/%s to be released", rayJob.Namespace, rayJob.Name)
+	test.Eventually(WorkloadForRayJob(test, rayJob), TestTimeoutShort).
+		Should(WithTransform(WorkloadFinished, BeTrue()))
+	test.Eventually(KueueClusterQueue(test, clusterQueue.Name), TestTimeoutShort).
+		Should(WithTransform(KueueClusterQueueUsage, And(
+			HaveFlavorUsage("default-flavor", corev1.ResourceCPU, "0"),
+			HaveFlavorUsage("default-flavor", corev1.ResourceMemory, "0"),
+		)))
+}
+
+func setClusterQueueStopPolicy(test Test, name string, stopPolicy kueuev1beta1.StopPolicy) {
+	test.T().Helper()
+
+	patch := []byte(fmt.Sprintf(`{"spec":{"stopPolicy":%q}}`, stopPolicy))
+	RetryOnConflictOrTransient(test, func() error {
+		_, err := test.Client().Kueue().KueueV1beta1().ClusterQueues().Patch(test.Ctx(), name, types.MergePatchType, patch, metav1.PatchOptions{})
+		return err
+	})
+	test.T().Logf("Set Kueue ClusterQueue %s stop policy to %s", name, stopPolicy)
+}
+
+func namespacePods(test Test, namespace string) func(g Gomega) []corev1.Pod {
+	return func(g Gomega) []corev1.Pod {
+		pods, err := test.Client().Core().CoreV1().Pods(namespace).List(test.Ctx(), metav1.ListOptions{})
+		g.Expect(err).NotTo(HaveOccurred())
+		return pods.Items
+	}
+}
+
+func rayClusterNotFound(test Test, namespace, name string) func(g Gomega) bool {
+	return func(g Gomega) bool {
+		_, err := test.Client().Ray().RayV1().RayClusters(namespace).Get(test.Ctx(), name, metav1.GetOptions{})
+		return errors.IsNotFound(err)
+	}
+}
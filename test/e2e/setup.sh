@@ -16,6 +16,9 @@
 
 set -euo pipefail
 : "${KUBERAY_VERSION}"
+: "${TRAINING_OPERATOR_VERSION:=v1.7.0}"
+: "${JOBSET_VERSION:=v0.5.1}"
+: "${CLUSTER_AUTOSCALER_VERSION:=cluster-autoscaler-1.30.0}"
 
 echo Deploying KubeRay "${KUBERAY_VERSION}"
 kubectl apply --server-side -k "github.com/ray-project/kuberay/ray-operator/config/default?ref=${KUBERAY_VERSION}&timeout=180s"
@@ -57,6 +60,17 @@ roleRef:
   name: e2e-controller-rayclusters
 EOF
 
+# The Training Operator, JobSet and the ProvisioningRequest CRD are deployed before Kueue, that only enables their integrations if their CRDs exist on start
+echo Deploying Kubeflow Training Operator "${TRAINING_OPERATOR_VERSION}"
+kubectl apply --server-side -k "github.com/kubeflow/training-operator/manifests/overlays/standalone?ref=${TRAINING_OPERATOR_VERSION}&timeout=180s"
+
+echo Deploying JobSet "${JOBSET_VERSION}"
+kubectl apply --server-side -f "https://github.com/kubernetes-sigs/jobset/releases/download/${JOBSET_VERSION}/manifests.yaml"
+
+# Only the ProvisioningRequest CRD is installed, the e2e tests provision the requests in place of the cluster autoscaler
+echo Deploying the ProvisioningRequest CRD of the cluster autoscaler "${CLUSTER_AUTOSCALER_VERSION}"
+kubectl apply --server-side -f "https://raw.githubusercontent.com/kubernetes/autoscaler/${CLUSTER_AUTOSCALER_VERSION}/cluster-autoscaler/apis/config/crd/autoscaling.x-k8s.io_provisioningrequests.yaml"
+
 echo "Deploying Kueue $KUEUE_VERSION"
 kubectl apply --server-side -f https://github.com/kubernetes-sigs/kueue/releases/download/${KUEUE_VERSION}/manifests.yaml
 
@@ -0,0 +1,216 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package odh
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	. "github.com/project-codeflare/codeflare-common/support"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	. "github.com/project-codeflare/codeflare-operator/test/support"
+)
+
+// Runs a workbench pod, from the notebook image, that logs in with the bearer token of a user with limited rights,
+// accesses the OAuth protected dashboard, and connects to the RayCluster with ray.init, over the Ray client route,
+// with the certificate signed by the CA of the RayCluster, to submit tasks, i.e., the path data scientists follow
+// from their notebooks.
+func TestRayClientFromWorkbench(t *testing.T) {
+	test := With(t)
+	Tags(test, OpenShiftOnly)
+
+	// Create a namespace
+	namespace := test.NewTestNamespace()
+	DumpEventsOnFailure(test, namespace.Name)
+	CollectDiagnosticsOnFailure(test, namespace.Name)
+
+	rayCluster := NewRayClusterBuilder(namespace.Name, "workbench").
+		WithWorkerGroup("workers", 1, corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("250m"),
+				corev1.ResourceMemory: resource.MustParse("512Mi"),
+			},
+			Limits: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("1"),
+				corev1.ResourceMemory: resource.MustParse("1G"),
+			},
+		}, nil).
+		Build()
+	rayCluster, err := test.Client().Ray().RayV1().RayClusters(namespace.Name).Create(test.Ctx(), rayCluster, metav1.CreateOptions{})
+	test.Expect(err).NotTo(HaveOccurred())
+	test.T().Logf("Created RayCluster %s/%s successfully", rayCluster.Namespace, rayCluster.Name)
+
+	test.T().Logf("Waiting for RayCluster %s/%s to be running", rayCluster.Namespace, rayCluster.Name)
+	test.Eventually(RayCluster(test, namespace.Name, rayCluster.Name), TestTimeoutMedium).
+		Should(WithTransform(RayClusterState, Equal(rayv1.Ready)))
+
+	// The routes are created by the operator once the RayCluster is ready
+	dashboardRoute := "ray-dashboard-" + rayCluster.Name
+	rayClientRoute := "rayclient-" + rayCluster.Name
+	test.Eventually(Route(test, namespace.Name, dashboardRoute), TestTimeoutShort).Should(Not(BeNil()))
+	test.Eventually(Route(test, namespace.Name, rayClientRoute), TestTimeoutShort).Should(Not(BeNil()))
+
+	// Create RBAC, retrieve token for user with limited rights
+	policyRules := []rbacv1.PolicyRule{
+		{
+			Verbs:     []string{"get", "list"},
+			APIGroups: []string{rayv1.GroupVersion.Group},
+			Resources: []string{"rayclusters", "rayclusters/status"},
+		},
+		{
+			Verbs:     []string{"get", "list"},
+			APIGroups: []string{"route.openshift.io"},
+			Resources: []string{"routes"},
+		},
+		{
+			// The client certificate is generated from the CA of the RayCluster
+			Verbs:         []string{"get"},
+			APIGroups:     []string{corev1.GroupName},
+			Resources:     []string{"secrets"},
+			ResourceNames: []string{"ca-secret-" + rayCluster.Name},
+		},
+	}
+	sa := CreateServiceAccount(test, namespace.Name)
+	role := CreateRole(test, namespace.Name, policyRules)
+	CreateRoleBinding(test, namespace.Name, sa, role)
+	token := CreateToken(test, namespace.Name, sa)
+
+	config := CreateConfigMap(test, namespace.Name, map[string][]byte{
+		"ray_client.py": ReadFile(test, "resources/ray_client.py"),
+	})
+	workbench := createWorkbenchPod(test, namespace, config.Name, token, map[string]string{
+		"RAY_CLUSTER_NAME":      rayCluster.Name,
+		"RAY_CLUSTER_NAMESPACE": namespace.Name,
+		"RAY_DASHBOARD_HOST":    GetRoute(test, namespace.Name, dashboardRoute).Spec.Host,
+		"RAY_CLIENT_HOST":       GetRoute(test, namespace.Name, rayClientRoute).Spec.Host,
+	})
+
+	test.T().Logf("Waiting for workbench Pod %s/%s to complete", workbench.Namespace, workbench.Name)
+	test.Eventually(workbenchPodPhase(test, workbench), TestTimeoutLong).
+		Should(BeElementOf(corev1.PodSucceeded, corev1.PodFailed))
+	logs := string(GetPodLogs(test, workbench, corev1.PodLogOptions{}))
+	test.T().Logf("Logs of workbench Pod %s/%s:\n%s", workbench.Namespace, workbench.Name, logs)
+	test.Expect(workbenchPodPhase(test, workbench)(test)).To(Equal(corev1.PodSucceeded))
+	test.Expect(logs).To(ContainSubstring("Ray tasks completed successfully"))
+}
+
+// createWorkbenchPod creates a pod from the recommended notebook image, that runs the ray_client.py script
+// of the ConfigMap, with the bearer token, and the OpenShift API URL, as the workbenches have them.
+func createWorkbenchPod(test Test, namespace *corev1.Namespace, configMapName, token string, env map[string]string) *corev1.Pod {
+	test.T().Helper()
+
+	secret := &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.String(),
+			Kind:       "Secret",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "workbench-",
+			Namespace:    namespace.Name,
+		},
+		StringData: map[string]string{"token": token},
+	}
+	secret, err := test.Client().Core().CoreV1().Secrets(namespace.Name).Create(test.Ctx(), secret, metav1.CreateOptions{})
+	test.Expect(err).NotTo(HaveOccurred())
+
+	is := GetImageStream(test, GetOpenDataHubNamespace(), GetNotebookImageStreamName(test))
+	image := "image-registry.openshift-image-registry.svc:5000/" + GetOpenDataHubNamespace() + "/" + is.Name + ":" + getRecommendedImageStreamTag(test, is)
+
+	vars := []corev1.EnvVar{
+		{Name: "OCP_SERVER", Value: GetOpenShiftApiUrl(test)},
+		{
+			Name: "OCP_TOKEN",
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: secret.Name},
+					Key:                  "token",
+				},
+			},
+		},
+	}
+	for name, value := range env {
+		vars = append(vars, corev1.EnvVar{Name: name, Value: value})
+	}
+
+	pod := &corev1.Pod{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.String(),
+			Kind:       "Pod",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "workbench",
+			Namespace: namespace.Name,
+		},
+		Spec: corev1.PodSpec{
+			RestartPolicy: corev1.RestartPolicyNever,
+			Containers: []corev1.Container{
+				{
+					Name:       "workbench",
+					Image:      image,
+					Command:    []string{"python", "/opt/app-root/notebooks/ray_client.py"},
+					Env:        vars,
+					WorkingDir: "/opt/app-root/src",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("250m"),
+							corev1.ResourceMemory: resource.MustParse("512Mi"),
+						},
+						Limits: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("1"),
+							corev1.ResourceMemory: resource.MustParse("2Gi"),
+						},
+					},
+					VolumeMounts: []corev1.VolumeMount{
+						{
+							Name:      "notebooks",
+							MountPath: "/opt/app-root/notebooks",
+						},
+					},
+				},
+			},
+			Volumes: []corev1.Volume{
+				{
+					Name: "notebooks",
+					VolumeSource: corev1.VolumeSource{
+						ConfigMap: &corev1.ConfigMapVolumeSource{
+							LocalObjectReference: corev1.LocalObjectReference{Name: configMapName},
+						},
+					},
+				},
+			},
+		},
+	}
+	pod, err = test.Client().Core().CoreV1().Pods(namespace.Name).Create(test.Ctx(), pod, metav1.CreateOptions{})
+	test.Expect(err).NotTo(HaveOccurred())
+	test.T().Logf("Created workbench Pod %s/%s successfully", pod.Namespace, pod.Name)
+
+	return pod
+}
+
+func workbenchPodPhase(test Test, pod *corev1.Pod) func(g Gomega) corev1.PodPhase {
+	return func(g Gomega) corev1.PodPhase {
+		pod, err := test.Client().Core().CoreV1().Pods(pod.Namespace).Get(test.Ctx(), pod.Name, metav1.GetOptions{})
+		g.Expect(err).NotTo(HaveOccurred())
+		return pod.Status.Phase
+	}
+}
@@ -24,13 +24,18 @@ import (
 	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
 
 	rbacv1 "k8s.io/api/rbac/v1"
+
+	. "github.com/project-codeflare/codeflare-operator/test/support"
 )
 
 func TestRay(t *testing.T) {
 	test := With(t)
+	Tags(test, LongRunning)
 
 	// Create a namespace
 	namespace := test.NewTestNamespace()
+	DumpEventsOnFailure(test, namespace.Name)
+	CollectDiagnosticsOnFailure(test, namespace.Name)
 
 	// Test configuration
 	jupyterNotebookConfigMapFileName := "mnist_ray_mini.ipynb"
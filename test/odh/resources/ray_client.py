@@ -0,0 +1,56 @@
+# Copyright 2024 IBM, Red Hat
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#      http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+# Connects to the RayCluster from a workbench, the way data scientists do, i.e., logs in with the bearer token,
+# generates the client certificate signed by the CA of the RayCluster, and submits tasks over the Ray client route.
+
+import os
+
+import ray
+import requests
+from codeflare_sdk import TokenAuthentication
+from codeflare_sdk.utils import generate_cert
+
+cluster_name = os.environ["RAY_CLUSTER_NAME"]
+namespace = os.environ["RAY_CLUSTER_NAMESPACE"]
+token = os.environ["OCP_TOKEN"]
+
+auth = TokenAuthentication(token=token, server=os.environ["OCP_SERVER"], skip_tls=True)
+print(auth.login(), flush=True)
+
+# The dashboard is protected by the OAuth proxy, that accepts the bearer token
+response = requests.get(f"https://{os.environ['RAY_DASHBOARD_HOST']}/api/version",
+                        headers={"Authorization": f"Bearer {token}"}, verify=False)
+response.raise_for_status()
+print(f"Ray dashboard version: {response.json()}", flush=True)
+
+# The Ray client server requires the client to present a certificate signed by the CA of the RayCluster
+generate_cert.generate_tls_cert(cluster_name, namespace)
+generate_cert.export_env(cluster_name, namespace)
+
+ray.init(address=f"ray://{os.environ['RAY_CLIENT_HOST']}:443", logging_level="DEBUG")
+print(f"Connected to RayCluster {namespace}/{cluster_name}: {ray.cluster_resources()}", flush=True)
+
+
+@ray.remote
+def square(x):
+    return x * x
+
+
+squares = ray.get([square.remote(i) for i in range(8)])
+if squares != [i * i for i in range(8)]:
+    raise RuntimeError(f"unexpected results of the Ray tasks: {squares}")
+print("Ray tasks completed successfully", flush=True)
+
+ray.shutdown()
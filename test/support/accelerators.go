@@ -0,0 +1,165 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/onsi/gomega"
+	"github.com/project-codeflare/codeflare-common/support"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Accelerator describes a kind of accelerator, the extended resource its device plugin advertises,
+// and the node labels that indicate the hardware is present, independently of the device plugin.
+type Accelerator struct {
+	Name         string
+	ResourceName corev1.ResourceName
+	// NodeLabels are set by Node Feature Discovery, or by the vendor feature discovery, on the nodes with the hardware
+	NodeLabels []string
+}
+
+var (
+	NvidiaGPU = Accelerator{
+		Name:         "NVIDIA GPU",
+		ResourceName: "nvidia.com/gpu",
+		NodeLabels:   []string{"nvidia.com/gpu.present", "feature.node.kubernetes.io/pci-10de.present"},
+	}
+	AMDGPU = Accelerator{
+		Name:         "AMD GPU",
+		ResourceName: "amd.com/gpu",
+		NodeLabels:   []string{"feature.node.kubernetes.io/amd-gpu", "feature.node.kubernetes.io/pci-1002.present"},
+	}
+	IntelGaudi = Accelerator{
+		Name:         "Intel Gaudi",
+		ResourceName: "habana.ai/gaudi",
+		NodeLabels:   []string{"feature.node.kubernetes.io/pci-1da3.present"},
+	}
+)
+
+// Toleration returns the toleration of the taint keyed by the resource name of the accelerator, that the nodes
+// with accelerators are commonly tainted with, so that only the pods requesting accelerators are scheduled on them.
+func (a Accelerator) Toleration() corev1.Toleration {
+	return corev1.Toleration{
+		Key:      string(a.ResourceName),
+		Operator: corev1.TolerationOpExists,
+		Effect:   corev1.TaintEffectNoSchedule,
+	}
+}
+
+// accelerators are the accelerators the tests may run on, by order of preference.
+var accelerators = []Accelerator{NvidiaGPU, AMDGPU, IntelGaudi}
+
+// AcceleratorAvailability is the availability of an accelerator across the schedulable nodes of the cluster.
+type AcceleratorAvailability struct {
+	Accelerator
+	// Allocatable is the number of accelerators allocatable across the nodes
+	Allocatable int64
+	// Nodes are the nodes with allocatable accelerators
+	Nodes []string
+	// Unusable are the nodes labelled with the hardware, but without allocatable accelerators,
+	// e.g., because the device plugin isn't deployed, or is failing
+	Unusable []string
+}
+
+// Accelerators is the availability of the accelerators detected in the cluster, indexed by resource name.
+type Accelerators map[corev1.ResourceName]AcceleratorAvailability
+
+// DetectAccelerators inspects the allocatable resources and the labels of the schedulable nodes,
+// to discover the accelerators available in the cluster, so that the tests that require accelerators
+// can skip, or select the accelerator they run on, rather than timing out waiting for them to be scheduled.
+func DetectAccelerators(t support.Test) Accelerators {
+	t.T().Helper()
+
+	nodes, err := t.Client().Core().CoreV1().Nodes().List(t.Ctx(), metav1.ListOptions{})
+	t.Expect(err).NotTo(gomega.HaveOccurred())
+
+	detected := Accelerators{}
+	for _, accelerator := range accelerators {
+		availability := AcceleratorAvailability{Accelerator: accelerator}
+		for _, node := range nodes.Items {
+			if node.Spec.Unschedulable {
+				continue
+			}
+			if quantity, ok := node.Status.Allocatable[accelerator.ResourceName]; ok && !quantity.IsZero() {
+				availability.Allocatable += quantity.Value()
+				availability.Nodes = append(availability.Nodes, node.Name)
+			} else if hasAnyLabel(node, accelerator.NodeLabels) {
+				availability.Unusable = append(availability.Unusable, node.Name)
+			}
+		}
+		if availability.Allocatable > 0 || len(availability.Unusable) > 0 {
+			detected[accelerator.ResourceName] = availability
+		}
+	}
+
+	return detected
+}
+
+// Available returns whether the number of accelerators are allocatable across the cluster.
+func (a Accelerators) Available(accelerator Accelerator, count int64) bool {
+	return a[accelerator.ResourceName].Allocatable >= count
+}
+
+// Select returns the preferred accelerator, of which the number are allocatable across the cluster,
+// or false if there's none.
+func (a Accelerators) Select(count int64) (Accelerator, bool) {
+	for _, accelerator := range accelerators {
+		if a.Available(accelerator, count) {
+			return accelerator, true
+		}
+	}
+	return Accelerator{}, false
+}
+
+func (a Accelerators) String() string {
+	if len(a) == 0 {
+		return "none"
+	}
+	var descriptions []string
+	for _, accelerator := range accelerators {
+		if availability, ok := a[accelerator.ResourceName]; ok {
+			description := fmt.Sprintf("%d %s(s) on %v", availability.Allocatable, accelerator.Name, availability.Nodes)
+			if len(availability.Unusable) > 0 {
+				description += fmt.Sprintf(", unusable on %v", availability.Unusable)
+			}
+			descriptions = append(descriptions, description)
+		}
+	}
+	return strings.Join(descriptions, "; ")
+}
+
+// SkipUnlessAccelerator skips the test, unless the number of accelerators are allocatable across the cluster.
+func SkipUnlessAccelerator(t support.Test, accelerator Accelerator, count int64) {
+	t.T().Helper()
+
+	if detected := DetectAccelerators(t); !detected.Available(accelerator, count) {
+		t.T().Skipf("The test requires %d %s(s), detected accelerators: %s", count, accelerator.Name, detected)
+	}
+}
+
+func hasAnyLabel(node corev1.Node, labels []string) bool {
+	for _, label := range labels {
+		if value, ok := node.Labels[label]; ok && value != "false" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"github.com/onsi/gomega"
+	"github.com/project-codeflare/codeflare-common/support"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+// Deployment returns a function that gets the Deployment, to be polled with Eventually.
+func Deployment(t support.Test, namespace, name string) func(g gomega.Gomega) *appsv1.Deployment {
+	return func(g gomega.Gomega) *appsv1.Deployment {
+		deployment, err := t.Client().Core().AppsV1().Deployments(namespace).Get(t.Ctx(), name, metav1.GetOptions{})
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+		return deployment
+	}
+}
+
+func GetDeployment(t support.Test, namespace, name string) *appsv1.Deployment {
+	t.T().Helper()
+	return Deployment(t, namespace, name)(t)
+}
+
+// StatefulSet returns a function that gets the StatefulSet, to be polled with Eventually.
+func StatefulSet(t support.Test, namespace, name string) func(g gomega.Gomega) *appsv1.StatefulSet {
+	return func(g gomega.Gomega) *appsv1.StatefulSet {
+		statefulSet, err := t.Client().Core().AppsV1().StatefulSets(namespace).Get(t.Ctx(), name, metav1.GetOptions{})
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+		return statefulSet
+	}
+}
+
+func GetStatefulSet(t support.Test, namespace, name string) *appsv1.StatefulSet {
+	t.T().Helper()
+	return StatefulSet(t, namespace, name)(t)
+}
+
+// DaemonSet returns a function that gets the DaemonSet, to be polled with Eventually.
+func DaemonSet(t support.Test, namespace, name string) func(g gomega.Gomega) *appsv1.DaemonSet {
+	return func(g gomega.Gomega) *appsv1.DaemonSet {
+		daemonSet, err := t.Client().Core().AppsV1().DaemonSets(namespace).Get(t.Ctx(), name, metav1.GetOptions{})
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+		return daemonSet
+	}
+}
+
+func GetDaemonSet(t support.Test, namespace, name string) *appsv1.DaemonSet {
+	t.T().Helper()
+	return DaemonSet(t, namespace, name)(t)
+}
+
+func DeploymentAvailableReplicas(deployment *appsv1.Deployment) int32 {
+	return deployment.Status.AvailableReplicas
+}
+
+func StatefulSetAvailableReplicas(statefulSet *appsv1.StatefulSet) int32 {
+	return statefulSet.Status.AvailableReplicas
+}
+
+// DeploymentReady returns whether the latest generation of the Deployment has been rolled out,
+// and all its replicas are available, e.g.:
+//
+//	test.Eventually(Deployment(test, namespace, name), TestTimeoutMedium).Should(WithTransform(DeploymentReady, BeTrue()))
+func DeploymentReady(deployment *appsv1.Deployment) bool {
+	replicas := ptr.Deref(deployment.Spec.Replicas, 1)
+	if deployment.Status.ObservedGeneration < deployment.Generation ||
+		deployment.Status.UpdatedReplicas != replicas ||
+		deployment.Status.AvailableReplicas != replicas {
+		return false
+	}
+	return support.ConditionStatus(appsv1.DeploymentAvailable)(deployment) == corev1.ConditionTrue
+}
+
+// StatefulSetReady returns whether the latest revision of the StatefulSet has been rolled out,
+// and all its replicas are ready.
+func StatefulSetReady(statefulSet *appsv1.StatefulSet) bool {
+	replicas := ptr.Deref(statefulSet.Spec.Replicas, 1)
+	return statefulSet.Status.ObservedGeneration >= statefulSet.Generation &&
+		statefulSet.Status.UpdateRevision == statefulSet.Status.CurrentRevision &&
+		statefulSet.Status.UpdatedReplicas == replicas &&
+		statefulSet.Status.ReadyReplicas == replicas
+}
+
+// DaemonSetReady returns whether the latest generation of the DaemonSet has been rolled out,
+// and its pods are ready on all the nodes they're scheduled on.
+func DaemonSetReady(daemonSet *appsv1.DaemonSet) bool {
+	return daemonSet.Status.ObservedGeneration >= daemonSet.Generation &&
+		daemonSet.Status.UpdatedNumberScheduled == daemonSet.Status.DesiredNumberScheduled &&
+		daemonSet.Status.NumberReady == daemonSet.Status.DesiredNumberScheduled
+}
@@ -0,0 +1,172 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"context"
+
+	"github.com/onsi/gomega"
+	"github.com/onsi/gomega/types"
+	awv1beta2 "github.com/project-codeflare/appwrapper/api/v1beta2"
+	"github.com/project-codeflare/codeflare-common/support"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+)
+
+// AppWrapperV1beta2Client is a typed client for the workload.codeflare.dev/v1beta2 API group,
+// that spares the tests the conversion from and to unstructured objects of the dynamic client.
+type AppWrapperV1beta2Client struct {
+	client rest.Interface
+}
+
+// AppWrapperClient is a typed client for the AppWrappers of a namespace.
+type AppWrapperClient struct {
+	client    rest.Interface
+	namespace string
+}
+
+// AppWrapperV1beta2 returns a typed client for the workload.codeflare.dev/v1beta2 API group.
+func AppWrapperV1beta2(t support.Test) *AppWrapperV1beta2Client {
+	t.T().Helper()
+	return &AppWrapperV1beta2Client{client: newRESTClient(t, awv1beta2.GroupVersion, awv1beta2.AddToScheme)}
+}
+
+// AppWrappers returns a client for the AppWrappers of the namespace.
+func (c *AppWrapperV1beta2Client) AppWrappers(namespace string) *AppWrapperClient {
+	return &AppWrapperClient{client: c.client, namespace: namespace}
+}
+
+func (c *AppWrapperClient) Create(ctx context.Context, aw *awv1beta2.AppWrapper, opts metav1.CreateOptions) (*awv1beta2.AppWrapper, error) {
+	result := &awv1beta2.AppWrapper{}
+	err := c.client.Post().
+		Namespace(c.namespace).
+		Resource("appwrappers").
+		VersionedParams(&opts, metav1.ParameterCodec).
+		Body(aw).
+		Do(ctx).
+		Into(result)
+	return result, err
+}
+
+func (c *AppWrapperClient) Get(ctx context.Context, name string, opts metav1.GetOptions) (*awv1beta2.AppWrapper, error) {
+	result := &awv1beta2.AppWrapper{}
+	err := c.client.Get().
+		Namespace(c.namespace).
+		Resource("appwrappers").
+		Name(name).
+		VersionedParams(&opts, metav1.ParameterCodec).
+		Do(ctx).
+		Into(result)
+	return result, err
+}
+
+func (c *AppWrapperClient) List(ctx context.Context, opts metav1.ListOptions) (*awv1beta2.AppWrapperList, error) {
+	result := &awv1beta2.AppWrapperList{}
+	err := c.client.Get().
+		Namespace(c.namespace).
+		Resource("appwrappers").
+		VersionedParams(&opts, metav1.ParameterCodec).
+		Do(ctx).
+		Into(result)
+	return result, err
+}
+
+func (c *AppWrapperClient) Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error {
+	return c.client.Delete().
+		Namespace(c.namespace).
+		Resource("appwrappers").
+		Name(name).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// CreateAppWrapper creates the AppWrapper, and returns it as persisted by the API server.
+func CreateAppWrapper(t support.Test, aw *awv1beta2.AppWrapper) *awv1beta2.AppWrapper {
+	t.T().Helper()
+
+	aw, err := AppWrapperV1beta2(t).AppWrappers(aw.Namespace).Create(t.Ctx(), aw, metav1.CreateOptions{})
+	t.Expect(err).NotTo(gomega.HaveOccurred())
+	t.T().Logf("Created AppWrapper %s/%s successfully", aw.Namespace, aw.Name)
+
+	return aw
+}
+
+// GetAppWrapperPhase returns the current phase of the AppWrapper.
+func GetAppWrapperPhase(t support.Test, namespace, name string) awv1beta2.AppWrapperPhase {
+	t.T().Helper()
+
+	aw, err := AppWrapperV1beta2(t).AppWrappers(namespace).Get(t.Ctx(), name, metav1.GetOptions{})
+	t.Expect(err).NotTo(gomega.HaveOccurred())
+	return aw.Status.Phase
+}
+
+// ComponentPhase is the deployment phase of a component of an AppWrapper. The AppWrapper controller doesn't report
+// a phase per component, so it's derived from the ResourcesDeployed condition of the component status.
+type ComponentPhase string
+
+const (
+	// ComponentPending means the AppWrapper controller hasn't reported the status of the component yet.
+	ComponentPending ComponentPhase = "Pending"
+	// ComponentDeployed means the resources of the component have been created.
+	ComponentDeployed ComponentPhase = "Deployed"
+	// ComponentUndeployed means the resources of the component have been deleted, e.g., when the AppWrapper
+	// has been reset or suspended.
+	ComponentUndeployed ComponentPhase = "Undeployed"
+)
+
+// AppWrapperRetries returns the number of times the AppWrapper has been reset, after its resources
+// have failed or been unhealthy, e.g.:
+//
+//	test.Eventually(AppWrapper(test, namespace, name), TestTimeoutMedium).
+//		Should(WithTransform(AppWrapperRetries, BeNumerically(">=", 1)))
+func AppWrapperRetries(aw *awv1beta2.AppWrapper) int32 {
+	return aw.Status.Retries
+}
+
+// AppWrapperComponentPhase returns a transform that gets the phase of the i-th component of the AppWrapper, e.g.:
+//
+//	test.Eventually(AppWrapper(test, namespace, name), TestTimeoutShort).
+//		Should(WithTransform(AppWrapperComponentPhase(0), Equal(ComponentUndeployed)))
+func AppWrapperComponentPhase(i int) func(aw *awv1beta2.AppWrapper) ComponentPhase {
+	return func(aw *awv1beta2.AppWrapper) ComponentPhase {
+		if i >= len(aw.Status.ComponentStatus) {
+			return ComponentPending
+		}
+		condition := meta.FindStatusCondition(aw.Status.ComponentStatus[i].Conditions, string(awv1beta2.ResourcesDeployed))
+		switch {
+		case condition == nil:
+			return ComponentPending
+		case condition.Status == metav1.ConditionTrue:
+			return ComponentDeployed
+		default:
+			return ComponentUndeployed
+		}
+	}
+}
+
+// HaveAppWrapperPhase succeeds if the AppWrapper is in one of the phases.
+func HaveAppWrapperPhase(phases ...awv1beta2.AppWrapperPhase) types.GomegaMatcher {
+	return gomega.WithTransform(support.AppWrapperPhase, gomega.BeElementOf(phases))
+}
+
+// HaveAppWrapperRetries succeeds if the AppWrapper has been reset the given number of times.
+func HaveAppWrapperRetries(retries int32) types.GomegaMatcher {
+	return gomega.WithTransform(AppWrapperRetries, gomega.Equal(retries))
+}
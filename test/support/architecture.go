@@ -0,0 +1,98 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/onsi/gomega"
+	"github.com/project-codeflare/codeflare-common/support"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ArchitectureEnvVar is the environment variable of the CPU architecture, e.g., arm64, of the nodes the workloads
+// of the tests are constrained to, so that the tests run on the nodes of that architecture in multi-arch clusters.
+const ArchitectureEnvVar = "CODEFLARE_TEST_ARCHITECTURE"
+
+// defaultImagesArchitecture is the architecture the default images of the tests are built for.
+const defaultImagesArchitecture = "amd64"
+
+// GetArchitecture returns the CPU architecture the workloads of the tests are constrained to, or an empty string
+// if they may run on nodes of any architecture.
+func GetArchitecture() string {
+	return os.Getenv(ArchitectureEnvVar)
+}
+
+// ArchitectureNodeSelector returns the node selector constraining the pods to the nodes of the architecture
+// of the tests, or nil if they may run on nodes of any architecture.
+func ArchitectureNodeSelector() map[string]string {
+	if arch := GetArchitecture(); arch != "" {
+		return map[string]string{corev1.LabelArchStable: arch}
+	}
+	return nil
+}
+
+// DetectArchitectures returns the schedulable nodes of the cluster, indexed by their CPU architecture.
+func DetectArchitectures(t support.Test) map[string][]string {
+	t.T().Helper()
+
+	nodes, err := t.Client().Core().CoreV1().Nodes().List(t.Ctx(), metav1.ListOptions{})
+	t.Expect(err).NotTo(gomega.HaveOccurred())
+
+	architectures := map[string][]string{}
+	for _, node := range nodes.Items {
+		if node.Spec.Unschedulable {
+			continue
+		}
+		arch := node.Labels[corev1.LabelArchStable]
+		architectures[arch] = append(architectures[arch], node.Name)
+	}
+
+	return architectures
+}
+
+// WithArchitectureProfile returns a runner that, when the tests are constrained to an architecture other than the one
+// the default images are built for, e.g., with CODEFLARE_TEST_ARCHITECTURE=arm64, reports the default images the
+// tests would run, so that they're overridden with images of that architecture, or multi-arch images, rather than
+// letting the pods crash. The Ray images may also be mapped by the operator, with the kuberay.rayImages configuration.
+// It's meant to be called from the TestMain function of the suites.
+func WithArchitectureProfile(m Runner) Runner {
+	return architectureRunner{m}
+}
+
+type architectureRunner struct {
+	Runner
+}
+
+func (r architectureRunner) Run() int {
+	arch := GetArchitecture()
+	if arch == "" || arch == defaultImagesArchitecture {
+		return r.Runner.Run()
+	}
+
+	for _, reference := range imageReferences {
+		if _, ok := os.LookupEnv(reference.envVar); !ok && !reference.multiArch {
+			fmt.Fprintf(os.Stderr, "Image %s is built for %s, set %s to an image built for %s, or a multi-arch image\n",
+				reference.image(), defaultImagesArchitecture, reference.envVar, arch)
+		}
+	}
+
+	return r.Runner.Run()
+}
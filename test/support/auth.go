@@ -0,0 +1,135 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/onsi/gomega"
+	"github.com/project-codeflare/codeflare-common/support"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+const (
+	// BearerTokenEnvVar is the environment variable of the bearer token the tests use to access the Ray dashboards.
+	BearerTokenEnvVar = "CODEFLARE_TEST_BEARER_TOKEN"
+	// OAuthUsernameEnvVar and OAuthPasswordEnvVar are the environment variables of the credentials of the OpenShift
+	// user, e.g., an htpasswd user, the tests acquire an OAuth access token for, to access the Ray dashboards.
+	OAuthUsernameEnvVar = "CODEFLARE_TEST_OAUTH_USERNAME"
+	OAuthPasswordEnvVar = "CODEFLARE_TEST_OAUTH_PASSWORD"
+
+	// oauthChallengingClient is the OpenShift OAuth client that issues tokens for basic authentication challenges, like oc login does.
+	oauthChallengingClient = "openshift-challenging-client"
+)
+
+// GetBearerToken returns a bearer token authorized to access the dashboards of the RayClusters of the namespace,
+// through the OAuth proxy the operator injects. It's either the token set with the CODEFLARE_TEST_BEARER_TOKEN
+// environment variable, an OAuth access token of the user whose credentials are set with the
+// CODEFLARE_TEST_OAUTH_USERNAME and CODEFLARE_TEST_OAUTH_PASSWORD environment variables, or otherwise
+// the token of a ServiceAccount created with access to the dashboards.
+func GetBearerToken(t support.Test, namespace string) string {
+	t.T().Helper()
+
+	if token, ok := os.LookupEnv(BearerTokenEnvVar); ok && token != "" {
+		return token
+	}
+	username, password := os.Getenv(OAuthUsernameEnvVar), os.Getenv(OAuthPasswordEnvVar)
+	if username != "" && password != "" {
+		return GetOAuthToken(t, username, password)
+	}
+	return CreateDashboardToken(t, namespace)
+}
+
+// GetOAuthToken performs the OpenShift OAuth implicit flow, with the credentials of the user, and returns the issued access token.
+func GetOAuthToken(t support.Test, username, password string) string {
+	t.T().Helper()
+
+	// Discover the OAuth server from the API server metadata
+	data, err := t.Client().Core().Discovery().RESTClient().Get().AbsPath("/.well-known/oauth-authorization-server").DoRaw(t.Ctx())
+	t.Expect(err).NotTo(gomega.HaveOccurred())
+	metadata := struct {
+		AuthorizationEndpoint string `json:"authorization_endpoint"`
+	}{}
+	t.Expect(json.Unmarshal(data, &metadata)).To(gomega.Succeed())
+
+	authorizeURL, err := url.Parse(metadata.AuthorizationEndpoint)
+	t.Expect(err).NotTo(gomega.HaveOccurred())
+	authorizeURL.RawQuery = url.Values{
+		"response_type": {"token"},
+		"client_id":     {oauthChallengingClient},
+	}.Encode()
+
+	request, err := http.NewRequestWithContext(t.Ctx(), http.MethodGet, authorizeURL.String(), nil)
+	t.Expect(err).NotTo(gomega.HaveOccurred())
+	request.SetBasicAuth(username, password)
+	// Required by the OAuth server for basic authentication challenges
+	request.Header.Set("X-CSRF-Token", "1")
+
+	client := &http.Client{
+		Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}},
+		// The token is returned in the fragment of the redirect location
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	response, err := client.Do(request)
+	t.Expect(err).NotTo(gomega.HaveOccurred())
+	defer response.Body.Close()
+	t.Expect(response.StatusCode).To(gomega.Equal(http.StatusFound), "OAuth authorization failed for user %s", username)
+
+	location, err := response.Location()
+	t.Expect(err).NotTo(gomega.HaveOccurred())
+	fragment, err := url.ParseQuery(location.Fragment)
+	t.Expect(err).NotTo(gomega.HaveOccurred())
+	token := fragment.Get("access_token")
+	t.Expect(token).NotTo(gomega.BeEmpty(), "no access token issued for user %s", username)
+	t.T().Logf("Acquired OAuth access token for user %s", username)
+
+	return token
+}
+
+// CreateDashboardToken creates a ServiceAccount, granted access to the dashboards of the RayClusters of the namespace,
+// and returns a token for it. The token is requested for the default audiences of the API server, so that it can be
+// validated by the OAuth proxy with a TokenReview.
+func CreateDashboardToken(t support.Test, namespace string) string {
+	t.T().Helper()
+
+	serviceAccount := support.CreateServiceAccount(t, namespace)
+	role := support.CreateRole(t, namespace, []rbacv1.PolicyRule{
+		{
+			// Authorizes the dashboard access when the dashboard RBAC is enabled
+			Verbs:     []string{"get"},
+			APIGroups: []string{rayv1.GroupVersion.Group},
+			Resources: []string{"rayclusters/dashboard"},
+		},
+		{
+			// Authorizes the dashboard access otherwise
+			Verbs:     []string{"get"},
+			APIGroups: []string{""},
+			Resources: []string{"pods"},
+		},
+	})
+	support.CreateRoleBinding(t, namespace, serviceAccount, role)
+
+	return support.CreateToken(t, namespace, serviceAccount)
+}
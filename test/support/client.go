@@ -0,0 +1,62 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"github.com/onsi/gomega"
+	"github.com/project-codeflare/codeflare-common/support"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// newRESTClient returns a REST client for the API group version, whose types are registered with addToScheme,
+// to back the typed clients of the APIs that codeflare-common only exposes through the dynamic client.
+func newRESTClient(t support.Test, groupVersion schema.GroupVersion, addToScheme func(*runtime.Scheme) error) rest.Interface {
+	t.T().Helper()
+
+	scheme := runtime.NewScheme()
+	t.Expect(addToScheme(scheme)).To(gomega.Succeed())
+
+	cfg := rest.CopyConfig(restConfig(t))
+	cfg.GroupVersion = &groupVersion
+	cfg.APIPath = "/apis"
+	cfg.NegotiatedSerializer = serializer.NewCodecFactory(scheme).WithoutConversion()
+	client, err := rest.RESTClientFor(cfg)
+	t.Expect(err).NotTo(gomega.HaveOccurred())
+
+	return client
+}
+
+// restConfig returns the configuration of the cluster the tests run against, loaded the same way kubectl does.
+func restConfig(t support.Test) *rest.Config {
+	t.T().Helper()
+
+	cfg, err := loadRESTConfig()
+	t.Expect(err).NotTo(gomega.HaveOccurred())
+	return cfg
+}
+
+func loadRESTConfig() (*rest.Config, error) {
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		clientcmd.NewDefaultClientConfigLoadingRules(),
+		&clientcmd.ConfigOverrides{},
+	).ClientConfig()
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/onsi/gomega/gcustom"
+	"github.com/onsi/gomega/types"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/project-codeflare/codeflare-operator/pkg/controllers"
+)
+
+// HaveCondition succeeds if the object has a condition of the given type, with the given status.
+// It works with typed and unstructured objects, that report their conditions in the status conditions field,
+// like AppWrappers, Kueue Workloads, Jobs or Pods, and with RayClusters, whose conditions are reported by the operator
+// in the conditions annotation, e.g.:
+//
+//	test.Eventually(RayCluster(test, namespace, name), TestTimeoutMedium).
+//		Should(HaveCondition(controllers.SuspendedCondition, metav1.ConditionTrue))
+func HaveCondition(conditionType string, status metav1.ConditionStatus) types.GomegaMatcher {
+	return gcustom.MakeMatcher(func(actual any) (bool, error) {
+		conditions, err := objectConditions(actual)
+		if err != nil {
+			return false, err
+		}
+		for _, condition := range conditions {
+			if condition.Type == conditionType {
+				return condition.Status == string(status), nil
+			}
+		}
+		return false, nil
+	}).WithTemplate("Expected:\n{{.FormattedActual}}\n{{.To}} have condition {{.Data}}", fmt.Sprintf("%s=%s", conditionType, status))
+}
+
+// objectCondition holds the fields common to metav1.Condition and the typed conditions of the core APIs.
+type objectCondition struct {
+	Type   string `json:"type"`
+	Status string `json:"status"`
+}
+
+// objectConditions returns the conditions of the object, from its status conditions field and its conditions annotation.
+func objectConditions(actual any) ([]objectCondition, error) {
+	var content map[string]any
+	switch object := actual.(type) {
+	case nil:
+		return nil, fmt.Errorf("HaveCondition expects an object, got nil")
+	case *unstructured.Unstructured:
+		content = object.Object
+	case unstructured.Unstructured:
+		content = object.Object
+	case map[string]any:
+		content = object
+	default:
+		var err error
+		content, err = runtime.DefaultUnstructuredConverter.ToUnstructured(actual)
+		if err != nil {
+			return nil, fmt.Errorf("HaveCondition expects an object, got %T: %w", actual, err)
+		}
+	}
+
+	var conditions []objectCondition
+	if items, found, err := unstructured.NestedSlice(content, "status", "conditions"); err != nil {
+		return nil, err
+	} else if found {
+		for _, item := range items {
+			if fields, ok := item.(map[string]any); ok {
+				conditionType, _, _ := unstructured.NestedString(fields, "type")
+				conditionStatus, _, _ := unstructured.NestedString(fields, "status")
+				conditions = append(conditions, objectCondition{Type: conditionType, Status: conditionStatus})
+			}
+		}
+	}
+
+	if annotation, found, _ := unstructured.NestedString(content, "metadata", "annotations", controllers.ConditionsAnnotation); found {
+		var annotated []objectCondition
+		if err := json.Unmarshal([]byte(annotation), &annotated); err != nil {
+			return nil, fmt.Errorf("invalid %s annotation: %w", controllers.ConditionsAnnotation, err)
+		}
+		conditions = append(conditions, annotated...)
+	}
+
+	return conditions, nil
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/onsi/gomega"
+	"github.com/project-codeflare/codeflare-common/support"
+)
+
+// DatasetsDirEnvVar is the environment variable of the local directory holding the datasets of the training tests,
+// with a sub-directory per dataset, e.g., mnist, that are seeded into object storage instead of being downloaded
+// from their upstream location.
+const DatasetsDirEnvVar = "CODEFLARE_TEST_DATASETS_DIR"
+
+const datasetsBucket = "datasets"
+
+// datasetURLs returns the upstream location of the datasets, used when they are not available locally.
+var datasetURLs = map[string]func() string{
+	"mnist": support.GetMnistDatasetURL,
+}
+
+// UploadDataset uploads the file, or the files of the directory, at the local path into the bucket, under a prefix
+// named after the base name of the path, makes them readable without credentials, and returns the URL of the dataset,
+// e.g., http://minio.<namespace>.svc:9000/datasets/mnist/, so that the files can be downloaded by appending their name.
+func (m *MinIO) UploadDataset(t support.Test, bucket, localPath string) string {
+	t.T().Helper()
+
+	name := filepath.Base(localPath)
+	count := 0
+	err := filepath.WalkDir(localPath, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		relative, err := filepath.Rel(localPath, path)
+		if err != nil {
+			return err
+		}
+		key := name
+		if relative != "." {
+			key += "/" + filepath.ToSlash(relative)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		m.PutObject(t, bucket, key, data)
+		count++
+		return nil
+	})
+	t.Expect(err).NotTo(gomega.HaveOccurred())
+	t.Expect(count).To(gomega.BeNumerically(">", 0), "no files to upload at %s", localPath)
+
+	m.AllowAnonymousRead(t, bucket, name)
+	t.T().Logf("Uploaded %d file(s) of dataset %s into bucket %s of MinIO %s/%s", count, name, bucket, m.Namespace, minioName)
+
+	return m.GetDatasetURL(bucket, name)
+}
+
+// GetDatasetURL returns the in-cluster URL of the dataset uploaded into the bucket with UploadDataset.
+func (m *MinIO) GetDatasetURL(bucket, name string) string {
+	return m.Endpoint + "/" + bucket + "/" + strings.Trim(name, "/") + "/"
+}
+
+// GetDatasetURL returns the URL the named dataset can be downloaded from by the workloads of the tests.
+// When the dataset is available in the directory set with the CODEFLARE_TEST_DATASETS_DIR environment variable,
+// it's uploaded into a MinIO instance shared by the tests, once per suite, so that the test doesn't depend on
+// external storage. Otherwise, the upstream location of the dataset, that can be overridden with its own environment
+// variable, e.g., MNIST_DATASET_URL, is returned.
+func GetDatasetURL(t support.Test, name string) string {
+	t.T().Helper()
+
+	if dir, ok := os.LookupEnv(DatasetsDirEnvVar); ok && dir != "" {
+		localPath := filepath.Join(dir, name)
+		if _, err := os.Stat(localPath); err == nil {
+			return SharedFixture(t, "dataset/"+name, func(t support.Test) (string, FixtureTeardown) {
+				return SharedMinIO(t, datasetsBucket).UploadDataset(t, datasetsBucket, localPath), nil
+			})
+		}
+		t.T().Logf("Dataset %s not found in %s, using its upstream location", name, dir)
+	}
+
+	upstream, ok := datasetURLs[name]
+	t.Expect(ok).To(gomega.BeTrue(), "unknown dataset %s", name)
+	return upstream()
+}
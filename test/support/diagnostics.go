@@ -0,0 +1,246 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	awv1beta2 "github.com/project-codeflare/appwrapper/api/v1beta2"
+	"github.com/project-codeflare/codeflare-common/support"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	kueuev1beta1 "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// ArtifactsDirEnvVar is the environment variable of the directory the diagnostics of failed tests are collected into.
+	ArtifactsDirEnvVar = "CODEFLARE_TEST_ARTIFACTS_DIR"
+
+	operatorNamespaceEnvVar  = "CODEFLARE_OPERATOR_NAMESPACE"
+	defaultOperatorNamespace = "openshift-operators"
+	operatorPodsSelector     = "app.kubernetes.io/name=codeflare-operator"
+)
+
+// diagnosticsResources are the workload resources collected from the namespace of failed tests.
+var diagnosticsResources = []schema.GroupVersionResource{
+	rayv1.SchemeGroupVersion.WithResource("rayclusters"),
+	rayv1.SchemeGroupVersion.WithResource("rayjobs"),
+	awv1beta2.GroupVersion.WithResource("appwrappers"),
+	kueuev1beta1.SchemeGroupVersion.WithResource("workloads"),
+}
+
+// GetOperatorNamespace returns the namespace the CodeFlare operator is deployed into,
+// which can be overridden with the CODEFLARE_OPERATOR_NAMESPACE environment variable.
+func GetOperatorNamespace() string {
+	if namespace, ok := os.LookupEnv(operatorNamespaceEnvVar); ok {
+		return namespace
+	}
+	return defaultOperatorNamespace
+}
+
+// CollectDiagnostics collects the RayCluster, RayJob, AppWrapper, and Workload resources of the namespace,
+// the description and logs of its pods, and the logs of the operator, into a directory dedicated to the test,
+// under the directory set with the CODEFLARE_TEST_ARTIFACTS_DIR environment variable, the same way must-gather does,
+// so that failures in CI can be investigated offline. It does nothing if the environment variable is unset.
+func CollectDiagnostics(t support.Test, namespace string) {
+	t.T().Helper()
+
+	parent, ok := os.LookupEnv(ArtifactsDirEnvVar)
+	if !ok || parent == "" {
+		return
+	}
+	dir := filepath.Join(parent, artifactsDirName(t.T().Name()), namespace)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		// Troubleshooting is best effort, and must not mask the original failure
+		t.T().Logf("Failed to create artifacts directory %s: %v", dir, err)
+		return
+	}
+
+	for _, gvr := range diagnosticsResources {
+		list, err := t.Client().Dynamic().Resource(gvr).Namespace(namespace).List(t.Ctx(), metav1.ListOptions{})
+		if err != nil {
+			t.T().Logf("Failed to list %s in namespace %s: %v", gvr.Resource, namespace, err)
+			continue
+		}
+		for _, item := range list.Items {
+			writeYAML(t, filepath.Join(dir, gvr.Resource, item.GetName()+".yaml"), item.Object)
+		}
+	}
+
+	collectPods(t, namespace, "", filepath.Join(dir, "pods"))
+
+	collectPods(t, GetOperatorNamespace(), operatorPodsSelector, filepath.Join(dir, "operator"))
+
+	t.T().Logf("Collected diagnostics of namespace %s into %s", namespace, dir)
+}
+
+// CollectDiagnosticsOnFailure registers CollectDiagnostics, and LogResourceUsage, to be called for the namespace
+// when the test completes, if it failed. It should be called right after the namespace is created, so that the
+// diagnostics are collected before it's deleted.
+func CollectDiagnosticsOnFailure(t support.Test, namespace string) {
+	t.T().Cleanup(func() {
+		if t.T().Failed() {
+			LogResourceUsage(t, namespace)
+			CollectDiagnostics(t, namespace)
+		}
+	})
+}
+
+// collectPods writes the description, and the logs of the current and previous instance of each container,
+// of the pods matching the selector, into the directory.
+func collectPods(t support.Test, namespace, selector, dir string) {
+	pods, err := t.Client().Core().CoreV1().Pods(namespace).List(t.Ctx(), metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		t.T().Logf("Failed to list pods in namespace %s: %v", namespace, err)
+		return
+	}
+	for _, pod := range pods.Items {
+		writeYAML(t, filepath.Join(dir, pod.Name+".yaml"), pod)
+		writeFile(t, filepath.Join(dir, pod.Name+".describe.txt"), []byte(describePod(t, &pod)))
+
+		containers := append(append([]corev1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...)
+		for _, container := range containers {
+			if logs, ok := podLogs(t, &pod, container.Name, false); ok {
+				writeFile(t, filepath.Join(dir, pod.Name, container.Name+".log"), logs)
+			}
+			if podContainerRestarted(&pod, container.Name) {
+				if logs, ok := podLogs(t, &pod, container.Name, true); ok {
+					writeFile(t, filepath.Join(dir, pod.Name, container.Name+".previous.log"), logs)
+				}
+			}
+		}
+	}
+}
+
+// describePod summarizes the status of the pod and lists its events, like kubectl describe does.
+func describePod(t support.Test, pod *corev1.Pod) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Name:       %s\n", pod.Name)
+	fmt.Fprintf(&b, "Namespace:  %s\n", pod.Namespace)
+	fmt.Fprintf(&b, "Node:       %s\n", pod.Spec.NodeName)
+	fmt.Fprintf(&b, "Phase:      %s\n", pod.Status.Phase)
+	if pod.Status.Reason != "" {
+		fmt.Fprintf(&b, "Reason:     %s\n", pod.Status.Reason)
+	}
+	if pod.Status.Message != "" {
+		fmt.Fprintf(&b, "Message:    %s\n", pod.Status.Message)
+	}
+
+	b.WriteString("Conditions:\n")
+	for _, condition := range pod.Status.Conditions {
+		fmt.Fprintf(&b, "  %s=%s %s %s\n", condition.Type, condition.Status, condition.Reason, condition.Message)
+	}
+
+	b.WriteString("Containers:\n")
+	statuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	for _, status := range statuses {
+		fmt.Fprintf(&b, "  %s:\n    Image:     %s\n    Ready:     %t\n    Restarts:  %d\n", status.Name, status.Image, status.Ready, status.RestartCount)
+		fmt.Fprintf(&b, "    State:     %s\n", containerStateString(status.State))
+		if status.LastTerminationState.Terminated != nil {
+			fmt.Fprintf(&b, "    Last State: %s\n", containerStateString(status.LastTerminationState))
+		}
+	}
+
+	b.WriteString("Events:\n")
+	events, err := t.Client().Core().EventsV1().Events(pod.Namespace).List(t.Ctx(), metav1.ListOptions{
+		FieldSelector: "regarding.kind=Pod,regarding.name=" + pod.Name,
+	})
+	if err != nil {
+		fmt.Fprintf(&b, "  Failed to list events: %v\n", err)
+		return b.String()
+	}
+	for _, event := range events.Items {
+		fmt.Fprintf(&b, "  %s  %s  %s (x%d): %s\n", eventLastSeen(event).Format(time.RFC3339),
+			event.Type, event.Reason, eventCount(event), event.Note)
+	}
+	return b.String()
+}
+
+func containerStateString(state corev1.ContainerState) string {
+	switch {
+	case state.Waiting != nil:
+		return fmt.Sprintf("Waiting (%s) %s", state.Waiting.Reason, state.Waiting.Message)
+	case state.Running != nil:
+		return fmt.Sprintf("Running since %s", state.Running.StartedAt)
+	case state.Terminated != nil:
+		return fmt.Sprintf("Terminated (%s) with exit code %d %s", state.Terminated.Reason, state.Terminated.ExitCode, state.Terminated.Message)
+	}
+	return "Unknown"
+}
+
+func podContainerRestarted(pod *corev1.Pod, container string) bool {
+	for _, status := range append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...) {
+		if status.Name == container {
+			return status.RestartCount > 0
+		}
+	}
+	return false
+}
+
+func podLogs(t support.Test, pod *corev1.Pod, container string, previous bool) ([]byte, bool) {
+	stream, err := t.Client().Core().CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
+		Container: container,
+		Previous:  previous,
+	}).Stream(t.Ctx())
+	if err != nil {
+		t.T().Logf("Failed to retrieve logs of container %s of Pod %s/%s: %v", container, pod.Namespace, pod.Name, err)
+		return nil, false
+	}
+	defer stream.Close()
+	logs, err := io.ReadAll(stream)
+	if err != nil {
+		t.T().Logf("Failed to read logs of container %s of Pod %s/%s: %v", container, pod.Namespace, pod.Name, err)
+		return nil, false
+	}
+	return logs, true
+}
+
+func writeYAML(t support.Test, name string, object any) {
+	data, err := yaml.Marshal(object)
+	if err != nil {
+		t.T().Logf("Failed to marshal %s: %v", name, err)
+		return
+	}
+	writeFile(t, name, data)
+}
+
+func writeFile(t support.Test, name string, data []byte) {
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		t.T().Logf("Failed to create directory %s: %v", filepath.Dir(name), err)
+		return
+	}
+	if err := os.WriteFile(name, data, 0o644); err != nil {
+		t.T().Logf("Failed to write %s: %v", name, err)
+	}
+}
+
+var unsafeFileNameChars = regexp.MustCompile(`[^a-zA-Z0-9._/-]`)
+
+// artifactsDirName returns the relative directory path for the test, with a directory per sub-test.
+func artifactsDirName(testName string) string {
+	return unsafeFileNameChars.ReplaceAllString(testName, "_")
+}
@@ -0,0 +1,166 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/project-codeflare/codeflare-common/support"
+)
+
+const (
+	// DisconnectedEnvVar is the environment variable that enables the disconnected profile, for clusters without
+	// access to the public internet, where the images, Python packages, and datasets are pulled from mirrors.
+	DisconnectedEnvVar = "CODEFLARE_TEST_DISCONNECTED"
+	// ImageMirrorEnvVar is the environment variable of the registry the images are mirrored into, e.g., with oc-mirror,
+	// that the images of the tests, whose environment variable isn't set, are pulled from in the disconnected profile.
+	ImageMirrorEnvVar = "CODEFLARE_TEST_IMAGE_MIRROR"
+)
+
+// publicHosts are the hosts of the public internet the tests pull from by default.
+var publicHosts = []string{
+	"docker.io",
+	"quay.io",
+	"ghcr.io",
+	"gcr.io",
+	"registry.k8s.io",
+	"pypi.org",
+	"pypi.python.org",
+	"files.pythonhosted.org",
+	"github.com",
+	"raw.githubusercontent.com",
+	"yann.lecun.com",
+}
+
+// imageReference is an image the tests run, that can be overridden with its environment variable.
+type imageReference struct {
+	envVar string
+	image  func() string
+	// multiArch is whether the default image is available for all the architectures the tests may run on
+	multiArch bool
+}
+
+var imageReferences = []imageReference{
+	{support.CodeFlareTestRayImage, support.GetRayImage, false},
+	{support.CodeFlareTestPyTorchImage, support.GetPyTorchImage, false},
+	{MinIOImageEnvVar, minioImage, true},
+	{RedisImageEnvVar, redisImage, true},
+}
+
+// IsDisconnected returns whether the disconnected profile is enabled, with the CODEFLARE_TEST_DISCONNECTED
+// environment variable.
+func IsDisconnected() bool {
+	return os.Getenv(DisconnectedEnvVar) == "true"
+}
+
+// MirrorImage returns the reference of the image in the mirror registry, where the repository of the image is kept,
+// e.g., quay.io/minio/minio:tag is mirrored as <mirror>/minio/minio:tag, and redis:7.2 as <mirror>/library/redis:7.2.
+func MirrorImage(mirror, image string) string {
+	registry, repository := splitImage(image)
+	if registry == "docker.io" && !strings.Contains(repository, "/") {
+		repository = "library/" + repository
+	}
+	return strings.TrimSuffix(mirror, "/") + "/" + repository
+}
+
+// splitImage returns the registry of the image, docker.io for the short names, and the rest of its reference.
+func splitImage(image string) (string, string) {
+	if registry, repository, ok := strings.Cut(image, "/"); ok &&
+		(strings.ContainsAny(registry, ".:") || registry == "localhost") {
+		return registry, repository
+	}
+	return "docker.io", image
+}
+
+// isPublicHost returns whether the host is, or is a sub-domain of, one of the public hosts the tests pull from.
+func isPublicHost(host string) bool {
+	return slices.ContainsFunc(publicHosts, func(public string) bool {
+		return host == public || strings.HasSuffix(host, "."+public)
+	})
+}
+
+// WithDisconnectedProfile returns a runner that, when the disconnected profile is enabled, routes the images of the
+// tests through the mirror registry set with CODEFLARE_TEST_IMAGE_MIRROR, and fails fast, before running the tests,
+// when the Python packages, or the datasets, are still pulled from the public internet, rather than letting the tests
+// time out. It's meant to be called from the TestMain function of the suites.
+func WithDisconnectedProfile(m Runner) Runner {
+	return disconnectedRunner{m}
+}
+
+type disconnectedRunner struct {
+	Runner
+}
+
+func (r disconnectedRunner) Run() int {
+	if !IsDisconnected() {
+		return r.Runner.Run()
+	}
+
+	if mirror, ok := os.LookupEnv(ImageMirrorEnvVar); ok && mirror != "" {
+		for _, reference := range imageReferences {
+			if _, ok := os.LookupEnv(reference.envVar); !ok {
+				os.Setenv(reference.envVar, MirrorImage(mirror, reference.image()))
+			}
+		}
+	}
+
+	violations := publicReferences()
+	for _, reference := range imageReferences {
+		// The images may also be mirrored by the cluster, e.g., with an ImageDigestMirrorSet on OpenShift
+		if registry, _ := splitImage(reference.image()); isPublicHost(registry) {
+			fmt.Fprintf(os.Stderr, "Image %s is pulled from %s, unless the cluster mirrors it, set %s or %s to pull it from a mirror\n",
+				reference.image(), registry, reference.envVar, ImageMirrorEnvVar)
+		}
+	}
+	if len(violations) > 0 {
+		fmt.Fprintf(os.Stderr, "The disconnected profile is enabled, but the tests still reach the public internet:\n  %s\n",
+			strings.Join(violations, "\n  "))
+		return 1
+	}
+
+	return r.Runner.Run()
+}
+
+// publicReferences returns the Python package index, and the datasets not available locally, that are pulled from
+// the public internet, with the environment variable to set to pull them from a mirror.
+func publicReferences() []string {
+	var violations []string
+	if index, err := url.Parse(support.GetPipIndexURL()); err != nil || isPublicHost(index.Hostname()) {
+		violations = append(violations, fmt.Sprintf("Python packages are installed from %s, set PIP_INDEX_URL", support.GetPipIndexURL()))
+	}
+
+	datasetsDir := os.Getenv(DatasetsDirEnvVar)
+	for name, upstream := range datasetURLs {
+		if datasetsDir != "" {
+			if _, err := os.Stat(filepath.Join(datasetsDir, name)); err == nil {
+				continue
+			}
+		}
+		if location, err := url.Parse(upstream()); err != nil || isPublicHost(location.Hostname()) {
+			violations = append(violations, fmt.Sprintf("Dataset %s is downloaded from %s, add it to %s, or set %s_DATASET_URL",
+				name, upstream(), DatasetsDirEnvVar, strings.ToUpper(name)))
+		}
+	}
+	slices.Sort(violations)
+
+	return violations
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"slices"
+	"time"
+
+	"github.com/project-codeflare/codeflare-common/support"
+
+	corev1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DumpEvents prints the Warning events of the namespace, sorted by the time they were last seen,
+// so that scheduling, image pull, or admission failures show up in the test output.
+func DumpEvents(t support.Test, namespace string) {
+	t.T().Helper()
+
+	events, err := t.Client().Core().EventsV1().Events(namespace).List(t.Ctx(), metav1.ListOptions{
+		FieldSelector: "type=" + corev1.EventTypeWarning,
+	})
+	if err != nil {
+		// Troubleshooting is best effort, and must not mask the original failure
+		t.T().Logf("Failed to list events in namespace %s: %v", namespace, err)
+		return
+	}
+
+	warnings := events.Items
+	slices.SortStableFunc(warnings, func(a, b eventsv1.Event) int {
+		return eventLastSeen(a).Compare(eventLastSeen(b))
+	})
+
+	t.T().Logf("Warning events in namespace %s:", namespace)
+	if len(warnings) == 0 {
+		t.T().Logf("  <none>")
+	}
+	for _, event := range warnings {
+		t.T().Logf("  %s  %s/%s  %s (x%d): %s", eventLastSeen(event).Format(time.RFC3339),
+			event.Regarding.Kind, event.Regarding.Name, event.Reason, eventCount(event), event.Note)
+	}
+}
+
+// DumpEventsOnFailure registers DumpEvents to be called for the namespace when the test completes, if it failed.
+// It should be called right after the namespace is created, so that the events are dumped before it's deleted.
+func DumpEventsOnFailure(t support.Test, namespace string) {
+	t.T().Cleanup(func() {
+		if t.T().Failed() {
+			DumpEvents(t, namespace)
+		}
+	})
+}
+
+func eventLastSeen(event eventsv1.Event) time.Time {
+	if event.Series != nil {
+		return event.Series.LastObservedTime.Time
+	}
+	if !event.DeprecatedLastTimestamp.IsZero() {
+		return event.DeprecatedLastTimestamp.Time
+	}
+	return event.EventTime.Time
+}
+
+func eventCount(event eventsv1.Event) int32 {
+	if event.Series != nil {
+		return event.Series.Count
+	}
+	return max(event.DeprecatedCount, 1)
+}
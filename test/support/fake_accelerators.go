@@ -0,0 +1,102 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"encoding/json"
+	"slices"
+	"strconv"
+
+	"github.com/onsi/gomega"
+	"github.com/project-codeflare/codeflare-common/support"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// AdvertiseFakeAccelerators advertises the number of synthetic accelerators as the capacity of the nodes, or of all
+// the schedulable nodes if none is given, and labels them as a device plugin and its feature discovery would, so that
+// the code paths specific to accelerators, like the tolerations, the quotas, or the num-gpus Ray start parameter,
+// can be exercised in CPU-only clusters, like KinD. There's no device plugin, so the containers get no device,
+// and only have to be schedulable. The nodes that already have the accelerators are left untouched, and the
+// others are restored when the test completes. As the nodes are shared, the tests that use it must not run in
+// parallel with the tests that depend on the actual accelerators of the cluster.
+func AdvertiseFakeAccelerators(t support.Test, accelerator Accelerator, count int64, nodeNames ...string) {
+	t.T().Helper()
+
+	nodes, err := t.Client().Core().CoreV1().Nodes().List(t.Ctx(), metav1.ListOptions{})
+	t.Expect(err).NotTo(gomega.HaveOccurred())
+
+	for _, node := range nodes.Items {
+		if len(nodeNames) > 0 && !slices.Contains(nodeNames, node.Name) || len(nodeNames) == 0 && !nodeSchedulable(node) {
+			continue
+		}
+		if quantity, ok := node.Status.Allocatable[accelerator.ResourceName]; ok && !quantity.IsZero() {
+			t.T().Logf("Node %s already has %s %s(s)", node.Name, quantity.String(), accelerator.Name)
+			continue
+		}
+
+		name := node.Name
+		patchFakeAccelerators(t, name, accelerator, strconv.FormatInt(count, 10))
+		t.T().Logf("Advertised %d fake %s(s) on node %s", count, accelerator.Name, name)
+		t.T().Cleanup(func() {
+			patchFakeAccelerators(t, name, accelerator, nil)
+			t.T().Logf("Removed fake %s(s) from node %s", accelerator.Name, name)
+		})
+	}
+}
+
+// patchFakeAccelerators sets, or removes when the quantity is nil, the accelerators in the status of the node, and its label.
+func patchFakeAccelerators(t support.Test, nodeName string, accelerator Accelerator, quantity any) {
+	resources := map[corev1.ResourceName]any{accelerator.ResourceName: quantity}
+	statusPatch, err := json.Marshal(map[string]any{
+		"status": map[string]any{"capacity": resources, "allocatable": resources},
+	})
+	t.Expect(err).NotTo(gomega.HaveOccurred())
+
+	var label any
+	if quantity != nil {
+		label = "true"
+	}
+	labelPatch, err := json.Marshal(map[string]any{
+		"metadata": map[string]any{"labels": map[string]any{accelerator.NodeLabels[0]: label}},
+	})
+	t.Expect(err).NotTo(gomega.HaveOccurred())
+
+	RetryOnConflictOrTransient(t, func() error {
+		_, err := t.Client().Core().CoreV1().Nodes().Patch(t.Ctx(), nodeName, types.MergePatchType, statusPatch, metav1.PatchOptions{}, "status")
+		return err
+	})
+	RetryOnConflictOrTransient(t, func() error {
+		_, err := t.Client().Core().CoreV1().Nodes().Patch(t.Ctx(), nodeName, types.MergePatchType, labelPatch, metav1.PatchOptions{})
+		return err
+	})
+}
+
+// nodeSchedulable returns whether the workloads can be scheduled on the node, without tolerating its taints.
+func nodeSchedulable(node corev1.Node) bool {
+	if node.Spec.Unschedulable {
+		return false
+	}
+	for _, taint := range node.Spec.Taints {
+		if taint.Effect == corev1.TaintEffectNoSchedule || taint.Effect == corev1.TaintEffectNoExecute {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,368 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/onsi/gomega"
+	"github.com/project-codeflare/codeflare-common/support"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kueuev1beta1 "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+)
+
+// FixtureTeardown deletes a shared fixture. It's called with the client of the test that has set the fixture up,
+// as that test may have completed by then.
+type FixtureTeardown func(ctx context.Context, client support.Client) error
+
+// Runner runs the tests of a suite, like testing.M does.
+type Runner interface {
+	Run() int
+}
+
+type fixture struct {
+	key      string
+	mutex    sync.Mutex
+	created  bool
+	value    any
+	refs     int
+	client   support.Client
+	teardown FixtureTeardown
+}
+
+var fixtures = struct {
+	sync.Mutex
+	byKey map[string]*fixture
+	// created holds the fixtures in the order they've been set up, so that they're torn down in the reverse order,
+	// after the fixtures that depend on them
+	created []*fixture
+	// retained is set while the suite holds a reference to all the fixtures
+	retained bool
+}{byKey: map[string]*fixture{}}
+
+// SharedFixture returns the fixture with the given key, that's set up by the first test that requests it, and shared
+// with the tests that request it afterwards, so that the expensive setups, like pre-pulling images or deploying
+// a MinIO instance, are done once per suite rather than once per test. The fixtures are reference counted: each test
+// holds a reference until it completes, and a fixture is torn down once it's no longer referenced. When the suite is
+// run with WithFixtures, the suite holds a reference to the fixtures until it completes. Otherwise, they are only shared
+// by the tests that run concurrently. As the fixture outlives the test that sets it up, the setup must not register
+// cleanup functions with the test, and returns the teardown, if any, instead. A failed setup is retried by the next test.
+func SharedFixture[V any](t support.Test, key string, setup func(t support.Test) (V, FixtureTeardown)) V {
+	t.T().Helper()
+
+	fixtures.Lock()
+	f, ok := fixtures.byKey[key]
+	if !ok {
+		f = &fixture{key: key}
+		fixtures.byKey[key] = f
+	}
+	fixtures.Unlock()
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if !f.created {
+		value, teardown := setup(t)
+		f.value, f.teardown, f.client, f.created = value, teardown, t.Client(), true
+		fixtures.Lock()
+		fixtures.created = append(fixtures.created, f)
+		fixtures.Unlock()
+		t.T().Logf("Set up shared fixture %s", key)
+	}
+	f.refs++
+
+	t.T().Cleanup(func() {
+		f.mutex.Lock()
+		defer f.mutex.Unlock()
+		f.refs--
+
+		fixtures.Lock()
+		retained := fixtures.retained
+		fixtures.Unlock()
+		if f.refs == 0 && !retained {
+			t.Expect(f.tearDown(t.Ctx())).To(gomega.Succeed(), "failed to tear down shared fixture %s", key)
+			t.T().Logf("Tore down shared fixture %s", key)
+		}
+	})
+
+	return f.value.(V)
+}
+
+// tearDown tears the fixture down, and resets it so that it's set up again when requested. The fixture must be locked.
+func (f *fixture) tearDown(ctx context.Context) error {
+	fixtures.Lock()
+	fixtures.created = slices.DeleteFunc(fixtures.created, func(created *fixture) bool { return created == f })
+	fixtures.Unlock()
+
+	teardown, client := f.teardown, f.client
+	f.value, f.teardown, f.client, f.created = nil, nil, nil, false
+	if teardown == nil {
+		return nil
+	}
+	return teardown(ctx, client)
+}
+
+// WithFixtures returns a runner that holds a reference to the shared fixtures while the tests run, so that they are
+// shared by all the tests of the suite, and tears them down once the tests complete. It's meant to be called from
+// the TestMain function of the suites, e.g.:
+//
+//	func TestMain(m *testing.M) {
+//		os.Exit(WithFixtures(m).Run())
+//	}
+func WithFixtures(m Runner) Runner {
+	return fixturesRunner{m}
+}
+
+type fixturesRunner struct {
+	Runner
+}
+
+func (r fixturesRunner) Run() int {
+	fixtures.Lock()
+	fixtures.retained = true
+	fixtures.Unlock()
+
+	defer TeardownFixtures()
+	return r.Runner.Run()
+}
+
+// TeardownFixtures releases the reference the suite holds to the shared fixtures, and tears down those that are no longer
+// referenced by any test, in the reverse order they've been set up in.
+func TeardownFixtures() {
+	fixtures.Lock()
+	fixtures.retained = false
+	created := slices.Clone(fixtures.created)
+	fixtures.Unlock()
+
+	for i := len(created) - 1; i >= 0; i-- {
+		f := created[i]
+		f.mutex.Lock()
+		if f.created && f.refs == 0 {
+			ctx, cancel := context.WithTimeout(context.Background(), support.TestTimeoutMedium)
+			if err := f.tearDown(ctx); err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to tear down shared fixture %s: %v\n", f.key, err)
+			}
+			cancel()
+		}
+		f.mutex.Unlock()
+	}
+}
+
+// SharedTestNamespace returns a namespace shared by the tests, that holds the shared fixtures that are namespaced,
+// like the MinIO instance the datasets are uploaded into. The workloads of the tests must be created in their own namespace.
+func SharedTestNamespace(t support.Test) *corev1.Namespace {
+	t.T().Helper()
+
+	return SharedFixture(t, "namespace", func(t support.Test) (*corev1.Namespace, FixtureTeardown) {
+		namespace := &corev1.Namespace{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: corev1.SchemeGroupVersion.String(),
+				Kind:       "Namespace",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: "test-fixtures-",
+				Labels:       testResourceLabels(),
+			},
+		}
+		namespace, err := t.Client().Core().CoreV1().Namespaces().Create(t.Ctx(), namespace, metav1.CreateOptions{})
+		t.Expect(err).NotTo(gomega.HaveOccurred())
+		t.T().Logf("Created shared test namespace %s", namespace.Name)
+
+		return namespace, func(ctx context.Context, client support.Client) error {
+			propagationPolicy := metav1.DeletePropagationBackground
+			err := client.Core().CoreV1().Namespaces().Delete(ctx, namespace.Name, metav1.DeleteOptions{
+				PropagationPolicy: &propagationPolicy,
+			})
+			if errors.IsNotFound(err) {
+				return nil
+			}
+			return err
+		}
+	})
+}
+
+// SharedKueueResourceFlavor returns an empty ResourceFlavor shared by the tests, e.g., for the ClusterQueues they create.
+func SharedKueueResourceFlavor(t support.Test) *kueuev1beta1.ResourceFlavor {
+	t.T().Helper()
+
+	return SharedFixture(t, "resource-flavor", func(t support.Test) (*kueuev1beta1.ResourceFlavor, FixtureTeardown) {
+		resourceFlavor := &kueuev1beta1.ResourceFlavor{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: kueuev1beta1.SchemeGroupVersion.String(),
+				Kind:       "ResourceFlavor",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: "rf-",
+				Labels:       testResourceLabels(),
+			},
+		}
+		resourceFlavor, err := t.Client().Kueue().KueueV1beta1().ResourceFlavors().Create(t.Ctx(), resourceFlavor, metav1.CreateOptions{})
+		t.Expect(err).NotTo(gomega.HaveOccurred())
+		t.T().Logf("Created Kueue ResourceFlavor %s successfully", resourceFlavor.Name)
+
+		return resourceFlavor, func(ctx context.Context, client support.Client) error {
+			err := client.Kueue().KueueV1beta1().ResourceFlavors().Delete(ctx, resourceFlavor.Name, metav1.DeleteOptions{})
+			if errors.IsNotFound(err) {
+				return nil
+			}
+			return err
+		}
+	})
+}
+
+// SharedKueueClusterQueue returns a ClusterQueue shared by the tests, with the shared ResourceFlavor, and the same
+// quota as the ClusterQueue the e2e setup creates, for the suites that run against clusters not provisioned by it.
+// The tests that depend on the quota available to their workloads must use a dedicated ClusterQueue.
+func SharedKueueClusterQueue(t support.Test) *kueuev1beta1.ClusterQueue {
+	t.T().Helper()
+
+	return SharedFixture(t, "cluster-queue", func(t support.Test) (*kueuev1beta1.ClusterQueue, FixtureTeardown) {
+		resourceFlavor := SharedKueueResourceFlavor(t)
+		clusterQueue := createKueueClusterQueue(t, kueuev1beta1.ClusterQueueSpec{
+			NamespaceSelector: &metav1.LabelSelector{},
+			ResourceGroups: []kueuev1beta1.ResourceGroup{
+				{
+					CoveredResources: []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory},
+					Flavors: []kueuev1beta1.FlavorQuotas{
+						{
+							Name: kueuev1beta1.ResourceFlavorReference(resourceFlavor.Name),
+							Resources: []kueuev1beta1.ResourceQuota{
+								{Name: corev1.ResourceCPU, NominalQuota: resource.MustParse("4")},
+								{Name: corev1.ResourceMemory, NominalQuota: resource.MustParse("4G")},
+							},
+						},
+					},
+				},
+			},
+		})
+
+		return clusterQueue, func(ctx context.Context, client support.Client) error {
+			err := client.Kueue().KueueV1beta1().ClusterQueues().Delete(ctx, clusterQueue.Name, metav1.DeleteOptions{})
+			if errors.IsNotFound(err) {
+				return nil
+			}
+			return err
+		}
+	})
+}
+
+// PrePullImages pulls the images on all the nodes of the architecture of the tests, including the tainted ones, with
+// a DaemonSet shared by the tests that pre-pull the same images, and waits for the images to be pulled, so that the
+// tests that run concurrently don't each wait for the pulls, and their timeouts don't have to account for them.
+// The images must provide a shell.
+func PrePullImages(t support.Test, images ...string) {
+	t.T().Helper()
+
+	images = slices.Clone(images)
+	slices.Sort(images)
+	images = slices.Compact(images)
+	if len(images) == 0 {
+		return
+	}
+
+	SharedFixture(t, "pre-pull/"+strings.Join(images, ","), func(t support.Test) (*appsv1.DaemonSet, FixtureTeardown) {
+		namespace := SharedTestNamespace(t)
+		labels := map[string]string{"app.kubernetes.io/name": "pre-pull"}
+
+		// The images are pulled by the init containers, and the DaemonSet pods only become ready once all are pulled
+		var initContainers []corev1.Container
+		for i, image := range images[1:] {
+			initContainers = append(initContainers, corev1.Container{
+				Name:    fmt.Sprintf("pull-%d", i+1),
+				Image:   image,
+				Command: []string{"sh", "-c", "exit 0"},
+			})
+		}
+		daemonSet := &appsv1.DaemonSet{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: appsv1.SchemeGroupVersion.String(),
+				Kind:       "DaemonSet",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: "pre-pull-",
+				Namespace:    namespace.Name,
+				Labels:       labels,
+			},
+			Spec: appsv1.DaemonSetSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: labels},
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: labels},
+					Spec: corev1.PodSpec{
+						InitContainers: initContainers,
+						Containers: []corev1.Container{
+							{
+								Name:    "pull-0",
+								Image:   images[0],
+								Command: []string{"sh", "-c", "sleep infinity"},
+								Resources: corev1.ResourceRequirements{
+									Requests: corev1.ResourceList{
+										corev1.ResourceCPU:    resource.MustParse("1m"),
+										corev1.ResourceMemory: resource.MustParse("8Mi"),
+									},
+								},
+							},
+						},
+						Tolerations: []corev1.Toleration{
+							{Operator: corev1.TolerationOpExists},
+						},
+						// The images may not be available for the other architectures of multi-arch clusters
+						NodeSelector:                  ArchitectureNodeSelector(),
+						TerminationGracePeriodSeconds: support.Ptr(int64(0)),
+					},
+				},
+			},
+		}
+		daemonSet, err := t.Client().Core().AppsV1().DaemonSets(namespace.Name).Create(t.Ctx(), daemonSet, metav1.CreateOptions{})
+		t.Expect(err).NotTo(gomega.HaveOccurred())
+
+		t.T().Logf("Waiting for image(s) %s to be pulled on all the nodes", strings.Join(images, ", "))
+		t.Eventually(DaemonSet(t, daemonSet.Namespace, daemonSet.Name), support.TestTimeoutLong).
+			Should(gomega.WithTransform(DaemonSetReady, gomega.BeTrue()))
+
+		return daemonSet, func(ctx context.Context, client support.Client) error {
+			err := client.Core().AppsV1().DaemonSets(daemonSet.Namespace).Delete(ctx, daemonSet.Name, metav1.DeleteOptions{})
+			if errors.IsNotFound(err) {
+				return nil
+			}
+			return err
+		}
+	})
+}
+
+// SharedMinIO returns a MinIO instance, with the given bucket, that's deployed into the shared test namespace, and shared
+// by the tests. It's deleted along with the shared test namespace. Each test gets its own copy, so that the port-forwarding
+// used to access the S3 API is set up, and closed, by the test.
+func SharedMinIO(t support.Test, bucket string) *MinIO {
+	t.T().Helper()
+
+	minio := *SharedFixture(t, "minio/"+bucket, func(t support.Test) (*MinIO, FixtureTeardown) {
+		minio := DeployMinIO(t, SharedTestNamespace(t).Name, bucket)
+		minio.localAddress = ""
+		return minio, nil
+	})
+	return &minio
+}
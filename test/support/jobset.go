@@ -0,0 +1,116 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/onsi/gomega"
+	"github.com/project-codeflare/codeflare-common/support"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+	jobsetv1alpha2 "sigs.k8s.io/jobset/api/jobset/v1alpha2"
+	kueuev1beta1 "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+)
+
+// JobSetClient is a typed client for the JobSets of a namespace, as codeflare-common doesn't provide one.
+type JobSetClient struct {
+	client    rest.Interface
+	namespace string
+}
+
+// JobSets returns a client for the JobSets of the namespace.
+func JobSets(t support.Test, namespace string) *JobSetClient {
+	t.T().Helper()
+	return &JobSetClient{client: newRESTClient(t, jobsetv1alpha2.GroupVersion, jobsetv1alpha2.AddToScheme), namespace: namespace}
+}
+
+func (c *JobSetClient) Create(ctx context.Context, jobSet *jobsetv1alpha2.JobSet, opts metav1.CreateOptions) (*jobsetv1alpha2.JobSet, error) {
+	result := &jobsetv1alpha2.JobSet{}
+	err := c.client.Post().
+		Namespace(c.namespace).
+		Resource("jobsets").
+		VersionedParams(&opts, metav1.ParameterCodec).
+		Body(jobSet).
+		Do(ctx).
+		Into(result)
+	return result, err
+}
+
+func (c *JobSetClient) Get(ctx context.Context, name string, opts metav1.GetOptions) (*jobsetv1alpha2.JobSet, error) {
+	result := &jobsetv1alpha2.JobSet{}
+	err := c.client.Get().
+		Namespace(c.namespace).
+		Resource("jobsets").
+		Name(name).
+		VersionedParams(&opts, metav1.ParameterCodec).
+		Do(ctx).
+		Into(result)
+	return result, err
+}
+
+// IsJobSetAvailable returns whether the JobSet API is served, i.e., the JobSet controller is installed.
+func IsJobSetAvailable(t support.Test) bool {
+	t.T().Helper()
+
+	resources, err := t.Client().Core().Discovery().ServerResourcesForGroupVersion(jobsetv1alpha2.GroupVersion.String())
+	if apierrors.IsNotFound(err) {
+		return false
+	}
+	t.Expect(err).NotTo(gomega.HaveOccurred())
+	return slices.ContainsFunc(resources.APIResources, func(resource metav1.APIResource) bool {
+		return resource.Name == "jobsets"
+	})
+}
+
+// JobSet returns a function that gets the JobSet, to be polled with Eventually.
+func JobSet(t support.Test, namespace, name string) func(g gomega.Gomega) *jobsetv1alpha2.JobSet {
+	return func(g gomega.Gomega) *jobsetv1alpha2.JobSet {
+		jobSet, err := JobSets(t, namespace).Get(t.Ctx(), name, metav1.GetOptions{})
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+		return jobSet
+	}
+}
+
+// WorkloadForJobSet returns a function that gets the Kueue Workload created for the JobSet, to be polled with Eventually.
+// The function fails if the Workload doesn't exist yet.
+func WorkloadForJobSet(t support.Test, jobSet *jobsetv1alpha2.JobSet) func(g gomega.Gomega) *kueuev1beta1.Workload {
+	return func(g gomega.Gomega) *kueuev1beta1.Workload {
+		for _, workload := range Workloads(t, jobSet.Namespace)(g) {
+			if workloadOwnedBy(workload, "JobSet", jobSet) {
+				return workload
+			}
+		}
+		g.Expect(fmt.Errorf("no Kueue Workload for JobSet %s/%s", jobSet.Namespace, jobSet.Name)).NotTo(gomega.HaveOccurred())
+		return nil
+	}
+}
+
+// JobSetCompleted returns whether the JobSet has completed successfully.
+func JobSetCompleted(jobSet *jobsetv1alpha2.JobSet) bool {
+	return apimeta.IsStatusConditionTrue(jobSet.Status.Conditions, string(jobsetv1alpha2.JobSetCompleted))
+}
+
+// JobSetFailed returns whether the JobSet has failed.
+func JobSetFailed(jobSet *jobsetv1alpha2.JobSet) bool {
+	return apimeta.IsStatusConditionTrue(jobSet.Status.Conditions, string(jobsetv1alpha2.JobSetFailed))
+}
@@ -0,0 +1,162 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"context"
+	"slices"
+
+	kubeflowv1 "github.com/kubeflow/training-operator/pkg/apis/kubeflow.org/v1"
+	"github.com/onsi/gomega"
+	"github.com/project-codeflare/codeflare-common/support"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+)
+
+// KubeflowV1Client is a typed client for the kubeflow.org/v1 API group of the Kubeflow Training Operator.
+type KubeflowV1Client struct {
+	client rest.Interface
+}
+
+// PyTorchJobClient is a typed client for the PyTorchJobs of a namespace.
+type PyTorchJobClient struct {
+	client    rest.Interface
+	namespace string
+}
+
+// Kubeflow returns a typed client for the kubeflow.org/v1 API group, as codeflare-common doesn't provide one.
+func Kubeflow(t support.Test) *KubeflowV1Client {
+	t.T().Helper()
+	return &KubeflowV1Client{client: newRESTClient(t, kubeflowv1.GroupVersion, kubeflowv1.AddToScheme)}
+}
+
+// PyTorchJobs returns a client for the PyTorchJobs of the namespace.
+func (c *KubeflowV1Client) PyTorchJobs(namespace string) *PyTorchJobClient {
+	return &PyTorchJobClient{client: c.client, namespace: namespace}
+}
+
+func (c *PyTorchJobClient) Create(ctx context.Context, job *kubeflowv1.PyTorchJob, opts metav1.CreateOptions) (*kubeflowv1.PyTorchJob, error) {
+	result := &kubeflowv1.PyTorchJob{}
+	err := c.client.Post().
+		Namespace(c.namespace).
+		Resource("pytorchjobs").
+		VersionedParams(&opts, metav1.ParameterCodec).
+		Body(job).
+		Do(ctx).
+		Into(result)
+	return result, err
+}
+
+func (c *PyTorchJobClient) Get(ctx context.Context, name string, opts metav1.GetOptions) (*kubeflowv1.PyTorchJob, error) {
+	result := &kubeflowv1.PyTorchJob{}
+	err := c.client.Get().
+		Namespace(c.namespace).
+		Resource("pytorchjobs").
+		Name(name).
+		VersionedParams(&opts, metav1.ParameterCodec).
+		Do(ctx).
+		Into(result)
+	return result, err
+}
+
+func (c *PyTorchJobClient) List(ctx context.Context, opts metav1.ListOptions) (*kubeflowv1.PyTorchJobList, error) {
+	result := &kubeflowv1.PyTorchJobList{}
+	err := c.client.Get().
+		Namespace(c.namespace).
+		Resource("pytorchjobs").
+		VersionedParams(&opts, metav1.ParameterCodec).
+		Do(ctx).
+		Into(result)
+	return result, err
+}
+
+func (c *PyTorchJobClient) Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error {
+	return c.client.Delete().
+		Namespace(c.namespace).
+		Resource("pytorchjobs").
+		Name(name).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// IsPyTorchJobAvailable returns whether the PyTorchJob API is served, i.e., the Training Operator is installed.
+func IsPyTorchJobAvailable(t support.Test) bool {
+	t.T().Helper()
+
+	resources, err := t.Client().Core().Discovery().ServerResourcesForGroupVersion(kubeflowv1.GroupVersion.String())
+	if apierrors.IsNotFound(err) {
+		return false
+	}
+	t.Expect(err).NotTo(gomega.HaveOccurred())
+	return slices.ContainsFunc(resources.APIResources, func(resource metav1.APIResource) bool {
+		return resource.Name == "pytorchjobs"
+	})
+}
+
+// PyTorchJob returns a function that gets the PyTorchJob, to be polled with Eventually.
+func PyTorchJob(t support.Test, namespace, name string) func(g gomega.Gomega) *kubeflowv1.PyTorchJob {
+	return func(g gomega.Gomega) *kubeflowv1.PyTorchJob {
+		job, err := Kubeflow(t).PyTorchJobs(namespace).Get(t.Ctx(), name, metav1.GetOptions{})
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+		return job
+	}
+}
+
+func GetPyTorchJob(t support.Test, namespace, name string) *kubeflowv1.PyTorchJob {
+	t.T().Helper()
+	return PyTorchJob(t, namespace, name)(t)
+}
+
+// PyTorchJobConditionStatus returns a transform that gets the status of the PyTorchJob condition,
+// or Unknown if the condition isn't reported, e.g.:
+//
+//	test.Eventually(PyTorchJob(test, namespace, name), TestTimeoutLong).
+//		Should(WithTransform(PyTorchJobConditionStatus(kubeflowv1.JobSucceeded), Equal(corev1.ConditionTrue)))
+func PyTorchJobConditionStatus(conditionType kubeflowv1.JobConditionType) func(job *kubeflowv1.PyTorchJob) corev1.ConditionStatus {
+	return func(job *kubeflowv1.PyTorchJob) corev1.ConditionStatus {
+		for _, condition := range job.Status.Conditions {
+			if condition.Type == conditionType {
+				return condition.Status
+			}
+		}
+		return corev1.ConditionUnknown
+	}
+}
+
+// PyTorchJobRunning returns whether the PyTorchJob is running.
+func PyTorchJobRunning(job *kubeflowv1.PyTorchJob) bool {
+	return PyTorchJobConditionStatus(kubeflowv1.JobRunning)(job) == corev1.ConditionTrue
+}
+
+// PyTorchJobSucceeded returns whether the PyTorchJob has completed successfully.
+func PyTorchJobSucceeded(job *kubeflowv1.PyTorchJob) bool {
+	return PyTorchJobConditionStatus(kubeflowv1.JobSucceeded)(job) == corev1.ConditionTrue
+}
+
+// PyTorchJobFailed returns whether the PyTorchJob has failed.
+func PyTorchJobFailed(job *kubeflowv1.PyTorchJob) bool {
+	return PyTorchJobConditionStatus(kubeflowv1.JobFailed)(job) == corev1.ConditionTrue
+}
+
+// PyTorchJobSuspended returns whether the PyTorchJob is suspended, e.g., while it's waiting to be admitted by Kueue.
+func PyTorchJobSuspended(job *kubeflowv1.PyTorchJob) bool {
+	return PyTorchJobConditionStatus(kubeflowv1.JobSuspended)(job) == corev1.ConditionTrue
+}
@@ -0,0 +1,266 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"fmt"
+
+	"github.com/onsi/gomega"
+	"github.com/onsi/gomega/gcustom"
+	"github.com/onsi/gomega/types"
+	"github.com/project-codeflare/codeflare-common/support"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kueuev1beta1 "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+)
+
+// CreateKueueWorkloadPriorityClass creates a WorkloadPriorityClass with the given value,
+// that's deleted once the test completes.
+func CreateKueueWorkloadPriorityClass(t support.Test, value int32) *kueuev1beta1.WorkloadPriorityClass {
+	t.T().Helper()
+
+	priorityClass := &kueuev1beta1.WorkloadPriorityClass{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: kueuev1beta1.SchemeGroupVersion.String(),
+			Kind:       "WorkloadPriorityClass",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "wpc-",
+			Labels:       testResourceLabels(),
+		},
+		Value: value,
+	}
+
+	priorityClass, err := t.Client().Kueue().KueueV1beta1().WorkloadPriorityClasses().Create(t.Ctx(), priorityClass, metav1.CreateOptions{})
+	t.Expect(err).NotTo(gomega.HaveOccurred())
+	t.T().Logf("Created Kueue WorkloadPriorityClass %s with value %d successfully", priorityClass.Name, priorityClass.Value)
+	t.T().Cleanup(func() {
+		err := t.Client().Kueue().KueueV1beta1().WorkloadPriorityClasses().Delete(t.Ctx(), priorityClass.Name, metav1.DeleteOptions{})
+		t.Expect(err).NotTo(gomega.HaveOccurred())
+	})
+
+	return priorityClass
+}
+
+// CreateTestKueueClusterQueue creates a ClusterQueue, labelled as a test resource so that it's reaped if the test
+// is aborted, and deletes it once the test completes.
+func CreateTestKueueClusterQueue(t support.Test, clusterQueueSpec kueuev1beta1.ClusterQueueSpec) *kueuev1beta1.ClusterQueue {
+	t.T().Helper()
+
+	clusterQueue := createKueueClusterQueue(t, clusterQueueSpec)
+	t.T().Cleanup(func() {
+		err := t.Client().Kueue().KueueV1beta1().ClusterQueues().Delete(t.Ctx(), clusterQueue.Name, metav1.DeleteOptions{})
+		t.Expect(err).NotTo(gomega.HaveOccurred())
+	})
+
+	return clusterQueue
+}
+
+// createKueueClusterQueue creates a ClusterQueue labelled as a test resource.
+func createKueueClusterQueue(t support.Test, clusterQueueSpec kueuev1beta1.ClusterQueueSpec) *kueuev1beta1.ClusterQueue {
+	t.T().Helper()
+
+	clusterQueue := &kueuev1beta1.ClusterQueue{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: kueuev1beta1.SchemeGroupVersion.String(),
+			Kind:       "ClusterQueue",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "cq-",
+			Labels:       testResourceLabels(),
+		},
+		Spec: clusterQueueSpec,
+	}
+
+	clusterQueue, err := t.Client().Kueue().KueueV1beta1().ClusterQueues().Create(t.Ctx(), clusterQueue, metav1.CreateOptions{})
+	t.Expect(err).NotTo(gomega.HaveOccurred())
+	t.T().Logf("Created Kueue ClusterQueue %s successfully", clusterQueue.Name)
+
+	return clusterQueue
+}
+
+// Workloads returns a function that lists the Kueue Workloads of the namespace, to be polled with Eventually.
+func Workloads(t support.Test, namespace string) func(g gomega.Gomega) []*kueuev1beta1.Workload {
+	return func(g gomega.Gomega) []*kueuev1beta1.Workload {
+		workloads, err := t.Client().Kueue().KueueV1beta1().Workloads(namespace).List(t.Ctx(), metav1.ListOptions{})
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+
+		var items []*kueuev1beta1.Workload
+		for i := range workloads.Items {
+			items = append(items, &workloads.Items[i])
+		}
+		return items
+	}
+}
+
+// WorkloadForRayCluster returns a function that gets the Kueue Workload created for the RayCluster, to be polled with Eventually.
+// The function fails if the Workload doesn't exist yet.
+func WorkloadForRayCluster(t support.Test, rayCluster *rayv1.RayCluster) func(g gomega.Gomega) *kueuev1beta1.Workload {
+	return func(g gomega.Gomega) *kueuev1beta1.Workload {
+		for _, workload := range Workloads(t, rayCluster.Namespace)(g) {
+			if workloadOwnedBy(workload, "RayCluster", rayCluster) {
+				return workload
+			}
+		}
+		g.Expect(fmt.Errorf("no Kueue Workload for RayCluster %s/%s", rayCluster.Namespace, rayCluster.Name)).NotTo(gomega.HaveOccurred())
+		return nil
+	}
+}
+
+// GetWorkloadForRayCluster returns the Kueue Workload created for the RayCluster, and fails the test if it doesn't exist.
+func GetWorkloadForRayCluster(t support.Test, rayCluster *rayv1.RayCluster) *kueuev1beta1.Workload {
+	t.T().Helper()
+	return WorkloadForRayCluster(t, rayCluster)(t)
+}
+
+// WorkloadForRayJob returns a function that gets the Kueue Workload created for the RayJob, to be polled with Eventually.
+// The function fails if the Workload doesn't exist yet.
+func WorkloadForRayJob(t support.Test, rayJob *rayv1.RayJob) func(g gomega.Gomega) *kueuev1beta1.Workload {
+	return func(g gomega.Gomega) *kueuev1beta1.Workload {
+		for _, workload := range Workloads(t, rayJob.Namespace)(g) {
+			if workloadOwnedBy(workload, "RayJob", rayJob) {
+				return workload
+			}
+		}
+		g.Expect(fmt.Errorf("no Kueue Workload for RayJob %s/%s", rayJob.Namespace, rayJob.Name)).NotTo(gomega.HaveOccurred())
+		return nil
+	}
+}
+
+// WorkloadForJob returns a function that gets the Kueue Workload created for the batch Job, to be polled with Eventually.
+// The function fails if the Workload doesn't exist yet.
+func WorkloadForJob(t support.Test, job *batchv1.Job) func(g gomega.Gomega) *kueuev1beta1.Workload {
+	return func(g gomega.Gomega) *kueuev1beta1.Workload {
+		for _, workload := range Workloads(t, job.Namespace)(g) {
+			if workloadOwnedBy(workload, "Job", job) {
+				return workload
+			}
+		}
+		g.Expect(fmt.Errorf("no Kueue Workload for Job %s/%s", job.Namespace, job.Name)).NotTo(gomega.HaveOccurred())
+		return nil
+	}
+}
+
+// WorkloadAdmitted returns whether the Workload has been admitted by its ClusterQueue.
+func WorkloadAdmitted(workload *kueuev1beta1.Workload) bool {
+	return apimeta.IsStatusConditionTrue(workload.Status.Conditions, kueuev1beta1.WorkloadAdmitted)
+}
+
+// WorkloadFinished returns whether the job of the Workload has completed, and its quota has been released.
+func WorkloadFinished(workload *kueuev1beta1.Workload) bool {
+	return apimeta.IsStatusConditionTrue(workload.Status.Conditions, kueuev1beta1.WorkloadFinished)
+}
+
+// WorkloadPreempted returns whether the Workload has been evicted to make room for a higher priority Workload,
+// or to reclaim the quota it borrowed from its cohort.
+func WorkloadPreempted(workload *kueuev1beta1.Workload) bool {
+	condition := apimeta.FindStatusCondition(workload.Status.Conditions, kueuev1beta1.WorkloadEvicted)
+	return condition != nil && condition.Status == metav1.ConditionTrue && condition.Reason == kueuev1beta1.WorkloadEvictedByPreemption
+}
+
+// workloadOwnedBy returns whether the Workload is owned by the object of the given kind. The UID is compared, so that
+// the Workload of a previous object with the same name isn't matched.
+func workloadOwnedBy(workload *kueuev1beta1.Workload, kind string, object metav1.Object) bool {
+	for _, owner := range workload.OwnerReferences {
+		if owner.Kind == kind && owner.Name == object.GetName() && (object.GetUID() == "" || owner.UID == object.GetUID()) {
+			return true
+		}
+	}
+	return false
+}
+
+// KueueClusterQueue returns a function that gets the ClusterQueue, to be polled with Eventually.
+func KueueClusterQueue(t support.Test, name string) func(g gomega.Gomega) *kueuev1beta1.ClusterQueue {
+	return func(g gomega.Gomega) *kueuev1beta1.ClusterQueue {
+		clusterQueue, err := t.Client().Kueue().KueueV1beta1().ClusterQueues().Get(t.Ctx(), name, metav1.GetOptions{})
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+		return clusterQueue
+	}
+}
+
+// KueueClusterQueueBorrowed returns the quota the ClusterQueue borrows from its cohort, summed across all the flavors.
+func KueueClusterQueueBorrowed(clusterQueue *kueuev1beta1.ClusterQueue) corev1.ResourceList {
+	borrowed := corev1.ResourceList{}
+	for _, flavor := range clusterQueue.Status.FlavorsUsage {
+		for _, usage := range flavor.Resources {
+			quantity := borrowed[usage.Name]
+			quantity.Add(usage.Borrowed)
+			borrowed[usage.Name] = quantity
+		}
+	}
+	return borrowed
+}
+
+// ClusterQueueResourceUsage is the quota of a resource of a flavor used by the Workloads admitted in a ClusterQueue,
+// and the part of it that's borrowed from the cohort.
+type ClusterQueueResourceUsage struct {
+	Total    resource.Quantity
+	Borrowed resource.Quantity
+}
+
+// ClusterQueueUsage is the quota used by the Workloads admitted in a ClusterQueue, indexed by flavor and resource.
+type ClusterQueueUsage map[string]map[corev1.ResourceName]ClusterQueueResourceUsage
+
+// KueueClusterQueueUsage returns the quota used by the Workloads admitted in the ClusterQueue, per flavor and resource.
+func KueueClusterQueueUsage(clusterQueue *kueuev1beta1.ClusterQueue) ClusterQueueUsage {
+	usage := ClusterQueueUsage{}
+	for _, flavor := range clusterQueue.Status.FlavorsUsage {
+		resources := map[corev1.ResourceName]ClusterQueueResourceUsage{}
+		for _, resourceUsage := range flavor.Resources {
+			resources[resourceUsage.Name] = ClusterQueueResourceUsage{Total: resourceUsage.Total, Borrowed: resourceUsage.Borrowed}
+		}
+		usage[string(flavor.Name)] = resources
+	}
+	return usage
+}
+
+// GetClusterQueueUsage returns the quota used by the Workloads admitted in the ClusterQueue, per flavor and resource.
+func GetClusterQueueUsage(t support.Test, name string) ClusterQueueUsage {
+	t.T().Helper()
+	return KueueClusterQueueUsage(KueueClusterQueue(t, name)(t))
+}
+
+// HaveFlavorUsage succeeds if the ClusterQueueUsage has exactly the quantity of the resource of the flavor in use, e.g.:
+//
+//	test.Eventually(KueueClusterQueue(test, name), TestTimeoutShort).
+//		Should(WithTransform(KueueClusterQueueUsage, HaveFlavorUsage("default-flavor", corev1.ResourceCPU, "1750m")))
+func HaveFlavorUsage(flavor string, resourceName corev1.ResourceName, quantity string) types.GomegaMatcher {
+	return haveFlavorResourceUsage(flavor, resourceName, quantity, "usage", func(usage ClusterQueueResourceUsage) resource.Quantity {
+		return usage.Total
+	})
+}
+
+// HaveFlavorBorrowed succeeds if the ClusterQueueUsage has exactly the quantity of the resource of the flavor borrowed from the cohort.
+func HaveFlavorBorrowed(flavor string, resourceName corev1.ResourceName, quantity string) types.GomegaMatcher {
+	return haveFlavorResourceUsage(flavor, resourceName, quantity, "borrowed", func(usage ClusterQueueResourceUsage) resource.Quantity {
+		return usage.Borrowed
+	})
+}
+
+func haveFlavorResourceUsage(flavor string, resourceName corev1.ResourceName, quantity, kind string, get func(ClusterQueueResourceUsage) resource.Quantity) types.GomegaMatcher {
+	expected := resource.MustParse(quantity)
+	return gcustom.MakeMatcher(func(usage ClusterQueueUsage) (bool, error) {
+		// The usage of the resources that aren't in use may not be reported
+		actual := get(usage[flavor][resourceName])
+		return actual.Cmp(expected) == 0, nil
+	}).WithTemplate("Expected:\n{{.FormattedActual}}\n{{.To}} have {{.Data}}", fmt.Sprintf("%s %s of flavor %s equal to %s", resourceName, kind, flavor, quantity))
+}
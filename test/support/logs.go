@@ -0,0 +1,102 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"bufio"
+	"context"
+	"time"
+
+	"github.com/project-codeflare/codeflare-common/support"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// podLogsRetryInterval is the interval between attempts to (re-)open the log stream of a container,
+// e.g., while it's waiting to start, or after it's been restarted.
+const podLogsRetryInterval = 5 * time.Second
+
+// StreamPodLogs follows the logs of the container of the Pod in the background, and interleaves them into the
+// test output, prefixed with the Pod and container names, so that long-running workloads, like training jobs,
+// report their progress as the test runs, rather than only when it fails.
+// The stream is re-opened when it's interrupted, until the Pod terminates or the test completes.
+func StreamPodLogs(t support.Test, pod *corev1.Pod, container string) {
+	t.T().Helper()
+
+	ctx, cancel := context.WithCancel(t.Ctx())
+	done := make(chan struct{})
+	// The goroutine must not log once the test has completed
+	t.T().Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	prefix := pod.Namespace + "/" + pod.Name + "/" + container
+	t.T().Logf("Streaming logs of container %s of Pod %s/%s", container, pod.Namespace, pod.Name)
+
+	go func() {
+		defer close(done)
+
+		var since *metav1.Time
+		for {
+			opened := streamContainerLogs(ctx, t, pod, container, since, prefix)
+			if opened {
+				since = &metav1.Time{Time: time.Now()}
+			}
+			if ctx.Err() != nil || podTerminated(ctx, t, pod) {
+				return
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(podLogsRetryInterval):
+			}
+		}
+	}()
+}
+
+func streamContainerLogs(ctx context.Context, t support.Test, pod *corev1.Pod, container string, since *metav1.Time, prefix string) bool {
+	stream, err := t.Client().Core().CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
+		Container: container,
+		Follow:    true,
+		SinceTime: since,
+	}).Stream(ctx)
+	if err != nil {
+		// The container may not have started yet
+		return false
+	}
+	defer stream.Close()
+
+	scanner := bufio.NewScanner(stream)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		t.T().Logf("[%s] %s", prefix, scanner.Text())
+	}
+	return true
+}
+
+func podTerminated(ctx context.Context, t support.Test, pod *corev1.Pod) bool {
+	current, err := t.Client().Core().CoreV1().Pods(pod.Namespace).Get(ctx, pod.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return true
+	} else if err != nil {
+		return false
+	}
+	return current.Status.Phase == corev1.PodSucceeded || current.Status.Phase == corev1.PodFailed
+}
@@ -0,0 +1,178 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"math"
+	"net/http"
+	"slices"
+
+	"github.com/onsi/gomega"
+	"github.com/project-codeflare/codeflare-common/support"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+)
+
+const (
+	// operatorMetricsService is the Service exposing the Prometheus endpoint of the controller manager of the operator.
+	operatorMetricsService = "codeflare-operator-manager-metrics"
+	operatorMetricsPort    = 8080
+)
+
+// Metrics are the metric families scraped from a Prometheus endpoint, indexed by name.
+type Metrics map[string]*dto.MetricFamily
+
+// OperatorMetrics returns a function that scrapes the Prometheus endpoint of the operator, to be polled with Eventually,
+// e.g., to assert the latency of its webhooks, or its memory usage, with the metrics exported by controller-runtime:
+//
+//	test.Expect(GetOperatorMetrics(test).Value("process_resident_memory_bytes", nil)).
+//		To(BeNumerically("<", 512*1024*1024))
+//
+// The port is forwarded once, when the function is created.
+func OperatorMetrics(t support.Test) func(g gomega.Gomega) Metrics {
+	t.T().Helper()
+
+	endpoint := "http://" + SetupPortForward(t, GetOperatorNamespace(), operatorMetricsService, operatorMetricsPort) + "/metrics"
+
+	return func(g gomega.Gomega) Metrics {
+		return scrapeMetrics(t, g, endpoint)
+	}
+}
+
+func GetOperatorMetrics(t support.Test) Metrics {
+	t.T().Helper()
+	return OperatorMetrics(t)(t)
+}
+
+// Value returns the sum of the values of the samples of the metric, whose labels match the labels,
+// e.g., Value("ray_tasks", map[string]string{"State": "FINISHED"}), or 0 if there's none.
+func (m Metrics) Value(name string, labels map[string]string) float64 {
+	family, ok := m[name]
+	if !ok {
+		return 0
+	}
+	sum := 0.0
+	for _, metric := range family.GetMetric() {
+		if !metricHasLabels(metric, labels) {
+			continue
+		}
+		switch {
+		case metric.GetGauge() != nil:
+			sum += metric.GetGauge().GetValue()
+		case metric.GetCounter() != nil:
+			sum += metric.GetCounter().GetValue()
+		case metric.GetUntyped() != nil:
+			sum += metric.GetUntyped().GetValue()
+		case metric.GetHistogram() != nil:
+			sum += metric.GetHistogram().GetSampleSum()
+		case metric.GetSummary() != nil:
+			sum += metric.GetSummary().GetSampleSum()
+		}
+	}
+	return sum
+}
+
+// Histogram returns the observations of the histogram metric, merged across its samples whose labels match the labels.
+func (m Metrics) Histogram(name string, labels map[string]string) Histogram {
+	histogram := Histogram{Buckets: map[float64]uint64{}}
+	family, ok := m[name]
+	if !ok {
+		return histogram
+	}
+	for _, metric := range family.GetMetric() {
+		if metric.GetHistogram() == nil || !metricHasLabels(metric, labels) {
+			continue
+		}
+		histogram.Count += metric.GetHistogram().GetSampleCount()
+		for _, bucket := range metric.GetHistogram().GetBucket() {
+			histogram.Buckets[bucket.GetUpperBound()] += bucket.GetCumulativeCount()
+		}
+	}
+	return histogram
+}
+
+// Histogram is the number of observations of a histogram metric, and their cumulative count per bucket upper bound.
+type Histogram struct {
+	Count   uint64
+	Buckets map[float64]uint64
+}
+
+// Sub returns the observations made since the earlier histogram, e.g., to only account for those made during a test.
+func (h Histogram) Sub(earlier Histogram) Histogram {
+	histogram := Histogram{Count: h.Count - earlier.Count, Buckets: map[float64]uint64{}}
+	for bound, count := range h.Buckets {
+		histogram.Buckets[bound] = count - earlier.Buckets[bound]
+	}
+	return histogram
+}
+
+// Quantile estimates the q-quantile, e.g., 0.99, of the observations, by linear interpolation within the bucket
+// it falls into, like the histogram_quantile function of Prometheus does. It returns NaN if there's no observation,
+// and the largest finite upper bound if the quantile falls into the +Inf bucket.
+func (h Histogram) Quantile(q float64) float64 {
+	if h.Count == 0 {
+		return math.NaN()
+	}
+	var bounds []float64
+	for bound := range h.Buckets {
+		if !math.IsInf(bound, 1) {
+			bounds = append(bounds, bound)
+		}
+	}
+	slices.Sort(bounds)
+
+	rank := q * float64(h.Count)
+	lower, below := 0.0, uint64(0)
+	for _, bound := range bounds {
+		count := h.Buckets[bound]
+		if float64(count) >= rank {
+			if count == below {
+				return bound
+			}
+			return lower + (bound-lower)*(rank-float64(below))/float64(count-below)
+		}
+		lower, below = bound, count
+	}
+	return lower
+}
+
+func scrapeMetrics(t support.Test, g gomega.Gomega, endpoint string) Metrics {
+	request, err := http.NewRequestWithContext(t.Ctx(), http.MethodGet, endpoint, nil)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	response, err := http.DefaultClient.Do(request)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	defer response.Body.Close()
+	g.Expect(response.StatusCode).To(gomega.Equal(http.StatusOK))
+
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(response.Body)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	return families
+}
+
+func metricHasLabels(metric *dto.Metric, labels map[string]string) bool {
+	matched := 0
+	for _, pair := range metric.GetLabel() {
+		if value, ok := labels[pair.GetName()]; ok {
+			if value != pair.GetValue() {
+				return false
+			}
+			matched++
+		}
+	}
+	return matched == len(labels)
+}
@@ -0,0 +1,306 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/onsi/gomega"
+	"github.com/project-codeflare/codeflare-common/support"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	// MinIOImageEnvVar is the environment variable of the MinIO image, e.g., to pull it from a mirror registry.
+	MinIOImageEnvVar = "CODEFLARE_TEST_MINIO_IMAGE"
+
+	defaultMinIOImage = "quay.io/minio/minio:RELEASE.2024-06-13T22-53-53Z"
+	minioName         = "minio"
+	minioPort         = 9000
+	minioRegion       = "us-east-1"
+)
+
+// MinIO is an S3 compatible object storage, deployed in a test namespace.
+type MinIO struct {
+	Namespace string
+	// Endpoint is the URL of the S3 API, reachable from within the cluster.
+	Endpoint  string
+	AccessKey string
+	SecretKey string
+	// SecretName is the name of the Secret holding the AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, AWS_ENDPOINT_URL,
+	// and AWS_DEFAULT_REGION variables, to be set with envFrom in the pods that access the storage.
+	SecretName string
+
+	localAddress string
+}
+
+// DeployMinIO deploys an ephemeral MinIO instance into the namespace, waits for it to be ready, and creates the buckets.
+// The instance stores its data in an emptyDir volume, and is deleted along with the namespace.
+func DeployMinIO(t support.Test, namespace string, buckets ...string) *MinIO {
+	t.T().Helper()
+
+	minio := &MinIO{
+		Namespace:  namespace,
+		Endpoint:   fmt.Sprintf("http://%s.%s.svc:%d", minioName, namespace, minioPort),
+		AccessKey:  randomHex(t, 10),
+		SecretKey:  randomHex(t, 20),
+		SecretName: minioName + "-credentials",
+	}
+	labels := map[string]string{"app.kubernetes.io/name": minioName}
+
+	secret := &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.String(),
+			Kind:       "Secret",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      minio.SecretName,
+			Namespace: namespace,
+		},
+		StringData: map[string]string{
+			"AWS_ACCESS_KEY_ID":     minio.AccessKey,
+			"AWS_SECRET_ACCESS_KEY": minio.SecretKey,
+			"AWS_ENDPOINT_URL":      minio.Endpoint,
+			"AWS_DEFAULT_REGION":    minioRegion,
+		},
+	}
+	_, err := t.Client().Core().CoreV1().Secrets(namespace).Create(t.Ctx(), secret, metav1.CreateOptions{})
+	t.Expect(err).NotTo(gomega.HaveOccurred())
+
+	deployment := &appsv1.Deployment{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: appsv1.SchemeGroupVersion.String(),
+			Kind:       "Deployment",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      minioName,
+			Namespace: namespace,
+			Labels:    labels,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: ptr.To(int32(1)),
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:  minioName,
+							Image: minioImage(),
+							Args:  []string{"server", "/data"},
+							Env: []corev1.EnvVar{
+								{Name: "MINIO_ROOT_USER", Value: minio.AccessKey},
+								{Name: "MINIO_ROOT_PASSWORD", Value: minio.SecretKey},
+							},
+							Ports: []corev1.ContainerPort{
+								{Name: "s3", ContainerPort: minioPort},
+							},
+							ReadinessProbe: &corev1.Probe{
+								ProbeHandler: corev1.ProbeHandler{
+									HTTPGet: &corev1.HTTPGetAction{Path: "/minio/health/ready", Port: intstr.FromInt32(minioPort)},
+								},
+								PeriodSeconds: 2,
+							},
+							SecurityContext: &corev1.SecurityContext{
+								AllowPrivilegeEscalation: ptr.To(false),
+								Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+								SeccompProfile:           &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+							},
+							VolumeMounts: []corev1.VolumeMount{
+								{Name: "data", MountPath: "/data"},
+							},
+						},
+					},
+					Volumes: []corev1.Volume{
+						{Name: "data", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+					},
+				},
+			},
+		},
+	}
+	_, err = t.Client().Core().AppsV1().Deployments(namespace).Create(t.Ctx(), deployment, metav1.CreateOptions{})
+	t.Expect(err).NotTo(gomega.HaveOccurred())
+
+	service := &corev1.Service{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.String(),
+			Kind:       "Service",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      minioName,
+			Namespace: namespace,
+			Labels:    labels,
+		},
+		Spec: corev1.ServiceSpec{
+			Selector: labels,
+			Ports: []corev1.ServicePort{
+				{Name: "s3", Port: minioPort, TargetPort: intstr.FromString("s3")},
+			},
+		},
+	}
+	_, err = t.Client().Core().CoreV1().Services(namespace).Create(t.Ctx(), service, metav1.CreateOptions{})
+	t.Expect(err).NotTo(gomega.HaveOccurred())
+
+	t.T().Logf("Waiting for MinIO %s/%s to be ready", namespace, minioName)
+	t.Eventually(Deployment(t, namespace, minioName), support.TestTimeoutMedium).
+		Should(gomega.WithTransform(DeploymentReady, gomega.BeTrue()))
+	t.T().Logf("Deployed MinIO %s/%s, available at %s", namespace, minioName, minio.Endpoint)
+
+	for _, bucket := range buckets {
+		minio.CreateBucket(t, bucket)
+	}
+
+	return minio
+}
+
+// CreateBucket creates the bucket, if it doesn't exist already.
+func (m *MinIO) CreateBucket(t support.Test, bucket string) {
+	t.T().Helper()
+
+	response := m.do(t, http.MethodPut, "/"+bucket, "", nil)
+	if response.statusCode == http.StatusConflict && strings.Contains(string(response.body), "BucketAlreadyOwnedByYou") {
+		return
+	}
+	t.Expect(response.statusCode).To(gomega.Equal(http.StatusOK), "failed to create bucket %s: %s", bucket, response.body)
+	t.T().Logf("Created bucket %s in MinIO %s/%s", bucket, m.Namespace, minioName)
+}
+
+// PutObject stores the data as the object with the given key in the bucket.
+func (m *MinIO) PutObject(t support.Test, bucket, key string, data []byte) {
+	t.T().Helper()
+
+	response := m.do(t, http.MethodPut, "/"+bucket+"/"+key, "", data)
+	t.Expect(response.statusCode).To(gomega.Equal(http.StatusOK), "failed to put object %s/%s: %s", bucket, key, response.body)
+}
+
+// AllowAnonymousRead sets the policy of the bucket so that the objects, whose key starts with the prefix,
+// can be downloaded without credentials, e.g., by training scripts that fetch their dataset over plain HTTP.
+func (m *MinIO) AllowAnonymousRead(t support.Test, bucket, prefix string) {
+	t.T().Helper()
+
+	policy := fmt.Sprintf(`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"AWS":["*"]},"Action":["s3:GetObject"],"Resource":["arn:aws:s3:::%s/%s*"]}]}`,
+		bucket, prefix)
+	response := m.do(t, http.MethodPut, "/"+bucket, "policy=", []byte(policy))
+	t.Expect(response.statusCode).To(gomega.BeElementOf(http.StatusOK, http.StatusNoContent),
+		"failed to set policy of bucket %s: %s", bucket, response.body)
+}
+
+type s3Response struct {
+	statusCode int
+	body       []byte
+}
+
+// do sends a request to the S3 API, through a port-forwarding to the MinIO service, that's set up on first use.
+// The path must not need escaping beyond what url.URL does, which holds for bucket names and typical object keys,
+// and the query must already be in canonical form, i.e., with sorted and escaped parameters.
+func (m *MinIO) do(t support.Test, method, path, query string, body []byte) s3Response {
+	t.T().Helper()
+
+	if m.localAddress == "" {
+		m.localAddress = SetupPortForward(t, m.Namespace, minioName, minioPort)
+	}
+
+	target := url.URL{Scheme: "http", Host: m.localAddress, Path: path, RawQuery: query}
+	request, err := http.NewRequestWithContext(t.Ctx(), method, target.String(), bytes.NewReader(body))
+	t.Expect(err).NotTo(gomega.HaveOccurred())
+	signS3Request(request, body, m.AccessKey, m.SecretKey, time.Now().UTC())
+
+	response, err := http.DefaultClient.Do(request)
+	t.Expect(err).NotTo(gomega.HaveOccurred())
+	defer response.Body.Close()
+	data, err := io.ReadAll(response.Body)
+	t.Expect(err).NotTo(gomega.HaveOccurred())
+
+	return s3Response{statusCode: response.StatusCode, body: data}
+}
+
+// signS3Request signs the request with AWS Signature Version 4, as required by the S3 API,
+// so that the tests don't depend on an S3 SDK. The query of the request URL must be in canonical form.
+func signS3Request(request *http.Request, body []byte, accessKey, secretKey string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	request.Header.Set("X-Amz-Date", amzDate)
+	request.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	const signedHeaders = "host;x-amz-content-sha256;x-amz-date"
+	canonicalRequest := strings.Join([]string{
+		request.Method,
+		request.URL.EscapedPath(),
+		request.URL.RawQuery,
+		"host:" + request.URL.Host,
+		"x-amz-content-sha256:" + payloadHash,
+		"x-amz-date:" + amzDate,
+		"",
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := strings.Join([]string{date, minioRegion, "s3", "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, sha256Hex([]byte(canonicalRequest))}, "\n")
+
+	key := []byte("AWS4" + secretKey)
+	for _, part := range []string{date, minioRegion, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	request.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKey, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func randomHex(t support.Test, n int) string {
+	data := make([]byte, n)
+	_, err := rand.Read(data)
+	t.Expect(err).NotTo(gomega.HaveOccurred())
+	return hex.EncodeToString(data)
+}
+
+func minioImage() string {
+	if image, ok := os.LookupEnv(MinIOImageEnvVar); ok {
+		return image
+	}
+	return defaultMinIOImage
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"os"
+	"strconv"
+	"sync"
+
+	"github.com/onsi/gomega"
+	"github.com/project-codeflare/codeflare-common/support"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NamespacePoolSizeEnvVar is the environment variable of the maximum number of test namespaces that can exist at once.
+const NamespacePoolSizeEnvVar = "CODEFLARE_TEST_NAMESPACE_POOL_SIZE"
+
+// NamespacePool leases uniquely named namespaces to tests running in parallel, and bounds the number of namespaces
+// that exist at once, so that the workloads of parallel tests don't exceed the capacity of the cluster.
+// A lease is only released once the namespace is fully deleted, as the pods of a terminating namespace still hold
+// their resources.
+type NamespacePool struct {
+	leases chan struct{}
+}
+
+// NewNamespacePool returns a pool of the given size. A size of zero or less means the pool is unbounded.
+func NewNamespacePool(size int) *NamespacePool {
+	pool := &NamespacePool{}
+	if size > 0 {
+		pool.leases = make(chan struct{}, size)
+	}
+	return pool
+}
+
+var (
+	defaultNamespacePool     *NamespacePool
+	defaultNamespacePoolOnce sync.Once
+)
+
+// DefaultNamespacePool returns the pool shared by the tests of the package, whose size is set with
+// the CODEFLARE_TEST_NAMESPACE_POOL_SIZE environment variable, and is unbounded by default.
+func DefaultNamespacePool() *NamespacePool {
+	defaultNamespacePoolOnce.Do(func() {
+		size := 0
+		if value, ok := os.LookupEnv(NamespacePoolSizeEnvVar); ok {
+			if parsed, err := strconv.Atoi(value); err == nil {
+				size = parsed
+			}
+		}
+		defaultNamespacePool = NewNamespacePool(size)
+	})
+	return defaultNamespacePool
+}
+
+// Lease waits for the pool to have room, creates a uniquely named test namespace, and releases it when the test completes,
+// once the namespace has been deleted.
+func (p *NamespacePool) Lease(t support.Test, options ...support.Option[*corev1.Namespace]) *corev1.Namespace {
+	t.T().Helper()
+
+	if p.leases != nil {
+		select {
+		case p.leases <- struct{}{}:
+		case <-t.Ctx().Done():
+			t.T().Fatalf("Failed to lease a test namespace: %v", t.Ctx().Err())
+		}
+	}
+
+	// The release is registered before the namespace is created, so that it runs after the namespace deletion
+	// registered by NewTestNamespace, as cleanup functions are called in last added, first called order
+	var namespace *corev1.Namespace
+	t.T().Cleanup(func() {
+		if namespace != nil {
+			t.Eventually(func() bool {
+				_, err := t.Client().Core().CoreV1().Namespaces().Get(t.Ctx(), namespace.Name, metav1.GetOptions{})
+				return errors.IsNotFound(err)
+			}, support.TestTimeoutMedium).Should(gomega.BeTrue(), "namespace %s hasn't been deleted", namespace.Name)
+		}
+		if p.leases != nil {
+			<-p.leases
+		}
+	})
+
+	namespace = t.NewTestNamespace(options...)
+	labelTestNamespace(t, namespace)
+	t.T().Logf("Leased test namespace %s", namespace.Name)
+
+	return namespace
+}
+
+// LeaseTestNamespace leases a test namespace from the default pool.
+func LeaseTestNamespace(t support.Test, options ...support.Option[*corev1.Namespace]) *corev1.Namespace {
+	t.T().Helper()
+	return DefaultNamespacePool().Lease(t, options...)
+}
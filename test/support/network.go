@@ -0,0 +1,55 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"net/netip"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// IPFamilyOf returns the family of the IP address, or an empty family if the address isn't a valid, unbracketed, IP.
+func IPFamilyOf(address string) corev1.IPFamily {
+	ip, err := netip.ParseAddr(address)
+	switch {
+	case err != nil:
+		return ""
+	case ip.Is4() || ip.Is4In6():
+		return corev1.IPv4Protocol
+	default:
+		return corev1.IPv6Protocol
+	}
+}
+
+// PodIPFamilies returns the families of the IPs of the pod, i.e., a single family on single-stack clusters,
+// and both families on dual-stack clusters.
+func PodIPFamilies(pod *corev1.Pod) []corev1.IPFamily {
+	var families []corev1.IPFamily
+	for _, podIP := range pod.Status.PodIPs {
+		families = append(families, IPFamilyOf(podIP.IP))
+	}
+	return families
+}
+
+// PodIPs returns the IPs of the pod.
+func PodIPs(pod *corev1.Pod) []string {
+	var ips []string
+	for _, podIP := range pod.Status.PodIPs {
+		ips = append(ips, podIP.IP)
+	}
+	return ips
+}
@@ -0,0 +1,187 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/onsi/gomega"
+	"github.com/project-codeflare/codeflare-common/support"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// MemoryPressureTaint is the taint the node lifecycle controller sets on the nodes under memory pressure.
+var MemoryPressureTaint = corev1.Taint{
+	Key:    corev1.TaintNodeMemoryPressure,
+	Effect: corev1.TaintEffectNoSchedule,
+}
+
+// CordonNode marks the node unschedulable, and marks it schedulable again when the test completes,
+// unless it was already cordoned.
+func CordonNode(t support.Test, nodeName string) {
+	t.T().Helper()
+
+	node, err := t.Client().Core().CoreV1().Nodes().Get(t.Ctx(), nodeName, metav1.GetOptions{})
+	t.Expect(err).NotTo(gomega.HaveOccurred())
+	if node.Spec.Unschedulable {
+		return
+	}
+
+	setNodeUnschedulable(t, nodeName, true)
+	t.T().Logf("Cordoned node %s", nodeName)
+	t.T().Cleanup(func() {
+		setNodeUnschedulable(t, nodeName, false)
+		t.T().Logf("Uncordoned node %s", nodeName)
+	})
+}
+
+// DrainNode cordons the node, and evicts its pods, like kubectl drain does, so that the controllers of the workloads
+// reschedule them on the other nodes. The pods managed by DaemonSets, and the static pods, are left on the node.
+// The evictions are retried while they are disallowed by PodDisruptionBudgets. The node is uncordoned when the test completes.
+// As the pods of all the namespaces are evicted, the tests that use it must not run in parallel with the other tests.
+func DrainNode(t support.Test, nodeName string) {
+	t.T().Helper()
+
+	CordonNode(t, nodeName)
+
+	for _, pod := range nodePods(t, nodeName) {
+		if !evictable(pod) {
+			continue
+		}
+		eviction := &policyv1.Eviction{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      pod.Name,
+				Namespace: pod.Namespace,
+			},
+		}
+		t.Eventually(func() error {
+			err := t.Client().Core().PolicyV1().Evictions(pod.Namespace).Evict(t.Ctx(), eviction)
+			if errors.IsNotFound(err) {
+				return nil
+			}
+			return err
+		}, support.TestTimeoutMedium).Should(gomega.Succeed(), "failed to evict pod %s/%s", pod.Namespace, pod.Name)
+		t.T().Logf("Evicted pod %s/%s from node %s", pod.Namespace, pod.Name, nodeName)
+	}
+}
+
+// TaintNode adds the taint to the node, and removes it when the test completes, unless the node already had it.
+// The NoExecute taints evict the pods that don't tolerate them.
+func TaintNode(t support.Test, nodeName string, taint corev1.Taint) {
+	t.T().Helper()
+
+	node, err := t.Client().Core().CoreV1().Nodes().Get(t.Ctx(), nodeName, metav1.GetOptions{})
+	t.Expect(err).NotTo(gomega.HaveOccurred())
+	for _, existing := range node.Spec.Taints {
+		if existing.MatchTaint(&taint) {
+			return
+		}
+	}
+
+	updateNodeTaints(t, nodeName, func(taints []corev1.Taint) []corev1.Taint {
+		return append(taints, taint)
+	})
+	t.T().Logf("Tainted node %s with %s", nodeName, taint.ToString())
+	t.T().Cleanup(func() {
+		updateNodeTaints(t, nodeName, func(taints []corev1.Taint) []corev1.Taint {
+			var remaining []corev1.Taint
+			for _, existing := range taints {
+				if !existing.MatchTaint(&taint) {
+					remaining = append(remaining, existing)
+				}
+			}
+			return remaining
+		})
+		t.T().Logf("Removed taint %s from node %s", taint.ToString(), nodeName)
+	})
+}
+
+// SimulateMemoryPressure taints the node as the node lifecycle controller does when it's under memory pressure,
+// and deletes its pods, as the kubelet evicts them to reclaim memory, without respecting PodDisruptionBudgets.
+// The pods managed by DaemonSets, and the static pods, are left on the node. The taint is removed when the test completes.
+// As with DrainNode, the tests that use it must not run in parallel with the other tests.
+func SimulateMemoryPressure(t support.Test, nodeName string) {
+	t.T().Helper()
+
+	TaintNode(t, nodeName, MemoryPressureTaint)
+
+	for _, pod := range nodePods(t, nodeName) {
+		if !evictable(pod) {
+			continue
+		}
+		err := t.Client().Core().CoreV1().Pods(pod.Namespace).Delete(t.Ctx(), pod.Name, metav1.DeleteOptions{})
+		if !errors.IsNotFound(err) {
+			t.Expect(err).NotTo(gomega.HaveOccurred())
+		}
+		t.T().Logf("Evicted pod %s/%s from node %s under memory pressure", pod.Namespace, pod.Name, nodeName)
+	}
+}
+
+func setNodeUnschedulable(t support.Test, nodeName string, unschedulable bool) {
+	patch := []byte(fmt.Sprintf(`{"spec":{"unschedulable":%t}}`, unschedulable))
+	RetryOnConflictOrTransient(t, func() error {
+		_, err := t.Client().Core().CoreV1().Nodes().Patch(t.Ctx(), nodeName, types.MergePatchType, patch, metav1.PatchOptions{})
+		return err
+	})
+}
+
+// updateNodeTaints updates the taints of the node, with an optimistic lock, as the taints are also updated by the controllers.
+func updateNodeTaints(t support.Test, nodeName string, update func([]corev1.Taint) []corev1.Taint) {
+	RetryOnConflictOrTransient(t, func() error {
+		node, err := t.Client().Core().CoreV1().Nodes().Get(t.Ctx(), nodeName, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		patch, err := json.Marshal(map[string]any{
+			"metadata": map[string]any{"resourceVersion": node.ResourceVersion},
+			"spec":     map[string]any{"taints": update(node.Spec.Taints)},
+		})
+		if err != nil {
+			return err
+		}
+		_, err = t.Client().Core().CoreV1().Nodes().Patch(t.Ctx(), nodeName, types.MergePatchType, patch, metav1.PatchOptions{})
+		return err
+	})
+}
+
+func nodePods(t support.Test, nodeName string) []corev1.Pod {
+	return support.GetPods(t, metav1.NamespaceAll, metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("spec.nodeName", nodeName).String(),
+	})
+}
+
+// evictable returns whether the pod would be evicted by kubectl drain, i.e., whether it's running,
+// and managed neither by a DaemonSet, nor by the kubelet.
+func evictable(pod corev1.Pod) bool {
+	if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed || pod.DeletionTimestamp != nil {
+		return false
+	}
+	if _, mirror := pod.Annotations[corev1.MirrorPodAnnotationKey]; mirror {
+		return false
+	}
+	if owner := metav1.GetControllerOf(&pod); owner != nil && owner.Kind == "DaemonSet" {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/onsi/gomega"
+	"github.com/project-codeflare/codeflare-common/support"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+)
+
+// portForwardAddress is the local address the ports are forwarded on. The IPv4 loopback address is also available
+// on IPv6-only hosts, and the local address is joined with the port so it's bracketed if changed to an IPv6 one.
+const portForwardAddress = "127.0.0.1"
+
+// SetupPortForward forwards a random local port to the port of the service, and returns the local address,
+// e.g., 127.0.0.1:40123. It's meant to reach services, like the Ray dashboard, without relying on the cluster
+// ingress and host names resolution. The forwarding targets one of the ready pods selected by the service,
+// so it doesn't survive the restart of that pod, and is stopped when the test completes.
+func SetupPortForward(t support.Test, namespace, serviceName string, port int32) string {
+	t.T().Helper()
+
+	service, err := t.Client().Core().CoreV1().Services(namespace).Get(t.Ctx(), serviceName, metav1.GetOptions{})
+	t.Expect(err).NotTo(gomega.HaveOccurred())
+
+	var pod *corev1.Pod
+	t.Eventually(func(g gomega.Gomega) *corev1.Pod {
+		pods, err := t.Client().Core().CoreV1().Pods(namespace).List(t.Ctx(), metav1.ListOptions{
+			LabelSelector: labels.SelectorFromSet(service.Spec.Selector).String(),
+		})
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+		pod = nil
+		for i := range pods.Items {
+			if isPodReady(&pods.Items[i]) {
+				pod = &pods.Items[i]
+				break
+			}
+		}
+		return pod
+	}, support.TestTimeoutMedium).ShouldNot(gomega.BeNil(), "no ready pod for Service %s/%s", namespace, serviceName)
+
+	targetPort, err := serviceTargetPort(service, pod, port)
+	t.Expect(err).NotTo(gomega.HaveOccurred())
+
+	transport, upgrader, err := spdy.RoundTripperFor(restConfig(t))
+	t.Expect(err).NotTo(gomega.HaveOccurred())
+
+	request := t.Client().Core().CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(pod.Namespace).
+		Name(pod.Name).
+		SubResource("portforward")
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, request.URL())
+
+	stopChan, readyChan := make(chan struct{}), make(chan struct{})
+	forwarder, err := portforward.NewOnAddresses(dialer, []string{portForwardAddress}, []string{fmt.Sprintf("0:%d", targetPort)},
+		stopChan, readyChan, io.Discard, io.Discard)
+	t.Expect(err).NotTo(gomega.HaveOccurred())
+
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- forwarder.ForwardPorts()
+	}()
+	t.T().Cleanup(func() {
+		close(stopChan)
+	})
+
+	select {
+	case <-readyChan:
+	case err := <-errChan:
+		t.T().Fatalf("Failed to port-forward to Pod %s/%s: %v", pod.Namespace, pod.Name, err)
+	case <-t.Ctx().Done():
+		t.T().Fatalf("Failed to port-forward to Pod %s/%s: %v", pod.Namespace, pod.Name, t.Ctx().Err())
+	}
+
+	ports, err := forwarder.GetPorts()
+	t.Expect(err).NotTo(gomega.HaveOccurred())
+	t.Expect(ports).To(gomega.HaveLen(1))
+
+	address := net.JoinHostPort(portForwardAddress, strconv.Itoa(int(ports[0].Local)))
+	t.T().Logf("Forwarding %s to port %d of Service %s/%s, through Pod %s", address, port, namespace, serviceName, pod.Name)
+
+	return address
+}
+
+// serviceTargetPort returns the pod port the service port is routed to.
+func serviceTargetPort(service *corev1.Service, pod *corev1.Pod, port int32) (int32, error) {
+	for _, servicePort := range service.Spec.Ports {
+		if servicePort.Port != port {
+			continue
+		}
+		if servicePort.TargetPort.StrVal == "" {
+			if servicePort.TargetPort.IntVal == 0 {
+				return port, nil
+			}
+			return servicePort.TargetPort.IntVal, nil
+		}
+		for _, container := range pod.Spec.Containers {
+			for _, containerPort := range container.Ports {
+				if containerPort.Name == servicePort.TargetPort.StrVal {
+					return containerPort.ContainerPort, nil
+				}
+			}
+		}
+		return 0, fmt.Errorf("Pod %s/%s has no port named %s", pod.Namespace, pod.Name, servicePort.TargetPort.StrVal)
+	}
+	return 0, fmt.Errorf("Service %s/%s has no port %d", service.Namespace, service.Name, port)
+}
+
+func isPodReady(pod *corev1.Pod) bool {
+	if !pod.DeletionTimestamp.IsZero() || pod.Status.Phase != corev1.PodRunning {
+		return false
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"github.com/onsi/gomega"
+	"github.com/project-codeflare/codeflare-common/support"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+const probeContainerName = "probe"
+
+// RunProbePod runs the command in a pod created from the image in the namespace, waits for it to terminate,
+// and returns its exit code and output. It's meant to assert conditions from inside the cluster, like
+// networking, DNS resolution, or accelerators visibility. The pod is deleted once its output is captured.
+func RunProbePod(t support.Test, namespace, image string, cmd []string) (int32, string) {
+	t.T().Helper()
+
+	pod := &corev1.Pod{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.String(),
+			Kind:       "Pod",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "probe-",
+			Namespace:    namespace,
+		},
+		Spec: corev1.PodSpec{
+			RestartPolicy: corev1.RestartPolicyNever,
+			Containers: []corev1.Container{
+				{
+					Name:    probeContainerName,
+					Image:   image,
+					Command: cmd,
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("50m"),
+							corev1.ResourceMemory: resource.MustParse("64Mi"),
+						},
+						Limits: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("500m"),
+							corev1.ResourceMemory: resource.MustParse("512Mi"),
+						},
+					},
+					SecurityContext: &corev1.SecurityContext{
+						AllowPrivilegeEscalation: ptr.To(false),
+						Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+						SeccompProfile:           &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+					},
+				},
+			},
+		},
+	}
+
+	pod, err := t.Client().Core().CoreV1().Pods(namespace).Create(t.Ctx(), pod, metav1.CreateOptions{})
+	t.Expect(err).NotTo(gomega.HaveOccurred())
+	t.T().Logf("Created probe Pod %s/%s running %q", pod.Namespace, pod.Name, cmd)
+	defer func() {
+		err := t.Client().Core().CoreV1().Pods(namespace).Delete(t.Ctx(), pod.Name, metav1.DeleteOptions{})
+		t.Expect(err).NotTo(gomega.HaveOccurred())
+	}()
+
+	t.Eventually(func(g gomega.Gomega) corev1.PodPhase {
+		pod, err = t.Client().Core().CoreV1().Pods(namespace).Get(t.Ctx(), pod.Name, metav1.GetOptions{})
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+		return pod.Status.Phase
+	}, support.TestTimeoutMedium).Should(gomega.BeElementOf(corev1.PodSucceeded, corev1.PodFailed))
+
+	var exitCode int32
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name == probeContainerName && status.State.Terminated != nil {
+			exitCode = status.State.Terminated.ExitCode
+		}
+	}
+	output := support.GetPodLogs(t, pod, corev1.PodLogOptions{Container: probeContainerName})
+	t.T().Logf("Probe Pod %s/%s exited with code %d", pod.Namespace, pod.Name, exitCode)
+
+	return exitCode, string(output)
+}
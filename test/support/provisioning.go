@@ -0,0 +1,137 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"slices"
+	"time"
+
+	"github.com/onsi/gomega"
+	"github.com/project-codeflare/codeflare-common/support"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	kueuev1beta1 "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+)
+
+// provisioningRequestControllerName is the name of the Kueue AdmissionCheck controller that creates
+// ProvisioningRequests for the Workloads, for the cluster autoscaler to provision their nodes.
+const provisioningRequestControllerName = "kueue.x-k8s.io/provisioning-request"
+
+// ProvisioningRequestResource is the resource of the ProvisioningRequests of the cluster autoscaler.
+// It's unstructured, as the operator doesn't depend on the cluster autoscaler API.
+var ProvisioningRequestResource = schema.GroupVersionResource{Group: "autoscaling.x-k8s.io", Version: "v1beta1", Resource: "provisioningrequests"}
+
+// IsProvisioningRequestAvailable returns whether the ProvisioningRequest API of the cluster autoscaler is served.
+func IsProvisioningRequestAvailable(t support.Test) bool {
+	t.T().Helper()
+
+	resources, err := t.Client().Core().Discovery().ServerResourcesForGroupVersion(ProvisioningRequestResource.GroupVersion().String())
+	if apierrors.IsNotFound(err) {
+		return false
+	}
+	t.Expect(err).NotTo(gomega.HaveOccurred())
+	return slices.ContainsFunc(resources.APIResources, func(resource metav1.APIResource) bool {
+		return resource.Name == ProvisioningRequestResource.Resource
+	})
+}
+
+// CreateTestKueueProvisioningAdmissionCheck creates an AdmissionCheck, and its ProvisioningRequestConfig, that provisions
+// the nodes of the Workloads with ProvisioningRequests of the class, before they are admitted. They are labelled as test
+// resources, and deleted once the test completes.
+func CreateTestKueueProvisioningAdmissionCheck(t support.Test, provisioningClassName string) *kueuev1beta1.AdmissionCheck {
+	t.T().Helper()
+
+	config := &kueuev1beta1.ProvisioningRequestConfig{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: kueuev1beta1.SchemeGroupVersion.String(),
+			Kind:       "ProvisioningRequestConfig",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "prc-",
+			Labels:       testResourceLabels(),
+		},
+		Spec: kueuev1beta1.ProvisioningRequestConfigSpec{
+			ProvisioningClassName: provisioningClassName,
+		},
+	}
+	config, err := t.Client().Kueue().KueueV1beta1().ProvisioningRequestConfigs().Create(t.Ctx(), config, metav1.CreateOptions{})
+	t.Expect(err).NotTo(gomega.HaveOccurred())
+	t.T().Cleanup(func() {
+		err := t.Client().Kueue().KueueV1beta1().ProvisioningRequestConfigs().Delete(t.Ctx(), config.Name, metav1.DeleteOptions{})
+		t.Expect(err).NotTo(gomega.HaveOccurred())
+	})
+
+	admissionCheck := &kueuev1beta1.AdmissionCheck{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: kueuev1beta1.SchemeGroupVersion.String(),
+			Kind:       "AdmissionCheck",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "ac-",
+			Labels:       testResourceLabels(),
+		},
+		Spec: kueuev1beta1.AdmissionCheckSpec{
+			ControllerName: provisioningRequestControllerName,
+			Parameters: &kueuev1beta1.AdmissionCheckParametersReference{
+				APIGroup: kueuev1beta1.GroupVersion.Group,
+				Kind:     "ProvisioningRequestConfig",
+				Name:     config.Name,
+			},
+		},
+	}
+	admissionCheck, err = t.Client().Kueue().KueueV1beta1().AdmissionChecks().Create(t.Ctx(), admissionCheck, metav1.CreateOptions{})
+	t.Expect(err).NotTo(gomega.HaveOccurred())
+	t.T().Logf("Created Kueue AdmissionCheck %s successfully", admissionCheck.Name)
+	t.T().Cleanup(func() {
+		err := t.Client().Kueue().KueueV1beta1().AdmissionChecks().Delete(t.Ctx(), admissionCheck.Name, metav1.DeleteOptions{})
+		t.Expect(err).NotTo(gomega.HaveOccurred())
+	})
+
+	return admissionCheck
+}
+
+// ProvisioningRequests returns a function that lists the ProvisioningRequests of the namespace, to be polled with Eventually.
+func ProvisioningRequests(t support.Test, namespace string) func(g gomega.Gomega) []unstructured.Unstructured {
+	return func(g gomega.Gomega) []unstructured.Unstructured {
+		list, err := t.Client().Dynamic().Resource(ProvisioningRequestResource).Namespace(namespace).List(t.Ctx(), metav1.ListOptions{})
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+		return list.Items
+	}
+}
+
+// SetProvisioningRequestProvisioned reports the nodes of the ProvisioningRequest as provisioned, the same way
+// the cluster autoscaler does, so that the admission of the Workloads can be tested without scaling the cluster.
+func SetProvisioningRequestProvisioned(t support.Test, provisioningRequest *unstructured.Unstructured) {
+	t.T().Helper()
+
+	condition := map[string]any{
+		"type":               "Provisioned",
+		"status":             string(metav1.ConditionTrue),
+		"reason":             "Provisioned",
+		"message":            "Provisioned by the e2e tests",
+		"lastTransitionTime": metav1.Now().UTC().Format(time.RFC3339),
+	}
+	provisioningRequest = provisioningRequest.DeepCopy()
+	t.Expect(unstructured.SetNestedSlice(provisioningRequest.Object, []any{condition}, "status", "conditions")).To(gomega.Succeed())
+	_, err := t.Client().Dynamic().Resource(ProvisioningRequestResource).Namespace(provisioningRequest.GetNamespace()).
+		UpdateStatus(t.Ctx(), provisioningRequest, metav1.UpdateOptions{})
+	t.Expect(err).NotTo(gomega.HaveOccurred())
+	t.T().Logf("Set ProvisioningRequest %s/%s as provisioned", provisioningRequest.GetNamespace(), provisioningRequest.GetName())
+}
@@ -0,0 +1,204 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package support complements the test support from codeflare-common with
+// helpers specific to the CodeFlare operator test suites.
+package support
+
+import (
+	"github.com/project-codeflare/codeflare-common/support"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/project-codeflare/codeflare-operator/pkg/controllers"
+)
+
+// RayClusterBuilder builds RayClusters with a head group and any number of worker groups.
+type RayClusterBuilder struct {
+	rayCluster *rayv1.RayCluster
+}
+
+// NewRayClusterBuilder returns a builder for a RayCluster with a head group using the test Ray image.
+func NewRayClusterBuilder(namespace, name string) *RayClusterBuilder {
+	return &RayClusterBuilder{
+		rayCluster: &rayv1.RayCluster{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: rayv1.GroupVersion.String(),
+				Kind:       "RayCluster",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+			},
+			Spec: rayv1.RayClusterSpec{
+				RayVersion: support.GetRayVersion(),
+				HeadGroupSpec: rayv1.HeadGroupSpec{
+					RayStartParams: map[string]string{
+						"dashboard-host": "0.0.0.0",
+					},
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							NodeSelector: ArchitectureNodeSelector(),
+							Containers: []corev1.Container{
+								{
+									Name:  "ray-head",
+									Image: support.GetRayImage(),
+									Ports: []corev1.ContainerPort{
+										{
+											ContainerPort: 6379,
+											Name:          "gcs",
+										},
+										{
+											ContainerPort: 8265,
+											Name:          "dashboard",
+										},
+										{
+											ContainerPort: 10001,
+											Name:          "client",
+										},
+									},
+									Lifecycle: rayStopLifecycle(),
+									Resources: corev1.ResourceRequirements{
+										Requests: corev1.ResourceList{
+											corev1.ResourceCPU:    resource.MustParse("250m"),
+											corev1.ResourceMemory: resource.MustParse("512Mi"),
+										},
+										Limits: corev1.ResourceList{
+											corev1.ResourceCPU:    resource.MustParse("1"),
+											corev1.ResourceMemory: resource.MustParse("2G"),
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// WithLabels adds the labels to the RayCluster, e.g. to assign it to a Kueue LocalQueue.
+func (b *RayClusterBuilder) WithLabels(labels map[string]string) *RayClusterBuilder {
+	if b.rayCluster.Labels == nil {
+		b.rayCluster.Labels = map[string]string{}
+	}
+	for k, v := range labels {
+		b.rayCluster.Labels[k] = v
+	}
+	return b
+}
+
+// WithGCSFaultTolerance enables the GCS fault tolerance of the RayCluster, backed by the Redis instance at the address,
+// so that the Ray cluster, and its running jobs, recover from the failure of the head.
+func (b *RayClusterBuilder) WithGCSFaultTolerance(redisAddress string) *RayClusterBuilder {
+	if b.rayCluster.Annotations == nil {
+		b.rayCluster.Annotations = map[string]string{}
+	}
+	b.rayCluster.Annotations["ray.io/ft-enabled"] = "true"
+	head := &b.rayCluster.Spec.HeadGroupSpec.Template.Spec.Containers[0]
+	head.Env = append(head.Env, corev1.EnvVar{Name: "RAY_REDIS_ADDRESS", Value: redisAddress})
+	return b
+}
+
+// WithHeadResources sets the resources of the head container.
+func (b *RayClusterBuilder) WithHeadResources(resources corev1.ResourceRequirements) *RayClusterBuilder {
+	b.rayCluster.Spec.HeadGroupSpec.Template.Spec.Containers[0].Resources = resources
+	return b
+}
+
+// WithWorkerGroup adds a worker group with the given number of replicas, container resources,
+// and pod labels, e.g. to describe the accelerator the group is meant for.
+func (b *RayClusterBuilder) WithWorkerGroup(name string, replicas int32, resources corev1.ResourceRequirements, labels map[string]string) *RayClusterBuilder {
+	b.rayCluster.Spec.WorkerGroupSpecs = append(b.rayCluster.Spec.WorkerGroupSpecs, rayv1.WorkerGroupSpec{
+		GroupName:      name,
+		Replicas:       support.Ptr(replicas),
+		MinReplicas:    support.Ptr(replicas),
+		MaxReplicas:    support.Ptr(replicas),
+		RayStartParams: map[string]string{},
+		Template: corev1.PodTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: labels,
+			},
+			Spec: corev1.PodSpec{
+				NodeSelector: ArchitectureNodeSelector(),
+				Containers: []corev1.Container{
+					{
+						Name:      "ray-worker",
+						Image:     support.GetRayImage(),
+						Lifecycle: rayStopLifecycle(),
+						Resources: resources,
+					},
+				},
+			},
+		},
+	})
+	return b
+}
+
+// WithInTreeAutoscaling enables the Ray autoscaler, that scales the worker groups added so far between the minimum
+// replicas, and their maximum replicas, i.e., the replicas they've been added with, and removes the workers that have
+// been idle for the timeout.
+func (b *RayClusterBuilder) WithInTreeAutoscaling(minReplicas int32, idleTimeoutSeconds int32) *RayClusterBuilder {
+	b.rayCluster.Spec.EnableInTreeAutoscaling = support.Ptr(true)
+	b.rayCluster.Spec.AutoscalerOptions = &rayv1.AutoscalerOptions{
+		IdleTimeoutSeconds: support.Ptr(idleTimeoutSeconds),
+	}
+	for i := range b.rayCluster.Spec.WorkerGroupSpecs {
+		b.rayCluster.Spec.WorkerGroupSpecs[i].MinReplicas = support.Ptr(minReplicas)
+	}
+	return b
+}
+
+// Build returns a copy of the RayCluster, so the builder can be reused.
+func (b *RayClusterBuilder) Build() *rayv1.RayCluster {
+	return b.rayCluster.DeepCopy()
+}
+
+// RayClusterDesiredWorkerReplicas returns the number of worker replicas requested across all the worker groups.
+func RayClusterDesiredWorkerReplicas(cluster *rayv1.RayCluster) int32 {
+	var replicas int32
+	for _, workerGroup := range cluster.Spec.WorkerGroupSpecs {
+		if workerGroup.Replicas != nil {
+			replicas += *workerGroup.Replicas
+		}
+	}
+	return replicas
+}
+
+// RayClusterAvailableWorkerReplicas returns the number of available worker replicas reported in the RayCluster status.
+func RayClusterAvailableWorkerReplicas(cluster *rayv1.RayCluster) int32 {
+	return cluster.Status.AvailableWorkerReplicas
+}
+
+// RayClusterDashboardURL returns the URL the dashboard of the RayCluster is exposed at, as reported by the operator
+// along with the DashboardReady condition.
+func RayClusterDashboardURL(cluster *rayv1.RayCluster) string {
+	return cluster.Annotations[controllers.DashboardURLAnnotation]
+}
+
+func rayStopLifecycle() *corev1.Lifecycle {
+	return &corev1.Lifecycle{
+		PreStop: &corev1.LifecycleHandler{
+			Exec: &corev1.ExecAction{
+				Command: []string{"/bin/sh", "-c", "ray stop"},
+			},
+		},
+	}
+}
@@ -0,0 +1,389 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/onsi/gomega"
+	"github.com/project-codeflare/codeflare-common/support"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+)
+
+// rayDashboardPort is the port of the dashboard of the Ray head, that also serves the Job Submission and State APIs.
+const rayDashboardPort = 8265
+
+// rayJobLogsPollInterval is the interval the logs of a Ray job are polled at when they are tailed.
+const rayJobLogsPollInterval = 2 * time.Second
+
+// rayNodeStateAlive is the state of the Ray nodes that are registered with the GCS, and healthy.
+const rayNodeStateAlive = "ALIVE"
+
+// RayJobStatusRunning is the status of the Ray jobs whose driver is running.
+const RayJobStatusRunning = "RUNNING"
+
+// Terminal statuses of Ray jobs.
+const (
+	RayJobStatusSucceeded = "SUCCEEDED"
+	RayJobStatusFailed    = "FAILED"
+	RayJobStatusStopped   = "STOPPED"
+)
+
+// RayJobSubmission is the request of the Job Submission API to submit a job.
+type RayJobSubmission struct {
+	Entrypoint          string             `json:"entrypoint"`
+	SubmissionID        string             `json:"submission_id,omitempty"`
+	RuntimeEnv          map[string]any     `json:"runtime_env,omitempty"`
+	Metadata            map[string]string  `json:"metadata,omitempty"`
+	EntrypointNumCPUs   float64            `json:"entrypoint_num_cpus,omitempty"`
+	EntrypointNumGPUs   float64            `json:"entrypoint_num_gpus,omitempty"`
+	EntrypointResources map[string]float64 `json:"entrypoint_resources,omitempty"`
+}
+
+// RayJobInfo is the description of a job returned by the Job Submission API.
+type RayJobInfo struct {
+	Type         string            `json:"type"`
+	JobID        string            `json:"job_id"`
+	SubmissionID string            `json:"submission_id"`
+	Status       string            `json:"status"`
+	Entrypoint   string            `json:"entrypoint"`
+	Message      string            `json:"message"`
+	ErrorType    string            `json:"error_type"`
+	StartTime    int64             `json:"start_time"`
+	EndTime      int64             `json:"end_time"`
+	Metadata     map[string]string `json:"metadata"`
+}
+
+// RayNode is the description of a node of the Ray cluster returned by the State API.
+type RayNode struct {
+	NodeID         string             `json:"node_id"`
+	NodeIP         string             `json:"node_ip"`
+	NodeName       string             `json:"node_name"`
+	IsHeadNode     bool               `json:"is_head_node"`
+	State          string             `json:"state"`
+	ResourcesTotal map[string]float64 `json:"resources_total"`
+	Labels         map[string]string  `json:"labels"`
+}
+
+// RayDashboardClusterStatus is the status of the Ray cluster, as reported by the autoscaler to the dashboard.
+type RayDashboardClusterStatus struct {
+	AutoscalingStatus string         `json:"autoscalingStatus"`
+	AutoscalingError  string         `json:"autoscalingError"`
+	ClusterStatus     map[string]any `json:"clusterStatus"`
+}
+
+var _ support.RayClusterClient = (*RayDashboardClient)(nil)
+
+// RayDashboardClient is a client for the Job Submission and State APIs served by the dashboard of a Ray cluster,
+// that complements the RayClusterClient from codeflare-common, so that the tests can drive Ray jobs, and inspect
+// the Ray cluster, without the Ray CLI.
+type RayDashboardClient struct {
+	endpoint    url.URL
+	bearerToken string
+	client      *http.Client
+}
+
+// NewRayDashboardClient returns a client for the dashboard at the endpoint. The bearer token, if any,
+// is sent with the requests, to be authorized by the OAuth proxy in front of the dashboard.
+func NewRayDashboardClient(endpoint url.URL, bearerToken string) *RayDashboardClient {
+	return &RayDashboardClient{
+		endpoint:    endpoint,
+		bearerToken: bearerToken,
+		client: &http.Client{
+			// The dashboard Routes are usually exposed with self-signed certificates in test clusters
+			Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}},
+		},
+	}
+}
+
+// GetRayDashboardClient returns a client for the dashboard of the RayCluster, as it's reachable from the tests.
+// On OpenShift, that's through the dashboard Route created by the operator, once it serves requests, authorized
+// with GetBearerToken. Otherwise, a local port is forwarded to the head Service, so that the dashboard is reachable
+// without resolving the Ingress host.
+func GetRayDashboardClient(t support.Test, namespace, rayClusterName string) *RayDashboardClient {
+	t.T().Helper()
+
+	if support.IsOpenShift(t) {
+		route := support.GetRoute(t, namespace, "ray-dashboard-"+rayClusterName)
+		hostname := route.Status.Ingress[0].Host
+		client := NewRayDashboardClient(url.URL{Scheme: "https", Host: hostname}, GetBearerToken(t, namespace))
+
+		// Wait for expected HTTP code
+		t.T().Logf("Waiting for Route %s/%s to be available", route.Namespace, route.Name)
+		t.Eventually(func() (int, error) {
+			response, err := client.request(http.MethodGet, "/", nil)
+			if err != nil {
+				return -1, err
+			}
+			response.Body.Close()
+			return response.StatusCode, nil
+		}, support.TestTimeoutShort).Should(gomega.Not(gomega.Equal(http.StatusServiceUnavailable)))
+
+		return client
+	}
+
+	return NewRayDashboardClient(url.URL{
+		Scheme: "http",
+		Host:   SetupPortForward(t, namespace, rayClusterName+"-head-svc", rayDashboardPort),
+	}, "")
+}
+
+// GetRayDashboardURL returns the URL the dashboard of the RayCluster is reachable at from the tests.
+func GetRayDashboardURL(t support.Test, namespace, rayClusterName string) url.URL {
+	t.T().Helper()
+	return *GetRayDashboardClient(t, namespace, rayClusterName).Endpoint()
+}
+
+// Endpoint returns the URL of the dashboard.
+func (c *RayDashboardClient) Endpoint() *url.URL {
+	endpoint := c.endpoint
+	return &endpoint
+}
+
+// CreateJob submits the job, as the RayClusterClient from codeflare-common does.
+func (c *RayDashboardClient) CreateJob(job *support.RayJobSetup) (*support.RayJobResponse, error) {
+	return c.SubmitJob(&RayJobSubmission{Entrypoint: job.EntryPoint, RuntimeEnv: job.RuntimeEnv})
+}
+
+// SubmitJob submits the job, and returns its identifiers.
+func (c *RayDashboardClient) SubmitJob(job *RayJobSubmission) (*support.RayJobResponse, error) {
+	response := &support.RayJobResponse{}
+	err := c.do(http.MethodPost, "/api/jobs/", job, response)
+	return response, err
+}
+
+func (c *RayDashboardClient) GetJobDetails(jobID string) (*support.RayJobDetailsResponse, error) {
+	response := &support.RayJobDetailsResponse{}
+	err := c.do(http.MethodGet, "/api/jobs/"+url.PathEscape(jobID), nil, response)
+	return response, err
+}
+
+// GetJob returns the description of the job, identified with its job or submission ID.
+func (c *RayDashboardClient) GetJob(jobID string) (*RayJobInfo, error) {
+	response := &RayJobInfo{}
+	err := c.do(http.MethodGet, "/api/jobs/"+url.PathEscape(jobID), nil, response)
+	return response, err
+}
+
+// ListJobs returns the jobs submitted to the Ray cluster, including the completed ones.
+func (c *RayDashboardClient) ListJobs() ([]RayJobInfo, error) {
+	var response []RayJobInfo
+	err := c.do(http.MethodGet, "/api/jobs/", nil, &response)
+	return response, err
+}
+
+// StopJob requests the job to be stopped, and returns whether it was running.
+func (c *RayDashboardClient) StopJob(jobID string) (bool, error) {
+	response := struct {
+		Stopped bool `json:"stopped"`
+	}{}
+	err := c.do(http.MethodPost, "/api/jobs/"+url.PathEscape(jobID)+"/stop", nil, &response)
+	return response.Stopped, err
+}
+
+// DeleteJob deletes the job, and its logs, once it has terminated, and returns whether it was deleted.
+func (c *RayDashboardClient) DeleteJob(jobID string) (bool, error) {
+	response := struct {
+		Deleted bool `json:"deleted"`
+	}{}
+	err := c.do(http.MethodDelete, "/api/jobs/"+url.PathEscape(jobID), nil, &response)
+	return response.Deleted, err
+}
+
+func (c *RayDashboardClient) GetJobLogs(jobID string) (string, error) {
+	response := &support.RayJobLogsResponse{}
+	err := c.do(http.MethodGet, "/api/jobs/"+url.PathEscape(jobID)+"/logs", nil, response)
+	return response.Logs, err
+}
+
+// TailJobLogs calls the function with each line of the logs of the job, as they are produced,
+// until the job terminates, or the context is done. The logs are polled, rather than streamed
+// from the websocket endpoint, so that the client only depends on the standard library.
+func (c *RayDashboardClient) TailJobLogs(ctx context.Context, jobID string, line func(string)) error {
+	offset := 0
+	for {
+		// Read the status before the logs, so that the logs are complete once the job has terminated
+		job, err := c.GetJob(jobID)
+		if err != nil {
+			return err
+		}
+		logs, err := c.GetJobLogs(jobID)
+		if err != nil {
+			return err
+		}
+
+		terminated := slices.Contains([]string{RayJobStatusSucceeded, RayJobStatusFailed, RayJobStatusStopped}, job.Status)
+		if offset < len(logs) {
+			chunk := logs[offset:]
+			// Only emit the complete lines, unless there's no more to come
+			if end := strings.LastIndexByte(chunk, '\n'); end >= 0 || terminated {
+				if !terminated {
+					chunk = chunk[:end+1]
+				}
+				offset += len(chunk)
+				for _, l := range strings.Split(strings.TrimSuffix(chunk, "\n"), "\n") {
+					line(l)
+				}
+			}
+		}
+		if terminated {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(rayJobLogsPollInterval):
+		}
+	}
+}
+
+// ListNodes returns the nodes of the Ray cluster, that have joined it, including the dead ones.
+func (c *RayDashboardClient) ListNodes() ([]RayNode, error) {
+	response := struct {
+		Result bool   `json:"result"`
+		Msg    string `json:"msg"`
+		Data   struct {
+			Result struct {
+				Result []RayNode `json:"result"`
+			} `json:"result"`
+		} `json:"data"`
+	}{}
+	if err := c.do(http.MethodGet, "/api/v0/nodes?limit=10000", nil, &response); err != nil {
+		return nil, err
+	}
+	if !response.Result {
+		return nil, fmt.Errorf("failed to list Ray nodes: %s", response.Msg)
+	}
+	return response.Data.Result.Result, nil
+}
+
+// GetClusterStatus returns the status of the Ray cluster reported by the autoscaler, e.g., the pending nodes and resource demands.
+func (c *RayDashboardClient) GetClusterStatus() (*RayDashboardClusterStatus, error) {
+	response := struct {
+		Result bool                      `json:"result"`
+		Msg    string                    `json:"msg"`
+		Data   RayDashboardClusterStatus `json:"data"`
+	}{}
+	if err := c.do(http.MethodGet, "/api/cluster_status", nil, &response); err != nil {
+		return nil, err
+	}
+	if !response.Result {
+		return nil, fmt.Errorf("failed to get Ray cluster status: %s", response.Msg)
+	}
+	return &response.Data, nil
+}
+
+// do sends the request, with the body encoded as JSON, and decodes the JSON response into the result.
+func (c *RayDashboardClient) do(method, path string, body, result any) error {
+	var data []byte
+	if body != nil {
+		var err error
+		if data, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+
+	response, err := c.request(method, path, data)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	responseData, err := io.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("incorrect response code: %d for %s %s, response body: %s", response.StatusCode, method, path, responseData)
+	}
+	return json.Unmarshal(responseData, result)
+}
+
+func (c *RayDashboardClient) request(method, path string, body []byte) (*http.Response, error) {
+	request, err := http.NewRequest(method, strings.TrimSuffix(c.endpoint.String(), "/")+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	if c.bearerToken != "" {
+		request.Header.Set("Authorization", "Bearer "+c.bearerToken)
+	}
+	return c.client.Do(request)
+}
+
+// RayDashboardJob returns a function that gets the job, identified with its job or submission ID, from the
+// Job Submission API, to be polled with Eventually.
+func RayDashboardJob(t support.Test, client *RayDashboardClient, jobID string) func(g gomega.Gomega) *RayJobInfo {
+	return func(g gomega.Gomega) *RayJobInfo {
+		job, err := client.GetJob(jobID)
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+		return job
+	}
+}
+
+// RayDashboardJobStatus returns the status of the job, e.g., RUNNING, or SUCCEEDED.
+func RayDashboardJobStatus(job *RayJobInfo) string {
+	return job.Status
+}
+
+// RayNodes returns a function that lists the nodes of the Ray cluster, to be polled with Eventually.
+func RayNodes(t support.Test, client *RayDashboardClient) func(g gomega.Gomega) []RayNode {
+	return func(g gomega.Gomega) []RayNode {
+		nodes, err := client.ListNodes()
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+		return nodes
+	}
+}
+
+// RayNodesAlive returns the nodes that are alive, out of the nodes that have joined the Ray cluster.
+func RayNodesAlive(nodes []RayNode) []RayNode {
+	var alive []RayNode
+	for _, node := range nodes {
+		if node.State == rayNodeStateAlive {
+			alive = append(alive, node)
+		}
+	}
+	return alive
+}
+
+// EventuallyRayNodes waits for the head, and the desired number of workers of the RayCluster, to be alive in the
+// Ray cluster, as reported by the dashboard. It catches the workers whose pods are ready, and the RayCluster
+// reported ready accordingly, but that never registered with the GCS of the head.
+func EventuallyRayNodes(t support.Test, client *RayDashboardClient, rayCluster *rayv1.RayCluster) {
+	t.T().Helper()
+
+	expected := 1 + int(RayClusterDesiredWorkerReplicas(rayCluster))
+	t.T().Logf("Waiting for %d Ray node(s) of RayCluster %s/%s to be alive", expected, rayCluster.Namespace, rayCluster.Name)
+	t.Eventually(RayNodes(t, client), support.TestTimeoutMedium).
+		Should(gomega.WithTransform(RayNodesAlive, gomega.And(
+			gomega.HaveLen(expected),
+			gomega.ContainElement(gomega.HaveField("IsHeadNode", gomega.BeTrue())),
+		)))
+}
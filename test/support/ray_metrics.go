@@ -0,0 +1,58 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"github.com/onsi/gomega"
+	"github.com/project-codeflare/codeflare-common/support"
+)
+
+// rayMetricsPort is the port of the Prometheus endpoint of the Ray metrics agent, that KubeRay exposes on the head Service.
+const rayMetricsPort = 8080
+
+// RayMetrics are the metric families scraped from a Ray metrics agent, indexed by name, e.g., ray_tasks.
+type RayMetrics = Metrics
+
+// RayHeadMetrics returns a function that scrapes the Prometheus endpoint of the head of the RayCluster, to be polled
+// with Eventually, e.g., to assert the tasks of a training job have been distributed across the Ray workers:
+//
+//	test.Eventually(RayHeadMetrics(test, namespace, name), TestTimeoutMedium).
+//		Should(WithTransform(RayMetricValue("ray_cluster_active_nodes", nil), BeNumerically(">=", 2)))
+//
+// The metrics are those of the head node, as each Ray node exports its own, plus the cluster-wide ones
+// reported by the GCS and the autoscaler. The port is forwarded once, when the function is created.
+func RayHeadMetrics(t support.Test, namespace, rayClusterName string) func(g gomega.Gomega) RayMetrics {
+	t.T().Helper()
+
+	endpoint := "http://" + SetupPortForward(t, namespace, rayClusterName+"-head-svc", rayMetricsPort) + "/metrics"
+
+	return func(g gomega.Gomega) RayMetrics {
+		return scrapeMetrics(t, g, endpoint)
+	}
+}
+
+func GetRayHeadMetrics(t support.Test, namespace, rayClusterName string) RayMetrics {
+	t.T().Helper()
+	return RayHeadMetrics(t, namespace, rayClusterName)(t)
+}
+
+// RayMetricValue returns a transform that gets the value of the metric, as computed by Metrics.Value.
+func RayMetricValue(name string, labels map[string]string) func(m RayMetrics) float64 {
+	return func(m RayMetrics) float64 {
+		return m.Value(name, labels)
+	}
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/onsi/gomega"
+	"github.com/project-codeflare/codeflare-common/support"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// GetRayJobSubmitterLogs returns the logs of the pods of the Job KubeRay creates to submit the RayJob, from the oldest
+// to the most recent attempt, so that the failures that occur before the job is registered with the Ray dashboard,
+// e.g., when the submitter can't reach the RayCluster, or the runtime environment is invalid, can be investigated.
+// It returns an empty string if the submitter Job hasn't been created yet.
+func GetRayJobSubmitterLogs(t support.Test, namespace, rayJobName string) string {
+	t.T().Helper()
+
+	// The labels KubeRay sets on the submitter Job, that are not propagated to its pods
+	jobs, err := t.Client().Core().BatchV1().Jobs(namespace).List(t.Ctx(), metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(map[string]string{
+			"ray.io/originated-from-cr-name": rayJobName,
+			"ray.io/originated-from-crd":     "RayJob",
+		}).String(),
+	})
+	t.Expect(err).NotTo(gomega.HaveOccurred())
+
+	var logs strings.Builder
+	for _, job := range jobs.Items {
+		selector, err := metav1.LabelSelectorAsSelector(job.Spec.Selector)
+		t.Expect(err).NotTo(gomega.HaveOccurred())
+		pods := support.GetPods(t, namespace, metav1.ListOptions{LabelSelector: selector.String()})
+		sort.Slice(pods, func(i, j int) bool {
+			return pods[i].CreationTimestamp.Before(&pods[j].CreationTimestamp)
+		})
+
+		for i := range pods {
+			pod := &pods[i]
+			for _, container := range pod.Spec.Containers {
+				fmt.Fprintf(&logs, "===== Pod %s/%s, container %s =====\n", pod.Namespace, pod.Name, container.Name)
+				if !containerStarted(pod, container.Name) {
+					logs.WriteString("The container hasn't started\n")
+					continue
+				}
+				logs.Write(support.GetPodLogs(t, pod, corev1.PodLogOptions{Container: container.Name}))
+			}
+		}
+	}
+
+	return logs.String()
+}
+
+// containerStarted returns whether the container has run, and has logs to be retrieved.
+func containerStarted(pod *corev1.Pod, container string) bool {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name == container {
+			return status.State.Running != nil || status.State.Terminated != nil || status.LastTerminationState.Terminated != nil
+		}
+	}
+	return false
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/onsi/gomega"
+	"github.com/project-codeflare/codeflare-common/support"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// rayServePort is the port of the Ray Serve HTTP proxy, exposed by the serve Service KubeRay creates for RayServices.
+const rayServePort = 8000
+
+// RayService returns a function that gets the RayService, to be polled with Eventually.
+func RayService(t support.Test, namespace, name string) func(g gomega.Gomega) *rayv1.RayService {
+	return func(g gomega.Gomega) *rayv1.RayService {
+		service, err := t.Client().Ray().RayV1().RayServices(namespace).Get(t.Ctx(), name, metav1.GetOptions{})
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+		return service
+	}
+}
+
+func GetRayService(t support.Test, namespace, name string) *rayv1.RayService {
+	t.T().Helper()
+	return RayService(t, namespace, name)(t)
+}
+
+// RayServiceStatus returns the status of the RayService, e.g., Running once its Serve applications are ready, e.g.:
+//
+//	test.Eventually(RayService(test, namespace, name), TestTimeoutLong).
+//		Should(WithTransform(RayServiceStatus, Equal(rayv1.Running)))
+func RayServiceStatus(service *rayv1.RayService) rayv1.ServiceStatus {
+	return service.Status.ServiceStatus
+}
+
+// RayServiceNumServeEndpoints returns the number of Ray pods that are selected by the serve Service.
+func RayServiceNumServeEndpoints(service *rayv1.RayService) int32 {
+	return service.Status.NumServeEndpoints
+}
+
+// RayServiceApplicationStatus returns a transform that gets the status of the Serve application of the active RayCluster,
+// e.g., RUNNING, or an empty string if the application isn't deployed.
+func RayServiceApplicationStatus(application string) func(service *rayv1.RayService) string {
+	return func(service *rayv1.RayService) string {
+		return service.Status.ActiveServiceStatus.Applications[application].Status
+	}
+}
+
+// RayServiceActiveRayClusterName returns the name of the RayCluster that serves the traffic of the RayService.
+func RayServiceActiveRayClusterName(service *rayv1.RayService) string {
+	return service.Status.ActiveServiceStatus.RayClusterName
+}
+
+// GetRayServeURL forwards a local port to the serve Service of the RayService, and returns its base URL,
+// e.g., http://127.0.0.1:40123, so that the Serve applications can be probed without relying on the cluster ingress.
+func GetRayServeURL(t support.Test, service *rayv1.RayService) string {
+	t.T().Helper()
+	return "http://" + SetupPortForward(t, service.Namespace, service.Name+"-serve-svc", rayServePort)
+}
+
+// RayServeResponse returns a function that sends a request to the path of the Serve endpoint, and returns the body
+// of the response, to be polled with Eventually until the application responds, e.g.:
+//
+//	test.Eventually(RayServeResponse(test, http.MethodPost, serveURL+"/fruit", `["MANGO", 2]`), TestTimeoutShort).
+//		Should(Equal("6"))
+func RayServeResponse(t support.Test, method, url, body string) func(g gomega.Gomega) string {
+	return func(g gomega.Gomega) string {
+		request, err := http.NewRequestWithContext(t.Ctx(), method, url, strings.NewReader(body))
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+		if body != "" {
+			request.Header.Set("Content-Type", "application/json")
+		}
+		response, err := http.DefaultClient.Do(request)
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+		defer response.Body.Close()
+		data, err := io.ReadAll(response.Body)
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+		g.Expect(response.StatusCode).To(gomega.Equal(http.StatusOK), "unexpected response from %s: %s", url, data)
+		return string(data)
+	}
+}
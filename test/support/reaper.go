@@ -0,0 +1,177 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/project-codeflare/codeflare-common/support"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	kueueclient "sigs.k8s.io/kueue/client-go/clientset/versioned"
+)
+
+const (
+	// TestResourceLabel is the label of the resources created by the tests, that outlive them when they're aborted,
+	// i.e., the test namespaces, and the cluster-scoped Kueue resources.
+	TestResourceLabel = "codeflare.dev/test-resource"
+
+	// ReaperTTLEnvVar is the environment variable of the age, e.g., 6h, past which the test resources are considered
+	// left over by aborted runs, and are deleted when the suite starts. Setting it to 0 disables the reaping.
+	ReaperTTLEnvVar = "CODEFLARE_TEST_REAPER_TTL"
+
+	defaultReaperTTL = 24 * time.Hour
+)
+
+// testResourceLabels returns the labels of the resources created by the tests.
+func testResourceLabels() map[string]string {
+	return map[string]string{TestResourceLabel: "true"}
+}
+
+// labelTestNamespace labels the namespace as created by the tests, so that it's reaped when the test is aborted.
+func labelTestNamespace(t support.Test, namespace *corev1.Namespace) {
+	t.T().Helper()
+
+	patch := []byte(fmt.Sprintf(`{"metadata":{"labels":{%q:"true"}}}`, TestResourceLabel))
+	RetryOnConflictOrTransient(t, func() error {
+		_, err := t.Client().Core().CoreV1().Namespaces().Patch(t.Ctx(), namespace.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+		return err
+	})
+}
+
+// WithReaper returns a runner that deletes the test resources left over by aborted runs, before running the tests,
+// so that they don't accumulate in shared clusters. It's meant to be called from the TestMain function of the suites.
+func WithReaper(m Runner) Runner {
+	return reaperRunner{m}
+}
+
+type reaperRunner struct {
+	Runner
+}
+
+func (r reaperRunner) Run() int {
+	ttl := defaultReaperTTL
+	if value, ok := os.LookupEnv(ReaperTTLEnvVar); ok {
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid %s value %q: %v\n", ReaperTTLEnvVar, value, err)
+			return 1
+		}
+		ttl = parsed
+	}
+
+	if ttl > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), support.TestTimeoutMedium)
+		err := ReapTestResources(ctx, ttl)
+		cancel()
+		if err != nil {
+			// The tests may still succeed, e.g., when the reaping is forbidden
+			fmt.Fprintf(os.Stderr, "Failed to reap the leftover test resources: %v\n", err)
+		}
+	}
+
+	return r.Runner.Run()
+}
+
+// ReapTestResources deletes the test namespaces, and the cluster-scoped Kueue resources, i.e., the ClusterQueues,
+// ResourceFlavors, and WorkloadPriorityClasses, that bear the test resource label, and are older than the TTL.
+func ReapTestResources(ctx context.Context, ttl time.Duration) error {
+	cfg, err := loadRESTConfig()
+	if err != nil {
+		return err
+	}
+	core, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return err
+	}
+	kueue, err := kueueclient.NewForConfig(cfg)
+	if err != nil {
+		return err
+	}
+
+	listOptions := metav1.ListOptions{LabelSelector: TestResourceLabel + "=true"}
+	expired := func(object metav1.Object) bool {
+		return object.GetDeletionTimestamp() == nil && time.Since(object.GetCreationTimestamp().Time) > ttl
+	}
+	var errs []error
+	reap := func(kind string, object metav1.Object, remove func() error) {
+		if !expired(object) {
+			return
+		}
+		if err := remove(); err != nil && !apierrors.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("deleting %s %s: %w", kind, object.GetName(), err))
+			return
+		}
+		fmt.Fprintf(os.Stderr, "Deleted leftover test %s %s, created at %s\n", kind, object.GetName(), object.GetCreationTimestamp().UTC())
+	}
+
+	// The namespaces are deleted first, so that the ClusterQueues have no more workloads
+	namespaces, err := core.CoreV1().Namespaces().List(ctx, listOptions)
+	if err != nil {
+		return err
+	}
+	propagationPolicy := metav1.DeletePropagationBackground
+	for i := range namespaces.Items {
+		namespace := &namespaces.Items[i]
+		reap("Namespace", namespace, func() error {
+			return core.CoreV1().Namespaces().Delete(ctx, namespace.Name, metav1.DeleteOptions{PropagationPolicy: &propagationPolicy})
+		})
+	}
+
+	clusterQueues, err := kueue.KueueV1beta1().ClusterQueues().List(ctx, listOptions)
+	if err != nil {
+		return errors.Join(append(errs, err)...)
+	}
+	for i := range clusterQueues.Items {
+		clusterQueue := &clusterQueues.Items[i]
+		reap("ClusterQueue", clusterQueue, func() error {
+			return kueue.KueueV1beta1().ClusterQueues().Delete(ctx, clusterQueue.Name, metav1.DeleteOptions{})
+		})
+	}
+
+	resourceFlavors, err := kueue.KueueV1beta1().ResourceFlavors().List(ctx, listOptions)
+	if err != nil {
+		return errors.Join(append(errs, err)...)
+	}
+	for i := range resourceFlavors.Items {
+		resourceFlavor := &resourceFlavors.Items[i]
+		reap("ResourceFlavor", resourceFlavor, func() error {
+			return kueue.KueueV1beta1().ResourceFlavors().Delete(ctx, resourceFlavor.Name, metav1.DeleteOptions{})
+		})
+	}
+
+	priorityClasses, err := kueue.KueueV1beta1().WorkloadPriorityClasses().List(ctx, listOptions)
+	if err != nil {
+		return errors.Join(append(errs, err)...)
+	}
+	for i := range priorityClasses.Items {
+		priorityClass := &priorityClasses.Items[i]
+		reap("WorkloadPriorityClass", priorityClass, func() error {
+			return kueue.KueueV1beta1().WorkloadPriorityClasses().Delete(ctx, priorityClass.Name, metav1.DeleteOptions{})
+		})
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/onsi/gomega"
+	"github.com/project-codeflare/codeflare-common/support"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	// RedisImageEnvVar is the environment variable of the Redis image, e.g., to pull it from a mirror registry.
+	RedisImageEnvVar = "CODEFLARE_TEST_REDIS_IMAGE"
+
+	defaultRedisImage = "docker.io/library/redis:7.2"
+	redisName         = "redis"
+	redisPort         = 6379
+)
+
+// DeployRedis deploys an ephemeral Redis instance into the namespace, e.g., to back the GCS fault tolerance of
+// a RayCluster, waits for it to be ready, and returns its address, reachable from within the cluster.
+// The instance doesn't persist its data, so that it can run with any user, and is deleted along with the namespace.
+func DeployRedis(t support.Test, namespace string) string {
+	t.T().Helper()
+
+	labels := map[string]string{"app.kubernetes.io/name": redisName}
+
+	deployment := &appsv1.Deployment{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: appsv1.SchemeGroupVersion.String(),
+			Kind:       "Deployment",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      redisName,
+			Namespace: namespace,
+			Labels:    labels,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: ptr.To(int32(1)),
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:  redisName,
+							Image: redisImage(),
+							// The entrypoint of the image is bypassed, as it changes the ownership of the data directory
+							Command: []string{"redis-server", "--save", "", "--appendonly", "no"},
+							Ports: []corev1.ContainerPort{
+								{Name: "redis", ContainerPort: redisPort},
+							},
+							ReadinessProbe: &corev1.Probe{
+								ProbeHandler: corev1.ProbeHandler{
+									Exec: &corev1.ExecAction{Command: []string{"redis-cli", "ping"}},
+								},
+								PeriodSeconds: 2,
+							},
+							SecurityContext: &corev1.SecurityContext{
+								AllowPrivilegeEscalation: ptr.To(false),
+								Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+								SeccompProfile:           &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	_, err := t.Client().Core().AppsV1().Deployments(namespace).Create(t.Ctx(), deployment, metav1.CreateOptions{})
+	t.Expect(err).NotTo(gomega.HaveOccurred())
+
+	service := &corev1.Service{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.String(),
+			Kind:       "Service",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      redisName,
+			Namespace: namespace,
+			Labels:    labels,
+		},
+		Spec: corev1.ServiceSpec{
+			Selector: labels,
+			Ports: []corev1.ServicePort{
+				{Name: "redis", Port: redisPort, TargetPort: intstr.FromString("redis")},
+			},
+		},
+	}
+	_, err = t.Client().Core().CoreV1().Services(namespace).Create(t.Ctx(), service, metav1.CreateOptions{})
+	t.Expect(err).NotTo(gomega.HaveOccurred())
+
+	address := fmt.Sprintf("%s.%s.svc:%d", redisName, namespace, redisPort)
+	t.T().Logf("Waiting for Redis %s/%s to be ready", namespace, redisName)
+	t.Eventually(Deployment(t, namespace, redisName), support.TestTimeoutMedium).
+		Should(gomega.WithTransform(DeploymentReady, gomega.BeTrue()))
+	t.T().Logf("Deployed Redis %s/%s, available at %s", namespace, redisName, address)
+
+	return address
+}
+
+func redisImage() string {
+	if image, ok := os.LookupEnv(RedisImageEnvVar); ok {
+		return image
+	}
+	return defaultRedisImage
+}
@@ -0,0 +1,186 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/project-codeflare/codeflare-common/support"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+	metricsclient "k8s.io/metrics/pkg/client/clientset/versioned"
+)
+
+// LogResourceUsage logs a snapshot of the CPU and memory used by the nodes, and by the pods of the namespace,
+// as reported by the metrics.k8s.io API, alongside their requests and the allocatable resources of the nodes,
+// including the accelerators, whose usage isn't reported by the metrics API. It can be called at key checkpoints
+// of the tests, to troubleshoot quota, scheduling, or OOM issues. The usage is omitted if the metrics API
+// isn't served, e.g., when the metrics server isn't deployed.
+func LogResourceUsage(t support.Test, namespace string) {
+	t.T().Helper()
+
+	nodes, err := t.Client().Core().CoreV1().Nodes().List(t.Ctx(), metav1.ListOptions{})
+	if err != nil {
+		// Troubleshooting is best effort, and must not mask the original failure
+		t.T().Logf("Failed to list nodes: %v", err)
+		return
+	}
+	pods, err := t.Client().Core().CoreV1().Pods(metav1.NamespaceAll).List(t.Ctx(), metav1.ListOptions{
+		FieldSelector: "status.phase!=" + string(corev1.PodSucceeded) + ",status.phase!=" + string(corev1.PodFailed),
+	})
+	if err != nil {
+		t.T().Logf("Failed to list pods: %v", err)
+		return
+	}
+	nodeRequests := map[string]corev1.ResourceList{}
+	for _, pod := range pods.Items {
+		if pod.Spec.NodeName != "" {
+			nodeRequests[pod.Spec.NodeName] = addResources(nodeRequests[pod.Spec.NodeName], podRequests(&pod))
+		}
+	}
+
+	nodeUsage, podUsage := resourceMetrics(t, namespace)
+
+	t.T().Logf("Resource usage of nodes (used / requested / allocatable):")
+	for _, node := range nodes.Items {
+		t.T().Logf("  %s  %s", node.Name, formatResources(nodeUsage[node.Name], nodeRequests[node.Name], node.Status.Allocatable))
+	}
+
+	t.T().Logf("Resource usage of pods in namespace %s (used / requested / limit):", namespace)
+	count := 0
+	for _, pod := range pods.Items {
+		if pod.Namespace != namespace {
+			continue
+		}
+		count++
+		t.T().Logf("  %s  %s  %s", pod.Name, pod.Spec.NodeName, formatResources(podUsage[pod.Name], podRequests(&pod), podLimits(&pod)))
+	}
+	if count == 0 {
+		t.T().Logf("  <none>")
+	}
+}
+
+// resourceMetrics returns the usage of the nodes, and of the pods of the namespace, indexed by name.
+func resourceMetrics(t support.Test, namespace string) (map[string]corev1.ResourceList, map[string]corev1.ResourceList) {
+	nodeUsage := map[string]corev1.ResourceList{}
+	podUsage := map[string]corev1.ResourceList{}
+
+	client, err := metricsclient.NewForConfig(restConfig(t))
+	if err != nil {
+		t.T().Logf("Failed to create metrics client: %v", err)
+		return nodeUsage, podUsage
+	}
+
+	nodeMetrics, err := client.MetricsV1beta1().NodeMetricses().List(t.Ctx(), metav1.ListOptions{})
+	if err != nil {
+		t.T().Logf("Resource usage unavailable, the metrics API isn't served: %v", err)
+		return nodeUsage, podUsage
+	}
+	for _, metrics := range nodeMetrics.Items {
+		nodeUsage[metrics.Name] = metrics.Usage
+	}
+
+	podMetrics, err := client.MetricsV1beta1().PodMetricses(namespace).List(t.Ctx(), metav1.ListOptions{})
+	if err != nil {
+		t.T().Logf("Failed to list pod metrics in namespace %s: %v", namespace, err)
+		return nodeUsage, podUsage
+	}
+	for _, metrics := range podMetrics.Items {
+		podUsage[metrics.Name] = podMetricsUsage(metrics)
+	}
+
+	return nodeUsage, podUsage
+}
+
+func podMetricsUsage(metrics metricsv1beta1.PodMetrics) corev1.ResourceList {
+	var usage corev1.ResourceList
+	for _, container := range metrics.Containers {
+		usage = addResources(usage, container.Usage)
+	}
+	return usage
+}
+
+func podRequests(pod *corev1.Pod) corev1.ResourceList {
+	var requests corev1.ResourceList
+	for _, container := range pod.Spec.Containers {
+		requests = addResources(requests, container.Resources.Requests)
+	}
+	return requests
+}
+
+func podLimits(pod *corev1.Pod) corev1.ResourceList {
+	var limits corev1.ResourceList
+	for _, container := range pod.Spec.Containers {
+		limits = addResources(limits, container.Resources.Limits)
+	}
+	return limits
+}
+
+func addResources(total, resources corev1.ResourceList) corev1.ResourceList {
+	if total == nil {
+		total = corev1.ResourceList{}
+	}
+	for name, quantity := range resources {
+		sum := total[name]
+		sum.Add(quantity)
+		total[name] = sum
+	}
+	return total
+}
+
+// formatResources formats the CPU, memory, and accelerator quantities of the resource lists side by side,
+// e.g., cpu: 1250m / 2000m / 8000m  memory: 3.1Gi / 4.0Gi / 16.0Gi  nvidia.com/gpu: - / 1 / 2.
+// Accelerators are only included if any of the lists has them.
+func formatResources(lists ...corev1.ResourceList) string {
+	names := []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory}
+	for _, accelerator := range accelerators {
+		for _, list := range lists {
+			if _, ok := list[accelerator.ResourceName]; ok {
+				names = append(names, accelerator.ResourceName)
+				break
+			}
+		}
+	}
+
+	fields := make([]string, 0, len(names))
+	for _, name := range names {
+		values := make([]string, 0, len(lists))
+		for _, list := range lists {
+			values = append(values, formatQuantity(name, list))
+		}
+		fields = append(fields, fmt.Sprintf("%s: %s", name, strings.Join(values, " / ")))
+	}
+	return strings.Join(fields, "  ")
+}
+
+func formatQuantity(name corev1.ResourceName, list corev1.ResourceList) string {
+	quantity, ok := list[name]
+	if !ok {
+		return "-"
+	}
+	switch name {
+	case corev1.ResourceCPU:
+		return fmt.Sprintf("%dm", quantity.MilliValue())
+	case corev1.ResourceMemory:
+		return fmt.Sprintf("%.1fGi", float64(quantity.Value())/(1<<30))
+	default:
+		return quantity.String()
+	}
+}
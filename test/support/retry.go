@@ -0,0 +1,80 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"errors"
+	"net"
+	"time"
+
+	"github.com/onsi/gomega"
+	"github.com/project-codeflare/codeflare-common/support"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/retry"
+)
+
+// TransientBackoff is the backoff of RetryOnConflictOrTransient. It amounts to about a minute,
+// which is enough for the API server to recover from a rollout or a burst of requests.
+var TransientBackoff = wait.Backoff{
+	Duration: 250 * time.Millisecond,
+	Factor:   2,
+	Jitter:   0.1,
+	Steps:    8,
+	Cap:      15 * time.Second,
+}
+
+// RetryOnConflictOrTransient calls the function, that's expected to perform API calls, until it succeeds, or fails
+// with an error that's not retriable, with exponential backoff. Conflicts, rate limiting, server-side timeouts,
+// and transient network errors are retried. The function must re-read the objects it updates, so that it doesn't
+// run into the same conflict again. The test fails if the function doesn't eventually succeed.
+func RetryOnConflictOrTransient(t support.Test, fn func() error) {
+	t.T().Helper()
+
+	attempts := 0
+	err := retry.OnError(TransientBackoff, IsConflictOrTransient, func() error {
+		attempts++
+		err := fn()
+		if err != nil && IsConflictOrTransient(err) {
+			t.T().Logf("Retrying after error: %v", err)
+		}
+		return err
+	})
+	t.Expect(err).NotTo(gomega.HaveOccurred(), "failed after %d attempt(s)", attempts)
+}
+
+// IsConflictOrTransient returns whether the error is an API conflict, or an error that's expected to be transient.
+func IsConflictOrTransient(err error) bool {
+	if err == nil {
+		return false
+	}
+	if apierrors.IsConflict(err) ||
+		apierrors.IsTooManyRequests(err) ||
+		apierrors.IsServerTimeout(err) ||
+		apierrors.IsTimeout(err) ||
+		apierrors.IsServiceUnavailable(err) ||
+		apierrors.IsInternalError(err) {
+		return true
+	}
+	if utilnet.IsConnectionReset(err) || utilnet.IsConnectionRefused(err) || utilnet.IsProbableEOF(err) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"github.com/onsi/gomega"
+	"github.com/project-codeflare/codeflare-common/support"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+// RayClusterPods returns a function that lists the pods of the RayCluster, including the terminating ones,
+// to be polled with Eventually.
+func RayClusterPods(t support.Test, namespace, name string) func(g gomega.Gomega) []corev1.Pod {
+	return func(g gomega.Gomega) []corev1.Pod {
+		pods, err := t.Client().Core().CoreV1().Pods(namespace).List(t.Ctx(), metav1.ListOptions{
+			LabelSelector: "ray.io/cluster=" + name,
+		})
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+		return pods.Items
+	}
+}
+
+// RayClusterHeadPod returns a function that gets the head pod of the RayCluster, to be polled with Eventually.
+// The function fails if there's no running head pod, e.g., while it's being recreated.
+func RayClusterHeadPod(t support.Test, namespace, name string) func(g gomega.Gomega) *corev1.Pod {
+	return func(g gomega.Gomega) *corev1.Pod {
+		pods, err := t.Client().Core().CoreV1().Pods(namespace).List(t.Ctx(), metav1.ListOptions{
+			LabelSelector: "ray.io/cluster=" + name + ",ray.io/node-type=head",
+		})
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+		var head *corev1.Pod
+		for i := range pods.Items {
+			if pods.Items[i].DeletionTimestamp == nil && pods.Items[i].Status.Phase == corev1.PodRunning {
+				head = &pods.Items[i]
+			}
+		}
+		g.Expect(head).NotTo(gomega.BeNil(), "no running head pod for RayCluster %s/%s", namespace, name)
+		return head
+	}
+}
+
+func GetRayClusterHeadPod(t support.Test, namespace, name string) *corev1.Pod {
+	t.T().Helper()
+	return RayClusterHeadPod(t, namespace, name)(t)
+}
+
+// ScheduledPods returns the number of pods that are bound to a node, and hold its resources,
+// i.e., that haven't completed, including the terminating ones.
+func ScheduledPods(pods []corev1.Pod) int32 {
+	var scheduled int32
+	for _, pod := range pods {
+		if pod.Spec.NodeName != "" && pod.Status.Phase != corev1.PodSucceeded && pod.Status.Phase != corev1.PodFailed {
+			scheduled++
+		}
+	}
+	return scheduled
+}
+
+// ExpectAllOrNothingScheduled asserts the RayCluster is gang scheduled, i.e., that all its pods, the head and
+// the desired workers, are scheduled once it's admitted, and none is while it's suspended, e.g., by Kueue,
+// pending admission, or after its eviction. The pods are waited for to settle, so that a partial allocation,
+// where some pods hold resources while the others can't be scheduled, fails the test once it lingers.
+func ExpectAllOrNothingScheduled(t support.Test, rayCluster *rayv1.RayCluster) {
+	t.T().Helper()
+
+	cluster := support.GetRayCluster(t, rayCluster.Namespace, rayCluster.Name)
+	expected, gang := int32(0), 1+RayClusterDesiredWorkerReplicas(cluster)
+	if !ptr.Deref(cluster.Spec.Suspend, false) {
+		expected = gang
+	}
+
+	t.T().Logf("Waiting for %d of the %d pods of RayCluster %s/%s to be scheduled", expected, gang, cluster.Namespace, cluster.Name)
+	t.Eventually(RayClusterPods(t, cluster.Namespace, cluster.Name), support.TestTimeoutMedium).
+		Should(gomega.WithTransform(ScheduledPods, gomega.Equal(expected)),
+			"RayCluster %s/%s is partially scheduled", cluster.Namespace, cluster.Name)
+}
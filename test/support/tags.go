@@ -0,0 +1,101 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/project-codeflare/codeflare-common/support"
+)
+
+// TestTagsEnvVar is the environment variable of the tags the tests are selected by. It's a comma-separated list of tags,
+// e.g., smoke,!gpu, where the tests are selected when they have one of the tags, or all of them if there's none,
+// except the scale and soak ones, unless they have one of the tags prefixed with !.
+const TestTagsEnvVar = "CODEFLARE_TEST_TAGS"
+
+// Tag is a trait of a test, or a capability it requires from the cluster, that the tests are selected by.
+type Tag string
+
+const (
+	// Smoke tags the quick tests that cover the main features, to be run on every change.
+	Smoke Tag = "smoke"
+	// GPU tags the tests that require accelerators. They are skipped when there's none in the cluster.
+	GPU Tag = "gpu"
+	// LongRunning tags the tests that take more than a few minutes, like the training ones.
+	LongRunning Tag = "long-running"
+	// OpenShiftOnly tags the tests that depend on OpenShift, e.g., on Routes. They are skipped on other clusters.
+	OpenShiftOnly Tag = "openshift-only"
+	// Scale tags the scalability tests, that load the cluster with hundreds of resources.
+	// They only run when selected explicitly.
+	Scale Tag = "scale"
+	// Soak tags the endurance tests, that run workloads continuously for hours. They only run when selected explicitly.
+	Soak Tag = "soak"
+)
+
+// explicitTags are the tags of the tests that only run when selected explicitly, e.g., as they'd disrupt the other tests.
+var explicitTags = []Tag{Scale, Soak}
+
+// Tags tags the test, and skips it, unless it's selected with the CODEFLARE_TEST_TAGS environment variable, and the
+// cluster has the capabilities its tags require. It must be called first in the test, e.g.:
+//
+//	func TestMNISTPyTorchJob(t *testing.T) {
+//		test := With(t)
+//		Tags(test, LongRunning)
+func Tags(t support.Test, tags ...Tag) {
+	t.T().Helper()
+
+	if selected, reason := selectedByTags(os.Getenv(TestTagsEnvVar), tags); !selected {
+		t.T().Skip(reason)
+	}
+	if slices.Contains(tags, OpenShiftOnly) && !support.IsOpenShift(t) {
+		t.T().Skip("Requires OpenShift")
+	}
+	if slices.Contains(tags, GPU) {
+		if _, ok := DetectAccelerators(t).Select(1); !ok {
+			t.T().Skip("Requires accelerators")
+		}
+	}
+}
+
+// selectedByTags returns whether the tags match the filter, and the reason why when they don't.
+func selectedByTags(filter string, tags []Tag) (bool, string) {
+	var included []Tag
+	for _, value := range strings.Split(filter, ",") {
+		value = strings.TrimSpace(value)
+		if excluded, ok := strings.CutPrefix(value, "!"); ok {
+			if slices.Contains(tags, Tag(excluded)) {
+				return false, "Excluded by tag " + excluded
+			}
+		} else if value != "" {
+			included = append(included, Tag(value))
+		}
+	}
+	if slices.ContainsFunc(tags, func(tag Tag) bool { return slices.Contains(included, tag) }) {
+		return true, ""
+	}
+	for _, tag := range explicitTags {
+		if slices.Contains(tags, tag) {
+			return false, "Only run when selected with tag " + string(tag)
+		}
+	}
+	if len(included) == 0 {
+		return true, ""
+	}
+	return false, "Not selected by tags " + filter
+}
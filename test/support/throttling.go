@@ -0,0 +1,97 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/onsi/gomega"
+	"github.com/project-codeflare/codeflare-common/support"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// APIServerThrottlingEnvVar enables the tests asserting the operator behavior under API server pressure.
+	// It must only be set when the operator reaches the API server through the throttling proxy,
+	// e.g., when it's deployed with make deploy-e2e-apiserver-throttling.
+	APIServerThrottlingEnvVar = "CODEFLARE_TEST_APISERVER_THROTTLING"
+
+	// defaultWebhookTimeout is the timeout of the admission webhooks that don't set one.
+	defaultWebhookTimeout = 10 * time.Second
+)
+
+// IsAPIServerThrottlingEnabled returns whether the operator under test reaches the API server through the throttling proxy.
+func IsAPIServerThrottlingEnabled() bool {
+	enabled, _ := strconv.ParseBool(os.Getenv(APIServerThrottlingEnvVar))
+	return enabled
+}
+
+// GetWebhookTimeout returns the timeout of the mutating or validating admission webhook with the given name,
+// e.g., mraycluster.ray.openshift.ai, after which the API server fails the admission.
+func GetWebhookTimeout(t support.Test, webhookName string) time.Duration {
+	t.T().Helper()
+
+	mutating, err := t.Client().Core().AdmissionregistrationV1().MutatingWebhookConfigurations().List(t.Ctx(), metav1.ListOptions{})
+	t.Expect(err).NotTo(gomega.HaveOccurred())
+	for _, configuration := range mutating.Items {
+		for _, webhook := range configuration.Webhooks {
+			if webhook.Name == webhookName {
+				return webhookTimeout(webhook.TimeoutSeconds)
+			}
+		}
+	}
+
+	validating, err := t.Client().Core().AdmissionregistrationV1().ValidatingWebhookConfigurations().List(t.Ctx(), metav1.ListOptions{})
+	t.Expect(err).NotTo(gomega.HaveOccurred())
+	for _, configuration := range validating.Items {
+		for _, webhook := range configuration.Webhooks {
+			if webhook.Name == webhookName {
+				return webhookTimeout(webhook.TimeoutSeconds)
+			}
+		}
+	}
+
+	t.T().Fatalf("Admission webhook %s not found", webhookName)
+	return 0
+}
+
+func webhookTimeout(seconds *int32) time.Duration {
+	if seconds == nil {
+		return defaultWebhookTimeout
+	}
+	return time.Duration(*seconds) * time.Second
+}
+
+// GetContainerRestarts returns the total number of restarts of the containers of the pods matching the label selector,
+// e.g., to assert the operator didn't crash or fail its liveness probe.
+func GetContainerRestarts(t support.Test, namespace, labelSelector string) int32 {
+	t.T().Helper()
+
+	pods, err := t.Client().Core().CoreV1().Pods(namespace).List(t.Ctx(), metav1.ListOptions{LabelSelector: labelSelector})
+	t.Expect(err).NotTo(gomega.HaveOccurred())
+
+	var restarts int32
+	for _, pod := range pods.Items {
+		for _, status := range pod.Status.ContainerStatuses {
+			restarts += status.RestartCount
+		}
+	}
+	return restarts
+}
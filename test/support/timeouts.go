@@ -0,0 +1,57 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/onsi/gomega"
+	"github.com/project-codeflare/codeflare-common/support"
+)
+
+// TimeoutFactorEnvVar is the environment variable of the factor the test timeouts are multiplied by,
+// e.g., 2.5, so that the same suites can run in slower environments, like disconnected clusters,
+// or clusters with emulated GPUs, without overriding each timeout.
+const TimeoutFactorEnvVar = "CODEFLARE_TEST_TIMEOUT_FACTOR"
+
+// The codeflare-common package initializes the timeouts, possibly overridden with their own environment variable,
+// before this package is initialized, so the factor applies on top of them.
+func init() {
+	value, ok := os.LookupEnv(TimeoutFactorEnvVar)
+	if !ok {
+		return
+	}
+	factor, err := strconv.ParseFloat(value, 64)
+	if err != nil || factor <= 0 {
+		fmt.Printf("Error parsing %s. Using default value: 1", TimeoutFactorEnvVar)
+		return
+	}
+
+	support.TestTimeoutShort = scaleTimeout(support.TestTimeoutShort, factor)
+	support.TestTimeoutMedium = scaleTimeout(support.TestTimeoutMedium, factor)
+	support.TestTimeoutLong = scaleTimeout(support.TestTimeoutLong, factor)
+	support.TestTimeoutGpuProvisioning = scaleTimeout(support.TestTimeoutGpuProvisioning, factor)
+
+	gomega.SetDefaultEventuallyTimeout(support.TestTimeoutShort)
+}
+
+func scaleTimeout(timeout time.Duration, factor float64) time.Duration {
+	return time.Duration(float64(timeout) * factor)
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package upgrade
+
+import (
+	"encoding/json"
+	"os"
+	"slices"
+
+	"github.com/onsi/gomega"
+	"github.com/project-codeflare/codeflare-common/support"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/yaml"
+
+	"github.com/project-codeflare/codeflare-operator/pkg/config"
+	testsupport "github.com/project-codeflare/codeflare-operator/test/support"
+)
+
+const (
+	// PhaseEnvVar is the environment variable of the phase of the upgrade tests to run, i.e., pre-upgrade,
+	// against release N of the operator, or post-upgrade, against the operator built from the local sources.
+	PhaseEnvVar = "CODEFLARE_TEST_UPGRADE_PHASE"
+
+	PhasePreUpgrade  = "pre"
+	PhasePostUpgrade = "post"
+
+	// The workloads are created into a well-known namespace, so that they're found by the post-upgrade phase
+	upgradeNamespace      = "codeflare-upgrade"
+	upgradeStateConfigMap = "upgrade-state"
+	upgradeStateKey       = "state.json"
+
+	operatorConfigMapName = "codeflare-operator-config"
+)
+
+// upgradeState is the state of the workloads, recorded by the pre-upgrade phase,
+// that's compared with the state the post-upgrade phase observes.
+type upgradeState struct {
+	LocalQueue string        `json:"localQueue"`
+	RayCluster workloadState `json:"rayCluster"`
+	AppWrapper workloadState `json:"appWrapper"`
+}
+
+type workloadState struct {
+	Name       string      `json:"name"`
+	Generation int64       `json:"generation"`
+	Retries    int32       `json:"retries,omitempty"`
+	Pods       []types.UID `json:"pods"`
+}
+
+// RunPhase skips the test unless the phase is the one selected with the CODEFLARE_TEST_UPGRADE_PHASE
+// environment variable, as each phase runs against a different version of the operator.
+func RunPhase(t support.Test, phase string) {
+	t.T().Helper()
+	if selected := os.Getenv(PhaseEnvVar); selected != phase {
+		t.T().Skipf("Skipping %s-upgrade test, as %s is %q", phase, PhaseEnvVar, selected)
+	}
+}
+
+func saveUpgradeState(t support.Test, state upgradeState) {
+	t.T().Helper()
+
+	data, err := json.Marshal(state)
+	t.Expect(err).NotTo(gomega.HaveOccurred())
+	configMap := &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.String(),
+			Kind:       "ConfigMap",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      upgradeStateConfigMap,
+			Namespace: upgradeNamespace,
+		},
+		Data: map[string]string{upgradeStateKey: string(data)},
+	}
+	_, err = t.Client().Core().CoreV1().ConfigMaps(upgradeNamespace).Create(t.Ctx(), configMap, metav1.CreateOptions{})
+	t.Expect(err).NotTo(gomega.HaveOccurred())
+}
+
+func loadUpgradeState(t support.Test) upgradeState {
+	t.T().Helper()
+
+	configMap, err := t.Client().Core().CoreV1().ConfigMaps(upgradeNamespace).Get(t.Ctx(), upgradeStateConfigMap, metav1.GetOptions{})
+	t.Expect(err).NotTo(gomega.HaveOccurred(), "the pre-upgrade phase must be run first")
+	state := upgradeState{}
+	t.Expect(json.Unmarshal([]byte(configMap.Data[upgradeStateKey]), &state)).To(gomega.Succeed())
+	return state
+}
+
+// RayClusterPodUIDs returns a function that gets the sorted UIDs of the non-terminating pods of the RayCluster,
+// to be polled with Eventually, or Consistently, to assert the pods haven't been recreated.
+func RayClusterPodUIDs(t support.Test, namespace, name string) func(g gomega.Gomega) []types.UID {
+	return func(g gomega.Gomega) []types.UID {
+		var uids []types.UID
+		for _, pod := range testsupport.RayClusterPods(t, namespace, name)(g) {
+			if pod.DeletionTimestamp == nil {
+				uids = append(uids, pod.UID)
+			}
+		}
+		slices.Sort(uids)
+		return uids
+	}
+}
+
+// GetOperatorConfiguration returns the configuration of the operator, as it's loaded on start, with
+// the ingress domain of the cluster when it's not configured, so that the defaults it applies can be reproduced.
+func GetOperatorConfiguration(t support.Test) *config.CodeFlareOperatorConfiguration {
+	t.T().Helper()
+
+	configMap, err := t.Client().Core().CoreV1().ConfigMaps(testsupport.GetOperatorNamespace()).Get(t.Ctx(), operatorConfigMapName, metav1.GetOptions{})
+	t.Expect(err).NotTo(gomega.HaveOccurred())
+	cfg := &config.CodeFlareOperatorConfiguration{}
+	t.Expect(yaml.Unmarshal([]byte(configMap.Data["config.yaml"]), cfg)).To(gomega.Succeed())
+	if cfg.KubeRay == nil {
+		cfg.KubeRay = &config.KubeRayConfiguration{}
+	}
+
+	if cfg.KubeRay.IngressDomain == "" && support.IsOpenShift(t) {
+		ingress, err := t.Client().Dynamic().
+			Resource(schema.GroupVersionResource{Group: "config.openshift.io", Version: "v1", Resource: "ingresses"}).
+			Get(t.Ctx(), "cluster", metav1.GetOptions{})
+		t.Expect(err).NotTo(gomega.HaveOccurred())
+		cfg.KubeRay.IngressDomain, _, err = unstructured.NestedString(ingress.Object, "spec", "domain")
+		t.Expect(err).NotTo(gomega.HaveOccurred())
+	}
+
+	return cfg
+}
@@ -0,0 +1,53 @@
+#!/bin/bash
+
+# Copyright 2024 IBM, Red Hat
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#      http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+# Deploys the PREVIOUS_VERSION release of the CodeFlare operator, from the manifests of its tag, runs the pre-upgrade
+# phase of the upgrade tests, upgrades the operator to the IMG image, built from the local sources, and runs
+# the post-upgrade phase.
+
+set -euo pipefail
+: "${PREVIOUS_VERSION:?PREVIOUS_VERSION must be set to the release to upgrade from, e.g., v1.5.0}"
+: "${PREVIOUS_IMG:=quay.io/project-codeflare/codeflare-operator:${PREVIOUS_VERSION}}"
+: "${IMG:?IMG must be set to the operator image built from the local sources}"
+: "${CODEFLARE_OPERATOR_NAMESPACE:=openshift-operators}"
+: "${UPSTREAM_REPOSITORY:=https://github.com/project-codeflare/codeflare-operator.git}"
+
+ROOT_DIR=$(git rev-parse --show-toplevel)
+RELEASE_DIR=$(mktemp -d)
+trap 'git -C "${ROOT_DIR}" worktree remove --force "${RELEASE_DIR}"' EXIT
+
+deploy_operator() {
+  local dir=$1 image=$2
+  make -C "${dir}" deploy -e IMG="${image}" -e ENV=e2e
+  # The locally built image may only be available from the nodes, e.g., of a KinD cluster
+  kubectl patch deployment -n "${CODEFLARE_OPERATOR_NAMESPACE}" codeflare-operator-manager --type=json \
+    --patch='[{"op":"replace","path":"/spec/template/spec/containers/0/imagePullPolicy","value":"IfNotPresent"}]'
+  kubectl rollout status deployment -n "${CODEFLARE_OPERATOR_NAMESPACE}" codeflare-operator-manager --timeout=300s
+}
+
+echo "Deploying the CodeFlare operator ${PREVIOUS_VERSION}"
+git -C "${ROOT_DIR}" fetch --depth 1 "${UPSTREAM_REPOSITORY}" "refs/tags/${PREVIOUS_VERSION}:refs/tags/${PREVIOUS_VERSION}"
+git -C "${ROOT_DIR}" worktree add --detach "${RELEASE_DIR}" "${PREVIOUS_VERSION}"
+deploy_operator "${RELEASE_DIR}" "${PREVIOUS_IMG}"
+
+echo "Running the pre-upgrade tests"
+CODEFLARE_TEST_UPGRADE_PHASE=pre go test -count=1 -timeout 30m -v "${ROOT_DIR}/test/upgrade"
+
+echo "Upgrading the CodeFlare operator to ${IMG}"
+deploy_operator "${ROOT_DIR}" "${IMG}"
+
+echo "Running the post-upgrade tests"
+CODEFLARE_TEST_UPGRADE_PHASE=post go test -count=1 -timeout 30m -v "${ROOT_DIR}/test/upgrade"
@@ -0,0 +1,203 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package upgrade
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	awv1beta2 "github.com/project-codeflare/appwrapper/api/v1beta2"
+	. "github.com/project-codeflare/codeflare-common/support"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/project-codeflare/codeflare-operator/pkg/defaults"
+	. "github.com/project-codeflare/codeflare-operator/test/support"
+)
+
+const (
+	rayClusterName    = "raycluster-upgrade"
+	appWrapperName    = "appwrapper-upgrade"
+	newRayClusterName = "raycluster-upgrade-new"
+)
+
+// Creates a RayCluster, and an AppWrapper wrapping a RayCluster, with release N of the operator,
+// and records their state, to be compared with the state observed after the upgrade.
+func TestPreUpgrade(t *testing.T) {
+	test := With(t)
+	RunPhase(test, PhasePreUpgrade)
+
+	// The namespace is deleted by the post-upgrade phase, or reaped if it's never run
+	namespace := &corev1.Namespace{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.String(),
+			Kind:       "Namespace",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   upgradeNamespace,
+			Labels: map[string]string{TestResourceLabel: "true"},
+		},
+	}
+	namespace, err := test.Client().Core().CoreV1().Namespaces().Create(test.Ctx(), namespace, metav1.CreateOptions{})
+	test.Expect(err).NotTo(HaveOccurred())
+	DumpEventsOnFailure(test, namespace.Name)
+	CollectDiagnosticsOnFailure(test, namespace.Name)
+	localQueue := CreateKueueLocalQueue(test, namespace.Name, "e2e-cluster-queue")
+
+	rayCluster := newRayCluster(namespace.Name, rayClusterName, localQueue.Name)
+	rayCluster, err = test.Client().Ray().RayV1().RayClusters(namespace.Name).Create(test.Ctx(), rayCluster, metav1.CreateOptions{})
+	test.Expect(err).NotTo(HaveOccurred())
+	test.T().Logf("Created RayCluster %s/%s successfully", rayCluster.Namespace, rayCluster.Name)
+
+	aw := &awv1beta2.AppWrapper{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: awv1beta2.GroupVersion.String(),
+			Kind:       "AppWrapper",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      appWrapperName,
+			Namespace: namespace.Name,
+			Labels:    map[string]string{"kueue.x-k8s.io/queue-name": localQueue.Name},
+		},
+		Spec: awv1beta2.AppWrapperSpec{
+			Components: []awv1beta2.AppWrapperComponent{
+				{
+					Template: Raw(test, newRayCluster(namespace.Name, appWrapperName, "")),
+				},
+			},
+		},
+	}
+	aw = CreateAppWrapper(test, aw)
+
+	test.T().Logf("Waiting for RayCluster %s/%s to be running", rayCluster.Namespace, rayCluster.Name)
+	test.Eventually(RayCluster(test, namespace.Name, rayCluster.Name), TestTimeoutMedium).
+		Should(WithTransform(RayClusterState, Equal(rayv1.Ready)))
+
+	test.T().Logf("Waiting for AppWrapper %s/%s to be running", aw.Namespace, aw.Name)
+	test.Eventually(AppWrapper(test, namespace, aw.Name), TestTimeoutMedium).
+		Should(WithTransform(AppWrapperPhase, Equal(awv1beta2.AppWrapperRunning)))
+	test.Eventually(RayCluster(test, namespace.Name, appWrapperName), TestTimeoutMedium).
+		Should(WithTransform(RayClusterState, Equal(rayv1.Ready)))
+
+	rayCluster = GetRayCluster(test, namespace.Name, rayCluster.Name)
+	aw = AppWrapper(test, namespace, aw.Name)(test)
+	state := upgradeState{
+		LocalQueue: localQueue.Name,
+		RayCluster: workloadState{
+			Name:       rayCluster.Name,
+			Generation: rayCluster.Generation,
+			Pods:       RayClusterPodUIDs(test, namespace.Name, rayCluster.Name)(test),
+		},
+		AppWrapper: workloadState{
+			Name:       aw.Name,
+			Generation: aw.Generation,
+			Retries:    AppWrapperRetries(aw),
+			Pods:       RayClusterPodUIDs(test, namespace.Name, appWrapperName)(test),
+		},
+	}
+	saveUpgradeState(test, state)
+	test.T().Logf("Recorded the state of the workloads in namespace %s: %+v", namespace.Name, state)
+}
+
+// Asserts the workloads created with release N of the operator remain healthy, and untouched, once the operator
+// has been upgraded, and that the defaults of the upgraded operator only apply to the RayClusters created afterwards.
+func TestPostUpgrade(t *testing.T) {
+	test := With(t)
+	RunPhase(test, PhasePostUpgrade)
+
+	namespace := GetNamespaceWithName(test, upgradeNamespace)
+	DumpEventsOnFailure(test, namespace.Name)
+	CollectDiagnosticsOnFailure(test, namespace.Name)
+	test.T().Cleanup(func() {
+		propagationPolicy := metav1.DeletePropagationBackground
+		err := test.Client().Core().CoreV1().Namespaces().Delete(test.Ctx(), namespace.Name,
+			metav1.DeleteOptions{PropagationPolicy: &propagationPolicy})
+		test.Expect(err).NotTo(HaveOccurred())
+	})
+	state := loadUpgradeState(test)
+
+	// The upgraded operator may reconcile the existing workloads late after it's started
+	test.T().Logf("Checking RayCluster %s/%s remains healthy", namespace.Name, state.RayCluster.Name)
+	test.Consistently(RayCluster(test, namespace.Name, state.RayCluster.Name), TestTimeoutShort).
+		Should(And(
+			WithTransform(RayClusterState, Equal(rayv1.Ready)),
+			WithTransform(func(rc *rayv1.RayCluster) int64 { return rc.Generation }, Equal(state.RayCluster.Generation)),
+		))
+	test.Expect(RayClusterPodUIDs(test, namespace.Name, state.RayCluster.Name)(test)).
+		To(Equal(state.RayCluster.Pods), "the pods of RayCluster %s/%s have been recreated", namespace.Name, state.RayCluster.Name)
+
+	test.T().Logf("Checking AppWrapper %s/%s remains healthy", namespace.Name, state.AppWrapper.Name)
+	test.Consistently(AppWrapper(test, namespace, state.AppWrapper.Name), TestTimeoutShort).
+		Should(And(
+			WithTransform(AppWrapperPhase, Equal(awv1beta2.AppWrapperRunning)),
+			HaveAppWrapperRetries(state.AppWrapper.Retries),
+			WithTransform(func(aw *awv1beta2.AppWrapper) int64 { return aw.Generation }, Equal(state.AppWrapper.Generation)),
+		))
+	test.Expect(GetRayCluster(test, namespace.Name, state.AppWrapper.Name)).
+		To(WithTransform(RayClusterState, Equal(rayv1.Ready)))
+	test.Expect(RayClusterPodUIDs(test, namespace.Name, state.AppWrapper.Name)(test)).
+		To(Equal(state.AppWrapper.Pods), "the pods of AppWrapper %s/%s have been recreated", namespace.Name, state.AppWrapper.Name)
+
+	// The RayCluster created after the upgrade is admitted with the defaults of the upgraded operator
+	rayCluster := newRayCluster(namespace.Name, newRayClusterName, state.LocalQueue)
+	rayCluster, err := test.Client().Ray().RayV1().RayClusters(namespace.Name).Create(test.Ctx(), rayCluster, metav1.CreateOptions{})
+	test.Expect(err).NotTo(HaveOccurred())
+	test.T().Logf("Created RayCluster %s/%s successfully", rayCluster.Namespace, rayCluster.Name)
+
+	test.T().Logf("Waiting for RayCluster %s/%s to be running", rayCluster.Namespace, rayCluster.Name)
+	test.Eventually(RayCluster(test, namespace.Name, rayCluster.Name), TestTimeoutMedium).
+		Should(WithTransform(RayClusterState, Equal(rayv1.Ready)))
+
+	// The defaults are idempotent, so re-applying them to a RayCluster admitted by the upgraded operator is a no-op
+	cfg := GetOperatorConfiguration(test)
+	rayCluster = GetRayCluster(test, namespace.Name, rayCluster.Name)
+	defaulted := rayCluster.DeepCopy()
+	defaults.ApplyRayClusterDefaults(cfg.KubeRay, defaulted)
+	test.Expect(rayCluster.Spec).To(BeComparableTo(defaulted.Spec),
+		"RayCluster %s/%s doesn't have the defaults of the upgraded operator", rayCluster.Namespace, rayCluster.Name)
+
+	// Whereas the existing RayCluster keeps the defaults of release N, that may differ
+	existing := GetRayCluster(test, namespace.Name, state.RayCluster.Name)
+	defaulted = existing.DeepCopy()
+	defaults.ApplyRayClusterDefaults(cfg.KubeRay, defaulted)
+	if !equality.Semantic.DeepEqual(defaulted.Spec, existing.Spec) {
+		test.T().Logf("RayCluster %s/%s has the defaults of the previous release, that differ from the upgraded operator's",
+			existing.Namespace, existing.Name)
+	}
+}
+
+func newRayCluster(namespace, name, localQueue string) *rayv1.RayCluster {
+	builder := NewRayClusterBuilder(namespace, name).
+		WithWorkerGroup("workers", 1, corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("250m"),
+				corev1.ResourceMemory: resource.MustParse("512Mi"),
+			},
+			Limits: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("1"),
+				corev1.ResourceMemory: resource.MustParse("1G"),
+			},
+		}, nil)
+	if localQueue != "" {
+		builder.WithLabels(map[string]string{"kueue.x-k8s.io/queue-name": localQueue})
+	}
+	return builder.Build()
+}